@@ -27,12 +27,14 @@ import (
 
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/paths"
 
 	"github.com/elastic/beats/v7/heartbeat/config"
 	"github.com/elastic/beats/v7/heartbeat/hbregistry"
 	"github.com/elastic/beats/v7/heartbeat/monitors"
 	"github.com/elastic/beats/v7/heartbeat/monitors/plugin"
 	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
+	"github.com/elastic/beats/v7/heartbeat/notifier"
 	hbrunner "github.com/elastic/beats/v7/heartbeat/reload"
 	"github.com/elastic/beats/v7/heartbeat/scheduler"
 	_ "github.com/elastic/beats/v7/heartbeat/security"
@@ -44,6 +46,8 @@ import (
 	"github.com/elastic/beats/v7/libbeat/common/reload"
 	"github.com/elastic/beats/v7/libbeat/esleg/eslegclient"
 	"github.com/elastic/beats/v7/libbeat/management"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/backend/memlog"
 )
 
 // Heartbeat represents the root datastructure of this beat.
@@ -57,6 +61,8 @@ type Heartbeat struct {
 	monitorFactory     cfgfile.RunnerFactory
 	autodiscover       *autodiscover.Autodiscover
 	replaceStateLoader func(sl monitorstate.StateLoader)
+	closeStateStore    func()
+	notifications      *notifier.Dispatcher
 	trace              tracer.Tracer
 
 	otelStatusFactoryWrapper cfgfile.FactoryWrapper
@@ -108,6 +114,33 @@ func New(b *beat.Beat, rawConfig *conf.C) (beat.Beater, error) {
 		stateLoader, replaceStateLoader = monitorstate.DeferredStateLoader(monitorstate.NilStateLoader, 15*time.Second, logger)
 	}
 
+	// The local state store takes precedence over other loaders, since it always holds
+	// the latest state, falling back to them for monitors it doesn't know about yet
+	var stateObserver monitorstate.StateObserver
+	closeStateStore := func() {}
+	if parsedConfig.StateStore.Enabled {
+		localStore, closeLocalStore, err := openLocalStateStore(b.Info, parsedConfig.StateStore, logger)
+		if err != nil {
+			trace.Abort()
+			return nil, fmt.Errorf("could not open monitor state store: %w", err)
+		}
+		stateLoader = localStore.Loader(stateLoader)
+		stateObserver = localStore.Save
+		closeStateStore = closeLocalStore
+	}
+
+	var notifications *notifier.Dispatcher
+	if parsedConfig.Notifications.Enabled() {
+		dispatcher, err := notifier.NewDispatcher(parsedConfig.Notifications, logger)
+		if err != nil {
+			trace.Abort()
+			closeStateStore()
+			return nil, fmt.Errorf("could not setup notifications: %w", err)
+		}
+		notifications = dispatcher
+		stateObserver = monitorstate.MultiStateObserver(stateObserver, dispatcher.Observe)
+	}
+
 	limit := parsedConfig.Scheduler.Limit
 	schedLocationName := parsedConfig.Scheduler.Location
 	if schedLocationName == "" {
@@ -130,12 +163,15 @@ func New(b *beat.Beat, rawConfig *conf.C) (beat.Beater, error) {
 		config:             parsedConfig,
 		scheduler:          sched,
 		replaceStateLoader: replaceStateLoader,
+		closeStateStore:    closeStateStore,
+		notifications:      notifications,
 		// monitorFactory is the factory used for creating all monitor instances,
 		// wiring them up to everything needed to actually execute.
 		monitorFactory: monitors.NewFactory(monitors.FactoryParams{
 			BeatInfo:              b.Info,
 			AddTask:               sched.Add,
 			StateLoader:           stateLoader,
+			StateObserver:         stateObserver,
 			PluginsReg:            plugin.GlobalPluginsReg,
 			PipelineClientFactory: pipelineClientFactory,
 			BeatRunFrom:           parsedConfig.RunFrom,
@@ -155,6 +191,12 @@ func New(b *beat.Beat, rawConfig *conf.C) (beat.Beater, error) {
 func (bt *Heartbeat) Run(b *beat.Beat) error {
 	bt.trace.Start()
 	defer bt.trace.Close()
+	// Deferred early so it runs after the scheduler has stopped recording states
+	defer bt.closeStateStore()
+	if bt.notifications != nil {
+		// Flushes queued notifications once monitors have stopped
+		defer bt.notifications.Stop()
+	}
 
 	// Adapt local pipeline to synchronized mode if run_once is enabled
 	pipeline := b.Publisher
@@ -329,6 +371,33 @@ func (bt *Heartbeat) WithOtelFactoryWrapper(wrapper cfgfile.FactoryWrapper) {
 	bt.otelStatusFactoryWrapper = wrapper
 }
 
+// openLocalStateStore opens the memlog backed store used to persist monitor states
+// across restarts. The returned func closes it.
+func openLocalStateStore(info beat.Info, cfg config.StateStore, logger *logp.Logger) (*monitorstate.LocalStore, func(), error) {
+	backend, err := memlog.New(logger.Named("monitor_states"), memlog.Settings{
+		Root: info.Paths.Resolve(paths.Data, cfg.Path),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	reg := statestore.NewRegistry(backend)
+	store, err := reg.Get(info.Beat)
+	if err != nil {
+		_ = reg.Close()
+		return nil, nil, err
+	}
+
+	localStore := monitorstate.NewLocalStore(store, logger)
+	return localStore, func() {
+		if err := localStore.Close(); err != nil {
+			logger.Warnf("could not close monitor state store: %v", err)
+		}
+		if err := reg.Close(); err != nil {
+			logger.Warnf("could not close monitor state registry: %v", err)
+		}
+	}, nil
+}
+
 // makeESClient establishes an ES connection meant to load monitors' state
 func makeESClient(
 	ctx context.Context,
@@ -49,6 +49,8 @@ type Config struct {
 	Jobs           map[string]*JobLimit `config:"jobs"`
 	RunFrom        *LocationWithID      `config:"run_from"`
 	SocketTrace    *SocketTrace         `config:"socket_trace"`
+	StateStore     StateStore           `config:"state_store"`
+	Notifications  *conf.C              `config:"notifications"`
 }
 
 type JobLimit struct {
@@ -61,6 +63,14 @@ type Scheduler struct {
 	Location string `config:"location"`
 }
 
+// StateStore defines the syntax of a heartbeat.yml state_store block, used to persist
+// monitor states locally.
+type StateStore struct {
+	Enabled bool `config:"enabled"`
+	// Path of the store, relative to the data path unless absolute.
+	Path string `config:"path"`
+}
+
 // DefaultConfig is the canonical instantiation of Config.
 func DefaultConfig(logger *logp.Logger) *Config {
 	limits := map[string]*JobLimit{
@@ -84,7 +94,8 @@ func DefaultConfig(logger *logp.Logger) *Config {
 	}
 
 	return &Config{
-		Jobs: limits,
+		Jobs:       limits,
+		StateStore: StateStore{Path: "monitor-states"},
 	}
 }
 
@@ -18,6 +18,8 @@
 package eventext
 
 import (
+	"time"
+
 	"github.com/elastic/beats/v7/libbeat/beat"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
@@ -56,3 +58,32 @@ func IsEventCancelled(event *beat.Event) bool {
 	v, err := event.Meta.GetValue(EventCancelledMetaKey)
 	return err == nil && v == true
 }
+
+// ContinuationsAtMetaKey is the path to the @metadata key holding the time set by DelayContinuations.
+const ContinuationsAtMetaKey = "__hb_evt_conts_at__"
+
+// DelayContinuations marks the continuations returned along with the event to be run no
+// earlier than runAt. The runner releases its execution slots while they wait.
+func DelayContinuations(event *beat.Event, runAt time.Time) {
+	if event != nil {
+		SetMeta(event, ContinuationsAtMetaKey, runAt)
+	}
+}
+
+// TakeContinuationsAt returns the time set by DelayContinuations, if any, and removes the
+// marker so that it is not published.
+func TakeContinuationsAt(event *beat.Event) (runAt time.Time, ok bool) {
+	if event == nil || event.Meta == nil {
+		return time.Time{}, false
+	}
+	v, err := event.Meta.GetValue(ContinuationsAtMetaKey)
+	if err != nil {
+		return time.Time{}, false
+	}
+	_ = event.Meta.Delete(ContinuationsAtMetaKey)
+	if len(event.Meta) == 0 {
+		event.Meta = nil
+	}
+	runAt, ok = v.(time.Time)
+	return runAt, ok
+}
@@ -54,6 +54,7 @@ type RunnerFactory struct {
 	info                  *beat.Info
 	addTask               scheduler.AddTask
 	stateLoader           monitorstate.StateLoader
+	stateObserver         monitorstate.StateObserver
 	byId                  map[string]*Monitor
 	mtx                   *sync.Mutex
 	pluginsReg            *plugin.PluginsReg
@@ -87,6 +88,7 @@ type FactoryParams struct {
 	BeatInfo              beat.Info
 	AddTask               scheduler.AddTask
 	StateLoader           monitorstate.StateLoader
+	StateObserver         monitorstate.StateObserver
 	PluginsReg            *plugin.PluginsReg
 	PipelineClientFactory PipelineClientFactory
 	BeatRunFrom           *config.LocationWithID
@@ -104,6 +106,7 @@ func NewFactory(fp FactoryParams) *RunnerFactory {
 		pipelineClientFactory: fp.PipelineClientFactory,
 		beatLocation:          fp.BeatRunFrom,
 		stateLoader:           fp.StateLoader,
+		stateObserver:         fp.StateObserver,
 	}
 }
 
@@ -205,7 +208,7 @@ func (f *RunnerFactory) Create(p beat.Pipeline, c *conf.C) (cfgfile.Runner, erro
 		}
 	}
 
-	monitor, err := newMonitor(c, f.pluginsReg, pc, f.addTask, f.stateLoader, *f.info, safeStop)
+	monitor, err := newMonitor(c, f.pluginsReg, pc, f.addTask, f.stateLoader, f.stateObserver, *f.info, safeStop)
 	if err != nil {
 		return nil, fmt.Errorf("factory could not create monitor: %w", err)
 	}
@@ -334,7 +334,7 @@ func TestDuplicateMonitorIDs(t *testing.T) {
 	require.NoError(t, err)
 
 	// Ensure that an error is returned on a bad config
-	_, m0Err := newMonitor(badConf, reg, c, sched.Add, nil, beat.Info{Logger: logptest.NewTestingLogger(t, "")}, nil)
+	_, m0Err := newMonitor(badConf, reg, c, sched.Add, nil, nil, beat.Info{Logger: logptest.NewTestingLogger(t, "")}, nil)
 	require.Error(t, m0Err)
 
 	// Would fail if the previous newMonitor didn't free the monitor.id
@@ -86,7 +86,7 @@ func (m *Monitor) String() string {
 }
 
 func checkMonitorConfig(config *conf.C, registrar *plugin.PluginsReg, info beat.Info) error {
-	_, err := newMonitor(config, registrar, nil, nil, monitorstate.NilStateLoader, info, nil)
+	_, err := newMonitor(config, registrar, nil, nil, monitorstate.NilStateLoader, nil, info, nil)
 
 	return err
 }
@@ -99,10 +99,11 @@ func newMonitor(
 	pubClient beat.Client,
 	taskAdder scheduler.AddTask,
 	stateLoader monitorstate.StateLoader,
+	stateObserver monitorstate.StateObserver,
 	info beat.Info,
 	onStop func(*Monitor),
 ) (*Monitor, error) {
-	m, err := newMonitorUnsafe(config, registrar, pubClient, taskAdder, stateLoader, info, onStop)
+	m, err := newMonitorUnsafe(config, registrar, pubClient, taskAdder, stateLoader, stateObserver, info, onStop)
 	if m != nil && err != nil {
 		m.Stop()
 	}
@@ -117,6 +118,7 @@ func newMonitorUnsafe(
 	pubClient beat.Client,
 	addTask scheduler.AddTask,
 	stateLoader monitorstate.StateLoader,
+	stateObserver monitorstate.StateObserver,
 	info beat.Info,
 	onStop func(*Monitor),
 ) (*Monitor, error) {
@@ -143,7 +145,7 @@ func newMonitorUnsafe(
 		config:              config,
 		stats:               pluginFactory.Stats,
 		state:               MON_INIT,
-		monitorStateTracker: monitorstate.NewTracker(stateLoader, false, info.Logger),
+		monitorStateTracker: monitorstate.NewTracker(stateLoader, stateObserver, false, info.Logger),
 		logger:              info.Logger,
 	}
 
@@ -167,7 +169,7 @@ func newMonitorUnsafe(
 
 	var wrappedJobs []jobs.Job
 	if err == nil {
-		wrappedJobs = wrappers.WrapCommon(p.Jobs, m.stdFields, stateLoader, info.Logger)
+		wrappedJobs = wrappers.WrapCommon(p.Jobs, m.stdFields, stateLoader, stateObserver, info.Logger)
 	} else {
 		// If we've hit an error at this point, still run on schedule, but always return an error.
 		// This way the error is clearly communicated through to kibana.
@@ -196,7 +198,7 @@ func newMonitorUnsafe(
 		m.stdFields.BadConfig = true
 		// No need to retry bad configs
 		m.stdFields.MaxAttempts = 1
-		wrappedJobs = wrappers.WrapCommon(p.Jobs, m.stdFields, stateLoader, info.Logger)
+		wrappedJobs = wrappers.WrapCommon(p.Jobs, m.stdFields, stateLoader, stateObserver, info.Logger)
 	}
 
 	m.plugin = p
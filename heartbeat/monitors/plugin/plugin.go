@@ -77,7 +77,7 @@ func (p Plugin) Update(c *conf.C) error {
 
 // RunWrapped runs the plug-in with the provided wrappers returning a channel of resultant events.
 func (p Plugin) RunWrapped(fields stdfields.StdMonitorFields) chan *beat.Event {
-	wj := wrappers.WrapCommon(p.Jobs, fields, nil, p.Logger)
+	wj := wrappers.WrapCommon(p.Jobs, fields, nil, nil, p.Logger)
 	results := make(chan *beat.Event)
 
 	var runJob func(j jobs.Job)
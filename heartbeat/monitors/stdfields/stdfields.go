@@ -19,6 +19,7 @@ package stdfields
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	hbconfig "github.com/elastic/beats/v7/heartbeat/config"
@@ -31,6 +32,14 @@ type ServiceFields struct {
 	Name string `config:"name"`
 }
 
+// RetestConfig configures quick re-checks of a monitor that just went down. The
+// monitor is re-run up to Count times on Schedule before its state is allowed to
+// transition to down.
+type RetestConfig struct {
+	Count    uint16             `config:"count"`
+	Schedule *schedule.Schedule `config:"schedule"`
+}
+
 // StdMonitorFields represents the generic configuration options around a monitor plugin.
 type StdMonitorFields struct {
 	ID                 string              `config:"id"`
@@ -39,11 +48,15 @@ type StdMonitorFields struct {
 	Schedule           *schedule.Schedule  `config:"schedule" validate:"required"`
 	MaintenanceWindows []maintwin.MaintWin `config:"maintenance_windows" `
 	ParsedMainteWin    []maintwin.ParsedMaintWin
-	Timeout            time.Duration `config:"timeout"`
-	Service            ServiceFields `config:"service"`
-	Origin             string        `config:"origin"`
-	LegacyServiceName  string        `config:"service_name"`
-	MaxAttempts        uint16        `config:"max_attempts"`
+	Timeout            time.Duration  `config:"timeout"`
+	Service            ServiceFields  `config:"service"`
+	Origin             string         `config:"origin"`
+	LegacyServiceName  string         `config:"service_name"`
+	MaxAttempts        uint16         `config:"max_attempts"`
+	Retest             RetestConfig   `config:"retest"`
+	Timezone           string         `config:"timezone"`
+	Location           *time.Location `config:",ignore"`
+	Jitter             time.Duration  `config:"jitter"`
 	// Used by zip_url and local monitors
 	// kibana originating monitors only run one journey at a time
 	// and just use the `fields` syntax / manually set monitor IDs
@@ -80,6 +93,35 @@ func ConfigToStdMonitorFields(conf *config.C) (StdMonitorFields, error) {
 		sFields.IsLegacyBrowserSource = true
 	}
 
+	if sFields.Timezone != "" {
+		loc, err := time.LoadLocation(sFields.Timezone)
+		if err != nil {
+			return StdMonitorFields{}, fmt.Errorf("invalid timezone for monitor (id:%s name:%s): %w", sFields.ID, sFields.Name, err)
+		}
+		sFields.Location = loc
+	}
+
+	if sFields.Jitter < 0 {
+		return StdMonitorFields{}, fmt.Errorf("invalid jitter for monitor (id:%s name:%s): must not be negative", sFields.ID, sFields.Name)
+	}
+	if sFields.Jitter > 0 {
+		now := time.Now()
+		if sFields.Location != nil {
+			now = now.In(sFields.Location)
+		}
+		if period := sFields.Schedule.Period(now); sFields.Jitter >= period {
+			return StdMonitorFields{}, fmt.Errorf("invalid jitter for monitor (id:%s name:%s): must be smaller than the schedule period of %s", sFields.ID, sFields.Name, period)
+		}
+	}
+
+	// A retest policy implies as many extra attempts as configured retests
+	if sFields.Retest.Count == math.MaxUint16 {
+		return StdMonitorFields{}, fmt.Errorf("invalid retest count for monitor (id:%s name:%s): must be smaller than %d", sFields.ID, sFields.Name, math.MaxUint16)
+	}
+	if sFields.Retest.Count > 0 && sFields.MaxAttempts < sFields.Retest.Count+1 {
+		sFields.MaxAttempts = sFields.Retest.Count + 1
+	}
+
 	for _, mw := range sFields.MaintenanceWindows {
 		parsed, err := mw.Parse(true)
 		if err != nil {
@@ -20,6 +20,7 @@ package stdfields
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -65,3 +66,99 @@ func TestLegacyServiceNameConfig(t *testing.T) {
 	}
 
 }
+
+func TestSchedulingOptionsConfig(t *testing.T) {
+	configBase := func() mapstr.M {
+		return mapstr.M{
+			"type":     "http",
+			"id":       "myId",
+			"schedule": "@every 1m",
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		c, err := conf.NewConfigFrom(configBase())
+		require.NoError(t, err)
+		f, err := ConfigToStdMonitorFields(c)
+		require.NoError(t, err)
+		require.Nil(t, f.Location)
+		require.Zero(t, f.Jitter)
+		require.Nil(t, f.Retest.Schedule)
+		require.Equal(t, uint16(1), f.MaxAttempts)
+	})
+
+	t.Run("timezone, jitter and retest", func(t *testing.T) {
+		cm := configBase()
+		cm["timezone"] = "Pacific/Tarawa"
+		cm["jitter"] = "10s"
+		cm["retest"] = mapstr.M{"count": 3, "schedule": "@every 5s"}
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		f, err := ConfigToStdMonitorFields(c)
+		require.NoError(t, err)
+		require.Equal(t, "Pacific/Tarawa", f.Location.String())
+		require.Equal(t, 10*time.Second, f.Jitter)
+		require.NotNil(t, f.Retest.Schedule)
+		require.Equal(t, uint16(4), f.MaxAttempts)
+	})
+
+	t.Run("location is only derived from timezone", func(t *testing.T) {
+		cm := configBase()
+		cm["location"] = mapstr.M{"name": "Europe/Paris"}
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		f, err := ConfigToStdMonitorFields(c)
+		require.NoError(t, err)
+		require.Nil(t, f.Location)
+	})
+
+	t.Run("explicit max_attempts above retest count", func(t *testing.T) {
+		cm := configBase()
+		cm["max_attempts"] = 5
+		cm["retest"] = mapstr.M{"count": 1}
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		f, err := ConfigToStdMonitorFields(c)
+		require.NoError(t, err)
+		require.Equal(t, uint16(5), f.MaxAttempts)
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		cm := configBase()
+		cm["timezone"] = "Not/AZone"
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		_, err = ConfigToStdMonitorFields(c)
+		require.ErrorContains(t, err, "invalid timezone")
+	})
+
+	t.Run("negative jitter", func(t *testing.T) {
+		cm := configBase()
+		cm["jitter"] = "-1s"
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		_, err = ConfigToStdMonitorFields(c)
+		require.ErrorContains(t, err, "invalid jitter")
+	})
+
+	t.Run("jitter not smaller than the schedule period", func(t *testing.T) {
+		for _, sched := range []string{"@every 1m", "* * * * *"} {
+			cm := configBase()
+			cm["schedule"] = sched
+			cm["jitter"] = "5m"
+			c, err := conf.NewConfigFrom(cm)
+			require.NoError(t, err)
+			_, err = ConfigToStdMonitorFields(c)
+			require.ErrorContains(t, err, "must be smaller than the schedule period of 1m0s", sched)
+		}
+	})
+
+	t.Run("retest count overflowing max_attempts", func(t *testing.T) {
+		cm := configBase()
+		cm["retest"] = mapstr.M{"count": 65535}
+		c, err := conf.NewConfigFrom(cm)
+		require.NoError(t, err)
+		_, err = ConfigToStdMonitorFields(c)
+		require.ErrorContains(t, err, "invalid retest count")
+	})
+}
@@ -86,7 +86,9 @@ func (t *configuredJob) Start(pubClient beat.Client) {
 		return
 	}
 
-	t.cancelFn, err = t.monitor.addTask(t.config.Schedule, t.monitor.stdFields.ParsedMainteWin, t.monitor.stdFields.ID, t.makeSchedulerTaskFunc(), t.config.Type)
+	sf := t.monitor.stdFields
+	opts := scheduler.JobOpts{Location: sf.Location, Jitter: sf.Jitter}
+	t.cancelFn, err = t.monitor.addTask(t.config.Schedule, sf.ParsedMainteWin, sf.ID, t.makeSchedulerTaskFunc(), t.config.Type, opts)
 	if err != nil {
 		t.logger.Infof("could not start monitor: %v", err)
 	}
@@ -111,6 +113,7 @@ func runPublishJob(job jobs.Job, pubClient beat.Client, logger *logp.Logger) []s
 	if err != nil {
 		logger.Infof("Job failed with: %s", err)
 	}
+	runAt, delayed := eventext.TakeContinuationsAt(event)
 
 	hasContinuations := len(conts) > 0
 
@@ -146,5 +149,15 @@ func runPublishJob(job jobs.Job, pubClient beat.Client, logger *logp.Logger) []s
 			return runPublishJob(localCont, pubClient, logger)
 		}
 	}
+	if delayed {
+		// Hand the continuations back to the scheduler rather than waiting for runAt
+		// here, which would hold the execution slots of the job
+		return []scheduler.TaskFunc{func(ctx context.Context) []scheduler.TaskFunc {
+			if scheduler.RunLater(ctx, runAt, func(_ context.Context) []scheduler.TaskFunc { return contTasks }) {
+				return nil
+			}
+			return contTasks
+		}}
+	}
 	return contTasks
 }
@@ -20,6 +20,7 @@ package monitors
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/elastic/elastic-agent-libs/logp/logptest"
 	"github.com/elastic/elastic-agent-libs/mapstr"
@@ -82,6 +83,18 @@ func Test_runPublishJob(t *testing.T) {
 				lookslike.MustCompile(map[string]interface{}{"blah": "blargh"}),
 			},
 		},
+		{
+			"delayed cont",
+			func(event *beat.Event) (j []jobs.Job, e error) {
+				_, _ = simpleJob(event)
+				eventext.DelayContinuations(event, time.Now().Add(time.Hour))
+				return []jobs.Job{defineJob(mapstr.M{"baz": "bot"})}, nil
+			},
+			[]validator.Validator{
+				lookslike.MustCompile(map[string]interface{}{"foo": "bar"}),
+				lookslike.MustCompile(map[string]interface{}{"baz": "bot"}),
+			},
+		},
 		{
 			"cancelled cont",
 			func(event *beat.Event) (j []jobs.Job, e error) {
@@ -115,6 +128,7 @@ func Test_runPublishJob(t *testing.T) {
 			require.Len(t, pipel.PublishedEvents(), len(tc.validators))
 			for idx, event := range pipel.PublishedEvents() {
 				testslike.Test(t, tc.validators[idx], event.Fields)
+				require.NotContains(t, event.Meta, eventext.ContinuationsAtMetaKey)
 			}
 		})
 	}
@@ -174,7 +174,7 @@ func newESTestContext(t *testing.T) *esTestContext {
 		location:  location,
 	}
 
-	etc.tracker = NewTracker(etc.loader, true, logptest.NewTestingLogger(t, ""))
+	etc.tracker = NewTracker(etc.loader, nil, true, logptest.NewTestingLogger(t, ""))
 
 	return etc
 }
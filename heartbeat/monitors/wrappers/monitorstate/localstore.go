@@ -0,0 +1,147 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitorstate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// LocalStoreMaxAge mirrors the window the ES loader searches, states older than this
+// are not resumed, since the monitor has likely been stopped for a long time.
+const LocalStoreMaxAge = 6 * time.Hour
+
+// LocalStore persists the last state of each monitor in a local statestore, letting
+// monitor states survive restarts regardless of the configured output.
+type LocalStore struct {
+	store  *statestore.Store
+	maxAge time.Duration
+	logger *logp.Logger
+}
+
+// localStoreEntry is the persisted form of a State. The state is flattened since
+// the store's encoder can't handle State's recursive Ends field, which only matters
+// to the event recording a transition anyway.
+type localStoreEntry struct {
+	Updated     time.Time     `struct:"updated"`
+	ID          string        `struct:"id"`
+	StartedAt   time.Time     `struct:"started_at"`
+	DurationMs  int64         `struct:"duration_ms"`
+	Status      StateStatus   `struct:"status"`
+	Checks      int           `struct:"checks"`
+	Up          int           `struct:"up"`
+	Down        int           `struct:"down"`
+	FlapHistory []StateStatus `struct:"flap_history"`
+}
+
+func newLocalStoreEntry(state *State, updated time.Time) localStoreEntry {
+	return localStoreEntry{
+		Updated:     updated,
+		ID:          state.ID,
+		StartedAt:   state.StartedAt,
+		DurationMs:  state.DurationMs,
+		Status:      state.Status,
+		Checks:      state.Checks,
+		Up:          state.Up,
+		Down:        state.Down,
+		FlapHistory: state.FlapHistory,
+	}
+}
+
+func (e localStoreEntry) state() *State {
+	return &State{
+		ID:          e.ID,
+		StartedAt:   e.StartedAt,
+		DurationMs:  e.DurationMs,
+		Status:      e.Status,
+		Checks:      e.Checks,
+		Up:          e.Up,
+		Down:        e.Down,
+		FlapHistory: e.FlapHistory,
+	}
+}
+
+// NewLocalStore creates a LocalStore persisting states into the given store.
+func NewLocalStore(store *statestore.Store, logger *logp.Logger) *LocalStore {
+	return &LocalStore{
+		store:  store,
+		maxAge: LocalStoreMaxAge,
+		logger: logger,
+	}
+}
+
+// Loader returns a StateLoader reading from the local store. If no recent state is
+// found for a monitor the fallback loader is used instead, if set.
+func (ls *LocalStore) Loader(fallback StateLoader) StateLoader {
+	if fallback == nil {
+		fallback = NilStateLoader
+	}
+	return func(sf stdfields.StdMonitorFields) (*State, error) {
+		key := LocalStoreKey(sf)
+
+		has, err := ls.store.Has(key)
+		if err != nil {
+			return nil, LoaderError{err: fmt.Errorf("could not read local state for %s: %w", sf.ID, err), Retry: false}
+		}
+		if !has {
+			return fallback(sf)
+		}
+
+		var entry localStoreEntry
+		if err := ls.store.Get(key, &entry); err != nil {
+			return nil, LoaderError{err: fmt.Errorf("could not decode local state for %s: %w", sf.ID, err), Retry: false}
+		}
+		if entry.ID == "" || time.Since(entry.Updated) > ls.maxAge {
+			ls.logger.Infof("local state for monitor %s is stale or empty, ignoring it", sf.ID)
+			return fallback(sf)
+		}
+
+		return entry.state(), nil
+	}
+}
+
+// Save persists the given state for the monitor, it has the signature of a StateObserver.
+func (ls *LocalStore) Save(sf stdfields.StdMonitorFields, state *State) {
+	if state == nil {
+		return
+	}
+
+	err := ls.store.Set(LocalStoreKey(sf), newLocalStoreEntry(state, time.Now()))
+	if err != nil {
+		ls.logger.Warnf("could not persist state for monitor %s: %v", sf.ID, err)
+	}
+}
+
+// Close closes the underlying store.
+func (ls *LocalStore) Close() error {
+	return ls.store.Close()
+}
+
+// LocalStoreKey returns the key a monitor's state is stored under. Like the ES
+// loader, states are scoped to the location the monitor runs from.
+func LocalStoreKey(sf stdfields.StdMonitorFields) string {
+	rfid := "default"
+	if sf.RunFrom != nil {
+		rfid = sf.RunFrom.ID
+	}
+	return fmt.Sprintf("monitor::%s::%s", rfid, sf.ID)
+}
@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitorstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/heartbeat/config"
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/backend/memlog"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+// openLocalStore opens a memlog backed LocalStore in dir, the returned func closes it.
+func openLocalStore(t *testing.T, dir string) (*LocalStore, func()) {
+	logger := logptest.NewTestingLogger(t, "")
+	backend, err := memlog.New(logger, memlog.Settings{Root: dir})
+	require.NoError(t, err)
+	reg := statestore.NewRegistry(backend)
+	store, err := reg.Get("heartbeat")
+	require.NoError(t, err)
+
+	ls := NewLocalStore(store, logger)
+	return ls, func() {
+		require.NoError(t, ls.Close())
+		require.NoError(t, reg.Close())
+	}
+}
+
+func TestLocalStoreResumesStateAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+
+	ls, closeStore := openLocalStore(t, dir)
+	mst := NewTracker(ls.Loader(nil), ls.Save, true, logptest.NewTestingLogger(t, ""))
+	var last *State
+	for i := 0; i < 3; i++ {
+		last = mst.RecordStatus(TestSf, StatusUp, true)
+	}
+	requireMSCounts(t, last, 3, 0)
+	closeStore()
+
+	// Simulate a restart with a brand new tracker and store
+	ls, closeStore = openLocalStore(t, dir)
+	defer closeStore()
+	mst = NewTracker(ls.Loader(nil), ls.Save, true, logptest.NewTestingLogger(t, ""))
+
+	ms := mst.RecordStatus(TestSf, StatusUp, true)
+	require.Equal(t, last.ID, ms.ID)
+	require.Equal(t, StatusUp, ms.Status)
+	require.True(t, last.StartedAt.Equal(ms.StartedAt))
+	requireMSCounts(t, ms, 4, 0)
+
+	// Transitions continue from the resumed state
+	ms = mst.RecordStatus(TestSf, StatusDown, true)
+	require.Equal(t, StatusDown, ms.Status)
+	require.NotNil(t, ms.Ends)
+	require.Equal(t, last.ID, ms.Ends.ID)
+}
+
+func TestLocalStoreLoader(t *testing.T) {
+	loggedFallback := &State{ID: "from-fallback", Status: StatusDown}
+	fallback := func(_ stdfields.StdMonitorFields) (*State, error) {
+		return loggedFallback, nil
+	}
+
+	t.Run("uses the fallback if no local state exists", func(t *testing.T) {
+		ls, closeStore := openLocalStore(t, t.TempDir())
+		defer closeStore()
+
+		s, err := ls.Loader(fallback)(TestSf)
+		require.NoError(t, err)
+		require.Equal(t, loggedFallback, s)
+
+		s, err = ls.Loader(nil)(TestSf)
+		require.NoError(t, err)
+		require.Nil(t, s)
+	})
+
+	t.Run("uses the fallback if the local state is stale", func(t *testing.T) {
+		ls, closeStore := openLocalStore(t, t.TempDir())
+		defer closeStore()
+
+		stale := &State{ID: "stale", Status: StatusUp}
+		err := ls.store.Set(LocalStoreKey(TestSf), newLocalStoreEntry(stale, time.Now().Add(-LocalStoreMaxAge-time.Minute)))
+		require.NoError(t, err)
+
+		s, err := ls.Loader(fallback)(TestSf)
+		require.NoError(t, err)
+		require.Equal(t, loggedFallback, s)
+	})
+
+	t.Run("states are scoped by location", func(t *testing.T) {
+		ls, closeStore := openLocalStore(t, t.TempDir())
+		defer closeStore()
+
+		sfElsewhere := TestSf
+		sfElsewhere.RunFrom = &config.LocationWithID{ID: "elsewhere"}
+		ls.Save(TestSf, &State{ID: "here", Status: StatusUp, FlapHistory: []StateStatus{StatusUp}})
+
+		s, err := ls.Loader(nil)(TestSf)
+		require.NoError(t, err)
+		require.Equal(t, "here", s.ID)
+		require.Equal(t, []StateStatus{StatusUp}, s.FlapHistory)
+
+		s, err = ls.Loader(nil)(sfElsewhere)
+		require.NoError(t, err)
+		require.Nil(t, s)
+	})
+}
@@ -32,8 +32,8 @@ import (
 // state loader, which will try to fetch the last known state for a never
 // before seen monitor, which usually means using ES. If set to nil
 // it will use ES if configured, otherwise it will only track state from
-// memory.
-func NewTracker(sl StateLoader, flappingEnabled bool, logger *logp.Logger) *Tracker {
+// memory. The optional state observer is invoked with every state recorded.
+func NewTracker(sl StateLoader, so StateObserver, flappingEnabled bool, logger *logp.Logger) *Tracker {
 	if sl == nil {
 		sl = NilStateLoader
 	}
@@ -41,6 +41,7 @@ func NewTracker(sl StateLoader, flappingEnabled bool, logger *logp.Logger) *Trac
 		states:          map[string]*State{},
 		mtx:             sync.Mutex{},
 		stateLoader:     sl,
+		stateObserver:   so,
 		flappingEnabled: flappingEnabled,
 		logger:          logger,
 	}
@@ -50,6 +51,7 @@ type Tracker struct {
 	states          map[string]*State
 	mtx             sync.Mutex
 	stateLoader     StateLoader
+	stateObserver   StateObserver
 	flappingEnabled bool
 	logger          *logp.Logger
 }
@@ -58,7 +60,42 @@ type Tracker struct {
 // other than ES if necessary
 type StateLoader func(stdfields.StdMonitorFields) (*State, error)
 
+// StateObserver is invoked with a copy of the state after each recorded check,
+// it is used to persist or otherwise act on state changes outside of published events.
+type StateObserver func(stdfields.StdMonitorFields, *State)
+
+// MultiStateObserver combines observers into one, invoking them in order. Nil
+// observers are skipped, nil is returned if no observer is left.
+func MultiStateObserver(observers ...StateObserver) StateObserver {
+	var set []StateObserver
+	for _, so := range observers {
+		if so != nil {
+			set = append(set, so)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return nil
+	case 1:
+		return set[0]
+	}
+	return func(sf stdfields.StdMonitorFields, ms *State) {
+		for _, so := range set {
+			so(sf, ms)
+		}
+	}
+}
+
 func (t *Tracker) RecordStatus(sf stdfields.StdMonitorFields, newStatus StateStatus, isFinalAttempt bool) (ms *State) {
+	ms = t.recordStatus(sf, newStatus, isFinalAttempt)
+	// Observers may perform IO, so they're invoked outside the lock with their own copy
+	if t.stateObserver != nil {
+		t.stateObserver(sf, ms.copy())
+	}
+	return ms
+}
+
+func (t *Tracker) recordStatus(sf stdfields.StdMonitorFields, newStatus StateStatus, isFinalAttempt bool) (ms *State) {
 	//note: the return values have no concurrency controls, they may be unsafely read unless
 	//copied to the stack, copying the structs before  returning
 	t.mtx.Lock()
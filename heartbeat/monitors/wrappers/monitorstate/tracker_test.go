@@ -29,7 +29,7 @@ import (
 )
 
 func TestTrackerRecord(t *testing.T) {
-	mst := NewTracker(NilStateLoader, true, logptest.NewTestingLogger(t, ""))
+	mst := NewTracker(NilStateLoader, nil, true, logptest.NewTestingLogger(t, ""))
 	ms := mst.RecordStatus(TestSf, StatusUp, true)
 	require.Equal(t, StatusUp, ms.Status)
 	requireMSStatusCount(t, ms, StatusUp, 1)
@@ -52,7 +52,7 @@ func TestTrackerRecord(t *testing.T) {
 }
 
 func TestTrackerRecordFlappingDisabled(t *testing.T) {
-	mst := NewTracker(NilStateLoader, false, logptest.NewTestingLogger(t, ""))
+	mst := NewTracker(NilStateLoader, nil, false, logptest.NewTestingLogger(t, ""))
 	ms := mst.RecordStatus(TestSf, StatusUp, true)
 	require.Equal(t, StatusUp, ms.Status)
 	requireMSStatusCount(t, ms, StatusUp, 1)
@@ -71,6 +71,21 @@ func TestTrackerRecordFlappingDisabled(t *testing.T) {
 	requireMSStatusCount(t, ms, StatusDown, 1)
 }
 
+func TestMultiStateObserver(t *testing.T) {
+	require.Nil(t, MultiStateObserver(nil, nil))
+
+	var calls []string
+	observer := func(name string) StateObserver {
+		return func(sf stdfields.StdMonitorFields, ms *State) {
+			calls = append(calls, name+":"+string(ms.Status))
+		}
+	}
+
+	mst := NewTracker(NilStateLoader, MultiStateObserver(observer("a"), nil, observer("b")), false, logptest.NewTestingLogger(t, ""))
+	mst.RecordStatus(TestSf, StatusDown, true)
+	require.Equal(t, []string{"a:down", "b:down"}, calls)
+}
+
 func TestAtomicStateLoader(t *testing.T) {
 	stateA := &State{ID: "A"}
 	stateB := &State{ID: "B"}
@@ -174,7 +189,7 @@ func TestStateLoaderRetry(t *testing.T) {
 				return nil, LoaderError{err: errors.New("test error"), Retry: tt.retryable}
 			}
 
-			mst := NewTracker(errorStateLoader, true, logptest.NewTestingLogger(t, ""))
+			mst := NewTracker(errorStateLoader, nil, true, logptest.NewTestingLogger(t, ""))
 			mst.GetCurrentState(stdfields.StdMonitorFields{}, tt.rc)
 
 			require.Equal(t, calls, tt.expectedCalls)
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/elastic/beats/v7/heartbeat/eventext"
 	"github.com/elastic/beats/v7/heartbeat/monitors/jobs"
 	"github.com/elastic/beats/v7/heartbeat/monitors/logger"
 	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
@@ -160,8 +161,11 @@ func (s *Summarizer) Wrap(j jobs.Job) jobs.Job {
 				//    that it's hard to tell the sequence in which jobs executed apart in our
 				//    kibana queries
 				// 2. If the site error is very short 1s gives it a tiny bit of time to recover
+				// A configured retest schedule takes precedence over the default delay.
+				// The delay is left to the scheduler, so that a waiting retry does not
+				// hold execution slots other monitors need.
+				eventext.DelayContinuations(event, time.Now().Add(s.nextRetryDelay()))
 				delayedRootJob := func(event *beat.Event) ([]jobs.Job, error) {
-					time.Sleep(s.retryDelay)
 					for _, p := range s.plugins {
 						p.BeforeRetry()
 					}
@@ -181,3 +185,26 @@ func (s *Summarizer) Wrap(j jobs.Job) jobs.Job {
 		return conts, eventErr
 	}
 }
+
+// nextRetryDelay returns how long to wait before the next attempt, following the
+// monitor's retest schedule if one is configured. The delay never exceeds the
+// time until the next run on the monitor's main schedule.
+func (s *Summarizer) nextRetryDelay() time.Duration {
+	if s.sf.Retest.Schedule == nil {
+		return s.retryDelay
+	}
+	now := time.Now()
+	if s.sf.Location != nil {
+		now = now.In(s.sf.Location)
+	}
+	d := s.sf.Retest.Schedule.Next(now).Sub(now)
+	if d <= 0 {
+		return s.retryDelay
+	}
+	if s.sf.Schedule != nil {
+		if main := s.sf.Schedule.Next(now).Sub(now); main > 0 && d > main {
+			return main
+		}
+	}
+	return d
+}
@@ -25,11 +25,13 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/elastic/beats/v7/heartbeat/eventext"
 	"github.com/elastic/beats/v7/heartbeat/look"
 	"github.com/elastic/beats/v7/heartbeat/monitors/jobs"
 	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
 	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
 	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/summarizer/jobsummary"
+	"github.com/elastic/beats/v7/heartbeat/scheduler/schedule"
 	"github.com/elastic/beats/v7/libbeat/beat"
 	"github.com/elastic/elastic-agent-libs/logp/logptest"
 	"github.com/elastic/elastic-agent-libs/mapstr"
@@ -150,7 +152,7 @@ func TestSummarizer(t *testing.T) {
 				return nil, retErr
 			}
 
-			tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, false, logptest.NewTestingLogger(t, ""))
+			tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, nil, false, logptest.NewTestingLogger(t, ""))
 			sf := stdfields.StdMonitorFields{ID: "testmon", Name: "testmon", Type: "http", MaxAttempts: uint16(tt.maxAttempts)}
 
 			rcvdStatuses := ""
@@ -253,7 +255,7 @@ func TestSummarizerPluginOrder(t *testing.T) {
 			t.Parallel()
 
 			// Monitor setup
-			tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, false, logptest.NewTestingLogger(t, ""))
+			tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, nil, false, logptest.NewTestingLogger(t, ""))
 			sf := stdfields.StdMonitorFields{ID: "testmon", Name: "testmon", Type: "http", MaxAttempts: uint16(tt.maxAttempts)}
 
 			// Test locals
@@ -323,7 +325,7 @@ func TestRetryLightweightMonitorDuration(t *testing.T) {
 	t.Parallel()
 
 	// Monitor setup
-	tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, false, logptest.NewTestingLogger(t, ""))
+	tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, nil, false, logptest.NewTestingLogger(t, ""))
 	sf := stdfields.StdMonitorFields{ID: "testmon", Name: "testmon", Type: "http", MaxAttempts: uint16(2)}
 
 	// We simplify these to always down
@@ -377,6 +379,54 @@ func TestRetryLightweightMonitorDuration(t *testing.T) {
 	require.GreaterOrEqual(t, look.RTTMS(retryElapsed), rcvdDuration)
 }
 
+func TestSummarizerRetestDelay(t *testing.T) {
+	tracker := monitorstate.NewTracker(monitorstate.NilStateLoader, nil, false, logptest.NewTestingLogger(t, ""))
+
+	t.Run("capped to the main schedule", func(t *testing.T) {
+		sf := stdfields.StdMonitorFields{
+			ID:       "testmon",
+			Type:     "http",
+			Schedule: schedule.MustParse("@every 10s"),
+			Retest:   stdfields.RetestConfig{Count: 1, Schedule: schedule.MustParse("@every 30s")},
+		}
+		s := NewSummarizer(nil, sf, tracker, logptest.NewTestingLogger(t, ""))
+		require.Equal(t, 10*time.Second, s.nextRetryDelay())
+
+		sf.Retest.Schedule = schedule.MustParse("@every 5s")
+		s = NewSummarizer(nil, sf, tracker, logptest.NewTestingLogger(t, ""))
+		require.Equal(t, 5*time.Second, s.nextRetryDelay())
+	})
+
+	t.Run("left to the scheduler", func(t *testing.T) {
+		sf := stdfields.StdMonitorFields{
+			ID:          "testmon",
+			Type:        "http",
+			MaxAttempts: 2,
+			Schedule:    schedule.MustParse("@every 1h"),
+			Retest:      stdfields.RetestConfig{Count: 1, Schedule: schedule.MustParse("@every 30m")},
+		}
+		runs := 0
+		job := func(event *beat.Event) ([]jobs.Job, error) {
+			runs++
+			event.Fields = mapstr.M{"monitor": mapstr.M{"id": "testmon", "status": string(monitorstate.StatusDown)}}
+			return nil, fmt.Errorf("dummyerr")
+		}
+
+		s := NewSummarizer(job, sf, tracker, logptest.NewTestingLogger(t, ""))
+		event := &beat.Event{}
+		started := time.Now()
+		conts, _ := s.Wrap(job)(event)
+		require.Len(t, conts, 1)
+		require.Less(t, time.Since(started), time.Minute)
+		require.Equal(t, 1, runs)
+
+		runAt, ok := eventext.TakeContinuationsAt(event)
+		require.True(t, ok)
+		require.WithinDuration(t, started.Add(30*time.Minute), runAt, time.Minute)
+		require.Nil(t, event.Meta)
+	})
+}
+
 type MockPlugin struct {
 	eachEvent       func(e *beat.Event, err error)
 	beforeSummary   func(e *beat.Event)
@@ -38,8 +38,8 @@ import (
 // WrapCommon applies the common wrappers that all monitor jobs get.
 func WrapCommon(js []jobs.Job,
 	stdMonFields stdfields.StdMonitorFields,
-	stateLoader monitorstate.StateLoader, logger *logp.Logger) []jobs.Job {
-	mst := monitorstate.NewTracker(stateLoader, false, logger)
+	stateLoader monitorstate.StateLoader, stateObserver monitorstate.StateObserver, logger *logp.Logger) []jobs.Job {
+	mst := monitorstate.NewTracker(stateLoader, stateObserver, false, logger)
 	var wrapped []jobs.Job
 	if stdMonFields.Type != "browser" || stdMonFields.BadConfig {
 		wrapped = WrapLightweight(js, stdMonFields, mst, logger)
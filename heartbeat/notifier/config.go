@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+// Config defines the syntax of a heartbeat.yml notifications block.
+type Config struct {
+	Flapping  FlappingConfig `config:"flapping"`
+	Notifiers []*conf.C      `config:"notifiers"`
+}
+
+// FlappingConfig configures flapping detection, a monitor is considered flapping
+// once it changed state Transitions times within Window. Individual down and recovered
+// notifications are suppressed while a monitor is flapping.
+type FlappingConfig struct {
+	Transitions int           `config:"transitions" validate:"min=0"`
+	Window      time.Duration `config:"window"`
+}
+
+// DefaultConfig returns the default notifications configuration.
+func DefaultConfig() Config {
+	return Config{
+		Flapping: FlappingConfig{
+			Transitions: 4,
+			Window:      10 * time.Minute,
+		},
+	}
+}
+
+// Validate validates the flapping configuration.
+func (c *FlappingConfig) Validate() error {
+	if c.Transitions > 0 && c.Window <= 0 {
+		return errors.New("flapping.window must be greater than zero when flapping detection is enabled")
+	}
+	return nil
+}
+
+// Unpack validates a notification kind from config.
+func (k *Kind) Unpack(s string) error {
+	switch kind := Kind(s); kind {
+	case KindDown, KindFlapping, KindRecovered:
+		*k = kind
+		return nil
+	}
+	return fmt.Errorf("unknown notification event '%s', valid events are %s, %s and %s", s, KindDown, KindFlapping, KindRecovered)
+}
+
+// notifierConfig holds the options common to all notifier types.
+type notifierConfig struct {
+	Type      string          `config:"type" validate:"required"`
+	Events    []Kind          `config:"events"`
+	RateLimit RateLimitConfig `config:"rate_limit"`
+	Timeout   time.Duration   `config:"timeout" validate:"positive"`
+}
+
+// RateLimitConfig limits the number of notifications a notifier sends, notifications
+// exceeding Limit within Interval are dropped.
+type RateLimitConfig struct {
+	Limit    int           `config:"limit" validate:"min=0"`
+	Interval time.Duration `config:"interval"`
+}
+
+func defaultNotifierConfig() notifierConfig {
+	return notifierConfig{
+		RateLimit: RateLimitConfig{
+			Limit:    10,
+			Interval: time.Minute,
+		},
+		Timeout: 10 * time.Second,
+	}
+}
+
+// Validate validates the common notifier options.
+func (c *notifierConfig) Validate() error {
+	if c.RateLimit.Limit > 0 && c.RateLimit.Interval <= 0 {
+		return errors.New("rate_limit.interval must be greater than zero when a rate limit is set")
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// queueSize is the number of notifications buffered per notifier before new ones are dropped.
+const queueSize = 100
+
+// Dispatcher observes monitor states, detects down, flapping and recovered transitions
+// and hands the resulting notifications to the configured notifiers. Delivery happens
+// asynchronously so that slow notifiers never block monitor execution.
+type Dispatcher struct {
+	flapping FlappingConfig
+	outputs  []*output
+	logger   *logp.Logger
+
+	mtx      sync.Mutex
+	monitors map[string]*monitorTracking
+
+	wg sync.WaitGroup
+	// now is swapped in tests
+	now func() time.Time
+}
+
+type monitorTracking struct {
+	status      monitorstate.StateStatus
+	transitions []time.Time
+	flapping    bool
+}
+
+type output struct {
+	name     string
+	notifier Notifier
+	events   map[Kind]bool
+	limiter  *rate.Limiter
+	timeout  time.Duration
+	queue    chan Notification
+}
+
+// NewDispatcher creates a dispatcher from a notifications config block and starts
+// a delivery worker for each configured notifier.
+func NewDispatcher(cfg *conf.C, logger *logp.Logger) (*Dispatcher, error) {
+	config := DefaultConfig()
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, fmt.Errorf("could not unpack notifications config: %w", err)
+	}
+
+	logger = logger.Named("notifier")
+	outputs := make([]*output, 0, len(config.Notifiers))
+	for i, nc := range config.Notifiers {
+		o, err := newOutput(nc, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not create notifier %d: %w", i, err)
+		}
+		outputs = append(outputs, o)
+	}
+
+	d := newDispatcher(config.Flapping, outputs, logger)
+	d.start()
+	return d, nil
+}
+
+func newDispatcher(flapping FlappingConfig, outputs []*output, logger *logp.Logger) *Dispatcher {
+	return &Dispatcher{
+		flapping: flapping,
+		outputs:  outputs,
+		logger:   logger,
+		monitors: map[string]*monitorTracking{},
+		now:      time.Now,
+	}
+}
+
+func newOutput(cfg *conf.C, logger *logp.Logger) (*output, error) {
+	common := defaultNotifierConfig()
+	if err := cfg.Unpack(&common); err != nil {
+		return nil, err
+	}
+
+	factory, err := getFactory(common.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := factory(cfg, logger.With("notifier.type", common.Type))
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s notifier: %w", common.Type, err)
+	}
+
+	// Subscribe to all events unless configured otherwise
+	if len(common.Events) == 0 {
+		common.Events = []Kind{KindDown, KindFlapping, KindRecovered}
+	}
+	events := map[Kind]bool{}
+	for _, k := range common.Events {
+		events[k] = true
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if common.RateLimit.Limit > 0 {
+		limiter = rate.NewLimiter(rate.Every(common.RateLimit.Interval/time.Duration(common.RateLimit.Limit)), common.RateLimit.Limit)
+	}
+
+	return &output{
+		name:     common.Type,
+		notifier: n,
+		events:   events,
+		limiter:  limiter,
+		timeout:  common.Timeout,
+		queue:    make(chan Notification, queueSize),
+	}, nil
+}
+
+func (d *Dispatcher) start() {
+	for _, o := range d.outputs {
+		d.wg.Add(1)
+		go func(o *output) {
+			defer d.wg.Done()
+			for n := range o.queue {
+				d.deliver(o, n)
+			}
+		}(o)
+	}
+}
+
+func (d *Dispatcher) deliver(o *output, n Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	if err := o.notifier.Notify(ctx, n); err != nil {
+		d.logger.Warnf("%s notifier failed to send %s notification for monitor %s: %s", o.name, n.Kind, n.Monitor.ID, err)
+		return
+	}
+	d.logger.Debugf("%s notifier sent %s notification for monitor %s", o.name, n.Kind, n.Monitor.ID)
+}
+
+// Stop stops accepting notifications and waits for queued ones to be delivered.
+func (d *Dispatcher) Stop() {
+	d.mtx.Lock()
+	for _, o := range d.outputs {
+		close(o.queue)
+	}
+	d.outputs = nil
+	d.mtx.Unlock()
+
+	d.wg.Wait()
+}
+
+// Observe records the latest state of a monitor and emits notifications for state changes,
+// it matches the monitorstate.StateObserver signature.
+func (d *Dispatcher) Observe(sf stdfields.StdMonitorFields, state *monitorstate.State) {
+	if state == nil {
+		return
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, n := range d.track(sf, state) {
+		d.dispatch(n)
+	}
+}
+
+// track updates the tracking info of a monitor and returns the notifications to send, if any.
+func (d *Dispatcher) track(sf stdfields.StdMonitorFields, state *monitorstate.State) []Notification {
+	key := sf.ID
+	if sf.RunFrom != nil {
+		key = sf.RunFrom.ID + "::" + sf.ID
+	}
+	now := d.now()
+
+	mt, found := d.monitors[key]
+	if !found {
+		mt = &monitorTracking{status: state.Status}
+		d.monitors[key] = mt
+		// Only notify about a first-seen down state if it's actually new, a state
+		// resumed from a state loader has already been notified about.
+		switch {
+		case state.Status == monitorstate.StatusFlapping:
+			mt.flapping = true
+			return []Notification{newNotification(KindFlapping, sf, state, monitorstate.StatusEmpty)}
+		case state.Status == monitorstate.StatusDown && state.Checks <= 1 && state.Ends == nil:
+			return []Notification{newNotification(KindDown, sf, state, monitorstate.StatusEmpty)}
+		}
+		return nil
+	}
+
+	previous := mt.status
+	if previous == state.Status {
+		// Flapping ends once the monitor has been stable for a full window
+		if mt.flapping && state.Status != monitorstate.StatusFlapping && d.windowElapsed(mt, now) {
+			mt.flapping = false
+			mt.transitions = nil
+			return []Notification{d.stableNotification(sf, state, monitorstate.StatusFlapping)}
+		}
+		return nil
+	}
+
+	mt.status = state.Status
+	mt.transitions = append(mt.transitions, now)
+	d.pruneTransitions(mt, now)
+
+	if !mt.flapping && (state.Status == monitorstate.StatusFlapping || d.isFlapping(mt)) {
+		mt.flapping = true
+		return []Notification{newNotification(KindFlapping, sf, state, previous)}
+	}
+	if mt.flapping {
+		// Without our own flap detection only the monitor's flapping status counts
+		if d.flapping.Transitions == 0 && state.Status != monitorstate.StatusFlapping {
+			mt.flapping = false
+			return []Notification{d.stableNotification(sf, state, previous)}
+		}
+		return nil
+	}
+
+	switch state.Status {
+	case monitorstate.StatusDown:
+		return []Notification{newNotification(KindDown, sf, state, previous)}
+	case monitorstate.StatusUp:
+		if previous == monitorstate.StatusDown || previous == monitorstate.StatusFlapping {
+			return []Notification{newNotification(KindRecovered, sf, state, previous)}
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) stableNotification(sf stdfields.StdMonitorFields, state *monitorstate.State, previous monitorstate.StateStatus) Notification {
+	if state.Status == monitorstate.StatusUp {
+		return newNotification(KindRecovered, sf, state, previous)
+	}
+	return newNotification(KindDown, sf, state, previous)
+}
+
+func (d *Dispatcher) isFlapping(mt *monitorTracking) bool {
+	return d.flapping.Transitions > 0 && len(mt.transitions) >= d.flapping.Transitions
+}
+
+func (d *Dispatcher) windowElapsed(mt *monitorTracking, now time.Time) bool {
+	if len(mt.transitions) == 0 {
+		return true
+	}
+	return now.Sub(mt.transitions[len(mt.transitions)-1]) >= d.flapping.Window
+}
+
+func (d *Dispatcher) pruneTransitions(mt *monitorTracking, now time.Time) {
+	cutoff := now.Add(-d.flapping.Window)
+	idx := 0
+	for idx < len(mt.transitions) && mt.transitions[idx].Before(cutoff) {
+		idx++
+	}
+	mt.transitions = mt.transitions[idx:]
+}
+
+// dispatch enqueues a notification for every notifier subscribed to its kind, it never blocks.
+func (d *Dispatcher) dispatch(n Notification) {
+	for _, o := range d.outputs {
+		if !o.events[n.Kind] {
+			continue
+		}
+		if !o.limiter.Allow() {
+			d.logger.Warnf("%s notifier rate limit exceeded, dropping %s notification for monitor %s", o.name, n.Kind, n.Monitor.ID)
+			continue
+		}
+		select {
+		case o.queue <- n:
+		default:
+			d.logger.Warnf("%s notifier queue is full, dropping %s notification for monitor %s", o.name, n.Kind, n.Monitor.ID)
+		}
+	}
+}
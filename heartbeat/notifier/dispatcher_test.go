@@ -0,0 +1,208 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+type recordingNotifier struct {
+	mtx  sync.Mutex
+	sent []Notification
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n Notification) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func (r *recordingNotifier) kinds() []Kind {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var kinds []Kind
+	for _, n := range r.sent {
+		kinds = append(kinds, n.Kind)
+	}
+	return kinds
+}
+
+func testOutput(events ...Kind) *output {
+	if len(events) == 0 {
+		events = []Kind{KindDown, KindFlapping, KindRecovered}
+	}
+	o := &output{
+		name:     "test",
+		notifier: &recordingNotifier{},
+		events:   map[Kind]bool{},
+		limiter:  rate.NewLimiter(rate.Inf, 0),
+		timeout:  time.Second,
+		queue:    make(chan Notification, queueSize),
+	}
+	for _, k := range events {
+		o.events[k] = true
+	}
+	return o
+}
+
+func testDispatcher(t *testing.T, flapping FlappingConfig, outputs ...*output) (*Dispatcher, *time.Time) {
+	now := time.Now()
+	d := newDispatcher(flapping, outputs, logptest.NewTestingLogger(t, ""))
+	d.now = func() time.Time { return now }
+	d.start()
+	return d, &now
+}
+
+func observeStatuses(d *Dispatcher, sf stdfields.StdMonitorFields, now *time.Time, statuses ...monitorstate.StateStatus) {
+	for i, s := range statuses {
+		d.Observe(sf, &monitorstate.State{Status: s, Checks: i + 1})
+		*now = now.Add(time.Minute)
+	}
+}
+
+func TestDispatcherTransitions(t *testing.T) {
+	sf := stdfields.StdMonitorFields{ID: "mon", Name: "My Monitor", Type: "http"}
+	up, down := monitorstate.StatusUp, monitorstate.StatusDown
+
+	tests := []struct {
+		name     string
+		statuses []monitorstate.StateStatus
+		expected []Kind
+	}{
+		{"always up", []monitorstate.StateStatus{up, up, up}, nil},
+		{"new down", []monitorstate.StateStatus{down, down}, []Kind{KindDown}},
+		{"down then recovered", []monitorstate.StateStatus{up, down, down, up, up}, []Kind{KindDown, KindRecovered}},
+		{"tracker flapping", []monitorstate.StateStatus{up, monitorstate.StatusFlapping, monitorstate.StatusFlapping}, []Kind{KindFlapping}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := testOutput()
+			d, now := testDispatcher(t, FlappingConfig{Transitions: 4, Window: 10 * time.Minute}, o)
+			observeStatuses(d, sf, now, tt.statuses...)
+			d.Stop()
+
+			rec := o.notifier.(*recordingNotifier)
+			require.Equal(t, tt.expected, rec.kinds())
+			for _, n := range rec.sent {
+				require.Equal(t, "mon", n.Monitor.ID)
+				require.Equal(t, "My Monitor", n.Monitor.Name)
+			}
+		})
+	}
+}
+
+func TestDispatcherResumedDownState(t *testing.T) {
+	sf := stdfields.StdMonitorFields{ID: "mon"}
+	o := testOutput()
+	d, _ := testDispatcher(t, DefaultConfig().Flapping, o)
+
+	// A down state with prior checks was loaded from a previous run and already notified
+	d.Observe(sf, &monitorstate.State{Status: monitorstate.StatusDown, Checks: 5})
+	d.Stop()
+
+	require.Empty(t, o.notifier.(*recordingNotifier).kinds())
+}
+
+func TestDispatcherFlapping(t *testing.T) {
+	sf := stdfields.StdMonitorFields{ID: "mon"}
+	up, down := monitorstate.StatusUp, monitorstate.StatusDown
+
+	o := testOutput()
+	d, now := testDispatcher(t, FlappingConfig{Transitions: 3, Window: 10 * time.Minute}, o)
+
+	// down, recovered, then the third transition within the window starts flapping
+	observeStatuses(d, sf, now, up, down, up, down, up, down)
+	// stable, but not for the full window yet
+	observeStatuses(d, sf, now, down, down, down)
+	*now = now.Add(10 * time.Minute)
+	// stable for a full window, flapping ends
+	observeStatuses(d, sf, now, down, down)
+	d.Stop()
+
+	require.Equal(t, []Kind{KindDown, KindRecovered, KindFlapping, KindDown}, o.notifier.(*recordingNotifier).kinds())
+}
+
+func TestDispatcherEventsFilter(t *testing.T) {
+	sf := stdfields.StdMonitorFields{ID: "mon"}
+	up, down := monitorstate.StatusUp, monitorstate.StatusDown
+
+	all := testOutput()
+	recoveries := testOutput(KindRecovered)
+	d, now := testDispatcher(t, DefaultConfig().Flapping, all, recoveries)
+	observeStatuses(d, sf, now, up, down, up)
+	d.Stop()
+
+	require.Equal(t, []Kind{KindDown, KindRecovered}, all.notifier.(*recordingNotifier).kinds())
+	require.Equal(t, []Kind{KindRecovered}, recoveries.notifier.(*recordingNotifier).kinds())
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	o := testOutput()
+	o.limiter = rate.NewLimiter(rate.Every(time.Hour), 2)
+	d, now := testDispatcher(t, FlappingConfig{}, o)
+
+	for i := 0; i < 5; i++ {
+		observeStatuses(d, stdfields.StdMonitorFields{ID: "mon-" + string(rune('a'+i))}, now, monitorstate.StatusDown)
+	}
+	d.Stop()
+
+	require.Len(t, o.notifier.(*recordingNotifier).kinds(), 2)
+}
+
+func TestNewDispatcherConfig(t *testing.T) {
+	logger := logp.NewNopLogger()
+
+	_, err := NewDispatcher(conf.MustNewConfigFrom(map[string]interface{}{
+		"notifiers": []map[string]interface{}{{"type": "pager"}},
+	}), logger)
+	require.ErrorContains(t, err, "notifier type 'pager' does not exist")
+
+	_, err = NewDispatcher(conf.MustNewConfigFrom(map[string]interface{}{
+		"notifiers": []map[string]interface{}{{"type": "exec", "command": "true", "events": []string{"sideways"}}},
+	}), logger)
+	require.ErrorContains(t, err, "unknown notification event 'sideways'")
+
+	d, err := NewDispatcher(conf.MustNewConfigFrom(map[string]interface{}{
+		"flapping": map[string]interface{}{"transitions": 6, "window": "30m"},
+		"notifiers": []map[string]interface{}{
+			{"type": "exec", "command": "true", "events": []string{"down"}},
+			{"type": "webhook", "url": "https://example.com/hook"},
+		},
+	}), logger)
+	require.NoError(t, err)
+	defer d.Stop()
+
+	require.Equal(t, FlappingConfig{Transitions: 6, Window: 30 * time.Minute}, d.flapping)
+	require.Len(t, d.outputs, 2)
+	require.Equal(t, map[Kind]bool{KindDown: true}, d.outputs[0].events)
+	require.Len(t, d.outputs[1].events, 3)
+}
@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+func init() {
+	Register("exec", newExec)
+}
+
+type execConfig struct {
+	Command string   `config:"command" validate:"required"`
+	Args    []string `config:"args"`
+}
+
+// execNotifier runs a local command for each notification. Arguments are templates
+// and the notification is written as JSON to the command's stdin.
+type execNotifier struct {
+	command string
+	args    []*messageTemplate
+}
+
+func newExec(cfg *conf.C, _ *logp.Logger) (Notifier, error) {
+	var config execConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	e := &execNotifier{command: config.Command}
+	for i, a := range config.Args {
+		t, err := parseTemplate(fmt.Sprintf("args[%d]", i), a)
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, t)
+	}
+	return e, nil
+}
+
+func (e *execNotifier) Notify(ctx context.Context, n Notification) error {
+	args := make([]string, 0, len(e.args))
+	for _, t := range e.args {
+		a, err := t.render(n)
+		if err != nil {
+			return err
+		}
+		args = append(args, a)
+	}
+
+	stdin, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("could not encode notification: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.command, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("command %s failed: %w: %s", e.command, err, msg)
+		}
+		return fmt.Errorf("command %s failed: %w", e.command, err)
+	}
+	return nil
+}
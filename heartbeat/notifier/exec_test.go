@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !windows
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+func TestExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	n, err := newExec(conf.MustNewConfigFrom(map[string]interface{}{
+		"command": "sh",
+		"args":    []string{"-c", `echo "$1" > ` + out + `; cat >> ` + out, "notify", "{{.Monitor.ID}} {{.Kind}}"},
+	}), logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	require.NoError(t, n.Notify(context.Background(), testNotification(KindDown)))
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+
+	args, stdin, _ := strings.Cut(string(content), "\n")
+	require.Equal(t, "mon down", args)
+
+	var decoded Notification
+	require.NoError(t, json.Unmarshal([]byte(stdin), &decoded))
+	require.Equal(t, "My Monitor", decoded.Monitor.Name)
+}
+
+func TestExecFailure(t *testing.T) {
+	n, err := newExec(conf.MustNewConfigFrom(map[string]interface{}{
+		"command": "sh",
+		"args":    []string{"-c", "echo broken >&2; exit 3"},
+	}), logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), testNotification(KindDown))
+	require.ErrorContains(t, err, "exit status 3: broken")
+}
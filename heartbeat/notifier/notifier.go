@@ -0,0 +1,122 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Kind is the kind of state change a notification is sent for.
+type Kind string
+
+const (
+	// KindDown is sent when a monitor transitions to down.
+	KindDown Kind = "down"
+	// KindFlapping is sent when a monitor starts flapping between up and down.
+	KindFlapping Kind = "flapping"
+	// KindRecovered is sent when a monitor that was down or flapping is up again.
+	KindRecovered Kind = "recovered"
+)
+
+// Monitor holds the monitor fields available to notification templates.
+type Monitor struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	ServiceName string `json:"service_name,omitempty"`
+	RunFrom     string `json:"run_from,omitempty"`
+}
+
+// Notification describes a monitor state change, it is the data passed to notifiers
+// and to message templates.
+type Notification struct {
+	Kind      Kind                     `json:"kind"`
+	Monitor   Monitor                  `json:"monitor"`
+	State     *monitorstate.State      `json:"state"`
+	Previous  monitorstate.StateStatus `json:"previous_status,omitempty"`
+	Timestamp time.Time                `json:"@timestamp"`
+}
+
+func newNotification(kind Kind, sf stdfields.StdMonitorFields, state *monitorstate.State, previous monitorstate.StateStatus) Notification {
+	m := Monitor{
+		ID:          sf.ID,
+		Name:        sf.Name,
+		Type:        sf.Type,
+		ServiceName: sf.Service.Name,
+	}
+	if sf.RunFrom != nil {
+		m.RunFrom = sf.RunFrom.ID
+	}
+	return Notification{
+		Kind:      kind,
+		Monitor:   m,
+		State:     state,
+		Previous:  previous,
+		Timestamp: time.Now(),
+	}
+}
+
+// Notifier delivers notifications to an external system.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Factory creates a Notifier from its configuration.
+type Factory func(cfg *conf.C, logger *logp.Logger) (Notifier, error)
+
+var (
+	registryMtx sync.Mutex
+	registry    = map[string]Factory{}
+)
+
+// Register registers a notifier type, it panics if the type is already registered.
+func Register(name string, factory Factory) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("notifier type '%s' already exists", name))
+	}
+	registry[name] = factory
+}
+
+func getFactory(name string) (Factory, error) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	factory, found := registry[name]
+	if !found {
+		names := make([]string, 0, len(registry))
+		for n := range registry {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("notifier type '%s' does not exist, valid types are %s", name, strings.Join(names, ", "))
+	}
+	return factory, nil
+}
@@ -0,0 +1,179 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+func init() {
+	Register("smtp", newSMTP)
+}
+
+type smtpConfig struct {
+	Host     string            `config:"host" validate:"required"`
+	Username string            `config:"username"`
+	Password string            `config:"password"`
+	From     string            `config:"from" validate:"required"`
+	To       []string          `config:"to" validate:"required"`
+	Subject  string            `config:"subject"`
+	Body     string            `config:"body"`
+	TLS      *tlscommon.Config `config:"ssl"`
+}
+
+func (c *smtpConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Host); err != nil {
+		return fmt.Errorf("smtp host must be in the form host:port: %w", err)
+	}
+	if len(c.To) == 0 {
+		return errors.New("smtp notifier requires at least one recipient in 'to'")
+	}
+	return nil
+}
+
+// smtpNotifier sends notifications as plain text emails, STARTTLS is used
+// whenever the server supports it.
+type smtpNotifier struct {
+	config    smtpConfig
+	tlsConfig *tls.Config
+	subject   *messageTemplate
+	body      *messageTemplate
+}
+
+func newSMTP(cfg *conf.C, logger *logp.Logger) (Notifier, error) {
+	config := smtpConfig{
+		Subject: defaultSubject,
+		Body:    defaultBody,
+	}
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	s := &smtpNotifier{config: config}
+	serverName, _, _ := net.SplitHostPort(config.Host)
+	if config.TLS != nil {
+		tlsCfg, err := tlscommon.LoadTLSConfig(config.TLS, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not load smtp ssl config: %w", err)
+		}
+		if tlsCfg != nil {
+			s.tlsConfig = tlsCfg.BuildModuleClientConfig(serverName)
+		}
+	}
+	if s.tlsConfig == nil {
+		s.tlsConfig = &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+	}
+
+	var err error
+	if s.subject, err = parseTemplate("subject", config.Subject); err != nil {
+		return nil, err
+	}
+	if s.body, err = parseTemplate("body", config.Body); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *smtpNotifier) Notify(ctx context.Context, n Notification) error {
+	subject, err := s.subject.render(n)
+	if err != nil {
+		return err
+	}
+	body, err := s.body.render(n)
+	if err != nil {
+		return err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.config.Host)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	host, _, _ := net.SplitHostPort(s.config.Host)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(s.tlsConfig); err != nil {
+			return fmt.Errorf("starttls failed: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.config.From); err != nil {
+		return err
+	}
+	for _, to := range s.config.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.message(subject, body, n.Timestamp)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (s *smtpNotifier) message(subject, body string, ts time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.config.From + "\r\n")
+	b.WriteString("To: " + strings.Join(s.config.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + sanitizeHeader(subject) + "\r\n")
+	b.WriteString("Date: " + ts.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitizeHeader prevents templated values from injecting additional headers.
+func sanitizeHeader(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer runs a minimal SMTP server accepting a single message.
+func startSMTPServer(t *testing.T) (string, <-chan smtpMessage) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		tp := textproto.NewConn(conn)
+		var msg smtpMessage
+		_ = tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				_ = tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				msg.data = string(data)
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				received <- msg
+				return
+			default:
+				_ = tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func TestSMTP(t *testing.T) {
+	addr, received := startSMTPServer(t)
+
+	n, err := newSMTP(conf.MustNewConfigFrom(map[string]interface{}{
+		"host": addr,
+		"from": "heartbeat@example.com",
+		"to":   []string{"ops@example.com", "oncall@example.com"},
+	}), logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, n.Notify(ctx, testNotification(KindDown)))
+
+	msg := <-received
+	require.Equal(t, "heartbeat@example.com", msg.from)
+	require.Equal(t, []string{"ops@example.com", "oncall@example.com"}, msg.to)
+
+	tpr := textproto.NewReader(bufio.NewReader(strings.NewReader(msg.data)))
+	headers, err := tpr.ReadMIMEHeader()
+	require.NoError(t, err)
+	require.Equal(t, "[Heartbeat] My Monitor (mon) is down", headers.Get("Subject"))
+	require.Equal(t, "ops@example.com, oncall@example.com", headers.Get("To"))
+	require.Contains(t, msg.data, "Monitor My Monitor (mon, type http) is down.")
+	require.Contains(t, msg.data, "Previous status: up")
+}
+
+func TestSMTPConfig(t *testing.T) {
+	_, err := newSMTP(conf.MustNewConfigFrom(map[string]interface{}{
+		"host": "localhost",
+		"from": "heartbeat@example.com",
+		"to":   []string{"ops@example.com"},
+	}), logptest.NewTestingLogger(t, ""))
+	require.ErrorContains(t, err, "host:port")
+
+	_, err = newSMTP(conf.MustNewConfigFrom(map[string]interface{}{
+		"host": "localhost:25",
+		"from": "heartbeat@example.com",
+	}), logptest.NewTestingLogger(t, ""))
+	require.Error(t, err)
+}
+
+func TestSanitizeHeader(t *testing.T) {
+	require.Equal(t, "down Bcc: evil@example.com", sanitizeHeader("down\nBcc: evil@example.com"))
+}
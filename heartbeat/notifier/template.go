@@ -0,0 +1,67 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultSubject = `[Heartbeat] {{.Monitor.Name}} ({{.Monitor.ID}}) is {{.Kind}}`
+	defaultBody    = `Monitor {{.Monitor.Name}} ({{.Monitor.ID}}, type {{.Monitor.Type}}) is {{.Kind}}.
+{{- if .Previous}}
+Previous status: {{.Previous}}{{end}}
+{{- if .State}}
+Current status: {{.State.Status}} since {{.State.StartedAt}}, {{.State.Up}} up / {{.State.Down}} down checks.{{end}}
+Time: {{.Timestamp}}
+`
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// messageTemplate is a text template rendered with a Notification.
+type messageTemplate struct {
+	tmpl *template.Template
+}
+
+func parseTemplate(name, text string) (*messageTemplate, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s template: %w", name, err)
+	}
+	return &messageTemplate{tmpl: t}, nil
+}
+
+func (t *messageTemplate) render(n Notification) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, n); err != nil {
+		return "", fmt.Errorf("could not render %s template: %w", t.tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
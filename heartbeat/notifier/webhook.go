@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+)
+
+func init() {
+	Register("webhook", newWebhook)
+}
+
+type webhookConfig struct {
+	URL         string                           `config:"url" validate:"required"`
+	Method      string                           `config:"method"`
+	Headers     map[string]string                `config:"headers"`
+	Body        string                           `config:"body"`
+	ContentType string                           `config:"content_type"`
+	Transport   httpcommon.HTTPTransportSettings `config:",inline"`
+}
+
+func (c *webhookConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use http or https, got '%s'", u.Scheme)
+	}
+	switch strings.ToUpper(c.Method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("unsupported webhook method '%s', valid methods are POST, PUT and PATCH", c.Method)
+	}
+	return nil
+}
+
+// webhook sends notifications as HTTP requests. Without a body template the
+// notification is sent as JSON.
+type webhook struct {
+	config webhookConfig
+	client *http.Client
+	body   *messageTemplate
+}
+
+func newWebhook(cfg *conf.C, logger *logp.Logger) (Notifier, error) {
+	config := webhookConfig{
+		Method:    http.MethodPost,
+		Transport: httpcommon.DefaultHTTPTransportSettings(),
+	}
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	client, err := config.Transport.Client(httpcommon.WithLogger(logger))
+	if err != nil {
+		return nil, fmt.Errorf("could not create webhook client: %w", err)
+	}
+
+	w := &webhook{config: config, client: client}
+	if config.Body != "" {
+		w.body, err = parseTemplate("body", config.Body)
+		if err != nil {
+			return nil, err
+		}
+		if w.config.ContentType == "" {
+			w.config.ContentType = "text/plain; charset=utf-8"
+		}
+	} else if w.config.ContentType == "" {
+		w.config.ContentType = "application/json"
+	}
+	return w, nil
+}
+
+func (w *webhook) Notify(ctx context.Context, n Notification) error {
+	var body string
+	if w.body != nil {
+		var err error
+		if body, err = w.body.render(n); err != nil {
+			return err
+		}
+	} else {
+		b, err := json.Marshal(n)
+		if err != nil {
+			return fmt.Errorf("could not encode notification: %w", err)
+		}
+		body = string(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(w.config.Method), w.config.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.config.ContentType)
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.New("webhook returned " + resp.Status + ": " + strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
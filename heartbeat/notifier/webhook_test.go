@@ -0,0 +1,107 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/heartbeat/monitors/stdfields"
+	"github.com/elastic/beats/v7/heartbeat/monitors/wrappers/monitorstate"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+func testNotification(kind Kind) Notification {
+	sf := stdfields.StdMonitorFields{ID: "mon", Name: "My Monitor", Type: "http"}
+	return newNotification(kind, sf, &monitorstate.State{Status: monitorstate.StatusDown, Checks: 1, Down: 1}, monitorstate.StatusUp)
+}
+
+func TestWebhook(t *testing.T) {
+	var gotMethod, gotContentType, gotHeader string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotContentType = r.Header.Get("Content-Type")
+		gotHeader = r.Header.Get("X-Token")
+		gotBody, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("json body", func(t *testing.T) {
+		n, err := newWebhook(conf.MustNewConfigFrom(map[string]interface{}{
+			"url":     srv.URL + "/hook",
+			"headers": map[string]string{"X-Token": "secret"},
+		}), logptest.NewTestingLogger(t, ""))
+		require.NoError(t, err)
+		require.NoError(t, n.Notify(context.Background(), testNotification(KindDown)))
+
+		require.Equal(t, http.MethodPost, gotMethod)
+		require.Equal(t, "application/json", gotContentType)
+		require.Equal(t, "secret", gotHeader)
+
+		var decoded Notification
+		require.NoError(t, json.Unmarshal(gotBody, &decoded))
+		require.Equal(t, KindDown, decoded.Kind)
+		require.Equal(t, "mon", decoded.Monitor.ID)
+		require.Equal(t, monitorstate.StatusUp, decoded.Previous)
+	})
+
+	t.Run("templated body", func(t *testing.T) {
+		n, err := newWebhook(conf.MustNewConfigFrom(map[string]interface{}{
+			"url":    srv.URL + "/hook",
+			"method": "put",
+			"body":   `{"text": "{{.Monitor.Name}} is {{upper (print .Kind)}}"}`,
+		}), logptest.NewTestingLogger(t, ""))
+		require.NoError(t, err)
+		require.NoError(t, n.Notify(context.Background(), testNotification(KindRecovered)))
+
+		require.Equal(t, http.MethodPut, gotMethod)
+		require.Equal(t, `{"text": "My Monitor is RECOVERED"}`, string(gotBody))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		n, err := newWebhook(conf.MustNewConfigFrom(map[string]interface{}{
+			"url": srv.URL + "/fail",
+		}), logptest.NewTestingLogger(t, ""))
+		require.NoError(t, err)
+		require.ErrorContains(t, n.Notify(context.Background(), testNotification(KindDown)), "502")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := newWebhook(conf.MustNewConfigFrom(map[string]interface{}{
+			"url": "ftp://example.com",
+		}), logptest.NewTestingLogger(t, ""))
+		require.ErrorContains(t, err, "must use http or https")
+
+		_, err = newWebhook(conf.MustNewConfigFrom(map[string]interface{}{
+			"url":  srv.URL,
+			"body": "{{.Monitor.Name",
+		}), logptest.NewTestingLogger(t, ""))
+		require.ErrorContains(t, err, "could not parse body template")
+	})
+}
@@ -43,15 +43,32 @@ type schedJob struct {
 // Subtasks are run in separate goroutines.
 // returns the time execution began on its first task
 func newSchedJob(ctx context.Context, s *Scheduler, id string, jobType string, task TaskFunc, logger *logp.Logger) *schedJob {
-	return &schedJob{
+	sj := &schedJob{
 		id:          id,
-		ctx:         ctx,
 		scheduler:   s,
 		jobLimitSem: s.jobLimitSem[jobType],
 		entrypoint:  task,
 		wg:          &sync.WaitGroup{},
 		logger:      logger,
 	}
+	sj.ctx = context.WithValue(ctx, schedJobKey{}, sj)
+	return sj
+}
+
+// schedJobKey is the context key under which tasks find the job they belong to.
+type schedJobKey struct{}
+
+// later returns a new run of this job, with the given task as entry point.
+func (sj *schedJob) later(task TaskFunc) *schedJob {
+	return &schedJob{
+		id:          sj.id,
+		ctx:         sj.ctx,
+		scheduler:   sj.scheduler,
+		jobLimitSem: sj.jobLimitSem,
+		entrypoint:  task,
+		wg:          &sync.WaitGroup{},
+		logger:      sj.logger,
+	}
 }
 
 // runRecursiveTask runs an individual task and its continuations until none are left with as much parallelism as possible.
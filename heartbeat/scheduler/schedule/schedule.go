@@ -74,6 +74,26 @@ func (s intervalScheduler) Next(t time.Time) time.Time {
 	return t.Add(s.interval)
 }
 
+// periodSamples is the number of consecutive runs inspected to find the period
+// of a schedule.
+const periodSamples = 16
+
+// Period returns the shortest time between two consecutive runs of the schedule,
+// among the runs following the given time. This is the interval of interval
+// schedules.
+func (s *Schedule) Period(from time.Time) time.Duration {
+	var period time.Duration
+	prev := s.Next(from)
+	for i := 0; i < periodSamples; i++ {
+		next := s.Next(prev)
+		if d := next.Sub(prev); period == 0 || d < period {
+			period = d
+		}
+		prev = next
+	}
+	return period
+}
+
 func (s *Schedule) Unpack(str string) error {
 	tmp, err := Parse(str)
 	if err == nil {
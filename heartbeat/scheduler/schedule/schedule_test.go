@@ -169,3 +169,21 @@ func cronMustParse(tb testing.TB, in string) *cron.Schedule {
 	require.NoError(tb, err)
 	return s
 }
+
+func TestPeriod(t *testing.T) {
+	from := time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+	tests := []struct {
+		schedStr string
+		want     time.Duration
+	}{
+		{"@every 30s", 30 * time.Second},
+		{"* * * * *", time.Minute},
+		{"*/15 4 * 2 *", 15 * time.Minute},
+		{"0 9,10 * * *", time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedStr, func(t *testing.T) {
+			require.Equal(t, tt.want, MustParse(tt.schedStr).Period(from))
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"math"
 	"sync"
 	"time"
@@ -171,20 +172,50 @@ func (s *Scheduler) WaitForRunOnce() {
 // has already stopped.
 var ErrAlreadyStopped = errors.New("attempted to add job to already stopped scheduler")
 
-type AddTask func(sched Schedule, pmws []maintwin.ParsedMaintWin, id string, entrypoint TaskFunc, jobType string) (removeFn context.CancelFunc, err error)
+// JobOpts holds per-job scheduling options. The zero value schedules the job in the
+// scheduler's location without any jitter.
+type JobOpts struct {
+	// Location overrides the scheduler location used to compute the job's next run,
+	// which matters for cron schedules.
+	Location *time.Location
+	// Jitter is the upper bound of the offset applied to the job's runs. Each job gets a
+	// stable offset in [0, Jitter) derived from its ID, so that many jobs sharing the same
+	// schedule are spread out rather than all fired at once.
+	Jitter time.Duration
+}
+
+type AddTask func(sched Schedule, pmws []maintwin.ParsedMaintWin, id string, entrypoint TaskFunc, jobType string, opts JobOpts) (removeFn context.CancelFunc, err error)
 
 // Add adds the given TaskFunc to the current scheduler. Will return an error if the scheduler
 // is done.
-func (s *Scheduler) Add(sched Schedule, pmws []maintwin.ParsedMaintWin, id string, entrypoint TaskFunc, jobType string) (removeFn context.CancelFunc, err error) {
+func (s *Scheduler) Add(sched Schedule, pmws []maintwin.ParsedMaintWin, id string, entrypoint TaskFunc, jobType string, opts JobOpts) (removeFn context.CancelFunc, err error) {
 	if errors.Is(s.ctx.Err(), context.Canceled) {
 		return nil, ErrAlreadyStopped
 	}
 
 	jobCtx, jobCtxCancel := context.WithCancel(s.ctx)
 
+	location := s.location
+	if opts.Location != nil {
+		location = opts.Location
+	}
+	offset := jitterOffset(id, opts.Jitter)
+
+	// nextRunAt returns the time the job should next run after the given time.
+	// Interval schedules are relative to the last run, so the offset applied to the first
+	// run carries over, while cron schedules are aligned to the clock and need it on every run.
+	// The last run of a cron job already includes the offset, it is removed to find the next
+	// slot, otherwise slots would be skipped.
+	nextRunAt := func(after time.Time) time.Time {
+		if sched.RunOnInit() {
+			return sched.Next(after.In(location))
+		}
+		return sched.Next(after.Add(-offset).In(location)).Add(offset)
+	}
+
 	// lastRanAt stores the last runAt the task was invoked
 	// The initial value is runAt.Now() because we use it to get the next runAt a job is scheduled to run
-	lastRanAt := time.Now().In(s.location)
+	lastRanAt := time.Now().In(location)
 
 	var taskFn timerqueue.TimerTaskFn
 
@@ -221,7 +252,7 @@ func (s *Scheduler) Add(sched Schedule, pmws []maintwin.ParsedMaintWin, id strin
 			s.runOnceWg.Done()
 		} else {
 			// Schedule the next run
-			s.runTaskOnce(sched.Next(lastRanAt), taskFn, true)
+			s.runTaskOnce(nextRunAt(lastRanAt), taskFn, true)
 		}
 		debugf("Job '%v' returned at %v", id, time.Now())
 	}
@@ -230,12 +261,14 @@ func (s *Scheduler) Add(sched Schedule, pmws []maintwin.ParsedMaintWin, id strin
 		s.runOnceWg.Add(1)
 	}
 
-	// Run non-cron tasks immediately, or run all tasks immediately if we're
-	// in RunOnce mode
-	if s.runOnce || sched.RunOnInit() {
+	// Run non-cron tasks immediately (after their jitter offset), or run all tasks
+	// immediately if we're in RunOnce mode
+	if s.runOnce {
 		s.runTaskOnce(time.Now(), taskFn, false)
+	} else if sched.RunOnInit() {
+		s.runTaskOnce(time.Now().Add(offset), taskFn, false)
 	} else {
-		s.runTaskOnce(sched.Next(lastRanAt), taskFn, true)
+		s.runTaskOnce(nextRunAt(lastRanAt), taskFn, true)
 	}
 
 	return func() {
@@ -244,6 +277,50 @@ func (s *Scheduler) Add(sched Schedule, pmws []maintwin.ParsedMaintWin, id strin
 	}, nil
 }
 
+// jitterOffset returns a stable offset in [0, jitter) for the given job ID.
+func jitterOffset(id string, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return time.Duration(h.Sum64() % uint64(jitter))
+}
+
+// RunLater schedules task to run at runAt as a new run of the job the given context
+// belongs to, which must be the context passed to a TaskFunc by the scheduler. Unlike
+// continuations the task does not hold the job's execution slots while it waits, it
+// acquires them again when it runs. The task is dropped if the job is removed meanwhile.
+// Returns false if the context does not belong to a scheduled job.
+func RunLater(ctx context.Context, runAt time.Time, task TaskFunc) bool {
+	sj, ok := ctx.Value(schedJobKey{}).(*schedJob)
+	if !ok {
+		return false
+	}
+	s := sj.scheduler
+	if s.runOnce {
+		s.runOnceWg.Add(1)
+	}
+	pushed := s.timerQueue.Push(runAt, func(now time.Time) {
+		go func() {
+			if s.runOnce {
+				defer s.runOnceWg.Done()
+			}
+			if sj.ctx.Err() != nil {
+				debugf("Job '%v' canceled", sj.id)
+				return
+			}
+			s.stats.activeJobs.Inc()
+			sj.later(task).run()
+			s.stats.activeJobs.Dec()
+		}()
+	})
+	if !pushed && s.runOnce {
+		s.runOnceWg.Done()
+	}
+	return true
+}
+
 // runTaskOnce runs the given task exactly once at the given time. Set deadlineCheck
 // to false if this is the first invocation of this, otherwise the deadline checker
 // will complain about a missed task
@@ -98,7 +98,7 @@ func TestSchedulerRun(t *testing.T) {
 			return nil
 		}
 		return []TaskFunc{cont}
-	}), "http")
+	}), "http", JobOpts{})
 	require.NoError(t, err)
 
 	removedEvents := uint32(1)
@@ -114,7 +114,7 @@ func TestSchedulerRun(t *testing.T) {
 	}
 	// Attempt to execute this twice to see if remove() had any effect
 	removeMtx.Lock()
-	remove, err = s.Add(testSchedule{}, mainWins, "removed", testTaskTimes(removedEvents+1, testFn), "http")
+	remove, err = s.Add(testSchedule{}, mainWins, "removed", testTaskTimes(removedEvents+1, testFn), "http", JobOpts{})
 	require.NoError(t, err)
 	require.NotNil(t, remove)
 	removeMtx.Unlock()
@@ -127,7 +127,7 @@ func TestSchedulerRun(t *testing.T) {
 			return nil
 		}
 		return []TaskFunc{cont}
-	}), "http")
+	}), "http", JobOpts{})
 	require.NoError(t, err)
 
 	received := make([]string, 0)
@@ -159,6 +159,118 @@ func TestSchedulerRun(t *testing.T) {
 	assert.Equal(t, int(postRemoveEvents), int(counts["postRemoveCont"]))
 }
 
+func TestJitterOffset(t *testing.T) {
+	require.Zero(t, jitterOffset("myid", 0))
+
+	jitter := time.Minute
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("monitor-%d", i)
+		offset := jitterOffset(id, jitter)
+		require.GreaterOrEqual(t, offset, time.Duration(0))
+		require.Less(t, offset, jitter)
+		// offsets must be stable for a given ID
+		require.Equal(t, offset, jitterOffset(id, jitter))
+		seen[offset.Truncate(time.Second)] = true
+	}
+	// 1000 monitors should be spread over most of the 60 one second buckets
+	require.Greater(t, len(seen), 50)
+}
+
+// locationRecordingSchedule is a clock aligned schedule that records the location
+// of the times it is evaluated with.
+type locationRecordingSchedule struct {
+	locations chan *time.Location
+}
+
+func (locationRecordingSchedule) RunOnInit() bool {
+	return false
+}
+
+func (s locationRecordingSchedule) Next(now time.Time) time.Time {
+	s.locations <- now.Location()
+	return now.Add(time.Hour)
+}
+
+// alignedSchedule is a clock aligned schedule, like cron schedules, that runs at
+// every multiple of its period.
+type alignedSchedule struct {
+	period time.Duration
+}
+
+func (alignedSchedule) RunOnInit() bool {
+	return false
+}
+
+func (s alignedSchedule) Next(now time.Time) time.Time {
+	return now.Truncate(s.period).Add(s.period)
+}
+
+func TestSchedulerJobOpts(t *testing.T) {
+	s := Create(10, monitoring.NewRegistry(), tarawaTime(), nil, false, logptest.NewTestingLogger(t, ""))
+	defer s.Stop()
+
+	t.Run("jitter delays the first run", func(t *testing.T) {
+		jitter := 200 * time.Millisecond
+		expectedOffset := jitterOffset("jittered", jitter)
+		addedAt := time.Now()
+		executed := make(chan time.Time, 1)
+		_, err := s.Add(testSchedule{time.Hour}, nil, "jittered", func(_ context.Context) []TaskFunc {
+			executed <- time.Now()
+			return nil
+		}, "http", JobOpts{Jitter: jitter})
+		require.NoError(t, err)
+
+		select {
+		case ranAt := <-executed:
+			require.GreaterOrEqual(t, ranAt.Sub(addedAt), expectedOffset)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "jittered job never ran")
+		}
+	})
+
+	t.Run("jitter larger than the period of a cron schedule skips no run", func(t *testing.T) {
+		period := 200 * time.Millisecond
+		jitter := 2 * time.Second
+		offset := jitterOffset("cron-jittered", jitter)
+		require.Greater(t, offset, 3*period)
+
+		executed := make(chan time.Time, 10)
+		remove, err := s.Add(alignedSchedule{period}, nil, "cron-jittered", func(_ context.Context) []TaskFunc {
+			executed <- time.Now()
+			return nil
+		}, "http", JobOpts{Jitter: jitter})
+		require.NoError(t, err)
+		defer remove()
+
+		var runs []time.Time
+		for len(runs) < 3 {
+			select {
+			case ranAt := <-executed:
+				runs = append(runs, ranAt)
+			case <-time.After(jitter + 2*period):
+				require.Fail(t, "cron job with jitter did not run")
+			}
+		}
+		for i := 1; i < len(runs); i++ {
+			require.Less(t, runs[i].Sub(runs[i-1]), 2*period)
+		}
+	})
+
+	t.Run("location overrides the scheduler location", func(t *testing.T) {
+		kathmandu, err := time.LoadLocation("Asia/Kathmandu")
+		require.NoError(t, err)
+
+		sched := locationRecordingSchedule{locations: make(chan *time.Location, 1)}
+		_, err = s.Add(sched, nil, "tz", func(_ context.Context) []TaskFunc {
+			return nil
+		}, "http", JobOpts{Location: kathmandu})
+		require.NoError(t, err)
+
+		require.Equal(t, kathmandu, <-sched.locations)
+	})
+}
+
 func TestScheduler_WaitForRunOnce(t *testing.T) {
 	s := Create(10, monitoring.NewRegistry(), tarawaTime(), nil, true, logptest.NewTestingLogger(t, ""))
 
@@ -177,13 +289,74 @@ func TestScheduler_WaitForRunOnce(t *testing.T) {
 			return nil
 		}
 		return []TaskFunc{cont}
-	}, "http")
+	}, "http", JobOpts{})
 	require.NoError(t, err)
 
 	s.WaitForRunOnce()
 	require.Equal(t, uint32(1), atomic.LoadUint32(executed))
 }
 
+func TestRunLater(t *testing.T) {
+	t.Run("waiting task does not block other jobs", func(t *testing.T) {
+		jobLimits := map[string]*config.JobLimit{"http": {Limit: 1}}
+		s := Create(1, monitoring.NewRegistry(), tarawaTime(), jobLimits, false, logptest.NewTestingLogger(t, ""))
+		defer s.Stop()
+
+		executed := make(chan string, 3)
+		_, err := s.Add(testSchedule{time.Hour}, nil, "down", testTaskTimes(1, func(ctx context.Context) []TaskFunc {
+			executed <- "down"
+			require.True(t, RunLater(ctx, time.Now().Add(500*time.Millisecond), func(_ context.Context) []TaskFunc {
+				executed <- "retest"
+				return nil
+			}))
+			return nil
+		}), "http", JobOpts{})
+		require.NoError(t, err)
+		require.Equal(t, "down", <-executed)
+
+		_, err = s.Add(testSchedule{time.Hour}, nil, "up", testTaskTimes(1, func(_ context.Context) []TaskFunc {
+			executed <- "up"
+			return nil
+		}), "http", JobOpts{})
+		require.NoError(t, err)
+
+		for _, expected := range []string{"up", "retest"} {
+			select {
+			case got := <-executed:
+				require.Equal(t, expected, got)
+			case <-time.After(5 * time.Second):
+				require.Failf(t, "timed out", "waiting for %s", expected)
+			}
+		}
+	})
+
+	t.Run("dropped when the job is removed", func(t *testing.T) {
+		s := Create(10, monitoring.NewRegistry(), tarawaTime(), nil, false, logptest.NewTestingLogger(t, ""))
+		defer s.Stop()
+
+		scheduled := make(chan struct{})
+		retested := &atomic.Bool{}
+		remove, err := s.Add(testSchedule{time.Hour}, nil, "removed", testTaskTimes(1, func(ctx context.Context) []TaskFunc {
+			RunLater(ctx, time.Now().Add(100*time.Millisecond), func(_ context.Context) []TaskFunc {
+				retested.Store(true)
+				return nil
+			})
+			close(scheduled)
+			return nil
+		}), "http", JobOpts{})
+		require.NoError(t, err)
+		<-scheduled
+		remove()
+
+		time.Sleep(300 * time.Millisecond)
+		require.False(t, retested.Load())
+	})
+
+	t.Run("requires a scheduler context", func(t *testing.T) {
+		require.False(t, RunLater(context.Background(), time.Now(), func(_ context.Context) []TaskFunc { return nil }))
+	})
+}
+
 func TestScheduler_Stop(t *testing.T) {
 	s := Create(10, monitoring.NewRegistry(), tarawaTime(), nil, false, logptest.NewTestingLogger(t, ""))
 
@@ -196,7 +369,7 @@ func TestScheduler_Stop(t *testing.T) {
 	_, err := s.Add(testSchedule{}, mainWins, "testPostStop", testTaskTimes(1, func(_ context.Context) []TaskFunc {
 		executed <- struct{}{}
 		return nil
-	}), "http")
+	}), "http", JobOpts{})
 
 	assert.Equal(t, ErrAlreadyStopped, err)
 }
@@ -297,7 +470,7 @@ func BenchmarkScheduler(b *testing.B) {
 		_, err := s.Add(sched, mainWins, "testPostStop", func(_ context.Context) []TaskFunc {
 			executed <- struct{}{}
 			return nil
-		}, "http")
+		}, "http", JobOpts{})
 		assert.NoError(b, err)
 	}
 
@@ -214,6 +214,24 @@ func (c *Cache) CleanUp() int {
 	return count
 }
 
+// Flush removes all elements from the cache, whether expired or not. If a
+// RemovalListener is registered it will be invoked for each element that is
+// removed. Flush is used to process all pending elements at the end of the
+// input, the RemovalListener is invoked on the caller's goroutine.
+func (c *Cache) Flush() int {
+	c.Lock()
+	defer c.Unlock()
+	count := 0
+	for k, v := range c.elements {
+		delete(c.elements, k)
+		count++
+		if c.listener != nil {
+			c.listener(k, v.value)
+		}
+	}
+	return count
+}
+
 // Entries returns a shallow copy of the non-expired elements in the cache.
 func (c *Cache) Entries() map[Key]Value {
 	c.RLock()
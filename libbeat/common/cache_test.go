@@ -77,6 +77,21 @@ func TestExpireWithoutRemovalListener(t *testing.T) {
 	assert.Equal(t, 2, c.CleanUp())
 }
 
+// Test that Flush removes unexpired elements and invokes the RemovalListener.
+func TestFlush(t *testing.T) {
+	removed := map[Key]Value{}
+	c := newCache(Timeout, true, InitalSize, func(k Key, v Value) {
+		removed[k] = v
+	}, fakeClock)
+	c.Put(alphaKey, alphaValue)
+	c.Put(bravoKey, bravoValue)
+
+	assert.Equal(t, 2, c.Flush())
+	assert.Equal(t, map[Key]Value{alphaKey: alphaValue, bravoKey: bravoValue}, removed)
+	assert.Equal(t, 0, c.Size())
+	assert.Equal(t, 0, c.Flush())
+}
+
 func TestPutIfAbsent(t *testing.T) {
 	c := newCache(Timeout, true, InitalSize, nil, fakeClock)
 	oldValue := c.PutIfAbsent(alphaKey, alphaValue)
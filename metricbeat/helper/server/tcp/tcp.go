@@ -74,6 +74,10 @@ func NewTcpServer(base mb.BaseMetricSet) (server.Server, error) {
 	}, nil
 }
 
+func (g *TcpServer) GetHost() string {
+	return g.tcpAddr.String()
+}
+
 func (g *TcpServer) Start() error {
 	listener, err := net.ListenTCP("tcp", g.tcpAddr)
 	if err != nil {
@@ -131,10 +135,14 @@ func (g *TcpServer) handle(conn net.Conn) {
 
 		// Drop the delimiter and send the data
 		if len(bytes) > 0 {
-			g.eventQueue <- &TcpEvent{
+			select {
+			case g.eventQueue <- &TcpEvent{
 				event: mapstr.M{
 					server.EventDataKey: bytes[:len(bytes)-1],
 				},
+			}:
+			case <-g.done:
+				return
 			}
 		}
 
@@ -148,5 +156,6 @@ func (g *TcpServer) GetEvents() chan server.Event {
 func (g *TcpServer) Stop() {
 	close(g.done)
 	g.listener.Close()
-	close(g.eventQueue)
+	// The event queue is not closed, as connections may still be sending
+	// events. They stop once done is closed.
 }
@@ -18,6 +18,7 @@
 package udp
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
@@ -100,6 +101,9 @@ func (g *UdpServer) watchMetrics() {
 
 		length, addr, err := g.listener.ReadFromUDP(buffer)
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			g.logger.Errorf("Error reading from buffer: %v", err.Error())
 			continue
 		}
@@ -107,7 +111,7 @@ func (g *UdpServer) watchMetrics() {
 		bufCopy := make([]byte, length)
 		copy(bufCopy, buffer)
 
-		g.eventQueue <- &UdpEvent{
+		event := &UdpEvent{
 			event: mapstr.M{
 				server.EventDataKey: bufCopy,
 			},
@@ -115,6 +119,13 @@ func (g *UdpServer) watchMetrics() {
 				"client_ip": addr.IP.String(),
 			},
 		}
+		// The consumer may have stopped reading events before stopping the
+		// server.
+		select {
+		case g.eventQueue <- event:
+		case <-g.done:
+			return
+		}
 	}
 }
 
@@ -125,5 +136,4 @@ func (g *UdpServer) GetEvents() chan server.Event {
 func (g *UdpServer) Stop() {
 	close(g.done)
 	g.listener.Close()
-	close(g.eventQueue)
 }
@@ -219,6 +219,8 @@ func SwitchDriverName(d string) string {
 		return "postgres"
 	case "postgresql":
 		return "postgres"
+	case "sqlite":
+		return "sqlite3"
 	case "mssql":
 		// Use the modern sqlserver driver instead of the deprecated mssql driver.
 		// The sqlserver driver uses native @Name or @p1..@pN parameter syntax.
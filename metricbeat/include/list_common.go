@@ -84,9 +84,12 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/http"
 	_ "github.com/elastic/beats/v7/metricbeat/module/http/json"
 	_ "github.com/elastic/beats/v7/metricbeat/module/http/server"
+	_ "github.com/elastic/beats/v7/metricbeat/module/influxdb"
+	_ "github.com/elastic/beats/v7/metricbeat/module/influxdb/write"
 	_ "github.com/elastic/beats/v7/metricbeat/module/jolokia"
 	_ "github.com/elastic/beats/v7/metricbeat/module/jolokia/jmx"
 	_ "github.com/elastic/beats/v7/metricbeat/module/kafka"
+	_ "github.com/elastic/beats/v7/metricbeat/module/kafka/consumer_lag"
 	_ "github.com/elastic/beats/v7/metricbeat/module/kafka/consumergroup"
 	_ "github.com/elastic/beats/v7/metricbeat/module/kafka/partition"
 	_ "github.com/elastic/beats/v7/metricbeat/module/kibana"
@@ -101,12 +104,14 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/kvm/status"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/conntrack"
+	_ "github.com/elastic/beats/v7/metricbeat/module/linux/disk_health"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/iostat"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/ksm"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/memory"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/pageinfo"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/pressure"
 	_ "github.com/elastic/beats/v7/metricbeat/module/linux/rapl"
+	_ "github.com/elastic/beats/v7/metricbeat/module/linux/systemd"
 	_ "github.com/elastic/beats/v7/metricbeat/module/logstash"
 	_ "github.com/elastic/beats/v7/metricbeat/module/logstash/node"
 	_ "github.com/elastic/beats/v7/metricbeat/module/logstash/node_stats"
@@ -133,7 +138,9 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/nats/stats"
 	_ "github.com/elastic/beats/v7/metricbeat/module/nats/subscriptions"
 	_ "github.com/elastic/beats/v7/metricbeat/module/nginx"
+	_ "github.com/elastic/beats/v7/metricbeat/module/nginx/plus"
 	_ "github.com/elastic/beats/v7/metricbeat/module/nginx/stubstatus"
+	_ "github.com/elastic/beats/v7/metricbeat/module/nginx/vts"
 	_ "github.com/elastic/beats/v7/metricbeat/module/openmetrics"
 	_ "github.com/elastic/beats/v7/metricbeat/module/openmetrics/collector"
 	_ "github.com/elastic/beats/v7/metricbeat/module/php_fpm"
@@ -143,7 +150,11 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/activity"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/bgwriter"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/database"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/locks"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/replication"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/statement"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/table"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/wal"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus/collector"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus/query"
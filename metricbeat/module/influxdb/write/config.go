@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Grouping of the points in events.
+const (
+	// groupByMeasurement stores the points with the same measurement, tags
+	// and timestamp in the same event.
+	groupByMeasurement = "measurement"
+	// groupByTags stores the points with the same tags and timestamp in the
+	// same event, whatever their measurement.
+	groupByTags = "tags"
+)
+
+type config struct {
+	Protocol string `config:"protocol"`
+	GroupBy  string `config:"group_by"`
+	// Precision of the timestamps of UDP datagrams and of HTTP requests
+	// without a precision parameter.
+	Precision    string `config:"precision"`
+	MaxBodyBytes int64  `config:"max_body_bytes" validate:"positive"`
+}
+
+func defaultConfig() config {
+	return config{
+		Protocol:     "http",
+		GroupBy:      groupByMeasurement,
+		Precision:    "ns",
+		MaxBodyBytes: 10 * 1024 * 1024,
+	}
+}
+
+func (c config) Validate() error {
+	if c.Protocol != "http" && c.Protocol != "udp" {
+		return errors.New("`protocol` can only be http or udp")
+	}
+	if c.GroupBy != groupByMeasurement && c.GroupBy != groupByTags {
+		return fmt.Errorf("`group_by` can only be %s or %s", groupByMeasurement, groupByTags)
+	}
+	if _, err := parsePrecision(c.Precision); err != nil {
+		return fmt.Errorf("invalid `precision`: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// toEvents groups points in events. Fields of the points are stored under
+// `metrics.<measurement>`, and their tags under `tags`. Events are returned in
+// the order of their first point.
+func toEvents(points []point, groupBy, database string) []mb.Event {
+	var keys []string
+	events := map[string]mb.Event{}
+	for _, p := range points {
+		key := seriesKey(p, groupBy)
+		e, ok := events[key]
+		if !ok {
+			fields := mapstr.M{"metrics": mapstr.M{}}
+			if len(p.tags) > 0 {
+				tags := make(mapstr.M, len(p.tags))
+				for k, v := range p.tags {
+					tags[k] = v
+				}
+				fields["tags"] = tags
+			}
+			if database != "" {
+				fields["database"] = database
+			}
+			e = mb.Event{
+				Timestamp:       p.timestamp,
+				MetricSetFields: fields,
+			}
+			events[key] = e
+			keys = append(keys, key)
+		}
+
+		metrics := e.MetricSetFields["metrics"].(mapstr.M)
+		measurement, ok := metrics[p.measurement].(mapstr.M)
+		if !ok {
+			measurement = mapstr.M{}
+			metrics[p.measurement] = measurement
+		}
+		for k, v := range p.fields {
+			measurement[k] = v
+		}
+	}
+
+	result := make([]mb.Event, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, events[key])
+	}
+	return result
+}
+
+// seriesKey returns the key of the event where a point is stored.
+func seriesKey(p point, groupBy string) string {
+	tags := make([]string, 0, len(p.tags))
+	for k, v := range p.tags {
+		tags = append(tags, strconv.Quote(k)+"="+strconv.Quote(v))
+	}
+	sort.Strings(tags)
+
+	var b strings.Builder
+	if groupBy == groupByMeasurement {
+		b.WriteString(strconv.Quote(p.measurement))
+	}
+	b.WriteString("," + strings.Join(tags, ","))
+	if !p.timestamp.IsZero() {
+		b.WriteString(" " + strconv.FormatInt(p.timestamp.UnixNano(), 10))
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestToEvents(t *testing.T) {
+	data := "cpu,host=a usage=1 1\n" +
+		"mem,host=a used=2i 1\n" +
+		"cpu,host=b usage=3 1\n" +
+		"cpu,host=a idle=4 1\n" +
+		"cpu,host=a usage=5 2\n"
+	points, errs := parseLines([]byte(data), time.Second)
+	require.Empty(t, errs)
+
+	t.Run("measurement", func(t *testing.T) {
+		events := toEvents(points, groupByMeasurement, "telegraf")
+		require.Len(t, events, 4)
+
+		assert.Equal(t, time.Unix(1, 0), events[0].Timestamp)
+		assert.Equal(t, mapstr.M{
+			"metrics":  mapstr.M{"cpu": mapstr.M{"usage": 1.0, "idle": 4.0}},
+			"tags":     mapstr.M{"host": "a"},
+			"database": "telegraf",
+		}, events[0].MetricSetFields)
+		assert.Equal(t, mapstr.M{"mem": mapstr.M{"used": int64(2)}}, events[1].MetricSetFields["metrics"])
+		assert.Equal(t, mapstr.M{"host": "b"}, events[2].MetricSetFields["tags"])
+		assert.Equal(t, time.Unix(2, 0), events[3].Timestamp)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		events := toEvents(points, groupByTags, "")
+		require.Len(t, events, 3)
+
+		assert.Equal(t, mapstr.M{
+			"metrics": mapstr.M{
+				"cpu": mapstr.M{"usage": 1.0, "idle": 4.0},
+				"mem": mapstr.M{"used": int64(2)},
+			},
+			"tags": mapstr.M{"host": "a"},
+		}, events[0].MetricSetFields)
+	})
+}
+
+func TestToEventsWithoutTimestamp(t *testing.T) {
+	points, errs := parseLines([]byte("cpu usage=1\ncpu idle=2"), time.Nanosecond)
+	require.Empty(t, errs)
+
+	events := toEvents(points, groupByMeasurement, "")
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Timestamp.IsZero())
+	assert.Equal(t, mapstr.M{
+		"metrics": mapstr.M{"cpu": mapstr.M{"usage": 1.0, "idle": 2.0}},
+	}, events[0].MetricSetFields)
+}
@@ -0,0 +1,232 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// point is a data point of the InfluxDB line protocol.
+type point struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{}
+	// timestamp is zero if the line has no timestamp.
+	timestamp time.Time
+}
+
+// Characters escaped with a backslash in each element of a line.
+const (
+	measurementEscapes = ", "
+	keyEscapes         = ",= "
+)
+
+// parseLines parses the points in data, one per line. Empty lines and
+// comments are ignored. Lines that cannot be parsed don't prevent the other
+// ones from being parsed, their errors are returned with the valid points.
+func parseLines(data []byte, precision time.Duration) ([]point, []error) {
+	var points []point
+	var errs []error
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		p, err := parseLine(line, precision)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", n+1, err))
+			continue
+		}
+		points = append(points, p)
+	}
+	return points, errs
+}
+
+// parseLine parses a line of the form:
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+func parseLine(line string, precision time.Duration) (point, error) {
+	p := point{
+		tags:   map[string]string{},
+		fields: map[string]interface{}{},
+	}
+
+	var i int
+	p.measurement, i = readToken(line, 0, ", ", measurementEscapes)
+	if p.measurement == "" {
+		return p, errors.New("missing measurement")
+	}
+
+	for i < len(line) && line[i] == ',' {
+		var key, value string
+		key, i = readToken(line, i+1, "=", keyEscapes)
+		if i >= len(line) || key == "" {
+			return p, fmt.Errorf("invalid tag %q", key)
+		}
+		value, i = readToken(line, i+1, ", ", keyEscapes)
+		if value == "" {
+			return p, fmt.Errorf("missing value of tag %q", key)
+		}
+		p.tags[key] = value
+	}
+
+	i = skipSpaces(line, i)
+	for {
+		var key string
+		key, i = readToken(line, i, "=", keyEscapes)
+		if i >= len(line) || key == "" {
+			return p, errors.New("missing fields")
+		}
+		value, next, err := readFieldValue(line, i+1)
+		if err != nil {
+			return p, fmt.Errorf("invalid value of field %q: %w", key, err)
+		}
+		p.fields[key] = value
+		i = next
+		if i >= len(line) || line[i] != ',' {
+			break
+		}
+		i++
+	}
+
+	i = skipSpaces(line, i)
+	if i < len(line) {
+		ts, err := parseTimestamp(line[i:], precision)
+		if err != nil {
+			return p, err
+		}
+		p.timestamp = ts
+	}
+	return p, nil
+}
+
+// readToken reads from position i until one of the unescaped stop
+// characters. Escaped characters are unescaped, other backslashes are kept.
+func readToken(s string, i int, stops, escapes string) (string, int) {
+	var b strings.Builder
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && strings.IndexByte(escapes, s[i+1]) >= 0 {
+			b.WriteByte(s[i+1])
+			i++
+			continue
+		}
+		if strings.IndexByte(stops, c) >= 0 {
+			break
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), i
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// readFieldValue reads a field value starting at position i, and returns it
+// with the position after it.
+func readFieldValue(s string, i int) (interface{}, int, error) {
+	if i < len(s) && s[i] == '"' {
+		var b strings.Builder
+		for i++; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				b.WriteByte(s[i+1])
+				i++
+				continue
+			}
+			if c == '"' {
+				return b.String(), i + 1, nil
+			}
+			b.WriteByte(c)
+		}
+		return nil, i, errors.New("unterminated string")
+	}
+
+	end := i
+	for end < len(s) && s[end] != ',' && s[end] != ' ' {
+		end++
+	}
+	raw := s[i:end]
+	value, err := parseFieldValue(raw)
+	return value, end, err
+}
+
+func parseFieldValue(raw string) (interface{}, error) {
+	switch raw {
+	case "":
+		return nil, errors.New("empty value")
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+	switch raw[len(raw)-1] {
+	case 'i':
+		return strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+	case 'u':
+		return strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported value %q", raw)
+	}
+	return f, nil
+}
+
+func parseTimestamp(raw string, precision time.Duration) (time.Time, error) {
+	ts, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", raw)
+	}
+	if ts > math.MaxInt64/int64(precision) || ts < math.MinInt64/int64(precision) {
+		return time.Time{}, fmt.Errorf("timestamp %q out of range", raw)
+	}
+	return time.Unix(0, ts*int64(precision)), nil
+}
+
+// parsePrecision returns the duration of the timestamp unit used by InfluxDB
+// 1.x and 2.x APIs.
+func parsePrecision(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us", "µ", "µs":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	}
+	return 0, fmt.Errorf("unsupported precision %q", precision)
+}
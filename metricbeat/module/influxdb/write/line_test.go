@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	cases := map[string]struct {
+		line     string
+		expected point
+	}{
+		"simple": {
+			line: "cpu usage=0.5",
+			expected: point{
+				measurement: "cpu",
+				tags:        map[string]string{},
+				fields:      map[string]interface{}{"usage": 0.5},
+			},
+		},
+		"tags and timestamp": {
+			line: "cpu,host=a,region=eu usage=0.5,idle=0.25 1700000000000000000",
+			expected: point{
+				measurement: "cpu",
+				tags:        map[string]string{"host": "a", "region": "eu"},
+				fields:      map[string]interface{}{"usage": 0.5, "idle": 0.25},
+				timestamp:   time.Unix(1700000000, 0),
+			},
+		},
+		"types": {
+			line: `disk free=10i,inodes=3u,ok=t,failed=FALSE,path="/var/lib",ratio=1e3`,
+			expected: point{
+				measurement: "disk",
+				tags:        map[string]string{},
+				fields: map[string]interface{}{
+					"free":   int64(10),
+					"inodes": uint64(3),
+					"ok":     true,
+					"failed": false,
+					"path":   "/var/lib",
+					"ratio":  1000.0,
+				},
+			},
+		},
+		"escapes": {
+			line: `my\ cpu\,total,host\=name=a\ b\,c field\ one="say \"hi\", \\o/",other=1`,
+			expected: point{
+				measurement: "my cpu,total",
+				tags:        map[string]string{"host=name": `a b,c`},
+				fields:      map[string]interface{}{"field one": `say "hi", \o/`, "other": 1.0},
+			},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := parseLine(c.line, time.Nanosecond)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, p)
+		})
+	}
+}
+
+func TestParseLineErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing measurement": ",host=a value=1",
+		"missing fields":      "cpu",
+		"missing tag value":   "cpu,host= value=1",
+		"invalid tag":         "cpu,host value=1",
+		"empty value":         "cpu value=",
+		"invalid integer":     "cpu value=1.5i",
+		"unterminated string": `cpu value="abc`,
+		"nan":                 "cpu value=NaN",
+		"invalid timestamp":   "cpu value=1 yesterday",
+		"timestamp overflow":  "cpu value=1 9223372036854775807",
+	}
+	for name, line := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseLine(line, time.Second)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	data := "# comment\ncpu value=1 1700000000\n\nmem value=\nmem value=2i 1700000001\n"
+	points, errs := parseLines([]byte(data), time.Second)
+
+	require.Len(t, points, 2)
+	assert.Equal(t, "cpu", points[0].measurement)
+	assert.Equal(t, time.Unix(1700000000, 0), points[0].timestamp)
+	assert.Equal(t, "mem", points[1].measurement)
+	assert.Equal(t, time.Unix(1700000001, 0), points[1].timestamp)
+
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "line 4:")
+}
+
+func TestParsePrecision(t *testing.T) {
+	for precision, expected := range map[string]time.Duration{
+		"":   time.Nanosecond,
+		"ns": time.Nanosecond,
+		"u":  time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"h":  time.Hour,
+	} {
+		d, err := parsePrecision(precision)
+		require.NoError(t, err)
+		assert.Equal(t, expected, d, precision)
+	}
+
+	_, err := parsePrecision("d")
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	serverhelper "github.com/elastic/beats/v7/metricbeat/helper/server"
+	httpserver "github.com/elastic/beats/v7/metricbeat/helper/server/http"
+	"github.com/elastic/beats/v7/metricbeat/helper/server/udp"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+)
+
+// Paths of the write endpoints of the InfluxDB 1.x and 2.x APIs.
+const (
+	writePathV1 = "/write"
+	writePathV2 = "/api/v2/write"
+	pingPath    = "/ping"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	mb.Registry.MustAddMetricSet("influxdb", "write", New,
+		mb.DefaultMetricSet(),
+	)
+}
+
+// MetricSet receives points in InfluxDB line protocol over HTTP or UDP.
+type MetricSet struct {
+	mb.BaseMetricSet
+	server    serverhelper.Server
+	config    config
+	precision time.Duration
+
+	events chan []mb.Event
+	// done is closed when the metricset stops reporting events.
+	done chan struct{}
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The influxdb write metricset is beta."))
+
+	config := defaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+	precision, err := parsePrecision(config.Precision)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MetricSet{
+		BaseMetricSet: base,
+		config:        config,
+		precision:     precision,
+		events:        make(chan []mb.Event),
+		done:          make(chan struct{}),
+	}
+	if config.Protocol == "udp" {
+		m.server, err = udp.NewUdpServer(base)
+	} else {
+		m.server, err = httpserver.NewHttpServerWithHandler(base, m.handleFunc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Run receives points until the reporter is done.
+func (m *MetricSet) Run(reporter mb.PushReporterV2) {
+	if err := m.server.Start(); err != nil {
+		err = fmt.Errorf("failed to start influxdb write server: %w", err)
+		m.Logger().Errorf("%v", err)
+		reporter.Error(err)
+		return
+	}
+
+	for {
+		select {
+		case <-reporter.Done():
+			// Handlers waiting to send events return once done is closed.
+			close(m.done)
+			m.server.Stop()
+			return
+		case events := <-m.events:
+			for _, e := range events {
+				reporter.Event(e)
+			}
+		case msg := <-m.server.GetEvents():
+			// Datagrams received over UDP.
+			data, ok := msg.GetEvent()[serverhelper.EventDataKey].([]byte)
+			if !ok || len(data) == 0 {
+				continue
+			}
+			points, errs := parseLines(data, m.precision)
+			for _, err := range errs {
+				reporter.Error(err)
+			}
+			for _, e := range toEvents(points, m.config.GroupBy, "") {
+				reporter.Event(e)
+			}
+		}
+	}
+}
+
+func (m *MetricSet) handleFunc(w http.ResponseWriter, req *http.Request) {
+	var database string
+	query := req.URL.Query()
+	switch req.URL.Path {
+	case pingPath:
+		// Used by clients to check that the server is available.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case writePathV1:
+		database = query.Get("db")
+	case writePathV2:
+		database = query.Get("bucket")
+	default:
+		writeError(w, req, http.StatusNotFound, "not found")
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, req, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+
+	precision := m.precision
+	if p := query.Get("precision"); p != "" {
+		var err error
+		if precision, err = parsePrecision(p); err != nil {
+			writeError(w, req, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	body, err := m.readBody(w, req)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			writeError(w, req, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large: exceeds %d bytes limit", m.config.MaxBodyBytes))
+			return
+		}
+		writeError(w, req, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// As InfluxDB does, valid points are written even if some lines are
+	// invalid.
+	points, errs := parseLines(body, precision)
+	if events := toEvents(points, m.config.GroupBy, database); len(events) > 0 {
+		select {
+		case m.events <- events:
+		case <-req.Context().Done():
+			return
+		case <-m.done:
+			writeError(w, req, http.StatusServiceUnavailable, "server is stopping")
+			return
+		}
+	}
+	if len(errs) > 0 {
+		writeError(w, req, http.StatusBadRequest, "partial write: "+errors.Join(errs...).Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readBody reads the body of a request, decompressing it if needed. The size
+// of the body is limited before and after decompression.
+func (m *MetricSet) readBody(w http.ResponseWriter, req *http.Request) ([]byte, error) {
+	var body io.Reader = http.MaxBytesReader(w, req.Body, m.config.MaxBodyBytes)
+	switch encoding := req.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress request: %w", err)
+		}
+		defer gz.Close()
+		body = http.MaxBytesReader(w, gz, m.config.MaxBodyBytes)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	return data, nil
+}
+
+// writeError writes an error in the format of the API version of the request.
+func writeError(w http.ResponseWriter, req *http.Request, status int, message string) {
+	var body interface{} = map[string]string{"error": message}
+	if req.URL.Path == writePathV2 {
+		body = map[string]string{"code": "invalid", "message": message}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
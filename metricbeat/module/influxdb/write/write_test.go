@@ -0,0 +1,268 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package write
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+func freePort(t *testing.T, network string) int {
+	var addr net.Addr
+	if network == "udp" {
+		l, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr = l.LocalAddr()
+		require.NoError(t, l.Close())
+	} else {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr = l.Addr()
+		require.NoError(t, l.Close())
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return p
+}
+
+func gzipBody(t *testing.T, data string) []byte {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := gz.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return body.Bytes()
+}
+
+func TestRun(t *testing.T) {
+	v2Body := gzipBody(t, "cpu,host=a usage=0.5 1700000000000")
+	cases := map[string]struct {
+		protocol string
+		send     func(ctx context.Context, addr string) error
+		database string
+	}{
+		"http v1": {
+			protocol: "http",
+			send: func(ctx context.Context, addr string) error {
+				return post(ctx, "http://"+addr+"/write?db=telegraf&precision=s", "", []byte("cpu,host=a usage=0.5 1700000000"))
+			},
+			database: "telegraf",
+		},
+		"http v2 gzip": {
+			protocol: "http",
+			send: func(ctx context.Context, addr string) error {
+				return post(ctx, "http://"+addr+"/api/v2/write?org=o&bucket=telegraf&precision=ms", "gzip", v2Body)
+			},
+			database: "telegraf",
+		},
+		"udp": {
+			protocol: "udp",
+			send: func(ctx context.Context, addr string) error {
+				conn, err := (&net.Dialer{}).DialContext(ctx, "udp", addr)
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+				_, err = conn.Write([]byte("cpu,host=a usage=0.5 1700000000"))
+				return err
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			port := freePort(t, c.protocol)
+			config := map[string]interface{}{
+				"module":     "influxdb",
+				"metricsets": []string{"write"},
+				"host":       "127.0.0.1",
+				"port":       port,
+				"protocol":   c.protocol,
+			}
+			if c.protocol == "udp" {
+				config["precision"] = "s"
+			}
+			ms := mbtest.NewPushMetricSetV2(t, config)
+			addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+			// Points are sent until they are received, as the server may not
+			// be listening yet, and UDP datagrams may be lost.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for ctx.Err() == nil {
+					if c.send(ctx, addr) == nil && c.protocol != "udp" {
+						return
+					}
+					time.Sleep(50 * time.Millisecond)
+				}
+			}()
+
+			events := mbtest.RunPushMetricSetV2(10*time.Second, 1, ms)
+			require.NotEmpty(t, events)
+			assert.Equal(t, time.Unix(1700000000, 0), events[0].Timestamp)
+			expected := mapstr.M{
+				"metrics": mapstr.M{"cpu": mapstr.M{"usage": 0.5}},
+				"tags":    mapstr.M{"host": "a"},
+			}
+			if c.database != "" {
+				expected["database"] = c.database
+			}
+			assert.Equal(t, expected, events[0].MetricSetFields)
+		})
+	}
+}
+
+func post(ctx context.Context, url, encoding string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return &httpStatusError{resp.StatusCode}
+	}
+	return nil
+}
+
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func TestHandleFunc(t *testing.T) {
+	ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+		"module":         "influxdb",
+		"metricsets":     []string{"write"},
+		"max_body_bytes": 64,
+	}).(*MetricSet)
+
+	cases := map[string]struct {
+		method   string
+		path     string
+		encoding string
+		body     string
+		status   int
+	}{
+		"ping":                 {http.MethodGet, "/ping", "", "", http.StatusNoContent},
+		"unknown path":         {http.MethodPost, "/query", "", "", http.StatusNotFound},
+		"get":                  {http.MethodGet, "/write", "", "", http.StatusMethodNotAllowed},
+		"invalid precision":    {http.MethodPost, "/write?precision=d", "", "", http.StatusBadRequest},
+		"unsupported encoding": {http.MethodPost, "/write", "br", "", http.StatusBadRequest},
+		"invalid gzip":         {http.MethodPost, "/write", "gzip", "cpu value=1", http.StatusBadRequest},
+		"too large":            {http.MethodPost, "/write", "", strings.Repeat("x", 65), http.StatusRequestEntityTooLarge},
+		"too large gzip":       {http.MethodPost, "/write", "gzip", string(gzipBody(t, strings.Repeat("x", 65))), http.StatusRequestEntityTooLarge},
+		"invalid line":         {http.MethodPost, "/api/v2/write", "", "cpu", http.StatusBadRequest},
+		// Bodies without points are accepted without waiting for the
+		// metricset to report events.
+		"empty": {http.MethodPost, "/write", "", "# no points\n", http.StatusNoContent},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.encoding != "" {
+				req.Header.Set("Content-Encoding", c.encoding)
+			}
+			w := httptest.NewRecorder()
+			ms.handleFunc(w, req)
+			assert.Equal(t, c.status, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestHandleFuncPartialWrite(t *testing.T) {
+	ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+		"module":     "influxdb",
+		"metricsets": []string{"write"},
+	}).(*MetricSet)
+
+	received := make(chan []mb.Event, 1)
+	go func() {
+		received <- <-ms.events
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/write?bucket=b", strings.NewReader("cpu value=1\ncpu value=\n"))
+	w := httptest.NewRecorder()
+	ms.handleFunc(w, req)
+
+	// Valid points are reported even if the request has invalid lines.
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"invalid"`)
+	assert.Contains(t, w.Body.String(), "line 2:")
+	events := <-received
+	require.Len(t, events, 1)
+	assert.Equal(t, mapstr.M{"cpu": mapstr.M{"value": 1.0}}, events[0].MetricSetFields["metrics"])
+}
+
+func TestConfigValidate(t *testing.T) {
+	cases := map[string]struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		"protocol":  {map[string]interface{}{"protocol": "tcp"}, "`protocol` can only be http or udp"},
+		"group_by":  {map[string]interface{}{"group_by": "host"}, "`group_by` can only be measurement or tags"},
+		"precision": {map[string]interface{}{"precision": "d"}, "unsupported precision"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"module":     "influxdb",
+				"metricsets": []string{"write"},
+			}
+			for k, v := range c.config {
+				config[k] = v
+			}
+			cfg, err := conf.NewConfigFrom(config)
+			require.NoError(t, err)
+
+			_, _, err = mb.NewModule(cfg, mb.Registry, beat.Info{Paths: paths.New(), Logger: logptest.NewTestingLogger(t, "")})
+			assert.ErrorContains(t, err, c.expected)
+		})
+	}
+}
@@ -286,6 +286,82 @@ func (b *Broker) FetchPartitionOffsetFromTheLeader(topic string, partitionID int
 	return offset, nil
 }
 
+// ListClusterGroups lists the consumer groups managed by all the brokers of
+// the cluster.
+func (b *Broker) ListClusterGroups() ([]string, error) {
+	// The admin shares the cluster-wide client, it is not closed here because
+	// this would also close the client, that is closed with the broker.
+	admin, err := sarama.NewClusterAdminFromClient(b.client)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := admin.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0, len(resp))
+	for name := range resp {
+		groups = append(groups, name)
+	}
+	return groups, nil
+}
+
+// FetchClusterGroupOffsets fetches all the offsets committed by a group from
+// its coordinator, that can be any broker of the cluster.
+func (b *Broker) FetchClusterGroupOffsets(group string) (*sarama.OffsetFetchResponse, error) {
+	admin, err := sarama.NewClusterAdminFromClient(b.client)
+	if err != nil {
+		return nil, err
+	}
+	return admin.ListConsumerGroupOffsets(group, nil)
+}
+
+// FetchLogEndOffsets fetches the log-end offsets of partitions, sending a
+// single request to the leader of each group of partitions. The partitions
+// is a MAP mapping from topic name to partitionid array. Partitions whose
+// offset cannot be fetched are not included in the result.
+func (b *Broker) FetchLogEndOffsets(partitions map[string][]int32) (map[string]map[int32]int64, error) {
+	requests := map[*sarama.Broker]*sarama.OffsetRequest{}
+	for topic, partition := range partitions {
+		for _, partitionID := range partition {
+			leader, err := b.client.Leader(topic, partitionID)
+			if err != nil {
+				b.logger.Debugf("failed to get leader of partition %d of topic %s: %v", partitionID, topic, err)
+				continue
+			}
+			requ, found := requests[leader]
+			if !found {
+				requ = &sarama.OffsetRequest{Version: 1}
+				requests[leader] = requ
+			}
+			requ.AddBlock(topic, partitionID, sarama.OffsetNewest, 1)
+		}
+	}
+
+	offsets := map[string]map[int32]int64{}
+	for leader, requ := range requests {
+		resp, err := leader.GetAvailableOffsets(requ)
+		if err != nil {
+			return nil, fmt.Errorf("fetching log-end offsets from broker %d: %w", leader.ID(), err)
+		}
+		for topic, blocks := range resp.Blocks {
+			for partitionID, block := range blocks {
+				if !errors.Is(block.Err, sarama.ErrNoError) {
+					b.logger.Debugf("failed to fetch log-end offset of partition %d of topic %s: %v", partitionID, topic, block.Err)
+					continue
+				}
+				if offsets[topic] == nil {
+					offsets[topic] = map[int32]int64{}
+				}
+				offsets[topic][partitionID] = block.Offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
 // ID returns the broker ID or -1 if the broker id is unknown.
 func (b *Broker) ID() int32 {
 	if b.id == noID {
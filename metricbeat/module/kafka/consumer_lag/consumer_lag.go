@@ -0,0 +1,235 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer_lag
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/kafka"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// init registers the MetricSet with the central registry.
+func init() {
+	mb.Registry.MustAddMetricSet("kafka", "consumer_lag", New)
+}
+
+// MetricSet reports the lag of the consumer groups of a whole Kafka cluster.
+type MetricSet struct {
+	*kafka.MetricSet
+
+	groups  func(string) bool
+	topics  func(string) bool
+	history *offsetHistory
+}
+
+type config struct {
+	Groups []string `config:"groups"`
+	Topics []string `config:"topics"`
+
+	// OffsetHistorySize is the number of log-end offsets kept for each
+	// partition to estimate the time lag.
+	OffsetHistorySize int `config:"offset_history_size" validate:"min=2"`
+}
+
+func defaultConfig() config {
+	return config{
+		OffsetHistorySize: 60,
+	}
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The kafka consumer_lag metricset is beta."))
+
+	opts := kafka.MetricSetOptions{
+		// NOTE: Listing the groups and their offsets with the admin API
+		// requires at least the version 2.0.0 of the protocol, the same
+		// version as the consumergroup metricset is used.
+		Version: "2.1.0",
+	}
+
+	ms, err := kafka.NewMetricSet(base, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config := defaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		MetricSet: ms,
+		groups:    nameFilter(config.Groups),
+		topics:    nameFilter(config.Topics),
+		history:   newOffsetHistory(config.OffsetHistorySize),
+	}, nil
+}
+
+// Fetch fetches the lag of the consumer groups in each partition and
+// reports it per partition and per group and topic.
+func (m *MetricSet) Fetch(r mb.ReporterV2) error {
+	broker, err := m.Connect()
+	if err != nil {
+		return fmt.Errorf("error in connect: %w", err)
+	}
+	defer broker.Close()
+
+	lags, logEndOffsets, err := fetchPartitionLags(broker, m.groups, m.topics, m.Logger())
+	if err != nil {
+		return fmt.Errorf("error in fetch: %w", err)
+	}
+
+	m.recordLogEndOffsets(time.Now(), logEndOffsets)
+
+	for _, event := range m.lagEvents(lags) {
+		if !r.Event(event) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// recordLogEndOffsets adds the log-end offsets to the history, and forgets
+// the partitions that are not consumed anymore.
+func (m *MetricSet) recordLogEndOffsets(now time.Time, logEndOffsets map[string]map[int32]int64) {
+	seen := map[topicPartition]struct{}{}
+	for topic, offsets := range logEndOffsets {
+		for partition, offset := range offsets {
+			tp := topicPartition{topic: topic, partition: partition}
+			m.history.add(tp, now, offset)
+			seen[tp] = struct{}{}
+		}
+	}
+	m.history.retain(seen)
+}
+
+type groupTopic struct {
+	group string
+	topic string
+}
+
+type groupTopicLag struct {
+	partitions int
+	lag        int64
+	maxLag     int64
+	maxTimeLag time.Duration
+	hasTimeLag bool
+}
+
+// lagEvents builds an event for each partition lag, followed by an event
+// per group and topic aggregating the lag of their partitions. The lags are
+// expected to be sorted by group and topic.
+func (m *MetricSet) lagEvents(lags []partitionLag) []mb.Event {
+	var events []mb.Event
+	var current groupTopic
+	var total groupTopicLag
+
+	flush := func() {
+		if total.partitions == 0 {
+			return
+		}
+		events = append(events, groupTopicEvent(current, total))
+	}
+
+	for _, l := range lags {
+		key := groupTopic{group: l.group, topic: l.topic}
+		if key != current {
+			flush()
+			current, total = key, groupTopicLag{}
+		}
+
+		fields := mapstr.M{
+			"group":          l.group,
+			"offset":         l.offset,
+			"log_end_offset": l.logEndOffset,
+			"lag":            l.lag,
+		}
+
+		total.partitions++
+		total.lag += l.lag
+		total.maxLag = max(total.maxLag, l.lag)
+
+		timeLag, ok := m.history.timeLag(topicPartition{topic: l.topic, partition: l.partition}, l.offset)
+		if ok {
+			fields.Put("time_lag.ms", timeLag.Milliseconds())
+			total.maxTimeLag = max(total.maxTimeLag, timeLag)
+			total.hasTimeLag = true
+		}
+
+		events = append(events, mb.Event{
+			ModuleFields: mapstr.M{
+				"topic": mapstr.M{
+					"name": l.topic,
+				},
+				"partition": mapstr.M{
+					"id": l.partition,
+					// Helpful IDs to avoid scripts on queries
+					"topic_id": fmt.Sprintf("%d-%s", l.partition, l.topic),
+				},
+			},
+			MetricSetFields: fields,
+		})
+	}
+	flush()
+
+	return events
+}
+
+func groupTopicEvent(key groupTopic, total groupTopicLag) mb.Event {
+	totalFields := mapstr.M{
+		"partitions": total.partitions,
+		"lag":        total.lag,
+		"max_lag":    total.maxLag,
+	}
+	if total.hasTimeLag {
+		totalFields.Put("max_time_lag.ms", total.maxTimeLag.Milliseconds())
+	}
+
+	return mb.Event{
+		ModuleFields: mapstr.M{
+			"topic": mapstr.M{
+				"name": key.topic,
+			},
+		},
+		MetricSetFields: mapstr.M{
+			"group": key.group,
+			"total": totalFields,
+		},
+	}
+}
+
+// nameFilter returns a predicate that matches the given names, or nil to
+// match all the names if none is given.
+func nameFilter(names []string) func(string) bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return func(name string) bool {
+		_, found := set[name]
+		return found
+	}
+}
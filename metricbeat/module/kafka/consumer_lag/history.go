@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer_lag
+
+import (
+	"time"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type offsetSample struct {
+	timestamp time.Time
+	offset    int64
+}
+
+// offsetHistory keeps the latest log-end offsets of each partition, they are
+// used to estimate when the messages not consumed yet were produced.
+type offsetHistory struct {
+	size       int
+	partitions map[topicPartition][]offsetSample
+}
+
+func newOffsetHistory(size int) *offsetHistory {
+	return &offsetHistory{
+		size:       size,
+		partitions: map[topicPartition][]offsetSample{},
+	}
+}
+
+// add records the log-end offset of a partition at a given time. The
+// history of a partition is restarted if its offset goes back, what happens
+// when a topic is recreated.
+func (h *offsetHistory) add(tp topicPartition, timestamp time.Time, offset int64) {
+	samples := h.partitions[tp]
+	if n := len(samples); n > 0 && offset < samples[n-1].offset {
+		samples = samples[:0]
+	}
+	if len(samples) >= h.size {
+		samples = append(samples[:0], samples[len(samples)-h.size+1:]...)
+	}
+	h.partitions[tp] = append(samples, offsetSample{timestamp: timestamp, offset: offset})
+}
+
+// retain removes the history of the partitions that are not in seen.
+func (h *offsetHistory) retain(seen map[topicPartition]struct{}) {
+	for tp := range h.partitions {
+		if _, found := seen[tp]; !found {
+			delete(h.partitions, tp)
+		}
+	}
+}
+
+// timeLag estimates how long ago the message at a committed offset was
+// produced, relative to the latest sample of the partition. The time is
+// interpolated between the samples around the offset, or extrapolated with
+// the average produce rate of the history if the offset is older than all of
+// them. It returns false if there are not enough samples to estimate it.
+func (h *offsetHistory) timeLag(tp topicPartition, offset int64) (time.Duration, bool) {
+	samples := h.partitions[tp]
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	latest := samples[len(samples)-1]
+	if offset >= latest.offset {
+		return 0, true
+	}
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	for i := len(samples) - 1; i > 0; i-- {
+		prev, next := samples[i-1], samples[i]
+		if offset < prev.offset {
+			continue
+		}
+		ratio := float64(offset-prev.offset) / float64(next.offset-prev.offset)
+		produced := prev.timestamp.Add(time.Duration(ratio * float64(next.timestamp.Sub(prev.timestamp))))
+		return latest.timestamp.Sub(produced), true
+	}
+
+	oldest := samples[0]
+	elapsed := latest.timestamp.Sub(oldest.timestamp)
+	if latest.offset == oldest.offset || elapsed <= 0 {
+		return 0, false
+	}
+	rate := float64(latest.offset-oldest.offset) / float64(elapsed)
+	produced := oldest.timestamp.Add(-time.Duration(float64(oldest.offset-offset) / rate))
+	return latest.timestamp.Sub(produced), true
+}
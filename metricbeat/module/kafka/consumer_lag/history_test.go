@@ -0,0 +1,180 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer_lag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestOffsetHistorySize(t *testing.T) {
+	h := newOffsetHistory(3)
+	tp := topicPartition{topic: "topic", partition: 0}
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		h.add(tp, start.Add(time.Duration(i)*time.Second), int64(i*10))
+	}
+	samples := h.partitions[tp]
+	if assert.Len(t, samples, 3) {
+		assert.Equal(t, int64(20), samples[0].offset)
+		assert.Equal(t, int64(40), samples[2].offset)
+	}
+
+	// The history is restarted when offsets go back.
+	h.add(tp, start.Add(5*time.Second), 5)
+	assert.Equal(t, []offsetSample{{timestamp: start.Add(5 * time.Second), offset: 5}}, h.partitions[tp])
+
+	h.retain(map[topicPartition]struct{}{})
+	assert.Empty(t, h.partitions)
+}
+
+func TestOffsetHistoryTimeLag(t *testing.T) {
+	h := newOffsetHistory(10)
+	tp := topicPartition{topic: "topic", partition: 0}
+	start := time.Now()
+
+	_, ok := h.timeLag(tp, 0)
+	assert.False(t, ok, "no time lag without history")
+
+	h.add(tp, start, 100)
+	lag, ok := h.timeLag(tp, 100)
+	assert.True(t, ok)
+	assert.Zero(t, lag, "no time lag if all messages were consumed")
+
+	_, ok = h.timeLag(tp, 50)
+	assert.False(t, ok, "a single sample is not enough to estimate the time lag")
+
+	// 10 messages per second during 10 seconds, then none during 10 seconds.
+	h.add(tp, start.Add(10*time.Second), 200)
+	h.add(tp, start.Add(20*time.Second), 200)
+
+	cases := map[int64]time.Duration{
+		200: 0,
+		// Produced 5 seconds after the start.
+		150: 15 * time.Second,
+		100: 20 * time.Second,
+		// Older than the history, extrapolated with a rate of 5 messages
+		// per second.
+		50: 30 * time.Second,
+	}
+	for offset, expected := range cases {
+		lag, ok := h.timeLag(tp, offset)
+		assert.True(t, ok)
+		assert.Equal(t, expected, lag, "offset %d", offset)
+	}
+}
+
+func TestOffsetHistoryTimeLagNoProduction(t *testing.T) {
+	h := newOffsetHistory(10)
+	tp := topicPartition{topic: "topic", partition: 0}
+	start := time.Now()
+
+	h.add(tp, start, 100)
+	h.add(tp, start.Add(10*time.Second), 100)
+
+	_, ok := h.timeLag(tp, 50)
+	assert.False(t, ok, "time lag can't be estimated without produced messages")
+}
+
+func TestLagEvents(t *testing.T) {
+	h := newOffsetHistory(10)
+	start := time.Now()
+	h.add(topicPartition{topic: "topic1", partition: 0}, start, 0)
+	h.add(topicPartition{topic: "topic1", partition: 0}, start.Add(10*time.Second), 100)
+
+	m := &MetricSet{history: h}
+	events := m.lagEvents([]partitionLag{
+		{group: "group1", topic: "topic1", partition: 0, offset: 50, logEndOffset: 100, lag: 50},
+		{group: "group1", topic: "topic1", partition: 1, offset: 10, logEndOffset: 30, lag: 20},
+		{group: "group2", topic: "topic1", partition: 1, offset: 30, logEndOffset: 30, lag: 0},
+	})
+
+	expected := []mb.Event{
+		{
+			ModuleFields: mapstr.M{
+				"topic":     mapstr.M{"name": "topic1"},
+				"partition": mapstr.M{"id": int32(0), "topic_id": "0-topic1"},
+			},
+			MetricSetFields: mapstr.M{
+				"group":          "group1",
+				"offset":         int64(50),
+				"log_end_offset": int64(100),
+				"lag":            int64(50),
+				"time_lag":       mapstr.M{"ms": int64(5000)},
+			},
+		},
+		{
+			ModuleFields: mapstr.M{
+				"topic":     mapstr.M{"name": "topic1"},
+				"partition": mapstr.M{"id": int32(1), "topic_id": "1-topic1"},
+			},
+			MetricSetFields: mapstr.M{
+				"group":          "group1",
+				"offset":         int64(10),
+				"log_end_offset": int64(30),
+				"lag":            int64(20),
+			},
+		},
+		{
+			ModuleFields: mapstr.M{
+				"topic": mapstr.M{"name": "topic1"},
+			},
+			MetricSetFields: mapstr.M{
+				"group": "group1",
+				"total": mapstr.M{
+					"partitions":   2,
+					"lag":          int64(70),
+					"max_lag":      int64(50),
+					"max_time_lag": mapstr.M{"ms": int64(5000)},
+				},
+			},
+		},
+		{
+			ModuleFields: mapstr.M{
+				"topic":     mapstr.M{"name": "topic1"},
+				"partition": mapstr.M{"id": int32(1), "topic_id": "1-topic1"},
+			},
+			MetricSetFields: mapstr.M{
+				"group":          "group2",
+				"offset":         int64(30),
+				"log_end_offset": int64(30),
+				"lag":            int64(0),
+			},
+		},
+		{
+			ModuleFields: mapstr.M{
+				"topic": mapstr.M{"name": "topic1"},
+			},
+			MetricSetFields: mapstr.M{
+				"group": "group2",
+				"total": mapstr.M{
+					"partitions": 1,
+					"lag":        int64(0),
+					"max_lag":    int64(0),
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, events)
+}
@@ -0,0 +1,147 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer_lag
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/sarama"
+)
+
+type client interface {
+	ListClusterGroups() ([]string, error)
+	FetchClusterGroupOffsets(group string) (*sarama.OffsetFetchResponse, error)
+	FetchLogEndOffsets(partitions map[string][]int32) (map[string]map[int32]int64, error)
+}
+
+// partitionLag is the lag of a consumer group in a partition.
+type partitionLag struct {
+	group        string
+	topic        string
+	partition    int32
+	offset       int64
+	logEndOffset int64
+	lag          int64
+}
+
+// fetchPartitionLags fetches the offsets committed by the consumer groups of
+// the cluster and the log-end offsets of their partitions, and returns the
+// lag of each group in each partition, sorted by group, topic and partition.
+// The log-end offsets are also returned to record them in the history.
+func fetchPartitionLags(
+	b client,
+	groupsFilter,
+	topicsFilter func(string) bool,
+	logger *logp.Logger,
+) ([]partitionLag, map[string]map[int32]int64, error) {
+	groups, err := b.ListClusterGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(groups)
+
+	committed := map[string]map[string]map[int32]int64{}
+	partitions := map[string]map[int32]struct{}{}
+	for _, group := range groups {
+		if groupsFilter != nil && !groupsFilter(group) {
+			continue
+		}
+
+		resp, err := b.FetchClusterGroupOffsets(group)
+		if err != nil {
+			// Groups can be removed while they are queried, this shouldn't
+			// prevent to report the lag of the other groups.
+			logger.Errorf("failed to fetch offsets of group %s: %v", group, err)
+			continue
+		}
+
+		for topic, blocks := range resp.Blocks {
+			if topicsFilter != nil && !topicsFilter(topic) {
+				continue
+			}
+			for partition, block := range blocks {
+				// Offsets are -1 for partitions without committed offsets.
+				if !errors.Is(block.Err, sarama.ErrNoError) || block.Offset < 0 {
+					continue
+				}
+				if committed[group] == nil {
+					committed[group] = map[string]map[int32]int64{}
+				}
+				if committed[group][topic] == nil {
+					committed[group][topic] = map[int32]int64{}
+				}
+				committed[group][topic][partition] = block.Offset
+
+				if partitions[topic] == nil {
+					partitions[topic] = map[int32]struct{}{}
+				}
+				partitions[topic][partition] = struct{}{}
+			}
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, nil, nil
+	}
+
+	query := make(map[string][]int32, len(partitions))
+	for topic, ids := range partitions {
+		for id := range ids {
+			query[topic] = append(query[topic], id)
+		}
+	}
+	logEndOffsets, err := b.FetchLogEndOffsets(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lags []partitionLag
+	for group, topics := range committed {
+		for topic, offsets := range topics {
+			for partition, offset := range offsets {
+				logEndOffset, found := logEndOffsets[topic][partition]
+				if !found {
+					continue
+				}
+				lags = append(lags, partitionLag{
+					group:        group,
+					topic:        topic,
+					partition:    partition,
+					offset:       offset,
+					logEndOffset: logEndOffset,
+					// Offsets committed after the log-end offset was
+					// fetched would give a negative lag.
+					lag: max(logEndOffset-offset, 0),
+				})
+			}
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		a, b := lags[i], lags[j]
+		if a.group != b.group {
+			return a.group < b.group
+		}
+		if a.topic != b.topic {
+			return a.topic < b.topic
+		}
+		return a.partition < b.partition
+	})
+	return lags, logEndOffsets, nil
+}
@@ -0,0 +1,160 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer_lag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/sarama"
+)
+
+type mockClient struct {
+	// group -> topic -> partition -> committed offset
+	committed map[string]map[string]map[int32]int64
+	// topic -> partition -> log-end offset
+	logEndOffsets map[string]map[int32]int64
+
+	groupErrors map[string]error
+	queried     map[string][]int32
+}
+
+func (c *mockClient) ListClusterGroups() ([]string, error) {
+	var groups []string
+	for group := range c.committed {
+		groups = append(groups, group)
+	}
+	for group := range c.groupErrors {
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (c *mockClient) FetchClusterGroupOffsets(group string) (*sarama.OffsetFetchResponse, error) {
+	if err := c.groupErrors[group]; err != nil {
+		return nil, err
+	}
+	resp := &sarama.OffsetFetchResponse{}
+	for topic, offsets := range c.committed[group] {
+		for partition, offset := range offsets {
+			resp.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset})
+		}
+	}
+	return resp, nil
+}
+
+func (c *mockClient) FetchLogEndOffsets(partitions map[string][]int32) (map[string]map[int32]int64, error) {
+	c.queried = partitions
+	offsets := map[string]map[int32]int64{}
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			offset, found := c.logEndOffsets[topic][id]
+			if !found {
+				continue
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = map[int32]int64{}
+			}
+			offsets[topic][id] = offset
+		}
+	}
+	return offsets, nil
+}
+
+func TestFetchPartitionLags(t *testing.T) {
+	client := &mockClient{
+		committed: map[string]map[string]map[int32]int64{
+			"group2": {
+				"topic1": {0: 100},
+			},
+			"group1": {
+				"topic2": {0: 5},
+				"topic1": {1: 20, 0: 10},
+			},
+			// Partitions without committed offsets are ignored.
+			"group3": {
+				"topic1": {0: -1},
+			},
+		},
+		logEndOffsets: map[string]map[int32]int64{
+			"topic1": {0: 100, 1: 15},
+			"topic2": {0: 42},
+		},
+		groupErrors: map[string]error{
+			"deleted": errors.New("group coordinator not available"),
+		},
+	}
+
+	lags, logEndOffsets, err := fetchPartitionLags(client, nil, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+
+	expected := []partitionLag{
+		{group: "group1", topic: "topic1", partition: 0, offset: 10, logEndOffset: 100, lag: 90},
+		// Committed offsets after the fetched log-end offset have no lag.
+		{group: "group1", topic: "topic1", partition: 1, offset: 20, logEndOffset: 15, lag: 0},
+		{group: "group1", topic: "topic2", partition: 0, offset: 5, logEndOffset: 42, lag: 37},
+		{group: "group2", topic: "topic1", partition: 0, offset: 100, logEndOffset: 100, lag: 0},
+	}
+	assert.Equal(t, expected, lags)
+	assert.Equal(t, client.logEndOffsets, logEndOffsets)
+}
+
+func TestFetchPartitionLagsFilter(t *testing.T) {
+	client := &mockClient{
+		committed: map[string]map[string]map[int32]int64{
+			"group1": {
+				"topic1": {0: 1},
+				"topic2": {0: 2},
+			},
+			"group2": {
+				"topic1": {0: 3},
+			},
+		},
+		logEndOffsets: map[string]map[int32]int64{
+			"topic1": {0: 10},
+			"topic2": {0: 10},
+		},
+	}
+
+	lags, _, err := fetchPartitionLags(client,
+		nameFilter([]string{"group1"}),
+		nameFilter([]string{"topic1"}),
+		logptest.NewTestingLogger(t, ""),
+	)
+	require.NoError(t, err)
+
+	expected := []partitionLag{
+		{group: "group1", topic: "topic1", partition: 0, offset: 1, logEndOffset: 10, lag: 9},
+	}
+	assert.Equal(t, expected, lags)
+	assert.Equal(t, map[string][]int32{"topic1": {0}}, client.queried)
+}
+
+func TestFetchPartitionLagsNoGroups(t *testing.T) {
+	client := &mockClient{}
+
+	lags, logEndOffsets, err := fetchPartitionLags(client, nil, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	assert.Empty(t, lags)
+	assert.Empty(t, logEndOffsets)
+	assert.Nil(t, client.queried, "log-end offsets shouldn't be queried without committed offsets")
+}
@@ -32,5 +32,5 @@ func init() {
 // AssetKafka returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/kafka.
 func AssetKafka() string {
-	return "eJzUWk1z2zYTvvtX7OTkHMLcfXhn3iadjpumyaTpTKcXDgQsRdQgwACgbPnXdwCQFL9EEqLcNjrZFHafB/uFxVJv4AGPd/BAsgdyA2C5FXgHrz64/1/dADA0VPPSciXv4H83AAD+OygUqwTeAJhcaZtSJTO+v4OMCOOeahRIDN7B3qnNOApm7rz4G5CkwBOk+9hj6ZZqVZX1kwncvpquqp1WD6jbx1P6zuoMnx+8BninpKkK1PCTE4V7mSldECcAOTkg7BAlaCQMMq0KuK3FciKZ4HLfU2lzBNro81ReJ50Fw71098NZ73GzH6EGELNb6myLs5tJHMKYRmMmwR7w+Kj0kMg6PMIOqC03yFqIkc+sKjlN3N8jv42hZ2C/Oj1e5zkM1FrphCo2RhpYdBHGqwKnKhmjlURb7mSTnv9ikT43aoCzWRS/u3QCa95+PbDfJf9WIXAGKvMRW57QpX8QbLjMI+TgP0MHiGT+vwCaXKUg1LFboNWcmpDgodTV3/z88Y+ObFvgdmjJyrwudkjkXEZ9dAvA5sSCzbkBPKC0wI1DIxYZWLU6WRtQjd8qNDahOZESRfKtwgoTw59xjsnXHMGtaRxRawEvva46DQmUWrGKYpIRLpClJerUIFVytsY4HppYzyMIQq2n0WugRA2TmgKxTChiZ5llaGl+OS8quHOT19IaymmrNF6BXd9uS6RkVexQz5jrCjZaz2HWNNFMSsGpP40TgYShTlEgdf8PVY0YhfXQrPeu2wBfSSqQyDSWRi13DToGjXFUnpV6QCxRJ4wbqqREapdo/KnUBy8DVCh3StfKNgTrmA4+lVwv1pgTlbD+Zbi4lk1JcVzPppF4ETrmKGmMj3wO1b7dxkWofZKJyuTpRMiNs0btwa++JEDrBg9twmWyO1o0TWldguWSqoLLPTipUGXdhr3Ci0moysaxUJXdq2uz0PgXUossjkojdTUqBRpD9mhSPtuO9JxRy2yDv044XAB6BfdfgHotd0dCb3XvCrgGqrnhxvXa7T17ottuv/tO+23f66wqrwWXvKiKkFHEwmPOad6fGxiUzPTbJwNWARlfcdYERgjDWvtiG0cOqF1InNo5L9+wY5ApDQRMiZRnnNZ3sw39LlWabaFXazgRPHGZ5BpJMLZwNfeDxmo+z9w9VvWcHJvc5CkVZHYQ5IOLPPngakNpLLOE1DYsKVVFwUdXh7MbVllm0HUsXsrtt+1mIin4IeF2+A+dWeNqO68vou1F8FTWQjEND/zKC2rqsIRGTzXPKeoPZ5vPBRPJFUPC4Uj1fbt4Eij4bt2AYYDUWr/2P5dWdQZIO3Tp5/r6eQZF/3yJ2iytjFVFNw4sAUYsAWN1d0A8idyITaR3pAUE2fuC1+7+bajNlAhahZONGF+EGM8y1CgpuoP1EVEO5m61MYlkQwPPb2Zy6rp+K+PZsi8FLYe3J4bdoey8ff1FapLOMENW8Pm/MXwvkTX3MxdZLsJ8D1F3NC3JgfRUqsFcusFCFI74vguk7t/DbTCcQWsdvcA24ez1tLG6NHJlhua6lEhP1VnAAt1Rnm7fPpcWtSRi8AamBuhWodE8e0vBnVISX2xnauAlcXogXJCdwFqvaUa6e35A2ZnjR8aoxEecCY+JBF9B1n1+9YqbwjN8ETCk2TGbYC9D6JNXvExo0pnnSsDF/jy9H3IHS6zXwuTxBYz0Sxhpcga34Wry+ryn6lHqC7D4EjRP0zhfbqU5Spou0dopJcYXypXM7iXjlLiLAM8aA7ibJpdUVAxZ84KLyzeOTDtuRle54fb+ty+rdmLSBQ+/yCZsO2Jfpni2MYAr+P/HthcIB7C/ObtjbyJd2wwNL0giRxifa6mpEUb73Xc6wiDNkZHuKtcepv4Gu3QLssoSAaRQlfRVMsi6I1fp4eR9cVBBLM1Tw58xJYfFu+65QYU51+6tAi7I09pL9mrgmZekVGmWGpRs1ezo/PDDYW8dwqQarT5eTMRqjqxWVY+wthLyVeM/RCgMgeqR3L/srNg0aeww/glADGBEeiwBLv10YJXfT8ZtCvpVfiFgSiUNXs4gyG+gwFX6SPhijLWQ928/gRMAy4tY/0a/JemPOMMLE1VZP3ywHVaRPOr52Sqrl6eDeNUbjL8DAAD//1EPWgk="
+	return "eJzUmkuPI7cRx+/6FAWfdoGd3vscAiS2kUzW9hqOAwS5NKhmdYsZNqkl2aPRfvqAbJL9fkpjeDFzUatZ/x+LxVeVHuAZr4/wTPJncgAwzHB8hO8+2c/fHQAo6kyxs2FSPMJfDgAA7jsoJa04HgD0SSqTZlLkrHiEnHBtnyrkSDQ+QmHN5gw51Y+u+QMIUmIjaf/M9WxfVbI6+ycjul0zbVNHJZ9Rxcdj9iZt1v9/cxbgeyl0VaKCv1sUeBK5VCWxnYcTeUE4IgpQSCjkSpbwzjc7EUE5E0XHpDkhZMGeQ3mftF7o96XdH0Y7j0N/uOxJzHap1S1GD6M6hFKFWvea1WLPeL1IRXfpEfqCyjCNNEoc+tpGnlmW2P4elqVnZH+3dpzNKQ1USqokkxQPCx5dlHGmwJpKhmpnogyzsZIweoPSr8EMMDqr4nqXMrrRf63HAP8W7EuFwCjI3EVsNA9MuAdOZQVHPQf/GBwggrpPtWgygNuzIPjYLdEolul6gtdLnf/mnz//p9U2LnBHNGTlvC6PSETnmx7Dz/YFMCdiwJyYBnxBYYBpUMiJQQpG9ppPubgRVfilQm2S7ESEQJ58qbDCRLOvOEfy+wnBvhMGwlsB17rXcDTChwBnJWmVYZITxpGmZ1SpxkwKusShiHEcdUPwdoJdDWdUMGqpBsu5JGaWLEeTnfZzZZzZYXJWgk2w1iqFd6Dr+m0JSlTlEdWMu3ZStH20nmHWNZtJzpxlbjdOOBKKKkWOmf2sl4jq9yG874buBvlKZByJSLdi+Hb3wNGotfXEVymfEc+oEsp0JoXAzCxh/FfKT64NZFzaXdobuyFYhzj4emYK16PU778Niz2yScGv62lCizfB0VeRrUfxc8iP7W0sXBZJzit9SkdCbsDAZQHu7T0B6g94aBImkuPVoA5L65IsE5ksmSjAtnLSrsPO4G4IWZltFLIyhbw3hcL/YWaQbkMJre6GUqLWpECdMrF6MHyb2+TvEw47RO8w/DtU7zXcG6VvHd4VckEq3HC3nbXjPXvktB2/+0bP2+6gtGp5LZlgZVW64AJi4HJi2ambN9AoqO4enzQYCWR4xZkaqTabjWWdeut0iY+8oCJF+zjn2gc6CrlUQECfMWM5y/zdbPfepDCTit6C5y00gA3LKOtGwK0LV7gfBK+5RczeY2VnkDdSlOQ15aRYEi/JqwuuoALDNktK8cCSZrIsmdFLmqHDMs81GvCtbH/jaWYjgksS3i7/qZVrXCu9YRENwtHXYTGtH7g3V6gH5WCmN8wr1tVuJuknUoTreyRzzXV8zCttUH1wx4km12LTK+fwcp1ssVM7uXE57qPPr6dTW4ZPzf4QXx7VqmNgVGyQqOgpfW6Hj90Ejlc3Xx1+yENFZ0VLoxhcFikKmt6O40dM4KuJe7W/2tOwoqyFIsUukl/iQusBYhxFYRDShGCjcEXTcV40PoplWIk25JNS78LrBr/9+1EbVtZ5M1bafJbI0PFKTlE3fpyFhgvRIYlCPww0mICScc7qbUYn8OQSdhil3dHGWuOyeEBB/fLU+E7hC5OV3+VRDyXsBGTauVbhWSrboUoYxgGFrIqTt1QCUQjPQl5EM1PHPS0N4aM+HpuiC05urTJxhhDOu3HhektGd92xFaMN29gYvDITGTPB24VqksoXZk5xcMZnfxSZpB3Orb2Y/TlWQ+6YX8uHh22I/2DFyc4ePjbwzXB1uKPNWbLpFWAfYTMJreVNwB/6E/vQB/ajoLqeD5j9pzOTaMpQ3GKLtRtsp/jyJ91dQ2+9DWDCyNYYHNEekm32LRoZJSi7x45Nnc0qbWTrZGxtASWGgDaqXcYdVZ44ne3wgI1Hey2Jvf/oQg8ywrOqvn8S7RYfyvIcFdrt64jmYqvQ3W3XO9PuFNF8b5BGOzNaG13fleFu4A7skeFjQ9gunYaXR5HqdOcoTn+GrOD5q9asEEhDFtVGlo0wV1nzeYcIuXFnGky3pSgc8H5fQz39AO9qx2k0xuLVtAmj76OJSYyT1OZOIB1Tk4Illsd+qXeXKhMGlSC8iVk3wl6gvQoF6bGh2rzgjhnZvtjOrIF74vSFME6OHL3duO0X7AVF0+9kY4wKvOBMeIxM8BWw9v8XZ9jTBthJzJbb3NH7DYA+c7oK6DBGFd87jEHtGM/mVxx2Y9k6anV98A2c9JMzbH/v8a5OIL5PJiF8wfMNKH6rLY9jTPIwYetm6RLWUUo+TPuuJHsSlGXEputYHiq+9jrHRMYrijRc/5l4sDCxKIxgZ8G7p3/9tqon2leO/9hOmFgIj80mEScPBvcY/x/jWaDegN3N1257I9M1APkbuDosTc6O/q++1VihIX73jRYaSNgy0mNlj4epyzPPUdgkqbv7AyllJexiCXVbu+VKdV2RpGwTHIktdWj2FVPyUiwpT5UT9NRxb5VwSV6XhEMqfLXwILKDbl1gSG1VZlWFZ7pEYbX3l/E9h0KjrrtBjGJIvSlfaLoVyK0afyKgutrrC2d7kO44WFunSfDD8Id6WwQ3TI8lwZlZ4fy7atwb54YFvalr3uBhfZZC436Cuv0NCEymF8LMkniUfPr4GWwDl5neqLX5twyhPuUaQf2zBlkZVxM1LaqNHD5DucrrseMrf2fw/wEAWNF9Vg=="
 }
@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package disk_health
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// ataSMARTSize is the size of the SMART data and thresholds pages.
+	ataSMARTSize = 512
+	// ataMaxAttributes is the number of attribute entries of the pages.
+	ataMaxAttributes = 30
+	// ataAttributeSize is the size of an attribute entry.
+	ataAttributeSize = 12
+
+	// ataPrefailure is the flag of the attributes whose value at or below
+	// the threshold predicts an imminent failure.
+	ataPrefailure = 0x1
+)
+
+// Attributes IDs are not standard, these are the ones used by most of the
+// vendors.
+const (
+	attrReallocatedSectors    = 5
+	attrPowerOnHours          = 9
+	attrPowerCycles           = 12
+	attrWearLevelingCount     = 177
+	attrReportedUncorrect     = 187
+	attrAirflowTemperature    = 190
+	attrTemperature           = 194
+	attrPendingSectors        = 197
+	attrOfflineUncorrectable  = 198
+	attrCRCErrors             = 199
+	attrPercentLifetimeRemain = 202
+	attrSSDLifeLeft           = 231
+	attrMediaWearoutIndicator = 233
+)
+
+// ataWearAttributes are the attributes whose normalized value is the
+// remaining life of SSDs, by order of preference.
+var ataWearAttributes = []uint8{
+	attrMediaWearoutIndicator,
+	attrSSDLifeLeft,
+	attrWearLevelingCount,
+	attrPercentLifetimeRemain,
+}
+
+// ataAttribute is a SMART attribute of an ATA disk.
+type ataAttribute struct {
+	ID        uint8
+	Flags     uint16
+	Value     uint8 // normalized value, higher is better
+	Worst     uint8
+	Raw       uint64 // 48 bits vendor specific raw value
+	Threshold uint8
+}
+
+// failing returns true if the attribute predicts an imminent failure.
+func (a ataAttribute) failing() bool {
+	return a.Flags&ataPrefailure != 0 && a.Threshold > 0 && a.Value <= a.Threshold
+}
+
+// ataSMART are the attributes of an ATA disk, by ID.
+type ataSMART map[uint8]ataAttribute
+
+// parseATASMART parses the pages returned by the SMART READ DATA and SMART
+// READ ATTRIBUTE THRESHOLDS commands. The thresholds are optional.
+func parseATASMART(values, thresholds []byte) (ataSMART, error) {
+	if err := checkATAPage(values); err != nil {
+		return nil, fmt.Errorf("invalid SMART data page: %w", err)
+	}
+	limits := map[uint8]uint8{}
+	if thresholds != nil {
+		if err := checkATAPage(thresholds); err != nil {
+			return nil, fmt.Errorf("invalid SMART thresholds page: %w", err)
+		}
+		for i := 0; i < ataMaxAttributes; i++ {
+			entry := thresholds[2+i*ataAttributeSize:]
+			if entry[0] != 0 {
+				limits[entry[0]] = entry[1]
+			}
+		}
+	}
+
+	attrs := ataSMART{}
+	for i := 0; i < ataMaxAttributes; i++ {
+		entry := values[2+i*ataAttributeSize : 2+(i+1)*ataAttributeSize]
+		if entry[0] == 0 {
+			continue
+		}
+		raw := make([]byte, 8)
+		copy(raw, entry[5:11])
+		attrs[entry[0]] = ataAttribute{
+			ID:        entry[0],
+			Flags:     binary.LittleEndian.Uint16(entry[1:3]),
+			Value:     entry[3],
+			Worst:     entry[4],
+			Raw:       binary.LittleEndian.Uint64(raw),
+			Threshold: limits[entry[0]],
+		}
+	}
+	return attrs, nil
+}
+
+// checkATAPage checks the size and the checksum of a SMART page, the sum of
+// all its bytes must be zero.
+func checkATAPage(page []byte) error {
+	if len(page) < ataSMARTSize {
+		return fmt.Errorf("page too short: %d bytes", len(page))
+	}
+	var sum uint8
+	for _, b := range page[:ataSMARTSize] {
+		sum += b
+	}
+	if sum != 0 {
+		return errors.New("wrong checksum")
+	}
+	return nil
+}
+
+// raw returns the raw value of an attribute. Only the lower 32 bits are
+// returned, as some vendors store other values in the higher bytes.
+func (s ataSMART) raw(id uint8) (uint64, bool) {
+	a, found := s[id]
+	return a.Raw & 0xffffffff, found
+}
+
+// temperature returns the current temperature of the disk, in Celsius.
+func (s ataSMART) temperature() (uint64, bool) {
+	for _, id := range []uint8{attrTemperature, attrAirflowTemperature} {
+		if a, found := s[id]; found {
+			return a.Raw & 0xff, true
+		}
+	}
+	return 0, false
+}
+
+// wearUsed returns the fraction of the estimated life of an SSD used.
+func (s ataSMART) wearUsed() (float64, bool) {
+	for _, id := range ataWearAttributes {
+		if a, found := s[id]; found && a.Value <= 100 {
+			return float64(100-a.Value) / 100, true
+		}
+	}
+	return 0, false
+}
+
+// failing returns the IDs of the attributes predicting an imminent failure.
+func (s ataSMART) failing() []int {
+	failing := []int{}
+	for id := 0; id <= 0xff; id++ {
+		if a, found := s[uint8(id)]; found && a.failing() {
+			failing = append(failing, int(a.ID))
+		}
+	}
+	return failing
+}
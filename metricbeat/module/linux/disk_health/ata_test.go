@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package disk_health
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func readATAPages(t *testing.T) (values, thresholds []byte) {
+	t.Helper()
+	values, err := os.ReadFile("testdata/ata-smart-values.bin")
+	require.NoError(t, err)
+	thresholds, err = os.ReadFile("testdata/ata-smart-thresholds.bin")
+	require.NoError(t, err)
+	return values, thresholds
+}
+
+// setATAValue sets the normalized value of an attribute and fixes the
+// checksum of the page.
+func setATAValue(t *testing.T, page []byte, id, value uint8) {
+	t.Helper()
+	for i := 0; i < ataMaxAttributes; i++ {
+		entry := page[2+i*ataAttributeSize:]
+		if entry[0] == id {
+			page[511] += entry[3] - value
+			entry[3] = value
+			return
+		}
+	}
+	t.Fatalf("attribute %d not found", id)
+}
+
+func TestParseATASMART(t *testing.T) {
+	values, thresholds := readATAPages(t)
+
+	smart, err := parseATASMART(values, thresholds)
+	require.NoError(t, err)
+	assert.Len(t, smart, 10)
+	assert.Equal(t, ataAttribute{ID: 5, Flags: 0x33, Value: 100, Worst: 100, Raw: 8, Threshold: 10}, smart[5])
+
+	// Vendor data in the higher bytes of the raw value is ignored.
+	hours, found := smart.raw(attrPowerOnHours)
+	assert.True(t, found)
+	assert.Equal(t, uint64(21000), hours)
+
+	// The temperature is the lowest byte, followed by the min and max.
+	temperature, found := smart.temperature()
+	assert.True(t, found)
+	assert.Equal(t, uint64(36), temperature)
+
+	// Thresholds are optional.
+	smart, err = parseATASMART(values, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), smart[5].Threshold)
+	assert.Empty(t, smart.failing())
+
+	values[100]++
+	_, err = parseATASMART(values, thresholds)
+	assert.ErrorContains(t, err, "wrong checksum")
+
+	_, err = parseATASMART(values[:200], nil)
+	assert.ErrorContains(t, err, "too short")
+}
+
+func TestATAEvent(t *testing.T) {
+	values, thresholds := readATAPages(t)
+	smart, err := parseATASMART(values, thresholds)
+	require.NoError(t, err)
+
+	assert.Equal(t, mapstr.M{
+		"healthy":               true,
+		"failing_attributes":    []int{},
+		"temperature":           mapstr.M{"celsius": uint64(36)},
+		"wear":                  mapstr.M{"used": mapstr.M{"pct": 0.06}},
+		"power_on":              mapstr.M{"hours": uint64(21000)},
+		"power_cycles":          mapstr.M{"count": uint64(530)},
+		"media_errors":          mapstr.M{"count": uint64(0)},
+		"reallocated_sectors":   mapstr.M{"count": uint64(8)},
+		"pending_sectors":       mapstr.M{"count": uint64(0)},
+		"uncorrectable_sectors": mapstr.M{"count": uint64(0)},
+		"crc_errors":            mapstr.M{"count": uint64(2)},
+	}, ataEvent(smart))
+
+	// Reallocated sectors reached the threshold of the pre-failure attribute.
+	setATAValue(t, values, attrReallocatedSectors, 10)
+	// Attributes that are not pre-failure never fail.
+	setATAValue(t, values, attrPowerCycles, 0)
+	smart, err = parseATASMART(values, thresholds)
+	require.NoError(t, err)
+
+	event := ataEvent(smart)
+	assert.Equal(t, false, event["healthy"])
+	assert.Equal(t, []int{attrReallocatedSectors}, event["failing_attributes"])
+}
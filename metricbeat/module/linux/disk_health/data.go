@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package disk_health
+
+import (
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// nvmeDataUnit is the size of the data units of the NVMe counters.
+const nvmeDataUnit = 1000 * 512
+
+// nvmeEvent returns the fields of the event of an NVMe controller.
+func nvmeEvent(h nvmeHealth) mapstr.M {
+	event := mapstr.M{
+		"healthy": h.CriticalWarning == 0,
+		"critical_warning": mapstr.M{
+			"value": h.CriticalWarning,
+			"flags": h.warnings(),
+		},
+		"spare": mapstr.M{
+			"available": mapstr.M{"pct": float64(h.AvailableSpare) / 100},
+			"threshold": mapstr.M{"pct": float64(h.AvailableSpareThreshold) / 100},
+		},
+		"wear": mapstr.M{
+			"used": mapstr.M{"pct": float64(h.PercentageUsed) / 100},
+		},
+		"data": mapstr.M{
+			"read":    mapstr.M{"bytes": h.DataUnitsRead * nvmeDataUnit},
+			"written": mapstr.M{"bytes": h.DataUnitsWritten * nvmeDataUnit},
+		},
+		"power_on":          mapstr.M{"hours": h.PowerOnHours},
+		"power_cycles":      mapstr.M{"count": h.PowerCycles},
+		"unsafe_shutdowns":  mapstr.M{"count": h.UnsafeShutdowns},
+		"media_errors":      mapstr.M{"count": h.MediaErrors},
+		"error_log_entries": mapstr.M{"count": h.ErrorLogEntries},
+	}
+
+	temperature := mapstr.M{
+		"warning":  mapstr.M{"minutes": h.WarningTempTime},
+		"critical": mapstr.M{"minutes": h.CriticalTempTime},
+	}
+	// A zero temperature is reported by controllers without sensor.
+	if h.Temperature > 0 {
+		temperature["celsius"] = int64(h.Temperature) - 273
+	}
+	event["temperature"] = temperature
+
+	return event
+}
+
+// ataEvent returns the fields of the event of an ATA disk.
+func ataEvent(s ataSMART) mapstr.M {
+	failing := s.failing()
+	event := mapstr.M{
+		"healthy":            len(failing) == 0,
+		"failing_attributes": failing,
+	}
+
+	if v, ok := s.temperature(); ok {
+		event.Put("temperature.celsius", v)
+	}
+	if v, ok := s.wearUsed(); ok {
+		event.Put("wear.used.pct", v)
+	}
+	for id, field := range map[uint8]string{
+		attrPowerOnHours:         "power_on.hours",
+		attrPowerCycles:          "power_cycles.count",
+		attrReportedUncorrect:    "media_errors.count",
+		attrReallocatedSectors:   "reallocated_sectors.count",
+		attrPendingSectors:       "pending_sectors.count",
+		attrOfflineUncorrectable: "uncorrectable_sectors.count",
+		attrCRCErrors:            "crc_errors.count",
+	} {
+		if v, ok := s.raw(id); ok {
+			event.Put(field, v)
+		}
+	}
+
+	return event
+}
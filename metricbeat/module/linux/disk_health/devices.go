@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package disk_health
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	typeNVMe = "nvme"
+	typeATA  = "ata"
+)
+
+// disk is a disk whose health is reported.
+type disk struct {
+	name string // kernel name of the disk
+	path string // path of the device node
+	typ  string
+}
+
+// newDisk returns the disk of a device path configured by the user. NVMe
+// devices are recognized by their name, other devices are considered ATA
+// disks.
+func newDisk(path string) disk {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, "nvme") {
+		return disk{name: name, path: path, typ: typeNVMe}
+	}
+	return disk{name: name, path: path, typ: typeATA}
+}
+
+// discoverDisks lists the NVMe controllers and the ATA disks of the system
+// from sysfs. SCSI disks are ATA disks if their vendor is ATA, as set by
+// libata.
+func discoverDisks(sysPath, devPath string) ([]disk, error) {
+	var disks []disk
+
+	controllers, err := os.ReadDir(filepath.Join(sysPath, "class", "nvme"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error listing NVMe controllers: %w", err)
+	}
+	for _, c := range controllers {
+		disks = append(disks, disk{name: c.Name(), path: filepath.Join(devPath, c.Name()), typ: typeNVMe})
+	}
+
+	blocks, err := os.ReadDir(filepath.Join(sysPath, "block"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing block devices: %w", err)
+	}
+	for _, b := range blocks {
+		if !strings.HasPrefix(b.Name(), "sd") {
+			continue
+		}
+		vendor, err := os.ReadFile(filepath.Join(sysPath, "block", b.Name(), "device", "vendor"))
+		if err != nil || strings.TrimSpace(string(vendor)) != "ATA" {
+			continue
+		}
+		disks = append(disks, disk{name: b.Name(), path: filepath.Join(devPath, b.Name()), typ: typeATA})
+	}
+
+	sort.Slice(disks, func(i, j int) bool { return disks[i].name < disks[j].name })
+	return disks, nil
+}
@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package disk_health
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-system-metrics/metric/system/resolve"
+)
+
+// init registers the MetricSet with the central registry as soon as the program
+// starts. The New function will be called later to instantiate an instance of
+// the MetricSet for each host defined in the module's configuration. After the
+// MetricSet has been created then Fetch will begin to be called periodically.
+func init() {
+	mb.Registry.MustAddMetricSet("linux", "disk_health", New)
+}
+
+type config struct {
+	Devices []string `config:"disk_health.devices"`
+}
+
+// MetricSet holds any configuration or state information. It must implement
+// the mb.MetricSet interface. And this is best achieved by embedding
+// mb.BaseMetricSet because it implements all of the required mb.MetricSet
+// interface methods except for Fetch.
+type MetricSet struct {
+	mb.BaseMetricSet
+	config config
+	mod    resolve.Resolver
+	reader reader
+}
+
+// New creates a new instance of the MetricSet. New is responsible for unpacking
+// any MetricSet specific configuration options if there are any.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The linux disk_health metricset is beta."))
+
+	config := config{}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		config:        config,
+		mod:           base.Module().(resolve.Resolver),
+		reader:        ioctlReader{},
+	}, nil
+}
+
+// Fetch reports an event with the health of each disk. Errors reading the
+// configured devices are reported, while discovered disks not supporting
+// SMART, like disks behind USB bridges, are skipped.
+func (m *MetricSet) Fetch(report mb.ReporterV2) error {
+	var disks []disk
+	if len(m.config.Devices) > 0 {
+		for _, path := range m.config.Devices {
+			disks = append(disks, newDisk(m.mod.ResolveHostFS(path)))
+		}
+	} else {
+		var err error
+		disks, err = discoverDisks(m.mod.ResolveHostFS("/sys"), m.mod.ResolveHostFS("/dev"))
+		if err != nil {
+			return fmt.Errorf("error discovering disks: %w", err)
+		}
+	}
+
+	for _, d := range disks {
+		event, err := m.diskEvent(d)
+		if err != nil {
+			err = fmt.Errorf("error reading health of disk %s: %w", d.name, err)
+			if len(m.config.Devices) == 0 {
+				m.Logger().Debug(err)
+				continue
+			}
+			report.Error(err)
+			continue
+		}
+
+		event["device"] = mapstr.M{
+			"name": d.name,
+			"path": d.path,
+		}
+		event["type"] = d.typ
+		if !report.Event(mb.Event{MetricSetFields: event}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *MetricSet) diskEvent(d disk) (mapstr.M, error) {
+	switch d.typ {
+	case typeNVMe:
+		page, err := m.reader.nvmeHealth(d.path)
+		if err != nil {
+			return nil, err
+		}
+		health, err := parseNVMeHealth(page)
+		if err != nil {
+			return nil, err
+		}
+		return nvmeEvent(health), nil
+	default:
+		values, thresholds, err := m.reader.ataSMART(d.path)
+		if err != nil {
+			return nil, err
+		}
+		smart, err := parseATASMART(values, thresholds)
+		if err != nil {
+			return nil, err
+		}
+		return ataEvent(smart), nil
+	}
+}
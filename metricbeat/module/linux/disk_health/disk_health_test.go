@@ -0,0 +1,154 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package disk_health
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	_ "github.com/elastic/beats/v7/metricbeat/module/linux"
+)
+
+// fakeReader returns the captured pages of the testdata directory for the
+// devices in pages.
+type fakeReader struct {
+	t     *testing.T
+	pages map[string]string
+}
+
+func (r fakeReader) nvmeHealth(path string) ([]byte, error) {
+	if r.pages[path] != typeNVMe {
+		return nil, errors.New("inappropriate ioctl for device")
+	}
+	return readNVMePage(r.t), nil
+}
+
+func (r fakeReader) ataSMART(path string) ([]byte, []byte, error) {
+	if r.pages[path] != typeATA {
+		return nil, nil, errors.New("inappropriate ioctl for device")
+	}
+	values, thresholds := readATAPages(r.t)
+	return values, thresholds, nil
+}
+
+// newSysfs creates a sysfs tree with the NVMe controllers and the vendors
+// of the SCSI disks.
+func newSysfs(t *testing.T, controllers []string, disks map[string]string) string {
+	t.Helper()
+
+	hostfs := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs, "sys", "class", "nvme"), 0o755))
+	for _, c := range controllers {
+		require.NoError(t, os.Mkdir(filepath.Join(hostfs, "sys", "class", "nvme", c), 0o755))
+	}
+	for name, vendor := range disks {
+		dir := filepath.Join(hostfs, "sys", "block", name, "device")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor"), []byte(vendor+"\n"), 0o644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(hostfs, "sys", "block", "loop0"), 0o755))
+	return hostfs
+}
+
+func TestDiscoverDisks(t *testing.T) {
+	hostfs := newSysfs(t, []string{"nvme1", "nvme0"}, map[string]string{
+		"sda": "ATA     ",
+		"sdb": "Generic ",
+	})
+
+	disks, err := discoverDisks(filepath.Join(hostfs, "sys"), "/dev")
+	require.NoError(t, err)
+	assert.Equal(t, []disk{
+		{name: "nvme0", path: "/dev/nvme0", typ: typeNVMe},
+		{name: "nvme1", path: "/dev/nvme1", typ: typeNVMe},
+		{name: "sda", path: "/dev/sda", typ: typeATA},
+	}, disks)
+
+	// Systems without NVMe.
+	require.NoError(t, os.RemoveAll(filepath.Join(hostfs, "sys", "class")))
+	disks, err = discoverDisks(filepath.Join(hostfs, "sys"), "/dev")
+	require.NoError(t, err)
+	assert.Equal(t, []disk{{name: "sda", path: "/dev/sda", typ: typeATA}}, disks)
+}
+
+func TestNewDisk(t *testing.T) {
+	assert.Equal(t, disk{name: "nvme0", path: "/dev/nvme0", typ: typeNVMe}, newDisk("/dev/nvme0"))
+	assert.Equal(t, disk{name: "sdc", path: "/dev/sdc", typ: typeATA}, newDisk("/dev/sdc"))
+}
+
+func TestFetch(t *testing.T) {
+	hostfs := newSysfs(t, []string{"nvme0"}, map[string]string{"sda": "ATA", "sdb": "ATA"})
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(hostfs, nil))
+	ms, ok := f.(*MetricSet)
+	require.True(t, ok)
+	ms.reader = fakeReader{t: t, pages: map[string]string{
+		filepath.Join(hostfs, "dev", "nvme0"): typeNVMe,
+		filepath.Join(hostfs, "dev", "sda"):   typeATA,
+	}}
+
+	// sdb doesn't support SMART and is skipped.
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	nvme := events[0].MetricSetFields
+	assert.Equal(t, "nvme", nvme["type"])
+	name, _ := nvme.GetValue("device.name")
+	assert.Equal(t, "nvme0", name)
+	assert.Equal(t, true, nvme["healthy"])
+
+	ata := events[1].MetricSetFields
+	assert.Equal(t, "ata", ata["type"])
+	reallocated, _ := ata.GetValue("reallocated_sectors.count")
+	assert.Equal(t, uint64(8), reallocated)
+}
+
+func TestFetchConfiguredDevices(t *testing.T) {
+	hostfs := t.TempDir()
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(hostfs, []string{"/dev/sda", "/dev/sdb"}))
+	ms, ok := f.(*MetricSet)
+	require.True(t, ok)
+	ms.reader = fakeReader{t: t, pages: map[string]string{
+		filepath.Join(hostfs, "dev", "sda"): typeATA,
+	}}
+
+	// Errors reading the configured devices are reported.
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Len(t, events, 1)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "error reading health of disk sdb")
+}
+
+func getConfig(hostfs string, devices []string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":              "linux",
+		"metricsets":          []string{"disk_health"},
+		"hostfs":              hostfs,
+		"disk_health.devices": devices,
+	}
+}
@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package disk_health
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// reader reads the health pages of the disks. It is replaced by captured
+// pages in tests.
+type reader interface {
+	// nvmeHealth returns the SMART / Health Information log page of an NVMe
+	// controller.
+	nvmeHealth(path string) ([]byte, error)
+	// ataSMART returns the SMART data and thresholds pages of an ATA disk.
+	// Thresholds are nil if the disk doesn't report them.
+	ataSMART(path string) (values, thresholds []byte, err error)
+}
+
+const (
+	// nvmeIoctlAdminCmd is _IOWR('N', 0x41, struct nvme_admin_cmd).
+	nvmeIoctlAdminCmd     = 0xc0484e41
+	nvmeAdminGetLogPage   = 0x02
+	nvmeLogSMART          = 0x02
+	nvmeNSIDAll           = 0xffffffff
+	nvmeAdminCmdTimeoutMS = 5000
+
+	// hdioDriveCmd is HDIO_DRIVE_CMD, libata translates it to an ATA
+	// pass-through command for SATA disks.
+	hdioDriveCmd           = 0x031f
+	ataSMARTCmd            = 0xb0
+	ataSMARTReadValues     = 0xd0
+	ataSMARTReadThresholds = 0xd1
+)
+
+// nvmePassthruCmd is struct nvme_passthru_cmd of linux/nvme_ioctl.h.
+type nvmePassthruCmd struct {
+	Opcode      uint8
+	Flags       uint8
+	Rsvd1       uint16
+	NSID        uint32
+	Cdw2        uint32
+	Cdw3        uint32
+	Metadata    uint64
+	Addr        uint64
+	MetadataLen uint32
+	DataLen     uint32
+	Cdw10       uint32
+	Cdw11       uint32
+	Cdw12       uint32
+	Cdw13       uint32
+	Cdw14       uint32
+	Cdw15       uint32
+	TimeoutMS   uint32
+	Result      uint32
+}
+
+// ioctlReader reads the health pages of the disks with ioctls. They require
+// the CAP_SYS_ADMIN capability for NVMe disks and CAP_SYS_RAWIO for ATA
+// disks.
+type ioctlReader struct{}
+
+func (ioctlReader) nvmeHealth(path string) ([]byte, error) {
+	page := make([]byte, nvmeLogSize)
+	numDwords := uint32(nvmeLogSize/4 - 1)
+	cmd := nvmePassthruCmd{
+		Opcode:    nvmeAdminGetLogPage,
+		NSID:      nvmeNSIDAll,
+		Addr:      uint64(uintptr(unsafe.Pointer(&page[0]))),
+		DataLen:   nvmeLogSize,
+		Cdw10:     nvmeLogSMART | numDwords<<16,
+		TimeoutMS: nvmeAdminCmdTimeoutMS,
+	}
+	err := ioctl(path, nvmeIoctlAdminCmd, unsafe.Pointer(&cmd))
+	runtime.KeepAlive(page)
+	if err != nil {
+		return nil, fmt.Errorf("error reading NVMe health log page: %w", err)
+	}
+	return page, nil
+}
+
+func (ioctlReader) ataSMART(path string) (values, thresholds []byte, err error) {
+	values, err = ataSMARTCommand(path, ataSMARTReadValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading SMART data: %w", err)
+	}
+	// Thresholds are obsolete since ATA-8, and not reported by some disks.
+	thresholds, _ = ataSMARTCommand(path, ataSMARTReadThresholds)
+	return values, thresholds, nil
+}
+
+// ataSMARTCommand runs a SMART command reading a page. The arguments of
+// HDIO_DRIVE_CMD are the command, the sector number, the feature and the
+// sector count, followed by the data read.
+func ataSMARTCommand(path string, feature byte) ([]byte, error) {
+	args := make([]byte, 4+ataSMARTSize)
+	args[0], args[2], args[3] = ataSMARTCmd, feature, 1
+	if err := ioctl(path, hdioDriveCmd, unsafe.Pointer(&args[0])); err != nil {
+		return nil, err
+	}
+	return args[4:], nil
+}
+
+func ioctl(path string, req uintptr, arg unsafe.Pointer) error {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	status, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+	if errno != 0 {
+		return fmt.Errorf("ioctl on %s failed: %w", path, errno)
+	}
+	// NVMe commands return the status of the command.
+	if status != 0 {
+		return fmt.Errorf("command on %s failed with status %#x", path, status)
+	}
+	return nil
+}
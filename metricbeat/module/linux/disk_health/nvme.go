@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package disk_health
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// nvmeLogSize is the size of the SMART / Health Information log page.
+const nvmeLogSize = 512
+
+// nvmeCriticalWarnings are the bits of the critical warning field of the
+// SMART / Health Information log page.
+var nvmeCriticalWarnings = []string{
+	"spare",                       // available spare below threshold
+	"temperature",                 // temperature out of thresholds
+	"reliability",                 // reliability degraded by media errors
+	"read_only",                   // media in read only mode
+	"volatile_memory_backup",      // volatile memory backup device failed
+	"persistent_memory_read_only", // persistent memory region in read only mode
+}
+
+// nvmeHealth is the SMART / Health Information log page (log identifier
+// 02h) of an NVMe controller, as described in section 5.16.1.3 of the NVMe
+// base specification.
+type nvmeHealth struct {
+	CriticalWarning         uint8
+	Temperature             uint16 // Kelvin
+	AvailableSpare          uint8  // percent
+	AvailableSpareThreshold uint8  // percent
+	PercentageUsed          uint8
+	DataUnitsRead           uint64 // thousands of 512 bytes units
+	DataUnitsWritten        uint64
+	PowerCycles             uint64
+	PowerOnHours            uint64
+	UnsafeShutdowns         uint64
+	MediaErrors             uint64
+	ErrorLogEntries         uint64
+	WarningTempTime         uint32 // minutes
+	CriticalTempTime        uint32 // minutes
+}
+
+// parseNVMeHealth parses a SMART / Health Information log page.
+func parseNVMeHealth(page []byte) (nvmeHealth, error) {
+	if len(page) < nvmeLogSize {
+		return nvmeHealth{}, fmt.Errorf("NVMe health log page too short: %d bytes", len(page))
+	}
+
+	le := binary.LittleEndian
+	return nvmeHealth{
+		CriticalWarning:         page[0],
+		Temperature:             le.Uint16(page[1:3]),
+		AvailableSpare:          page[3],
+		AvailableSpareThreshold: page[4],
+		PercentageUsed:          page[5],
+		DataUnitsRead:           uint128(page[32:48]),
+		DataUnitsWritten:        uint128(page[48:64]),
+		PowerCycles:             uint128(page[112:128]),
+		PowerOnHours:            uint128(page[128:144]),
+		UnsafeShutdowns:         uint128(page[144:160]),
+		MediaErrors:             uint128(page[160:176]),
+		ErrorLogEntries:         uint128(page[176:192]),
+		WarningTempTime:         le.Uint32(page[192:196]),
+		CriticalTempTime:        le.Uint32(page[196:200]),
+	}, nil
+}
+
+// uint128 reads a 128 bits little endian counter, saturating the values
+// that don't fit in 64 bits.
+func uint128(b []byte) uint64 {
+	if binary.LittleEndian.Uint64(b[8:16]) != 0 {
+		return math.MaxUint64
+	}
+	return binary.LittleEndian.Uint64(b[0:8])
+}
+
+// warnings returns the names of the critical warnings set.
+func (h nvmeHealth) warnings() []string {
+	warnings := []string{}
+	for i, name := range nvmeCriticalWarnings {
+		if h.CriticalWarning&(1<<i) != 0 {
+			warnings = append(warnings, name)
+		}
+	}
+	return warnings
+}
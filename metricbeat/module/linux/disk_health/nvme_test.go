@@ -0,0 +1,96 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package disk_health
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func readNVMePage(t *testing.T) []byte {
+	t.Helper()
+	page, err := os.ReadFile("testdata/nvme-smart-log.bin")
+	require.NoError(t, err)
+	return page
+}
+
+func TestParseNVMeHealth(t *testing.T) {
+	health, err := parseNVMeHealth(readNVMePage(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, nvmeHealth{
+		Temperature:             310,
+		AvailableSpare:          100,
+		AvailableSpareThreshold: 10,
+		PercentageUsed:          3,
+		DataUnitsRead:           12345678,
+		DataUnitsWritten:        23456789,
+		PowerCycles:             120,
+		PowerOnHours:            5500,
+		UnsafeShutdowns:         15,
+		ErrorLogEntries:         42,
+	}, health)
+
+	_, err = parseNVMeHealth(make([]byte, 256))
+	assert.Error(t, err)
+}
+
+func TestNVMeEvent(t *testing.T) {
+	page := readNVMePage(t)
+	health, err := parseNVMeHealth(page)
+	require.NoError(t, err)
+
+	event := nvmeEvent(health)
+	assert.Equal(t, true, event["healthy"])
+	assert.Equal(t, mapstr.M{
+		"celsius":  int64(37),
+		"warning":  mapstr.M{"minutes": uint32(0)},
+		"critical": mapstr.M{"minutes": uint32(0)},
+	}, event["temperature"])
+	used, _ := event.GetValue("wear.used.pct")
+	assert.Equal(t, 0.03, used)
+	written, _ := event.GetValue("data.written.bytes")
+	assert.Equal(t, uint64(23456789*512000), written)
+
+	// Spare below threshold and degraded reliability.
+	page[0] = 0x05
+	page[160] = 7
+	health, err = parseNVMeHealth(page)
+	require.NoError(t, err)
+
+	event = nvmeEvent(health)
+	assert.Equal(t, false, event["healthy"])
+	assert.Equal(t, mapstr.M{"value": uint8(5), "flags": []string{"spare", "reliability"}}, event["critical_warning"])
+	mediaErrors, _ := event.GetValue("media_errors.count")
+	assert.Equal(t, uint64(7), mediaErrors)
+}
+
+func TestUint128(t *testing.T) {
+	b := make([]byte, 16)
+	b[0] = 1
+	assert.Equal(t, uint64(1), uint128(b))
+
+	b[8] = 1
+	assert.Equal(t, uint64(math.MaxUint64), uint128(b))
+}
@@ -32,5 +32,5 @@ func init() {
 // AssetLinux returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/linux.
 func AssetLinux() string {
-	return "eJzUnOuP28YRwL/7rxgcUCAObPnOTpzkPhS45oLCqK85+IECLVpitByKGy136X2crPz1xe6SEiWREvWi7/TNJ2rnN7Pz2iHplzCl+TUILt3XZwCWW0HXcPHe//viGYAmQWjoGsZk8RlASoZpXlqu5DX89RkAxN9CoVIn6BlAxkmk5jp89RIkFrRc3n/svKRrmGjlyuovLWsu1zVzY6mAgqzmzFRfNmU05TAlpdXIpotv2uT5z7pe9aeDxX/aFl8HacIYVxSo5yvfdeHsEO0/1XKgMpBZsoABY9FyYzkzL8I1lAIyrYyBX+8/A1OazNpabdBN8FSrdbYluVBy0vLlDnj/KZFNyZqwfEkppI7AqqVZIUMunKZOLkIt5smZ6JYYJK3mtOS0CgqcEmilCsiUBkkzUHLDrA3QuMIZKGs2LsHm1IC2OBbdlsuUk+kZcIxjjIzJnBBzMISa5ZR2ql/T8IlULdt8Og8zRBJQaMJ0HmxEzMaNxLV9Xo/OBqQ0pG3ifZLOYbp/umJM2kdzvaeznDSB4MZWwqFD+BLxAQU/B9yKIW2OFhhKqSyMCYLxtlBFN0g0GYvangEuuDoIpaau9FbjLIccw/aOCSq5y/wSL9dk+J8Nn1zYUPn8eY5ysbHytlrhXXWk6YsjY0cF6QmZpCSdGGKt9SMTCtdtu9N0n3ICuXA7LxIqkQaCzBRK0mCIKZnGbZ95l/ziyMXw8TknpQfOaNSqxkxzSwPrEWSeWpGV/Rh0I5a03JgN2oZePTZgWMsfRx4sXgGPxnO7UT9Ohv03v3i0eqZV0c44Wu+ZlC7QXsMm2YoCOEO+jnckOD6QxgmB5QWBKUna0ISsek2rxWNCNKQfKN0WrwNaPbrMKc0eVRjO7mtOf6Dh6wjFh0niC9N50P3K8B2X0XzP/TZ4yp4R204ecuiZuYMMECQnNj8J9JBheZxj+O84o8QveyaniBIiuHeOggvBY/iZ50GJd69+P87eY2faz70H0d+TZiSth1dZONUG9tRpLidVA7iC3F2EvhujTGc8tTk4ywX/E73YoPTyqucjuI2XG7ROx0sUY06HJt13xNzAAwrnpQATyoStvbq8/MvSHhut5tQU5+gzV5fdOpCwaNvz+wHjiH98vGuMHvacMJToG0OToz7L2epjWDhK8UdlZ2i9sLSx8FZ5R8NwX/iq9aO0XTBOns00nyX/4mgLRoELjAcl0PKWscLxGPdha1iOcuKtYpWCDI2tE2TQvttKmRMiMQzlOUYsy4O5zzLVgSKcIaM/5fhAMPYHY08gt3GacPZMpEopYTnys/BGU4YsHdA0YZjCFPg18cS1a/fDTF15XqOmrhScoT+g+xSy5og1UkGFWhnOnCxdvo/T6rA+pLjys23JM5ry8AxaSSxDCT44b068zyVTM8MyHQXb7btZdUcdO/NduxldvnJ0GM8hit4FmHJNzA4PGOWK7qleOck00XBgXlqwm+8u4q2MbbazhGLA3V2eziKtJiaQF313OtAOt9XdtDu3PV6QUJZxxkmy+ahk3aNJw1BQmrT1qk3qMralu7Cj7JoWlgz1AjihEdyAUDPSjb8Bl2lIlKbhPL7fNFa7yUTEurlYN+aX7iQft/PbmCDK/iYmqNXP3YTafLQ7e5eaMv71Gi7+E6zw34tt2f2TPxGEVYApaX2pb2R5X6ewugPhQSoHdiYcaWRDuY35x46CYJVFcd6w21XQGwpVd6VKpUS3IzpDaeuoqTd31497YN/FKuwZPC0KoWIvstRiB/m2qNnB3TNaOtq8lUPwihdtbZU17d2/n9Y/8AG5CP3zvp6iKU5Kvi1/TQFjZ0Eq2+o0/RQyTpfCnblM7tJHPZBmqii47ev3KWXohG0b+PVmPyJmb6P4OM3MlO5kXph5huvJfPA0H1L6DMu67//Wuf4I+3/ycpvKPNLc/tkn9V6YxyTFIwBvFpmwvzGHa9I6Nj+nZkOmslg7eynA5WHHgt5DD09RhkreDaHcgYeTvSm8pC21DFPMCdMRC+OZM9B89HuykFNPq7iNt9s8ZaIxyXkvymFMNkbvTEo2sTWlnPklWsZCnorLTJ1jMNSy9rZ50Nil6TxZ+0E3UA/z3KLFuFevSq3YqyDBC4iL+cNOiL24seM5KJ1utAO7isjt3U3nrrYx9+AO7Hc3cbR1uzpR24XVRLu4XK+9q3gdTteTMFBoImC5k1PjM9nr/11+f3/z99+Sj+/+/dt2tKvB0a76or0eHO11X7Q3g6O96Yv2w+BoP/RF+3FwtB/7or0dHO1tX7SfBkf7qS/az4Oj/dwX7ZfB0X7pnXKHLwdXXfWghpIqJTP6vrXiq/EftNGsxz8m8YopzWdKr7d+jUuSAktf56vrL4zVXE62nkg3tfuAs3pEwZWE0EA0ugpfpf3yK53Lor/SZMzqI/hH9FfxVlu9pj8oCxF4/LnEs1UvdYRDPSvdi+pE8QJQpsD7dmKsdCOjChpdXbYcl7qfO9l+Ptph5ZUnanLUVN8wBi6rR1LQgjeSBQ8HFs20ejIlGCL2vb/efw4DGUCwJOsnVmdcpmrWPtxYaPv2SWtr+Fc730vfN5dPWmGb+2yTO5lqf37eQ/Ew9xl54aON2WFnKuyhX1gXcGyUcLYOz8ZTYUyrxVNh+2rdrlI1MHiqwVrdPNgvXps6P8GQXdW5d9Q2tX6Kgbu21fvGblP9Jxe+226jVt9lTojHFsFLHYUAqeRLnortKoLhhRMWJSlnxIGBHUzxyAL7ZKbYN96DMR5bvJ/OMQ5MA8EqjyoN7G+SdgW5erLFnKs9473W9ZHF+n669g7oWtvHFsx7bu2+EVur/aiitZ/OnQo99WLN1XGFujbBIwvck5hgn3h+8sW52xEOCPOnXZSb4b64eYulOM3gblWpf6EN9/7RgKZShXfrx3N4Jy0J+HBz/77vnG7zP304xsZ+uer/TLA5BZD6DTBQmk+4DM/iZFoVI/hdem+R1a+4gS+ONK/eE89RpzPvnZ2Di1RjMZqh7Xj4/4C32u7Do67xqSC/8X7t+vGg2w83d1GfVBXIZTfSH8qJ072y+wFnUDa4mHLSkl68HRe41pBa2crycjhr3d9f7jSWBxrWVp6qn6muhjTVVQ9TXQ1uqqt+pkI2xQkNaK4ocLfJKrBhzVZJrcH+HwAA///RiQXl"
+	return "eJzcnV9v20iSwN/1KQoBDkgWDsdONplJHg7wxnN3wU5mDDtzC9zhjmg1S2Kvm91M/7Gi/fSLapIiKZEUZUv0ejB6iUVV/aq6uqr/sec13OH6I0ih/PcZgBNO4kd48Qv9+8UMwKBEZvEjzNGxGUCClhuRO6HVR/j3GQAUv4VMJ17iDGAhUCb2Y/jqNSiWYS2e/nPrHD/C0mifl3/pkFnLtWvrMIMMnRHcll82dTT1cK2UM4zfbb7p0gewaxfAIAt9uoRvgzRhrM8yZtat7/pw9qimTykO9ALUIt7AgHXMCesEt2fhGUyAcaOthU/XvwPXBu2sJagTugmeGL3NVpNLrZYdX+6Bp0/O+B06G8TnmEDiEZyu3QoLJqQ32MuFzMh1fCK6GgOVMwJrTqchY3cIRusMFtqAwhVohbYftJBwAsqKTShwKTZ859hc9ntuob1KToBjPedo7cJLuQaLzPAUk17zKxqxVNrgCXCqCLOICpg0yJJ18BFyVzQka7iMMNf9kMqicTHFJJ7Cdb/6bI6GenPVpqsUDYIU1pXKoUd5jXjPpEhO7UiXMgecKaUdzBFCJxmgKsIgNmgdM+4EcCHUQWp953PymuAppCw07xyh1Fvnl+Jxg1b8oxGTFW0i7F2cIpMune1L0g+oGf8VJFMr//rfXxCYSuDy62XQas+AAhQWRmfUmYWB2y+XN18hZ0u00Ww4X2/48V5wjOgfsy433+F6pU0yO8jLf0WjUAYNhE6ZhojPqpzjjJYSTUiGwS761kZDgDlz6fEAr5lLN2RBASidtGC7aahjHA/j6zpv6TwDdZ8haAPMsW6AItS6BwVzrSUydRjDfzBpEcQCmKrbAgzm2jgLDLgRTnAmYcWMEmp5Rnj0OOQGX/eV3CISmXNGzL0LRjK1CV0QFpgjQXOUegXCWXCpQZtqmfQ4HrMcDXPeYMRRWuHtbGRa2OOAT94YVK6pod0mQsGnQuN+tNJJUSaUd3gsxK8iwxAkoYEaHShlFuahWM31PQY/lgTAdZZrK9xu2zQN3Xg9mFlSj2iBMiie0M5NXB7d0BUyE3mLSZTz7fpTBJnlTGISL6Rm2w8stMmY+wg5Go7KHWb/z9aJjNFQY2EYpx5bRaIUi1ZUQuCDz6GwAn7niAlcnJ//247MrRwLvym5Lrs3JjBfw+3tVY8jcr1CE2sVpdqbY7VxPW4J4l9rBUH8EAJfc4k24tordxoMKFV0QmSYCBajMdqcCsIrg1zfo6FxB3MMhHK4NMKtodQLN1WbecW1Mci7h+zF4yRzM1Dodm3VgeIqZ90z6fFIln2qemeVjubCFeOQzVjmELSFZEt7vLq7TWfbUPDS5szgWTOB0GBLCjYXUrj12Y5EGonFWsn1GdxryZyQGGeYabOO54zf+fyMMoIV1qFy1TebH73qdkOgiNg9E5KaevJ0dIMZE+SfggQ4yxmnkNwgjWnM8Ntok38nt+K2zb4BabOfdQx1QGzHHNSZc5WGqQ02nBEshQWT0m4GNt0+SWh8R80fzdcHlM/KD10/2uOFK0oq9XyhabhQhcAB1JURzqGalrZUSnOw8bheWbbA2KbeJXqlTpevSQ1s1IzpCCEzx1Iv43LGfiK2oAeEKvxPgwipl5tVghGgBpmUmtNAJLbI3enKXkMTlJpalQte1lOIdz1ZMkeVCLU8MalXNtTbDeaKCUc5olo1yFhY7GvS74hrWHPx4ccee1rl/eRWNXTtbYGLDz/1MHPDTzs+ouGQWTCO8OnmU9cYp8354dUZeOuZlLsLdJzR2JlGvpwatKcP0MSWomoj1R7Jps9XthrINybQ2xPmLeMak+UdgcXST11fZ9umCE1L/KdYndqRPLTSRMUnMvjNo3VRhmaJNs7RUIR3urZrILDHtwBfUwS1iRpSCaVKC0FnQuMwinStkmJlckWrpt88+mKFt14S6g4Mqko4sR1B57ENabXHpA1R0wprd2gbdnVytxtgWs8/jjx4vAQeGEodAfsvJLy9PLzLGI0er7UMYFT5jgvO7tGwJYKj9R+b02ocLVu0o6bT40X1tWjuMYk6mYtwmdDrQeFR3R4kTuj3raB/oOPL30fsfhnT3slp0EkyvKzmBK+q0jqyx3aThxx6Yu6gAySqpUuPAj1ltywxHxgY1FsFx5h6+2mASw0FOAVHJqQURdazr6jHwecffnucv+fero9Hf10sFxO8XoSDF4E98YbmGMUeZQu5lxZezplKViJxKXgnpPhHMQcko+unXkVwVTxuaX28eERz7mlmU6xsCAthRZA2a7jUNhy2oCXm2h+zbafc2Wy27Y8jjDPbYocGmTQe7c7v2xgjmuWvt18ap2O2vu6iaJKEXdjYpsx07HUPTB9GcNHnNggu9nppR8NbjEawCLU8BYyg4UYpf3f/uQvGq5O55nclvnkcwMjYBqNarD0BxjUpAJ4ytSSvOK1hwayrEmSwvt9LdCQmtpwpewK0el5NWaacUNBkuIynlN1jsa9IBGqIs1gViWn3POYpEyfhLVwZsnRAM8jCQaGMfY+JuArtcZiJz0/r1MTnUhSLWpRCtgKxQio2AWb78tQD0mU4fFnKD7tJs+G0VRERaPyIDFpqJDGPyJtLirn4zq5YnkQka1vC3saqRtTFQkDHAy3qoKIKdFoWKlTvA0wEbcFND1jolesBvoVBnA6MtIWNZBpdFKdtB9isQyYnbN16dhaUgUEumcjGtnSgna6p+2n3NnvxQIyLheACFV93bLSN2mzbv+G2g13ormihZqgEsCVGcAkybLXXfwOhkpAobSN4aLxpnfHLpSzX1yu5RX7pT/JFcz6NCwrdT+KCyvzUL7ErRvuzd25wIb5/hBf/GyYt//diNmDh11TYojqFkzlU6htZnpaPWXlIlkDKAPaWioFWDeOiAwuC047J3lY8SrfbV9AbBpUHp3OtZdSLTBsMnUtNo7n7fjwC+0sIkXBAiCYG9QZbbcUe8qFes4e7DOyHkbcnwQ2nB6peZipAD+U9TnzURwAOjRSDtFaBydPyVxQw9w7omHZX0IwzyHqTS2+f1h46SMV1lgnXNqEfO8EF89J1LfhN0WevCvVA6kleL/PGzSuWP3Wap2CgqlcWpSfP9Y/w/1fS2zQm6kV80tz+OyX1UZiPSYqPALysD0ONduZ0g7RuZlo5zlulx492s1APmxaMwCoWPaix6VyJUP0Q2ruJKEhTLwZt17GU9jh5WJ45Ac0ttclGT1mXUjpaStttRBkbFqdiFOU0LpszCiatmtgGExGOdzeye8VHVHR2a7Yvsz9gYahDdldGrlDmPknW8dYP+oFGuCecQgxboz/kRvMfggZSUFhHk53Q9wjU0gxdmwTNgUXk6svlzndDzCO46XP15TIEXDic2PFEH1YT7cX5du0dFXQjCelDeR946tWdpUz25v/P/3R9+Z8/x7ef/+fnYbSLydEuxqK9mRztzVi0t5OjvR2L9ufJ0f48Fu3d5GjvxqK9nxzt/Vi0HydH+3Es2k+To/00Fu3D5GgfxqJdTF8OLvrqQQVFO3k2+tOWxMJXev533BmsF3+Mh17HaTwS0zlpOlhbPP/COtq4ezE7yLobtqqWKOjcQhhANEYVVKVJfGvkUtmXG7S2/crqI8ZXxVZbJZMmylK2jt6X947QTAV47s/KidtZeJVb6Nnw+KGC5rmPrM4wujjvWNPuP3cyPD/a4+XWiRrarq42jGklqDiSwhyQkxwQHDhGB8ZXWDmiGPfSYRZakAEG9DZHeWJ1JVSiV9Gwte+ftbVWfHfrg+x9e/68mzelbJN6ldDRlEMMD2v8ESmPjvZeN4VvkAtsbrWkFwNCVDZPhdE9N9WpsEOt7japXDB4rp213Dw4rL82bX7/3G0e3WubVr89f+5mH9x3m+Y/u+5bwg8ZRgew/tV6cG2jlKC0ei0SOWwiWJF56ZhC7a18YMcOrnj/B3XFof09OOPt+R/UGw9NA8Er/1Jp4HCXdBso9LMdeQt9YH8X+tkWcaEP7tBCP9/iLfSDe6zQz7RoC91r0HMv1kI/rlAL/eyLtNCPLdBCP//iLPSxCrPQz7woN7t7ZZRhuTzOwl3bqL8xF/b+ma0veJmv4bNyKOHm8vqXset0u/eSPsbHdPluea0nHbYhkOoNMNBGLIUqLwvTWXmxl1blr4SFbx6NKN8TT5lJVtRHexcuEsOyaMWcs6M7zh4LrsNp3+JUEEUAya6OB13dXH4JjoVEZ0yofqS/ay/xaEy0cpw3uMJtFeVNlBuuLaROtjw/n85b19fne51FQNP6iqjGuepiSlddjHDVxeSuuhjnKsbv6Kz8dO4qFO53WQk2rdtKrdtgFVRxnjw5RUG4dcyFO9/CZcA27NYYtNobjsB4AKXTKnpRUYBXwm3fyduSWT2YMcWWdFCVBvBXr//Svld0qLwc95beXxvX8xJ8d12QmiXhpbAjav5Fs4RGDG6E/iOrvuRO3ONo5X5+bNtv/fz1jvYzsDlysRCc3p+ga1zJxG4mg9ZLdzygmyCvopE0CTNeNengZXlP+xngd+Fec53gGVixVEzuXoVIQ006JxhFPZdFUZKJc5EcaaB0/fmqgiXJQFvAaDeXLJV3AXSj0CWr9xhboXh3Eye7bT/2DtvgueBOpFpAr8bQXKERf91M4avjI5XuCEBBRfFqcisMu4GqJHiiq72Yd5o2y3mdbdtNdwbMlqUhvLVX5tEdkS9/vSkFvIrgBi266iqDjSh6t6zUUozwGb0nY/bYrXB1dKu3bQ3REhq9uhrsXmhvYYGOp3TpIZc+6Xp7n57eCKN3NxKaA9Ru2mNbOYU8TdN2GrkSLi1fJSm/H5zHVjIiw8p7fDKhOkkfMgCpAOlWpOJS6MewLmRxuOV4t7N/NT5czr4hKjEwqZfMKq1xfbUp5WBb0u/IHGtNuSrOi/vRO9956A2RoVcJ9phc7ktWl/QRbZnSsRVI1Me/eWHQlqSNcdmgQTmyu8msuUZ2B9lokxrvT3QUgHoM+ebdu0Eb6fT5ZDaGU/n7jRvizdj3qQNMiky4JuBZePWNyoZYgHDh0nmli+e64emgUpi5RGo0+B64zdU/XCvrsz0+pcIAiqlqqa2NSf9t+ggJ3tdBhJ72UuLGNXUje/rn3xpG0KxsRyYPsz+4fzNsos5HG7jHiBua8OlwTzi90VGBH2TJjlCab+63JFzUNnFrVbcyP6TBHmXm8VrsbyTvKZosrPlXBfVIxtSjrSC9B734rqYf4stY9b/aeyzbF/ZdZD4D1c84nHT/OQC7FAWN"
 }
@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package systemd
+
+import (
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// accounting maps the resource accounting properties of the units to the
+// fields of the events. Memory, CPU, IO and tasks accounting are read by
+// systemd from the cgroup of the unit, IO accounting requires cgroup v2.
+var accounting = map[string]string{
+	"MemoryCurrent":     "memory.current.bytes",
+	"MemoryPeak":        "memory.peak.bytes",
+	"MemorySwapCurrent": "memory.swap.bytes",
+	"MemoryMax":         "memory.max.bytes",
+	"CPUUsageNSec":      "cpu.usage.ns",
+	"IOReadBytes":       "io.read.bytes",
+	"IOReadOperations":  "io.read.ops",
+	"IOWriteBytes":      "io.write.bytes",
+	"IOWriteOperations": "io.write.ops",
+	"TasksCurrent":      "tasks.current",
+	"TasksMax":          "tasks.max",
+}
+
+// unitEvent builds the fields of the event of a unit, without the restarts.
+func unitEvent(unit dbus.UnitStatus, props properties) mapstr.M {
+	event := mapstr.M{
+		"name":       unit.Name,
+		"load_state": unit.LoadState,
+		"state":      unit.ActiveState,
+		"sub_state":  unit.SubState,
+	}
+	if result := props.string("Result"); result != "" {
+		event["result"] = result
+	}
+	if pid, ok := props.uint("ExecMainPID"); ok && pid > 0 {
+		event["main_pid"] = pid
+	}
+	if ts, ok := props.uint("ActiveEnterTimestamp"); ok && ts > 0 {
+		event["active_since"] = time.UnixMicro(int64(ts)).UTC()
+	}
+	if ts, ok := props.uint("StateChangeTimestamp"); ok && ts > 0 {
+		event["state_since"] = time.UnixMicro(int64(ts)).UTC()
+	}
+
+	for prop, field := range accounting {
+		if v, ok := props.uint(prop); ok {
+			_, _ = event.Put(field, v)
+		}
+	}
+	return event
+}
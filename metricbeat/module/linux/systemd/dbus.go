@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package systemd
+
+import (
+	"context"
+	"math"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// conn is the subset of the systemd D-Bus API used by the metricset, it is
+// implemented by *dbus.Conn.
+type conn interface {
+	ListUnitsByPatternsContext(ctx context.Context, states, patterns []string) ([]dbus.UnitStatus, error)
+	GetAllPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error)
+	Close()
+}
+
+// newConn connects to the systemd manager. It is replaced in tests.
+var newConn = func(ctx context.Context) (conn, error) {
+	return dbus.NewWithContext(ctx)
+}
+
+// properties are the properties of a unit, as returned by GetAllProperties.
+type properties map[string]interface{}
+
+// uint reads an unsigned property. systemd reports unset and unavailable
+// values, like the accounting of units without their accounting enabled, as
+// the maximum of the type.
+func (p properties) uint(name string) (uint64, bool) {
+	switch v := p[name].(type) {
+	case uint64:
+		return v, v != math.MaxUint64
+	case uint32:
+		return uint64(v), v != math.MaxUint32
+	default:
+		return 0, false
+	}
+}
+
+func (p properties) string(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package systemd
+
+import (
+	"time"
+)
+
+// restartTracker keeps the restarts of the units detected in the fetches
+// within a time window.
+type restartTracker struct {
+	window time.Duration
+	units  map[string]*unitRestarts
+}
+
+type unitRestarts struct {
+	count       uint64 // NRestarts of the last fetch
+	activeEnter uint64 // ActiveEnterTimestamp of the last fetch
+	recent      []restartSample
+}
+
+type restartSample struct {
+	time     time.Time
+	restarts uint64
+}
+
+func newRestartTracker(window time.Duration) *restartTracker {
+	return &restartTracker{
+		window: window,
+		units:  map[string]*unitRestarts{},
+	}
+}
+
+// update records the restart counter and the last activation time of a unit
+// and returns the number of restarts since the previous fetch and within the
+// window.
+//
+// NRestarts only counts the automatic restarts done by systemd, so a new
+// activation time is also counted as a restart, to detect the restarts done
+// by users and by other services. A counter lower than in the previous fetch
+// means it was reset, all its restarts are then new ones. Nothing is counted
+// the first time a unit is seen.
+func (t *restartTracker) update(name string, now time.Time, count, activeEnter uint64) (newRestarts, recent uint64) {
+	u, found := t.units[name]
+	if !found {
+		t.units[name] = &unitRestarts{count: count, activeEnter: activeEnter}
+		return 0, 0
+	}
+
+	if count >= u.count {
+		newRestarts = count - u.count
+	} else {
+		newRestarts = count
+	}
+	if newRestarts == 0 && activeEnter != 0 && activeEnter != u.activeEnter {
+		newRestarts = 1
+	}
+	u.count, u.activeEnter = count, activeEnter
+
+	if newRestarts > 0 {
+		u.recent = append(u.recent, restartSample{time: now, restarts: newRestarts})
+	}
+	i := 0
+	for i < len(u.recent) && now.Sub(u.recent[i].time) >= t.window {
+		i++
+	}
+	u.recent = u.recent[i:]
+
+	for _, s := range u.recent {
+		recent += s.restarts
+	}
+	return newRestarts, recent
+}
+
+// retain forgets the units not seen in the last fetch.
+func (t *restartTracker) retain(seen map[string]struct{}) {
+	for name := range t.units {
+		if _, found := seen[name]; !found {
+			delete(t.units, name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package systemd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartTracker(t *testing.T) {
+	tracker := newRestartTracker(10 * time.Minute)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+
+	type fetch struct {
+		at                  time.Time
+		count, activeEnter  uint64
+		newRestarts, recent uint64
+	}
+	for i, f := range []fetch{
+		// First fetch, the restarts before the metricset started are not new.
+		{at: at(0), count: 5, activeEnter: 100, newRestarts: 0, recent: 0},
+		{at: at(1), count: 5, activeEnter: 100, newRestarts: 0, recent: 0},
+		// Automatic restarts.
+		{at: at(2), count: 7, activeEnter: 200, newRestarts: 2, recent: 2},
+		// Restart by a user, NRestarts doesn't change.
+		{at: at(3), count: 7, activeEnter: 300, newRestarts: 1, recent: 3},
+		// Counter reset.
+		{at: at(4), count: 1, activeEnter: 400, newRestarts: 1, recent: 4},
+		// The restarts of the minute 2 leave the window.
+		{at: at(12), count: 1, activeEnter: 400, newRestarts: 0, recent: 2},
+		{at: at(14), count: 1, activeEnter: 400, newRestarts: 0, recent: 0},
+	} {
+		newRestarts, recent := tracker.update("app.service", f.at, f.count, f.activeEnter)
+		assert.Equal(t, f.newRestarts, newRestarts, "new restarts of fetch %d", i)
+		assert.Equal(t, f.recent, recent, "recent restarts of fetch %d", i)
+	}
+
+	tracker.update("other.service", at(15), 0, 0)
+	tracker.retain(map[string]struct{}{"other.service": {}})
+	assert.NotContains(t, tracker.units, "app.service")
+	assert.Contains(t, tracker.units, "other.service")
+}
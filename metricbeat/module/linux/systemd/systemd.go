@@ -0,0 +1,157 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// init registers the MetricSet with the central registry as soon as the program
+// starts. The New function will be called later to instantiate an instance of
+// the MetricSet for each host defined in the module's configuration. After the
+// MetricSet has been created then Fetch will begin to be called periodically.
+func init() {
+	mb.Registry.MustAddMetricSet("linux", "systemd", New)
+}
+
+type config struct {
+	Units             []string      `config:"systemd.units"`
+	RestartWindow     time.Duration `config:"systemd.restart_window"`
+	FlappingThreshold uint64        `config:"systemd.flapping_threshold"`
+}
+
+func defaultConfig() config {
+	return config{
+		Units:             []string{"*.service"},
+		RestartWindow:     15 * time.Minute,
+		FlappingThreshold: 3,
+	}
+}
+
+// Validate validates the configuration of the metricset.
+func (c *config) Validate() error {
+	if c.RestartWindow <= 0 {
+		return errors.New("systemd.restart_window must be greater than 0")
+	}
+	if c.FlappingThreshold == 0 {
+		return errors.New("systemd.flapping_threshold must be greater than 0")
+	}
+	return nil
+}
+
+// MetricSet holds any configuration or state information. It must implement
+// the mb.MetricSet interface. And this is best achieved by embedding
+// mb.BaseMetricSet because it implements all of the required mb.MetricSet
+// interface methods except for Fetch.
+type MetricSet struct {
+	mb.BaseMetricSet
+	config   config
+	conn     conn
+	restarts *restartTracker
+	now      func() time.Time
+}
+
+// New creates a new instance of the MetricSet. New is responsible for unpacking
+// any MetricSet specific configuration options if there are any.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The linux systemd metricset is beta."))
+
+	config := defaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	conn, err := newConn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to dbus: %w", err)
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		config:        config,
+		conn:          conn,
+		restarts:      newRestartTracker(config.RestartWindow),
+		now:           time.Now,
+	}, nil
+}
+
+// Fetch reports an event for each loaded unit matching the configured
+// patterns.
+func (m *MetricSet) Fetch(ctx context.Context, report mb.ReporterV2) error {
+	units, err := m.conn.ListUnitsByPatternsContext(ctx, nil, m.config.Units)
+	if err != nil {
+		return fmt.Errorf("error listing units: %w", err)
+	}
+
+	now := m.now()
+	seen := make(map[string]struct{}, len(units))
+	for _, unit := range units {
+		// Units referenced by others but not installed are listed too.
+		if unit.LoadState == "not-found" {
+			continue
+		}
+
+		raw, err := m.conn.GetAllPropertiesContext(ctx, unit.Name)
+		if err != nil {
+			m.Logger().Errorf("error getting properties of unit %s: %s", unit.Name, err)
+			continue
+		}
+		seen[unit.Name] = struct{}{}
+
+		props := properties(raw)
+		event := unitEvent(unit, props)
+
+		// NRestarts is only available for services since systemd 235.
+		count, _ := props.uint("NRestarts")
+		activeEnter, _ := props.uint("ActiveEnterTimestamp")
+		newRestarts, recent := m.restarts.update(unit.Name, now, count, activeEnter)
+		event["restarts"] = mapstr.M{
+			"count": count,
+			"new":   newRestarts,
+			"window": mapstr.M{
+				"count": recent,
+			},
+			"rate": mapstr.M{
+				"per_min": float64(recent) / m.config.RestartWindow.Minutes(),
+			},
+		}
+		event["flapping"] = recent >= m.config.FlappingThreshold
+
+		if !report.Event(mb.Event{MetricSetFields: event}) {
+			return nil
+		}
+	}
+	m.restarts.retain(seen)
+
+	return nil
+}
+
+// Close closes the connection to D-Bus.
+func (m *MetricSet) Close() error {
+	m.conn.Close()
+	return nil
+}
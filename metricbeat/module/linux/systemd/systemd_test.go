@@ -0,0 +1,190 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// fakeConn serves the units and properties of a fake systemd manager.
+type fakeConn struct {
+	patterns []string
+	units    []dbus.UnitStatus
+	props    map[string]map[string]interface{}
+	closed   bool
+}
+
+func (c *fakeConn) ListUnitsByPatternsContext(_ context.Context, _, patterns []string) ([]dbus.UnitStatus, error) {
+	c.patterns = patterns
+	return c.units, nil
+}
+
+func (c *fakeConn) GetAllPropertiesContext(_ context.Context, unit string) (map[string]interface{}, error) {
+	props, found := c.props[unit]
+	if !found {
+		return nil, errors.New("unit not found")
+	}
+	return props, nil
+}
+
+func (c *fakeConn) Close() {
+	c.closed = true
+}
+
+func newTestMetricSet(t *testing.T, fake *fakeConn) *MetricSet {
+	t.Helper()
+
+	origNewConn := newConn
+	newConn = func(context.Context) (conn, error) { return fake, nil }
+	t.Cleanup(func() { newConn = origNewConn })
+
+	f := mbtest.NewReportingMetricSetV2WithContext(t, map[string]interface{}{
+		"module":                     "linux",
+		"metricsets":                 []string{"systemd"},
+		"systemd.units":              []string{"app.service", "web*.service"},
+		"systemd.restart_window":     "10m",
+		"systemd.flapping_threshold": 2,
+	})
+	ms, ok := f.(*MetricSet)
+	require.True(t, ok)
+	return ms
+}
+
+func TestFetch(t *testing.T) {
+	activeEnter := uint64(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC).UnixMicro())
+	fake := &fakeConn{
+		units: []dbus.UnitStatus{
+			{Name: "app.service", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "web.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+			{Name: "webhook.service", LoadState: "not-found", ActiveState: "inactive", SubState: "dead"},
+		},
+		props: map[string]map[string]interface{}{
+			"app.service": {
+				"Result":               "success",
+				"ExecMainPID":          uint32(1234),
+				"NRestarts":            uint32(4),
+				"ActiveEnterTimestamp": activeEnter,
+				"StateChangeTimestamp": activeEnter,
+				"MemoryCurrent":        uint64(52428800),
+				"MemoryPeak":           uint64(62914560),
+				"MemoryMax":            uint64(math.MaxUint64),
+				"CPUUsageNSec":         uint64(1500000000),
+				"IOReadBytes":          uint64(4096),
+				"IOWriteBytes":         uint64(8192),
+				"TasksCurrent":         uint64(12),
+				"TasksMax":             uint64(4915),
+			},
+			"web.service": {
+				"Result":        "exit-code",
+				"ExecMainPID":   uint32(0),
+				"NRestarts":     uint32(3),
+				"MemoryCurrent": uint64(math.MaxUint64),
+				"CPUUsageNSec":  uint64(math.MaxUint64),
+			},
+		},
+	}
+	ms := newTestMetricSet(t, fake)
+	now := time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC)
+	ms.now = func() time.Time { return now }
+
+	events, errs := mbtest.ReportingFetchV2WithContext(ms)
+	require.Empty(t, errs)
+	require.Len(t, events, 2, "units not found are skipped")
+	assert.Equal(t, []string{"app.service", "web*.service"}, fake.patterns)
+
+	app := events[0].MetricSetFields
+	assert.Equal(t, mapstr.M{
+		"name":         "app.service",
+		"load_state":   "loaded",
+		"state":        "active",
+		"sub_state":    "running",
+		"result":       "success",
+		"main_pid":     uint64(1234),
+		"active_since": time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		"state_since":  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		"memory": mapstr.M{
+			"current": mapstr.M{"bytes": uint64(52428800)},
+			"peak":    mapstr.M{"bytes": uint64(62914560)},
+		},
+		"cpu": mapstr.M{"usage": mapstr.M{"ns": uint64(1500000000)}},
+		"io": mapstr.M{
+			"read":  mapstr.M{"bytes": uint64(4096)},
+			"write": mapstr.M{"bytes": uint64(8192)},
+		},
+		"tasks": mapstr.M{"current": uint64(12), "max": uint64(4915)},
+		"restarts": mapstr.M{
+			"count":  uint64(4),
+			"new":    uint64(0),
+			"window": mapstr.M{"count": uint64(0)},
+			"rate":   mapstr.M{"per_min": float64(0)},
+		},
+		"flapping": false,
+	}, app)
+
+	web := events[1].MetricSetFields
+	assert.Equal(t, "failed", web["state"])
+	assert.NotContains(t, web, "main_pid")
+	assert.NotContains(t, web, "memory")
+	assert.NotContains(t, web, "cpu")
+
+	// web.service restarts twice, and gets flapping.
+	fake.props["web.service"]["NRestarts"] = uint32(5)
+	now = now.Add(time.Minute)
+
+	events, errs = mbtest.ReportingFetchV2WithContext(ms)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+	restarts, err := events[1].MetricSetFields.GetValue("restarts")
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{
+		"count":  uint64(5),
+		"new":    uint64(2),
+		"window": mapstr.M{"count": uint64(2)},
+		"rate":   mapstr.M{"per_min": 0.2},
+	}, restarts)
+	assert.Equal(t, true, events[1].MetricSetFields["flapping"])
+	assert.Equal(t, false, events[0].MetricSetFields["flapping"])
+
+	require.NoError(t, ms.Close())
+	assert.True(t, fake.closed)
+}
+
+func TestConfigValidation(t *testing.T) {
+	c := defaultConfig()
+	assert.NoError(t, c.Validate())
+
+	c.RestartWindow = 0
+	assert.Error(t, c.Validate())
+
+	c = defaultConfig()
+	c.FlappingThreshold = 0
+	assert.Error(t, c.Validate())
+}
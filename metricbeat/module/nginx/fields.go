@@ -32,5 +32,5 @@ func init() {
 // AssetNginx returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/nginx.
 func AssetNginx() string {
-	return "eJzElM9u2zAMxu9+ig8+r3kAH/YIOw3YYRhSVaJtIYrkkXTSvP0gR26cwC1SoMF0skWJ349/xCfs6NQgdj6+VoB6DdSg/pH/6wpwJJb9oD7FBt8rAJhsEOIDMUSNjoI9KXsrsCkEskoOLac9DoZ9yubkxkCyqQDpE+vWptj6rkFrglAFMAUyQg06k8+Qqo+dNPhdi4T6G+pedaj/VEDrKThpJpInRLOnC31eehqyG07jUHZWQsjrebr1DJuiGh8F2tNbHNobxZGYIJbNMMczXdkUF0uSJY3o+HJOy5tpDesDtAnv4uZzjPlI7F63OWPb7GR79rI9F2Gu2GA62iwUryswr9sYl3H2STR/XRnnSHd0OiZ2N7YP4r101ux3s6pqrPrDumZIsfuc4M+eYEdmioo47l+IkdoiARt83rcpRrLZg8BHG0bnY4dfxucWXVrf47U0qHwhsCY14Qo3K5BbAV5H6k10gW5r85VIReFuIsdpGB5KVBTuJmL6O5I8tG4FZVZa5yjd+dB+vwuEyeTGfyzI4qkd+zzZzhPByyw/TbcCip6MI17HPfL0Pv8XbpEvuDKkKIQXY3fQNG2ec/4Ou3k8u3dhdcIVbbSJYeZMb6p/AQAA//9pr1BQ"
+	return "eJzsnd+P47bxwN/9VxD3+MXGQHJ3L/vwBYI0aFIE2yB7SAoUhUNLY4tYilRJyrt7f30xFCnJtn6QXkv2XYweCsT2aT5DDjnDGWruG/IEr/dEbJl4WRBimOFwT9494H+/WxCSgk4UKwyT4p78/4IQQux3RIPagSLaUFNqkoNRLNEkkZxDYiAlGyVzsqOKSfxapiUHvVwQojOpzCqRYsO292RDuYYFIQo4UA33ZEvxN2AME1t9T/79Tmv+7o68y4wp3v1nQciGAU/1vSX5hgiaQ0OP/zOvBT5GybJwn3SogH/+tH/rT5JIYSgTmpgMaj1MRg15BgVEJ4oWXh/7V5buEW2SNk3BS11/2AU0AIV//sQHjHIdjDTCP/z954d/kV95qclvPz5+It//+rNnJaQ1xmswtPX5oR5tXXagNJNi7zuv0hO8PkuVHnw3oBj++b16IJGb/cHcF7suGU/PJ/SB5oASDwbJSukG2IIARU2f6lyKbRzCpwyIkYZyIsp8DQpxqkVQVnKIAi5pqrt58KuVYTloQ/OikymlBiKZWDMsnGrjEPxHe3jdWAVLzzg+P//Ni36W6gkUKZRMQDurz6hIOaR2ObhdR8F/S9Cmmy2RQkCC8nQn4+GKDID8gTMQpv3kfdHda6kNRZMECgOHozY4cgFgfRbmxZFklLxhTJUsitkQnbQoQpoYtoOJAJNSKSRpjyKKiyJkKZ+PD4WN0nkyrfm5lsPj4y/W/zNt0Pe7tVsFBrELA1e3zugT6ImG7dDudJng3rIpudWjkb8MYFxtKOOzLZFKWDCmBo0udqWg1PMNZyWUVEJJWiomtoPIHtdt4vpcVuk26Z8+ffq1fnasNVpjmWnk3MLtQ22g3MKfCOt4WxkE81DVal99lgLONYGPe1sK9ScNFBE7j/j/vePVHUoGDlk7pBwAbFBcLMPEdqIJ7J24Knyiqp5j/krWgMvTMUHaj+0fMhH0yGIgChJgO3/Iqb4dWCIKdCHF0K7XZY8nDXItimhcNkZ6ujuyfvXH4kSmHSbRb7dtXb59eVl0fD028IHKDCn0zExGvn15aWuhl4Ow310W9rso2PeXhX0fBfvhsrAfomA/Xhb2YxRsn5M/N+7hHte7cywXfagp0wlV6WwRZ73/JjIvOGCWCTcFWRrc7VL0HbTWox/b797L9auJj0Q3UuXU3JO+v3yibvZxsY4FZ+oKtQg2oONT31lcYvcJECO1O/KcgSDMEHseeHz85VQ/2JwfOn82MvyBipzhlBh7UpwaPfDUGHVynHy4Tz5FloU2Cmi+CLXwyENIAS4XJGpRPu6362e5CLPseU8mQagN1BNAQfmcqTWbuqrFtrNXXoUR5M8yXzPQswFXc66JglzW7qPmJGv0kIZx7s9W1lXu5Q+9b+3XCW1tit0ap8DbcYR1hG3TR5WAoHkIJD+uEHRnGfeRqt/0PHBspUWS0TRVWKqIwOvZAiaAE8fJCl1AwjYMUsJE+/OUKUwf72CYfU2Tp7IYpF9LyYGKRedPQun/yMBkoNqETBPq5AcN8zOwbWYmNs4/rJCY2cewaYbp/8HtYFYcBmUsyUhOMQNEpLDlv7K4I6miTDCxvSOpfBZ3pBR0Rxm/I0kGyZP9goqUlCIDyk32Oqyb+9GlzKMshvF6y0fntIjhUlJfleYQNacveFfiqIQ5BW0ti3CWM0M2sj2ww5zeqc2AOZYy3Ej1bM/KeDAyGdOBCrhDeY/sYecbqUIDX4slco23UJqIouYOySeOOemwvGLQTEWoeqYcY1iecXb476Lh318P/Pto+A/XA/8hGv7j9cB/jIYfylNOhX+4yQbsU8vFkBKDCbQgLcYSaW90I/sJtWC9RlOc16PbfsozWEPMI52s2BugS9FKvlFjIC+MxrlJZJ6XgiXUQJX2Dw1SXFA7sS4/yWeSU/FK7HW9FhxZQ4IHMYdB1xxw4o8CGPxZqW1oroAmGaYQjIvP7GQQkynQmTy8vHiobxWGr2wIP3lc870Nqp3M6tgQdBoOD1wG9QiawQh1+qyyrV8zZTntj8vCVtJU/Ef54L0JGmeuT3wzcgesIAc1zo/3WlcF1RrSUQ2Gj6SRSrSPpgjRaTnkmep2jYGKlFSwxHTnCNu6ZUBTUPZa8DLXixMnJ1AnNCa6A0W3YOcFd+ItGDs7PjpwRN7B+Gm7wxxTzjhnGhIpDu84H6rln3YFim1KzmueN6qFiZWZNLKeFGW1aK2puVzfWHbHHspAn6CgNlQN59o6rqnHqpe5ibIVxq7d4Ug7qcb2DK+Ehur9lUvpgLNktwsPguvM1RII9fvGiA6aD+Kfwdt3F37b5RsjW2qFVYNjgoGmHtj704AFFaFxX0DQ2r1DSq2nVInnUsVFCLFqBFWNZ5uOkyvICU0yWISul8jysX34FV9h7edrSDT7DL3H3MG5HTveRlZjEWQPvJ8Zz0wX567S6VL4UxzLy/xYieOS3MALWG0dE8nTXs36Y9oA+HYMWzHie2itF7MwhWCr3fg5nlNTamgVJ6VMPxEmjAyZpoyZRay3CuD/nXKW1vEbZkBonf3oZRr2QZ64fmjnr0bsKhC/b4+rZS8HEfusNghvzPTfqIJ97HLRh64N5TCFRfz4UjAFN5v4Am2iLFJqhl6fmNossHjOm8Ourt4Pr96ksHCQ3iznGi1HwQ4dAR167/YMxoPJm5aoYWO6Wco1WkrOtJ7CRH7zI0eExIsVpWiCvJs1XJs1dI7w6lkxY0Ccqso5Rpo4Bp9c6bWdg+F/K/sc0xCom9cLqk13isV6HAzgojX0CcRtE7/iTfy2bK9/2a5fsbw1xardd7FcyidISVnc3OzNzd7cbLCb9fpoU66xvvPGTmbNY+L6rCGZ2L6ssOvbCh+yqp6yqhrJ+Qt0Bd1CZ4ezbWh/s0xq05FhH8quj8zAA3Y1q5+77JTaeQG91zxGBEb2LCJMJLy0r/D+QRkmVNrf9vFiGyl9RuCTG1V5JCxQHVfMzonkJAQTdbfNOidRaKssT+SKxVPOm0Pxkro5nHWeEePY3oNAMNd7nEE8M0gzK1j0VuD6ZbIq1ewv8zlQd2OmGxdDqwviOvEO192BwRevat9hx7yHnU7P3tP3zMvGsKbryoRH3Jm3ObedOcGr/ePxnw/efbnqn+2DSXZMmZJy6zmIUXSzYYn/YeX1Oj1dRC/Py/i6C3UQrYZsNaV0nPG4WZujn6e9aGMHxt74sheyICVSue6ekHYTtRbQwYO7V0Z4J7bWk/dFd5vraKA0uJEEYL0taPKby4BWYw5nOgXayNVuLmKdz7gDugj+Sc5o3CHN3pRzzDkdYvvAeCLuk+Pw8Vh8KsTQuLxN2BMJT4Xo0LzU5aKLSWdUQXrWzonIUj2W5JBL9WpvnGEHnhRfIsW10+WUxvfhjrBhzI+ecAntGH3ZS/RlXOyK0Qhn6fIq+Y3sTVoImUIsfyRfs9hQGDpoNHOf7R0jvlD30tZmgd9xXrfL0ti3QEEhFd4coLr+woKm5P+udMWGdD697OYb2+nN//yiqzDkBc6vvmedD/P68buWZwRfQ1aLOqS7NXO9NXO9NXO90mauQ29uzL5JuDv8FvjUXaLn+tkcQx5+Ly2gmD8XcHiVP+Ti0HTUTuaJN4rGr8XPSR54lzXo3vaFuU+80N2oNnyxeHrt3nzjeOwNnOlU2J32ak4DjA2xk3kXQ0OLm49lTNvdOijhmJBs98+Wyh1Cl4s+RdxvB952H9QmQJP2G+7uzS00cdP657888Mgr357Zdw5dhDrcyEPzX6Lb7hfZ5vXKe6p6O55hXxjLO5zah/Br7yf19XYCc96hR9jwuo5k7nJKtw6Stw6Stw6Stw6SVQfJA494gWZKAaGmv1RhfDscXeC2u36t/nHgyAZEfpyuoHGUR/F7sY9eIjW60t7ldSfCicn2l0F3Q0i6MaBcX/OGH8tpiRSapYAHZdcSClsuDmuGWlnzkaVZakhmUBCl+T41jRo9yuI/4idLYSCtWnZtZIzu1vqCDO8L6u+PfdUuBZpTha8ZUW2bny0XXXw2TzBpiflraC70V2jUc703PHrybcGH0jm4ww6ho7pceVU8aDZcOLiIPWQGEjZstahbietW4rqVuG4lrluJ61bi6i9x/W8ASD1OHw=="
 }
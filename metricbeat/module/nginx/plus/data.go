@@ -0,0 +1,182 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plus
+
+import (
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstriface"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var (
+	nginxSchema = s.Schema{
+		"version":        c.Str("version"),
+		"build":          c.Str("build"),
+		"generation":     c.Int("generation"),
+		"load_timestamp": c.Str("load_timestamp"),
+		"pid":            c.Int("pid"),
+	}
+
+	connectionsSchema = s.Schema{
+		"accepted": c.Int("accepted"),
+		"dropped":  c.Int("dropped"),
+		"active":   c.Int("active"),
+		"idle":     c.Int("idle"),
+	}
+
+	sslSchema = s.Schema{
+		"handshakes":        c.Int("handshakes"),
+		"handshakes_failed": c.Int("handshakes_failed"),
+		"session_reuses":    c.Int("session_reuses"),
+	}
+
+	requestsSchema = s.Schema{
+		"total":   c.Int("total"),
+		"current": c.Int("current"),
+	}
+
+	responsesSchema = s.Schema{
+		"1xx":   c.Int("1xx"),
+		"2xx":   c.Int("2xx"),
+		"3xx":   c.Int("3xx"),
+		"4xx":   c.Int("4xx"),
+		"5xx":   c.Int("5xx"),
+		"total": c.Int("total"),
+	}
+
+	serverZoneSchema = s.Schema{
+		"processing": c.Int("processing"),
+		"requests":   c.Int("requests"),
+		"responses":  c.Dict("responses", responsesSchema),
+		"discarded":  c.Int("discarded", s.Optional),
+		"received":   s.Object{"bytes": c.Int("received")},
+		"sent":       s.Object{"bytes": c.Int("sent")},
+		"ssl":        c.Dict("ssl", sslSchema, c.DictOptional),
+	}
+
+	peerSchema = s.Schema{
+		"id":        c.Int("id"),
+		"server":    c.Str("server"),
+		"name":      c.Str("name"),
+		"backup":    c.Bool("backup"),
+		"weight":    c.Int("weight"),
+		"state":     c.Str("state"),
+		"active":    c.Int("active"),
+		"max_conns": c.Int("max_conns", s.Optional),
+		"requests":  c.Int("requests"),
+		"responses": c.Dict("responses", responsesSchema),
+		"sent":      s.Object{"bytes": c.Int("sent")},
+		"received":  s.Object{"bytes": c.Int("received")},
+		"fails":     c.Int("fails"),
+		"unavail":   c.Int("unavail"),
+		"health_checks": c.Dict("health_checks", s.Schema{
+			"checks":      c.Int("checks"),
+			"fails":       c.Int("fails"),
+			"unhealthy":   c.Int("unhealthy"),
+			"last_passed": c.Bool("last_passed", s.Optional),
+		}, c.DictOptional),
+		"header_time":   s.Object{"ms": c.Int("header_time", s.Optional)},
+		"response_time": s.Object{"ms": c.Int("response_time", s.Optional)},
+		"downtime":      s.Object{"ms": c.Int("downtime")},
+		"downstart":     c.Str("downstart", s.Optional),
+		"selected":      c.Str("selected", s.Optional),
+		"ssl":           c.Dict("ssl", sslSchema, c.DictOptional),
+	}
+
+	cacheCounterSchema = s.Schema{
+		"responses":         c.Int("responses"),
+		"bytes":             c.Int("bytes"),
+		"responses_written": c.Int("responses_written", s.Optional),
+		"bytes_written":     c.Int("bytes_written", s.Optional),
+	}
+
+	cacheSchema = s.Schema{
+		"size":        s.Object{"bytes": c.Int("size")},
+		"max_size":    s.Object{"bytes": c.Int("max_size", s.Optional)},
+		"cold":        c.Bool("cold"),
+		"hit":         c.Dict("hit", cacheCounterSchema),
+		"stale":       c.Dict("stale", cacheCounterSchema),
+		"updating":    c.Dict("updating", cacheCounterSchema),
+		"revalidated": c.Dict("revalidated", cacheCounterSchema),
+		"miss":        c.Dict("miss", cacheCounterSchema),
+		"expired":     c.Dict("expired", cacheCounterSchema),
+		"bypass":      c.Dict("bypass", cacheCounterSchema),
+	}
+)
+
+// serverZoneEvents returns an event for each server zone.
+func serverZoneEvents(zones map[string]interface{}) []mapstr.M {
+	var events []mapstr.M
+	for _, name := range sortedKeys(zones) {
+		zone, ok := zones[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := serverZoneSchema.Apply(zone)
+		data["name"] = name
+		events = append(events, mapstr.M{"server_zone": data})
+	}
+	return events
+}
+
+// upstreamPeerEvents returns an event for each peer of each upstream.
+func upstreamPeerEvents(upstreams map[string]interface{}) []mapstr.M {
+	var events []mapstr.M
+	for _, name := range sortedKeys(upstreams) {
+		upstream, ok := upstreams[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		peers, _ := upstream["peers"].([]interface{})
+		for _, p := range peers {
+			peer, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			data, _ := peerSchema.Apply(peer)
+			data["healthy"] = peer["state"] == "up"
+
+			event := mapstr.M{
+				"name": name,
+				"peer": data,
+			}
+			for _, key := range []string{"keepalive", "zombies"} {
+				if v, ok := upstream[key].(float64); ok {
+					event[key] = int64(v)
+				}
+			}
+			events = append(events, mapstr.M{"upstream": event})
+		}
+	}
+	return events
+}
+
+// cacheEvents returns an event for each cache zone.
+func cacheEvents(caches map[string]interface{}) []mapstr.M {
+	var events []mapstr.M
+	for _, name := range sortedKeys(caches) {
+		cache, ok := caches[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := cacheSchema.Apply(cache)
+		data["name"] = name
+		events = append(events, mapstr.M{"cache": data})
+	}
+	return events
+}
@@ -0,0 +1,173 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package plus collects metrics from the NGINX Plus REST API, provided by
+// the ngx_http_api_module.
+package plus
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	"github.com/elastic/beats/v7/metricbeat/helper"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/mb/parse"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const (
+	// defaultScheme is the default scheme to use when it is not specified in
+	// the host config.
+	defaultScheme = "http"
+
+	// defaultPath is the default path to the ngx_http_api_module endpoint on Nginx.
+	defaultPath = "/api"
+
+	// defaultAPIVersion is the version of the API used by default, available
+	// since NGINX Plus R30.
+	defaultAPIVersion = 9
+)
+
+var (
+	hostParser = parse.URLHostParserBuilder{
+		DefaultScheme: defaultScheme,
+		PathConfigKey: "api_path",
+		DefaultPath:   defaultPath,
+	}.Build()
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet("nginx", "plus", New,
+		mb.WithHostParser(hostParser),
+	)
+}
+
+type config struct {
+	APIVersion int `config:"api_version" validate:"min=1"`
+}
+
+// MetricSet for fetching NGINX Plus metrics.
+type MetricSet struct {
+	mb.BaseMetricSet
+	http    *helper.HTTP
+	baseURI string
+	version int
+}
+
+// New creates new instance of MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The nginx plus metricset is beta."))
+
+	config := config{APIVersion: defaultAPIVersion}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	http, err := helper.NewHTTP(base)
+	if err != nil {
+		return nil, err
+	}
+	http.SetHeader("Accept", "application/json")
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		http:          http,
+		baseURI:       http.GetURI(),
+		version:       config.APIVersion,
+	}, nil
+}
+
+// Fetch reports an event with the global status of the server, and events
+// for each server zone, upstream peer and cache zone.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	event := mapstr.M{}
+	for _, endpoint := range []struct {
+		path   string
+		field  string
+		schema s.Schema
+	}{
+		{"/nginx", "", nginxSchema},
+		{"/connections", "connections", connectionsSchema},
+		{"/ssl", "ssl", sslSchema},
+		{"/http/requests", "requests", requestsSchema},
+	} {
+		data, err := m.get(endpoint.path)
+		if err != nil {
+			return err
+		}
+		fields, err := endpoint.schema.Apply(data)
+		if err != nil {
+			return fmt.Errorf("error mapping %s: %w", endpoint.path, err)
+		}
+		if endpoint.field == "" {
+			event.DeepUpdate(fields)
+		} else {
+			event[endpoint.field] = fields
+		}
+	}
+	if !reporter.Event(mb.Event{MetricSetFields: event}) {
+		return nil
+	}
+
+	zones, err := m.get("/http/server_zones")
+	if err != nil {
+		return err
+	}
+	upstreams, err := m.get("/http/upstreams")
+	if err != nil {
+		return err
+	}
+	caches, err := m.get("/http/caches")
+	if err != nil {
+		return err
+	}
+
+	var events []mapstr.M
+	events = append(events, serverZoneEvents(zones)...)
+	events = append(events, upstreamPeerEvents(upstreams)...)
+	events = append(events, cacheEvents(caches)...)
+	for _, event := range events {
+		if !reporter.Event(mb.Event{MetricSetFields: event}) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// get fetches an endpoint of the API.
+func (m *MetricSet) get(path string) (map[string]interface{}, error) {
+	m.http.SetURI(fmt.Sprintf("%s/%d%s", m.baseURI, m.version, path))
+	data, err := m.http.FetchJSON()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// sortedKeys returns the names of the objects of an API response, to report
+// them in a stable order.
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// newServer serves the recorded responses of the NGINX Plus API under
+// /api/9.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint, found := strings.CutPrefix(r.URL.Path, "/api/9/")
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		file := "plus-" + filepath.Base(endpoint) + ".json"
+		body, err := os.ReadFile(filepath.Join("..", "_meta", "testdata", file))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newServer(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	// Global status, 2 server zones, 2 upstream peers and a cache.
+	require.Len(t, events, 6)
+
+	status := events[0].MetricSetFields
+	assert.Equal(t, "nginx-plus-r31", status["build"])
+	assert.Equal(t, mapstr.M{"accepted": int64(4968119), "dropped": int64(2), "active": int64(34), "idle": int64(52)}, status["connections"])
+	assert.Equal(t, mapstr.M{"total": int64(10624511), "current": int64(4)}, status["requests"])
+	handshakesFailed, _ := status.GetValue("ssl.handshakes_failed")
+	assert.Equal(t, int64(21025), handshakesFailed)
+
+	zone := events[1].MetricSetFields
+	name, _ := zone.GetValue("server_zone.name")
+	assert.Equal(t, "api.example.com", name)
+	errors5xx, _ := zone.GetValue("server_zone.responses.5xx")
+	assert.Equal(t, int64(266), errors5xx)
+	sent, _ := zone.GetValue("server_zone.sent.bytes")
+	assert.Equal(t, int64(19415530115), sent)
+	_, err := events[2].MetricSetFields.GetValue("server_zone.ssl")
+	assert.Error(t, err, "zones without SSL have no SSL stats")
+
+	peer, err := events[3].MetricSetFields.GetValue("upstream")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", peer.(mapstr.M)["name"])
+	assert.Equal(t, int64(0), peer.(mapstr.M)["keepalive"])
+	healthy, _ := peer.(mapstr.M).GetValue("peer.healthy")
+	assert.Equal(t, true, healthy)
+	responseTime, _ := peer.(mapstr.M).GetValue("peer.response_time.ms")
+	assert.Equal(t, int64(36), responseTime)
+
+	unhealthy, err := events[4].MetricSetFields.GetValue("upstream.peer")
+	require.NoError(t, err)
+	assert.Equal(t, false, unhealthy.(mapstr.M)["healthy"])
+	assert.Equal(t, "unhealthy", unhealthy.(mapstr.M)["state"])
+	assert.Equal(t, "2024-02-27T09:03:14.000Z", unhealthy.(mapstr.M)["downstart"])
+	assert.Equal(t, mapstr.M{"checks": int64(26284), "fails": int64(26284), "unhealthy": int64(1), "last_passed": false}, unhealthy.(mapstr.M)["health_checks"])
+
+	cache := events[5].MetricSetFields
+	hits, _ := cache.GetValue("cache.hit.responses")
+	assert.Equal(t, int64(254032), hits)
+	written, _ := cache.GetValue("cache.miss.bytes_written")
+	assert.Equal(t, int64(53841943822), written)
+}
+
+func TestFetchAPIVersion(t *testing.T) {
+	server := newServer(t)
+
+	config := getConfig(server.URL)
+	config["api_version"] = 8
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	_, errs := mbtest.ReportingFetchV2Error(f)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "HTTP error 404")
+}
+
+func TestData(t *testing.T) {
+	server := newServer(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	if err := mbtest.WriteEventsReporterV2Error(f, t, ""); err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "nginx",
+		"metricsets": []string{"plus"},
+		"hosts":      []string{host},
+	}
+}
@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vts
+
+import (
+	"sort"
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstriface"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var (
+	statusSchema = s.Schema{
+		"hostname":       c.Str("hostName"),
+		"version":        c.Str("nginxVersion"),
+		"module_version": c.Str("moduleVersion", s.Optional),
+		"connections": c.Dict("connections", s.Schema{
+			"active":   c.Int("active"),
+			"reading":  c.Int("reading"),
+			"writing":  c.Int("writing"),
+			"waiting":  c.Int("waiting"),
+			"accepted": c.Int("accepted"),
+			"handled":  c.Int("handled"),
+			"requests": c.Int("requests"),
+		}),
+		"shared_zone": c.Dict("sharedZones", s.Schema{
+			"name":      c.Str("name"),
+			"max_size":  s.Object{"bytes": c.Int("maxSize")},
+			"used_size": s.Object{"bytes": c.Int("usedSize")},
+			"used_node": c.Int("usedNode"),
+		}, c.DictOptional),
+	}
+
+	// The responses objects of the status mix the counters of the status
+	// codes and the counters of the cache statuses, they are reported apart.
+	responsesSchema = s.Schema{
+		"1xx": c.Int("1xx"),
+		"2xx": c.Int("2xx"),
+		"3xx": c.Int("3xx"),
+		"4xx": c.Int("4xx"),
+		"5xx": c.Int("5xx"),
+	}
+
+	cacheResponsesSchema = s.Schema{
+		"miss":        c.Int("miss"),
+		"bypass":      c.Int("bypass"),
+		"expired":     c.Int("expired"),
+		"stale":       c.Int("stale"),
+		"updating":    c.Int("updating"),
+		"revalidated": c.Int("revalidated"),
+		"hit":         c.Int("hit"),
+		"scarce":      c.Int("scarce", s.Optional),
+	}
+
+	serverZoneSchema = s.Schema{
+		"requests":     c.Int("requestCounter"),
+		"received":     s.Object{"bytes": c.Int("inBytes")},
+		"sent":         s.Object{"bytes": c.Int("outBytes")},
+		"responses":    c.Dict("responses", responsesSchema),
+		"cache":        c.Dict("responses", cacheResponsesSchema, c.DictOptional),
+		"request_time": s.Object{"ms": c.Int("requestMsec", s.Optional)},
+	}
+
+	peerSchema = s.Schema{
+		"server":        c.Str("server"),
+		"requests":      c.Int("requestCounter"),
+		"received":      s.Object{"bytes": c.Int("inBytes")},
+		"sent":          s.Object{"bytes": c.Int("outBytes")},
+		"responses":     c.Dict("responses", responsesSchema),
+		"request_time":  s.Object{"ms": c.Int("requestMsec", s.Optional)},
+		"response_time": s.Object{"ms": c.Int("responseMsec", s.Optional)},
+		"weight":        c.Int("weight"),
+		"max_fails":     c.Int("maxFails"),
+		"fail_timeout":  s.Object{"sec": c.Int("failTimeout")},
+		"backup":        c.Bool("backup"),
+		"down":          c.Bool("down"),
+	}
+
+	cacheZoneSchema = s.Schema{
+		"max_size":  s.Object{"bytes": c.Int("maxSize")},
+		"used_size": s.Object{"bytes": c.Int("usedSize")},
+		"received":  s.Object{"bytes": c.Int("inBytes")},
+		"sent":      s.Object{"bytes": c.Int("outBytes")},
+		"responses": c.Dict("responses", cacheResponsesSchema),
+	}
+)
+
+// eventsMapping returns an event with the global status of the server, an
+// event for each server zone, including the total of all the zones named *,
+// an event for each peer of each upstream, and an event for each cache zone.
+func eventsMapping(status map[string]interface{}) []mapstr.M {
+	event, _ := statusSchema.Apply(status)
+	if ms, ok := status["loadMsec"].(float64); ok {
+		event["load_timestamp"] = time.UnixMilli(int64(ms)).UTC()
+	}
+	events := []mapstr.M{event}
+
+	zones, _ := status["serverZones"].(map[string]interface{})
+	for _, name := range sortedKeys(zones) {
+		zone, ok := zones[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := serverZoneSchema.Apply(zone)
+		data["name"] = name
+		events = append(events, mapstr.M{"server_zone": data})
+	}
+
+	upstreams, _ := status["upstreamZones"].(map[string]interface{})
+	for _, name := range sortedKeys(upstreams) {
+		peers, _ := upstreams[name].([]interface{})
+		for _, p := range peers {
+			peer, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			data, _ := peerSchema.Apply(peer)
+			events = append(events, mapstr.M{"upstream": mapstr.M{
+				"name": name,
+				"peer": data,
+			}})
+		}
+	}
+
+	caches, _ := status["cacheZones"].(map[string]interface{})
+	for _, name := range sortedKeys(caches) {
+		cache, ok := caches[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := cacheZoneSchema.Apply(cache)
+		data["name"] = name
+		events = append(events, mapstr.M{"cache_zone": data})
+	}
+
+	return events
+}
+
+// sortedKeys returns the names of the zones, to report them in a stable
+// order.
+func sortedKeys(zones map[string]interface{}) []string {
+	keys := make([]string, 0, len(zones))
+	for key := range zones {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// newServer serves the recorded JSON status of the module.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("..", "_meta", "testdata", "vts.json"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != defaultPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newServer(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	// Global status, 2 server zones, 2 upstream peers and a cache zone.
+	require.Len(t, events, 6)
+
+	status := events[0].MetricSetFields
+	assert.Equal(t, "web-1", status["hostname"])
+	assert.Equal(t, "1.25.3", status["version"])
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 118000000, time.UTC), status["load_timestamp"])
+	active, _ := status.GetValue("connections.active")
+	assert.Equal(t, int64(12), active)
+	used, _ := status.GetValue("shared_zone.used_size.bytes")
+	assert.Equal(t, int64(15423), used)
+
+	// The total of all the zones is named *.
+	name, _ := events[1].MetricSetFields.GetValue("server_zone.name")
+	assert.Equal(t, "*", name)
+
+	zone, err := events[2].MetricSetFields.GetValue("server_zone")
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{
+		"name":         "example.com",
+		"requests":     int64(130230),
+		"received":     mapstr.M{"bytes": int64(33562061)},
+		"sent":         mapstr.M{"bytes": int64(1261822114)},
+		"responses":    mapstr.M{"1xx": int64(0), "2xx": int64(128711), "3xx": int64(1220), "4xx": int64(287), "5xx": int64(12)},
+		"cache":        mapstr.M{"miss": int64(3071), "bypass": int64(0), "expired": int64(12), "stale": int64(0), "updating": int64(0), "revalidated": int64(0), "hit": int64(20530), "scarce": int64(0)},
+		"request_time": mapstr.M{"ms": int64(21)},
+	}, zone)
+
+	peer, err := events[4].MetricSetFields.GetValue("upstream")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", peer.(mapstr.M)["name"])
+	down, _ := peer.(mapstr.M).GetValue("peer.down")
+	assert.Equal(t, true, down)
+	server2, _ := peer.(mapstr.M).GetValue("peer.server")
+	assert.Equal(t, "10.0.0.2:8080", server2)
+
+	hits, _ := events[5].MetricSetFields.GetValue("cache_zone.responses.hit")
+	assert.Equal(t, int64(20530), hits)
+}
+
+func TestData(t *testing.T) {
+	server := newServer(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	if err := mbtest.WriteEventsReporterV2Error(f, t, ""); err != nil {
+		t.Fatal("write", err)
+	}
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "nginx",
+		"metricsets": []string{"vts"},
+		"hosts":      []string{host},
+	}
+}
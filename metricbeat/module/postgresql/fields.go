@@ -32,5 +32,5 @@ func init() {
 // AssetPostgresql returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/postgresql.
 func AssetPostgresql() string {
-	return "eJzUWk+P47oNv8+nIN7l7RbZoL3OoUCxr0AX6O7bh92ix4CR6ViILHklOZn00xeU5D+xncwkY8+2Oc0kFvkTSZE/Uv4Aezo9QmWc31lyP9QDgJde0SP88jV++e2Pf/7yAJCRE1ZWXhr9CH99AAD4TN5K4UAYpUh4yiC3poRuHTiyB7Ju/QDgCmP9Rhidy90j5KgcPQBYUoSOHmGHDwC5JJW5xyD8A2gsaQCNP/5U8fPW1FX6ZgIaf3o4yoh0nX7r6+nrQuHlQfpT+8OUtisa+fO7JsiMqEvSHiqyyQZQWSPIuRUb4ij1DqTOjS2RZbAZkO3nDfiCQNTWkvZnchtsYHLwBfqewFoUgA6cR0+AOmvWw4+a7GkNH1v/bE9nMsPvjKXabXj1plGy7j127qLmMzRh34wZetyio7WR2dkDjTmV0bvBD1csGqz66be4cWqlgy+kgy2KPekMJIeh1nGb3qyvA+N/J5Ht6XQ0doj6GXBfsKQZ0FWzWetrDA1ojNYhmdZcO7LrJXzFgkGZ3Y4ykDpE94uwTPjnBh/coRWrSkkRDuPmdcp7kuI5Hfj+BWCEkqT9GrPMknO3Qfn0FdK6BlCUdieGwjh/uz3+YZwPcloMrfIod8X5ylJlbMxKgGCJKwXBb1++gTJmX1e8OD6+4S1dxcmSZgrf7x+/AosDXZdbstGJPUNKB7XjpJkbC8KUZa0bfx+lL4JtR0KTrVdgLHz4C8gcEP6l5RM4I/aUhNIFX6TFG97RjXs5VZ0PUlFo3M710cmtomApB2gJsPbmgKKuS1BYa1GQXfW/PBq7J7sa6VFmJwUqdmkb/J2AqV+TJKjQolKk2i8YHpdbPUxHAEcrPT+SHNHaVBQk9pWROvzqPFpfVys4orIkSB742yMTDp2RDQXyiCoKW4+U/P3Jk3bSaAclnsDSTjpPNuFz0ceYZZK3gapN8cGI1/0XkE1GaYb+Vs/KkuBYkI5nOZEBOEYewMdqBXJN61Xz0GQiGInl5yJhmd6Kt6gdswSj32A7v7ZB29Pb3+M0yEBrFoPXniR1iqSMosJz2xvLhzxSMulAmyGUxOio5yCFzo9lTe8xSN6IAvVumsm8dpMROuMIsKKmC2COKL0c5dmIY2uMItQ3QrE1sf1GJKq1fFIJRgOCMmJ/xUy36f6YQs4ciFNTMsSQR3XZ84CqjumzY8MjoQB/Sv5+hO8F9fdETyTqsBdMhH1ytczUeG1jBS5FCJqO3SEvSxzW9r4okLp/qEaSJdu198AKtrW/FMn86Vxzw4YGKOAdbgMleM94pOvOj5OlVGiZu6R1kyDOANOToMqD0W0JDOK4MQv742/6ygVyCZ6UixrIWjNRLngnOTpfoS8gr3UjSqmrjuYlH87WTIvOpMOtomxoj5Y78SGxKPZN6ybJ8e/NurjP58he8NKNJ5Se/PBQ/OqgZObHVbfrPj/10mDKl2FR6CBHcrk7doMs2xmuEamBT6bxBffXLNytQPpu8ZigdKk18DnOa1HstZy2ocOwDX/WMP9G6SGsiwxYxtC7lsSeA3AP8SsirWMXRTCcIo6FFMUQzghES1t2kSO9ZhryzaOXzkvhALem9q3ySPESpWvrvWvHHP2pRaTbQ7c2M4sG5jjX3D656JikWztRUFarUUa4t6/4EtsJk0Mrua8vxmWBB4ItkYaKbG5seSk8+0gt/ajJ+QWQtpJnQuplSW4d/LUuh11ShJsrgzceue/GowIsTa1jTmIik0C6iNFVoXWLSZ9TJ2dp7i9bcCOpKSY59I4FWYJcqtQm8QY88yTDiXa/YsGlVEo6EkZnF9qAsSHcSYv/Zzsw/sIaLf8Txwo3GGNb5zlZt+4ZZfboTTpad2W15V30/XAd2wRxnR/V9jSdFF+AbZPXSs0OMMTmhUTtvKkqygAhAGBzOoEathTYE8hx/BSY9Q6MgRL1qd3G1T2mIrV8XEhLgutxGERdZUoDaJucj8BCHmihBBN60xAWkN6BOWoIygPXhHfa2BKVGrI4uODHAnWmgpeNo8AQus6v0ZoZ5pJR1/Qs5v1VG6FSRuASZalxYKvhcvPnNpYczToHYPrYECkXm4pEcY6cHgPVDErHlKq5HXgNpfpdE1hzDHdLjbzuVqn55sNRZn1s57dA7c3PJKOaQHk7lfp5dz9h+PLnQHhdgZYy9oaprbg0n/vJt0EroLLyp1sAh5OwMfkmiVygdibBvW5lfIPQ3qc9Nyt0a2HKclQcZsiUPR1tr9uz+hlRjRguposzvNYoxTb4uYgZBR9YvDTf2ioj9twB4PxZltlcUgCsYIT2KqRiCW+HutjHFTJuHiobKgZ5ashtrBEgUBScHqdG3ejDvRPzEwyDLdDEVBftCd6FnRqtWKBQdUYOCtkNjrqXC0aCzzWzWF5gKrIYhhju5DyVv7pApNN/8en3Vy3Kuw+evtQyZKbeqnsqWuwKWHRTQiK2ZOTtqUsHwxhYTY3iXkD/e1u62g6+ck8s+632ZM2RT6KvrV6iFTdHPoZRelPCw7TvRacygMvJi2IpbEn4ndCkdmQXGWEwtkb6neDqKluExwZsSfid0DJStBi0JPx2aMLoXEmxQE/f4BCoBXFtzGpiPtJqjBe0loQ5kD01eEcSn6EuVFbGoj2tw7Bj/kLWyE/DFGHp2RiAv416fRgJQksgTK3DHaSlHVpu88K7IMciDhrOl3DpG0lt4Lyj9W7NxdPGuzbuG10h9e79KlyjnysIV5hmt2EFmym7ATjylwfendG3Jz+b0YdDsVAJegO8oQXHLrgcO+ySO1zwjBcDG0kuWMLOGWEWiuD8jLGRDBn52Cq8LF/873Tqo5a9vTN8bc9+9lJovIriv8LLatO9/MQbomdSpT6Y9DpN81JokNu9EiqqGmqHu/haqA9HIXCuG94J7S5NX3e38pYvGXZDrGAVi5G0T9xGv/k7q/23RWytYXjze3YnO5+9OiytP6/p9fR04+XDH/FydbjuXKxApRaopu3AOpq2ve2w9VXjMseZtcro62z9Qugli/OxDCd09huhDla/iWm6vlrr7mr9OYCl1DPC+yy1LOtyVoD4NCdAfJodIOFFE94ed58J9ZzonM8yOsyH76upahVLlPOoM7QZZHSQXdXqzR/6SF94jRihl1Qae1rH4emMk6fh8UnT2TBCiBObOBNKV3jPmvgc54xDuxcADXOu+4Bm0no5W4/5AqxJ4Z1wE7l/O7iDm9yXwlVGoFowWoP8VwdrRLlgrI5h3hOqEeaykTpGemegRrDLxukY7J1hym3mkv5n+a92fwC5rEFHOKft+d8AAAD//5VSM/M="
+	return "eJzcXVuvGzeSfj+/ojAvsReyMMEssIAfFjDiAGPAiT2xF3kUqO6SmnvYpEKyj6z8+kHx0s2+6nK6dZxBzkMsqau+upCsKhbZb+ART2/hoIzdazR/iAcAy63At/C3z/7DL//6+LcHgBxNpvnBciXfwv8+AAD8glbzzECmhMDMYg47rUpongOD+gm1WT8AmEJpu8mU3PH9W9gxYfABQKNAZvAt7NkDwI6jyM1bR/wNSFZiBxp9YU8H+r1W1SF8MgCN/hIcpUe6Dt+lfFJeLLP8idtT/cUQtwmO9PdJIuQqq0qUFg6ogw7goFWGxqxIEUcu98DlTumSkUJJDYz0ZxXYAiGrtEZpW3QjNlA7sAWzCcEqK4AZMJZZBCbz+Dz8UaE+reGn2j7bVDTw3xOWw35DT28ik6gogK6JAIZVmKoxZ5ZtmcG14nnrB1GdQsl954sJjdLfpw/vveBYUwdbcANblj2izIGTG0rp3dCq9TQw+meHh0f2iKej0vl14H5lJc6A7jCbtj5714CotAbJMOfKoF4vYSsiDELt95gDl1ZdimXAPlfY4Aau7HAQPHODcfM85gklP047tr8ATCY4Srtmea7RmOugfPgM4bkIyFO7EUOhjL1eH/9UxoJMlNIw93RXNF9pPChNn21PwEAjrRQI73/9AkKpx+pAAvifb0ikSZxEaSb3/frTZyByIKtyi9obMVEkN1AZmjR3SkOmyrKS0d5Hbgtn3x7RoOsVKA1vfgS+Awb/J/k3MCp7xEAUR2wRHt7QFHWlLKdDY4OwKARqa1qnDd8KdJoywDQCq6x6YllVlSBYJbMC9Sr98Kj0I+pVj49Qe54xARob528IDH0bKMGBaSYEivoDgkfLrexORwBHzS09EwwRBFlBVmD2eFBcum+NZdpWhxUcmdCYIX+iT48UcMgctVsgj0x4Ym2F038/f7MoDVfSQMlOoHHPjUUd8BlvY5bnnHTORBxFXonT9nPIOgzpMbcwXWtZXiIcC5TO32IwAEcfB9CwWgFf43oVfzQ4EfTI0u98wDIsitVMGooSlLyDOD/UTpvwTWUcBunCmsXg1SNJnIA08YQ+jmrrXmka5BRUIQ1uqbpQQkSHiYEEM7ZPa1hGR3mTFUzucREhHQMnk4PlOY0o/Mi45b151uPYKiWQySuh6ApJf+k6RWpsNB9YgpLAQKjscUJN1/H+KbicekKamoIiunFUM3s+MVH56bOJhntEAf4r2PstfC0wlQm/YVaR+oCFgH3waZ6L/rNRC7QUMZB4bAZ5WTKZj5MCLtPB3KPMSa/JD1awreyYJ9NfY5orBOqggFds60KC14SHx5SG/oeXXDBNsUt4bhBECzB+y/BgQcl6CXTkKDEzjnGBLeYZqwz2Vx36j0lArdXAckH63DFjD8wWsKtkJCXEpKHpkTetZ4ZJ59ywrcC8q486dqJBoln2GFM3joa+j895ORO/HRwlzkrXjZKv+M12k4sfDJQU+dGq22SfH5JpMMyX7iGXQfboUnZsOrNso7hIUgKNTGULyq9JJ2YF3DYP98gmU6uL52he82Sn5rQNPnXT8LOK+Z1xC+45p1w/i7X8oDeJnQNwS+BHvh2CPw+GpohjwbMC7OAcsn7oAtjufYz0nGrIF8ssN5aqRGyrKlsz9yFeCOnq9T54CLetqoUPt7tmjTWLCDM4x7MqF00kadYmKzCvBOYz5RW/+nRC7aCmnESu5PPMQsGeELaIkkpHVB8ac88UqcY/KjR2AaQ15ZmQWl6iWTt7rctuZkte/hZ2QrErh9xXZZkAVqqKlu0dEJcI0niM5kBjIEz6NHXSLK12Cbge1eCT5HrHAjXCjgu/zjuvtRS0Kci5eVzRLFtyIbjBTMncXKoIc5LZX1kPhL/QSvI/Mb9SGdtqt6PKcKKU2b038KjNlVeapEhYnsE2ELjOj2p7Gp4UL8C22VUilsXnA0jDx4xM1MaqwwFzYOAAkDpNxiRs0UVPwPv+U7C8ltUqBSWTp1qMSRnDIjW7gF0L5FxjRuuxK0QFrhdB2+xoCMwO0FughuJUaFUMWIBbA+oowTF3sSa8krSXIMRpMKPv27FgMqdRbAtl0IUrTeYXueaKYknPq0eWdIevJ3XEhFAZW2JZChyg5jBsLAo7zUajQTtnisxsE0gZnyiHEOdI06MLNR3T9UMXUdwdeE5I9UkiaHWkAKGm1+wqxU/eHHmeYmvvAtU7P4MRVaTxrFDq5fZ+XPHl7y7gNQXTmINGoyqdjdXnXng3aAVYHuzpGsBuJGzUbhNImplUnQyxQDhJw1LMXqAo3jDKJLc260yVJbezw0x51LluovVWoOoxjE4XLbxaCUHKfVnEhII2SNhYfWtLpS/KAFg+O1KK5gIDIAY9tJOQiiWs7dbFFJebcXduZaNqP8tPMbj1awRkLCtwBUb1yLrAmPadKD5hrkQLEinUZfoEr5ykSgoimIkqRwMFbwpHTXNBj3CbM5ElPOqAmlGiDeZkLJY/GJdQhH/5X7+e1ChFBc7SYylDrqqtwOuU61Y0nxUQ6biEeGxBydtTMx10fWA1VIq7IPxPRJpMB58pE9G+l0xaHWkk2krLJVJxqotF6nEJ59hHPwFuh5ZKD8tgC8RvhMalQb1ICYOwReo3gqsO+SJxLBGHQPxGaDkKXAxaIH49NGrzEjxbIKePODImM6QSWl4hlR5qjn6DVmNG+zdhNRjYkJ/Gb7E8KM30aU3z4PxS1PRDMSXTeNYH4F0v14ceIarKZFQHoz1IjXumKc0zxPNY+EJD+xFa+npUI5xXuN6vafHUbuFSlDeagsv965XbRm8zIOJC7TfEYDOkNwCDdrzgXQNbb092NqV3i2Kkz6Qe0VGHGTDBuO+QSW4wQY9gmwSZJJpgCT3nyHK3/M6k4cata8qQo/WpQs+NByF9P5n6QxdaV1NX5+sfiQAUKHJnS0b7OlExdbNFkp27HtrDfuMYr/x0pmRbfCrY2FYFwNGmR6BUOXq3oZ3P5pehjgtcPinx5BC0aPptdLcdtB7K/LdoL839a2PfIbOuB0T2iDmo7f9jZs0aflWWRoFLqkkuA6of04VsOzzkdJakZ/Dh/UikRyqeT6yP0Wrw6l1G+ccXgkWfruA3dfz5WyYqw5/cJ+v1eiRJ2GtGU87azf6zj+xA3am5WZtID8No4qbiMmjiGLoUTXD9DsXhkXxFe+02OF27C4M+dCUo9zV97vepA4huTWd4JLXQ9yppk1q8QIB2j/B6lLNWAkdZD/v6hdx/U6IeukExcUasZ6FVrWC+o939dr/LINkh7dcPu2S+UCKnT8Oc0LbMME2NKd9xbY11Ls+ksjMdzrUexwFOTcsLgKyn6JaR27XPcbBnOrIXwJt2bndqnRcod6jTbCaQsSMt6US7ANB0/8hM0L62ekwSXJ3RuqI+GCZPl8BdBmlq6eejTcKEwUbTydD1Ut22+jFDHT7l3HGF8abRy5pjl8PseN6ENtVzXlEBVcl+qXC6a+JC6O8D+YhzQOFnehv6an4hyI75FWBpoC6N1XlGav+6JyUZeHUAcAF4+nv3xLigBkkwXGZpVR5+/O9xeYf6HWeaZn6acPlxQDFY2Rx4bhYN9kwHE5qk/SGGTGkP/jhorZTdvCjycDCnoM2p8GXEA1nBuKRnBsmmEq5CF0T9qIvX/UkPalncIukkxKJr+Jk2aweJcmr8NZhWQsa15/BtcjzYYgG1fUS5t0VUCqkfjW0M7Ji3zJyugEqOww5q2EzFzM8F3yR6Y07qS6Up/vVDF2ly2ujhXNI3Aeq35NBSKwAbr9zQzzYJe1/EaVFtl2aaaNNYJvPtyddvIr2E1MYIZc9XhZIngJ54bkEnwHoYsnNXlxcY+fd3HyGcxgrmA2M1spK8zypgkWEsj31nGXN0gb4c61FML5sdcnMV2O8q+WphH8ccToIOn5qdCXH/dG101GdhHjllOxPoidO4Af3K10ZYHU1R1jFIi/YdIlpaMlDS7/OzEg6cz51jSI6e1Y1mOXNYd5DoxdYLDQR3Sqae52l3KE80MEKl4lV9FDdjNivoTG49za+c9uiQ93AlzzUa007jhEjUPb+gXF9Cd7uq4sGreN4pmiJc7fGKUSPuCg7KorSciRUMtAWHhV/DH5XSVXlOsIPmSrfvIplr1HwOpMfkoUTMB70ZBcaSDuvbomn3V9XQ9AqRzLhggu3XhqaDoQ3es5LRcRhm38LYwxdI/q7eBiZn3aOknqwYTFJqykvahaWeLcIJJ7RxlE1J7LJVB2tadtp2xr+C8Mn+uOu2Iz30wr+AOnGg6/SwYJ0BBTsYEg3tkfood6JyfQvxaCJZn5rTBa16rpGB7gnoH2H2/0ll+S4uG2GJSUYNFTKiwridoXLx92ntOWH+Cl7kgDYng+b2Ikf+P8qLosLu4UWUIrLTX8GNPFLMg//MNhsHDfwnOZDLohZyoKg6qifMVQhoFVeEsp3aypVp/30y1anaShvPQVR7LpdB9Kmyh8oGFgQtXrJDkCYcf/mtQNIRgYBXh+JkHCbXg+PgvT6/O70MuvdxM/pyVdEm29O4soYvNrlUWfUFJ0FZrXsBfPDdvxCqj25zr1KXoaPQSoZsOsJeNd0VHs843rrHcWmF0uUldHiyaaokpOtb10qNlnGJ+XeyUkY4caEMVogLHnyQ4Y4N+mKIIwSJyMMogKHTuztOfazUskHB/uQc3CiGOq65LjHfuLBlQ0HF96EjChpqdE3UQJ3R0lRl6CFrTQOrsQ2dM3HEkQkq/9jKLDNtBaflok7XfdU5tI0Lxsu2L8Ar6qLVT5gPi4R0vVlOrcmVjL+kZjWhjH09PUj+Ma4Gw3a4cbrgf34vCa6LkMi7t82FDVvcKY2NsmiukJDT1VZ6bKtw73upm/Y0Ezpx0mF3od5qfcWLiB7OBVHX3DTr95zp/2izYeSA8MC1sy2q1B4copt402xoJ4j3zGaHCirD9uhiVuv6691BrmQrrJPm9S6abW5iGtyauviU8T1vLm1OxjvX0sw7xMAVV92QZvGLcAMK1wGkK9nbwY2A3K/m01eDpbbnFF+L3+x1DP5FeKH3XJssJeBmJomGbsFwXJp2FV1NKpcOTs0Exh9dke0zWd0jgKdJjdOwdCO0XycZT3GvhJVmmeHYpa6kbO7rOgew5HJGeL9wycuqnBUg+zYnQPZtdoDIRlV4vd/9gkzOic7YPMen+fB9VodKhAScqmdM55DjE29WreRQc4r0TFGkDb3EUunT2p8RmfE4e3f4hEMorrHGHwP3B81DUHdWxW2cM94EcAFQ4nYj0Jxry/GOWAPDG+GGCPJ+cOuQ9Tq4rh69oLc6+s92VkdlSV/tw7zFVR2VhT21j/RGR3WEFvbTPtgb3ZSKM0van+g/2/xEZGGF9nBO6zPCtKx9KcY893JSMuVJm26O0W1zbBGLSR09v/HPNx2KSVei+2ow37uiFXGgyD9VZzljj7Sw34PXMKUbO0u2DFtP+wIQtS2WU0FkcQEauvbPrA1dEOp6XhY66tkw8ByBS2652ypU8mqMMyZnDUTBn7B3BUoX9xRALnP8tpD+HO2bVeeevqPWErQvflfMC98H88J3vkywL5TdLKqBf376GrWwqk9h0FXE3N3fymTwE/+bCaSiv393K8SfjeUlIQLZd+EJCPl8Mc4QBCJ/DkKpcr7jmG9caXrDJBOnP5fUC+GByDUUxOvppnmlRACST0CPYzFA96+mWRp5ZDqO3OPoDpJz/RT/GBbUE1vTZSQPF/YPn5HsI2EMlz+n2EsmK9drMoy/A2iZ9agpq3pcdVn1QnDNy4juobGIJYbjyauQcoalkmdB3lmLtwL2Q/GuTjg9/COil/LCM+gqq+6oswjmBjd8IT3eiHh0+3gU6tS28RkxvvA/60SnpPOQlP20Mp9kh3ccrt9eeQnQDmO4npQCIxcV0bFYmcPXT+++fCUXZOeE2NIWy93wN6uuSSQxB5a56SG5GctLtz01MU44FIndUQX++URQ+ETnmbDmRScaapd0hCeV4Q7td5h4Y5qMCdfx0t/1iUo5oM76t1hcrJad7lzuwPZNOaaeEkKrbEs1PaI7jUG11ykkKuPIxHNqTL/TaYc3zJ3OFmpfv9olChNOm5BR6+M11LjBdFa4Nuq2iUbO2R7plRcyr//tn0b93DoTl5t4ieSgJwx3q50xc6vtz4tPrXN1Dy+X9c2V60FUB2XciwfvNl7Tw11Um8QaQtuM7s6lcKGee+dinv6wRzYIbNyLQB2sJNre4p77XbvA4vd3H9fwwVJyqvkTc/1tfPhFT0k3ObHW4wkiZkrnF6vwyrouwSBL6tw0ze3ra+6riDjpFR+bA9vjhpc0FSwImFgBsQLPagJ5j94FktzLZbs3frYOGKSLxA3WCG+eWPLFKwSX1m1aDnsHseJ7VuhHAUuPIP2mRCCIN8lIPJcNGBP0/ibOKCYVeoKoKfSBJvqWHKSeH/9nShriO2MLR9fFbP/G8VTGINL5e8X9Mt28T98f/nXv96PuSq42lrtLEqaOXqd6u1xPdLhzcaP7tlVncuKH+dzWJqp3NDaxi8b2siWmnug0ob8bTD2Xkpa/bddZ+5L7dlNcdegW/mepy0wTa7Vft2Eqd/8qzZyniCa/FCtJt26HzDPuzBH1GvgMQMmJ5zR+CrNBF7nHt18oeQbkjnGx2CW2njgwS7vu1lCMETi3xvBlEJc1d23pVK8Rv4PMxLVKXdzut+K7x3wUmU1eAv7vAQBBk/8c"
 }
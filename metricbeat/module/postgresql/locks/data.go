@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package locks
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+var locksSchema = s.Schema{
+	"mode": c.Str("mode"),
+	"granted": s.Object{
+		"count": c.Int("granted"),
+	},
+	"waiting": s.Object{
+		"count": c.Int("waiting"),
+	},
+}
+
+var processSchema = s.Schema{
+	"pid": c.Int("pid"),
+	"user": s.Object{
+		"name": c.Str("usename"),
+	},
+	"database": s.Object{
+		"name": c.Str("datname"),
+	},
+	"application_name":  c.Str("application_name"),
+	"state":             c.Str("state"),
+	"wait_event_type":   c.Str("wait_event_type"),
+	"wait_event":        c.Str("wait_event"),
+	"transaction_start": c.Time(time.RFC3339Nano, "xact_start", s.Optional),
+	"query_start":       c.Time(time.RFC3339Nano, "query_start", s.Optional),
+	"transaction_duration": s.Object{
+		"ms": c.Float("transaction_duration_ms", s.Optional),
+	},
+	"query_duration": s.Object{
+		"ms": c.Float("query_duration_ms", s.Optional),
+	},
+	"wait_duration": s.Object{
+		"ms": c.Float("wait_duration_ms", s.Optional),
+	},
+	"query": c.Str("query"),
+}
+
+// parsePIDs parses a comma-separated list of process ids.
+func parsePIDs(list string) []int64 {
+	var pids []int64
+	for _, field := range strings.Split(list, ",") {
+		pid, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// blockingChain describes the chains of processes blocking a process.
+type blockingChain struct {
+	// roots are the processes at the end of the chains, that are not
+	// waiting for other processes.
+	roots []int64
+	// depth is the number of processes between the process and its
+	// farthest root, the root included.
+	depth int
+}
+
+// blockingChains follows the processes blocking each process waiting for
+// locks, up to the processes that are not blocked. blockers maps each
+// process to the processes blocking it. Processes in a deadlock may have no
+// roots, until the deadlock is detected by the server.
+func blockingChains(blockers map[int64][]int64) map[int64]blockingChain {
+	chains := make(map[int64]blockingChain, len(blockers))
+	for pid, direct := range blockers {
+		if len(direct) == 0 {
+			continue
+		}
+
+		var chain blockingChain
+		distance := map[int64]int{pid: 0}
+		queue := []int64{pid}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			next := blockers[current]
+			if len(next) == 0 && current != pid {
+				chain.roots = append(chain.roots, current)
+				chain.depth = max(chain.depth, distance[current])
+				continue
+			}
+			for _, blocker := range next {
+				if _, seen := distance[blocker]; seen {
+					continue
+				}
+				distance[blocker] = distance[current] + 1
+				queue = append(queue, blocker)
+			}
+		}
+		slices.Sort(chain.roots)
+		chains[pid] = chain
+	}
+	return chains
+}
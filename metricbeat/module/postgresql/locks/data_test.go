@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package locks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePIDs(t *testing.T) {
+	assert.Equal(t, []int64{12, 345}, parsePIDs("12,345"))
+	assert.Nil(t, parsePIDs(""))
+}
+
+func TestBlockingChains(t *testing.T) {
+	chains := blockingChains(map[int64][]int64{
+		// 10 blocks 11, that blocks 12 and 13.
+		10: nil,
+		11: {10},
+		12: {11},
+		13: {11, 10},
+		// 20 and 21 block 22.
+		20: nil,
+		21: nil,
+		22: {21, 20},
+		// 30 and 31 are in a deadlock.
+		30: {31},
+		31: {30},
+	})
+
+	expected := map[int64]blockingChain{
+		11: {roots: []int64{10}, depth: 1},
+		12: {roots: []int64{10}, depth: 2},
+		13: {roots: []int64{10}, depth: 1},
+		22: {roots: []int64{20, 21}, depth: 1},
+		30: {},
+		31: {},
+	}
+	assert.Equal(t, expected, chains)
+}
+
+func TestProcessEvents(t *testing.T) {
+	events := processEvents([]map[string]interface{}{
+		{"pid": "10", "usename": "app", "state": "idle in transaction", "blocking_pids": ""},
+		{"pid": "11", "usename": "app", "state": "active", "blocking_pids": "10", "wait_duration_ms": "1500.5"},
+		{"pid": "12", "usename": "batch", "state": "active", "blocking_pids": "11"},
+	})
+	require.Len(t, events, 3)
+
+	root, _ := events[0].MetricSetFields.GetValue("process")
+	assert.Subset(t, root, map[string]interface{}{
+		"pid":               int64(10),
+		"role":              "blocking",
+		"blocked_processes": 2,
+	})
+
+	blocked, _ := events[2].MetricSetFields.GetValue("process")
+	assert.Subset(t, blocked, map[string]interface{}{
+		"pid":                int64(12),
+		"role":               "blocked",
+		"blocking_pids":      []int64{11},
+		"root_blocking_pids": []int64{10},
+		"chain_depth":        2,
+	})
+
+	wait, _ := events[1].MetricSetFields.GetValue("process.wait_duration.ms")
+	assert.Equal(t, 1500.5, wait)
+}
+
+func TestProcessesQuery(t *testing.T) {
+	assert.NotContains(t, processesQuery(130002), "waitstart")
+	assert.Contains(t, processesQuery(140005), "min(l.waitstart)")
+}
@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package locks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	mb.Registry.MustAddMetricSet("postgresql", "locks", New,
+		mb.WithHostParser(postgresql.ParseURL),
+	)
+}
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	*postgresql.MetricSet
+}
+
+// New create a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The postgresql locks metricset is beta."))
+
+	ms, err := postgresql.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricSet{MetricSet: ms}, nil
+}
+
+// Fetch reports the number of locks held and awaited per database and mode,
+// and an event for each process involved in a blocking chain.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	ctx := context.Background()
+	version, err := m.ServerVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting server version: %w", err)
+	}
+
+	locks, err := m.QueryStats(ctx, locksQuery)
+	if err != nil {
+		return fmt.Errorf("error in QueryStats for pg_locks: %w", err)
+	}
+	for _, result := range locks {
+		data, _ := locksSchema.Apply(result)
+		if database, _ := result["database"].(string); database != "" {
+			data.Put("database.name", database)
+		}
+		reporter.Event(mb.Event{
+			MetricSetFields: data,
+		})
+	}
+
+	processes, err := m.QueryStats(ctx, processesQuery(version))
+	if err != nil {
+		return fmt.Errorf("error in QueryStats for blocked processes: %w", err)
+	}
+	for _, event := range processEvents(processes) {
+		reporter.Event(event)
+	}
+
+	return nil
+}
+
+// processEvents builds the events of the processes waiting for locks, with
+// the chains of processes blocking them, and of the processes at the root of
+// these chains, with the number of processes they block.
+func processEvents(processes []map[string]interface{}) []mb.Event {
+	blockers := make(map[int64][]int64, len(processes))
+	fields := make([]mapstr.M, len(processes))
+	for i, result := range processes {
+		fields[i], _ = processSchema.Apply(result)
+		pid, _ := fields[i]["pid"].(int64)
+		list, _ := result["blocking_pids"].(string)
+		blockers[pid] = parsePIDs(list)
+	}
+
+	chains := blockingChains(blockers)
+	blocked := map[int64]int{}
+	for _, chain := range chains {
+		for _, root := range chain.roots {
+			blocked[root]++
+		}
+	}
+
+	events := make([]mb.Event, 0, len(processes))
+	for _, data := range fields {
+		pid, _ := data["pid"].(int64)
+		if chain, found := chains[pid]; found {
+			data["role"] = "blocked"
+			data["blocking_pids"] = blockers[pid]
+			data["root_blocking_pids"] = chain.roots
+			data["chain_depth"] = chain.depth
+		} else {
+			data["role"] = "blocking"
+			data["blocked_processes"] = blocked[pid]
+		}
+		events = append(events, mb.Event{
+			MetricSetFields: mapstr.M{"process": data},
+		})
+	}
+	return events
+}
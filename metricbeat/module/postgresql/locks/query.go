@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package locks
+
+import "strings"
+
+// PostgreSQL 14 added the time when processes started to wait for a lock.
+const version14 = 140000
+
+// locksQuery aggregates the locks held and awaited in each database by mode.
+// Locks on objects shared by all databases have no database.
+const locksQuery = `SELECT d.datname AS database, l.mode,
+  count(*) FILTER (WHERE l.granted) AS granted,
+  count(*) FILTER (WHERE NOT l.granted) AS waiting
+FROM pg_locks l LEFT JOIN pg_database d ON d.oid = l.database
+GROUP BY d.datname, l.mode`
+
+// processesQuery returns the query of the processes waiting for a lock and
+// of the processes blocking them. pg_blocking_pids is expensive, so it is
+// only called once for each process waiting for a lock.
+func processesQuery(version int) string {
+	columns := []string{
+		"a.pid", "a.usename", "a.datname", "a.application_name", "a.state",
+		"a.wait_event_type", "a.wait_event", "a.xact_start", "a.query_start",
+		"EXTRACT(EPOCH FROM now() - a.xact_start) * 1000 AS transaction_duration_ms",
+		"EXTRACT(EPOCH FROM now() - a.query_start) * 1000 AS query_duration_ms",
+	}
+	if version >= version14 {
+		columns = append(columns, "(SELECT EXTRACT(EPOCH FROM now() - min(l.waitstart)) * 1000 "+
+			"FROM pg_locks l WHERE l.pid = a.pid AND NOT l.granted) AS wait_duration_ms")
+	}
+	columns = append(columns,
+		"array_to_string(COALESCE(b.blocking, '{}'), ',') AS blocking_pids",
+		"a.query",
+	)
+
+	return `WITH blocked AS (
+  SELECT pid, pg_blocking_pids(pid) AS blocking FROM pg_stat_activity
+  WHERE wait_event_type = 'Lock'
+)
+SELECT ` + strings.Join(columns, ", ") + `
+FROM pg_stat_activity a LEFT JOIN blocked b ON b.pid = a.pid
+WHERE cardinality(b.blocking) > 0
+  OR a.pid IN (SELECT unnest(blocking) FROM blocked)`
+}
@@ -22,7 +22,9 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/elastic/beats/v7/metricbeat/mb"
 
@@ -95,6 +97,25 @@ func (ms *MetricSet) QueryStats(ctx context.Context, query string) ([]map[string
 	return results, nil
 }
 
+// ServerVersion returns the version of the server as a number, in the
+// format of the server_version_num setting (90605 for 9.6.5, 130002 for 13.2).
+func (ms *MetricSet) ServerVersion(ctx context.Context) (int, error) {
+	results, err := ms.QueryStats(ctx, "SHOW server_version_num")
+	if err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, errors.New("no results from the server_version_num query")
+	}
+
+	str, _ := results[0]["server_version_num"].(string)
+	version, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("parsing server version %q: %w", str, err)
+	}
+	return version, nil
+}
+
 // Close closes the metricset and its connections
 func (ms *MetricSet) Close() error {
 	if ms.db == nil {
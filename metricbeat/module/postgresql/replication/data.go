@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package replication
+
+import (
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+// Based on: https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+var standbySchema = s.Schema{
+	"pid": c.Int("pid"),
+	"user": s.Object{
+		"name": c.Str("usename"),
+	},
+	"application_name": c.Str("application_name"),
+	"client": s.Object{
+		"address":  c.Str("client_addr"),
+		"hostname": c.Str("client_hostname"),
+		"port":     c.Int("client_port", s.Optional),
+	},
+	"backend_start": c.Time(time.RFC3339Nano, "backend_start", s.Optional),
+	"state":         c.Str("state"),
+	"sync": s.Object{
+		"state":    c.Str("sync_state"),
+		"priority": c.Int("sync_priority", s.Optional),
+	},
+	"lag": s.Object{
+		"sent": s.Object{
+			"bytes": c.Int("sent_lag_bytes", s.Optional),
+		},
+		"write": s.Object{
+			"bytes": c.Int("write_lag_bytes", s.Optional),
+			"ms":    c.Float("write_lag_ms", s.Optional),
+		},
+		"flush": s.Object{
+			"bytes": c.Int("flush_lag_bytes", s.Optional),
+			"ms":    c.Float("flush_lag_ms", s.Optional),
+		},
+		"replay": s.Object{
+			"bytes": c.Int("replay_lag_bytes", s.Optional),
+			"ms":    c.Float("replay_lag_ms", s.Optional),
+		},
+	},
+}
+
+// Based on: https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+var slotSchema = s.Schema{
+	"name":       c.Str("slot_name"),
+	"plugin":     c.Str("plugin"),
+	"type":       c.Str("slot_type"),
+	"database":   c.Str("database"),
+	"active":     c.Bool("active"),
+	"active_pid": c.Int("active_pid", s.Optional),
+	"temporary":  c.Bool("temporary", s.Optional),
+	"retained": s.Object{
+		"bytes": c.Int("retained_bytes", s.Optional),
+	},
+	"confirmed_flush_lag": s.Object{
+		"bytes": c.Int("confirmed_flush_lag_bytes", s.Optional),
+	},
+	"wal_status": c.Str("wal_status", s.Optional),
+	"safe_wal_size": s.Object{
+		"bytes": c.Int("safe_wal_size", s.Optional),
+	},
+}
@@ -0,0 +1,96 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package replication
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PostgreSQL 10 renamed the xlog functions and the location columns to
+	// wal and lsn, and added the lag times of the standbys.
+	version10 = 100000
+	// PostgreSQL 13 added the state of the WAL files retained by slots.
+	version13 = 130000
+)
+
+// walFunctions returns the names of the functions to get the current WAL
+// positions and the difference between two of them.
+func walFunctions(version int) (current, received, diff string) {
+	if version < version10 {
+		return "pg_current_xlog_location()", "pg_last_xlog_receive_location()", "pg_xlog_location_diff"
+	}
+	return "pg_current_wal_lsn()", "pg_last_wal_receive_lsn()", "pg_wal_lsn_diff"
+}
+
+// currentPosition returns the expression of the current WAL position. On
+// standbys with cascading replication, this is the last received position.
+func currentPosition(version int) string {
+	current, received, _ := walFunctions(version)
+	return fmt.Sprintf("CASE WHEN pg_is_in_recovery() THEN %s ELSE %s END", received, current)
+}
+
+// standbysQuery returns the query of the lag of the standbys connected to
+// the server, in bytes and, since PostgreSQL 10, in time.
+func standbysQuery(version int) string {
+	_, _, diff := walFunctions(version)
+	position := currentPosition(version)
+	suffix := "lsn"
+	if version < version10 {
+		suffix = "location"
+	}
+
+	columns := []string{
+		"pid", "usename", "application_name", "host(client_addr) AS client_addr",
+		"client_hostname", "client_port", "backend_start", "state", "sync_state", "sync_priority",
+	}
+	for _, lag := range []string{"sent", "write", "flush", "replay"} {
+		columns = append(columns, fmt.Sprintf("%s(%s, %s_%s)::bigint AS %s_lag_bytes", diff, position, lag, suffix, lag))
+	}
+	if version >= version10 {
+		for _, lag := range []string{"write", "flush", "replay"} {
+			columns = append(columns, fmt.Sprintf("EXTRACT(EPOCH FROM %s_lag) * 1000 AS %s_lag_ms", lag, lag))
+		}
+	}
+
+	return "SELECT " + strings.Join(columns, ", ") + " FROM pg_stat_replication"
+}
+
+// slotsQuery returns the query of the replication slots, with the WAL
+// retained by them.
+func slotsQuery(version int) string {
+	_, _, diff := walFunctions(version)
+	position := currentPosition(version)
+
+	columns := []string{
+		"slot_name", "plugin", "slot_type", "database", "active", "active_pid",
+		fmt.Sprintf("%s(%s, restart_lsn)::bigint AS retained_bytes", diff, position),
+		fmt.Sprintf("%s(%s, confirmed_flush_lsn)::bigint AS confirmed_flush_lag_bytes", diff, position),
+	}
+	if version >= version10 {
+		columns = append(columns, "temporary")
+	}
+	if version >= version13 {
+		columns = append(columns, "wal_status", "safe_wal_size")
+	}
+
+	return "SELECT " + strings.Join(columns, ", ") + " FROM pg_replication_slots"
+}
@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestStandbysQuery(t *testing.T) {
+	query := standbysQuery(90624)
+	assert.Contains(t, query, "pg_xlog_location_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_xlog_receive_location() ELSE pg_current_xlog_location() END, replay_location)::bigint AS replay_lag_bytes")
+	assert.NotContains(t, query, "_lag_ms")
+
+	query = standbysQuery(130002)
+	assert.Contains(t, query, "pg_wal_lsn_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END, sent_lsn)::bigint AS sent_lag_bytes")
+	assert.Contains(t, query, "EXTRACT(EPOCH FROM replay_lag) * 1000 AS replay_lag_ms")
+	assert.NotContains(t, query, "xlog")
+}
+
+func TestSlotsQuery(t *testing.T) {
+	query := slotsQuery(90624)
+	assert.Contains(t, query, "pg_xlog_location_diff(")
+	assert.NotContains(t, query, "temporary")
+	assert.NotContains(t, query, "wal_status")
+
+	query = slotsQuery(120000)
+	assert.Contains(t, query, "temporary")
+	assert.NotContains(t, query, "wal_status")
+
+	query = slotsQuery(160001)
+	assert.Contains(t, query, "pg_wal_lsn_diff(")
+	assert.Contains(t, query, "wal_status, safe_wal_size")
+}
+
+func TestStandbySchema(t *testing.T) {
+	data, _ := standbySchema.Apply(map[string]interface{}{
+		"pid":              "83",
+		"usename":          "replicator",
+		"application_name": "standby1",
+		"client_addr":      "172.18.0.3",
+		"client_hostname":  "",
+		"client_port":      "41234",
+		"backend_start":    "2024-03-01T10:00:00.123456Z",
+		"state":            "streaming",
+		"sync_state":       "async",
+		"sync_priority":    "0",
+		"sent_lag_bytes":   "0",
+		"write_lag_bytes":  "128",
+		"flush_lag_bytes":  "128",
+		"replay_lag_bytes": "4096",
+		"write_lag_ms":     "0.512",
+		"flush_lag_ms":     "1.203",
+		// Lag times are NULL when the standby is idle.
+		"replay_lag_ms": "",
+	})
+
+	assert.Equal(t, mapstr.M{
+		"sent":   mapstr.M{"bytes": int64(0)},
+		"write":  mapstr.M{"bytes": int64(128), "ms": 0.512},
+		"flush":  mapstr.M{"bytes": int64(128), "ms": 1.203},
+		"replay": mapstr.M{"bytes": int64(4096)},
+	}, data["lag"])
+	assert.Equal(t, mapstr.M{"state": "async", "priority": int64(0)}, data["sync"])
+}
@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package replication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	mb.Registry.MustAddMetricSet("postgresql", "replication", New,
+		mb.WithHostParser(postgresql.ParseURL),
+	)
+}
+
+// MetricSet type defines all fields of the MetricSet
+type MetricSet struct {
+	*postgresql.MetricSet
+}
+
+// New create a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The postgresql replication metricset is beta."))
+
+	ms, err := postgresql.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricSet{MetricSet: ms}, nil
+}
+
+// Fetch reports an event for each standby connected to the server, and for
+// each replication slot.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	ctx := context.Background()
+	version, err := m.ServerVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting server version: %w", err)
+	}
+
+	standbys, err := m.QueryStats(ctx, standbysQuery(version))
+	if err != nil {
+		return fmt.Errorf("error in QueryStats for pg_stat_replication: %w", err)
+	}
+	for _, result := range standbys {
+		data, _ := standbySchema.Apply(result)
+		reporter.Event(mb.Event{
+			MetricSetFields: mapstr.M{"standby": data},
+		})
+	}
+
+	slots, err := m.QueryStats(ctx, slotsQuery(version))
+	if err != nil {
+		return fmt.Errorf("error in QueryStats for pg_replication_slots: %w", err)
+	}
+	for _, result := range slots {
+		data, _ := slotSchema.Apply(result)
+		reporter.Event(mb.Event{
+			MetricSetFields: mapstr.M{"slot": data},
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package table
+
+import (
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+// Based on: https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-ALL-TABLES-VIEW
+var schema = s.Schema{
+	"name":   c.Str("relname"),
+	"schema": c.Str("schemaname"),
+	"database": s.Object{
+		"name": c.Str("datname"),
+	},
+	"scans": s.Object{
+		"sequential": s.Object{
+			"count": c.Int("seq_scan", s.Optional),
+			"rows":  c.Int("seq_tup_read", s.Optional),
+		},
+		"index": s.Object{
+			"count": c.Int("idx_scan", s.Optional),
+			"rows":  c.Int("idx_tup_fetch", s.Optional),
+		},
+	},
+	"rows": s.Object{
+		"inserted":               c.Int("n_tup_ins"),
+		"updated":                c.Int("n_tup_upd"),
+		"deleted":                c.Int("n_tup_del"),
+		"hot_updated":            c.Int("n_tup_hot_upd"),
+		"live":                   c.Int("n_live_tup"),
+		"dead":                   c.Int("n_dead_tup"),
+		"modified_since_analyze": c.Int("n_mod_since_analyze", s.Optional),
+		"inserted_since_vacuum":  c.Int("n_ins_since_vacuum", s.Optional),
+	},
+	"vacuum": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_vacuum", s.Optional),
+		"count": c.Int("vacuum_count"),
+	},
+	"autovacuum": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_autovacuum", s.Optional),
+		"count": c.Int("autovacuum_count"),
+	},
+	"analyze": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_analyze", s.Optional),
+		"count": c.Int("analyze_count"),
+	},
+	"autoanalyze": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_autoanalyze", s.Optional),
+		"count": c.Int("autoanalyze_count"),
+	},
+	"size": s.Object{
+		"bytes": c.Int("table_size_bytes"),
+		"total": s.Object{
+			"bytes": c.Int("total_size_bytes"),
+		},
+	},
+	"bloat": s.Object{
+		"bytes": c.Int("bloat_bytes", s.Optional),
+		"ratio": c.Float("bloat_ratio", s.Optional),
+	},
+}
@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package table
+
+import (
+	"strings"
+)
+
+// PostgreSQL 13 added n_ins_since_vacuum to pg_stat_user_tables.
+const version13 = 130000
+
+// bloatQuery estimates the bloat of each table comparing its number of pages
+// with the number of pages its live tuples would need. The size of the
+// tuples is estimated from the average width of the columns in pg_stats, so
+// tables not analyzed yet don't have an estimation. Each tuple has a 24 bytes
+// header and a 4 bytes item pointer, and each page has a 24 bytes header,
+// pages are only filled up to the fillfactor of the table.
+const bloatQuery = `SELECT t.oid AS relid,
+  GREATEST(t.relpages - t.expected_pages, 0) * t.block_size AS bloat_bytes,
+  CASE WHEN t.relpages > 0 THEN GREATEST(t.relpages - t.expected_pages, 0)::float8 / t.relpages ELSE 0 END AS bloat_ratio
+FROM (
+  SELECT c.oid, c.relpages, current_setting('block_size')::bigint AS block_size,
+    CEIL(GREATEST(c.reltuples, 0) * (28 + CEIL(w.width / 8) * 8) /
+      ((current_setting('block_size')::bigint - 24) * f.fillfactor / 100.0))::bigint AS expected_pages
+  FROM pg_class c
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  JOIN (
+    SELECT schemaname, tablename, SUM((1 - null_frac) * avg_width) AS width
+    FROM pg_stats GROUP BY schemaname, tablename
+  ) w ON w.schemaname = n.nspname AND w.tablename = c.relname
+  CROSS JOIN LATERAL (
+    SELECT COALESCE((
+      SELECT substring(o FROM 'fillfactor=([0-9]+)')::int
+      FROM unnest(c.reloptions) o WHERE o LIKE 'fillfactor=%'
+    ), 100) AS fillfactor
+  ) f
+  WHERE c.relkind = 'r'
+) t`
+
+// tablesQuery returns the query of the statistics of the user tables of the
+// current database.
+func tablesQuery(version int) string {
+	columns := []string{
+		"current_database() AS datname", "s.schemaname", "s.relname",
+		"s.seq_scan", "s.seq_tup_read", "s.idx_scan", "s.idx_tup_fetch",
+		"s.n_tup_ins", "s.n_tup_upd", "s.n_tup_del", "s.n_tup_hot_upd",
+		"s.n_live_tup", "s.n_dead_tup", "s.n_mod_since_analyze",
+	}
+	if version >= version13 {
+		columns = append(columns, "s.n_ins_since_vacuum")
+	}
+	columns = append(columns,
+		"s.last_vacuum", "s.last_autovacuum", "s.last_analyze", "s.last_autoanalyze",
+		"s.vacuum_count", "s.autovacuum_count", "s.analyze_count", "s.autoanalyze_count",
+		"pg_relation_size(s.relid) AS table_size_bytes",
+		"pg_total_relation_size(s.relid) AS total_size_bytes",
+		"b.bloat_bytes", "b.bloat_ratio",
+	)
+
+	return "SELECT " + strings.Join(columns, ", ") +
+		" FROM pg_stat_user_tables s LEFT JOIN (" + bloatQuery + ") b ON b.relid = s.relid"
+}
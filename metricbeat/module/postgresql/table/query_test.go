@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package table
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestTablesQuery(t *testing.T) {
+	query := tablesQuery(120005)
+	assert.NotContains(t, query, "n_ins_since_vacuum")
+	assert.Contains(t, query, "LEFT JOIN (SELECT t.oid AS relid")
+
+	query = tablesQuery(130002)
+	assert.Contains(t, query, "s.n_ins_since_vacuum")
+}
+
+func TestSchema(t *testing.T) {
+	data, _ := schema.Apply(map[string]interface{}{
+		"datname":             "postgres",
+		"schemaname":          "public",
+		"relname":             "orders",
+		"seq_scan":            "12",
+		"seq_tup_read":        "3400",
+		"idx_scan":            "",
+		"idx_tup_fetch":       "",
+		"n_tup_ins":           "1000",
+		"n_tup_upd":           "200",
+		"n_tup_del":           "50",
+		"n_tup_hot_upd":       "150",
+		"n_live_tup":          "950",
+		"n_dead_tup":          "250",
+		"n_mod_since_analyze": "250",
+		"last_vacuum":         "",
+		"last_autovacuum":     "2024-03-01T10:00:00.123456Z",
+		"last_analyze":        "",
+		"last_autoanalyze":    "2024-03-01T10:00:01Z",
+		"vacuum_count":        "0",
+		"autovacuum_count":    "3",
+		"analyze_count":       "0",
+		"autoanalyze_count":   "4",
+		"table_size_bytes":    "131072",
+		"total_size_bytes":    "196608",
+		"bloat_bytes":         "",
+		"bloat_ratio":         "",
+	})
+
+	assert.Equal(t, "orders", data["name"])
+	assert.Equal(t, mapstr.M{"name": "postgres"}, data["database"])
+	assert.Equal(t, mapstr.M{"sequential": mapstr.M{"count": int64(12), "rows": int64(3400)}, "index": mapstr.M{}}, data["scans"])
+	assert.Equal(t, mapstr.M{"count": int64(0)}, data["vacuum"])
+	assert.Equal(t, mapstr.M{}, data["bloat"], "tables without statistics have no bloat estimation")
+
+	dead, err := data.GetValue("rows.dead")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), dead)
+}
@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package wal
+
+import (
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+// Based on: https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-WAL-VIEW
+// and https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-ARCHIVER-VIEW
+var schema = s.Schema{
+	"in_recovery": c.Bool("in_recovery"),
+	"position": s.Object{
+		"bytes": c.Int("position_bytes", s.Optional),
+	},
+	"records":          c.Int("wal_records", s.Optional),
+	"full_page_images": c.Int("wal_fpi", s.Optional),
+	"bytes":            c.Int("wal_bytes", s.Optional),
+	"buffers_full":     c.Int("wal_buffers_full", s.Optional),
+	"write": s.Object{
+		"count": c.Int("wal_write", s.Optional),
+		"time":  s.Object{"ms": c.Float("wal_write_time", s.Optional)},
+	},
+	"sync": s.Object{
+		"count": c.Int("wal_sync", s.Optional),
+		"time":  s.Object{"ms": c.Float("wal_sync_time", s.Optional)},
+	},
+	"stats_reset": c.Time(time.RFC3339Nano, "wal_stats_reset", s.Optional),
+	"archiver": s.Object{
+		"archived": s.Object{
+			"count": c.Int("archived_count"),
+			"last": s.Object{
+				"wal":  c.Str("last_archived_wal"),
+				"time": c.Time(time.RFC3339Nano, "last_archived_time", s.Optional),
+			},
+		},
+		"failed": s.Object{
+			"count": c.Int("failed_count"),
+			"last": s.Object{
+				"wal":  c.Str("last_failed_wal"),
+				"time": c.Time(time.RFC3339Nano, "last_failed_time", s.Optional),
+			},
+		},
+		"stats_reset": c.Time(time.RFC3339Nano, "archiver_stats_reset", s.Optional),
+	},
+}
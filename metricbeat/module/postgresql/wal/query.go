@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package wal
+
+import (
+	"strings"
+)
+
+const (
+	// PostgreSQL 10 renamed the xlog functions to wal.
+	version10 = 100000
+	// PostgreSQL 14 added the pg_stat_wal view.
+	version14 = 140000
+	// PostgreSQL 18 moved the write and sync statistics of pg_stat_wal to
+	// pg_stat_io.
+	version18 = 180000
+)
+
+// walQuery returns the query of the WAL and archiver statistics. The WAL
+// position of standbys is the last received position.
+func walQuery(version int) string {
+	position := "pg_wal_lsn_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END, '0/0')"
+	if version < version10 {
+		position = "pg_xlog_location_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_xlog_receive_location() ELSE pg_current_xlog_location() END, '0/0')"
+	}
+
+	columns := []string{
+		"pg_is_in_recovery() AS in_recovery",
+		position + "::bigint AS position_bytes",
+		"a.archived_count", "a.last_archived_wal", "a.last_archived_time",
+		"a.failed_count", "a.last_failed_wal", "a.last_failed_time",
+		"a.stats_reset AS archiver_stats_reset",
+	}
+	from := "pg_stat_archiver a"
+	if version >= version14 {
+		columns = append(columns,
+			"w.wal_records", "w.wal_fpi", "w.wal_bytes", "w.wal_buffers_full",
+			"w.stats_reset AS wal_stats_reset",
+		)
+		if version < version18 {
+			columns = append(columns, "w.wal_write", "w.wal_sync", "w.wal_write_time", "w.wal_sync_time")
+		}
+		from += ", pg_stat_wal w"
+	}
+
+	return "SELECT " + strings.Join(columns, ", ") + " FROM " + from
+}
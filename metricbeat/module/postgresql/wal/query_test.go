@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package wal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestWALQuery(t *testing.T) {
+	query := walQuery(90624)
+	assert.Contains(t, query, "pg_xlog_location_diff(")
+	assert.NotContains(t, query, "pg_stat_wal")
+
+	query = walQuery(130002)
+	assert.Contains(t, query, "pg_wal_lsn_diff(")
+	assert.NotContains(t, query, "pg_stat_wal")
+
+	query = walQuery(170002)
+	assert.Contains(t, query, "FROM pg_stat_archiver a, pg_stat_wal w")
+	assert.Contains(t, query, "w.wal_sync_time")
+
+	query = walQuery(180000)
+	assert.Contains(t, query, "w.wal_bytes")
+	assert.NotContains(t, query, "w.wal_sync")
+}
+
+func TestSchema(t *testing.T) {
+	data, _ := schema.Apply(map[string]interface{}{
+		"in_recovery":          "false",
+		"position_bytes":       "352321536",
+		"archived_count":       "20",
+		"last_archived_wal":    "000000010000000000000014",
+		"last_archived_time":   "2024-03-01T10:00:00.123456Z",
+		"failed_count":         "0",
+		"last_failed_wal":      "",
+		"last_failed_time":     "",
+		"archiver_stats_reset": "2024-02-01T00:00:00Z",
+		"wal_records":          "1500",
+		"wal_fpi":              "30",
+		"wal_bytes":            "204800",
+		"wal_buffers_full":     "0",
+		"wal_stats_reset":      "2024-02-01T00:00:00Z",
+	})
+
+	assert.Equal(t, false, data["in_recovery"])
+	assert.Equal(t, mapstr.M{"bytes": int64(352321536)}, data["position"])
+	assert.Equal(t, int64(204800), data["bytes"])
+	assert.Equal(t, int64(30), data["full_page_images"])
+
+	failed, err := data.GetValue("archiver.failed")
+	assert.NoError(t, err)
+	assert.Equal(t, mapstr.M{"count": int64(0), "last": mapstr.M{"wal": ""}}, failed)
+}
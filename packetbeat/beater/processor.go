@@ -133,6 +133,9 @@ type processorFactory struct {
 	beat         *beat.Beat
 	configurator func(*conf.C, *logp.Logger) (config.Config, error)
 	logger       *logp.Logger
+	// publisher is the pipeline transactions are published to. The beat
+	// publisher is used if nil.
+	publisher beat.Pipeline
 }
 
 func newProcessorFactory(name string, err chan error, beat *beat.Beat, configurator func(*conf.C, *logp.Logger) (config.Config, error)) *processorFactory {
@@ -193,9 +196,13 @@ func (p *processorFactory) create(pipeline beat.PipelineConnector, cfg *conf.C,
 		}
 	}
 
+	txPipeline := p.beat.Publisher
+	if p.publisher != nil {
+		txPipeline = p.publisher
+	}
 	publisher, err := publish.NewTransactionPublisher(
 		p.beat.Info.Name,
-		p.beat.Publisher,
+		txPipeline,
 		config.IgnoreOutgoing,
 		config.Interfaces[0].File == "",
 		config.Interfaces[0].InternalNetworks,
@@ -257,7 +264,14 @@ func setupFlows(pipeline beat.Pipeline, watch *procs.ProcessesWatcher, cfg confi
 		return nil, err
 	}
 
-	return flows.NewFlows(client.PublishAll, watch, cfg.Flows, logger)
+	f, err := flows.NewFlows(client.PublishAll, watch, cfg.Flows, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Interfaces[0].Replay {
+		f.UsePacketTime()
+	}
+	return f, nil
 }
 
 func setupSniffer(
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package beater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/publisher/pipeline"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+
+	_ "github.com/elastic/beats/v7/packetbeat/protos/dns"
+)
+
+// connectCounter counts the clients connected to a pipeline.
+type connectCounter struct {
+	beat.Pipeline
+	connects int
+}
+
+func (c *connectCounter) ConnectWith(cfg beat.ClientConfig) (beat.Client, error) {
+	c.connects++
+	return c.Pipeline.ConnectWith(cfg)
+}
+
+func TestTransactionPipeline(t *testing.T) {
+	cfg, err := config.NewConfigFrom(`
+interfaces.file: ../tests/system/pcaps/dns_mx.pcap
+flows.enabled: false
+protocols:
+- type: dns
+  ports: [53]
+`)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		replay bool
+	}{
+		{name: "live"},
+		{name: "replay", replay: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			beatPipeline := &connectCounter{Pipeline: pipeline.NewNilPipeline()}
+			runnerPipeline := &connectCounter{Pipeline: pipeline.NewNilPipeline()}
+			b := &beat.Beat{
+				Info:      beat.Info{Name: "packetbeat", Logger: logptest.NewTestingLogger(t, "")},
+				Publisher: beatPipeline,
+			}
+			factory := newProcessorFactory(b.Info.Name, make(chan error, maxSniffers), b, initialConfig().FromStatic)
+			if test.replay {
+				factory.publisher = runnerPipeline
+			}
+
+			_, publisher, _, _, _, err := factory.create(runnerPipeline, cfg, nil)
+			require.NoError(t, err)
+			defer publisher.Stop()
+
+			if test.replay {
+				require.Zero(t, beatPipeline.connects, "transactions published to the beat pipeline")
+				require.NotZero(t, runnerPipeline.connects, "transactions not published to the runner pipeline")
+			} else {
+				require.NotZero(t, beatPipeline.connects, "transactions not published to the beat pipeline")
+				require.Zero(t, runnerPipeline.connects, "transactions published to the runner pipeline")
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package beater
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common/acker"
+	"github.com/elastic/beats/v7/libbeat/esleg/eslegclient"
+	"github.com/elastic/beats/v7/libbeat/outputs/elasticsearch"
+	"github.com/elastic/beats/v7/libbeat/publisher/pipetool"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/beats/v7/packetbeat/module"
+)
+
+// Pacing values for ReplayOptions.
+const (
+	// PacingFast reads packets as fast as possible.
+	PacingFast = "fast"
+	// PacingOriginal reads packets with the delays recorded in the capture.
+	PacingOriginal = "original"
+)
+
+// ReplayOptions configures an offline replay of capture files.
+type ReplayOptions struct {
+	// Files are the pcap or pcapng files to replay, in order.
+	Files []string
+	// Pacing is PacingFast or PacingOriginal.
+	Pacing string
+	// ACKTimeout is how long to wait for the outputs to acknowledge
+	// all events after the last file has been replayed.
+	ACKTimeout time.Duration
+	// Output receives the replay summary.
+	Output io.Writer
+}
+
+// replay is a beat.Beater processing a list of capture files one after the
+// other, using packet timestamps for all events and flushing all pending
+// transactions and flows at the end of each file.
+type replay struct {
+	opts     ReplayOptions
+	config   *conf.C
+	done     chan struct{}
+	stopOnce sync.Once
+	logger   *logp.Logger
+
+	published atomic.Int64
+	acked     atomic.Int64
+}
+
+// replayResult is the outcome of replaying one file.
+type replayResult struct {
+	file     string
+	packets  uint64
+	duration time.Duration
+	err      error
+}
+
+// NewReplay returns a beat.Creator for a Packetbeat replaying the capture
+// files in opts instead of sniffing the configured interfaces.
+func NewReplay(opts ReplayOptions) beat.Creator {
+	return func(b *beat.Beat, rawConfig *conf.C) (beat.Beater, error) {
+		if b.Manager.Enabled() {
+			return nil, errors.New("replay is not supported when packetbeat is managed")
+		}
+		if len(opts.Files) == 0 {
+			return nil, errors.New("no capture files to replay")
+		}
+		switch opts.Pacing {
+		case PacingFast, PacingOriginal:
+		default:
+			return nil, fmt.Errorf("invalid pacing %q, must be %q or %q", opts.Pacing, PacingFast, PacingOriginal)
+		}
+		if opts.Output == nil {
+			opts.Output = io.Discard
+		}
+		return &replay{
+			opts:   opts,
+			config: rawConfig,
+			done:   make(chan struct{}),
+			logger: b.Info.Logger.Named("replay"),
+		}, nil
+	}
+}
+
+// Run replays all files and writes a summary to the configured output. It
+// returns an error if any of the files could not be replayed.
+func (r *replay) Run(b *beat.Beat) error {
+	if b.Config.Output.Name() == "elasticsearch" {
+		_, err := elasticsearch.RegisterConnectCallback(func(esClient *eslegclient.Connection, _ *logp.Logger) error {
+			_, err := module.UploadPipelines(b.Info, esClient, false)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		r.logger.Warn(pipelinesWarning)
+	}
+
+	pipeline := r.countingPipeline(b.Publisher)
+
+	start := time.Now()
+	var results []replayResult
+	for _, file := range r.opts.Files {
+		if r.stopped() {
+			break
+		}
+		results = append(results, r.replayFile(b, pipeline, file))
+	}
+	acked := r.waitACKs()
+	r.summarize(results, time.Since(start), acked)
+
+	var failed int
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+		}
+	}
+	switch {
+	case failed != 0:
+		return fmt.Errorf("failed to replay %d of %d files", failed, len(r.opts.Files))
+	case len(results) < len(r.opts.Files):
+		return errors.New("replay interrupted")
+	case !acked:
+		return fmt.Errorf("%d events were not acknowledged by the output", r.published.Load()-r.acked.Load())
+	}
+	return nil
+}
+
+// Stop interrupts the replay after the current file.
+func (r *replay) Stop() {
+	r.logger.Info("Packetbeat replay send stop signal")
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+func (r *replay) stopped() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// countingPipeline wraps pipeline so that all events published and acknowledged
+// by its clients are counted.
+func (r *replay) countingPipeline(pipeline beat.PipelineConnector) beat.PipelineConnector {
+	pipeline = pipetool.WithClientConfigEdit(pipeline, func(cfg beat.ClientConfig) (beat.ClientConfig, error) {
+		// ACK tracking is per client, so each connection gets its own counter.
+		counter := acker.TrackingCounter(func(_, total int) {
+			r.acked.Add(int64(total))
+		})
+		if cfg.EventListener != nil {
+			cfg.EventListener = acker.Combine(counter, cfg.EventListener)
+		} else {
+			cfg.EventListener = counter
+		}
+		return cfg, nil
+	})
+	return pipetool.WithClientWrapper(pipeline, func(client beat.Client) beat.Client {
+		return &countingClient{client: client, published: &r.published}
+	})
+}
+
+// replayFile runs a packetbeat processor on file until its end is reached.
+func (r *replay) replayFile(b *beat.Beat, pipeline beat.PipelineConnector, file string) replayResult {
+	res := replayResult{file: file}
+	r.logger.Infof("Replaying %s", file)
+
+	factory := newProcessorFactory(b.Info.Name, make(chan error, maxSniffers), b, r.configurator(file))
+	// Transactions are counted along with flows.
+	factory.publisher = pipeline
+	runner, err := factory.Create(pipeline, r.config)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	proc, _ := runner.(*processor)
+
+	start := time.Now()
+	runner.Start()
+	select {
+	case <-r.done:
+		res.err = errors.New("interrupted")
+	case res.err = <-factory.err:
+	}
+	runner.Stop()
+	res.duration = time.Since(start)
+	if proc != nil {
+		res.packets = proc.sniffer.Packets()
+	}
+	if res.err != nil {
+		r.logger.Errorf("Failed to replay %s: %v", file, res.err)
+	}
+	return res
+}
+
+// configurator returns a configurator replacing the configured interfaces by
+// file. Internal networks configured on the first interface are kept since
+// they are used to compute the network direction of events.
+func (r *replay) configurator(file string) func(*conf.C, *logp.Logger) (config.Config, error) {
+	return func(cfg *conf.C, logger *logp.Logger) (config.Config, error) {
+		c, err := initialConfig().FromStatic(cfg, logger)
+		if err != nil {
+			return c, err
+		}
+		var internal []string
+		if len(c.Interfaces) != 0 {
+			internal = c.Interfaces[0].InternalNetworks
+		}
+		c.Interfaces = []config.InterfaceConfig{{
+			File:             file,
+			Loop:             1,
+			TopSpeed:         r.opts.Pacing == PacingFast,
+			Replay:           true,
+			InternalNetworks: internal,
+		}}
+		return c, nil
+	}
+}
+
+// waitACKs waits for all published events to be acknowledged, it returns
+// false if the ACKTimeout or a stop signal occurs first.
+func (r *replay) waitACKs() bool {
+	if r.opts.ACKTimeout <= 0 {
+		return r.acked.Load() >= r.published.Load()
+	}
+	timeout := time.NewTimer(r.opts.ACKTimeout)
+	defer timeout.Stop()
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for r.acked.Load() < r.published.Load() {
+		select {
+		case <-r.done:
+			return false
+		case <-timeout.C:
+			return false
+		case <-tick.C:
+		}
+	}
+	return true
+}
+
+func (r *replay) summarize(results []replayResult, elapsed time.Duration, acked bool) {
+	w := r.opts.Output
+	var packets uint64
+	for _, res := range results {
+		packets += res.packets
+		status := "ok"
+		if res.err != nil {
+			status = "error: " + res.err.Error()
+		}
+		fmt.Fprintf(w, "%s: %d packets in %v, %s\n", res.file, res.packets, res.duration.Round(time.Millisecond), status)
+	}
+	fmt.Fprintf(w, "Replayed %d of %d files, %d packets in %v.\n", len(results), len(r.opts.Files), packets, elapsed.Round(time.Millisecond))
+	published, ackedEvents := r.published.Load(), r.acked.Load()
+	fmt.Fprintf(w, "Published %d events, %d acknowledged.\n", published, ackedEvents)
+	if !acked {
+		fmt.Fprintf(w, "Timed out waiting for %d events to be acknowledged.\n", published-ackedEvents)
+	}
+}
+
+// countingClient counts the events published through a client.
+type countingClient struct {
+	client    beat.Client
+	published *atomic.Int64
+}
+
+func (c *countingClient) Publish(event beat.Event) {
+	c.published.Add(1)
+	c.client.Publish(event)
+}
+
+func (c *countingClient) PublishAll(events []beat.Event) {
+	c.published.Add(int64(len(events)))
+	c.client.PublishAll(events)
+}
+
+func (c *countingClient) Close() error {
+	return c.client.Close()
+}
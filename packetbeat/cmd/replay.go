@@ -0,0 +1,111 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/beats/v7/libbeat/cmd/instance"
+	"github.com/elastic/beats/v7/packetbeat/beater"
+)
+
+// captureExtensions are the file extensions replayed when a directory is given.
+var captureExtensions = map[string]bool{
+	".pcap":   true,
+	".pcapng": true,
+	".cap":    true,
+}
+
+func genReplayCommand(settings instance.Settings) *cobra.Command {
+	var (
+		pacing     string
+		ackTimeout time.Duration
+	)
+	replayCmd := &cobra.Command{
+		Use:   "replay [flags] PATH...",
+		Short: "Replay pcap files and exit",
+		Long: `Replay decodes the given pcap or pcapng files, or all capture files in the
+given directories, publishes the resulting events using packet timestamps,
+and exits with a summary once all files have been processed.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			files, err := captureFiles(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error collecting capture files: %v\n", err)
+				os.Exit(1)
+			}
+			err = instance.Run(settings, beater.NewReplay(beater.ReplayOptions{
+				Files:      files,
+				Pacing:     pacing,
+				ACKTimeout: ackTimeout,
+				Output:     os.Stdout,
+			}))
+			if err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+	replayCmd.Flags().StringVar(&pacing, "pacing", beater.PacingFast,
+		fmt.Sprintf("Packet pacing, %q to read as fast as possible or %q to keep the captured delays", beater.PacingFast, beater.PacingOriginal))
+	replayCmd.Flags().DurationVar(&ackTimeout, "ack-timeout", 30*time.Second,
+		"Time to wait for the output to acknowledge all events before exiting")
+	return replayCmd
+}
+
+// captureFiles returns the capture files for paths. Files are kept as given,
+// directories are expanded to the capture files they directly contain, in
+// name order.
+func captureFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, e := range entries {
+			if e.IsDir() || !captureExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+				continue
+			}
+			found = append(found, filepath.Join(path, e.Name()))
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no capture files found in %s", path)
+		}
+		files = append(files, found...)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no capture files")
+	}
+	return files, nil
+}
@@ -0,0 +1,52 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.pcapng", "a.pcap", "c.CAP", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.pcap"), 0o755))
+	single := filepath.Join(t.TempDir(), "single.dump")
+	require.NoError(t, os.WriteFile(single, nil, 0o644))
+
+	files, err := captureFiles([]string{single, dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		single,
+		filepath.Join(dir, "a.pcap"),
+		filepath.Join(dir, "b.pcapng"),
+		filepath.Join(dir, "c.CAP"),
+	}, files)
+
+	_, err = captureFiles([]string{filepath.Join(dir, "missing.pcap")})
+	assert.Error(t, err)
+
+	_, err = captureFiles([]string{t.TempDir()})
+	assert.ErrorContains(t, err, "no capture files found")
+}
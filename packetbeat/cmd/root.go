@@ -70,6 +70,7 @@ func PacketbeatSettings(globals processors.PluginConfig) instance.Settings {
 func Initialize(settings instance.Settings) *cmd.BeatsRootCmd {
 	rootCmd := cmd.GenRootCmdWithSettings(beater.New, settings)
 	rootCmd.AddCommand(genDevicesCommand())
+	rootCmd.AddCommand(genReplayCommand(settings))
 	return rootCmd
 }
 
@@ -134,6 +134,7 @@ type InterfaceConfig struct {
 	Dumpfile              string // Dumpfile is the basename of pcap dumpfiles. The file names will have a creation time stamp and .pcap extension appended.
 	OneAtATime            bool
 	Loop                  int
+	Replay                bool // Replay keeps packet timestamps when pacing File reads and flushes pending transactions at the end of File.
 }
 
 type Flows struct {
@@ -148,8 +149,28 @@ type Flows struct {
 	// DeltaFlowReports when enabled will report flow network stats(bytes, packets) as delta values
 	EnableDeltaFlowReports bool `config:"enable_delta_flow_reports"`
 	AllowMismatchedEth     bool `config:"allow_mismatched_eth"`
+	// Export configures the exporters sending flow records to other systems.
+	Export []*conf.C `config:"export"`
+	// Classification configures the detection of the application protocol
+	// of flows from their payload.
+	Classification FlowClassification `config:"classification"`
 }
 
+// FlowClassification configures the payload based detection of the
+// application protocol of flows.
+type FlowClassification struct {
+	Enabled bool `config:"enabled"`
+	// MaxPackets is the number of packets with payload inspected per flow.
+	MaxPackets int `config:"max_packets" validate:"min=0"`
+	// Dispatch hands connections on ports no analyzer is configured for to
+	// the analyzer of the detected protocol.
+	Dispatch bool `config:"dispatch"`
+}
+
+// DefaultClassificationPackets is the number of packets with payload
+// inspected per flow when classification.max_packets is not set.
+const DefaultClassificationPackets = 4
+
 type ProtocolCommon struct {
 	Ports              []int         `config:"ports"`
 	SendRequest        bool          `config:"send_request"`
@@ -161,6 +182,18 @@ func (f *Flows) IsEnabled() bool {
 	return f != nil && (f.Enabled == nil || *f.Enabled)
 }
 
+// ClassificationPackets returns the number of packets with payload inspected
+// per flow to detect its application protocol, or 0 if detection is disabled.
+func (f *Flows) ClassificationPackets() int {
+	if !f.IsEnabled() || !f.Classification.Enabled {
+		return 0
+	}
+	if f.Classification.MaxPackets == 0 {
+		return DefaultClassificationPackets
+	}
+	return f.Classification.MaxPackets
+}
+
 func (i InterfaceConfig) Validate() error {
 	if i.Type != "af_packet" && i.FanoutGroup != nil {
 		return errFanoutGroupAFPacketOnly
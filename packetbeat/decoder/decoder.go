@@ -27,6 +27,7 @@ import (
 
 	"github.com/elastic/beats/v7/packetbeat/flows"
 	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/classify"
 	"github.com/elastic/beats/v7/packetbeat/protos/icmp"
 	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
 	"github.com/elastic/beats/v7/packetbeat/protos/udp"
@@ -69,6 +70,13 @@ type Decoder struct {
 	flowID              *flows.FlowID // buffer flowID among many calls
 	flowIDBufferBacking [flows.SizeFlowIDMax]byte
 
+	// classifyPackets is the number of packets with payload inspected per
+	// flow to detect its application protocol, 0 if detection is disabled.
+	// transport and payload hold the transport layer of the current packet.
+	classifyPackets int
+	transport       classify.Transport
+	payload         []byte
+
 	allowMismatchedEth bool
 	logger             *logp.Logger
 }
@@ -153,6 +161,25 @@ func New(
 	return &d, nil
 }
 
+// Flush has the transport processors publish all pending transactions, it is
+// called when no more packets will be decoded, e.g. at the end of a replayed file.
+func (d *Decoder) Flush() {
+	type flusher interface {
+		Flush()
+	}
+	for _, proc := range []interface{}{d.tcpProc, d.udpProc, d.icmp4Proc, d.icmp6Proc} {
+		if f, ok := proc.(flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+// ClassifyFlows enables the detection of the application protocol of flows
+// from the payload of their first maxPackets packets.
+func (d *Decoder) ClassifyFlows(maxPackets int) {
+	d.classifyPackets = maxPackets
+}
+
 func (d *Decoder) SetTruncated() {
 	d.truncated = true
 }
@@ -171,6 +198,7 @@ func (d *Decoder) AddLayers(layers []gopacket.DecodingLayer) {
 
 func (d *Decoder) OnPacket(data []byte, ci *gopacket.CaptureInfo) {
 	d.truncated = false
+	d.transport, d.payload = 0, nil
 
 	current := d.linkLayerDecoder
 	currentType := d.linkLayerType
@@ -185,6 +213,7 @@ func (d *Decoder) OnPacket(data []byte, ci *gopacket.CaptureInfo) {
 		// suppress flow stats snapshots while processing packet
 		d.flows.Lock()
 		defer d.flows.Unlock()
+		d.flows.AdvanceTime(ci.Timestamp)
 	}
 
 	d.stD1Q.i = 0
@@ -273,6 +302,12 @@ func (d *Decoder) OnPacket(data []byte, ci *gopacket.CaptureInfo) {
 		d.statPackets.Add(flow, 1)
 		////nolint:gosec // G115: safe conversion, ci.Length is the size of the original packet and is therefore always positive
 		d.statBytes.Add(flow, uint64(ci.Length))
+
+		if d.classifyPackets > 0 && len(d.payload) != 0 && flow.Inspect(d.classifyPackets) {
+			if name := classify.Payload(d.transport, d.payload); name != "" {
+				flow.SetProtocol(name)
+			}
+		}
 	}
 }
 
@@ -514,6 +549,7 @@ func (d *Decoder) onUDP(packet *protos.Packet) {
 	packet.Tuple.DstPort = dst
 	packet.Payload = d.udp.Payload
 	packet.Tuple.ComputeHashables()
+	d.transport, d.payload = classify.UDP, packet.Payload
 
 	d.udpProc.Process(id, packet)
 }
@@ -530,6 +566,7 @@ func (d *Decoder) onTCP(packet *protos.Packet) {
 	packet.Tuple.SrcPort = src
 	packet.Tuple.DstPort = dst
 	packet.Payload = d.tcp.Payload
+	d.transport, d.payload = classify.TCP, packet.Payload
 
 	if id == nil && len(packet.Payload) == 0 && !d.tcp.FIN {
 		// We have no use for this atm.
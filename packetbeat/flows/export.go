@@ -0,0 +1,193 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package flows
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Record is a snapshot of a flow handed to exporters each time the flow is
+// reported.
+type Record struct {
+	Start, End time.Time
+	// Final is set on the last report of a flow, once it timed out.
+	Final bool
+	// Delta is set when the counters hold the traffic since the previous
+	// report instead of the traffic since the flow started.
+	Delta bool
+
+	SourceMAC, DestinationMAC net.HardwareAddr
+	// VLANs holds the outer VLAN ID first.
+	VLANs []uint16
+
+	SourceIP, DestinationIP     net.IP
+	SourcePort, DestinationPort uint16
+	// Protocol is the IANA number of the transport protocol.
+	Protocol uint8
+	// ICMPType and ICMPCode are set for ICMP flows.
+	ICMPType, ICMPCode uint8
+
+	// Source and Destination count the traffic sent by each endpoint.
+	Source, Destination Counters
+}
+
+// Counters holds the traffic counted in one direction of a flow.
+type Counters struct {
+	Bytes   uint64
+	Packets uint64
+}
+
+// Exporter sends flow records to a system other than the Beats publishing
+// pipeline, like a NetFlow collector. Export is called with the records of
+// the flows reported on each reporting period, while the flow table is
+// locked, so it must not block.
+type Exporter interface {
+	Export(ts time.Time, records []Record) error
+	Close() error
+}
+
+// ExporterFactory creates an exporter from its configuration.
+type ExporterFactory func(cfg *conf.C, logger *logp.Logger) (Exporter, error)
+
+var exporterFactories = map[string]ExporterFactory{}
+
+// RegisterExporter registers an exporter type that can be enabled in the
+// export section of the flows configuration.
+func RegisterExporter(name string, factory ExporterFactory) {
+	if _, exists := exporterFactories[name]; exists {
+		panic(fmt.Sprintf("flow exporter %q already registered", name))
+	}
+	exporterFactories[name] = factory
+}
+
+// newExporters creates the exporters configured in the export section of
+// the flows configuration.
+func newExporters(cfgs []*conf.C, logger *logp.Logger) ([]Exporter, error) {
+	exporters := make([]Exporter, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		var typ struct {
+			Type string `config:"type" validate:"required"`
+		}
+		err := cfg.Unpack(&typ)
+		if err == nil {
+			factory, found := exporterFactories[typ.Type]
+			if !found {
+				err = fmt.Errorf("unknown flow exporter type %q", typ.Type)
+			} else {
+				var exporter Exporter
+				exporter, err = factory(cfg, logger.Named(typ.Type))
+				if err == nil {
+					exporters = append(exporters, exporter)
+					continue
+				}
+			}
+		}
+		for _, exporter := range exporters {
+			exporter.Close()
+		}
+		return nil, err
+	}
+	return exporters, nil
+}
+
+// closeExporters closes all exporters, returning their errors.
+func closeExporters(exporters []Exporter) error {
+	var errs []error
+	for _, exporter := range exporters {
+		errs = append(errs, exporter.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// newRecord returns the record of a flow being reported. It must be created
+// before the flow event, which resets the counters of delta reports.
+func newRecord(f *biFlow, isOver bool, uintNames []string, delta bool) Record {
+	r := Record{
+		Start: f.createTS,
+		End:   f.ts,
+		Final: isOver,
+		Delta: delta,
+	}
+
+	if src, dst, ok := f.id.EthAddr(); ok {
+		r.SourceMAC, r.DestinationMAC = net.HardwareAddr(src), net.HardwareAddr(dst)
+	}
+	if vlan := f.id.OutterVLan(); vlan != nil {
+		r.VLANs = append(r.VLANs, binary.LittleEndian.Uint16(vlan))
+	}
+	if vlan := f.id.VLan(); vlan != nil {
+		r.VLANs = append(r.VLANs, binary.LittleEndian.Uint16(vlan))
+	}
+
+	// The outer addresses identify tunneled flows, as in flow events.
+	if src, dst, ok := f.id.OutterIPv4Addr(); ok {
+		r.SourceIP, r.DestinationIP = net.IP(src), net.IP(dst)
+	} else if src, dst, ok := f.id.OutterIPv6Addr(); ok {
+		r.SourceIP, r.DestinationIP = net.IP(src), net.IP(dst)
+	} else if src, dst, ok := f.id.IPv4Addr(); ok {
+		r.SourceIP, r.DestinationIP = net.IP(src), net.IP(dst)
+	} else if src, dst, ok := f.id.IPv6Addr(); ok {
+		r.SourceIP, r.DestinationIP = net.IP(src), net.IP(dst)
+	}
+
+	if src, dst, ok := f.id.UDPAddr(); ok {
+		r.SourcePort, r.DestinationPort = binary.LittleEndian.Uint16(src), binary.LittleEndian.Uint16(dst)
+		r.Protocol = 17
+	}
+	if src, dst, ok := f.id.TCPAddr(); ok {
+		r.SourcePort, r.DestinationPort = binary.LittleEndian.Uint16(src), binary.LittleEndian.Uint16(dst)
+		r.Protocol = 6
+	}
+	if typeCode, ok := uintCounter(f.stats[0], uintNames, "icmpV4TypeCode"); ok && typeCode > 0 {
+		r.Protocol, r.ICMPType, r.ICMPCode = 1, uint8(typeCode>>8), uint8(typeCode)
+	}
+	if typeCode, ok := uintCounter(f.stats[0], uintNames, "icmpV6TypeCode"); ok && typeCode > 0 {
+		r.Protocol, r.ICMPType, r.ICMPCode = 58, uint8(typeCode>>8), uint8(typeCode)
+	}
+
+	r.Source.Bytes, _ = uintCounter(f.stats[0], uintNames, "bytes")
+	r.Source.Packets, _ = uintCounter(f.stats[0], uintNames, "packets")
+	r.Destination.Bytes, _ = uintCounter(f.stats[1], uintNames, "bytes")
+	r.Destination.Packets, _ = uintCounter(f.stats[1], uintNames, "packets")
+	return r
+}
+
+// uintCounter returns the value of a counter of one direction of a flow,
+// if it has been set.
+func uintCounter(stats *flowStats, names []string, name string) (uint64, bool) {
+	if stats == nil {
+		return 0, false
+	}
+	for i, n := range names {
+		if n != name {
+			continue
+		}
+		if i >= len(stats.uints) || stats.uintFlags[i/8]&(1<<uint(i%8)) == 0 {
+			return 0, false
+		}
+		return stats.uints[i], true
+	}
+	return 0, false
+}
@@ -0,0 +1,146 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package flows
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+type testExporter struct {
+	exports [][]Record
+	closed  bool
+}
+
+func (e *testExporter) Export(ts time.Time, records []Record) error {
+	e.exports = append(e.exports, append([]Record(nil), records...))
+	return nil
+}
+
+func (e *testExporter) Close() error {
+	e.closed = true
+	return nil
+}
+
+var lastTestExporter *testExporter
+
+func init() {
+	RegisterExporter("test", func(cfg *conf.C, logger *logp.Logger) (Exporter, error) {
+		lastTestExporter = &testExporter{}
+		return lastTestExporter, nil
+	})
+}
+
+func TestNewExporters(t *testing.T) {
+	logger := logptest.NewTestingLogger(t, "")
+	exporters, err := newExporters([]*conf.C{conf.MustNewConfigFrom(map[string]interface{}{"type": "test"})}, logger)
+	require.NoError(t, err)
+	require.Len(t, exporters, 1)
+
+	_, err = newExporters([]*conf.C{
+		conf.MustNewConfigFrom(map[string]interface{}{"type": "test"}),
+		conf.MustNewConfigFrom(map[string]interface{}{"type": "unknown"}),
+	}, logger)
+	assert.ErrorContains(t, err, `unknown flow exporter type "unknown"`)
+	assert.True(t, lastTestExporter.closed, "exporters created before the error must be closed")
+
+	_, err = newExporters([]*conf.C{conf.MustNewConfigFrom(map[string]interface{}{"hosts": []string{"localhost:4739"}})}, logger)
+	assert.Error(t, err)
+}
+
+func TestExportRecords(t *testing.T) {
+	logger := logptest.NewTestingLogger(t, "")
+	module, err := NewFlows(nil, &procs.ProcessesWatcher{}, &config.Flows{
+		EnableDeltaFlowReports: true,
+		Export:                 []*conf.C{conf.MustNewConfigFrom(map[string]interface{}{"type": "test"})},
+	}, logger)
+	require.NoError(t, err)
+	exporter := lastTestExporter
+
+	bytes, err := module.NewUint("bytes")
+	require.NoError(t, err)
+	packets, err := module.NewUint("packets")
+	require.NoError(t, err)
+
+	mac1, mac2 := []byte{1, 2, 3, 4, 5, 6}, []byte{6, 5, 4, 3, 2, 1}
+	ip1, ip2 := []byte{203, 0, 113, 3}, []byte{198, 51, 100, 2}
+	forward, reverse := newFlowID(logger), newFlowID(logger)
+	addAll(addEther(mac1, mac2), addVLan([]byte{171, 0}), addIP(ip1, ip2), addTCP([]byte{0x95, 0x97}, []byte{80, 0}))(forward)
+	addAll(addEther(mac2, mac1), addVLan([]byte{171, 0}), addIP(ip2, ip1), addTCP([]byte{80, 0}, []byte{0x95, 0x97}))(reverse)
+
+	module.Lock()
+	flow := module.Get(forward)
+	bytes.Add(flow, 10)
+	packets.Add(flow, 1)
+	flow = module.Get(reverse)
+	bytes.Add(flow, 460)
+	packets.Add(flow, 2)
+	module.Unlock()
+
+	var events []beat.Event
+	processor := &flowsProcessor{
+		table:                    module.table,
+		watcher:                  &procs.ProcessesWatcher{},
+		counters:                 module.counterReg,
+		clock:                    module.clock,
+		timeout:                  time.Minute,
+		enableDeltaFlowReporting: true,
+		exporters:                module.exporters,
+		logger:                   logger,
+	}
+	processor.spool.init(func(e []beat.Event) { events = append(events, e...) }, 1)
+	processor.execute(nil, false, true, false)
+	processor.execute(nil, false, true, false)
+
+	require.Len(t, events, 2)
+	require.Len(t, exporter.exports, 2)
+	require.Len(t, exporter.exports[0], 1)
+	r := exporter.exports[0][0]
+	assert.Equal(t, net.HardwareAddr(mac1), r.SourceMAC)
+	assert.Equal(t, net.HardwareAddr(mac2), r.DestinationMAC)
+	assert.Equal(t, []uint16{171}, r.VLANs)
+	assert.Equal(t, net.IP(ip1), r.SourceIP)
+	assert.Equal(t, net.IP(ip2), r.DestinationIP)
+	assert.Equal(t, uint16(38805), r.SourcePort)
+	assert.Equal(t, uint16(80), r.DestinationPort)
+	assert.Equal(t, uint8(6), r.Protocol)
+	assert.Equal(t, Counters{Bytes: 10, Packets: 1}, r.Source)
+	assert.Equal(t, Counters{Bytes: 460, Packets: 2}, r.Destination)
+	assert.True(t, r.Delta)
+	assert.False(t, r.Final)
+
+	// Delta counters are reset after each report.
+	assert.Equal(t, Counters{}, exporter.exports[1][0].Source)
+	assert.Equal(t, Counters{}, exporter.exports[1][0].Destination)
+
+	module.Stop()
+	assert.True(t, exporter.closed)
+}
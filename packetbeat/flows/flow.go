@@ -31,10 +31,16 @@ type biFlow struct {
 	dir        flowDirection
 	stats      [2]*flowStats
 	prev, next *biFlow
+
+	// protocol is the application protocol detected from the payload of
+	// the first inspected packets of the flow.
+	protocol  string
+	inspected int
 }
 
 type Flow struct {
 	stats *flowStats
+	bf    *biFlow
 }
 
 func newBiFlow(id rawFlowID, ts time.Time, dir flowDirection) *biFlow {
@@ -53,3 +59,22 @@ func (f *biFlow) kill() {
 func (f *biFlow) isAlive() bool {
 	return atomic.LoadUint32(&f.killed) == 0
 }
+
+// Inspect returns whether the payload of the packet being processed should
+// be inspected to detect the application protocol of the flow. It's the case
+// until the protocol is detected or max packets have been inspected.
+func (f *Flow) Inspect(max int) bool {
+	if f.bf == nil || f.bf.protocol != "" || f.bf.inspected >= max {
+		return false
+	}
+	f.bf.inspected++
+	return true
+}
+
+// SetProtocol sets the application protocol of the flow, reported as
+// network.protocol.
+func (f *Flow) SetProtocol(name string) {
+	if f.bf != nil {
+		f.bf.protocol = name
+	}
+}
@@ -18,6 +18,7 @@
 package flows
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/beats/v7/libbeat/beat"
@@ -37,11 +38,37 @@ const (
 // Flows holds and publishes network flow information for running processes.
 type Flows struct {
 	worker     *worker
+	exporters  []Exporter
 	table      *flowMetaTable
 	counterReg *counterReg
+	clock      *clock
 	logger     *logp.Logger
 }
 
+// clock is the time source of flows. It's the wall clock unless packet time is
+// enabled, in which case it's the timestamp of the latest packet seen.
+type clock struct {
+	packetTime bool
+	latest     atomic.Int64 // unix nanoseconds of the latest packet
+}
+
+func (c *clock) now() time.Time {
+	if c == nil || !c.packetTime {
+		return time.Now()
+	}
+	return time.Unix(0, c.latest.Load())
+}
+
+func (c *clock) advance(ts time.Time) {
+	if c == nil || !c.packetTime {
+		return
+	}
+	// packets may be slightly out of order, the clock never goes back
+	if ns := ts.UnixNano(); ns > c.latest.Load() {
+		c.latest.Store(ns)
+	}
+}
+
 // NewFlows returns a Flows publishing to pub after enrichment by the given
 // process watcher. Publication timeout and period are specified by config.
 func NewFlows(
@@ -70,16 +97,25 @@ func NewFlows(
 		return nil, err
 	}
 
+	clock := &clock{}
 	table := &flowMetaTable{
 		table: make(map[flowIDMeta]*flowTable),
+		clock: clock,
 	}
 	table.logger = logger
 
 	counter := &counterReg{}
 	counter.logger = logger
 
-	worker, err := newFlowsWorker(pub, watcher, table, counter, timeout, period, config.EnableDeltaFlowReports, logger)
+	exporters, err := newExporters(config.Export, logger)
 	if err != nil {
+		logger.Errorf("failed to configure flow exporters: %v", err)
+		return nil, err
+	}
+
+	worker, err := newFlowsWorker(pub, exporters, watcher, table, counter, clock, timeout, period, config.EnableDeltaFlowReports, logger)
+	if err != nil {
+		closeExporters(exporters)
 		logger.Errorf("failed to configure flows processing intervals: %v", err)
 		return nil, err
 	}
@@ -87,11 +123,26 @@ func NewFlows(
 	return &Flows{
 		table:      table,
 		worker:     worker,
+		exporters:  exporters,
 		counterReg: counter,
+		clock:      clock,
 		logger:     logger,
 	}, nil
 }
 
+// UsePacketTime makes flows use packet timestamps instead of the wall clock for
+// flow start and end times, timeouts and reports. This is used when replaying
+// captures and must be called before Start.
+func (f *Flows) UsePacketTime() {
+	f.clock.packetTime = true
+}
+
+// AdvanceTime sets the flows time to the timestamp of a packet being processed,
+// it has no effect unless UsePacketTime has been called.
+func (f *Flows) AdvanceTime(ts time.Time) {
+	f.clock.advance(ts)
+}
+
 func (f *Flows) Lock() {
 	f.logger.Debug("lock flows")
 	f.table.Lock()
@@ -117,6 +168,9 @@ func (f *Flows) Start() {
 
 func (f *Flows) Stop() {
 	f.worker.stop()
+	if err := closeExporters(f.exporters); err != nil {
+		f.logger.Errorf("failed to close flow exporters: %v", err)
+	}
 }
 
 func (f *Flows) NewInt(name string) (*Int, error) {
@@ -167,3 +167,33 @@ func TestFlowsCounting(t *testing.T) {
 	assert.Equal(t, nil, stat["float1"])
 	assert.Equal(t, 1.4142, stat["float2"])
 }
+
+func TestFlowsPacketTime(t *testing.T) {
+	module, err := NewFlows(nil, &procs.ProcessesWatcher{}, &config.Flows{}, logptest.NewTestingLogger(t, ""))
+	assert.NoError(t, err)
+	module.UsePacketTime()
+
+	// a flow ID caches its flow, use a new one per packet as the decoder does
+	packet := func(ts time.Time) {
+		id := newFlowID(logptest.NewTestingLogger(t, ""))
+		addAll(
+			addIP([]byte{127, 0, 0, 1}, []byte{128, 0, 1, 2}),
+			addTCP([]byte{0, 1}, []byte{0, 2}),
+		)(id)
+		module.AdvanceTime(ts)
+		module.Get(id)
+	}
+
+	start := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	module.Lock()
+	packet(start)
+	packet(start.Add(5 * time.Second))
+	// packets going back in time don't move the clock backwards
+	packet(start.Add(time.Second))
+	module.Unlock()
+
+	flow := module.table.tables.head.flows.head
+	assert.Equal(t, start, flow.createTS.UTC())
+	assert.Equal(t, start.Add(5*time.Second), flow.ts.UTC())
+	assert.Equal(t, start.Add(5*time.Second), module.clock.now().UTC())
+}
@@ -19,7 +19,6 @@ package flows
 
 import (
 	"sync"
-	"time"
 
 	"github.com/elastic/elastic-agent-libs/logp"
 )
@@ -39,6 +38,8 @@ type flowMetaTable struct {
 
 	tables flowTableList
 
+	clock *clock
+
 	// TODO: create snapshot of table for concurrent iteration
 	// tablesSnapshot flowTableList
 	logger *logp.Logger
@@ -56,6 +57,8 @@ type flowTable struct {
 
 	flows flowList
 
+	clock *clock
+
 	// TODO: create snapshot of table for concurrent iteration
 	// flowsSnapshot flowList
 
@@ -74,7 +77,7 @@ type flowList struct {
 func (t *flowMetaTable) get(id *FlowID, counter *counterReg) Flow {
 	sub := t.table[id.flowIDMeta]
 	if sub == nil {
-		sub = &flowTable{table: make(map[string]*biFlow), logger: t.logger}
+		sub = &flowTable{table: make(map[string]*biFlow), clock: t.clock, logger: t.logger}
 		t.table[id.flowIDMeta] = sub
 		t.tables.append(sub)
 	}
@@ -82,7 +85,7 @@ func (t *flowMetaTable) get(id *FlowID, counter *counterReg) Flow {
 }
 
 func (t *flowTable) get(id *FlowID, counter *counterReg) Flow {
-	ts := time.Now()
+	ts := t.clock.now()
 
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -105,7 +108,7 @@ func (t *flowTable) get(id *FlowID, counter *counterReg) Flow {
 		stats = newFlowStats(counter)
 		bf.stats[dir] = stats
 	}
-	return Flow{stats}
+	return Flow{stats: stats, bf: bf}
 }
 
 func (t *flowTable) remove(f *biFlow) {
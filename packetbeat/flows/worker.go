@@ -128,12 +128,14 @@ func (w *worker) periodically(tick time.Duration, fn func() error) {
 // reporting intervals specified by period. If period is less than or equal to zero
 // reporting will be done at flow lifetime end.
 // Flows are published via the pub Reporter after being enriched with process information
-// by watcher.
+// by watcher, and sent to the exporters.
 func newFlowsWorker(
 	pub Reporter,
+	exporters []Exporter,
 	watcher *procs.ProcessesWatcher,
 	table *flowMetaTable,
 	counters *counterReg,
+	clock *clock,
 	timeout, period time.Duration,
 	enableDeltaFlowReports bool,
 	logger *logp.Logger) (*worker, error) {
@@ -173,8 +175,10 @@ func newFlowsWorker(
 		table:                    table,
 		watcher:                  watcher,
 		counters:                 counters,
+		clock:                    clock,
 		timeout:                  timeout,
 		enableDeltaFlowReporting: enableDeltaFlowReports,
+		exporters:                exporters,
 		logger:                   logger,
 	}
 	processor.spool.init(pub, defaultBatchSize)
@@ -236,8 +240,11 @@ type flowsProcessor struct {
 	watcher                  *procs.ProcessesWatcher
 	table                    *flowMetaTable
 	counters                 *counterReg
+	clock                    *clock
 	timeout                  time.Duration
 	enableDeltaFlowReporting bool
+	exporters                []Exporter
+	records                  []Record
 	logger                   *logp.Logger
 }
 
@@ -257,7 +264,7 @@ func (fw *flowsProcessor) execute(w *worker, checkTimeout, handleReports, lastRe
 
 	fw.table.Lock()
 	defer fw.table.Unlock()
-	ts := time.Now()
+	ts := fw.clock.now()
 
 	// TODO: create snapshot inside flows/tables, so deletion of timed-out flows
 	//       and reporting flows stats can be done more concurrent to packet
@@ -291,9 +298,26 @@ func (fw *flowsProcessor) execute(w *worker, checkTimeout, handleReports, lastRe
 	}
 
 	fw.spool.flush()
+	fw.export(ts)
+}
+
+// export sends the records of the flows reported to the exporters.
+func (fw *flowsProcessor) export(ts time.Time) {
+	if len(fw.records) == 0 {
+		return
+	}
+	for _, exporter := range fw.exporters {
+		if err := exporter.Export(ts, fw.records); err != nil {
+			fw.logger.Warnf("failed to export flows: %v", err)
+		}
+	}
+	fw.records = fw.records[:0]
 }
 
 func (fw *flowsProcessor) report(w *worker, ts time.Time, flow *biFlow, isOver bool, intNames, uintNames, floatNames []string) {
+	if len(fw.exporters) != 0 {
+		fw.records = append(fw.records, newRecord(flow, isOver, uintNames, fw.enableDeltaFlowReporting))
+	}
 	event := createEvent(fw.watcher, ts, flow, isOver, intNames, uintNames, floatNames, fw.enableDeltaFlowReporting)
 
 	fw.logger.Debugf("add event: %v", event)
@@ -487,6 +511,9 @@ func createEvent(watcher *procs.ProcessesWatcher, ts time.Time, f *biFlow, isOve
 			network["community_id"] = hash
 		}
 	}
+	if f.protocol != "" {
+		network["protocol"] = f.protocol
+	}
 	network["bytes"] = totalBytes
 	network["packets"] = totalPackets
 	fields["network"] = network
@@ -156,3 +156,29 @@ func TestCreateEvent(t *testing.T) {
 	assert.Equal(t, expectbiFlow.stats[1].uintFlags, bif.stats[1].uintFlags)
 	assert.Equal(t, expectbiFlow.stats[1].uints, bif.stats[1].uints)
 }
+
+func TestCreateEventProtocol(t *testing.T) {
+	id := newFlowID(logptest.NewTestingLogger(t, ""))
+	id.AddIPv4([]byte{203, 0, 113, 3}, []byte{198, 51, 100, 2})
+	id.AddTCP(38901, 8443)
+
+	bif := &biFlow{id: id.rawFlowID, createTS: time.Unix(1542292881, 0), dir: flowDirForward}
+	bif.stats[0] = &flowStats{uintFlags: []uint8{1}, uints: []uint64{10}}
+	flow := Flow{stats: bif.stats[0], bf: bif}
+
+	// Payloads are inspected until the protocol is detected.
+	assert.True(t, flow.Inspect(3))
+	assert.True(t, flow.Inspect(3))
+	flow.SetProtocol("tls")
+	assert.False(t, flow.Inspect(3))
+
+	event := createEvent(&procs.ProcessesWatcher{}, time.Now(), bif, false, nil, []string{"bytes"}, nil, false)
+	protocol, err := event.Fields.GetValue("network.protocol")
+	assert.NoError(t, err)
+	assert.Equal(t, "tls", protocol)
+
+	// Or until the maximum number of packets has been inspected.
+	other := Flow{bf: &biFlow{}}
+	assert.True(t, other.Inspect(1))
+	assert.False(t, other.Inspect(1))
+}
@@ -0,0 +1,244 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package ber decodes the subset of the ASN.1 Basic Encoding Rules (ITU-T
+// X.690) used by network protocols like LDAP and Kerberos. Only definite
+// length encodings are supported, both protocols forbid the indefinite form.
+package ber
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Class is the class of a BER tag.
+type Class uint8
+
+const (
+	ClassUniversal   Class = 0
+	ClassApplication Class = 1
+	ClassContext     Class = 2
+	ClassPrivate     Class = 3
+)
+
+// Universal tags.
+const (
+	TagBoolean         = 1
+	TagInteger         = 2
+	TagBitString       = 3
+	TagOctetString     = 4
+	TagNull            = 5
+	TagEnumerated      = 10
+	TagSequence        = 16
+	TagSet             = 17
+	TagGeneralizedTime = 24
+	TagGeneralString   = 27
+)
+
+// maxLengthOctets bounds the size of the long form of the length octets.
+const maxLengthOctets = 4
+
+var (
+	// ErrTruncated is returned when the data ends before the end of an
+	// element. Protocols that stream BER elements use it to wait for more
+	// data.
+	ErrTruncated = errors.New("truncated BER element")
+
+	errIndefiniteLength = errors.New("indefinite length BER elements are not supported")
+	errLengthTooLarge   = errors.New("BER element length too large")
+	errTagTooLarge      = errors.New("BER tag number too large")
+)
+
+// Header is the identifier and length octets of an element.
+type Header struct {
+	Class       Class
+	Constructed bool
+	Tag         int
+
+	// HeaderLen is the number of bytes used by the identifier and length
+	// octets, and Length the number of content bytes that follow them.
+	HeaderLen int
+	Length    int
+}
+
+// Size returns the encoded size of the element.
+func (h Header) Size() int {
+	return h.HeaderLen + h.Length
+}
+
+// Element is a decoded element.
+type Element struct {
+	Header
+	// Content holds the content octets of the element. For constructed
+	// elements they hold the encoding of the nested elements.
+	Content []byte
+}
+
+// ReadHeader decodes the identifier and length octets at the start of data.
+// It doesn't require the content octets to be present.
+func ReadHeader(data []byte) (Header, error) {
+	var h Header
+	if len(data) < 2 {
+		return h, ErrTruncated
+	}
+	h.Class = Class(data[0] >> 6)
+	h.Constructed = data[0]&0x20 != 0
+	h.Tag = int(data[0] & 0x1f)
+	pos := 1
+	if h.Tag == 0x1f {
+		// High tag number form.
+		h.Tag = 0
+		for {
+			if pos >= len(data) {
+				return h, ErrTruncated
+			}
+			if h.Tag >= 1<<23 {
+				return h, errTagTooLarge
+			}
+			b := data[pos]
+			pos++
+			h.Tag = h.Tag<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	if pos >= len(data) {
+		return h, ErrTruncated
+	}
+	b := data[pos]
+	pos++
+	switch {
+	case b < 0x80:
+		h.Length = int(b)
+	case b == 0x80:
+		return h, errIndefiniteLength
+	default:
+		n := int(b & 0x7f)
+		if n > maxLengthOctets {
+			return h, errLengthTooLarge
+		}
+		if pos+n > len(data) {
+			return h, ErrTruncated
+		}
+		for _, b := range data[pos : pos+n] {
+			h.Length = h.Length<<8 | int(b)
+		}
+		pos += n
+		if h.Length < 0 || h.Length > 1<<30 {
+			return h, errLengthTooLarge
+		}
+	}
+	h.HeaderLen = pos
+	return h, nil
+}
+
+// Decode decodes the element at the start of data and returns the data
+// that follows it.
+func Decode(data []byte) (Element, []byte, error) {
+	h, err := ReadHeader(data)
+	if err != nil {
+		return Element{}, nil, err
+	}
+	if h.Size() > len(data) {
+		return Element{}, nil, ErrTruncated
+	}
+	e := Element{Header: h, Content: data[h.HeaderLen:h.Size()]}
+	return e, data[h.Size():], nil
+}
+
+// Is returns whether the element has the given class and tag.
+func (e Element) Is(class Class, tag int) bool {
+	return e.Class == class && e.Tag == tag
+}
+
+// IsUniversal returns whether the element has the given universal tag.
+func (e Element) IsUniversal(tag int) bool {
+	return e.Is(ClassUniversal, tag)
+}
+
+// Children decodes the elements nested in a constructed element.
+func (e Element) Children() ([]Element, error) {
+	if !e.Constructed {
+		return nil, fmt.Errorf("BER element [%d] %d is not constructed", e.Class, e.Tag)
+	}
+	var children []Element
+	for data := e.Content; len(data) > 0; {
+		var (
+			child Element
+			err   error
+		)
+		child, data, err = Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// Explicit returns the element wrapped by an explicitly tagged element.
+func (e Element) Explicit() (Element, error) {
+	if !e.Constructed {
+		return Element{}, fmt.Errorf("BER element [%d] %d is not constructed", e.Class, e.Tag)
+	}
+	inner, rest, err := Decode(e.Content)
+	if err != nil {
+		return Element{}, err
+	}
+	if len(rest) != 0 {
+		return Element{}, errors.New("trailing data after explicitly tagged BER element")
+	}
+	return inner, nil
+}
+
+// Int decodes the content of an INTEGER or ENUMERATED element.
+func (e Element) Int() (int64, error) {
+	if e.Constructed || len(e.Content) == 0 || len(e.Content) > 8 {
+		return 0, fmt.Errorf("invalid BER integer of %d bytes", len(e.Content))
+	}
+	// Sign extend the first byte.
+	v := int64(int8(e.Content[0]))
+	for _, b := range e.Content[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// Bool decodes the content of a BOOLEAN element.
+func (e Element) Bool() (bool, error) {
+	if e.Constructed || len(e.Content) != 1 {
+		return false, fmt.Errorf("invalid BER boolean of %d bytes", len(e.Content))
+	}
+	return e.Content[0] != 0, nil
+}
+
+// String returns the content of a primitive string element.
+func (e Element) String() string {
+	return string(e.Content)
+}
+
+// BitString decodes the content of a BIT STRING element, returning the
+// value of the bits, the first bit being the most significant bit of the
+// first byte.
+func (e Element) BitString() ([]byte, error) {
+	if e.Constructed || len(e.Content) == 0 || e.Content[0] > 7 {
+		return nil, errors.New("invalid BER bit string")
+	}
+	return e.Content[1:], nil
+}
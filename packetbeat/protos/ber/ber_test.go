@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeader(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		header Header
+		err    error
+	}{
+		{
+			name:   "short length",
+			data:   []byte{0x30, 0x03},
+			header: Header{Class: ClassUniversal, Constructed: true, Tag: TagSequence, HeaderLen: 2, Length: 3},
+		},
+		{
+			name:   "long length",
+			data:   []byte{0x04, 0x82, 0x01, 0x00},
+			header: Header{Class: ClassUniversal, Tag: TagOctetString, HeaderLen: 4, Length: 256},
+		},
+		{
+			name:   "non-minimal long length",
+			data:   []byte{0x0a, 0x84, 0x00, 0x00, 0x00, 0x01},
+			header: Header{Class: ClassUniversal, Tag: TagEnumerated, HeaderLen: 6, Length: 1},
+		},
+		{
+			name:   "application tag",
+			data:   []byte{0x63, 0x00},
+			header: Header{Class: ClassApplication, Constructed: true, Tag: 3, HeaderLen: 2},
+		},
+		{
+			name:   "high tag number",
+			data:   []byte{0x9f, 0x81, 0x00, 0x01},
+			header: Header{Class: ClassContext, Tag: 128, HeaderLen: 4, Length: 1},
+		},
+		{
+			name: "truncated length",
+			data: []byte{0x04, 0x82, 0x01},
+			err:  ErrTruncated,
+		},
+		{
+			name: "truncated tag",
+			data: []byte{0x1f, 0x81},
+			err:  ErrTruncated,
+		},
+		{
+			name: "indefinite length",
+			data: []byte{0x30, 0x80},
+			err:  errIndefiniteLength,
+		},
+		{
+			name: "length too large",
+			data: []byte{0x30, 0x85, 0x01, 0x00, 0x00, 0x00, 0x00},
+			err:  errLengthTooLarge,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := ReadHeader(test.data)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.header, h)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// SEQUENCE { INTEGER 5, [1] { OCTET STRING "ab" }, BOOLEAN TRUE } followed by
+	// a NULL.
+	data := []byte{
+		0x30, 0x0c,
+		0x02, 0x01, 0x05,
+		0xa1, 0x04, 0x04, 0x02, 'a', 'b',
+		0x01, 0x01, 0xff,
+		0x05, 0x00,
+	}
+	seq, rest, err := Decode(data)
+	require.NoError(t, err)
+	assert.True(t, seq.IsUniversal(TagSequence))
+	assert.Equal(t, []byte{0x05, 0x00}, rest)
+
+	children, err := seq.Children()
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+
+	v, err := children[0].Int()
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, v)
+
+	assert.True(t, children[1].Is(ClassContext, 1))
+	inner, err := children[1].Explicit()
+	require.NoError(t, err)
+	assert.Equal(t, "ab", inner.String())
+
+	b, err := children[2].Bool()
+	require.NoError(t, err)
+	assert.True(t, b)
+
+	_, err = children[0].Children()
+	assert.Error(t, err)
+
+	_, _, err = Decode(data[:8])
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestInt(t *testing.T) {
+	for _, test := range []struct {
+		content []byte
+		value   int64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x7f}, 127},
+		{[]byte{0x00, 0x80}, 128},
+		{[]byte{0xff}, -1},
+		{[]byte{0xff, 0x7f}, -129},
+		{[]byte{0x00, 0xff, 0xff, 0xff, 0xff}, 0xffffffff},
+	} {
+		v, err := Element{Content: test.content}.Int()
+		require.NoError(t, err)
+		assert.Equal(t, test.value, v, "% x", test.content)
+	}
+
+	_, err := Element{}.Int()
+	assert.Error(t, err)
+	_, err = Element{Content: make([]byte, 9)}.Int()
+	assert.Error(t, err)
+}
+
+func TestBitString(t *testing.T) {
+	bits, err := Element{Content: []byte{0x00, 0x40, 0x81, 0x00, 0x10}}.BitString()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x40, 0x81, 0x00, 0x10}, bits)
+
+	_, err = Element{Content: []byte{0x08, 0x00}}.BitString()
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package bertest encodes BER elements for protocol tests.
+package bertest
+
+// TLV encodes an element with the given identifier octet and the
+// concatenation of parts as its content, using the definite length form.
+func TLV(tag byte, parts ...[]byte) []byte {
+	var content []byte
+	for _, part := range parts {
+		content = append(content, part...)
+	}
+	out := []byte{tag}
+	switch n := len(content); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	case n < 0x10000:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, content...)
+}
+
+// Seq encodes a SEQUENCE of parts.
+func Seq(parts ...[]byte) []byte { return TLV(0x30, parts...) }
+
+// Set encodes a SET of parts.
+func Set(parts ...[]byte) []byte { return TLV(0x31, parts...) }
+
+// Str encodes an OCTET STRING.
+func Str(s string) []byte { return TLV(0x04, []byte(s)) }
+
+// Boolean encodes a BOOLEAN.
+func Boolean(b bool) []byte {
+	if b {
+		return TLV(0x01, []byte{0xff})
+	}
+	return TLV(0x01, []byte{0})
+}
+
+// Integer encodes an INTEGER.
+func Integer(v int64) []byte { return TLV(0x02, IntBytes(v)) }
+
+// Enumerated encodes an ENUMERATED.
+func Enumerated(v int64) []byte { return TLV(0x0a, IntBytes(v)) }
+
+// IntBytes returns the minimal two's complement content octets of v.
+func IntBytes(v int64) []byte {
+	out := []byte{byte(v)}
+	for v >>= 8; v != 0 && v != -1; v >>= 8 {
+		out = append([]byte{byte(v)}, out...)
+	}
+	if (v == 0 && out[0]&0x80 != 0) || (v == -1 && out[0]&0x80 == 0) {
+		out = append([]byte{byte(v)}, out...)
+	}
+	return out
+}
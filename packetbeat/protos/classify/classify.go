@@ -0,0 +1,359 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package classify detects the application protocol of a flow from the
+// payload of its first packets, regardless of the ports in use. Detection
+// relies on signatures matching the start of the first message each peer
+// sends, so it's meant to be applied to the first packets of a flow only.
+package classify
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+)
+
+// Transport is the transport protocol a payload was received with.
+type Transport uint8
+
+const (
+	TCP Transport = iota + 1
+	UDP
+)
+
+// signature matches the payload of the first messages of a protocol.
+type signature struct {
+	name      string
+	transport Transport
+	match     func(payload []byte) bool
+
+	// portBound is set for protocols whose analyzer tells clients and
+	// servers apart by the configured ports, so it can't be handed
+	// connections on other ports.
+	portBound bool
+}
+
+// signatures are tried in order, the most specific ones first. The names
+// are the ones of the protocol analyzers, so they can be looked up in the
+// protocols registry.
+var signatures = []signature{
+	{name: "tls", transport: TCP, match: isTLS},
+	{name: "ssh", transport: TCP, match: isSSH},
+	{name: "http2", transport: TCP, match: isHTTP2},
+	{name: "http", transport: TCP, match: isHTTP},
+	{name: "sip", transport: TCP, match: isSIP},
+	{name: "sip", transport: UDP, match: isSIP},
+	{name: "rdp", transport: TCP, match: isRDP},
+	{name: "smb", transport: TCP, match: isSMB},
+	{name: "amqp", transport: TCP, match: isAMQP},
+	{name: "redis", transport: TCP, match: isRedis},
+	{name: "pgsql", transport: TCP, match: isPgsql},
+	{name: "mysql", transport: TCP, match: isMySQL, portBound: true},
+	{name: "mongodb", transport: TCP, match: isMongoDB},
+	{name: "nfs", transport: TCP, match: isNFS},
+	{name: "kerberos", transport: TCP, match: isKerberosTCP},
+	{name: "kerberos", transport: UDP, match: isKerberos},
+	{name: "ldap", transport: TCP, match: isLDAP, portBound: true},
+	{name: "ldap", transport: UDP, match: isLDAP, portBound: true},
+	{name: "dns", transport: TCP, match: isDNSTCP},
+	{name: "dns", transport: UDP, match: isDNS},
+	{name: "dhcpv4", transport: UDP, match: isDHCPv4},
+	{name: "quic", transport: UDP, match: isQUIC},
+}
+
+// Payload returns the name of the application protocol of a packet's
+// payload, or an empty string if it's not recognized.
+func Payload(transport Transport, payload []byte) string {
+	if sig := lookup(transport, payload); sig != nil {
+		return sig.name
+	}
+	return ""
+}
+
+// Dispatchable returns the name of the application protocol of a packet's
+// payload if the connection can be handed to the protocol's analyzer even
+// though it uses ports the analyzer is not configured for.
+func Dispatchable(transport Transport, payload []byte) string {
+	if sig := lookup(transport, payload); sig != nil && !sig.portBound {
+		return sig.name
+	}
+	return ""
+}
+
+func lookup(transport Transport, payload []byte) *signature {
+	if len(payload) == 0 {
+		return nil
+	}
+	for i := range signatures {
+		sig := &signatures[i]
+		if sig.transport == transport && sig.match(payload) {
+			return sig
+		}
+	}
+	return nil
+}
+
+// isTLS matches the record carrying a ClientHello or ServerHello.
+func isTLS(p []byte) bool {
+	return len(p) >= 6 &&
+		p[0] == 0x16 && // handshake
+		p[1] == 3 && p[2] <= 4 &&
+		(p[5] == 1 || p[5] == 2)
+}
+
+// isSSH matches the identification string each side sends first.
+func isSSH(p []byte) bool {
+	return bytes.HasPrefix(p, []byte("SSH-2.0-")) || bytes.HasPrefix(p, []byte("SSH-1."))
+}
+
+// isHTTP2 matches the client connection preface of HTTP/2 with prior
+// knowledge.
+func isHTTP2(p []byte) bool {
+	return bytes.HasPrefix(p, []byte("PRI * HTTP/2.0\r\n"))
+}
+
+var httpMethods = [][]byte{
+	[]byte("GET "), []byte("POST "), []byte("PUT "), []byte("HEAD "),
+	[]byte("DELETE "), []byte("OPTIONS "), []byte("PATCH "),
+	[]byte("CONNECT "), []byte("TRACE "),
+}
+
+// isHTTP matches the request line of an HTTP/1.x request or the status line
+// of a response.
+func isHTTP(p []byte) bool {
+	if bytes.HasPrefix(p, []byte("HTTP/1.")) {
+		return true
+	}
+	for _, m := range httpMethods {
+		if bytes.HasPrefix(p, m) {
+			line, _, _ := bytes.Cut(p[len(m):], []byte("\r\n"))
+			return bytes.Contains(line, []byte(" HTTP/1."))
+		}
+	}
+	return false
+}
+
+// isSIP matches the start line of SIP requests and responses.
+func isSIP(p []byte) bool {
+	if bytes.HasPrefix(p, []byte("SIP/2.0 ")) {
+		return true
+	}
+	line, _, found := bytes.Cut(p, []byte("\r\n"))
+	return found && bytes.HasSuffix(line, []byte(" SIP/2.0")) && bytes.Contains(line, []byte(" sip:"))
+}
+
+// isRDP matches the X.224 connection request or confirm, carried in a TPKT,
+// that starts RDP connections.
+func isRDP(p []byte) bool {
+	return len(p) >= 11 &&
+		p[0] == 3 && p[1] == 0 && // TPKT version 3
+		int(binary.BigEndian.Uint16(p[2:])) == len(p) &&
+		int(p[4]) == len(p)-5 &&
+		(p[5] == 0xe0 || p[5] == 0xd0)
+}
+
+// isSMB matches SMB messages carried in a NetBIOS session message.
+func isSMB(p []byte) bool {
+	if len(p) < 8 || p[0] != 0 {
+		return false
+	}
+	switch string(p[5:8]) {
+	case "SMB":
+		// SMB2 and SMB1 headers, and SMB3 transform and compression headers.
+		return p[4] == 0xfe || p[4] == 0xff || p[4] == 0xfd || p[4] == 0xfc
+	}
+	return false
+}
+
+// isAMQP matches the protocol header clients send first.
+func isAMQP(p []byte) bool {
+	return len(p) == 8 && bytes.HasPrefix(p, []byte("AMQP"))
+}
+
+// isRedis matches a command sent as a RESP array of bulk strings.
+func isRedis(p []byte) bool {
+	if len(p) < 4 || p[0] != '*' {
+		return false
+	}
+	i := 1
+	for i < len(p) && i < 8 && p[i] >= '0' && p[i] <= '9' {
+		i++
+	}
+	return i > 1 && bytes.HasPrefix(p[i:], []byte("\r\n$"))
+}
+
+// isPgsql matches the startup message or the SSL and GSSAPI encryption
+// requests that open a PostgreSQL connection.
+func isPgsql(p []byte) bool {
+	if len(p) < 8 || int(binary.BigEndian.Uint32(p)) != len(p) {
+		return false
+	}
+	switch binary.BigEndian.Uint32(p[4:]) {
+	case 0x00030000: // protocol 3.0
+		return len(p) > 8
+	case 80877103, 80877104: // SSLRequest, GSSENCRequest
+		return len(p) == 8
+	}
+	return false
+}
+
+// isMySQL matches the initial handshake packet sent by servers.
+func isMySQL(p []byte) bool {
+	if len(p) < 10 || p[3] != 0 || p[4] != 10 {
+		return false
+	}
+	length := int(p[0]) | int(p[1])<<8 | int(p[2])<<16
+	if length != len(p)-4 {
+		return false
+	}
+	// The version string is printable and NUL terminated.
+	end := bytes.IndexByte(p[5:], 0)
+	if end <= 0 {
+		return false
+	}
+	for _, c := range p[5 : 5+end] {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// isMongoDB matches the header of the OP_MSG and OP_QUERY requests.
+func isMongoDB(p []byte) bool {
+	if len(p) < 21 {
+		return false
+	}
+	if length := int(binary.LittleEndian.Uint32(p)); length < len(p) || length > 48<<20 {
+		return false
+	}
+	responseTo := binary.LittleEndian.Uint32(p[8:])
+	switch binary.LittleEndian.Uint32(p[12:]) {
+	case 2013, 2004: // OP_MSG, OP_QUERY
+		return responseTo == 0
+	}
+	return false
+}
+
+// isNFS matches ONC RPC calls to the NFS program, with the record marking
+// used over TCP.
+func isNFS(p []byte) bool {
+	return len(p) >= 24 &&
+		p[0]&0x80 != 0 && // last fragment
+		binary.BigEndian.Uint32(p[8:]) == 0 && // CALL
+		binary.BigEndian.Uint32(p[12:]) == 2 && // RPC version
+		binary.BigEndian.Uint32(p[16:]) == 100003 // NFS program
+}
+
+// isKerberosTCP matches Kerberos messages preceded by their length, as sent
+// over TCP.
+func isKerberosTCP(p []byte) bool {
+	return len(p) > 4 &&
+		int(binary.BigEndian.Uint32(p)) >= len(p)-4 &&
+		isKerberos(p[4:])
+}
+
+// isKerberos matches the AS and TGS exchanges and KRB-ERROR messages.
+func isKerberos(p []byte) bool {
+	h, err := ber.ReadHeader(p)
+	if err != nil || h.Class != ber.ClassApplication || !h.Constructed {
+		return false
+	}
+	switch h.Tag {
+	case 10, 11, 12, 13, 30: // AS-REQ, AS-REP, TGS-REQ, TGS-REP, KRB-ERROR
+	default:
+		return false
+	}
+	seq, err := ber.ReadHeader(p[h.HeaderLen:])
+	return err == nil && seq.Class == ber.ClassUniversal && seq.Tag == ber.TagSequence
+}
+
+// isLDAP matches an LDAPMessage: a sequence starting with the message ID and
+// followed by the protocol operation.
+func isLDAP(p []byte) bool {
+	h, err := ber.ReadHeader(p)
+	if err != nil || h.Class != ber.ClassUniversal || h.Tag != ber.TagSequence || !h.Constructed {
+		return false
+	}
+	p = p[h.HeaderLen:]
+	id, err := ber.ReadHeader(p)
+	if err != nil || id.Class != ber.ClassUniversal || id.Tag != ber.TagInteger ||
+		id.Length < 1 || id.Length > 4 || len(p) < id.Size() {
+		return false
+	}
+	op, err := ber.ReadHeader(p[id.Size():])
+	return err == nil && op.Class == ber.ClassApplication && op.Tag <= 25
+}
+
+// isDNSTCP matches DNS messages preceded by their length, as sent over TCP.
+func isDNSTCP(p []byte) bool {
+	return len(p) > 2 &&
+		int(binary.BigEndian.Uint16(p)) >= len(p)-2 &&
+		isDNS(p[2:])
+}
+
+// isDNS matches standard queries and their responses, that hold a single
+// question.
+func isDNS(p []byte) bool {
+	if len(p) < 17 {
+		return false
+	}
+	flags := binary.BigEndian.Uint16(p[2:])
+	if opcode := flags >> 11 & 0xf; opcode != 0 || flags&0x0040 != 0 { // Z bit
+		return false
+	}
+	if binary.BigEndian.Uint16(p[4:]) != 1 {
+		return false
+	}
+	isQuery := flags&0x8000 == 0
+	if isQuery && (binary.BigEndian.Uint16(p[6:]) != 0 || binary.BigEndian.Uint16(p[8:]) != 0) {
+		return false
+	}
+	// The question name is a sequence of labels ending with the root.
+	pos := 12
+	for pos < len(p) {
+		n := int(p[pos])
+		if n == 0 {
+			return pos+5 <= len(p)
+		}
+		if n > 63 {
+			return false
+		}
+		pos += n + 1
+	}
+	return false
+}
+
+// isDHCPv4 matches BOOTP messages carrying the DHCP magic cookie.
+func isDHCPv4(p []byte) bool {
+	return len(p) >= 240 &&
+		(p[0] == 1 || p[0] == 2) &&
+		binary.BigEndian.Uint32(p[236:]) == 0x63825363
+}
+
+// isQUIC matches long header packets of QUIC versions 1 and 2.
+func isQUIC(p []byte) bool {
+	if len(p) < 7 || p[0]&0xc0 != 0xc0 {
+		return false
+	}
+	switch binary.BigEndian.Uint32(p[1:]) {
+	case 0x00000001, 0x6b3343cf:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package classify
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPayload(t *testing.T) {
+	dhcp := make([]byte, 300)
+	dhcp[0], dhcp[1], dhcp[2] = 1, 1, 6
+	copy(dhcp[236:], []byte{0x63, 0x82, 0x53, 0x63})
+
+	tests := []struct {
+		name      string
+		transport Transport
+		payload   []byte
+		want      string
+	}{
+		{
+			name:      "tls client hello",
+			transport: TCP,
+			payload:   unhex(t, "16 0301 00c8 01 0000c4 0303"),
+			want:      "tls",
+		},
+		{
+			name:      "tls server hello",
+			transport: TCP,
+			payload:   unhex(t, "16 0303 0059 02 000055 0303"),
+			want:      "tls",
+		},
+		{
+			name:      "tls application data",
+			transport: TCP,
+			payload:   unhex(t, "17 0303 0020 0102030405"),
+		},
+		{
+			name:      "ssh",
+			transport: TCP,
+			payload:   []byte("SSH-2.0-OpenSSH_9.6\r\n"),
+			want:      "ssh",
+		},
+		{
+			name:      "http request",
+			transport: TCP,
+			payload:   []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n"),
+			want:      "http",
+		},
+		{
+			name:      "http response",
+			transport: TCP,
+			payload:   []byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"),
+			want:      "http",
+		},
+		{
+			name:      "not http",
+			transport: TCP,
+			payload:   []byte("GET key\r\n"),
+		},
+		{
+			name:      "http2 preface",
+			transport: TCP,
+			payload:   []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"),
+			want:      "http2",
+		},
+		{
+			name:      "sip request",
+			transport: UDP,
+			payload:   []byte("INVITE sip:bob@example.com SIP/2.0\r\nVia: SIP/2.0/UDP host\r\n\r\n"),
+			want:      "sip",
+		},
+		{
+			name:      "sip response",
+			transport: TCP,
+			payload:   []byte("SIP/2.0 180 Ringing\r\n\r\n"),
+			want:      "sip",
+		},
+		{
+			name:      "rdp connection request",
+			transport: TCP,
+			payload:   unhex(t, "0300 0013 0e e0 0000 0000 00 01 00 0800 03000000"),
+			want:      "rdp",
+		},
+		{
+			name:      "smb2",
+			transport: TCP,
+			payload:   unhex(t, "00 0000b0 fe534d42 4000"),
+			want:      "smb",
+		},
+		{
+			name:      "smb1 negotiate",
+			transport: TCP,
+			payload:   unhex(t, "00 000054 ff534d42 72"),
+			want:      "smb",
+		},
+		{
+			name:      "amqp",
+			transport: TCP,
+			payload:   []byte("AMQP\x00\x00\x09\x01"),
+			want:      "amqp",
+		},
+		{
+			name:      "redis",
+			transport: TCP,
+			payload:   []byte("*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n"),
+			want:      "redis",
+		},
+		{
+			name:      "pgsql startup",
+			transport: TCP,
+			payload:   unhex(t, "00000013 00030000 7573657200 706f737400 00"),
+			want:      "pgsql",
+		},
+		{
+			name:      "pgsql ssl request",
+			transport: TCP,
+			payload:   unhex(t, "00000008 04d2162f"),
+			want:      "pgsql",
+		},
+		{
+			name:      "mysql greeting",
+			transport: TCP,
+			payload:   unhex(t, "0c0000 00 0a 382e302e333600 01000000"),
+			want:      "mysql",
+		},
+		{
+			name:      "mongodb op_msg",
+			transport: TCP,
+			payload:   unhex(t, "2d000000 01000000 00000000 dd070000 00000000 00 18000000"),
+			want:      "mongodb",
+		},
+		{
+			name:      "nfs call",
+			transport: TCP,
+			payload:   unhex(t, "80000028 12345678 00000000 00000002 000186a3 00000004"),
+			want:      "nfs",
+		},
+		{
+			name:      "kerberos as-req over tcp",
+			transport: TCP,
+			payload:   unhex(t, "00000010 6a0e 300c a103020105 a20302010a"),
+			want:      "kerberos",
+		},
+		{
+			name:      "kerberos as-req over udp",
+			transport: UDP,
+			payload:   unhex(t, "6a0e 300c a103020105 a20302010a"),
+			want:      "kerberos",
+		},
+		{
+			name:      "ldap bind request",
+			transport: TCP,
+			payload:   unhex(t, "300c 020101 6007 020103 0400 8000"),
+			want:      "ldap",
+		},
+		{
+			name:      "dns query",
+			transport: UDP,
+			payload:   unhex(t, "abcd 0100 0001 0000 0000 0000 076578616d706c6503636f6d00 0001 0001"),
+			want:      "dns",
+		},
+		{
+			name:      "dns over tcp",
+			transport: TCP,
+			payload:   unhex(t, "001d abcd 0100 0001 0000 0000 0000 076578616d706c6503636f6d00 0001 0001"),
+			want:      "dns",
+		},
+		{
+			name:      "truncated dns name",
+			transport: UDP,
+			payload:   unhex(t, "abcd 0100 0001 0000 0000 0000 076578616d706c65"),
+		},
+		{
+			name:      "dhcp",
+			transport: UDP,
+			payload:   dhcp,
+			want:      "dhcpv4",
+		},
+		{
+			name:      "quic initial",
+			transport: UDP,
+			payload:   unhex(t, "c3 00000001 08 0102030405060708 00"),
+			want:      "quic",
+		},
+		{
+			name:      "wrong transport",
+			transport: UDP,
+			payload:   []byte("SSH-2.0-OpenSSH_9.6\r\n"),
+		},
+		{
+			name:      "random",
+			transport: TCP,
+			payload:   unhex(t, "8badf00d deadbeef 0000 1111"),
+		},
+		{
+			name:      "empty",
+			transport: TCP,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Payload(test.transport, test.payload))
+		})
+	}
+}
+
+func TestDispatchable(t *testing.T) {
+	http := []byte("GET / HTTP/1.1\r\n\r\n")
+	assert.Equal(t, "http", Dispatchable(TCP, http))
+
+	// The MySQL analyzer identifies servers by their port.
+	mysql := unhex(t, "0c0000 00 0a 382e302e333600 01000000")
+	assert.Equal(t, "mysql", Payload(TCP, mysql))
+	assert.Equal(t, "", Dispatchable(TCP, mysql))
+}
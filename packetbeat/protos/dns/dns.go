@@ -298,6 +298,11 @@ func (dns *dnsPlugin) Close() {
 	dns.transactions.StopJanitor()
 }
 
+// Flush publishes all unanswered requests.
+func (dns *dnsPlugin) Flush() {
+	dns.transactions.Flush()
+}
+
 func (dns *dnsPlugin) GetPorts() []int {
 	return dns.ports
 }
@@ -73,6 +73,9 @@ type ProtocolFields struct {
 	// HTTP request headers.
 	RequestHeaders mapstr.M `packetbeat:"request.headers"`
 
+	// JA4H fingerprint of the HTTP request.
+	RequestJA4H string `packetbeat:"request.ja4h"`
+
 	// HTTP response headers.
 	ResponseHeaders mapstr.M `packetbeat:"response.headers"`
 
@@ -32,5 +32,5 @@ func init() {
 // AssetHttp returns asset data.
 // This is the base64 encoded zlib format compressed contents of protos/http.
 func AssetHttp() string {
-	return "eJzUVMuOlUAQ3fMVJ7N2+AAWJm6MszEubuJyUkAB7dCP6a72yt+b5nEdoKMxNy5kBfU451T1aR7xwlOFQcQVgCgZucLDp8vly0MBtBwar5woayqk4GNw3KhONeDvbASd4rENZYH1rSoA4BGGNN9Q0yOT4wq9t3GL7LCfTGe9pvQBqm0UyMAzIzy/Rg4CMi08B2dN4HLFeEv6lnjtucUzk2RqzhpzHLsBmVr2YZfbcGz9jRs5pJbg81LxwtPV+vZQslP6/pAEPkCTQ2ONkDLK9POiGnISPberoFUzOm/1nF9nLU9oXwfVDNsYELshQYXE0ak+eqpHLvHU3cquSoYZNpDmE+QqIS0I5BnOc0hWUWbu0RwC9fwufUy4qnFEzQjsyJNwi3o6ITZWawplkT2C1KfzJ0CjomNGq97Tslvx8ajekQwVoh/L18h+KjLGWgz4B2ediv7eWkFIYnh2g6dwlLmjvGwXZenA0nE8av5B2qW7/dkKPtpo2vw6/x9H7/8Ev567LH1CSxa/09InzHr6vaUb2x6F3Gfo9B8ubxtbnTWz5K9UznP/QMHK8zMAAP//lgPSsQ=="
+	return "eJzUVTlv20wQ7fkrBq4lwjZkfRKLD3AT2EEQpDCQUhjuDsm1uYf3sMN/Hyy1FMTDBpI4RaBGmp15b86nNTxRV0DjvckAvPAtFXBx9/Dw7SID4OSYFcYLrQqIxrUzxEQlGNALKQ+VoJa7PIP0rcgAANagUNIJNZp8Z6iA2uowWEbY96rSVmIkAix18OAb6hnB0nMg5wEVB0vOaOUoTxjnpOfEKeZkX6hkwWee4xLHqEBCTtaN3gYcXT4SO4ePn6PxcPR4ou5VWz5xGWX6/+QR4BYkGmBaeRRKqLpvFEPjgyWeEko5Q2W17N9TrXk2ggKA741gzVAGeD0ggXCRoxJ1sFi2lMN9dXJ7Fb7pYR1KmkGmFGKDAC2BseTiqgjVx0hyDmtaxR8dvIq2hZLAkUGLnjiU3QyRaSnR5dniCGKcXJ4AtgKnL1LUtl+zArwN0+wN+qaAYNv8OZDtlhkfcdNM4v5gnp9vN3dQCVWTNVYoD7o6H9kKmJYmxM6cpinJN5qvZlAvZJ3QapUG1TfIRQD9JMj1B3TLGBm//oKqDlhT8pzuBf1AaaIQ1HR1xezlllRwB9zt+fVuv93tb/aHzX+4xZtyc727YYdLtt9wfn3Ftpsyy6b9Go42e6Mn6RpnTr9+js6jD+5gGouO3hvDwyAuxwg4RrzZhq/awycdFF9eiHQX/4AKjNXzg2RghhZlIXn9rgzMMMvufRlgmtOHikD878pPHUub1bMs8i/u3F/IwDQWHWU/BwDHlCfB"
 }
@@ -578,6 +578,7 @@ func (http *httpPlugin) newTransaction(requ, resp *message) beat.Event {
 			httpFields.RequestBodyContent = common.NetString(requ.body)
 		}
 		httpFields.RequestHeaders = http.collectHeaders(requ)
+		httpFields.RequestJA4H = ja4hFingerprint(requ)
 
 		// url
 		u := newURL(host, int64(port), path, params)
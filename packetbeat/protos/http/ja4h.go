@@ -0,0 +1,118 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ja4hFingerprint returns the JA4H fingerprint of an HTTP request.
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4H.md.
+func ja4hFingerprint(m *message) string {
+	if m.headerOffset <= 0 || m.headerOffset > len(m.rawHeaders) {
+		return ""
+	}
+
+	var (
+		names    []string
+		cookies  []string
+		language string
+		referer  bool
+	)
+	for _, line := range bytes.Split(m.rawHeaders[m.headerOffset:], constCRLF) {
+		// Skip the empty line ending the headers and obsolete line folding.
+		if len(line) == 0 || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon <= 0 {
+			continue
+		}
+		name := string(bytes.TrimSpace(line[:colon]))
+		value := bytes.TrimSpace(line[colon+1:])
+		switch strings.ToLower(name) {
+		case "cookie":
+			for _, cookie := range bytes.Split(value, []byte(";")) {
+				if cookie = bytes.TrimSpace(cookie); len(cookie) > 0 {
+					cookies = append(cookies, string(cookie))
+				}
+			}
+			continue
+		case "referer":
+			referer = true
+			continue
+		case "accept-language":
+			if language == "" {
+				language = ja4hLanguage(value)
+			}
+		}
+		names = append(names, name)
+	}
+
+	method := strings.ToLower(string(m.method))
+	if len(method) > 2 {
+		method = method[:2]
+	}
+	cookieFlag, refererFlag := 'n', 'n'
+	if len(cookies) > 0 {
+		cookieFlag = 'c'
+	}
+	if referer {
+		refererFlag = 'r'
+	}
+	if language == "" {
+		language = "0000"
+	}
+	a := fmt.Sprintf("%s%d%d%c%c%02d%s",
+		method, m.version.major, m.version.minor, cookieFlag, refererFlag,
+		min(len(names), 99), language)
+
+	cookieNames := make([]string, len(cookies))
+	for idx, cookie := range cookies {
+		cookieNames[idx], _, _ = strings.Cut(cookie, "=")
+	}
+	slices.Sort(cookieNames)
+	slices.Sort(cookies)
+
+	return a + "_" + ja4hHash(names) + "_" + ja4hHash(cookieNames) + "_" + ja4hHash(cookies)
+}
+
+// ja4hLanguage returns the first four characters of the first language
+// in an Accept-Language header, without dashes and padded with zeros.
+func ja4hLanguage(value []byte) string {
+	first, _, _ := strings.Cut(string(value), ",")
+	first, _, _ = strings.Cut(first, ";")
+	first = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(first), "-", ""))
+	if len(first) > 4 {
+		first = first[:4]
+	}
+	return first + strings.Repeat("0", 4-len(first))
+}
+
+func ja4hHash(values []string) string {
+	if len(values) == 0 {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(strings.Join(values, ",")))
+	return hex.EncodeToString(sum[:6])
+}
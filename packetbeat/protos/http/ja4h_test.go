@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJa4hFingerprint(t *testing.T) {
+	for _, test := range []struct {
+		name, data, expected string
+	}{
+		{
+			name: "cookies and referer",
+			data: "GET /index.html HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"User-Agent: Mozilla/5.0\r\n" +
+				"Accept: text/html\r\n" +
+				"Accept-Language: en-US,en;q=0.9\r\n" +
+				"Accept-Encoding: gzip, deflate\r\n" +
+				"Referer: http://example.com/\r\n" +
+				"Cookie: session=abc123; _ga=GA1.2.3; lang=en\r\n" +
+				"Connection: keep-alive\r\n" +
+				"\r\n",
+			expected: "ge11cr06enus_a89d28968959_47a6a5b4285c_0c94dd21c64b",
+		},
+		{
+			name: "no cookies, referer or language",
+			data: "POST /upload HTTP/1.0\r\n" +
+				"Host: example.com\r\n" +
+				"User-Agent: curl/8.0\r\n" +
+				"Accept: */*\r\n" +
+				"\r\n",
+			expected: "po10nn030000_fe444ad14866_000000000000_000000000000",
+		},
+		{
+			name: "folded header and short language",
+			data: "GET / HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"User-Agent: Mozilla/5.0\r\n" +
+				"  (X11; Linux x86_64)\r\n" +
+				"Accept: text/html\r\n" +
+				"accept-language: fr;q=0.8\r\n" +
+				"\r\n",
+			expected: "ge11nn04fr00_" + ja4hHash([]string{"Host", "User-Agent", "Accept", "accept-language"}) + "_000000000000_000000000000",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m, ok, complete := testParse(nil, test.data)
+			require.True(t, ok)
+			require.True(t, complete)
+			assert.Equal(t, test.expected, ja4hFingerprint(m))
+		})
+	}
+}
+
+func TestJa4hLanguage(t *testing.T) {
+	for value, expected := range map[string]string{
+		"en-US,en;q=0.9": "enus",
+		"de":             "de00",
+		"zh-Hant-TW":     "zhha",
+		"*":              "*000",
+		"":               "0000",
+	} {
+		assert.Equal(t, expected, ja4hLanguage([]byte(value)), value)
+	}
+}
@@ -0,0 +1,288 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/libbeat/ecs"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+// httpFields contains the ECS HTTP fields set by the analyzer.
+type httpFields struct {
+	Version            string   `ecs:"version"`
+	RequestMethod      string   `ecs:"request.method"`
+	RequestBytes       int64    `ecs:"request.bytes"`
+	RequestBodyBytes   int64    `ecs:"request.body.bytes"`
+	RequestHeaders     mapstr.M `packetbeat:"request.headers"`
+	ResponseStatusCode int64    `ecs:"response.status_code"`
+	ResponseBytes      int64    `ecs:"response.bytes"`
+	ResponseBodyBytes  int64    `ecs:"response.body.bytes"`
+	ResponseHeaders    mapstr.M `packetbeat:"response.headers"`
+}
+
+func (h *http2Plugin) newTransaction(conn *connection, st *h2stream, notes []string) beat.Event {
+	req, resp := &st.request, &st.response
+
+	ts := req.ts
+	if !req.seen {
+		ts = resp.ts
+	}
+	evt, pbf := pb.NewBeatEvent(ts)
+
+	source, destination := common.MakeEndpointPair(conn.tuple.BaseTuple, conn.cmdlineTuple)
+	src, dst := &source, &destination
+	if conn.clientDir == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = "http2"
+	pbf.Event.Dataset = "http2"
+
+	for _, note := range notes {
+		if note != "" {
+			pbf.Error.Message = append(pbf.Error.Message, note)
+		}
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+
+	status := common.OK_STATUS
+	hf := httpFields{Version: "2"}
+	if req.seen {
+		pbf.Event.Start = req.ts
+		pbf.Source.Bytes = int64(req.bytes)
+		hf.RequestMethod = req.method
+		hf.RequestBytes = int64(req.bytes)
+		hf.RequestBodyBytes = int64(req.bodyBytes)
+		hf.RequestHeaders = h.collectHeaders(req)
+
+		host, port := splitAuthority(req.authority, req.scheme)
+		if host != "" {
+			if net.ParseIP(host) == nil {
+				pbf.Destination.Domain = host
+				pbf.AddHost(host)
+			} else {
+				pbf.AddIP(host)
+			}
+		}
+		pb.MarshalStruct(evt.Fields, "url", newURL(req.scheme, host, port, req.path))
+		if ua, ok := req.header("user-agent"); ok {
+			pb.MarshalStruct(evt.Fields, "user_agent", ecs.UserAgent{Original: ua})
+		}
+
+		fields["method"] = req.method
+		fields["query"] = req.method + " " + req.path
+		if h.sendRequest {
+			fields["request"] = h.rawMessage(req, true)
+		}
+	}
+	if resp.seen {
+		pbf.Event.End = resp.endTs
+		pbf.Destination.Bytes = int64(resp.bytes)
+		hf.ResponseStatusCode = int64(resp.statusCode)
+		hf.ResponseBytes = int64(resp.bytes)
+		hf.ResponseBodyBytes = int64(resp.bodyBytes)
+		hf.ResponseHeaders = h.collectHeaders(resp)
+		if resp.statusCode >= 400 {
+			status = common.ERROR_STATUS
+		}
+		if h.sendResponse {
+			fields["response"] = h.rawMessage(resp, false)
+		}
+	}
+	switch {
+	case !resp.seen && !st.reset:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched request")
+	case !req.seen:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched response")
+	case !resp.ended && !st.reset:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Incomplete response")
+	}
+	pb.MarshalStruct(evt.Fields, "http", hf)
+
+	h2 := mapstr.M{"stream_id": st.id}
+	if conn.upgrade && st.id == 1 {
+		h2["h2c_upgrade"] = true
+	}
+	if st.push {
+		h2["push"] = true
+	}
+	if st.reset {
+		h2["error_code"] = errorCodeName(st.resetCode)
+		if st.resetClient {
+			h2["reset_by"] = "client"
+		} else {
+			h2["reset_by"] = "server"
+		}
+		if st.resetCode != 0 {
+			status = common.ERROR_STATUS
+		}
+	}
+	fields["http2"] = h2
+
+	if st.isGRPC() {
+		grpc := mapstr.M{
+			"request":  mapstr.M{"messages": req.grpc.messages},
+			"response": mapstr.M{"messages": resp.grpc.messages},
+		}
+		if service, method, ok := parseGRPCPath(req.path); ok {
+			grpc["service"] = service
+			grpc["method"] = method
+		}
+		if v, ok := resp.header("grpc-status"); ok {
+			if code, err := strconv.Atoi(v); err == nil {
+				grpc["status_code"] = code
+				grpc["status"] = grpcStatusName(code)
+				if code != 0 {
+					status = common.ERROR_STATUS
+				}
+			}
+		}
+		if v, ok := resp.header("grpc-message"); ok && v != "" {
+			grpc["message"] = decodeGRPCMessage(v)
+		}
+		if v, ok := req.header("grpc-encoding"); ok {
+			grpc["encoding"] = v
+		}
+		fields["grpc"] = grpc
+	}
+
+	fields["status"] = status
+	return evt
+}
+
+// splitAuthority returns the host and port of an :authority pseudo-header
+// field, the port defaults to the default port of scheme.
+func splitAuthority(authority, scheme string) (string, int) {
+	port := 80
+	if scheme == "https" {
+		port = 443
+	}
+	if authority == "" {
+		return "", port
+	}
+	host, p, err := net.SplitHostPort(authority)
+	if err != nil {
+		return strings.Trim(authority, "[]"), port
+	}
+	if n, err := strconv.Atoi(p); err == nil {
+		port = n
+	}
+	return host, port
+}
+
+func newURL(scheme, host string, port int, target string) *ecs.Url {
+	if scheme == "" {
+		scheme = "http"
+	}
+	path, query, _ := strings.Cut(target, "?")
+	u := &ecs.Url{
+		Scheme: scheme,
+		Domain: host,
+		Path:   path,
+		Query:  query,
+	}
+	defaultPort := (scheme == "http" && port == 80) || (scheme == "https" && port == 443)
+	if !defaultPort {
+		u.Port = int64(port)
+	}
+	if host != "" {
+		authority := host
+		if !defaultPort {
+			authority = net.JoinHostPort(host, strconv.Itoa(port))
+		} else if strings.IndexByte(host, ':') != -1 {
+			authority = "[" + host + "]"
+		}
+		full := url.URL{Scheme: scheme, Host: authority, Path: path, RawQuery: query}
+		u.Full = full.String()
+	}
+	return u
+}
+
+func (h *http2Plugin) collectHeaders(m *message) mapstr.M {
+	hdrs := mapstr.M{}
+	for _, fields := range [][]hpack.HeaderField{m.headers, m.trailers} {
+		for _, f := range fields {
+			if f.Name != "content-type" && !h.sendAllHeaders && !h.sendHeaders[f.Name] {
+				continue
+			}
+			value := h.headerValue(f)
+			if prev, ok := hdrs[f.Name]; ok {
+				sep := ", "
+				if f.Name == "cookie" {
+					sep = "; "
+				}
+				value = prev.(string) + sep + value
+			}
+			hdrs[f.Name] = value
+		}
+	}
+	if len(hdrs) == 0 {
+		return nil
+	}
+	return hdrs
+}
+
+func (h *http2Plugin) headerValue(f hpack.HeaderField) string {
+	if h.redactAuthorization && (f.Name == "authorization" || f.Name == "proxy-authorization") {
+		return "*"
+	}
+	return f.Value
+}
+
+// rawMessage renders the header and trailer fields of a message in an
+// HTTP/1.1 like text form.
+func (h *http2Plugin) rawMessage(m *message, request bool) string {
+	var b strings.Builder
+	if request {
+		b.WriteString(m.method + " " + m.path + " HTTP/2\r\n")
+	} else {
+		b.WriteString("HTTP/2 " + strconv.Itoa(m.statusCode) + "\r\n")
+	}
+	for _, f := range m.headers {
+		b.WriteString(f.Name + ": " + h.headerValue(f) + "\r\n")
+	}
+	b.WriteString("\r\n")
+	if len(m.trailers) != 0 {
+		for _, f := range m.trailers {
+			b.WriteString(f.Name + ": " + h.headerValue(f) + "\r\n")
+		}
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
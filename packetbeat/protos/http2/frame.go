@@ -0,0 +1,140 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// clientPreface is sent by clients to start an HTTP/2 connection, either
+// directly (prior knowledge) or after an h2c upgrade.
+const clientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+const frameHeaderLen = 9
+
+// initialHeaderTableSize is the HPACK dynamic table size used until a
+// SETTINGS_HEADER_TABLE_SIZE is announced.
+const initialHeaderTableSize = 4096
+
+type frameType uint8
+
+const (
+	frameData         frameType = 0x0
+	frameHeaders      frameType = 0x1
+	framePriority     frameType = 0x2
+	frameRSTStream    frameType = 0x3
+	frameSettings     frameType = 0x4
+	framePushPromise  frameType = 0x5
+	framePing         frameType = 0x6
+	frameGoAway       frameType = 0x7
+	frameWindowUpdate frameType = 0x8
+	frameContinuation frameType = 0x9
+)
+
+// Frame flags. Not all flags are valid for all frame types.
+const (
+	flagEndStream  = 0x1
+	flagAck        = 0x1
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+)
+
+const settingHeaderTableSize = 0x1
+
+var (
+	errShortFrame  = errors.New("frame payload too short")
+	errBadPadding  = errors.New("invalid frame padding")
+	errUnexpected  = errors.New("unexpected frame")
+	errNotHTTP2    = errors.New("not an HTTP/2 connection")
+	errHPACKDecode = errors.New("HPACK decoding failed")
+)
+
+type frameHeader struct {
+	length   uint32
+	typ      frameType
+	flags    uint8
+	streamID uint32
+}
+
+func (h frameHeader) has(flag uint8) bool {
+	return h.flags&flag != 0
+}
+
+// parseFrameHeader parses a frame header, b must hold at least frameHeaderLen bytes.
+func parseFrameHeader(b []byte) frameHeader {
+	return frameHeader{
+		length:   uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]),
+		typ:      frameType(b[3]),
+		flags:    b[4],
+		streamID: binary.BigEndian.Uint32(b[5:9]) & 0x7fffffff,
+	}
+}
+
+// isSettingsFrame returns whether b starts with what looks like the SETTINGS
+// frame a server sends first on a connection.
+func isSettingsFrame(b []byte) bool {
+	if len(b) < frameHeaderLen {
+		return false
+	}
+	h := parseFrameHeader(b)
+	return h.typ == frameSettings && h.streamID == 0 && !h.has(flagAck) && h.length%6 == 0
+}
+
+// unpad removes the padding of DATA, HEADERS and PUSH_PROMISE frame payloads.
+func unpad(h frameHeader, payload []byte) ([]byte, error) {
+	if !h.has(flagPadded) {
+		return payload, nil
+	}
+	if len(payload) == 0 {
+		return nil, errShortFrame
+	}
+	pad := int(payload[0])
+	payload = payload[1:]
+	if pad > len(payload) {
+		return nil, errBadPadding
+	}
+	return payload[:len(payload)-pad], nil
+}
+
+var errorCodeNames = []string{
+	"NO_ERROR",
+	"PROTOCOL_ERROR",
+	"INTERNAL_ERROR",
+	"FLOW_CONTROL_ERROR",
+	"SETTINGS_TIMEOUT",
+	"STREAM_CLOSED",
+	"FRAME_SIZE_ERROR",
+	"REFUSED_STREAM",
+	"CANCEL",
+	"COMPRESSION_ERROR",
+	"CONNECT_ERROR",
+	"ENHANCE_YOUR_CALM",
+	"INADEQUATE_SECURITY",
+	"HTTP_1_1_REQUIRED",
+}
+
+// errorCodeName returns the name of an RST_STREAM or GOAWAY error code.
+func errorCodeName(code uint32) string {
+	if int(code) < len(errorCodeNames) {
+		return errorCodeNames[code]
+	}
+	return "UNKNOWN_" + strconv.FormatUint(uint64(code), 10)
+}
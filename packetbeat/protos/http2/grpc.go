@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"encoding/binary"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const grpcMessageHeaderLen = 5
+
+var grpcStatusNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// grpcStatusName returns the canonical name of a gRPC status code.
+func grpcStatusName(code int) string {
+	if code >= 0 && code < len(grpcStatusNames) {
+		return grpcStatusNames[code]
+	}
+	return "CODE_" + strconv.Itoa(code)
+}
+
+// isGRPC returns whether contentType is the content type of a gRPC request.
+// gRPC-Web is not included as its trailers are carried in the body.
+func isGRPC(contentType string) bool {
+	if !strings.HasPrefix(contentType, "application/grpc") {
+		return false
+	}
+	rest := contentType[len("application/grpc"):]
+	return rest == "" || rest[0] == '+' || rest[0] == ';'
+}
+
+// parseGRPCPath splits a gRPC request path of the form /package.Service/Method.
+func parseGRPCPath(path string) (service, method string, ok bool) {
+	if !strings.HasPrefix(path, "/") {
+		return "", "", false
+	}
+	service, method, ok = strings.Cut(path[1:], "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+// decodeGRPCMessage decodes the percent-encoded grpc-message header value.
+func decodeGRPCMessage(msg string) string {
+	if s, err := url.PathUnescape(msg); err == nil {
+		return s
+	}
+	return msg
+}
+
+// grpcFramer counts the length-prefixed gRPC messages carried by the DATA
+// frames of one side of a stream.
+type grpcFramer struct {
+	header    [grpcMessageHeaderLen]byte
+	headerLen int
+	remaining uint32
+	messages  int
+}
+
+func (g *grpcFramer) write(p []byte) {
+	for len(p) > 0 {
+		if g.remaining > 0 {
+			n := uint32(len(p))
+			if n > g.remaining {
+				n = g.remaining
+			}
+			g.remaining -= n
+			p = p[n:]
+			continue
+		}
+		n := copy(g.header[g.headerLen:], p)
+		g.headerLen += n
+		p = p[n:]
+		if g.headerLen == grpcMessageHeaderLen {
+			g.messages++
+			g.remaining = binary.BigEndian.Uint32(g.header[1:])
+			g.headerLen = 0
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package http2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGRPC(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"application/grpc":                true,
+		"application/grpc+proto":          true,
+		"application/grpc;charset=utf-8":  true,
+		"application/grpc-web":            false,
+		"application/grpc-web-text+proto": false,
+		"application/json":                false,
+		"":                                false,
+	} {
+		assert.Equal(t, want, isGRPC(ct), ct)
+	}
+}
+
+func TestParseGRPCPath(t *testing.T) {
+	service, method, ok := parseGRPCPath("/grpc.health.v1.Health/Watch")
+	assert.True(t, ok)
+	assert.Equal(t, "grpc.health.v1.Health", service)
+	assert.Equal(t, "Watch", method)
+
+	for _, path := range []string{"", "/", "/Health", "/Health/", "//Watch", "/a/b/c", "Health/Watch"} {
+		_, _, ok := parseGRPCPath(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestGRPCFramer(t *testing.T) {
+	stream := append(grpcMessage("hello"), grpcMessage("")...)
+	stream = append(stream, 0, 0, 0, 1, 0)
+	stream = append(stream, make([]byte, 256)...)
+
+	var whole grpcFramer
+	whole.write(stream)
+	assert.Equal(t, 3, whole.messages)
+
+	// Message boundaries are tracked across DATA frames.
+	var split grpcFramer
+	for _, b := range stream {
+		split.write([]byte{b})
+	}
+	assert.Equal(t, 3, split.messages)
+	assert.Zero(t, split.remaining)
+}
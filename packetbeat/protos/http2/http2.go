@@ -0,0 +1,771 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package http2 implements a Packetbeat analyzer for cleartext HTTP/2 (h2c),
+// correlating the requests and responses of each HTTP/2 stream into a
+// transaction and extracting gRPC call details.
+package http2
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+var (
+	droppedStreams = monitoring.NewInt(nil, "http2.dropped_streams")
+	ignoredConns   = monitoring.NewInt(nil, "http2.ignored_connections")
+)
+
+// maxUpgradeRequestSize limits the size of the HTTP/1.1 headers buffered
+// while looking for an h2c upgrade.
+const maxUpgradeRequestSize = 16 * 1024
+
+type sideRole uint8
+
+const (
+	roleUnknown sideRole = iota
+	roleClient
+	roleServer
+)
+
+// side holds the parsing state of one direction of a connection.
+type side struct {
+	applayer.Stream
+	role sideRole
+	// framing is set once the side carries HTTP/2 frames.
+	framing bool
+	// upgraded is set on the client side once an h2c upgrade request has been
+	// seen, the client preface is expected next.
+	upgraded bool
+
+	decoder   *hpack.Decoder
+	tableSize uint32
+	block     *headerBlock
+}
+
+// headerBlock is a header block being received in a HEADERS or PUSH_PROMISE
+// frame and its CONTINUATION frames.
+type headerBlock struct {
+	streamID  uint32
+	promised  uint32
+	endStream bool
+	fragment  []byte
+	bytes     int
+}
+
+type connection struct {
+	tuple        common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+	// clientDir is the TCP direction of the client, -1 if not known yet.
+	clientDir int
+	upgrade   bool
+	// ignored is set when the connection is not, or can no longer be, analyzed.
+	ignored bool
+
+	sides       [2]*side
+	streams     map[uint32]*h2stream
+	maxClientID uint32
+}
+
+// h2stream is an HTTP/2 stream, the request is sent by the client and the
+// response by the server.
+type h2stream struct {
+	id       uint32
+	push     bool
+	request  message
+	response message
+
+	reset       bool
+	resetCode   uint32
+	resetClient bool
+}
+
+type message struct {
+	ts, endTs   time.Time
+	seen        bool
+	headersDone bool
+	ended       bool
+
+	headers  []hpack.HeaderField
+	trailers []hpack.HeaderField
+
+	method, scheme, authority, path string
+	statusCode                      int
+
+	bytes     int
+	bodyBytes int
+	grpc      grpcFramer
+}
+
+func (m *message) update(ts time.Time) {
+	if !m.seen {
+		m.seen = true
+		m.ts = ts
+	}
+	m.endTs = ts
+}
+
+// setHeaders sets the header fields of the message, pseudo-header fields are
+// stored separately.
+func (m *message) setHeaders(fields []hpack.HeaderField) {
+	m.headersDone = true
+	m.headers = m.headers[:0]
+	for _, f := range fields {
+		switch f.Name {
+		case ":method":
+			m.method = f.Value
+		case ":scheme":
+			m.scheme = f.Value
+		case ":authority":
+			m.authority = f.Value
+		case ":path":
+			m.path = f.Value
+		case ":status":
+			m.statusCode, _ = strconv.Atoi(f.Value)
+		default:
+			if !f.IsPseudo() {
+				m.headers = append(m.headers, f)
+			}
+		}
+	}
+}
+
+// header returns the value of the first header or trailer field called name.
+func (m *message) header(name string) (string, bool) {
+	for _, fields := range [][]hpack.HeaderField{m.trailers, m.headers} {
+		for _, f := range fields {
+			if f.Name == name {
+				return f.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// HTTP/2 protocol plugin
+type http2Plugin struct {
+	// config
+	ports               []int
+	sendRequest         bool
+	sendResponse        bool
+	sendAllHeaders      bool
+	sendHeaders         map[string]bool
+	redactAuthorization bool
+	maxStreams          int
+	transactionTimeout  time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+	logger  *logp.Logger
+	isDebug bool
+}
+
+func init() {
+	protos.Register("http2", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &http2Plugin{}
+	p.logger = logger.Named("http2")
+	p.isDebug = p.logger.IsDebug()
+
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	p.init(results, watcher, &config)
+	return p, nil
+}
+
+//go:inline
+func (h *http2Plugin) debugf(format string, args ...interface{}) {
+	if h.isDebug {
+		h.logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+func (h *http2Plugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *http2Config) {
+	h.setFromConfig(config)
+	h.results = results
+	h.watcher = watcher
+}
+
+func (h *http2Plugin) setFromConfig(config *http2Config) {
+	h.ports = config.Ports
+	h.sendRequest = config.SendRequest
+	h.sendResponse = config.SendResponse
+	h.sendAllHeaders = config.SendAllHeaders
+	h.sendHeaders = make(map[string]bool, len(config.SendHeaders))
+	for _, name := range config.SendHeaders {
+		h.sendHeaders[strings.ToLower(name)] = true
+	}
+	h.redactAuthorization = config.RedactAuthorization
+	h.maxStreams = config.MaxStreams
+	h.transactionTimeout = config.TransactionTimeout
+}
+
+func (h *http2Plugin) GetPorts() []int {
+	return h.ports
+}
+
+func (h *http2Plugin) ConnectionTimeout() time.Duration {
+	return h.transactionTimeout
+}
+
+func (h *http2Plugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := h.ensureConnection(private, tcptuple)
+	if conn.ignored {
+		return conn
+	}
+
+	s := conn.sides[dir]
+	if s == nil {
+		s = &side{}
+		s.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.sides[dir] = s
+	}
+	if err := s.Append(pkt.Payload); err != nil {
+		h.ignore(conn, "%v", err)
+		return conn
+	}
+
+	if err := h.process(conn, s, dir, pkt.Ts); err != nil {
+		h.ignore(conn, "%v", err)
+	}
+	return conn
+}
+
+func (h *http2Plugin) ensureConnection(private protos.ProtocolData, tcptuple *common.TCPTuple) *connection {
+	if conn, ok := private.(*connection); ok && conn != nil {
+		return conn
+	}
+	return &connection{
+		tuple:        *tcptuple,
+		cmdlineTuple: h.watcher.FindProcessesTupleTCP(tcptuple.IPPort()),
+		clientDir:    -1,
+		streams:      make(map[uint32]*h2stream),
+	}
+}
+
+// ignore stops the analysis of conn, publishing the streams seen so far.
+func (h *http2Plugin) ignore(conn *connection, format string, args ...interface{}) {
+	h.debugf("ignoring connection %s: %s", &conn.tuple, fmt.Sprintf(format, args...))
+	ignoredConns.Inc()
+	h.publishAll(conn, "Connection analysis stopped")
+	conn.ignored = true
+	conn.sides = [2]*side{}
+}
+
+// process consumes the buffered data of one side of the connection.
+func (h *http2Plugin) process(conn *connection, s *side, dir uint8, ts time.Time) error {
+	for !s.framing {
+		done, err := h.detect(conn, s, dir, ts)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	for s.Buf.Len() >= frameHeaderLen {
+		hdr := parseFrameHeader(s.Buf.Bytes())
+		if !s.Buf.Avail(frameHeaderLen + int(hdr.length)) {
+			break
+		}
+		frame, _ := s.Buf.Collect(frameHeaderLen + int(hdr.length))
+		if err := h.handleFrame(conn, s, hdr, frame[frameHeaderLen:], ts); err != nil {
+			return err
+		}
+		s.Buf.Reset()
+	}
+	return nil
+}
+
+// detect identifies the role of a side from the data it starts with. It
+// returns false if more data is needed.
+func (h *http2Plugin) detect(conn *connection, s *side, dir uint8, ts time.Time) (bool, error) {
+	buf := s.Buf.Bytes()
+	switch {
+	case len(buf) < len(clientPreface) && strings.HasPrefix(clientPreface, string(buf)):
+		return false, nil
+	case bytes.HasPrefix(buf, []byte(clientPreface)):
+		if err := h.setRole(conn, s, dir, roleClient); err != nil {
+			return false, err
+		}
+		_, _ = s.Buf.Collect(len(clientPreface))
+		s.Buf.Reset()
+		startFraming(s)
+		return true, nil
+	case s.role == roleClient && s.upgraded:
+		return false, errNotHTTP2
+	case bytes.HasPrefix(buf, []byte("HTTP/1.")):
+		return h.detectUpgradeResponse(conn, s, dir)
+	case isSettingsFrame(buf):
+		if err := h.setRole(conn, s, dir, roleServer); err != nil {
+			return false, err
+		}
+		startFraming(s)
+		return true, nil
+	case isHTTP1Request(buf):
+		return h.detectUpgradeRequest(conn, s, dir, ts)
+	case conn.clientDir == int(dir) && s.role == roleUnknown && len(buf) >= frameHeaderLen:
+		// The server was identified first, the client preface was sent
+		// before the capture started.
+		s.role = roleClient
+		startFraming(s)
+		return true, nil
+	case len(buf) < frameHeaderLen:
+		return false, nil
+	}
+	return false, errNotHTTP2
+}
+
+func (h *http2Plugin) setRole(conn *connection, s *side, dir uint8, role sideRole) error {
+	clientDir := int(dir)
+	if role == roleServer {
+		clientDir = 1 - int(dir)
+	}
+	if conn.clientDir >= 0 && conn.clientDir != clientDir {
+		return fmt.Errorf("both sides of the connection act as %s", roleName(role))
+	}
+	conn.clientDir = clientDir
+	s.role = role
+	return nil
+}
+
+func roleName(r sideRole) string {
+	if r == roleClient {
+		return "client"
+	}
+	return "server"
+}
+
+func startFraming(s *side) {
+	s.framing = true
+	s.decoder = hpack.NewDecoder(initialHeaderTableSize, nil)
+	if s.tableSize != 0 {
+		s.decoder.SetAllowedMaxDynamicTableSize(s.tableSize)
+	}
+}
+
+var http1Methods = []string{"GET ", "POST ", "PUT ", "HEAD ", "OPTIONS ", "DELETE ", "PATCH ", "TRACE ", "CONNECT "}
+
+func isHTTP1Request(buf []byte) bool {
+	for _, m := range http1Methods {
+		if bytes.HasPrefix(buf, []byte(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// readHTTP1Head returns the start line and the header fields of an HTTP/1.x
+// message and the size of its head, or a zero size if more data is needed.
+func readHTTP1Head(buf []byte) (start string, fields []hpack.HeaderField, size int, err error) {
+	end := bytes.Index(buf, []byte("\r\n\r\n"))
+	if end < 0 {
+		if len(buf) > maxUpgradeRequestSize {
+			return "", nil, 0, errNotHTTP2
+		}
+		return "", nil, 0, nil
+	}
+	lines := strings.Split(string(buf[:end]), "\r\n")
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return "", nil, 0, errNotHTTP2
+		}
+		fields = append(fields, hpack.HeaderField{
+			Name:  strings.ToLower(strings.TrimSpace(name)),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return lines[0], fields, end + 4, nil
+}
+
+// detectUpgradeRequest parses an HTTP/1.1 request asking for an h2c upgrade.
+// The request becomes the request of stream 1.
+func (h *http2Plugin) detectUpgradeRequest(conn *connection, s *side, dir uint8, ts time.Time) (bool, error) {
+	start, fields, size, err := readHTTP1Head(s.Buf.Bytes())
+	if err != nil || size == 0 {
+		return false, err
+	}
+	parts := strings.SplitN(start, " ", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/1.") {
+		return false, errNotHTTP2
+	}
+
+	var upgrade bool
+	var host string
+	var contentLength int
+	for _, f := range fields {
+		switch f.Name {
+		case "upgrade":
+			for _, proto := range strings.Split(f.Value, ",") {
+				if strings.EqualFold(strings.TrimSpace(proto), "h2c") {
+					upgrade = true
+				}
+			}
+		case "host":
+			host = f.Value
+		case "content-length":
+			contentLength, _ = strconv.Atoi(f.Value)
+		}
+	}
+	if !upgrade {
+		return false, errNotHTTP2
+	}
+	if !s.Buf.Avail(size + contentLength) {
+		return false, nil
+	}
+	if err := h.setRole(conn, s, dir, roleClient); err != nil {
+		return false, err
+	}
+	_, _ = s.Buf.Collect(size + contentLength)
+	s.Buf.Reset()
+	s.upgraded = true
+	conn.upgrade = true
+
+	st := &h2stream{id: 1}
+	req := &st.request
+	req.update(ts)
+	req.setHeaders(fields)
+	req.method = parts[0]
+	req.path = parts[1]
+	req.scheme = "http"
+	req.authority = host
+	req.bytes = size + contentLength
+	req.bodyBytes = contentLength
+	req.ended = true
+	conn.streams[st.id] = st
+	conn.maxClientID = st.id
+	return true, nil
+}
+
+// detectUpgradeResponse checks that the server accepted an h2c upgrade, frames
+// follow the 101 response.
+func (h *http2Plugin) detectUpgradeResponse(conn *connection, s *side, dir uint8) (bool, error) {
+	start, _, size, err := readHTTP1Head(s.Buf.Bytes())
+	if err != nil || size == 0 {
+		return false, err
+	}
+	parts := strings.SplitN(start, " ", 3)
+	if len(parts) < 2 || parts[1] != "101" {
+		return false, errNotHTTP2
+	}
+	if err := h.setRole(conn, s, dir, roleServer); err != nil {
+		return false, err
+	}
+	_, _ = s.Buf.Collect(size)
+	s.Buf.Reset()
+	if st := conn.streams[1]; st != nil {
+		st.response.bytes += size
+	}
+	startFraming(s)
+	return true, nil
+}
+
+func (h *http2Plugin) handleFrame(conn *connection, s *side, hdr frameHeader, payload []byte, ts time.Time) error {
+	client := s.role == roleClient
+	if s.block != nil && hdr.typ != frameContinuation {
+		return fmt.Errorf("%w: %d frame while expecting CONTINUATION", errUnexpected, hdr.typ)
+	}
+
+	switch hdr.typ {
+	case frameData:
+		data, err := unpad(hdr, payload)
+		if err != nil {
+			return err
+		}
+		st := conn.streams[hdr.streamID]
+		if st == nil {
+			return nil
+		}
+		msg := st.message(client)
+		msg.update(ts)
+		msg.bytes += frameHeaderLen + len(payload)
+		msg.bodyBytes += len(data)
+		if st.isGRPC() {
+			msg.grpc.write(data)
+		}
+		if hdr.has(flagEndStream) {
+			msg.ended = true
+			h.checkComplete(conn, st, client)
+		}
+
+	case frameHeaders:
+		block, err := unpad(hdr, payload)
+		if err != nil {
+			return err
+		}
+		if hdr.has(flagPriority) {
+			if len(block) < 5 {
+				return errShortFrame
+			}
+			block = block[5:]
+		}
+		s.block = &headerBlock{
+			streamID:  hdr.streamID,
+			endStream: hdr.has(flagEndStream),
+			fragment:  append([]byte(nil), block...),
+			bytes:     frameHeaderLen + len(payload),
+		}
+		if hdr.has(flagEndHeaders) {
+			return h.handleHeaderBlock(conn, s, ts)
+		}
+
+	case framePushPromise:
+		block, err := unpad(hdr, payload)
+		if err != nil {
+			return err
+		}
+		if len(block) < 4 {
+			return errShortFrame
+		}
+		s.block = &headerBlock{
+			streamID: hdr.streamID,
+			promised: uint32(block[0]&0x7f)<<24 | uint32(block[1])<<16 | uint32(block[2])<<8 | uint32(block[3]),
+			fragment: append([]byte(nil), block[4:]...),
+			bytes:    frameHeaderLen + len(payload),
+		}
+		if hdr.has(flagEndHeaders) {
+			return h.handleHeaderBlock(conn, s, ts)
+		}
+
+	case frameContinuation:
+		if s.block == nil || s.block.streamID != hdr.streamID {
+			return fmt.Errorf("%w: CONTINUATION on stream %d", errUnexpected, hdr.streamID)
+		}
+		s.block.fragment = append(s.block.fragment, payload...)
+		s.block.bytes += frameHeaderLen + len(payload)
+		if hdr.has(flagEndHeaders) {
+			return h.handleHeaderBlock(conn, s, ts)
+		}
+
+	case frameRSTStream:
+		if len(payload) < 4 {
+			return errShortFrame
+		}
+		st := conn.streams[hdr.streamID]
+		if st == nil {
+			return nil
+		}
+		st.message(client).update(ts)
+		st.reset = true
+		st.resetCode = uint32(payload[0])<<24 | uint32(payload[1])<<16 | uint32(payload[2])<<8 | uint32(payload[3])
+		st.resetClient = client
+		h.publish(conn, st)
+
+	case frameSettings:
+		if hdr.has(flagAck) {
+			return nil
+		}
+		for i := 0; i+6 <= len(payload); i += 6 {
+			id := uint16(payload[i])<<8 | uint16(payload[i+1])
+			if id != settingHeaderTableSize {
+				continue
+			}
+			// The table size announced by one side bounds the table
+			// used by the encoder of the other side.
+			size := uint32(payload[i+2])<<24 | uint32(payload[i+3])<<16 | uint32(payload[i+4])<<8 | uint32(payload[i+5])
+			other := h.otherSide(conn, s)
+			other.tableSize = size
+			if other.decoder != nil {
+				other.decoder.SetAllowedMaxDynamicTableSize(size)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *http2Plugin) otherSide(conn *connection, s *side) *side {
+	for dir, o := range conn.sides {
+		if o != s {
+			if o == nil {
+				o = &side{}
+				o.Stream.Init(tcp.TCPMaxDataInStream)
+				conn.sides[dir] = o
+			}
+			return o
+		}
+	}
+	return s
+}
+
+// handleHeaderBlock decodes a complete header block and applies it to its stream.
+func (h *http2Plugin) handleHeaderBlock(conn *connection, s *side, ts time.Time) error {
+	block := s.block
+	s.block = nil
+	client := s.role == roleClient
+
+	fields, err := s.decoder.DecodeFull(block.fragment)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errHPACKDecode, err)
+	}
+
+	if block.promised != 0 {
+		st := h.newStream(conn, block.promised)
+		if st == nil {
+			return nil
+		}
+		st.push = true
+		req := &st.request
+		req.update(ts)
+		req.setHeaders(fields)
+		req.bytes = block.bytes
+		req.ended = true
+		return nil
+	}
+
+	st := conn.streams[block.streamID]
+	if st == nil {
+		// Only clients open streams, servers open pushed streams with a
+		// PUSH_PROMISE. Other headers are sent on closed streams.
+		if !client || block.streamID%2 == 0 || block.streamID <= conn.maxClientID {
+			h.debugf("headers on unknown stream %d", block.streamID)
+			return nil
+		}
+		conn.maxClientID = block.streamID
+		if st = h.newStream(conn, block.streamID); st == nil {
+			return nil
+		}
+	}
+
+	msg := st.message(client)
+	msg.update(ts)
+	msg.bytes += block.bytes
+	switch {
+	case msg.headersDone:
+		msg.trailers = fields
+	case !client && isInformational(fields):
+		// 1xx responses precede the final response headers
+	default:
+		msg.setHeaders(fields)
+	}
+	if block.endStream {
+		msg.ended = true
+		h.checkComplete(conn, st, client)
+	}
+	return nil
+}
+
+func isInformational(fields []hpack.HeaderField) bool {
+	for _, f := range fields {
+		if f.Name == ":status" {
+			return len(f.Value) == 3 && f.Value[0] == '1'
+		}
+	}
+	return false
+}
+
+func (h *http2Plugin) newStream(conn *connection, id uint32) *h2stream {
+	if len(conn.streams) >= h.maxStreams {
+		h.debugf("too many streams on %s, dropping stream %d", &conn.tuple, id)
+		droppedStreams.Inc()
+		return nil
+	}
+	st := &h2stream{id: id}
+	conn.streams[id] = st
+	return st
+}
+
+func (st *h2stream) message(client bool) *message {
+	if client {
+		return &st.request
+	}
+	return &st.response
+}
+
+func (st *h2stream) isGRPC() bool {
+	ct, _ := st.request.header("content-type")
+	return isGRPC(ct)
+}
+
+// checkComplete publishes a stream once the server has ended it.
+func (h *http2Plugin) checkComplete(conn *connection, st *h2stream, client bool) {
+	if !client && st.response.ended {
+		h.publish(conn, st)
+	}
+}
+
+func (h *http2Plugin) publish(conn *connection, st *h2stream, notes ...string) {
+	delete(conn.streams, st.id)
+	if h.results != nil {
+		h.results(h.newTransaction(conn, st, notes))
+	}
+}
+
+// publishAll publishes all streams of conn that are not complete yet.
+func (h *http2Plugin) publishAll(conn *connection, note string) {
+	for _, st := range conn.streams {
+		h.publish(conn, st, note)
+	}
+}
+
+func (h *http2Plugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+func (h *http2Plugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	// A gap loses frames and breaks the HPACK state of the connection, it
+	// can't be analyzed any further.
+	if conn, ok := private.(*connection); ok && conn != nil && !conn.ignored {
+		h.ignore(conn, "gap of %d bytes", nbytes)
+		return conn, false
+	}
+	return private, false
+}
+
+func (h *http2Plugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return
+	}
+	h.debugf("expired connection %s", tuple)
+	h.publishAll(conn, "")
+}
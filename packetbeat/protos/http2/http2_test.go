@@ -0,0 +1,456 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package http2
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func (e *eventStore) get(t *testing.T, key string, idx int) interface{} {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	v, err := e.events[idx].Fields.GetValue(key)
+	require.NoError(t, err, "missing %s in event %d: %v", key, idx, e.events[idx].Fields)
+	return v
+}
+
+func http2ModForTests(t *testing.T, store *eventStore, cfg map[string]interface{}) *http2Plugin {
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(cfg), logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	return p.(*http2Plugin)
+}
+
+// peer writes the frames sent by one side of a connection, encoding header
+// blocks with its own HPACK context.
+type peer struct {
+	buf    bytes.Buffer
+	framer *http2.Framer
+	hbuf   bytes.Buffer
+	enc    *hpack.Encoder
+}
+
+func newPeer() *peer {
+	p := &peer{}
+	p.framer = http2.NewFramer(&p.buf, nil)
+	p.enc = hpack.NewEncoder(&p.hbuf)
+	return p
+}
+
+func (p *peer) block(fields ...string) []byte {
+	p.hbuf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		_ = p.enc.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte(nil), p.hbuf.Bytes()...)
+}
+
+func (p *peer) headers(streamID uint32, endStream bool, fields ...string) *peer {
+	_ = p.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: p.block(fields...),
+		EndStream:     endStream,
+		EndHeaders:    true,
+	})
+	return p
+}
+
+func (p *peer) data(streamID uint32, endStream bool, data []byte) *peer {
+	_ = p.framer.WriteData(streamID, endStream, data)
+	return p
+}
+
+// take returns the bytes written so far.
+func (p *peer) take() []byte {
+	b := append([]byte(nil), p.buf.Bytes()...)
+	p.buf.Reset()
+	return b
+}
+
+func grpcMessage(payload string) []byte {
+	return append([]byte{0, 0, 0, 0, byte(len(payload))}, payload...)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 50051,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// h2cPreface returns the connection preface and SETTINGS frame sent by the client.
+func h2cPreface(client *peer) []byte {
+	client.buf.WriteString(clientPreface)
+	_ = client.framer.WriteSettings()
+	return client.take()
+}
+
+// h2cSettings returns the SETTINGS frame sent by the server, along with its
+// acknowledgement of the client settings.
+func h2cSettings(server *peer) []byte {
+	_ = server.framer.WriteSettings(http2.Setting{ID: http2.SettingMaxConcurrentStreams, Val: 100})
+	_ = server.framer.WriteSettingsAck()
+	return server.take()
+}
+
+// h2cSettingsAck returns the acknowledgement of the server settings.
+func h2cSettingsAck(client *peer) []byte {
+	_ = client.framer.WriteSettingsAck()
+	return client.take()
+}
+
+func startH2C(plugin *http2Plugin, tuple *common.TCPTuple, private protos.ProtocolData, client, server *peer) protos.ProtocolData {
+	private = plugin.Parse(&protos.Packet{Payload: h2cPreface(client)}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: h2cSettings(server)}, tuple, tcp.TCPDirectionReverse, private)
+	return plugin.Parse(&protos.Packet{Payload: h2cSettingsAck(client)}, tuple, tcp.TCPDirectionOriginal, private)
+}
+
+func unaryRequest(client *peer, streamID uint32, method string) []byte {
+	client.headers(streamID, false,
+		":method", "POST",
+		":scheme", "http",
+		":authority", "greeter.example.com:50051",
+		":path", method,
+		"content-type", "application/grpc",
+		"te", "trailers",
+		"user-agent", "grpc-go/1.60.0",
+	).data(streamID, true, grpcMessage("\x0a\x05world"))
+	return client.take()
+}
+
+func unaryResponse(server *peer, streamID uint32, grpcStatus string) []byte {
+	server.headers(streamID, false,
+		":status", "200",
+		"content-type", "application/grpc",
+	).data(streamID, false, grpcMessage("\x0a\x0bHello world")).headers(streamID, true,
+		"grpc-status", grpcStatus,
+	)
+	return server.take()
+}
+
+func unaryCall(plugin *http2Plugin, tuple *common.TCPTuple, private protos.ProtocolData, client, server *peer, streamID uint32, method string, grpcStatus string) protos.ProtocolData {
+	private = plugin.Parse(&protos.Packet{Payload: unaryRequest(client, streamID, method)}, tuple, tcp.TCPDirectionOriginal, private)
+	return plugin.Parse(&protos.Packet{Payload: unaryResponse(server, streamID, grpcStatus)}, tuple, tcp.TCPDirectionReverse, private)
+}
+
+func TestGRPCUnaryCall(t *testing.T) {
+	for _, chunk := range []int{0, 1, 7} {
+		var store eventStore
+		plugin := http2ModForTests(t, &store, nil)
+		tuple := testTCPTuple()
+		var private protos.ProtocolData
+		client, server := newPeer(), newPeer()
+		for _, msg := range []struct {
+			dir     uint8
+			payload []byte
+		}{
+			{tcp.TCPDirectionOriginal, h2cPreface(client)},
+			{tcp.TCPDirectionReverse, h2cSettings(server)},
+			{tcp.TCPDirectionOriginal, h2cSettingsAck(client)},
+			{tcp.TCPDirectionOriginal, unaryRequest(client, 1, "/helloworld.Greeter/SayHello")},
+			{tcp.TCPDirectionReverse, unaryResponse(server, 1, "0")},
+		} {
+			for payload := msg.payload; len(payload) > 0; {
+				n := len(payload)
+				if chunk > 0 {
+					n = min(n, chunk)
+				}
+				private = plugin.Parse(&protos.Packet{Payload: payload[:n]}, tuple, msg.dir, private)
+				payload = payload[n:]
+			}
+		}
+
+		require.Len(t, store.events, 1, "chunk size %d", chunk)
+		assert.Equal(t, "http2", store.get(t, "type", 0))
+		assert.Equal(t, "OK", store.get(t, "status", 0))
+		assert.Equal(t, "POST", store.get(t, "http.request.method", 0))
+		assert.Equal(t, int64(200), store.get(t, "http.response.status_code", 0))
+		assert.Equal(t, "2", store.get(t, "http.version", 0))
+		assert.Equal(t, int64(12), store.get(t, "http.request.body.bytes", 0))
+		assert.Equal(t, "/helloworld.Greeter/SayHello", store.get(t, "url.path", 0))
+		assert.Equal(t, "http://greeter.example.com:50051/helloworld.Greeter/SayHello", store.get(t, "url.full", 0))
+		assert.Equal(t, "greeter.example.com", store.get(t, "destination.domain", 0))
+		assert.Equal(t, "grpc-go/1.60.0", store.get(t, "user_agent.original", 0))
+		assert.Equal(t, "192.168.0.1", store.get(t, "client.ip", 0))
+		assert.Equal(t, uint32(1), store.get(t, "http2.stream_id", 0))
+		assert.Equal(t, "helloworld.Greeter", store.get(t, "grpc.service", 0))
+		assert.Equal(t, "SayHello", store.get(t, "grpc.method", 0))
+		assert.Equal(t, 0, store.get(t, "grpc.status_code", 0))
+		assert.Equal(t, "OK", store.get(t, "grpc.status", 0))
+		assert.Equal(t, 1, store.get(t, "grpc.request.messages", 0))
+		assert.Equal(t, 1, store.get(t, "grpc.response.messages", 0))
+		assert.Empty(t, private.(*connection).streams)
+	}
+}
+
+func TestGRPCTrailersOnlyError(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+	private = startH2C(plugin, tuple, private, client, server)
+
+	client.headers(1, false,
+		":method", "POST",
+		":scheme", "http",
+		":authority", "10.0.0.2:50051",
+		":path", "/inventory.v1.Items/Get",
+		"content-type", "application/grpc+proto",
+	).data(1, true, grpcMessage("id"))
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+	server.headers(1, true,
+		":status", "200",
+		"content-type", "application/grpc",
+		"grpc-status", "5",
+		"grpc-message", "item%20not%20found",
+	)
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, "status", 0))
+	assert.Equal(t, 5, store.get(t, "grpc.status_code", 0))
+	assert.Equal(t, "NOT_FOUND", store.get(t, "grpc.status", 0))
+	assert.Equal(t, "item not found", store.get(t, "grpc.message", 0))
+	assert.Equal(t, 0, store.get(t, "grpc.response.messages", 0))
+	_, err := store.events[0].Fields.GetValue("destination.domain")
+	assert.Error(t, err, "IP authority must not be used as domain")
+}
+
+func TestConcurrentStreams(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, map[string]interface{}{
+		"send_headers": []string{"x-request-id"},
+	})
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+	private = startH2C(plugin, tuple, private, client, server)
+
+	for _, id := range []uint32{1, 3, 5} {
+		client.headers(id, true,
+			":method", "GET",
+			":scheme", "http",
+			":authority", "api.example.com",
+			":path", "/items/"+string(rune('0'+id))+"?verbose=1",
+			"x-request-id", "req-"+string(rune('0'+id)),
+		)
+	}
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+
+	// Responses are interleaved and complete in reverse order.
+	server.headers(5, false, ":status", "404").headers(3, false, ":status", "200", "content-type", "application/json")
+	server.data(3, false, []byte(`{"id":`)).data(5, true, nil).data(3, true, []byte(`3}`))
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 2)
+	server.headers(1, true, ":status", "503")
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 3)
+
+	byPath := map[string]int{}
+	for i := range store.events {
+		byPath[store.get(t, "url.path", i).(string)] = i
+	}
+	i := byPath["/items/5"]
+	assert.Equal(t, int64(404), store.get(t, "http.response.status_code", i))
+	assert.Equal(t, "Error", store.get(t, "status", i))
+	assert.Equal(t, "verbose=1", store.get(t, "url.query", i))
+	assert.Equal(t, mapstr.M{"x-request-id": "req-5"}, store.get(t, "http.request.headers", i))
+
+	i = byPath["/items/3"]
+	assert.Equal(t, int64(200), store.get(t, "http.response.status_code", i))
+	assert.Equal(t, int64(8), store.get(t, "http.response.body.bytes", i))
+	assert.Equal(t, "OK", store.get(t, "status", i))
+	assert.Equal(t, mapstr.M{"content-type": "application/json"}, store.get(t, "http.response.headers", i))
+	assert.Equal(t, "GET /items/3?verbose=1", store.get(t, "query", i))
+
+	i = byPath["/items/1"]
+	assert.Equal(t, int64(503), store.get(t, "http.response.status_code", i))
+	assert.Equal(t, uint32(1), store.get(t, "http2.stream_id", i))
+	_, err := store.events[i].Fields.GetValue("grpc")
+	assert.Error(t, err, "plain HTTP/2 transactions have no grpc fields")
+}
+
+func TestH2CUpgrade(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+
+	private = plugin.Parse(&protos.Packet{Payload: []byte("GET /status HTTP/1.1\r\n" +
+		"Host: web.example.com\r\n" +
+		"Connection: Upgrade, HTTP2-Settings\r\n" +
+		"Upgrade: h2c\r\n" +
+		"HTTP2-Settings: AAMAAABkAARAAAAAAAIAAAAA\r\n" +
+		"\r\n")}, tuple, tcp.TCPDirectionOriginal, private)
+	server.buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: h2c\r\n\r\n")
+	_ = server.framer.WriteSettings()
+	server.headers(1, false, ":status", "200", "content-type", "text/plain").data(1, true, []byte("up"))
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+	client.buf.WriteString(clientPreface)
+	_ = client.framer.WriteSettings()
+	_ = client.framer.WriteSettingsAck()
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, "status", 0))
+	assert.Equal(t, "GET", store.get(t, "http.request.method", 0))
+	assert.Equal(t, "/status", store.get(t, "url.path", 0))
+	assert.Equal(t, "web.example.com", store.get(t, "url.domain", 0))
+	assert.Equal(t, int64(200), store.get(t, "http.response.status_code", 0))
+	assert.Equal(t, int64(2), store.get(t, "http.response.body.bytes", 0))
+	assert.Equal(t, true, store.get(t, "http2.h2c_upgrade", 0))
+
+	// Later streams on the upgraded connection are decoded normally.
+	private = unaryCall(plugin, tuple, private, client, server, 3, "/health.Health/Check", "0")
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "health.Health", store.get(t, "grpc.service", 1))
+}
+
+func TestRSTStream(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+	private = startH2C(plugin, tuple, private, client, server)
+
+	client.headers(1, false,
+		":method", "POST",
+		":scheme", "http",
+		":authority", "svc:8080",
+		":path", "/upload",
+	)
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+	_ = server.framer.WriteRSTStream(1, http2.ErrCodeRefusedStream)
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, "status", 0))
+	assert.Equal(t, "REFUSED_STREAM", store.get(t, "http2.error_code", 0))
+	assert.Equal(t, "server", store.get(t, "http2.reset_by", 0))
+	assert.Empty(t, private.(*connection).streams)
+}
+
+func TestServerSettingsFirst(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+
+	// The capture starts after the client preface, so the server SETTINGS
+	// frame is the first thing seen.
+	_ = server.framer.WriteSettings()
+	private = plugin.Parse(&protos.Packet{Payload: server.take()}, tuple, tcp.TCPDirectionReverse, private)
+	private = unaryCall(plugin, tuple, private, client, server, 1, "/helloworld.Greeter/SayHello", "0")
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "192.168.0.1", store.get(t, "client.ip", 0))
+	assert.Equal(t, "192.168.0.2", store.get(t, "server.ip", 0))
+	assert.Equal(t, "SayHello", store.get(t, "grpc.method", 0))
+}
+
+func TestHTTP1Ignored(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+
+	private = plugin.Parse(&protos.Packet{Payload: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: []byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n")}, tuple, tcp.TCPDirectionReverse, private)
+
+	assert.Empty(t, store.events)
+	conn, _ := private.(*connection)
+	require.NotNil(t, conn)
+	assert.True(t, conn.ignored)
+}
+
+func TestExpiredPublishesPending(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, nil)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+	private = startH2C(plugin, tuple, private, client, server)
+
+	client.headers(1, true,
+		":method", "GET",
+		":scheme", "http",
+		":authority", "slow.example.com",
+		":path", "/wait",
+	)
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+	assert.Empty(t, store.events)
+
+	plugin.Expired(tuple, private)
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, "status", 0))
+	assert.Equal(t, "Unmatched request", store.get(t, "error.message", 0))
+}
+
+func TestMaxStreams(t *testing.T) {
+	var store eventStore
+	plugin := http2ModForTests(t, &store, map[string]interface{}{
+		"max_streams": 2,
+	})
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	client, server := newPeer(), newPeer()
+	private = startH2C(plugin, tuple, private, client, server)
+
+	for _, id := range []uint32{1, 3, 5} {
+		client.headers(id, true, ":method", "GET", ":scheme", "http", ":authority", "a", ":path", "/")
+	}
+	private = plugin.Parse(&protos.Packet{Payload: client.take()}, tuple, tcp.TCPDirectionOriginal, private)
+	assert.Len(t, private.(*connection).streams, 2)
+}
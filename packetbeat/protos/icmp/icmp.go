@@ -125,6 +125,11 @@ func (icmp *icmpPlugin) Close() {
 	icmp.transactions.StopJanitor()
 }
 
+// Flush publishes all pending transactions.
+func (icmp *icmpPlugin) Flush() {
+	icmp.transactions.Flush()
+}
+
 func (icmp *icmpPlugin) setFromConfig(config *icmpConfig) {
 	icmp.sendRequest = config.SendRequest
 	icmp.sendResponse = config.SendResponse
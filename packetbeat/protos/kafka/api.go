@@ -0,0 +1,505 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafka
+
+import (
+	"strconv"
+)
+
+// API keys decoded beyond the request header.
+const (
+	apiProduce      int16 = 0
+	apiFetch        int16 = 1
+	apiMetadata     int16 = 3
+	apiOffsetCommit int16 = 8
+	apiJoinGroup    int16 = 11
+	apiApiVersions  int16 = 18
+)
+
+var apiNames = []string{
+	"Produce",
+	"Fetch",
+	"ListOffsets",
+	"Metadata",
+	"LeaderAndIsr",
+	"StopReplica",
+	"UpdateMetadata",
+	"ControlledShutdown",
+	"OffsetCommit",
+	"OffsetFetch",
+	"FindCoordinator",
+	"JoinGroup",
+	"Heartbeat",
+	"LeaveGroup",
+	"SyncGroup",
+	"DescribeGroups",
+	"ListGroups",
+	"SaslHandshake",
+	"ApiVersions",
+	"CreateTopics",
+	"DeleteTopics",
+	"DeleteRecords",
+	"InitProducerId",
+	"OffsetForLeaderEpoch",
+	"AddPartitionsToTxn",
+	"AddOffsetsToTxn",
+	"EndTxn",
+	"WriteTxnMarkers",
+	"TxnOffsetCommit",
+	"DescribeAcls",
+	"CreateAcls",
+	"DeleteAcls",
+	"DescribeConfigs",
+	"AlterConfigs",
+	"AlterReplicaLogDirs",
+	"DescribeLogDirs",
+	"SaslAuthenticate",
+	"CreatePartitions",
+	"CreateDelegationToken",
+	"RenewDelegationToken",
+	"ExpireDelegationToken",
+	"DescribeDelegationToken",
+	"DeleteGroups",
+	"ElectLeaders",
+	"IncrementalAlterConfigs",
+	"AlterPartitionReassignments",
+	"ListPartitionReassignments",
+	"OffsetDelete",
+	"DescribeClientQuotas",
+	"AlterClientQuotas",
+	"DescribeUserScramCredentials",
+	"AlterUserScramCredentials",
+}
+
+func apiName(key int16) string {
+	if key >= 0 && int(key) < len(apiNames) {
+		return apiNames[key]
+	}
+	return "Api" + strconv.Itoa(int(key))
+}
+
+// errorNames are indexed by error code + 1, starting at UNKNOWN_SERVER_ERROR (-1).
+var errorNames = []string{
+	"UNKNOWN_SERVER_ERROR",
+	"NONE",
+	"OFFSET_OUT_OF_RANGE",
+	"CORRUPT_MESSAGE",
+	"UNKNOWN_TOPIC_OR_PARTITION",
+	"INVALID_FETCH_SIZE",
+	"LEADER_NOT_AVAILABLE",
+	"NOT_LEADER_OR_FOLLOWER",
+	"REQUEST_TIMED_OUT",
+	"BROKER_NOT_AVAILABLE",
+	"REPLICA_NOT_AVAILABLE",
+	"MESSAGE_TOO_LARGE",
+	"STALE_CONTROLLER_EPOCH",
+	"OFFSET_METADATA_TOO_LARGE",
+	"NETWORK_EXCEPTION",
+	"COORDINATOR_LOAD_IN_PROGRESS",
+	"COORDINATOR_NOT_AVAILABLE",
+	"NOT_COORDINATOR",
+	"INVALID_TOPIC_EXCEPTION",
+	"RECORD_LIST_TOO_LARGE",
+	"NOT_ENOUGH_REPLICAS",
+	"NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	"INVALID_REQUIRED_ACKS",
+	"ILLEGAL_GENERATION",
+	"INCONSISTENT_GROUP_PROTOCOL",
+	"INVALID_GROUP_ID",
+	"UNKNOWN_MEMBER_ID",
+	"INVALID_SESSION_TIMEOUT",
+	"REBALANCE_IN_PROGRESS",
+	"INVALID_COMMIT_OFFSET_SIZE",
+	"TOPIC_AUTHORIZATION_FAILED",
+	"GROUP_AUTHORIZATION_FAILED",
+	"CLUSTER_AUTHORIZATION_FAILED",
+	"INVALID_TIMESTAMP",
+	"UNSUPPORTED_SASL_MECHANISM",
+	"ILLEGAL_SASL_STATE",
+	"UNSUPPORTED_VERSION",
+	"TOPIC_ALREADY_EXISTS",
+	"INVALID_PARTITIONS",
+	"INVALID_REPLICATION_FACTOR",
+	"INVALID_REPLICA_ASSIGNMENT",
+	"INVALID_CONFIG",
+	"NOT_CONTROLLER",
+	"INVALID_REQUEST",
+	"UNSUPPORTED_FOR_MESSAGE_FORMAT",
+	"POLICY_VIOLATION",
+	"OUT_OF_ORDER_SEQUENCE_NUMBER",
+	"DUPLICATE_SEQUENCE_NUMBER",
+	"INVALID_PRODUCER_EPOCH",
+	"INVALID_TXN_STATE",
+	"INVALID_PRODUCER_ID_MAPPING",
+	"INVALID_TRANSACTION_TIMEOUT",
+	"CONCURRENT_TRANSACTIONS",
+	"TRANSACTION_COORDINATOR_FENCED",
+	"TRANSACTIONAL_ID_AUTHORIZATION_FAILED",
+	"SECURITY_DISABLED",
+	"OPERATION_NOT_ATTEMPTED",
+	"KAFKA_STORAGE_ERROR",
+	"LOG_DIR_NOT_FOUND",
+	"SASL_AUTHENTICATION_FAILED",
+	"UNKNOWN_PRODUCER_ID",
+	"REASSIGNMENT_IN_PROGRESS",
+	"DELEGATION_TOKEN_AUTH_DISABLED",
+	"DELEGATION_TOKEN_NOT_FOUND",
+	"DELEGATION_TOKEN_OWNER_MISMATCH",
+	"DELEGATION_TOKEN_REQUEST_NOT_ALLOWED",
+	"DELEGATION_TOKEN_AUTHORIZATION_FAILED",
+	"DELEGATION_TOKEN_EXPIRED",
+	"INVALID_PRINCIPAL_TYPE",
+	"NON_EMPTY_GROUP",
+	"GROUP_ID_NOT_FOUND",
+	"FETCH_SESSION_ID_NOT_FOUND",
+	"INVALID_FETCH_SESSION_EPOCH",
+	"LISTENER_NOT_FOUND",
+	"TOPIC_DELETION_DISABLED",
+	"FENCED_LEADER_EPOCH",
+	"UNKNOWN_LEADER_EPOCH",
+	"UNSUPPORTED_COMPRESSION_TYPE",
+	"STALE_BROKER_EPOCH",
+	"OFFSET_NOT_AVAILABLE",
+	"MEMBER_ID_REQUIRED",
+	"PREFERRED_LEADER_NOT_AVAILABLE",
+	"GROUP_MAX_SIZE_REACHED",
+	"FENCED_INSTANCE_ID",
+}
+
+func errorName(code int16) string {
+	if i := int(code) + 1; i >= 0 && i < len(errorNames) {
+		return errorNames[i]
+	}
+	return "ERROR_" + strconv.Itoa(int(code))
+}
+
+// bodyDecoder decodes the body of a message of version v into m.
+type bodyDecoder func(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool)
+
+// apiSpec describes the versions of an API whose bodies are decoded.
+type apiSpec struct {
+	maxVersion      int16
+	flexibleVersion int16
+	request         bodyDecoder
+	response        bodyDecoder
+}
+
+var apiSpecs = map[int16]apiSpec{
+	apiProduce:      {13, 9, decodeProduceRequest, decodeProduceResponse},
+	apiFetch:        {16, 12, decodeFetchRequest, decodeFetchResponse},
+	apiMetadata:     {12, 9, decodeMetadataRequest, decodeMetadataResponse},
+	apiOffsetCommit: {9, 8, decodeOffsetCommitRequest, decodeOffsetCommitResponse},
+	apiJoinGroup:    {9, 6, decodeJoinGroupRequest, decodeJoinGroupResponse},
+	apiApiVersions:  {4, 3, decodeApiVersionsRequest, decodeApiVersionsResponse},
+}
+
+// isFlexible reports whether a version uses the compact encodings and
+// tagged fields introduced by KIP-482. It is false for APIs that are not
+// decoded.
+func isFlexible(key, version int16) bool {
+	spec, ok := apiSpecs[key]
+	return ok && version >= spec.flexibleVersion
+}
+
+// topicName reads the topic name, or the topic ID used instead of names by
+// recent versions, resolving it from the IDs learned from Metadata responses.
+func (p *kafkaPlugin) topicName(d *decoder, byID, flexible bool) string {
+	if !byID {
+		return d.string(flexible)
+	}
+	id := d.uuid()
+	if name, ok := p.topicIDs[id]; ok {
+		return name
+	}
+	return id
+}
+
+func decodeProduceRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 3 {
+		d.string(flexible) // transactional_id
+	}
+	m.acks = d.int16()
+	m.hasAcks = d.err == nil
+	d.int32() // timeout_ms
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		topic := p.topicName(d, v >= 13, flexible)
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			m.recordBytes += int64(d.bytes(flexible))
+			d.taggedFields(flexible)
+		}
+		m.addTopic(topic)
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeProduceResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		p.topicName(d, v >= 13, flexible)
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			d.int32() // index
+			m.setError(d.int16())
+			d.int64() // base_offset
+			if v >= 2 {
+				d.int64() // log_append_time_ms
+			}
+			if v >= 5 {
+				d.int64() // log_start_offset
+			}
+			if v >= 8 {
+				for k, ne := 0, d.array(flexible); k < ne && d.err == nil; k++ {
+					d.int32()          // batch_index
+					d.string(flexible) // batch_index_error_message
+					d.taggedFields(flexible)
+				}
+				d.string(flexible) // error_message
+			}
+			d.taggedFields(flexible)
+		}
+		d.taggedFields(flexible)
+	}
+	if v >= 1 {
+		m.setThrottle(d.int32())
+	}
+}
+
+func decodeFetchRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v < 15 {
+		d.int32() // replica_id
+	}
+	d.int32() // max_wait_ms
+	d.int32() // min_bytes
+	if v >= 3 {
+		d.int32() // max_bytes
+	}
+	if v >= 4 {
+		d.int8() // isolation_level
+	}
+	if v >= 7 {
+		d.int32() // session_id
+		d.int32() // session_epoch
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		topic := p.topicName(d, v >= 13, flexible)
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			if v >= 9 {
+				d.int32() // current_leader_epoch
+			}
+			d.int64() // fetch_offset
+			if v >= 12 {
+				d.int32() // last_fetched_epoch
+			}
+			if v >= 5 {
+				d.int64() // log_start_offset
+			}
+			d.int32() // partition_max_bytes
+			d.taggedFields(flexible)
+		}
+		m.addTopic(topic)
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeFetchResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 1 {
+		m.setThrottle(d.int32())
+	}
+	if v >= 7 {
+		m.setError(d.int16())
+		d.int32() // session_id
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		p.topicName(d, v >= 13, flexible)
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			d.int32() // partition_index
+			m.setError(d.int16())
+			d.int64() // high_watermark
+			if v >= 4 {
+				d.int64() // last_stable_offset
+			}
+			if v >= 5 {
+				d.int64() // log_start_offset
+			}
+			if v >= 4 {
+				for k, na := 0, d.array(flexible); k < na && d.err == nil; k++ {
+					d.int64() // producer_id
+					d.int64() // first_offset
+					d.taggedFields(flexible)
+				}
+			}
+			if v >= 11 {
+				d.int32() // preferred_read_replica
+			}
+			m.recordBytes += int64(d.bytes(flexible))
+			d.taggedFields(flexible)
+		}
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeMetadataRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		var id string
+		if v >= 10 {
+			id = d.uuid()
+		}
+		name := d.string(flexible)
+		if name == "" {
+			name = id
+		}
+		m.addTopic(name)
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeMetadataResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 3 {
+		m.setThrottle(d.int32())
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		d.int32()          // node_id
+		d.string(flexible) // host
+		d.int32()          // port
+		if v >= 1 {
+			d.string(flexible) // rack
+		}
+		d.taggedFields(flexible)
+	}
+	if v >= 2 {
+		d.string(flexible) // cluster_id
+	}
+	if v >= 1 {
+		d.int32() // controller_id
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		m.setError(d.int16())
+		name := d.string(flexible)
+		if v >= 10 {
+			if id := d.uuid(); name != "" && d.err == nil {
+				p.learnTopicID(id, name)
+			}
+		}
+		if v >= 1 {
+			d.bool() // is_internal
+		}
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			m.setError(d.int16())
+			d.int32() // partition_index
+			d.int32() // leader_id
+			if v >= 7 {
+				d.int32() // leader_epoch
+			}
+			d.int32Array(flexible) // replica_nodes
+			d.int32Array(flexible) // isr_nodes
+			if v >= 5 {
+				d.int32Array(flexible) // offline_replicas
+			}
+			d.taggedFields(flexible)
+		}
+		if v >= 8 {
+			d.int32() // topic_authorized_operations
+		}
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeOffsetCommitRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	m.groupID = d.string(flexible)
+	if v >= 1 {
+		m.generationID = d.int32()
+		m.hasGeneration = d.err == nil
+		m.memberID = d.string(flexible)
+	}
+	if v >= 7 {
+		d.string(flexible) // group_instance_id
+	}
+	if v >= 2 && v <= 4 {
+		d.int64() // retention_time_ms
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		topic := d.string(flexible)
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			d.int64() // committed_offset
+			if v >= 6 {
+				d.int32() // committed_leader_epoch
+			}
+			if v == 1 {
+				d.int64() // commit_timestamp
+			}
+			d.string(flexible) // committed_metadata
+			d.taggedFields(flexible)
+		}
+		m.addTopic(topic)
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeOffsetCommitResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 3 {
+		m.setThrottle(d.int32())
+	}
+	for i, n := 0, d.array(flexible); i < n && d.err == nil; i++ {
+		d.string(flexible) // name
+		for j, np := 0, d.array(flexible); j < np && d.err == nil; j++ {
+			d.int32() // partition_index
+			m.setError(d.int16())
+			d.taggedFields(flexible)
+		}
+		d.taggedFields(flexible)
+	}
+}
+
+func decodeJoinGroupRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	m.groupID = d.string(flexible)
+	d.int32() // session_timeout_ms
+	if v >= 1 {
+		d.int32() // rebalance_timeout_ms
+	}
+	m.memberID = d.string(flexible)
+}
+
+func decodeJoinGroupResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 2 {
+		m.setThrottle(d.int32())
+	}
+	m.setError(d.int16())
+	m.generationID = d.int32()
+	m.hasGeneration = d.err == nil
+	if v >= 7 {
+		d.string(flexible) // protocol_type
+	}
+	d.string(flexible) // protocol_name
+	d.string(flexible) // leader
+	if v >= 9 {
+		d.bool() // skip_assignment
+	}
+	m.memberID = d.string(flexible)
+}
+
+func decodeApiVersionsRequest(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	if v >= 3 {
+		m.clientSoftwareName = d.string(flexible)
+		m.clientSoftwareVersion = d.string(flexible)
+	}
+}
+
+func decodeApiVersionsResponse(p *kafkaPlugin, d *decoder, m *message, v int16, flexible bool) {
+	m.setError(d.int16())
+}
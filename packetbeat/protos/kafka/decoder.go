@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+var errTruncated = errors.New("kafka message truncated")
+
+// decoder reads the primitive types of the Kafka protocol. Once a read fails
+// all further reads return zero values and err holds the first error.
+type decoder struct {
+	buf []byte
+	off int
+	err error
+}
+
+func newDecoder(buf []byte) *decoder {
+	return &decoder{buf: buf}
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf)-d.off {
+		d.err = errTruncated
+		d.off = len(d.buf)
+		return nil
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *decoder) skip(n int) {
+	d.take(n)
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf[d.off:])
+	if n <= 0 {
+		d.err = errTruncated
+		d.off = len(d.buf)
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// length reads the length prefix of a string, bytes or array field. Compact
+// fields of flexible versions store the length plus one as unsigned varint,
+// so -1 is returned for null values in both encodings.
+func (d *decoder) length(compact bool, size int) int {
+	if compact {
+		return int(d.uvarint()) - 1
+	}
+	if size == 2 {
+		return int(d.int16())
+	}
+	return int(d.int32())
+}
+
+// string reads a (nullable) string, null strings are returned as "".
+func (d *decoder) string(compact bool) string {
+	n := d.length(compact, 2)
+	if n <= 0 {
+		return ""
+	}
+	return string(d.take(n))
+}
+
+// bytes skips a (nullable) bytes field and returns its length.
+func (d *decoder) bytes(compact bool) int {
+	n := d.length(compact, 4)
+	if n <= 0 {
+		return 0
+	}
+	d.skip(n)
+	return n
+}
+
+// array reads the length of an array, null arrays have length -1.
+func (d *decoder) array(compact bool) int {
+	n := d.length(compact, 4)
+	// Every element is at least one byte long, this protects the callers
+	// from looping on corrupt lengths.
+	if n > len(d.buf)-d.off && d.err == nil {
+		d.err = errTruncated
+		d.off = len(d.buf)
+	}
+	return n
+}
+
+// int32Array skips an array of int32 values.
+func (d *decoder) int32Array(compact bool) {
+	if n := d.array(compact); n > 0 {
+		d.skip(4 * n)
+	}
+}
+
+func (d *decoder) uuid() string {
+	b := d.take(16)
+	if b == nil {
+		return ""
+	}
+	// Topic IDs are displayed URL-safe base64 encoded by the Kafka tools.
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// taggedFields skips the tagged fields section that ends every structure
+// in flexible versions.
+func (d *decoder) taggedFields(flexible bool) {
+	if !flexible {
+		return
+	}
+	n := d.uvarint()
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		d.uvarint() // tag
+		d.skip(int(d.uvarint()))
+	}
+}
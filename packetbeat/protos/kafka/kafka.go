@@ -0,0 +1,592 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package kafka implements a Packetbeat analyzer for the Kafka wire protocol.
+// Requests and responses are correlated by correlation ID. The request header
+// is decoded for every API, the bodies of the most common APIs are decoded to
+// report topics, partitions, consumer groups and error codes.
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// requestHeaderLen is the size of a request header with a null client ID.
+	requestHeaderLen = 10
+	// maxAPIKey and maxAPIVersion bound the header values accepted as a
+	// Kafka request, they are used to detect streams that aren't Kafka.
+	maxAPIKey     = 1000
+	maxAPIVersion = 100
+	// maxFrameSize is the largest message size accepted.
+	maxFrameSize = 1 << 30
+	// prefixSize is the number of bytes decoded from messages larger than
+	// max_message_size.
+	prefixSize = 64 * 1024
+
+	maxPendingRequests  = 1000
+	maxListedPartitions = 100
+	maxTopicIDs         = 10000
+)
+
+var (
+	unmatchedRequests  = monitoring.NewInt(nil, "kafka.unmatched_requests")
+	unmatchedResponses = monitoring.NewInt(nil, "kafka.unmatched_responses")
+	truncatedMessages  = monitoring.NewInt(nil, "kafka.truncated_messages")
+)
+
+type kafkaPlugin struct {
+	ports              []int
+	transactionTimeout time.Duration
+	maxMessageSize     int
+
+	// topicIDs maps the topic IDs seen in Metadata responses to topic names,
+	// recent versions of Produce and Fetch identify topics by ID only.
+	topicIDs map[string]string
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+	logger  *logp.Logger
+	isDebug bool
+}
+
+type stream struct {
+	applayer.Stream
+	isClient bool
+	// ts is the time the message being buffered started.
+	ts time.Time
+	// skip is the number of bytes left of a partially decoded message.
+	skip int
+}
+
+type connection struct {
+	streams [2]*stream
+	pending map[int32]*message
+	// order holds the correlation IDs of the pending requests in the order
+	// they were sent, brokers answer the requests of a connection in order.
+	order []int32
+}
+
+type message struct {
+	ts        time.Time
+	size      int
+	truncated bool
+
+	apiKey        int16
+	apiVersion    int16
+	correlationID int32
+	clientID      string
+
+	topics      []string
+	partitions  []string
+	recordBytes int64
+
+	acks    int16
+	hasAcks bool
+
+	groupID       string
+	memberID      string
+	generationID  int32
+	hasGeneration bool
+
+	errorCode   int16
+	throttleMs  int32
+	hasThrottle bool
+
+	clientSoftwareName    string
+	clientSoftwareVersion string
+
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+	direction    uint8
+}
+
+func (m *message) addTopic(name string) {
+	if name == "" {
+		return
+	}
+	for _, t := range m.topics {
+		if t == name {
+			return
+		}
+	}
+	m.topics = append(m.topics, name)
+}
+
+func (m *message) addPartition(topic string, index int32) {
+	if len(m.partitions) < maxListedPartitions {
+		m.partitions = append(m.partitions, fmt.Sprintf("%s-%d", topic, index))
+	}
+}
+
+// setError records the first error code other than NONE.
+func (m *message) setError(code int16) {
+	if m.errorCode == 0 {
+		m.errorCode = code
+	}
+}
+
+func (m *message) setThrottle(ms int32) {
+	m.throttleMs = ms
+	m.hasThrottle = true
+}
+
+func init() {
+	protos.Register("kafka", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &kafkaPlugin{}
+	p.logger = logger.Named("kafka")
+	p.isDebug = p.logger.IsDebug()
+
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	p.init(results, watcher, &config)
+	return p, nil
+}
+
+func (p *kafkaPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *kafkaConfig) {
+	p.ports = config.Ports
+	p.transactionTimeout = config.TransactionTimeout
+	p.maxMessageSize = config.MaxMessageSize
+	if p.maxMessageSize > tcp.TCPMaxDataInStream/2 {
+		p.maxMessageSize = tcp.TCPMaxDataInStream / 2
+	}
+	p.topicIDs = map[string]string{}
+	p.results = results
+	p.watcher = watcher
+}
+
+//go:inline
+func (p *kafkaPlugin) debugf(format string, args ...interface{}) {
+	if p.isDebug {
+		p.logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *kafkaPlugin) GetPorts() []int {
+	return p.ports
+}
+
+func (p *kafkaPlugin) ConnectionTimeout() time.Duration {
+	return p.transactionTimeout
+}
+
+func (p *kafkaPlugin) isServerPort(port uint16) bool {
+	for _, sPort := range p.ports {
+		if uint16(sPort) == port {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *kafkaPlugin) ensureConnection(private protos.ProtocolData) *connection {
+	if conn, ok := private.(*connection); ok && conn != nil {
+		return conn
+	}
+	return &connection{pending: map[int32]*message{}}
+}
+
+func (p *kafkaPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := p.ensureConnection(private)
+
+	st := conn.streams[dir]
+	if st == nil {
+		dstPort := tcptuple.DstPort
+		if dir == tcp.TCPDirectionReverse {
+			dstPort = tcptuple.SrcPort
+		}
+		st = &stream{isClient: p.isServerPort(dstPort)}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if st.Buf.Len() == 0 {
+		st.ts = pkt.Ts
+	}
+	if err := st.Append(payload); err != nil {
+		p.debugf("%v, dropping TCP stream", err)
+		conn.streams[dir] = nil
+		return conn
+	}
+
+	for {
+		buf := st.Buf.Bytes()
+		if len(buf) < 4 {
+			break
+		}
+		total := int(binary.BigEndian.Uint32(buf)) + 4
+		if total < 8 || total > maxFrameSize {
+			p.debugf("invalid message size %d, dropping TCP stream", total-4)
+			conn.streams[dir] = nil
+			return conn
+		}
+		truncated := false
+		if len(buf) < total {
+			if total <= p.maxMessageSize || len(buf) < prefixSize {
+				// wait for more data
+				break
+			}
+			truncated = true
+			st.skip = total - len(buf)
+		} else {
+			buf = buf[:total]
+		}
+		if !p.handleMessage(conn, st, buf, total, truncated, tcptuple, dir) {
+			p.debugf("not a Kafka message, dropping TCP stream")
+			conn.streams[dir] = nil
+			return conn
+		}
+		_ = st.Buf.Advance(len(buf))
+		st.Buf.Reset()
+		st.ts = pkt.Ts
+	}
+	return conn
+}
+
+// handleMessage decodes a message and correlates it. buf holds the whole
+// message, or its beginning if truncated. It returns false if the message
+// isn't a valid Kafka message.
+func (p *kafkaPlugin) handleMessage(
+	conn *connection,
+	st *stream,
+	buf []byte,
+	size int,
+	truncated bool,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) bool {
+	if truncated {
+		truncatedMessages.Inc()
+	}
+	m := &message{ts: st.ts, size: size, truncated: truncated}
+	d := newDecoder(buf[4:])
+	if !st.isClient {
+		m.correlationID = d.int32()
+		p.handleResponse(conn, m, d)
+		return true
+	}
+
+	if size < requestHeaderLen+4 {
+		return false
+	}
+	m.apiKey = d.int16()
+	m.apiVersion = d.int16()
+	m.correlationID = d.int32()
+	m.clientID = d.string(false)
+	if m.apiKey < 0 || m.apiKey > maxAPIKey || m.apiVersion < 0 || m.apiVersion > maxAPIVersion || d.err != nil {
+		return false
+	}
+	if spec, ok := apiSpecs[m.apiKey]; ok && m.apiVersion <= spec.maxVersion {
+		flexible := m.apiVersion >= spec.flexibleVersion
+		d.taggedFields(flexible)
+		spec.request(p, d, m, m.apiVersion, flexible)
+	}
+
+	m.tcpTuple = *tcptuple
+	m.direction = dir
+	m.cmdlineTuple = p.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	p.debugf("request %s v%d correlation_id=%d size=%d", apiName(m.apiKey), m.apiVersion, m.correlationID, size)
+
+	if m.apiKey == apiProduce && m.hasAcks && m.acks == 0 {
+		// Produce requests with acks=0 are not answered by the broker.
+		p.publish(m, nil)
+		return true
+	}
+	if _, exists := conn.pending[m.correlationID]; exists {
+		p.expire(conn, m.correlationID)
+	}
+	if len(conn.order) >= maxPendingRequests {
+		p.expire(conn, conn.order[0])
+	}
+	conn.pending[m.correlationID] = m
+	conn.order = append(conn.order, m.correlationID)
+	return true
+}
+
+func (p *kafkaPlugin) handleResponse(conn *connection, m *message, d *decoder) {
+	req, ok := conn.pending[m.correlationID]
+	if !ok {
+		p.debugf("response with unknown correlation_id=%d", m.correlationID)
+		unmatchedResponses.Inc()
+		return
+	}
+	// Responses are sent in request order, requests left before the
+	// matching one were lost.
+	for conn.order[0] != m.correlationID {
+		p.expire(conn, conn.order[0])
+	}
+	conn.order = conn.order[1:]
+	delete(conn.pending, m.correlationID)
+
+	if spec, ok := apiSpecs[req.apiKey]; ok && req.apiVersion <= spec.maxVersion {
+		flexible := req.apiVersion >= spec.flexibleVersion
+		// ApiVersions responses always use header version 0, so clients
+		// can parse them before knowing the versions supported by the broker.
+		if req.apiKey != apiApiVersions {
+			d.taggedFields(flexible)
+		}
+		spec.response(p, d, m, req.apiVersion, flexible)
+	}
+	p.publish(req, m)
+}
+
+// expire publishes the request with the given correlation ID as unmatched.
+func (p *kafkaPlugin) expire(conn *connection, correlationID int32) {
+	for i, id := range conn.order {
+		if id == correlationID {
+			conn.order = append(conn.order[:i], conn.order[i+1:]...)
+			break
+		}
+	}
+	if req, ok := conn.pending[correlationID]; ok {
+		delete(conn.pending, correlationID)
+		unmatchedRequests.Inc()
+		p.publish(req, nil)
+	}
+}
+
+func (p *kafkaPlugin) learnTopicID(id, name string) {
+	if len(p.topicIDs) >= maxTopicIDs {
+		p.topicIDs = map[string]string{}
+	}
+	p.topicIDs[id] = name
+}
+
+func (p *kafkaPlugin) publish(req, resp *message) {
+	if p.results != nil {
+		p.results(p.newTransaction(req, resp))
+	}
+}
+
+func (p *kafkaPlugin) newTransaction(req, resp *message) beat.Event {
+	source, destination := common.MakeEndpointPair(req.tcpTuple.BaseTuple, req.cmdlineTuple)
+	src, dst := &source, &destination
+	if req.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(req.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.Source.Bytes = int64(req.size)
+	pbf.Event.Dataset = "kafka"
+	pbf.Event.Start = req.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	name := apiName(req.apiKey)
+	pbf.Event.Action = "kafka." + snakeCase(name)
+
+	resource := strings.Join(req.topics, ",")
+	if resource == "" {
+		resource = req.groupID
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["method"] = name
+	if resource != "" {
+		fields["resource"] = resource
+		fields["query"] = name + " " + resource
+	} else {
+		fields["query"] = name
+	}
+
+	kafka := mapstr.M{
+		"api_key":        req.apiKey,
+		"api_version":    req.apiVersion,
+		"correlation_id": req.correlationID,
+	}
+	if req.clientID != "" {
+		kafka["client_id"] = req.clientID
+	}
+	if len(req.topics) != 0 {
+		kafka["topics"] = req.topics
+	}
+	if len(req.partitions) != 0 {
+		kafka["partitions"] = req.partitions
+	}
+	if req.hasAcks {
+		kafka["acks"] = req.acks
+	}
+	if req.apiKey == apiProduce && !req.truncated {
+		kafka["request"] = mapstr.M{"record_bytes": req.recordBytes}
+	}
+	groupID, memberID := req.groupID, req.memberID
+	if groupID != "" {
+		kafka["group_id"] = groupID
+	}
+	if req.clientSoftwareName != "" {
+		kafka["client_software"] = mapstr.M{
+			"name":    req.clientSoftwareName,
+			"version": req.clientSoftwareVersion,
+		}
+	}
+
+	status := common.OK_STATUS
+	switch {
+	case resp == nil && req.apiKey == apiProduce && req.hasAcks && req.acks == 0:
+		// not answered by design
+	case resp == nil:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched request")
+	default:
+		pbf.Event.End = resp.ts
+		pbf.Destination.Bytes = int64(resp.size)
+		if resp.errorCode != 0 {
+			status = common.ERROR_STATUS
+			kafka["error_code"] = resp.errorCode
+			kafka["error"] = errorName(resp.errorCode)
+		}
+		if resp.hasThrottle {
+			kafka["throttle_time_ms"] = resp.throttleMs
+		}
+		if req.apiKey == apiFetch && !resp.truncated {
+			kafka["response"] = mapstr.M{"record_bytes": resp.recordBytes}
+		}
+		if resp.memberID != "" {
+			memberID = resp.memberID
+		}
+		if resp.hasGeneration {
+			kafka["generation_id"] = resp.generationID
+		} else if req.hasGeneration {
+			kafka["generation_id"] = req.generationID
+		}
+	}
+	if memberID != "" {
+		kafka["member_id"] = memberID
+	}
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+
+	fields["kafka"] = kafka
+	fields["status"] = status
+	return evt
+}
+
+// snakeCase converts API names like OffsetCommit to offset_commit.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (p *kafkaPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return private, false
+	}
+	st := conn.streams[dir]
+	if st == nil {
+		return conn, false
+	}
+	if st.skip > 0 {
+		if nbytes <= st.skip {
+			st.skip -= nbytes
+			return conn, false
+		}
+		conn.streams[dir] = nil
+		return conn, false
+	}
+
+	// Messages are length prefixed, a gap inside a message only loses the
+	// part of the message that wasn't decoded yet.
+	buf := st.Buf.Bytes()
+	if len(buf) >= 4 {
+		total := int(binary.BigEndian.Uint32(buf)) + 4
+		if missing := total - len(buf); missing >= nbytes && total <= maxFrameSize {
+			if p.handleMessage(conn, st, buf, total, true, tcptuple, dir) {
+				st.skip = missing - nbytes
+				_ = st.Buf.Advance(len(buf))
+				st.Buf.Reset()
+				return conn, false
+			}
+		}
+	}
+	conn.streams[dir] = nil
+	return conn, false
+}
+
+func (p *kafkaPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests left without response.
+func (p *kafkaPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return
+	}
+	for len(conn.order) > 0 {
+		p.expire(conn, conn.order[0])
+	}
+}
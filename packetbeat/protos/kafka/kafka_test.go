@@ -0,0 +1,551 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kafka
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+)
+
+const serverPort = 9092
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func (e *eventStore) get(t *testing.T, idx int, key string) interface{} {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	v, err := e.events[idx].Fields.GetValue(key)
+	require.NoError(t, err, "missing %s in event %d: %v", key, idx, e.events[idx].Fields)
+	return v
+}
+
+func kafkaModForTests(t *testing.T, store *eventStore) *kafkaPlugin {
+	p, err := New(true, store.publish, &procs.ProcessesWatcher{}, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	kafka := p.(*kafkaPlugin)
+	kafka.ports = []int{serverPort}
+	return kafka
+}
+
+// encoder writes Kafka protocol primitives.
+type encoder struct {
+	buf      []byte
+	flexible bool
+}
+
+func (e *encoder) i8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) i16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) i32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) i64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+
+func (e *encoder) length(n, size int) {
+	switch {
+	case e.flexible:
+		e.buf = binary.AppendUvarint(e.buf, uint64(n+1))
+	case size == 2:
+		e.i16(int16(n))
+	default:
+		e.i32(int32(n))
+	}
+}
+
+func (e *encoder) str(s string) {
+	e.length(len(s), 2)
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) null() { e.length(-1, 2) }
+
+func (e *encoder) bytes(n int) {
+	e.length(n, 4)
+	e.buf = append(e.buf, make([]byte, n)...)
+}
+
+func (e *encoder) array(n int) { e.length(n, 4) }
+
+func (e *encoder) uuid(id [16]byte) { e.buf = append(e.buf, id[:]...) }
+
+func (e *encoder) tags() {
+	if e.flexible {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func frame(body []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+func request(key, version int16, correlationID int32, flexible bool, body func(e *encoder)) []byte {
+	e := &encoder{}
+	e.i16(key)
+	e.i16(version)
+	e.i32(correlationID)
+	e.str("test-client")
+	e.flexible = flexible
+	e.tags()
+	body(e)
+	return frame(e.buf)
+}
+
+func response(correlationID int32, flexible, headerTags bool, body func(e *encoder)) []byte {
+	e := &encoder{flexible: flexible}
+	e.i32(correlationID)
+	if headerTags {
+		e.tags()
+	}
+	body(e)
+	return frame(e.buf)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: serverPort,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+func produceRequest(version int16, correlationID int32, acks int16, recordBytes int) []byte {
+	return request(apiProduce, version, correlationID, version >= 9, func(e *encoder) {
+		e.null() // transactional_id
+		e.i16(acks)
+		e.i32(30000)
+		e.array(1)
+		e.str("orders")
+		e.array(2)
+		for i := int32(0); i < 2; i++ {
+			e.i32(i)
+			e.bytes(recordBytes)
+			e.tags()
+		}
+		e.tags()
+		e.tags()
+	})
+}
+
+func produceResponse(version int16, correlationID int32, errorCode int16) []byte {
+	flexible := version >= 9
+	return response(correlationID, flexible, flexible, func(e *encoder) {
+		e.array(1)
+		e.str("orders")
+		e.array(2)
+		for i := int32(0); i < 2; i++ {
+			e.i32(i)
+			e.i16(errorCode * int16(i))
+			e.i64(42)
+			e.i64(-1)
+			e.i64(0)
+			if version >= 8 {
+				e.array(0)
+				e.null()
+			}
+			e.tags()
+		}
+		e.tags()
+		e.i32(5)
+		e.tags()
+	})
+}
+
+func TestProduce(t *testing.T) {
+	for _, version := range []int16{7, 9} {
+		for _, chunk := range []int{0, 3} {
+			var store eventStore
+			plugin := kafkaModForTests(t, &store)
+			tuple := testTCPTuple()
+			var private protos.ProtocolData
+			req := produceRequest(version, 7, -1, 100)
+			for payload := req; len(payload) > 0; {
+				n := len(payload)
+				if chunk > 0 {
+					n = min(n, chunk)
+				}
+				private = plugin.Parse(&protos.Packet{Payload: payload[:n]}, tuple, tcp.TCPDirectionOriginal, private)
+				payload = payload[n:]
+			}
+			assert.Empty(t, store.events)
+			resp := produceResponse(version, 7, 0)
+			for payload := resp; len(payload) > 0; {
+				n := len(payload)
+				if chunk > 0 {
+					n = min(n, chunk)
+				}
+				private = plugin.Parse(&protos.Packet{Payload: payload[:n]}, tuple, tcp.TCPDirectionReverse, private)
+				payload = payload[n:]
+			}
+
+			require.Len(t, store.events, 1, "version %d chunk %d", version, chunk)
+			assert.Equal(t, "kafka", store.get(t, 0, "type"))
+			assert.Equal(t, "OK", store.get(t, 0, "status"))
+			assert.Equal(t, "Produce", store.get(t, 0, "method"))
+			assert.Equal(t, "orders", store.get(t, 0, "resource"))
+			assert.Equal(t, "kafka.produce", store.get(t, 0, "event.action"))
+			assert.Equal(t, int16(version), store.get(t, 0, "kafka.api_version"))
+			assert.Equal(t, int32(7), store.get(t, 0, "kafka.correlation_id"))
+			assert.Equal(t, "test-client", store.get(t, 0, "kafka.client_id"))
+			assert.Equal(t, []string{"orders"}, store.get(t, 0, "kafka.topics"))
+			assert.Equal(t, []string{"orders-0", "orders-1"}, store.get(t, 0, "kafka.partitions"))
+			assert.Equal(t, int16(-1), store.get(t, 0, "kafka.acks"))
+			assert.Equal(t, int64(200), store.get(t, 0, "kafka.request.record_bytes"))
+			assert.Equal(t, int32(5), store.get(t, 0, "kafka.throttle_time_ms"))
+			assert.Equal(t, int64(len(req)), store.get(t, 0, "source.bytes"))
+			assert.Equal(t, int64(len(resp)), store.get(t, 0, "destination.bytes"))
+			assert.Equal(t, "192.168.0.1", store.get(t, 0, "client.ip"))
+		}
+	}
+}
+
+func TestProduceError(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: produceRequest(9, 1, 1, 10)}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: produceResponse(9, 1, 6)}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "failure", store.get(t, 0, "event.outcome"))
+	assert.Equal(t, int16(6), store.get(t, 0, "kafka.error_code"))
+	assert.Equal(t, "NOT_LEADER_OR_FOLLOWER", store.get(t, 0, "kafka.error"))
+}
+
+func TestProduceWithoutAcks(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: produceRequest(8, 1, 0, 10)}, tuple, tcp.TCPDirectionOriginal, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, int16(0), store.get(t, 0, "kafka.acks"))
+	assert.Empty(t, private.(*connection).pending)
+}
+
+func TestFetchByTopicID(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	id := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	// Metadata v12 maps the topic ID to its name.
+	private = plugin.Parse(&protos.Packet{Payload: request(apiMetadata, 12, 1, true, func(e *encoder) {
+		e.array(1)
+		e.uuid([16]byte{})
+		e.str("payments")
+		e.tags()
+		e.i8(1) // allow_auto_topic_creation
+		e.i8(0) // include_topic_authorized_operations
+		e.tags()
+	})}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: response(1, true, true, func(e *encoder) {
+		e.i32(0)
+		e.array(1)
+		e.i32(1)
+		e.str("broker-1")
+		e.i32(9092)
+		e.null()
+		e.tags()
+		e.str("cluster")
+		e.i32(1)
+		e.array(1)
+		e.i16(0)
+		e.str("payments")
+		e.uuid(id)
+		e.i8(0)
+		e.array(1)
+		e.i16(0)
+		e.i32(0)
+		e.i32(1)
+		e.i32(0)
+		e.array(1)
+		e.i32(1)
+		e.array(1)
+		e.i32(1)
+		e.array(0)
+		e.tags()
+		e.i32(0)
+		e.tags()
+		e.tags()
+	})}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Metadata", store.get(t, 0, "method"))
+	assert.Equal(t, []string{"payments"}, store.get(t, 0, "kafka.topics"))
+
+	private = plugin.Parse(&protos.Packet{Payload: request(apiFetch, 13, 2, true, func(e *encoder) {
+		e.i32(-1)
+		e.i32(500)
+		e.i32(1)
+		e.i32(50 << 20)
+		e.i8(0)
+		e.i32(0)
+		e.i32(-1)
+		e.array(1)
+		e.uuid(id)
+		e.array(1)
+		e.i32(0)
+		e.i32(-1)
+		e.i64(100)
+		e.i32(-1)
+		e.i64(0)
+		e.i32(1 << 20)
+		e.tags()
+		e.tags()
+		e.array(0)
+		e.str("")
+		e.tags()
+	})}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: response(2, true, true, func(e *encoder) {
+		e.i32(0)
+		e.i16(0)
+		e.i32(0)
+		e.array(1)
+		e.uuid(id)
+		e.array(1)
+		e.i32(0)
+		e.i16(0)
+		e.i64(150)
+		e.i64(150)
+		e.i64(0)
+		e.array(-1)
+		e.i32(-1)
+		e.bytes(512)
+		e.tags()
+		e.tags()
+		e.tags()
+	})}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+	assert.Equal(t, []string{"payments"}, store.get(t, 1, "kafka.topics"))
+	assert.Equal(t, []string{"payments-0"}, store.get(t, 1, "kafka.partitions"))
+	assert.Equal(t, int64(512), store.get(t, 1, "kafka.response.record_bytes"))
+}
+
+func TestOffsetCommit(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: request(apiOffsetCommit, 8, 3, true, func(e *encoder) {
+		e.str("billing")
+		e.i32(12)
+		e.str("consumer-1-abc")
+		e.null()
+		e.array(1)
+		e.str("invoices")
+		e.array(1)
+		e.i32(3)
+		e.i64(1000)
+		e.i32(-1)
+		e.null()
+		e.tags()
+		e.tags()
+		e.tags()
+	})}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: response(3, true, true, func(e *encoder) {
+		e.i32(0)
+		e.array(1)
+		e.str("invoices")
+		e.array(1)
+		e.i32(3)
+		e.i16(22)
+		e.tags()
+		e.tags()
+		e.tags()
+	})}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "ILLEGAL_GENERATION", store.get(t, 0, "kafka.error"))
+	assert.Equal(t, "billing", store.get(t, 0, "kafka.group_id"))
+	assert.Equal(t, "consumer-1-abc", store.get(t, 0, "kafka.member_id"))
+	assert.Equal(t, int32(12), store.get(t, 0, "kafka.generation_id"))
+	assert.Equal(t, []string{"invoices-3"}, store.get(t, 0, "kafka.partitions"))
+	assert.Equal(t, "kafka.offset_commit", store.get(t, 0, "event.action"))
+}
+
+func TestJoinGroup(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: request(apiJoinGroup, 5, 4, false, func(e *encoder) {
+		e.str("billing")
+		e.i32(45000)
+		e.i32(300000)
+		e.str("")
+		e.null()
+		e.str("consumer")
+		e.array(0)
+	})}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: response(4, false, false, func(e *encoder) {
+		e.i32(0)
+		e.i16(0)
+		e.i32(13)
+		e.str("range")
+		e.str("consumer-1-abc")
+		e.str("consumer-1-abc")
+		e.array(0)
+	})}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, "billing", store.get(t, 0, "resource"))
+	assert.Equal(t, int32(13), store.get(t, 0, "kafka.generation_id"))
+	assert.Equal(t, "consumer-1-abc", store.get(t, 0, "kafka.member_id"))
+}
+
+func TestApiVersions(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: request(apiApiVersions, 3, 0, true, func(e *encoder) {
+		e.str("apache-kafka-java")
+		e.str("3.7.0")
+		e.tags()
+	})}, tuple, tcp.TCPDirectionOriginal, private)
+	// The response header has no tagged fields.
+	private = plugin.Parse(&protos.Packet{Payload: response(0, true, false, func(e *encoder) {
+		e.i16(35)
+		e.array(0)
+		e.i32(0)
+		e.tags()
+	})}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "apache-kafka-java", store.get(t, 0, "kafka.client_software.name"))
+	assert.Equal(t, "3.7.0", store.get(t, 0, "kafka.client_software.version"))
+	assert.Equal(t, "UNSUPPORTED_VERSION", store.get(t, 0, "kafka.error"))
+}
+
+func TestPipelinedRequests(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	heartbeat := func(correlationID int32) []byte {
+		return request(12, 4, correlationID, true, func(e *encoder) {
+			e.str("billing")
+			e.i32(1)
+			e.str("m")
+			e.null()
+			e.tags()
+		})
+	}
+	var pipelined []byte
+	for id := int32(10); id < 13; id++ {
+		pipelined = append(pipelined, heartbeat(id)...)
+	}
+	private = plugin.Parse(&protos.Packet{Payload: pipelined}, tuple, tcp.TCPDirectionOriginal, private)
+
+	// The response to request 10 was lost.
+	private = plugin.Parse(&protos.Packet{Payload: response(11, false, false, func(e *encoder) {})}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, int32(10), store.get(t, 0, "kafka.correlation_id"))
+	assert.Equal(t, "Unmatched request", store.get(t, 0, "error.message"))
+	assert.Equal(t, int32(11), store.get(t, 1, "kafka.correlation_id"))
+	assert.Equal(t, "Heartbeat", store.get(t, 1, "method"))
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+
+	private = plugin.Parse(&protos.Packet{Payload: response(99, false, false, func(e *encoder) {})}, tuple, tcp.TCPDirectionReverse, private)
+	assert.Len(t, store.events, 2)
+
+	plugin.Expired(tuple, private)
+	require.Len(t, store.events, 3)
+	assert.Equal(t, int32(12), store.get(t, 2, "kafka.correlation_id"))
+	assert.Equal(t, "Error", store.get(t, 2, "status"))
+}
+
+func TestNotKafka(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")}, tuple, tcp.TCPDirectionOriginal, private)
+
+	assert.Empty(t, store.events)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+}
+
+func TestLargeMessage(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	plugin.maxMessageSize = prefixSize
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+
+	for payload := produceRequest(7, 1, -1, 1<<20); len(payload) > 0; {
+		n := min(len(payload), 1460)
+		private = plugin.Parse(&protos.Packet{Payload: payload[:n]}, tuple, tcp.TCPDirectionOriginal, private)
+		payload = payload[n:]
+	}
+	private = plugin.Parse(&protos.Packet{Payload: produceResponse(7, 1, 0)}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, []string{"orders"}, store.get(t, 0, "kafka.topics"))
+	_, err := store.events[0].Fields.GetValue("kafka.request.record_bytes")
+	assert.Error(t, err, "record bytes of truncated messages are unknown")
+}
+
+func TestGapInMessage(t *testing.T) {
+	var store eventStore
+	plugin := kafkaModForTests(t, &store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+
+	req := produceRequest(7, 1, -1, 4096)
+	private = plugin.Parse(&protos.Packet{Payload: req[:200]}, tuple, tcp.TCPDirectionOriginal, private)
+	private, _ = plugin.GapInStream(tuple, tcp.TCPDirectionOriginal, 1000, private)
+	private = plugin.Parse(&protos.Packet{Payload: req[1200:]}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: produceResponse(7, 1, 0)}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, int64(len(req)), store.get(t, 0, "source.bytes"))
+}
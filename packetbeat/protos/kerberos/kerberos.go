@@ -0,0 +1,225 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package kerberos implements a Packetbeat analyzer for the exchanges
+// between Kerberos clients and the Key Distribution Center (RFC 4120):
+// AS and TGS requests, their replies and KRB-ERROR messages, over UDP and
+// TCP. Only the unencrypted parts of the messages are decoded.
+package kerberos
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+var (
+	unmatchedRequests  = monitoring.NewInt(nil, "kerberos.unmatched_requests")
+	unmatchedResponses = monitoring.NewInt(nil, "kerberos.unmatched_responses")
+)
+
+type kerberosPlugin struct {
+	ports              []int
+	transactionTimeout time.Duration
+
+	// Requests sent over UDP waiting for a response, by IP and port tuple.
+	requests *common.Cache
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+	logger  *logp.Logger
+}
+
+func init() {
+	protos.Register("kerberos", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &kerberosPlugin{logger: logger.Named("kerberos")}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	p.init(results, watcher, &config)
+	return p, nil
+}
+
+func (p *kerberosPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *kerberosConfig) {
+	p.ports = config.Ports
+	p.transactionTimeout = config.TransactionTimeout
+	p.results = results
+	p.watcher = watcher
+
+	p.requests = common.NewCacheWithRemovalListener(
+		p.transactionTimeout,
+		protos.DefaultTransactionHashSize,
+		func(k common.Key, v common.Value) {
+			req, ok := v.(*message)
+			if !ok {
+				p.logger.Error("Expired value is not a *message.")
+				return
+			}
+			unmatchedRequests.Inc()
+			p.publish(req, nil, "udp")
+		})
+	p.requests.StartJanitor(p.transactionTimeout)
+}
+
+func (p *kerberosPlugin) GetPorts() []int {
+	return p.ports
+}
+
+func (p *kerberosPlugin) ConnectionTimeout() time.Duration {
+	return p.transactionTimeout
+}
+
+func (p *kerberosPlugin) Close() {
+	p.requests.StopJanitor()
+}
+
+// Flush publishes all the UDP requests left without response.
+func (p *kerberosPlugin) Flush() {
+	p.requests.Flush()
+}
+
+func (p *kerberosPlugin) publish(req, resp *message, transport string) {
+	if p.results != nil {
+		p.results(p.newEvent(req, resp, transport))
+	}
+}
+
+func (p *kerberosPlugin) newEvent(req, resp *message, transport string) beat.Event {
+	source, destination := common.MakeEndpointPair(req.tuple.BaseTuple, req.cmdlineTuple)
+
+	evt, pbf := pb.NewBeatEvent(req.ts)
+	pbf.SetSource(&source)
+	pbf.SetDestination(&destination)
+	pbf.Source.Bytes = int64(req.size)
+	pbf.Event.Dataset = "kerberos"
+	pbf.Event.Start = req.ts
+	pbf.Network.Transport = transport
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	method := requestTypeNames[req.msgType]
+	pbf.Event.Action = "kerberos." + strings.ToLower(method)
+
+	// Names missing from the request, like the client of a TGS request,
+	// are taken from the response.
+	client, service := *req, *req
+	if resp != nil {
+		if client.clientName == "" {
+			client = *resp
+		}
+		if service.serviceName == "" {
+			service = *resp
+		}
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["method"] = method
+	query := method
+	if service.serviceName != "" {
+		resource := principal(service.serviceName, service.serviceRealm)
+		fields["resource"] = resource
+		query += " " + resource
+	}
+	fields["query"] = query
+
+	kerberos := mapstr.M{}
+	if client.clientName != "" {
+		kerberos["client"] = mapstr.M{"name": client.clientName, "realm": client.clientRealm}
+		fields["user.name"] = client.clientName
+		pbf.AddUser(client.clientName)
+	}
+	if service.serviceName != "" {
+		kerberos["service"] = mapstr.M{"name": service.serviceName, "realm": service.serviceRealm}
+	}
+	if len(req.kdcOptions) != 0 {
+		kerberos["kdc_options"] = req.kdcOptions
+	}
+	if len(req.encryptionTypes) != 0 {
+		kerberos["encryption_types"] = req.encryptionTypes
+	}
+	if len(req.preauthentication) != 0 {
+		kerberos["preauthentication"] = req.preauthentication
+	}
+
+	status := common.OK_STATUS
+	switch {
+	case resp == nil:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched request")
+	case resp.msgType == msgKRBError:
+		krbError := mapstr.M{
+			"code": resp.errorCode,
+			"name": errorCodeName(resp.errorCode),
+		}
+		if resp.errorText != "" {
+			krbError["text"] = resp.errorText
+		}
+		kerberos["error"] = krbError
+		if resp.errorCode != errPreauthRequired && resp.errorCode != errResponseTooBig {
+			status = common.ERROR_STATUS
+		}
+	default:
+		if resp.ticketEncryptionType != "" {
+			kerberos["ticket"] = mapstr.M{"encryption_type": resp.ticketEncryptionType}
+		}
+		if resp.replyEncryptionType != "" {
+			kerberos["reply"] = mapstr.M{"encryption_type": resp.replyEncryptionType}
+		}
+	}
+	if resp != nil {
+		pbf.Event.End = resp.ts
+		pbf.Destination.Bytes = int64(resp.size)
+	}
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+
+	fields["kerberos"] = kerberos
+	fields["status"] = status
+	return evt
+}
+
+// principal returns the name of a principal qualified with its realm.
+func principal(name, realm string) string {
+	if realm == "" {
+		return name
+	}
+	return name + "@" + realm
+}
@@ -0,0 +1,161 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// Messages sent over TCP are preceded by their length (RFC 4120,
+	// section 7.2.2). The high bit of the length is reserved.
+	lengthPrefixSize = 4
+	maxMessageSize   = 1 << 20
+
+	maxPendingRequests = 16
+)
+
+type stream struct {
+	applayer.Stream
+	// ts is the time the message being buffered started.
+	ts time.Time
+}
+
+type connection struct {
+	streams [2]*stream
+	// pending holds the requests waiting for a response, in the order
+	// they were sent. The KDC answers them in order.
+	pending []*message
+}
+
+func (p *kerberosPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		conn = &connection{}
+	}
+
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+	}
+	if st.Buf.Len() == 0 {
+		st.ts = pkt.Ts
+	}
+	if err := st.Append(pkt.Payload); err != nil {
+		p.logger.Debugf("%v, dropping TCP stream", err)
+		conn.streams[dir] = nil
+		return conn
+	}
+
+	for st.Buf.Len() >= lengthPrefixSize {
+		buf := st.Buf.Bytes()
+		length := binary.BigEndian.Uint32(buf)
+		if length == 0 || length > maxMessageSize {
+			p.logger.Debugf("invalid Kerberos message length %d, dropping TCP stream", length)
+			conn.streams[dir] = nil
+			return conn
+		}
+		size := lengthPrefixSize + int(length)
+		if len(buf) < size {
+			break
+		}
+
+		m := &message{ts: st.ts, size: size, tuple: pkt.Tuple}
+		if err := decodeMessage(buf[lengthPrefixSize:size], m); err != nil {
+			p.logger.Debugf("Failed to decode Kerberos message from %s: %v, dropping TCP stream", &pkt.Tuple, err)
+			conn.streams[dir] = nil
+			return conn
+		}
+		p.handleMessage(conn, m)
+
+		_ = st.Buf.Advance(size)
+		st.Buf.Reset()
+		st.ts = pkt.Ts
+	}
+	return conn
+}
+
+func (p *kerberosPlugin) handleMessage(conn *connection, m *message) {
+	if m.isRequest() {
+		m.cmdlineTuple = p.watcher.FindProcessesTupleTCP(&m.tuple)
+		if len(conn.pending) >= maxPendingRequests {
+			p.expire(conn)
+		}
+		conn.pending = append(conn.pending, m)
+		return
+	}
+
+	if len(conn.pending) == 0 {
+		p.logger.Debugf("Kerberos response from %s without request", &m.tuple)
+		unmatchedResponses.Inc()
+		return
+	}
+	req := conn.pending[0]
+	conn.pending = conn.pending[1:]
+	p.publish(req, m, "tcp")
+}
+
+// expire publishes the oldest pending request as unmatched.
+func (p *kerberosPlugin) expire(conn *connection) {
+	req := conn.pending[0]
+	conn.pending = conn.pending[1:]
+	unmatchedRequests.Inc()
+	p.publish(req, nil, "tcp")
+}
+
+func (p *kerberosPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return private, false
+	}
+	// Message boundaries are lost.
+	conn.streams[dir] = nil
+	return conn, false
+}
+
+func (p *kerberosPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests left without response.
+func (p *kerberosPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return
+	}
+	for len(conn.pending) > 0 {
+		p.expire(conn)
+	}
+}
@@ -0,0 +1,118 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kerberos
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+func testTCPTuple() *common.TCPTuple {
+	t := common.TCPTupleFromIPPort(&clientToKDC, 1)
+	return &t
+}
+
+func framed(messages ...[]byte) []byte {
+	var out []byte
+	for _, m := range messages {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(m)))
+		out = append(out, m...)
+	}
+	return out
+}
+
+func TestTCPExchange(t *testing.T) {
+	store := &eventStore{}
+	plugin := kerberosModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+
+	// Both messages are split in small segments.
+	req := framed(asReq.encode())
+	for i := 0; i < len(req); i += 10 {
+		pkt := protos.Packet{Tuple: clientToKDC, Payload: req[i:min(i+10, len(req))]}
+		private = plugin.Parse(&pkt, tuple, tcp.TCPDirectionOriginal, private)
+	}
+	rep := reply(msgASRep, "EXAMPLE.COM", []string{"jdoe"}, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, 18, 18)
+	resp := framed(rep)
+	for i := 0; i < len(resp); i += 10 {
+		pkt := protos.Packet{Tuple: kdcToClient, Payload: resp[i:min(i+10, len(resp))]}
+		private = plugin.Parse(&pkt, tuple, tcp.TCPDirectionReverse, private)
+	}
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, "tcp", store.get(t, 0, "network.transport"))
+	assert.Equal(t, "10.0.0.1", store.get(t, 0, "source.ip"))
+	assert.EqualValues(t, 88, store.get(t, 0, "destination.port"))
+	assert.EqualValues(t, 4+len(asReq.encode()), store.get(t, 0, "source.bytes"))
+	assert.EqualValues(t, 4+len(rep), store.get(t, 0, "destination.bytes"))
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", store.get(t, 0, "kerberos.ticket.encryption_type"))
+}
+
+func TestTCPPipelined(t *testing.T) {
+	store := &eventStore{}
+	plugin := kerberosModForTests(t, store)
+	tuple := testTCPTuple()
+
+	tgsReq := request{msgType: msgTGSReq, padata: []int64{1}, realm: "EXAMPLE.COM", sname: []string{"HTTP", "www.example.com"}, etypes: []int64{18}}
+	req := protos.Packet{Tuple: clientToKDC, Payload: framed(asReq.encode(), tgsReq.encode(), tgsReq.encode())}
+	private := plugin.Parse(&req, tuple, tcp.TCPDirectionOriginal, nil)
+	resp := protos.Packet{Tuple: kdcToClient, Payload: framed(
+		krbError(24, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, "Preauthentication failed"),
+		reply(msgTGSRep, "EXAMPLE.COM", []string{"jdoe"}, "EXAMPLE.COM", tgsReq.sname, 18, 18),
+	)}
+	private = plugin.Parse(&resp, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "AS", store.get(t, 0, "method"))
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "KDC_ERR_PREAUTH_FAILED", store.get(t, 0, "kerberos.error.name"))
+	assert.Equal(t, "Preauthentication failed", store.get(t, 0, "kerberos.error.text"))
+	assert.Equal(t, "TGS", store.get(t, 1, "method"))
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+
+	plugin.Expired(tuple, private)
+	require.Len(t, store.events, 3)
+	assert.Equal(t, "TGS", store.get(t, 2, "method"))
+	assert.Equal(t, "Unmatched request", store.get(t, 2, "error.message"))
+}
+
+func TestTCPNotKerberos(t *testing.T) {
+	store := &eventStore{}
+	plugin := kerberosModForTests(t, store)
+	tuple := testTCPTuple()
+
+	req := protos.Packet{Tuple: clientToKDC, Payload: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")}
+	private := plugin.Parse(&req, tuple, tcp.TCPDirectionOriginal, nil)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+
+	req = protos.Packet{Tuple: clientToKDC, Payload: framed([]byte{0x30, 0x03, 0x02, 0x01, 0x05})}
+	private = plugin.Parse(&req, tuple, tcp.TCPDirectionOriginal, nil)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+	assert.Empty(t, store.events)
+}
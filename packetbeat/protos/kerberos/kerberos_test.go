@@ -0,0 +1,257 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kerberos
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber/bertest"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+)
+
+// Verify that the interfaces for TCP and UDP have been satisfied.
+var (
+	_ protos.TCPPlugin = &kerberosPlugin{}
+	_ protos.UDPPlugin = &kerberosPlugin{}
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func (e *eventStore) get(t *testing.T, idx int, key string) interface{} {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	v, err := e.events[idx].Fields.GetValue(key)
+	require.NoError(t, err, "missing %s in event %d: %v", key, idx, e.events[idx].Fields)
+	return v
+}
+
+func (e *eventStore) missing(t *testing.T, idx int, key string) {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	_, err := e.events[idx].Fields.GetValue(key)
+	assert.Error(t, err, "unexpected %s in event %d", key, idx)
+}
+
+func kerberosModForTests(t *testing.T, store *eventStore) *kerberosPlugin {
+	p, err := New(true, store.publish, &procs.ProcessesWatcher{}, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	t.Cleanup(p.(*kerberosPlugin).Close)
+	return p.(*kerberosPlugin)
+}
+
+// Kerberos message encoding helpers.
+
+func field(tag byte, parts ...[]byte) []byte { return bertest.TLV(0xa0|tag, parts...) }
+func gstr(s string) []byte                   { return bertest.TLV(0x1b, []byte(s)) }
+
+func principalName(nameType int64, components ...string) []byte {
+	var names [][]byte
+	for _, c := range components {
+		names = append(names, gstr(c))
+	}
+	return bertest.Seq(field(0, bertest.Integer(nameType)), field(1, bertest.Seq(names...)))
+}
+
+func encryptedData(etype int64) []byte {
+	return bertest.Seq(field(0, bertest.Integer(etype)), field(1, bertest.Integer(2)), field(2, bertest.TLV(0x04, []byte("ciphertext"))))
+}
+
+func padata(types ...int64) []byte {
+	var entries [][]byte
+	for _, typ := range types {
+		entries = append(entries, bertest.Seq(field(1, bertest.Integer(typ)), field(2, bertest.TLV(0x04, []byte{0x30, 0x00}))))
+	}
+	return bertest.Seq(entries...)
+}
+
+type request struct {
+	msgType int64
+	padata  []int64
+	options []byte
+	cname   []string
+	realm   string
+	sname   []string
+	etypes  []int64
+}
+
+func (r request) encode() []byte {
+	var etypes [][]byte
+	for _, etype := range r.etypes {
+		etypes = append(etypes, bertest.Integer(etype))
+	}
+	body := [][]byte{field(0, bertest.TLV(0x03, append([]byte{0}, r.options...)))}
+	if r.cname != nil {
+		body = append(body, field(1, principalName(1, r.cname...)))
+	}
+	body = append(body,
+		field(2, gstr(r.realm)),
+		field(3, principalName(2, r.sname...)),
+		field(5, bertest.TLV(0x18, []byte("20370913024805Z"))),
+		field(7, bertest.Integer(12345678)),
+		field(8, bertest.Seq(etypes...)),
+	)
+	parts := [][]byte{field(1, bertest.Integer(5)), field(2, bertest.Integer(r.msgType))}
+	if r.padata != nil {
+		parts = append(parts, field(3, padata(r.padata...)))
+	}
+	parts = append(parts, field(4, bertest.Seq(body...)))
+	return bertest.TLV(0x60|byte(r.msgType), bertest.Seq(parts...))
+}
+
+func reply(msgType int64, crealm string, cname []string, realm string, sname []string, ticketEtype, replyEtype int64) []byte {
+	ticket := bertest.TLV(0x61, bertest.Seq(
+		field(0, bertest.Integer(5)),
+		field(1, gstr(realm)),
+		field(2, principalName(2, sname...)),
+		field(3, encryptedData(ticketEtype)),
+	))
+	return bertest.TLV(0x60|byte(msgType), bertest.Seq(
+		field(0, bertest.Integer(5)),
+		field(1, bertest.Integer(msgType)),
+		field(2, padata(19)),
+		field(3, gstr(crealm)),
+		field(4, principalName(1, cname...)),
+		field(5, ticket),
+		field(6, encryptedData(replyEtype)),
+	))
+}
+
+func krbError(code int64, realm string, sname []string, text string) []byte {
+	parts := [][]byte{
+		field(0, bertest.Integer(5)),
+		field(1, bertest.Integer(msgKRBError)),
+		field(4, bertest.TLV(0x18, []byte("20240501100000Z"))),
+		field(5, bertest.Integer(0)),
+		field(6, bertest.Integer(code)),
+		field(9, gstr(realm)),
+		field(10, principalName(2, sname...)),
+	}
+	if text != "" {
+		parts = append(parts, field(11, gstr(text)))
+	}
+	return bertest.TLV(0x7e, bertest.Seq(parts...))
+}
+
+// forwardable, renewable, canonicalize and renewable-ok.
+var defaultOptions = []byte{0x40, 0x81, 0x00, 0x10}
+
+var asReq = request{
+	msgType: msgASReq,
+	padata:  []int64{128},
+	options: defaultOptions,
+	cname:   []string{"jdoe"},
+	realm:   "EXAMPLE.COM",
+	sname:   []string{"krbtgt", "EXAMPLE.COM"},
+	etypes:  []int64{18, 17, 23, 1000},
+}
+
+func TestDecodeRequest(t *testing.T) {
+	var m message
+	require.NoError(t, decodeMessage(asReq.encode(), &m))
+	assert.True(t, m.isRequest())
+	assert.Equal(t, msgASReq, m.msgType)
+	assert.Equal(t, "jdoe", m.clientName)
+	assert.Equal(t, "EXAMPLE.COM", m.clientRealm)
+	assert.Equal(t, "krbtgt/EXAMPLE.COM", m.serviceName)
+	assert.Equal(t, "EXAMPLE.COM", m.serviceRealm)
+	assert.Equal(t, []string{"forwardable", "renewable", "canonicalize", "renewable-ok"}, m.kdcOptions)
+	assert.Equal(t, []string{"aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96", "rc4-hmac", "unknown (1000)"}, m.encryptionTypes)
+	assert.Equal(t, []string{"PA-PAC-REQUEST"}, m.preauthentication)
+
+	// The realm of a TGS request is the realm of the service only.
+	m = message{}
+	tgsReq := request{msgType: msgTGSReq, padata: []int64{1}, realm: "EXAMPLE.COM", sname: []string{"cifs", "fs1.example.com"}, etypes: []int64{18}}
+	require.NoError(t, decodeMessage(tgsReq.encode(), &m))
+	assert.Equal(t, msgTGSReq, m.msgType)
+	assert.Empty(t, m.clientName)
+	assert.Empty(t, m.clientRealm)
+	assert.Equal(t, "cifs/fs1.example.com", m.serviceName)
+	assert.Equal(t, []string{"PA-TGS-REQ"}, m.preauthentication)
+	assert.Empty(t, m.kdcOptions)
+}
+
+func TestDecodeReply(t *testing.T) {
+	var m message
+	data := reply(msgASRep, "EXAMPLE.COM", []string{"jdoe"}, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, 18, 17)
+	require.NoError(t, decodeMessage(data, &m))
+	assert.False(t, m.isRequest())
+	assert.Equal(t, msgASRep, m.msgType)
+	assert.Equal(t, "jdoe", m.clientName)
+	assert.Equal(t, "EXAMPLE.COM", m.clientRealm)
+	assert.Equal(t, "krbtgt/EXAMPLE.COM", m.serviceName)
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", m.ticketEncryptionType)
+	assert.Equal(t, "aes128-cts-hmac-sha1-96", m.replyEncryptionType)
+}
+
+func TestDecodeError(t *testing.T) {
+	var m message
+	require.NoError(t, decodeMessage(krbError(24, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, "Preauthentication failed"), &m))
+	assert.Equal(t, msgKRBError, m.msgType)
+	assert.EqualValues(t, 24, m.errorCode)
+	assert.Equal(t, "Preauthentication failed", m.errorText)
+	assert.Equal(t, "krbtgt/EXAMPLE.COM", m.serviceName)
+	assert.Equal(t, "EXAMPLE.COM", m.serviceRealm)
+	assert.Equal(t, "KDC_ERR_PREAUTH_FAILED", errorCodeName(m.errorCode))
+	assert.Equal(t, "unknown (99)", errorCodeName(99))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	badVersion := asReq.encode()
+	pvno := bytes.Index(badVersion, []byte{0xa1, 0x03, 0x02, 0x01, 0x05})
+	require.Positive(t, pvno)
+	badVersion[pvno+4] = 4
+
+	wrongType := asReq
+	wrongType.msgType = msgTGSReq
+	mismatch := wrongType.encode()
+	mismatch[0] = 0x6a
+
+	for name, data := range map[string][]byte{
+		"dns":           {0x21, 0x51, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		"truncated":     asReq.encode()[:20],
+		"trailing data": append(asReq.encode(), 0x00),
+		"universal":     bertest.Seq(field(1, bertest.Integer(5))),
+		"ap-req":        bertest.TLV(0x6e, bertest.Seq(field(0, bertest.Integer(5)), field(1, bertest.Integer(14)))),
+		"version":       badVersion,
+		"mismatch":      mismatch,
+		"no body":       bertest.TLV(0x6a, bertest.Seq(field(1, bertest.Integer(5)), field(2, bertest.Integer(10)))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var m message
+			assert.Error(t, decodeMessage(data, &m))
+		})
+	}
+}
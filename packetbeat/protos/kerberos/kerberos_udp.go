@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+func (p *kerberosPlugin) ParseUDP(pkt *protos.Packet) {
+	m := &message{ts: pkt.Ts, size: len(pkt.Payload), tuple: pkt.Tuple}
+	if err := decodeMessage(pkt.Payload, m); err != nil {
+		p.logger.Debugf("Failed to decode Kerberos message from %s: %v", &pkt.Tuple, err)
+		return
+	}
+
+	if m.isRequest() {
+		m.cmdlineTuple = p.watcher.FindProcessesTupleUDP(&pkt.Tuple)
+		if prev, ok := p.requests.Delete(pkt.Tuple.Hashable()).(*message); ok {
+			// The client retransmitted the request, or sent a new one
+			// from the same port before the response arrived.
+			unmatchedRequests.Inc()
+			p.publish(prev, nil, "udp")
+		}
+		p.requests.Put(pkt.Tuple.Hashable(), m)
+		return
+	}
+
+	req, ok := p.requests.Delete(pkt.Tuple.RevHashable()).(*message)
+	if !ok {
+		p.logger.Debugf("Kerberos response from %s without request", &pkt.Tuple)
+		unmatchedResponses.Inc()
+		return
+	}
+	p.publish(req, m, "udp")
+}
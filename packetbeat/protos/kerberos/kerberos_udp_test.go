@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kerberos
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+var (
+	clientToKDC = common.NewIPPortTuple(4, net.IPv4(10, 0, 0, 1), 51000, net.IPv4(10, 0, 0, 2), 88)
+	kdcToClient = common.NewIPPortTuple(4, net.IPv4(10, 0, 0, 2), 88, net.IPv4(10, 0, 0, 1), 51000)
+)
+
+func udpPacket(ts time.Time, tuple common.IPPortTuple, payload []byte) *protos.Packet {
+	return &protos.Packet{Ts: ts, Tuple: tuple, Payload: payload}
+}
+
+func TestUDPASExchange(t *testing.T) {
+	store := &eventStore{}
+	p := kerberosModForTests(t, store)
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// First attempt, without pre-authentication.
+	p.ParseUDP(udpPacket(ts, clientToKDC, asReq.encode()))
+	errResp := krbError(errPreauthRequired, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, "")
+	p.ParseUDP(udpPacket(ts.Add(time.Millisecond), kdcToClient, errResp))
+
+	// Second attempt, with an encrypted timestamp.
+	preauth := asReq
+	preauth.padata = []int64{2, 128}
+	p.ParseUDP(udpPacket(ts.Add(2*time.Millisecond), clientToKDC, preauth.encode()))
+	rep := reply(msgASRep, "EXAMPLE.COM", []string{"jdoe"}, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, 18, 18)
+	p.ParseUDP(udpPacket(ts.Add(5*time.Millisecond), kdcToClient, rep))
+
+	require.Len(t, store.events, 2)
+
+	assert.Equal(t, "kerberos", store.get(t, 0, "type"))
+	assert.Equal(t, "AS", store.get(t, 0, "method"))
+	assert.Equal(t, "kerberos.as", store.get(t, 0, "event.action"))
+	assert.Equal(t, "krbtgt/EXAMPLE.COM@EXAMPLE.COM", store.get(t, 0, "resource"))
+	assert.Equal(t, "AS krbtgt/EXAMPLE.COM@EXAMPLE.COM", store.get(t, 0, "query"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.EqualValues(t, errPreauthRequired, store.get(t, 0, "kerberos.error.code"))
+	assert.Equal(t, "KDC_ERR_PREAUTH_REQUIRED", store.get(t, 0, "kerberos.error.name"))
+	store.missing(t, 0, "kerberos.error.text")
+	assert.Equal(t, "jdoe", store.get(t, 0, "kerberos.client.name"))
+	assert.Equal(t, "EXAMPLE.COM", store.get(t, 0, "kerberos.client.realm"))
+	assert.Equal(t, "jdoe", store.get(t, 0, "user.name"))
+	assert.Equal(t, []string{"PA-PAC-REQUEST"}, store.get(t, 0, "kerberos.preauthentication"))
+	assert.Equal(t, "udp", store.get(t, 0, "network.transport"))
+	assert.Equal(t, "kerberos", store.get(t, 0, "network.protocol"))
+	assert.Equal(t, "10.0.0.1", store.get(t, 0, "source.ip"))
+	assert.EqualValues(t, 88, store.get(t, 0, "destination.port"))
+	assert.EqualValues(t, len(asReq.encode()), store.get(t, 0, "source.bytes"))
+	assert.EqualValues(t, len(errResp), store.get(t, 0, "destination.bytes"))
+
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+	assert.Equal(t, []string{"PA-ENC-TIMESTAMP", "PA-PAC-REQUEST"}, store.get(t, 1, "kerberos.preauthentication"))
+	assert.Equal(t, []string{"forwardable", "renewable", "canonicalize", "renewable-ok"}, store.get(t, 1, "kerberos.kdc_options"))
+	assert.Equal(t, []string{"aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96", "rc4-hmac", "unknown (1000)"}, store.get(t, 1, "kerberos.encryption_types"))
+	assert.Equal(t, "krbtgt/EXAMPLE.COM", store.get(t, 1, "kerberos.service.name"))
+	assert.Equal(t, "EXAMPLE.COM", store.get(t, 1, "kerberos.service.realm"))
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", store.get(t, 1, "kerberos.ticket.encryption_type"))
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", store.get(t, 1, "kerberos.reply.encryption_type"))
+	assert.EqualValues(t, 3*time.Millisecond, store.get(t, 1, "event.duration"))
+	store.missing(t, 1, "kerberos.error")
+}
+
+func TestUDPTGSExchange(t *testing.T) {
+	store := &eventStore{}
+	p := kerberosModForTests(t, store)
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tgsReq := request{msgType: msgTGSReq, padata: []int64{1}, options: defaultOptions, realm: "EXAMPLE.COM", sname: []string{"MSSQLSvc", "db1.example.com:1433"}, etypes: []int64{23}}
+	p.ParseUDP(udpPacket(ts, clientToKDC, tgsReq.encode()))
+	p.ParseUDP(udpPacket(ts, kdcToClient, reply(msgTGSRep, "EXAMPLE.COM", []string{"jdoe"}, "EXAMPLE.COM", []string{"MSSQLSvc", "db1.example.com:1433"}, 23, 18)))
+
+	tgsReq.sname = []string{"cifs", "unknown.example.com"}
+	p.ParseUDP(udpPacket(ts, clientToKDC, tgsReq.encode()))
+	p.ParseUDP(udpPacket(ts, kdcToClient, krbError(7, "EXAMPLE.COM", tgsReq.sname, "")))
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "TGS", store.get(t, 0, "method"))
+	assert.Equal(t, "kerberos.tgs", store.get(t, 0, "event.action"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	// The client of a TGS request is only known from the reply.
+	assert.Equal(t, "jdoe", store.get(t, 0, "kerberos.client.name"))
+	assert.Equal(t, "jdoe", store.get(t, 0, "user.name"))
+	assert.Equal(t, "MSSQLSvc/db1.example.com:1433", store.get(t, 0, "kerberos.service.name"))
+	assert.Equal(t, "rc4-hmac", store.get(t, 0, "kerberos.ticket.encryption_type"))
+	assert.Equal(t, []string{"PA-TGS-REQ"}, store.get(t, 0, "kerberos.preauthentication"))
+
+	assert.Equal(t, "Error", store.get(t, 1, "status"))
+	assert.Equal(t, "failure", store.get(t, 1, "event.outcome"))
+	assert.EqualValues(t, 7, store.get(t, 1, "kerberos.error.code"))
+	assert.Equal(t, "KDC_ERR_S_PRINCIPAL_UNKNOWN", store.get(t, 1, "kerberos.error.name"))
+	assert.Equal(t, "cifs/unknown.example.com@EXAMPLE.COM", store.get(t, 1, "resource"))
+	store.missing(t, 1, "kerberos.client")
+	store.missing(t, 1, "user.name")
+}
+
+func TestUDPUnmatched(t *testing.T) {
+	store := &eventStore{}
+	p := kerberosModForTests(t, store)
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// Response without request.
+	p.ParseUDP(udpPacket(ts, kdcToClient, krbError(6, "EXAMPLE.COM", []string{"krbtgt", "EXAMPLE.COM"}, "")))
+	assert.Empty(t, store.events)
+
+	// Retransmitted request.
+	p.ParseUDP(udpPacket(ts, clientToKDC, asReq.encode()))
+	p.ParseUDP(udpPacket(ts.Add(time.Second), clientToKDC, asReq.encode()))
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "Unmatched request", store.get(t, 0, "error.message"))
+
+	p.Flush()
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "Unmatched request", store.get(t, 1, "error.message"))
+	store.missing(t, 1, "kerberos.error")
+
+	// Not Kerberos.
+	p.ParseUDP(udpPacket(ts, clientToKDC, []byte("\x00\x01\x02\x03")))
+	p.Flush()
+	assert.Len(t, store.events, 2)
+}
@@ -0,0 +1,369 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+)
+
+const protocolVersion = 5
+
+var errNotKerberos = errors.New("not a Kerberos KDC message")
+
+// message is a decoded KDC request, reply or error.
+type message struct {
+	ts      time.Time
+	size    int
+	msgType int
+
+	tuple        common.IPPortTuple
+	cmdlineTuple *common.ProcessTuple
+
+	// Principal names are reported without their realm. The client name
+	// is only sent in AS requests, TGS requests carry it in the encrypted
+	// authenticator.
+	clientName   string
+	clientRealm  string
+	serviceName  string
+	serviceRealm string
+
+	// Request fields.
+	kdcOptions        []string
+	encryptionTypes   []string
+	preauthentication []string
+
+	// Reply fields.
+	ticketEncryptionType string
+	replyEncryptionType  string
+
+	// Error fields.
+	errorCode int64
+	errorText string
+}
+
+func (m *message) isRequest() bool {
+	return m.msgType == msgASReq || m.msgType == msgTGSReq
+}
+
+// decodeMessage decodes a KDC message. data must hold exactly one message.
+func decodeMessage(data []byte, m *message) error {
+	e, rest, err := ber.Decode(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("%d bytes of trailing data", len(rest))
+	}
+	if e.Class != ber.ClassApplication || !e.Constructed {
+		return errNotKerberos
+	}
+	body, err := e.Explicit()
+	if err != nil {
+		return err
+	}
+	fields, err := explicitFields(body)
+	if err != nil {
+		return err
+	}
+
+	m.msgType = e.Tag
+	switch e.Tag {
+	case msgASReq, msgTGSReq:
+		return decodeRequest(fields, m)
+	case msgASRep, msgTGSRep:
+		return decodeReply(fields, m)
+	case msgKRBError:
+		return decodeError(fields, m)
+	}
+	return errNotKerberos
+}
+
+// explicitFields returns the fields of a SEQUENCE whose components are all
+// explicitly tagged, by tag number.
+func explicitFields(e ber.Element) (map[int]ber.Element, error) {
+	if !e.IsUniversal(ber.TagSequence) {
+		return nil, fmt.Errorf("expected a SEQUENCE, got [%d] %d", e.Class, e.Tag)
+	}
+	children, err := e.Children()
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[int]ber.Element, len(children))
+	for _, child := range children {
+		if child.Class != ber.ClassContext {
+			return nil, fmt.Errorf("unexpected field [%d] %d", child.Class, child.Tag)
+		}
+		if fields[child.Tag], err = child.Explicit(); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// checkHeader validates the protocol version and message type fields.
+func checkHeader(fields map[int]ber.Element, pvnoTag int, m *message) error {
+	pvno, err := intField(fields, pvnoTag)
+	if err != nil {
+		return err
+	}
+	if pvno != protocolVersion {
+		return fmt.Errorf("unsupported protocol version %d", pvno)
+	}
+	msgType, err := intField(fields, pvnoTag+1)
+	if err != nil {
+		return err
+	}
+	if msgType != int64(m.msgType) {
+		return fmt.Errorf("message type %d doesn't match application tag %d", msgType, m.msgType)
+	}
+	return nil
+}
+
+func intField(fields map[int]ber.Element, tag int) (int64, error) {
+	e, found := fields[tag]
+	if !found {
+		return 0, fmt.Errorf("missing field %d", tag)
+	}
+	if !e.IsUniversal(ber.TagInteger) {
+		return 0, fmt.Errorf("field %d is not an INTEGER", tag)
+	}
+	return e.Int()
+}
+
+func decodeRequest(fields map[int]ber.Element, m *message) error {
+	if err := checkHeader(fields, 1, m); err != nil {
+		return err
+	}
+	if padata, found := fields[3]; found {
+		types, err := decodePreauthentication(padata)
+		if err != nil {
+			return err
+		}
+		m.preauthentication = types
+	}
+
+	reqBody, found := fields[4]
+	if !found {
+		return errors.New("missing request body")
+	}
+	body, err := explicitFields(reqBody)
+	if err != nil {
+		return err
+	}
+	if options, found := body[0]; found {
+		bits, err := options.BitString()
+		if err != nil {
+			return err
+		}
+		m.kdcOptions = kdcOptions(bits)
+	}
+	if cname, found := body[1]; found {
+		if m.clientName, err = decodePrincipalName(cname); err != nil {
+			return err
+		}
+	}
+	// The realm of the request body is the realm of the service, and of
+	// the client in AS requests.
+	if realm, found := body[2]; found {
+		m.serviceRealm = realm.String()
+		if m.msgType == msgASReq {
+			m.clientRealm = m.serviceRealm
+		}
+	}
+	if sname, found := body[3]; found {
+		if m.serviceName, err = decodePrincipalName(sname); err != nil {
+			return err
+		}
+	}
+	if etypes, found := body[8]; found {
+		children, err := etypes.Children()
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			etype, err := child.Int()
+			if err != nil {
+				return err
+			}
+			m.encryptionTypes = append(m.encryptionTypes, encryptionTypeName(etype))
+		}
+	}
+	return nil
+}
+
+func decodeReply(fields map[int]ber.Element, m *message) error {
+	if err := checkHeader(fields, 0, m); err != nil {
+		return err
+	}
+	var err error
+	if crealm, found := fields[3]; found {
+		m.clientRealm = crealm.String()
+	}
+	if cname, found := fields[4]; found {
+		if m.clientName, err = decodePrincipalName(cname); err != nil {
+			return err
+		}
+	}
+	if ticket, found := fields[5]; found {
+		if err := decodeTicket(ticket, m); err != nil {
+			return err
+		}
+	}
+	if encPart, found := fields[6]; found {
+		if m.replyEncryptionType, err = encryptionType(encPart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTicket decodes the unencrypted part of a ticket.
+func decodeTicket(e ber.Element, m *message) error {
+	if !e.Is(ber.ClassApplication, 1) {
+		return errors.New("invalid ticket")
+	}
+	inner, err := e.Explicit()
+	if err != nil {
+		return err
+	}
+	fields, err := explicitFields(inner)
+	if err != nil {
+		return err
+	}
+	if realm, found := fields[1]; found {
+		m.serviceRealm = realm.String()
+	}
+	if sname, found := fields[2]; found {
+		if m.serviceName, err = decodePrincipalName(sname); err != nil {
+			return err
+		}
+	}
+	if encPart, found := fields[3]; found {
+		if m.ticketEncryptionType, err = encryptionType(encPart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeError(fields map[int]ber.Element, m *message) error {
+	if err := checkHeader(fields, 0, m); err != nil {
+		return err
+	}
+	var err error
+	if m.errorCode, err = intField(fields, 6); err != nil {
+		return err
+	}
+	if crealm, found := fields[7]; found {
+		m.clientRealm = crealm.String()
+	}
+	if cname, found := fields[8]; found {
+		if m.clientName, err = decodePrincipalName(cname); err != nil {
+			return err
+		}
+	}
+	if realm, found := fields[9]; found {
+		m.serviceRealm = realm.String()
+	}
+	if sname, found := fields[10]; found {
+		if m.serviceName, err = decodePrincipalName(sname); err != nil {
+			return err
+		}
+	}
+	if text, found := fields[11]; found {
+		m.errorText = text.String()
+	}
+	return nil
+}
+
+// decodePrincipalName returns the components of a PrincipalName separated
+// by slashes, like krbtgt/EXAMPLE.COM.
+func decodePrincipalName(e ber.Element) (string, error) {
+	fields, err := explicitFields(e)
+	if err != nil {
+		return "", err
+	}
+	nameString, found := fields[1]
+	if !found {
+		return "", errors.New("missing principal name")
+	}
+	components, err := nameString.Children()
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(components))
+	for i, c := range components {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// encryptionType returns the name of the encryption type of an
+// EncryptedData.
+func encryptionType(e ber.Element) (string, error) {
+	fields, err := explicitFields(e)
+	if err != nil {
+		return "", err
+	}
+	etype, err := intField(fields, 0)
+	if err != nil {
+		return "", err
+	}
+	return encryptionTypeName(etype), nil
+}
+
+// decodePreauthentication returns the types of the PA-DATA of a request.
+func decodePreauthentication(e ber.Element) ([]string, error) {
+	children, err := e.Children()
+	if err != nil {
+		return nil, err
+	}
+	types := make([]string, 0, len(children))
+	for _, child := range children {
+		fields, err := explicitFields(child)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := intField(fields, 1)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, preauthenticationName(typ))
+	}
+	return types, nil
+}
+
+// kdcOptions returns the names of the KDC options set.
+func kdcOptions(bits []byte) []string {
+	var names []string
+	for i := 0; i < len(bits)*8; i++ {
+		if bits[i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		if name, found := kdcOptionNames[i]; found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
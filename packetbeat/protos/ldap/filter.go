@@ -0,0 +1,183 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+)
+
+// Filter choices (RFC 4511, section 4.5.1.7).
+const (
+	filterAnd             = 0
+	filterOr              = 1
+	filterNot             = 2
+	filterEqualityMatch   = 3
+	filterSubstrings      = 4
+	filterGreaterOrEqual  = 5
+	filterLessOrEqual     = 6
+	filterPresent         = 7
+	filterApproxMatch     = 8
+	filterExtensibleMatch = 9
+)
+
+const maxFilterDepth = 32
+
+var errFilterTooDeep = errors.New("search filter too deeply nested")
+
+// formatFilter returns the string representation of a search filter
+// (RFC 4515).
+func formatFilter(e ber.Element) (string, error) {
+	var b strings.Builder
+	if err := writeFilter(&b, e, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeFilter(b *strings.Builder, e ber.Element, depth int) error {
+	if depth > maxFilterDepth {
+		return errFilterTooDeep
+	}
+	if e.Class != ber.ClassContext {
+		return fmt.Errorf("invalid search filter element [%d] %d", e.Class, e.Tag)
+	}
+
+	b.WriteByte('(')
+	switch e.Tag {
+	case filterAnd, filterOr:
+		b.WriteByte("&|"[e.Tag])
+		children, err := e.Children()
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := writeFilter(b, child, depth+1); err != nil {
+				return err
+			}
+		}
+	case filterNot:
+		b.WriteByte('!')
+		inner, err := e.Explicit()
+		if err != nil {
+			return err
+		}
+		if err := writeFilter(b, inner, depth+1); err != nil {
+			return err
+		}
+	case filterEqualityMatch, filterGreaterOrEqual, filterLessOrEqual, filterApproxMatch:
+		children, err := e.Children()
+		if err != nil {
+			return err
+		}
+		if len(children) != 2 {
+			return errors.New("invalid attribute value assertion")
+		}
+		b.WriteString(children[0].String())
+		b.WriteString(map[int]string{
+			filterEqualityMatch:  "=",
+			filterGreaterOrEqual: ">=",
+			filterLessOrEqual:    "<=",
+			filterApproxMatch:    "~=",
+		}[e.Tag])
+		writeFilterValue(b, children[1].Content)
+	case filterSubstrings:
+		children, err := e.Children()
+		if err != nil {
+			return err
+		}
+		if len(children) != 2 {
+			return errors.New("invalid substring filter")
+		}
+		b.WriteString(children[0].String())
+		b.WriteByte('=')
+		substrings, err := children[1].Children()
+		if err != nil {
+			return err
+		}
+		// initial [0], any [1] and final [2] substrings.
+		if len(substrings) == 0 || substrings[0].Tag != 0 {
+			b.WriteByte('*')
+		}
+		for _, s := range substrings {
+			writeFilterValue(b, s.Content)
+			if s.Tag != 2 {
+				b.WriteByte('*')
+			}
+		}
+	case filterPresent:
+		b.WriteString(e.String())
+		b.WriteString("=*")
+	case filterExtensibleMatch:
+		children, err := e.Children()
+		if err != nil {
+			return err
+		}
+		var rule, typ, value string
+		var dnAttributes bool
+		for _, child := range children {
+			switch child.Tag {
+			case 1:
+				rule = child.String()
+			case 2:
+				typ = child.String()
+			case 3:
+				var v strings.Builder
+				writeFilterValue(&v, child.Content)
+				value = v.String()
+			case 4:
+				dnAttributes, _ = child.Bool()
+			}
+		}
+		b.WriteString(typ)
+		if dnAttributes {
+			b.WriteString(":dn")
+		}
+		if rule != "" {
+			b.WriteByte(':')
+			b.WriteString(rule)
+		}
+		b.WriteString(":=")
+		b.WriteString(value)
+	default:
+		return fmt.Errorf("unknown search filter choice %d", e.Tag)
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+// writeFilterValue writes an assertion value, escaping the characters
+// that are special in filters. Values that aren't valid UTF-8, common in
+// binary attributes like objectSid and objectGUID, are escaped in full.
+func writeFilterValue(b *strings.Builder, value []byte) {
+	const hex = "0123456789abcdef"
+	binary := !utf8.Valid(value)
+	for _, c := range value {
+		if binary || c == '*' || c == '(' || c == ')' || c == '\\' || c < 0x20 || c == 0x7f {
+			b.WriteByte('\\')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+			continue
+		}
+		b.WriteByte(c)
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ldap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber/bertest"
+)
+
+func TestFormatFilter(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		filter []byte
+		want   string
+	}{
+		{"present", ctx(7, "objectClass"), "(objectClass=*)"},
+		{"equality", equality("cn", "Babs Jensen"), "(cn=Babs Jensen)"},
+		{"not", bertest.TLV(0xa2, equality("cn", "Tim Howes")), "(!(cn=Tim Howes))"},
+		{
+			"and or",
+			bertest.TLV(0xa0, equality("objectClass", "Person"), bertest.TLV(0xa1, equality("sn", "Jensen"), bertest.TLV(0xa4, bertest.Str("cn"), bertest.Seq(ctx(0, "Babs J"))))),
+			"(&(objectClass=Person)(|(sn=Jensen)(cn=Babs J*)))",
+		},
+		{"substrings", bertest.TLV(0xa4, bertest.Str("o"), bertest.Seq(ctx(0, "univ"), ctx(1, "of"), ctx(2, "mich"))), "(o=univ*of*mich)"},
+		{"final substring", bertest.TLV(0xa4, bertest.Str("cn"), bertest.Seq(ctx(2, "son"))), "(cn=*son)"},
+		{"any substring", bertest.TLV(0xa4, bertest.Str("cn"), bertest.Seq(ctx(1, "a"))), "(cn=*a*)"},
+		{"ordering", bertest.TLV(0xa0, bertest.TLV(0xa5, bertest.Str("uSNChanged"), bertest.Str("100")), bertest.TLV(0xa6, bertest.Str("badPwdCount"), bertest.Str("3"))), "(&(uSNChanged>=100)(badPwdCount<=3))"},
+		{"approx", bertest.TLV(0xa8, bertest.Str("sn"), bertest.Str("Jensen")), "(sn~=Jensen)"},
+		{
+			"extensible",
+			bertest.TLV(0xa9, ctx(1, "1.2.840.113556.1.4.803"), ctx(2, "userAccountControl"), ctx(3, "2")),
+			"(userAccountControl:1.2.840.113556.1.4.803:=2)",
+		},
+		{"extensible dn", bertest.TLV(0xa9, ctx(2, "o"), ctx(3, "Ace Industry"), bertest.TLV(0x84, []byte{0xff})), "(o:dn:=Ace Industry)"},
+		{"escaped", equality("cn", "Parens R Us (for all your parenthetical needs)"), `(cn=Parens R Us \28for all your parenthetical needs\29)`},
+		{"asterisk", equality("cn", "*"), `(cn=\2a)`},
+		{"binary", equality("objectGUID", "\x01\xff\x00"), `(objectGUID=\01\ff\00)`},
+		{"utf-8", equality("sn", "Lučić"), "(sn=Lučić)"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			e, rest, err := ber.Decode(test.filter)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+			got, err := formatFilter(e)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFormatFilterErrors(t *testing.T) {
+	deep := ctx(7, "cn")
+	for i := 0; i < maxFilterDepth+1; i++ {
+		deep = bertest.TLV(0xa2, deep)
+	}
+	for _, test := range []struct {
+		name   string
+		filter []byte
+		err    string
+	}{
+		{"too deep", deep, errFilterTooDeep.Error()},
+		{"universal", bertest.Str("cn"), "invalid search filter element"},
+		{"unknown choice", ctx(10, "cn"), "unknown search filter choice 10"},
+		{"bad assertion", bertest.TLV(0xa3, bertest.Str("cn")), "invalid attribute value assertion"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			e, _, err := ber.Decode(test.filter)
+			require.NoError(t, err)
+			_, err = formatFilter(e)
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), test.err), err.Error())
+		})
+	}
+}
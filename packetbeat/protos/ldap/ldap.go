@@ -0,0 +1,541 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package ldap implements a Packetbeat analyzer for the Lightweight
+// Directory Access Protocol (RFC 4511). Requests and responses are
+// correlated by message ID, so operations sent asynchronously on the same
+// connection are reported as separate events. Connections protected with
+// TLS, after a StartTLS operation, or by a SASL security layer can't be
+// inspected.
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// maxMessageSize is the size of the largest message decoded in full.
+	// Only the message ID and operation of larger messages are decoded,
+	// they are usually search result entries with large attribute values.
+	maxMessageSize = 1 << 20
+	// prefixSize is the number of bytes buffered to decode the beginning
+	// of a large message.
+	prefixSize = 64
+
+	maxPendingRequests = 1000
+)
+
+var (
+	unmatchedRequests  = monitoring.NewInt(nil, "ldap.unmatched_requests")
+	unmatchedResponses = monitoring.NewInt(nil, "ldap.unmatched_responses")
+)
+
+type ldapPlugin struct {
+	ports              []int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+	logger  *logp.Logger
+	isDebug bool
+}
+
+type stream struct {
+	applayer.Stream
+	isClient bool
+	// ts is the time the message being buffered started.
+	ts time.Time
+	// skip is the number of bytes left of a partially decoded message.
+	skip int
+}
+
+type connection struct {
+	streams [2]*stream
+	pending map[int64]*transaction
+	// order holds the message IDs of the pending requests in the order
+	// they were sent, the oldest requests are expired first.
+	order []int64
+	// encrypted is set once TLS has been started on the connection.
+	encrypted bool
+}
+
+// transaction is a request and the responses received for it.
+type transaction struct {
+	request  *message
+	response *message
+	// entries and references count the search results returned.
+	entries    int
+	references int
+	// responseBytes is the size of all the responses.
+	responseBytes int
+}
+
+func init() {
+	protos.Register("ldap", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &ldapPlugin{}
+	p.logger = logger.Named("ldap")
+	p.isDebug = p.logger.IsDebug()
+
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	p.init(results, watcher, &config)
+	return p, nil
+}
+
+func (p *ldapPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *ldapConfig) {
+	p.ports = config.Ports
+	p.transactionTimeout = config.TransactionTimeout
+	p.results = results
+	p.watcher = watcher
+}
+
+//go:inline
+func (p *ldapPlugin) debugf(format string, args ...interface{}) {
+	if p.isDebug {
+		p.logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *ldapPlugin) GetPorts() []int {
+	return p.ports
+}
+
+func (p *ldapPlugin) ConnectionTimeout() time.Duration {
+	return p.transactionTimeout
+}
+
+func (p *ldapPlugin) isServerPort(port uint16) bool {
+	for _, sPort := range p.ports {
+		if uint16(sPort) == port {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ldapPlugin) ensureConnection(private protos.ProtocolData) *connection {
+	if conn, ok := private.(*connection); ok && conn != nil {
+		return conn
+	}
+	return &connection{pending: map[int64]*transaction{}}
+}
+
+func (p *ldapPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := p.ensureConnection(private)
+	if conn.encrypted {
+		return conn
+	}
+
+	st := conn.streams[dir]
+	if st == nil {
+		dstPort := tcptuple.DstPort
+		if dir == tcp.TCPDirectionReverse {
+			dstPort = tcptuple.SrcPort
+		}
+		st = &stream{isClient: p.isServerPort(dstPort)}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if st.Buf.Len() == 0 {
+		st.ts = pkt.Ts
+	}
+	if err := st.Append(payload); err != nil {
+		p.debugf("%v, dropping TCP stream", err)
+		conn.streams[dir] = nil
+		return conn
+	}
+
+	for st.Buf.Len() > 0 && !conn.encrypted {
+		buf := st.Buf.Bytes()
+		h, err := ber.ReadHeader(buf)
+		if errors.Is(err, ber.ErrTruncated) {
+			break
+		}
+		if err != nil || h.Class != ber.ClassUniversal || h.Tag != ber.TagSequence || !h.Constructed {
+			p.debugf("not an LDAP message, dropping TCP stream")
+			conn.streams[dir] = nil
+			return conn
+		}
+		total := h.Size()
+		truncated := false
+		if len(buf) < total {
+			if total <= maxMessageSize || len(buf) < prefixSize {
+				// wait for more data
+				break
+			}
+			truncated = true
+			st.skip = total - len(buf)
+		} else {
+			buf = buf[:total]
+		}
+		if !p.handleMessage(conn, st, buf, total, truncated, tcptuple, dir) {
+			p.debugf("invalid LDAP message, dropping TCP stream")
+			conn.streams[dir] = nil
+			return conn
+		}
+		_ = st.Buf.Advance(len(buf))
+		st.Buf.Reset()
+		st.ts = pkt.Ts
+	}
+	return conn
+}
+
+// handleMessage decodes a message and correlates it. buf holds the whole
+// message, or its beginning if truncated. It returns false if the message
+// isn't a valid LDAP message.
+func (p *ldapPlugin) handleMessage(
+	conn *connection,
+	st *stream,
+	buf []byte,
+	size int,
+	truncated bool,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) bool {
+	m := &message{ts: st.ts, size: size, truncated: truncated}
+	var err error
+	if truncated {
+		err = decodeMessageHeader(buf, m)
+	} else {
+		err = decodeMessage(buf, m)
+	}
+	if err != nil {
+		p.debugf("failed to decode message: %v", err)
+		return false
+	}
+
+	if !st.isClient {
+		p.handleResponse(conn, m)
+		return true
+	}
+	if !isRequestOp(m.op) {
+		return false
+	}
+
+	m.tcpTuple = *tcptuple
+	m.direction = dir
+	m.cmdlineTuple = p.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	p.debugf("request %s message_id=%d size=%d", operationNames[m.op], m.id, size)
+
+	switch m.op {
+	case opUnbindRequest:
+		// Unbind requests aren't answered.
+		p.publish(&transaction{request: m})
+		return true
+	case opAbandonRequest:
+		// Neither abandon requests, nor the operations they abandon.
+		p.publish(&transaction{request: m})
+		if trans, ok := conn.pending[m.abandonID]; ok {
+			p.remove(conn, m.abandonID)
+			trans.request.abandoned = true
+			p.publish(trans)
+		}
+		return true
+	}
+
+	if _, exists := conn.pending[m.id]; exists {
+		p.expire(conn, m.id)
+	}
+	if len(conn.order) >= maxPendingRequests {
+		p.expire(conn, conn.order[0])
+	}
+	conn.pending[m.id] = &transaction{request: m}
+	conn.order = append(conn.order, m.id)
+	return true
+}
+
+func (p *ldapPlugin) handleResponse(conn *connection, m *message) {
+	trans, ok := conn.pending[m.id]
+	if !ok {
+		if m.id == 0 && m.op == opExtendedResponse {
+			// Unsolicited notification, like a Notice of Disconnection.
+			p.debugf("unsolicited notification: %s", resultCodeName(m.resultCode))
+			return
+		}
+		p.debugf("response with unknown message_id=%d", m.id)
+		unmatchedResponses.Inc()
+		return
+	}
+	trans.responseBytes += m.size
+
+	switch m.op {
+	case opSearchResultEntry:
+		trans.entries++
+		return
+	case opSearchResultReference:
+		trans.references++
+		return
+	case opIntermediateResponse:
+		return
+	}
+	if m.op != responseOps[trans.request.op] {
+		p.debugf("unexpected response %d to %s message_id=%d", m.op, operationNames[trans.request.op], m.id)
+	}
+
+	p.remove(conn, m.id)
+	trans.response = m
+	p.publish(trans)
+
+	if trans.request.op == opExtendedRequest && trans.request.requestName == oidStartTLS &&
+		m.hasResult && m.resultCode == resultSuccess {
+		conn.encrypted = true
+	}
+}
+
+// remove removes a pending request.
+func (p *ldapPlugin) remove(conn *connection, id int64) {
+	for i, pending := range conn.order {
+		if pending == id {
+			conn.order = append(conn.order[:i], conn.order[i+1:]...)
+			break
+		}
+	}
+	delete(conn.pending, id)
+}
+
+// expire publishes the request with the given message ID as unmatched.
+func (p *ldapPlugin) expire(conn *connection, id int64) {
+	if trans, ok := conn.pending[id]; ok {
+		p.remove(conn, id)
+		unmatchedRequests.Inc()
+		p.publish(trans)
+	}
+}
+
+func (p *ldapPlugin) publish(trans *transaction) {
+	if p.results != nil {
+		p.results(p.newTransaction(trans))
+	}
+}
+
+func (p *ldapPlugin) newTransaction(trans *transaction) beat.Event {
+	req, resp := trans.request, trans.response
+	source, destination := common.MakeEndpointPair(req.tcpTuple.BaseTuple, req.cmdlineTuple)
+	src, dst := &source, &destination
+	if req.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(req.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.Source.Bytes = int64(req.size)
+	pbf.Event.Dataset = "ldap"
+	pbf.Event.Start = req.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	method := operationNames[req.op]
+	pbf.Event.Action = "ldap." + method
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["method"] = method
+	query := method
+	if req.dn != "" {
+		fields["resource"] = req.dn
+		query += " " + req.dn
+	}
+	if req.filter != "" {
+		query += " " + req.filter
+	}
+	fields["query"] = query
+
+	ldap := mapstr.M{"message_id": req.id}
+	if req.dn != "" {
+		ldap["dn"] = req.dn
+	}
+	if len(req.controls) != 0 {
+		ldap["controls"] = req.controls
+	}
+
+	switch req.op {
+	case opBindRequest:
+		bind := mapstr.M{
+			"version":        req.version,
+			"authentication": req.auth,
+		}
+		if req.saslMechanism != "" {
+			bind["sasl_mechanism"] = req.saslMechanism
+		}
+		ldap["bind"] = bind
+		if req.dn != "" {
+			fields["user.name"] = req.dn
+			pbf.AddUser(req.dn)
+		}
+	case opSearchRequest:
+		if !req.truncated {
+			search := mapstr.M{
+				"scope":         enumName(searchScopeNames, req.scope),
+				"deref_aliases": enumName(derefAliasesNames, req.derefAliases),
+				"size_limit":    req.sizeLimit,
+				"time_limit":    req.timeLimit,
+				"types_only":    req.typesOnly,
+				"filter":        req.filter,
+			}
+			if len(req.attributes) != 0 {
+				search["attributes"] = req.attributes
+			}
+			ldap["search"] = search
+		}
+		if resp != nil {
+			ldap.Put("search.entries", trans.entries)
+			ldap.Put("search.references", trans.references)
+		}
+	case opModifyRequest:
+		if len(req.changes) != 0 {
+			ldap["modify"] = mapstr.M{"changes": req.changes}
+		}
+	case opAddRequest:
+		if len(req.attributes) != 0 {
+			ldap["add"] = mapstr.M{"attributes": req.attributes}
+		}
+	case opModifyDNRequest:
+		modifyDN := mapstr.M{
+			"new_rdn":        req.newRDN,
+			"delete_old_rdn": req.deleteOldRDN,
+		}
+		if req.newSuperior != "" {
+			modifyDN["new_superior"] = req.newSuperior
+		}
+		ldap["modify_dn"] = modifyDN
+	case opCompareRequest:
+		ldap["compare"] = mapstr.M{"attribute": req.compareAttribute}
+	case opAbandonRequest:
+		ldap["abandon"] = mapstr.M{"message_id": req.abandonID}
+	case opExtendedRequest:
+		if req.requestName != "" {
+			extended := mapstr.M{"oid": req.requestName}
+			if name, found := extendedOperationNames[req.requestName]; found {
+				extended["name"] = name
+			}
+			ldap["extended"] = extended
+		}
+	}
+
+	status := common.OK_STATUS
+	switch {
+	case resp != nil:
+		pbf.Event.End = resp.ts
+		pbf.Destination.Bytes = int64(trans.responseBytes)
+		if resp.hasResult {
+			ldap["result_code"] = resp.resultCode
+			ldap["result"] = resultCodeName(resp.resultCode)
+			if resp.matchedDN != "" {
+				ldap["matched_dn"] = resp.matchedDN
+			}
+			if resp.diagnostic != "" {
+				ldap["diagnostic_message"] = resp.diagnostic
+			}
+			if !isSuccessResult(resp.resultCode) {
+				status = common.ERROR_STATUS
+			}
+		}
+	case req.abandoned:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Abandoned request")
+	case req.op != opUnbindRequest && req.op != opAbandonRequest:
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched request")
+	}
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+
+	fields["ldap"] = ldap
+	fields["status"] = status
+	return evt
+}
+
+func (p *ldapPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return private, false
+	}
+	st := conn.streams[dir]
+	if st == nil {
+		return conn, false
+	}
+	if st.skip > 0 && nbytes <= st.skip {
+		st.skip -= nbytes
+		return conn, false
+	}
+	// Message boundaries are lost.
+	conn.streams[dir] = nil
+	return conn, false
+}
+
+func (p *ldapPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests left without response.
+func (p *ldapPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return
+	}
+	for len(conn.order) > 0 {
+		p.expire(conn, conn.order[0])
+	}
+}
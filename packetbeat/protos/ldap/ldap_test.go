@@ -0,0 +1,409 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ldap
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber/bertest"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+)
+
+const serverPort = 389
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func (e *eventStore) get(t *testing.T, idx int, key string) interface{} {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	v, err := e.events[idx].Fields.GetValue(key)
+	require.NoError(t, err, "missing %s in event %d: %v", key, idx, e.events[idx].Fields)
+	return v
+}
+
+func (e *eventStore) missing(t *testing.T, idx int, key string) {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	_, err := e.events[idx].Fields.GetValue(key)
+	assert.Error(t, err, "unexpected %s in event %d", key, idx)
+}
+
+func ldapModForTests(t *testing.T, store *eventStore) *ldapPlugin {
+	p, err := New(true, store.publish, &procs.ProcessesWatcher{}, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	return p.(*ldapPlugin)
+}
+
+// LDAP message encoding helpers.
+
+func ctx(tag byte, s string) []byte { return bertest.TLV(0x80|tag, []byte(s)) }
+
+func msg(id int64, op []byte, controls ...[]byte) []byte {
+	if len(controls) > 0 {
+		return bertest.Seq(bertest.Integer(id), op, bertest.TLV(0xa0, controls...))
+	}
+	return bertest.Seq(bertest.Integer(id), op)
+}
+
+func result(tag byte, code int64, matchedDN, diagnostic string, extra ...[]byte) []byte {
+	parts := append([][]byte{bertest.Enumerated(code), bertest.Str(matchedDN), bertest.Str(diagnostic)}, extra...)
+	return bertest.TLV(0x60|tag, parts...)
+}
+
+func simpleBind(id int64, dn, password string) []byte {
+	return msg(id, bertest.TLV(0x60, bertest.Integer(3), bertest.Str(dn), ctx(0, password)))
+}
+
+func equality(attr, value string) []byte {
+	return bertest.TLV(0xa3, bertest.Str(attr), bertest.Str(value))
+}
+
+func searchRequest(id int64, base string, scope int64, filter []byte, attributes ...string) []byte {
+	var attrs [][]byte
+	for _, attr := range attributes {
+		attrs = append(attrs, bertest.Str(attr))
+	}
+	return msg(id, bertest.TLV(0x63,
+		bertest.Str(base), bertest.Enumerated(scope), bertest.Enumerated(0), bertest.Integer(1000), bertest.Integer(30), bertest.Boolean(false),
+		filter, bertest.Seq(attrs...)))
+}
+
+func searchEntry(id int64, dn string, size int) []byte {
+	return msg(id, bertest.TLV(0x64, bertest.Str(dn), bertest.Seq(bertest.Seq(bertest.Str("description"), bertest.Set(bertest.Str(string(make([]byte, size))))))))
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: serverPort,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+func TestSimpleBind(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: simpleBind(1, "cn=admin,dc=example,dc=com", "secret")}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opBindResponse, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "ldap", store.get(t, 0, "type"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, "bind", store.get(t, 0, "method"))
+	assert.Equal(t, "ldap.bind", store.get(t, 0, "event.action"))
+	assert.Equal(t, "bind cn=admin,dc=example,dc=com", store.get(t, 0, "query"))
+	assert.Equal(t, "cn=admin,dc=example,dc=com", store.get(t, 0, "ldap.dn"))
+	assert.Equal(t, "cn=admin,dc=example,dc=com", store.get(t, 0, "user.name"))
+	assert.EqualValues(t, 1, store.get(t, 0, "ldap.message_id"))
+	assert.EqualValues(t, 3, store.get(t, 0, "ldap.bind.version"))
+	assert.Equal(t, "simple", store.get(t, 0, "ldap.bind.authentication"))
+	assert.EqualValues(t, 0, store.get(t, 0, "ldap.result_code"))
+	assert.Equal(t, "success", store.get(t, 0, "ldap.result"))
+	assert.Equal(t, "tcp", store.get(t, 0, "network.transport"))
+	assert.Equal(t, "ldap", store.get(t, 0, "network.protocol"))
+	assert.EqualValues(t, 6512, store.get(t, 0, "source.port"))
+	assert.EqualValues(t, serverPort, store.get(t, 0, "destination.port"))
+
+	// The password must not be reported.
+	assert.NotContains(t, store.events[0].Fields.StringToPrint(), "secret")
+}
+
+func TestBindFailure(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	diagnostic := "80090308: LdapErr: DSID-0C09041C, comment: AcceptSecurityContext error, data 52e, v4563"
+	private = plugin.Parse(&protos.Packet{Payload: simpleBind(1, "jdoe@example.com", "wrong")}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opBindResponse, 49, "", diagnostic))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "failure", store.get(t, 0, "event.outcome"))
+	assert.EqualValues(t, 49, store.get(t, 0, "ldap.result_code"))
+	assert.Equal(t, "invalidCredentials", store.get(t, 0, "ldap.result"))
+	assert.Equal(t, diagnostic, store.get(t, 0, "ldap.diagnostic_message"))
+}
+
+func TestBindAuthentication(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		request   []byte
+		auth      string
+		mechanism string
+	}{
+		{"anonymous", simpleBind(1, "", ""), "anonymous", ""},
+		{"unauthenticated", simpleBind(1, "cn=admin", ""), "unauthenticated", ""},
+		{
+			"sasl",
+			msg(1, bertest.TLV(0x60, bertest.Integer(3), bertest.Str(""), bertest.TLV(0xa3, bertest.Str("GSS-SPNEGO"), bertest.Str("\x60\x82token")))),
+			"sasl", "GSS-SPNEGO",
+		},
+		{
+			"sicily",
+			msg(1, bertest.TLV(0x60, bertest.Integer(3), bertest.Str(""), ctx(10, "NTLMSSP\x00\x01"))),
+			"sicily_negotiate", "",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			store := &eventStore{}
+			plugin := ldapModForTests(t, store)
+			tuple := testTCPTuple()
+			var private protos.ProtocolData
+			private = plugin.Parse(&protos.Packet{Payload: test.request}, tuple, tcp.TCPDirectionOriginal, private)
+			private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opBindResponse, 14, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+			require.Len(t, store.events, 1)
+			assert.Equal(t, "OK", store.get(t, 0, "status"))
+			assert.Equal(t, "saslBindInProgress", store.get(t, 0, "ldap.result"))
+			assert.Equal(t, test.auth, store.get(t, 0, "ldap.bind.authentication"))
+			if test.mechanism != "" {
+				assert.Equal(t, test.mechanism, store.get(t, 0, "ldap.bind.sasl_mechanism"))
+			} else {
+				store.missing(t, 0, "ldap.bind.sasl_mechanism")
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	filter := bertest.TLV(0xa0, equality("objectClass", "user"), bertest.TLV(0xa4, bertest.Str("sAMAccountName"), bertest.Seq(ctx(0, "adm"))))
+	pagedResults := bertest.Seq(bertest.Str("1.2.840.113556.1.4.319"), bertest.Boolean(false), bertest.Str("\x30\x05\x02\x01\x64\x04\x00"))
+	req := searchRequest(2, "dc=example,dc=com", 2, filter, "cn", "memberOf")
+	req = msg(2, req[5:], pagedResults) // same operation, with a control
+	// The request is split in small segments.
+	for i := 0; i < len(req); i += 7 {
+		private = plugin.Parse(&protos.Packet{Payload: req[i:min(i+7, len(req))]}, tuple, tcp.TCPDirectionOriginal, private)
+	}
+
+	// Several responses in the same segment.
+	var resp []byte
+	resp = append(resp, searchEntry(2, "cn=admin1,dc=example,dc=com", 10)...)
+	resp = append(resp, searchEntry(2, "cn=admin2,dc=example,dc=com", 10)...)
+	resp = append(resp, msg(2, bertest.TLV(0x73, bertest.Str("ldap://other.example.com/dc=other,dc=example,dc=com")))...)
+	resp = append(resp, msg(2, result(opSearchResultDone, 0, "", ""))...)
+	private = plugin.Parse(&protos.Packet{Payload: resp}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, "search", store.get(t, 0, "method"))
+	assert.Equal(t, "dc=example,dc=com", store.get(t, 0, "resource"))
+	assert.Equal(t, "search dc=example,dc=com (&(objectClass=user)(sAMAccountName=adm*))", store.get(t, 0, "query"))
+	assert.Equal(t, "sub", store.get(t, 0, "ldap.search.scope"))
+	assert.Equal(t, "never", store.get(t, 0, "ldap.search.deref_aliases"))
+	assert.EqualValues(t, 1000, store.get(t, 0, "ldap.search.size_limit"))
+	assert.EqualValues(t, 30, store.get(t, 0, "ldap.search.time_limit"))
+	assert.Equal(t, false, store.get(t, 0, "ldap.search.types_only"))
+	assert.Equal(t, "(&(objectClass=user)(sAMAccountName=adm*))", store.get(t, 0, "ldap.search.filter"))
+	assert.Equal(t, []string{"cn", "memberOf"}, store.get(t, 0, "ldap.search.attributes"))
+	assert.Equal(t, 2, store.get(t, 0, "ldap.search.entries"))
+	assert.Equal(t, 1, store.get(t, 0, "ldap.search.references"))
+	assert.Equal(t, []string{"1.2.840.113556.1.4.319"}, store.get(t, 0, "ldap.controls"))
+	assert.EqualValues(t, len(resp), store.get(t, 0, "destination.bytes"))
+	assert.EqualValues(t, len(req), store.get(t, 0, "source.bytes"))
+}
+
+func TestLargeSearchEntry(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: searchRequest(3, "dc=example,dc=com", 0, ctx(7, "objectClass"))}, tuple, tcp.TCPDirectionOriginal, private)
+	entry := searchEntry(3, "cn=photo,dc=example,dc=com", maxMessageSize+1000)
+	for i := 0; i < len(entry); i += 1400 {
+		private = plugin.Parse(&protos.Packet{Payload: entry[i:min(i+1400, len(entry))]}, tuple, tcp.TCPDirectionReverse, private)
+	}
+	private = plugin.Parse(&protos.Packet{Payload: searchEntry(3, "cn=small,dc=example,dc=com", 10)}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(3, result(opSearchResultDone, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "(objectClass=*)", store.get(t, 0, "ldap.search.filter"))
+	assert.Equal(t, "base", store.get(t, 0, "ldap.search.scope"))
+	assert.Equal(t, 2, store.get(t, 0, "ldap.search.entries"))
+}
+
+func TestUpdateOperations(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+
+	private = plugin.Parse(&protos.Packet{Payload: msg(4, bertest.TLV(0x66, bertest.Str("cn=jdoe,ou=users,dc=example,dc=com"), bertest.Seq(
+		bertest.Seq(bertest.Enumerated(2), bertest.Seq(bertest.Str("description"), bertest.Set(bertest.Str("new")))),
+		bertest.Seq(bertest.Enumerated(0), bertest.Seq(bertest.Str("member"), bertest.Set(bertest.Str("cn=x")))),
+		bertest.Seq(bertest.Enumerated(1), bertest.Seq(bertest.Str("mail"), bertest.Set())),
+	)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(4, result(opModifyResponse, 50, "", "insufficient rights"))}, tuple, tcp.TCPDirectionReverse, private)
+
+	private = plugin.Parse(&protos.Packet{Payload: msg(5, bertest.TLV(0x68, bertest.Str("cn=new,ou=users,dc=example,dc=com"), bertest.Seq(
+		bertest.Seq(bertest.Str("objectClass"), bertest.Set(bertest.Str("top"), bertest.Str("person"))),
+		bertest.Seq(bertest.Str("cn"), bertest.Set(bertest.Str("new"))),
+	)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(5, result(opAddResponse, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	private = plugin.Parse(&protos.Packet{Payload: msg(6, bertest.TLV(0x4a, []byte("cn=old,ou=users,dc=example,dc=com")))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(6, result(opDelResponse, 32, "ou=users,dc=example,dc=com", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	private = plugin.Parse(&protos.Packet{Payload: msg(7, bertest.TLV(0x6c, bertest.Str("cn=new,ou=users,dc=example,dc=com"), bertest.Str("cn=renamed"), bertest.Boolean(true), ctx(0, "ou=admins,dc=example,dc=com")))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(7, result(opModifyDNResponse, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	private = plugin.Parse(&protos.Packet{Payload: msg(8, bertest.TLV(0x6e, bertest.Str("cn=renamed,ou=admins,dc=example,dc=com"), bertest.Seq(bertest.Str("memberOf"), bertest.Str("cn=Domain Admins"))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(8, result(opCompareResponse, 6, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 5)
+
+	assert.Equal(t, "modify", store.get(t, 0, "method"))
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "insufficientAccessRights", store.get(t, 0, "ldap.result"))
+	assert.Equal(t, []string{"replace: description", "add: member", "delete: mail"}, store.get(t, 0, "ldap.modify.changes"))
+
+	assert.Equal(t, "add", store.get(t, 1, "method"))
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+	assert.Equal(t, []string{"objectClass", "cn"}, store.get(t, 1, "ldap.add.attributes"))
+
+	assert.Equal(t, "delete", store.get(t, 2, "method"))
+	assert.Equal(t, "cn=old,ou=users,dc=example,dc=com", store.get(t, 2, "ldap.dn"))
+	assert.Equal(t, "noSuchObject", store.get(t, 2, "ldap.result"))
+	assert.Equal(t, "ou=users,dc=example,dc=com", store.get(t, 2, "ldap.matched_dn"))
+
+	assert.Equal(t, "modify_dn", store.get(t, 3, "method"))
+	assert.Equal(t, "cn=renamed", store.get(t, 3, "ldap.modify_dn.new_rdn"))
+	assert.Equal(t, true, store.get(t, 3, "ldap.modify_dn.delete_old_rdn"))
+	assert.Equal(t, "ou=admins,dc=example,dc=com", store.get(t, 3, "ldap.modify_dn.new_superior"))
+
+	assert.Equal(t, "compare", store.get(t, 4, "method"))
+	assert.Equal(t, "memberOf", store.get(t, 4, "ldap.compare.attribute"))
+	assert.Equal(t, "OK", store.get(t, 4, "status"))
+	assert.Equal(t, "compareTrue", store.get(t, 4, "ldap.result"))
+}
+
+func TestAsynchronousRequests(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: searchRequest(1, "ou=a,dc=example,dc=com", 1, ctx(7, "cn"))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: searchRequest(2, "ou=b,dc=example,dc=com", 1, ctx(7, "cn"))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: searchEntry(2, "cn=b1,ou=b,dc=example,dc=com", 1)}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: searchEntry(1, "cn=a1,ou=a,dc=example,dc=com", 1)}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(2, result(opSearchResultDone, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: searchEntry(1, "cn=a2,ou=a,dc=example,dc=com", 1)}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opSearchResultDone, 4, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "ou=b,dc=example,dc=com", store.get(t, 0, "ldap.dn"))
+	assert.Equal(t, 1, store.get(t, 0, "ldap.search.entries"))
+	assert.Equal(t, "ou=a,dc=example,dc=com", store.get(t, 1, "ldap.dn"))
+	assert.Equal(t, 2, store.get(t, 1, "ldap.search.entries"))
+	assert.Equal(t, "sizeLimitExceeded", store.get(t, 1, "ldap.result"))
+	assert.Equal(t, "one", store.get(t, 1, "ldap.search.scope"))
+}
+
+func TestUnansweredRequests(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: searchRequest(1, "dc=example,dc=com", 2, ctx(7, "cn"))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: searchRequest(2, "dc=example,dc=com", 2, ctx(7, "sn"))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(3, bertest.TLV(0x50, bertest.IntBytes(1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(4, bertest.TLV(0x42, nil))}, tuple, tcp.TCPDirectionOriginal, private)
+
+	require.Len(t, store.events, 3)
+	assert.Equal(t, "abandon", store.get(t, 0, "method"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.EqualValues(t, 1, store.get(t, 0, "ldap.abandon.message_id"))
+	assert.Equal(t, "search", store.get(t, 1, "method"))
+	assert.EqualValues(t, 1, store.get(t, 1, "ldap.message_id"))
+	assert.Equal(t, "Error", store.get(t, 1, "status"))
+	assert.Equal(t, "Abandoned request", store.get(t, 1, "error.message"))
+	assert.Equal(t, "unbind", store.get(t, 2, "method"))
+	assert.Equal(t, "OK", store.get(t, 2, "status"))
+
+	plugin.Expired(tuple, private)
+	require.Len(t, store.events, 4)
+	assert.EqualValues(t, 2, store.get(t, 3, "ldap.message_id"))
+	assert.Equal(t, "Error", store.get(t, 3, "status"))
+	assert.Equal(t, "Unmatched request", store.get(t, 3, "error.message"))
+	store.missing(t, 3, "ldap.result")
+}
+
+func TestStartTLS(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, bertest.TLV(0x77, ctx(0, oidStartTLS)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opExtendedResponse, 0, "", ""))}, tuple, tcp.TCPDirectionReverse, private)
+	// TLS handshake.
+	private = plugin.Parse(&protos.Packet{Payload: []byte{0x16, 0x03, 0x01, 0x00, 0x05, 0x01, 0x00, 0x00, 0x01, 0x00}}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: []byte{0x16, 0x03, 0x03, 0x00, 0x05, 0x02, 0x00, 0x00, 0x01, 0x00}}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "extended", store.get(t, 0, "method"))
+	assert.Equal(t, oidStartTLS, store.get(t, 0, "ldap.extended.oid"))
+	assert.Equal(t, "StartTLS", store.get(t, 0, "ldap.extended.name"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.True(t, private.(*connection).encrypted)
+}
+
+func TestNotLDAP(t *testing.T) {
+	store := &eventStore{}
+	plugin := ldapModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")}, tuple, tcp.TCPDirectionOriginal, private)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+
+	// Responses sent by the client.
+	plugin = ldapModForTests(t, store)
+	private = nil
+	private = plugin.Parse(&protos.Packet{Payload: msg(1, result(opBindResponse, 0, "", ""))}, tuple, tcp.TCPDirectionOriginal, private)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+	assert.Empty(t, store.events)
+}
@@ -0,0 +1,379 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/protos/ber"
+)
+
+// maxListedValues bounds the number of attributes, changes and controls
+// reported for a message.
+const maxListedValues = 100
+
+var errInvalidMessage = errors.New("invalid LDAP message")
+
+type message struct {
+	ts        time.Time
+	size      int
+	truncated bool
+
+	id       int64
+	op       int
+	controls []string
+
+	// dn is the entry targeted by the operation: the bind name, search
+	// base or entry modified.
+	dn string
+
+	// bind
+	version       int64
+	auth          string
+	saslMechanism string
+
+	// search
+	scope        int64
+	derefAliases int64
+	sizeLimit    int64
+	timeLimit    int64
+	typesOnly    bool
+	filter       string
+
+	// attributes requested by a search, or of the entry added.
+	attributes []string
+	// changes of a modify request, like "replace: description".
+	changes []string
+
+	// modify DN
+	newRDN       string
+	deleteOldRDN bool
+	newSuperior  string
+
+	// compare
+	compareAttribute string
+
+	// extended
+	requestName string
+
+	// abandon
+	abandonID int64
+	// abandoned is set on requests abandoned by the client.
+	abandoned bool
+
+	// LDAPResult of responses.
+	hasResult  bool
+	resultCode int64
+	matchedDN  string
+	diagnostic string
+
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+	direction    uint8
+}
+
+// decodeMessageHeader decodes the message ID and operation at the start
+// of a message that is too large to be decoded in full.
+func decodeMessageHeader(data []byte, m *message) error {
+	h, err := ber.ReadHeader(data)
+	if err != nil {
+		return err
+	}
+	if !h.Constructed || h.Class != ber.ClassUniversal || h.Tag != ber.TagSequence {
+		return errInvalidMessage
+	}
+	id, rest, err := ber.Decode(data[h.HeaderLen:])
+	if err != nil {
+		return err
+	}
+	if !id.IsUniversal(ber.TagInteger) {
+		return errInvalidMessage
+	}
+	if m.id, err = id.Int(); err != nil {
+		return err
+	}
+	op, err := ber.ReadHeader(rest)
+	if err != nil {
+		return err
+	}
+	if op.Class != ber.ClassApplication {
+		return errInvalidMessage
+	}
+	m.op = op.Tag
+	return nil
+}
+
+// decodeMessage decodes an LDAPMessage (RFC 4511, section 4.1.1).
+func decodeMessage(data []byte, m *message) error {
+	envelope, _, err := ber.Decode(data)
+	if err != nil {
+		return err
+	}
+	if !envelope.Constructed || !envelope.IsUniversal(ber.TagSequence) {
+		return errInvalidMessage
+	}
+	children, err := envelope.Children()
+	if err != nil {
+		return err
+	}
+	if len(children) < 2 || !children[0].IsUniversal(ber.TagInteger) || children[1].Class != ber.ClassApplication {
+		return errInvalidMessage
+	}
+	if m.id, err = children[0].Int(); err != nil {
+		return err
+	}
+	op := children[1]
+	m.op = op.Tag
+
+	for _, child := range children[2:] {
+		if child.Is(ber.ClassContext, 0) && child.Constructed {
+			m.controls = decodeControls(child)
+		}
+	}
+
+	switch op.Tag {
+	case opBindRequest:
+		return decodeBindRequest(op, m)
+	case opSearchRequest:
+		return decodeSearchRequest(op, m)
+	case opModifyRequest:
+		return decodeModifyRequest(op, m)
+	case opAddRequest:
+		return decodeAddRequest(op, m)
+	case opDelRequest:
+		m.dn = op.String()
+	case opModifyDNRequest:
+		return decodeModifyDNRequest(op, m)
+	case opCompareRequest:
+		return decodeCompareRequest(op, m)
+	case opAbandonRequest:
+		m.abandonID, err = op.Int()
+		return err
+	case opExtendedRequest:
+		return decodeExtendedRequest(op, m)
+	case opBindResponse, opSearchResultDone, opModifyResponse, opAddResponse,
+		opDelResponse, opModifyDNResponse, opCompareResponse, opExtendedResponse:
+		return decodeResult(op, m)
+	}
+	return nil
+}
+
+func decodeControls(e ber.Element) []string {
+	controls, err := e.Children()
+	if err != nil {
+		return nil
+	}
+	var oids []string
+	for _, control := range controls {
+		fields, err := control.Children()
+		if err != nil || len(fields) == 0 || len(oids) >= maxListedValues {
+			continue
+		}
+		oids = append(oids, fields[0].String())
+	}
+	return oids
+}
+
+// children decodes the elements of a constructed operation and checks
+// that there are at least n of them.
+func children(e ber.Element, n int) ([]ber.Element, error) {
+	list, err := e.Children()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < n {
+		return nil, fmt.Errorf("LDAP operation %d has %d elements, expected at least %d", e.Tag, len(list), n)
+	}
+	return list, nil
+}
+
+func decodeBindRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 3)
+	if err != nil {
+		return err
+	}
+	if m.version, err = fields[0].Int(); err != nil {
+		return err
+	}
+	m.dn = fields[1].String()
+
+	auth := fields[2]
+	if auth.Class != ber.ClassContext {
+		return errInvalidMessage
+	}
+	m.auth = bindAuthentication[auth.Tag]
+	switch {
+	case m.auth == "":
+		m.auth = fmt.Sprintf("unknown (%d)", auth.Tag)
+	case auth.Tag == 0 && len(auth.Content) == 0 && m.dn == "":
+		m.auth = "anonymous"
+	case auth.Tag == 0 && len(auth.Content) == 0:
+		m.auth = "unauthenticated"
+	case auth.Tag == 3:
+		sasl, err := auth.Children()
+		if err != nil || len(sasl) == 0 {
+			return errInvalidMessage
+		}
+		m.saslMechanism = sasl[0].String()
+	}
+	return nil
+}
+
+func decodeSearchRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 8)
+	if err != nil {
+		return err
+	}
+	m.dn = fields[0].String()
+	for i, v := range []*int64{&m.scope, &m.derefAliases, &m.sizeLimit, &m.timeLimit} {
+		if *v, err = fields[1+i].Int(); err != nil {
+			return err
+		}
+	}
+	if m.typesOnly, err = fields[5].Bool(); err != nil {
+		return err
+	}
+	if m.filter, err = formatFilter(fields[6]); err != nil {
+		return err
+	}
+	attributes, err := fields[7].Children()
+	if err != nil {
+		return err
+	}
+	for _, attr := range attributes {
+		if len(m.attributes) >= maxListedValues {
+			break
+		}
+		m.attributes = append(m.attributes, attr.String())
+	}
+	return nil
+}
+
+func decodeModifyRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 2)
+	if err != nil {
+		return err
+	}
+	m.dn = fields[0].String()
+	changes, err := fields[1].Children()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if len(m.changes) >= maxListedValues {
+			break
+		}
+		parts, err := children(change, 2)
+		if err != nil {
+			return err
+		}
+		operation, err := parts[0].Int()
+		if err != nil {
+			return err
+		}
+		attribute, err := children(parts[1], 1)
+		if err != nil {
+			return err
+		}
+		m.changes = append(m.changes, enumName(modifyOperationNames, operation)+": "+attribute[0].String())
+	}
+	return nil
+}
+
+func decodeAddRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 2)
+	if err != nil {
+		return err
+	}
+	m.dn = fields[0].String()
+	attributes, err := fields[1].Children()
+	if err != nil {
+		return err
+	}
+	for _, attr := range attributes {
+		if len(m.attributes) >= maxListedValues {
+			break
+		}
+		parts, err := children(attr, 1)
+		if err != nil {
+			return err
+		}
+		m.attributes = append(m.attributes, parts[0].String())
+	}
+	return nil
+}
+
+func decodeModifyDNRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 3)
+	if err != nil {
+		return err
+	}
+	m.dn = fields[0].String()
+	m.newRDN = fields[1].String()
+	if m.deleteOldRDN, err = fields[2].Bool(); err != nil {
+		return err
+	}
+	if len(fields) > 3 && fields[3].Is(ber.ClassContext, 0) {
+		m.newSuperior = fields[3].String()
+	}
+	return nil
+}
+
+func decodeCompareRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 2)
+	if err != nil {
+		return err
+	}
+	m.dn = fields[0].String()
+	ava, err := children(fields[1], 1)
+	if err != nil {
+		return err
+	}
+	m.compareAttribute = ava[0].String()
+	return nil
+}
+
+func decodeExtendedRequest(op ber.Element, m *message) error {
+	fields, err := children(op, 1)
+	if err != nil {
+		return err
+	}
+	if !fields[0].Is(ber.ClassContext, 0) {
+		return errInvalidMessage
+	}
+	m.requestName = fields[0].String()
+	return nil
+}
+
+// decodeResult decodes the LDAPResult components of a response.
+func decodeResult(op ber.Element, m *message) error {
+	fields, err := children(op, 3)
+	if err != nil {
+		return err
+	}
+	if m.resultCode, err = fields[0].Int(); err != nil {
+		return err
+	}
+	m.matchedDN = fields[1].String()
+	m.diagnostic = fields[2].String()
+	m.hasResult = true
+	return nil
+}
@@ -0,0 +1,192 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import "strconv"
+
+// Application tags of the protocolOp choice (RFC 4511, section 4.2).
+const (
+	opBindRequest           = 0
+	opBindResponse          = 1
+	opUnbindRequest         = 2
+	opSearchRequest         = 3
+	opSearchResultEntry     = 4
+	opSearchResultDone      = 5
+	opModifyRequest         = 6
+	opModifyResponse        = 7
+	opAddRequest            = 8
+	opAddResponse           = 9
+	opDelRequest            = 10
+	opDelResponse           = 11
+	opModifyDNRequest       = 12
+	opModifyDNResponse      = 13
+	opCompareRequest        = 14
+	opCompareResponse       = 15
+	opAbandonRequest        = 16
+	opSearchResultReference = 19
+	opExtendedRequest       = 23
+	opExtendedResponse      = 24
+	opIntermediateResponse  = 25
+)
+
+// operationNames are the names of the request operations, used as the
+// event method.
+var operationNames = map[int]string{
+	opBindRequest:     "bind",
+	opUnbindRequest:   "unbind",
+	opSearchRequest:   "search",
+	opModifyRequest:   "modify",
+	opAddRequest:      "add",
+	opDelRequest:      "delete",
+	opModifyDNRequest: "modify_dn",
+	opCompareRequest:  "compare",
+	opAbandonRequest:  "abandon",
+	opExtendedRequest: "extended",
+}
+
+// responseOps maps the request operations that are answered to the
+// operation of their final response.
+var responseOps = map[int]int{
+	opBindRequest:     opBindResponse,
+	opSearchRequest:   opSearchResultDone,
+	opModifyRequest:   opModifyResponse,
+	opAddRequest:      opAddResponse,
+	opDelRequest:      opDelResponse,
+	opModifyDNRequest: opModifyDNResponse,
+	opCompareRequest:  opCompareResponse,
+	opExtendedRequest: opExtendedResponse,
+}
+
+func isRequestOp(op int) bool {
+	_, found := operationNames[op]
+	return found
+}
+
+// Result codes (RFC 4511, appendix A and the IANA LDAP Result Code
+// registry).
+const (
+	resultSuccess            = 0
+	resultCompareFalse       = 5
+	resultCompareTrue        = 6
+	resultReferral           = 10
+	resultSaslBindInProgress = 14
+)
+
+var resultCodeNames = map[int64]string{
+	0:    "success",
+	1:    "operationsError",
+	2:    "protocolError",
+	3:    "timeLimitExceeded",
+	4:    "sizeLimitExceeded",
+	5:    "compareFalse",
+	6:    "compareTrue",
+	7:    "authMethodNotSupported",
+	8:    "strongerAuthRequired",
+	10:   "referral",
+	11:   "adminLimitExceeded",
+	12:   "unavailableCriticalExtension",
+	13:   "confidentialityRequired",
+	14:   "saslBindInProgress",
+	16:   "noSuchAttribute",
+	17:   "undefinedAttributeType",
+	18:   "inappropriateMatching",
+	19:   "constraintViolation",
+	20:   "attributeOrValueExists",
+	21:   "invalidAttributeSyntax",
+	32:   "noSuchObject",
+	33:   "aliasProblem",
+	34:   "invalidDNSyntax",
+	36:   "aliasDereferencingProblem",
+	48:   "inappropriateAuthentication",
+	49:   "invalidCredentials",
+	50:   "insufficientAccessRights",
+	51:   "busy",
+	52:   "unavailable",
+	53:   "unwillingToPerform",
+	54:   "loopDetect",
+	64:   "namingViolation",
+	65:   "objectClassViolation",
+	66:   "notAllowedOnNonLeaf",
+	67:   "notAllowedOnRDN",
+	68:   "entryAlreadyExists",
+	69:   "objectClassModsProhibited",
+	71:   "affectsMultipleDSAs",
+	80:   "other",
+	118:  "canceled",
+	119:  "noSuchOperation",
+	120:  "tooLate",
+	121:  "cannotCancel",
+	122:  "assertionFailed",
+	123:  "authorizationDenied",
+	4096: "e-syncRefreshRequired",
+}
+
+func resultCodeName(code int64) string {
+	if name, found := resultCodeNames[code]; found {
+		return name
+	}
+	return strconv.FormatInt(code, 10)
+}
+
+// isSuccessResult returns whether a result code reports an operation that
+// didn't fail.
+func isSuccessResult(code int64) bool {
+	switch code {
+	case resultSuccess, resultCompareFalse, resultCompareTrue, resultReferral, resultSaslBindInProgress:
+		return true
+	}
+	return false
+}
+
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+var extendedOperationNames = map[string]string{
+	oidStartTLS:                  "StartTLS",
+	"1.3.6.1.4.1.1466.20036":     "Notice of Disconnection",
+	"1.3.6.1.4.1.4203.1.11.1":    "Password Modify",
+	"1.3.6.1.4.1.4203.1.11.3":    "Who am I?",
+	"1.3.6.1.1.8":                "Cancel",
+	"1.3.6.1.1.21.1":             "Start Transaction",
+	"1.3.6.1.1.21.3":             "End Transaction",
+	"1.3.6.1.4.1.1466.101.119.1": "Dynamic Refresh",
+	"1.2.840.113556.1.4.1781":    "Fast Concurrent Bind",
+}
+
+var searchScopeNames = []string{"base", "one", "sub", "children"}
+
+var derefAliasesNames = []string{"never", "searching", "finding", "always"}
+
+var modifyOperationNames = []string{"add", "delete", "replace", "increment"}
+
+// bindAuthentication names the authentication choices of a BindRequest.
+// Tags 9 to 11 are used by Active Directory for NTLM (sicily)
+// authentication.
+var bindAuthentication = map[int]string{
+	0:  "simple",
+	3:  "sasl",
+	9:  "sicily_discovery",
+	10: "sicily_negotiate",
+	11: "sicily_response",
+}
+
+func enumName(names []string, v int64) string {
+	if v >= 0 && v < int64(len(names)) {
+		return names[v]
+	}
+	return strconv.FormatInt(v, 10)
+}
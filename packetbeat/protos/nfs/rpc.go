@@ -123,6 +123,11 @@ func (r *rpc) Close() {
 	r.callsSeen.StopJanitor()
 }
 
+// Flush publishes all calls without a reply.
+func (r *rpc) Flush() {
+	r.callsSeen.Flush()
+}
+
 func (r *rpc) GetPorts() []int {
 	return r.ports
 }
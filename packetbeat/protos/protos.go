@@ -83,6 +83,22 @@ type PluginCloser interface {
 	Close()
 }
 
+// PluginFlusher is an optional interface that protocol plugins can implement
+// to publish or discard all pending transactions immediately instead of
+// waiting for them to expire, e.g. at the end of a replayed capture file.
+// Flush must be safe to call more than once.
+type PluginFlusher interface {
+	Flush()
+}
+
+// PluginLinker is an optional interface for protocol plugins that hand data
+// over to other protocol plugins, e.g. decrypted TLS payloads passed on to
+// the HTTP analyzer. Link is called once all configured protocols have been
+// registered.
+type PluginLinker interface {
+	Link(protocols Protocols)
+}
+
 type Protocols interface {
 	BpfFilter(withVlans bool, withICMP bool) string
 	GetTCP(proto Protocol) TCPPlugin
@@ -152,6 +168,12 @@ func (s ProtocolsStruct) InitFiltered(test bool, device string, pub reporterFact
 		}
 	}
 
+	for _, inst := range s.all {
+		if linker, ok := inst.plugin.(PluginLinker); ok {
+			linker.Link(s)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,188 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Salts used to derive the Initial secrets (RFC 9001, section 5.2 and
+// RFC 9369, section 3.3.1).
+var (
+	initialSaltV1 = []byte{
+		0x38, 0x76, 0x2c, 0xf7, 0xf5, 0x59, 0x34, 0xb3, 0x4d, 0x17,
+		0x9a, 0xe6, 0xa4, 0xc8, 0x0c, 0xad, 0xcc, 0xbb, 0x7f, 0x0a,
+	}
+	initialSaltV2 = []byte{
+		0x0d, 0xed, 0xe3, 0xde, 0xf7, 0x00, 0xa6, 0xdb, 0x81, 0x93,
+		0x81, 0xbe, 0x6e, 0x26, 0x9d, 0xcb, 0xf9, 0xbd, 0x2e, 0xd9,
+	}
+	initialSaltDraft29 = []byte{
+		0xaf, 0xbf, 0xec, 0x28, 0x99, 0x93, 0xd2, 0x4c, 0x9e, 0x97,
+		0x86, 0xf1, 0x9c, 0x61, 0x11, 0xe0, 0x43, 0x90, 0xa8, 0x99,
+	}
+)
+
+var errDecrypt = errors.New("failed to decrypt packet")
+
+// isSupportedVersion returns whether the Initial packets of a QUIC version
+// can be decrypted.
+func isSupportedVersion(v uint32) bool {
+	return v == version1 || v == version2 ||
+		(v >= versionDraft29 && v <= versionDraft34)
+}
+
+// initialKeys protect the Initial packets sent by one endpoint.
+type initialKeys struct {
+	aead cipher.AEAD
+	iv   []byte
+	hp   cipher.Block
+}
+
+// newInitialKeys derives the client and server Initial keys from the
+// Destination Connection ID of the first Initial packet sent by the client.
+func newInitialKeys(version uint32, dcid []byte) (client, server *initialKeys, err error) {
+	salt, labelPrefix := initialSaltV1, "quic "
+	switch {
+	case version == version2:
+		salt, labelPrefix = initialSaltV2, "quicv2 "
+	case version >= versionDraft29 && version <= 0xff000020:
+		salt = initialSaltDraft29
+	case !isSupportedVersion(version):
+		return nil, nil, fmt.Errorf("unsupported version %s", versionName(version))
+	}
+
+	initialSecret, err := hkdf.Extract(sha256.New, dcid, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientSecret, err := expandLabel(initialSecret, "client in", sha256.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+	serverSecret, err := expandLabel(initialSecret, "server in", sha256.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+	if client, err = newPacketKeys(clientSecret, labelPrefix); err != nil {
+		return nil, nil, err
+	}
+	if server, err = newPacketKeys(serverSecret, labelPrefix); err != nil {
+		return nil, nil, err
+	}
+	return client, server, nil
+}
+
+func newPacketKeys(secret []byte, labelPrefix string) (*initialKeys, error) {
+	key, err := expandLabel(secret, labelPrefix+"key", 16)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := expandLabel(secret, labelPrefix+"iv", 12)
+	if err != nil {
+		return nil, err
+	}
+	hpKey, err := expandLabel(secret, labelPrefix+"hp", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	hp, err := aes.NewCipher(hpKey)
+	if err != nil {
+		return nil, err
+	}
+	return &initialKeys{aead: aead, iv: iv, hp: hp}, nil
+}
+
+// expandLabel implements HKDF-Expand-Label with an empty context
+// (RFC 8446, section 7.1).
+func expandLabel(secret []byte, label string, length int) ([]byte, error) {
+	label = "tls13 " + label
+	info := make([]byte, 0, 4+len(label))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, byte(len(label)))
+	info = append(info, label...)
+	info = append(info, 0)
+	return hkdf.Expand(sha256.New, secret, string(info), length)
+}
+
+// open removes the header protection of an Initial or Handshake packet and
+// decrypts its payload. largest is the largest packet number successfully
+// decrypted so far in the same direction, or -1. The packet is not modified.
+func (k *initialKeys) open(h *longHeader, largest int64) (payload []byte, pn int64, err error) {
+	sampleOffset := h.pnOffset + 4
+	if sampleOffset+aes.BlockSize > len(h.packet) {
+		return nil, 0, errTruncated
+	}
+	var mask [aes.BlockSize]byte
+	k.hp.Encrypt(mask[:], h.packet[sampleOffset:sampleOffset+aes.BlockSize])
+
+	header := make([]byte, h.pnOffset+4)
+	copy(header, h.packet)
+	header[0] ^= mask[0] & 0x0f
+	pnLen := int(header[0]&0x03) + 1
+	var truncated uint64
+	for i := 0; i < pnLen; i++ {
+		header[h.pnOffset+i] ^= mask[1+i]
+		truncated = truncated<<8 | uint64(header[h.pnOffset+i])
+	}
+	header = header[:h.pnOffset+pnLen]
+	pn = decodePacketNumber(largest, truncated, pnLen*8)
+
+	nonce := make([]byte, len(k.iv))
+	copy(nonce, k.iv)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(uint64(pn) >> (8 * i))
+	}
+	payload, err = k.aead.Open(nil, nonce, h.packet[len(header):], header)
+	if err != nil {
+		return nil, 0, errDecrypt
+	}
+	return payload, pn, nil
+}
+
+// decodePacketNumber recovers a full packet number from its truncated
+// encoding (RFC 9000, appendix A.3).
+func decodePacketNumber(largest int64, truncated uint64, bits int) int64 {
+	expected := largest + 1
+	win := int64(1) << bits
+	hwin := win / 2
+	mask := win - 1
+	candidate := (expected &^ mask) | int64(truncated)
+	switch {
+	case candidate <= expected-hwin && candidate < (1<<62)-win:
+		return candidate + win
+	case candidate > expected+hwin && candidate >= win:
+		return candidate - win
+	}
+	return candidate
+}
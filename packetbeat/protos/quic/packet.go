@@ -0,0 +1,325 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	version1       uint32 = 0x00000001
+	version2       uint32 = 0x6b3343cf
+	versionDraft29 uint32 = 0xff00001d
+	versionDraft34 uint32 = 0xff000022
+
+	maxConnectionIDLength = 20
+)
+
+type packetType uint8
+
+const (
+	packetInitial packetType = iota
+	packetZeroRTT
+	packetHandshake
+	packetRetry
+	packetVersionNegotiation
+)
+
+var packetTypeNames = []string{
+	packetInitial:            "initial",
+	packetZeroRTT:            "0-rtt",
+	packetHandshake:          "handshake",
+	packetRetry:              "retry",
+	packetVersionNegotiation: "version_negotiation",
+}
+
+func (t packetType) String() string {
+	if int(t) < len(packetTypeNames) {
+		return packetTypeNames[t]
+	}
+	return fmt.Sprintf("unknown (%d)", t)
+}
+
+// Frame types that can be found in Initial packets (RFC 9000, section 12.4).
+const (
+	framePadding         = 0x00
+	framePing            = 0x01
+	frameACK             = 0x02
+	frameACKECN          = 0x03
+	frameCrypto          = 0x06
+	frameConnectionClose = 0x1c
+)
+
+var (
+	errTruncated   = errors.New("truncated packet")
+	errNotLongForm = errors.New("not a long header packet")
+	errFixedBit    = errors.New("fixed bit not set")
+)
+
+// longHeader is a parsed long header packet (RFC 9000, section 17.2).
+type longHeader struct {
+	version uint32
+	typ     packetType
+	dcid    []byte
+	scid    []byte
+
+	// token of an Initial packet, or retry token of a Retry packet.
+	token []byte
+	// versions offered in a Version Negotiation packet.
+	versions []uint32
+
+	// packet holds the complete packet, and pnOffset the offset of the
+	// (protected) packet number within it.
+	packet   []byte
+	pnOffset int
+}
+
+// versionName returns the name used to report a QUIC version.
+func versionName(v uint32) string {
+	switch {
+	case v == version1:
+		return "1"
+	case v == version2:
+		return "2"
+	case v&0xffffff00 == 0xff000000:
+		return fmt.Sprintf("draft-%d", v&0xff)
+	}
+	return fmt.Sprintf("0x%08x", v)
+}
+
+// isLongHeader returns whether the packet starting with b uses the long
+// header form. Packets using the short header form are only sent once the
+// handshake is complete and are not inspected.
+func isLongHeader(b byte) bool {
+	return b&0x80 != 0
+}
+
+// parseLongHeader parses the long header packet at the start of data and
+// returns the remaining data, which holds any coalesced packets.
+func parseLongHeader(data []byte) (*longHeader, []byte, error) {
+	if len(data) < 7 {
+		return nil, nil, errTruncated
+	}
+	if !isLongHeader(data[0]) {
+		return nil, nil, errNotLongForm
+	}
+	h := &longHeader{version: binary.BigEndian.Uint32(data[1:5])}
+
+	pos := 5
+	var err error
+	if h.dcid, pos, err = readConnectionID(data, pos); err != nil {
+		return nil, nil, err
+	}
+	if h.scid, pos, err = readConnectionID(data, pos); err != nil {
+		return nil, nil, err
+	}
+
+	if h.version == 0 {
+		h.typ = packetVersionNegotiation
+		for rest := data[pos:]; len(rest) >= 4; rest = rest[4:] {
+			h.versions = append(h.versions, binary.BigEndian.Uint32(rest))
+		}
+		h.packet = data
+		return h, nil, nil
+	}
+
+	if data[0]&0x40 == 0 {
+		return nil, nil, errFixedBit
+	}
+	if len(h.dcid) > maxConnectionIDLength || len(h.scid) > maxConnectionIDLength {
+		return nil, nil, fmt.Errorf("connection ID too long")
+	}
+	h.typ = longPacketType(h.version, data[0])
+
+	switch h.typ {
+	case packetRetry:
+		// A Retry packet ends with a 16 bytes integrity tag.
+		if len(data)-pos < 16 {
+			return nil, nil, errTruncated
+		}
+		h.token = data[pos : len(data)-16]
+		h.packet = data
+		return h, nil, nil
+	case packetInitial:
+		tokenLen, n, err := readVarint(data[pos:])
+		if err != nil {
+			return nil, nil, err
+		}
+		pos += n
+		if tokenLen > uint64(len(data)-pos) {
+			return nil, nil, errTruncated
+		}
+		h.token = data[pos : pos+int(tokenLen)]
+		pos += int(tokenLen)
+	}
+
+	length, n, err := readVarint(data[pos:])
+	if err != nil {
+		return nil, nil, err
+	}
+	pos += n
+	if length > uint64(len(data)-pos) {
+		return nil, nil, errTruncated
+	}
+	end := pos + int(length)
+	h.packet = data[:end]
+	h.pnOffset = pos
+	return h, data[end:], nil
+}
+
+// longPacketType returns the type of a long header packet. QUIC version 2
+// uses different type values than version 1 (RFC 9369, section 3.2).
+func longPacketType(version uint32, first byte) packetType {
+	typ := packetType(first>>4) & 0x03
+	if version == version2 {
+		typ = (typ + 3) % 4
+	}
+	return typ
+}
+
+func readConnectionID(data []byte, pos int) ([]byte, int, error) {
+	if pos >= len(data) {
+		return nil, pos, errTruncated
+	}
+	l := int(data[pos])
+	pos++
+	if l > len(data)-pos {
+		return nil, pos, errTruncated
+	}
+	return data[pos : pos+l], pos + l, nil
+}
+
+// readVarint reads a variable-length integer (RFC 9000, section 16) and
+// returns its value and encoded length.
+func readVarint(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, errTruncated
+	}
+	n := 1 << (data[0] >> 6)
+	if len(data) < n {
+		return 0, 0, errTruncated
+	}
+	v := uint64(data[0] & 0x3f)
+	for _, b := range data[1:n] {
+		v = v<<8 | uint64(b)
+	}
+	return v, n, nil
+}
+
+// cryptoFrame is a CRYPTO frame, carrying TLS handshake data.
+type cryptoFrame struct {
+	offset uint64
+	data   []byte
+}
+
+// connectionClose is a CONNECTION_CLOSE frame.
+type connectionClose struct {
+	errorCode uint64
+	frameType uint64
+	reason    string
+}
+
+// parseFrames parses the frames in the decrypted payload of an Initial
+// packet. Only frames allowed in Initial packets are accepted.
+func parseFrames(payload []byte) (frames []cryptoFrame, closeFrame *connectionClose, err error) {
+	for len(payload) > 0 {
+		typ, n, err := readVarint(payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		payload = payload[n:]
+
+		switch typ {
+		case framePadding, framePing:
+		case frameACK, frameACKECN:
+			if payload, err = skipACK(payload, typ == frameACKECN); err != nil {
+				return nil, nil, err
+			}
+		case frameCrypto:
+			var offset, length uint64
+			if offset, payload, err = nextVarint(payload); err != nil {
+				return nil, nil, err
+			}
+			if length, payload, err = nextVarint(payload); err != nil {
+				return nil, nil, err
+			}
+			if length > uint64(len(payload)) {
+				return nil, nil, errTruncated
+			}
+			frames = append(frames, cryptoFrame{offset: offset, data: payload[:length]})
+			payload = payload[length:]
+		case frameConnectionClose:
+			closeFrame = &connectionClose{}
+			if closeFrame.errorCode, payload, err = nextVarint(payload); err != nil {
+				return nil, nil, err
+			}
+			if closeFrame.frameType, payload, err = nextVarint(payload); err != nil {
+				return nil, nil, err
+			}
+			var length uint64
+			if length, payload, err = nextVarint(payload); err != nil {
+				return nil, nil, err
+			}
+			if length > uint64(len(payload)) {
+				return nil, nil, errTruncated
+			}
+			closeFrame.reason = string(payload[:length])
+			payload = payload[length:]
+		default:
+			return nil, nil, fmt.Errorf("unexpected frame type 0x%x in initial packet", typ)
+		}
+	}
+	return frames, closeFrame, nil
+}
+
+// skipACK skips the body of an ACK frame.
+func skipACK(payload []byte, ecn bool) ([]byte, error) {
+	var rangeCount uint64
+	var err error
+	// Largest Acknowledged, ACK Delay, ACK Range Count, First ACK Range.
+	for i := 0; i < 4; i++ {
+		var v uint64
+		if v, payload, err = nextVarint(payload); err != nil {
+			return nil, err
+		}
+		if i == 2 {
+			rangeCount = v
+		}
+	}
+	// Gap and ACK Range Length for each range, then the ECN counts.
+	count := 2 * rangeCount
+	if ecn {
+		count += 3
+	}
+	for ; count > 0; count-- {
+		if _, payload, err = nextVarint(payload); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+func nextVarint(data []byte) (uint64, []byte, error) {
+	v, n, err := readVarint(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return v, data[n:], nil
+}
@@ -0,0 +1,286 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package quic
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	switch {
+	case v < 1<<6:
+		return append(b, byte(v))
+	case v < 1<<14:
+		return binary.BigEndian.AppendUint16(b, uint16(v)|0x4000)
+	case v < 1<<30:
+		return binary.BigEndian.AppendUint32(b, uint32(v)|0x80000000)
+	}
+	return binary.BigEndian.AppendUint64(b, v|0xc000000000000000)
+}
+
+// sealInitial builds an Initial packet protected with keys, using a 4 bytes
+// packet number.
+func sealInitial(keys *initialKeys, version uint32, dcid, scid []byte, pn uint32, payload []byte) []byte {
+	var typ byte
+	if version == version2 {
+		typ = 1
+	}
+	packet := []byte{0xc0 | typ<<4 | 0x03}
+	packet = binary.BigEndian.AppendUint32(packet, version)
+	packet = append(packet, byte(len(dcid)))
+	packet = append(packet, dcid...)
+	packet = append(packet, byte(len(scid)))
+	packet = append(packet, scid...)
+	packet = appendVarint(packet, 0) // token length
+	packet = appendVarint(packet, uint64(4+len(payload)+keys.aead.Overhead()))
+	pnOffset := len(packet)
+	packet = binary.BigEndian.AppendUint32(packet, pn)
+
+	nonce := make([]byte, len(keys.iv))
+	copy(nonce, keys.iv)
+	for i := 0; i < 4; i++ {
+		nonce[len(nonce)-1-i] ^= byte(pn >> (8 * i))
+	}
+	packet = keys.aead.Seal(packet, nonce, payload, packet)
+
+	var mask [16]byte
+	keys.hp.Encrypt(mask[:], packet[pnOffset+4:pnOffset+20])
+	packet[0] ^= mask[0] & 0x0f
+	for i := 0; i < 4; i++ {
+		packet[pnOffset+i] ^= mask[1+i]
+	}
+	return packet
+}
+
+// longPacket builds an unprotected long header packet, used for packet
+// types that are not decrypted.
+func longPacket(first byte, version uint32, dcid, scid, body []byte) []byte {
+	packet := []byte{first}
+	packet = binary.BigEndian.AppendUint32(packet, version)
+	packet = append(packet, byte(len(dcid)))
+	packet = append(packet, dcid...)
+	packet = append(packet, byte(len(scid)))
+	packet = append(packet, scid...)
+	return append(packet, body...)
+}
+
+func TestReadVarint(t *testing.T) {
+	// Examples from RFC 9000, appendix A.1.
+	for encoded, expected := range map[string]uint64{
+		"c2197c5eff14e88c": 151288809941952652,
+		"9d7f3e7d":         494878333,
+		"7bbd":             15293,
+		"25":               37,
+		"4025":             37,
+	} {
+		data := unhex(t, encoded)
+		v, n, err := readVarint(append(data, 0xff))
+		require.NoError(t, err)
+		assert.Equal(t, expected, v, encoded)
+		assert.Equal(t, len(data), n, encoded)
+	}
+
+	_, _, err := readVarint(unhex(t, "9d7f3e"))
+	assert.ErrorIs(t, err, errTruncated)
+	_, _, err = readVarint(nil)
+	assert.ErrorIs(t, err, errTruncated)
+}
+
+func TestInitialSecrets(t *testing.T) {
+	// Test vectors from RFC 9001, appendix A.1.
+	dcid := unhex(t, "8394c8f03e515708")
+	initialSecret, err := hkdf.Extract(sha256.New, dcid, initialSaltV1)
+	require.NoError(t, err)
+	assert.Equal(t, "7db5df06e7a69e432496adedb00851923595221596ae2ae9fb8115c1e9ed0a44", hex.EncodeToString(initialSecret))
+
+	for _, tc := range []struct {
+		label               string
+		secret, key, iv, hp string
+	}{
+		{
+			label:  "client in",
+			secret: "c00cf151ca5be075ed0ebfb5c80323c42d6b7db67881289af4008f1f6c357aea",
+			key:    "1f369613dd76d5467730efcbe3b1a22d",
+			iv:     "fa044b2f42a3fd3b46fb255c",
+			hp:     "9f50449e04a0e810283a1e9933adedd2",
+		},
+		{
+			label:  "server in",
+			secret: "3c199828fd139efd216c155ad844cc81fb82fa8d7446fa7d78be803acdda951b",
+			key:    "cf3a5331653c364c88f0f379b6067e37",
+			iv:     "0ac1493ca1905853b0bba03e",
+			hp:     "c206b8d9b9f0f37644430b490eeaa314",
+		},
+	} {
+		secret, err := expandLabel(initialSecret, tc.label, sha256.Size)
+		require.NoError(t, err)
+		assert.Equal(t, tc.secret, hex.EncodeToString(secret), tc.label)
+		for label, expected := range map[string]string{"quic key": tc.key, "quic iv": tc.iv, "quic hp": tc.hp} {
+			v, err := expandLabel(secret, label, len(expected)/2)
+			require.NoError(t, err)
+			assert.Equal(t, expected, hex.EncodeToString(v), tc.label+" "+label)
+		}
+	}
+
+	// Header protection mask from RFC 9001, appendix A.2.
+	client, _, err := newInitialKeys(version1, dcid)
+	require.NoError(t, err)
+	var mask [16]byte
+	client.hp.Encrypt(mask[:], unhex(t, "d1b1c98dd7689fb8ec11d242b123dc9b"))
+	assert.Equal(t, "437b9aec36", hex.EncodeToString(mask[:5]))
+
+	_, _, err = newInitialKeys(0x0a0a0a0a, dcid)
+	assert.Error(t, err)
+}
+
+func TestOpenInitial(t *testing.T) {
+	dcid := unhex(t, "8394c8f03e515708")
+	scid := unhex(t, "c101")
+	payload := append([]byte{framePing}, make([]byte, 40)...)
+
+	for _, version := range []uint32{version1, version2, versionDraft29} {
+		client, server, err := newInitialKeys(version, dcid)
+		require.NoError(t, err)
+		packet := sealInitial(client, version, dcid, scid, 7, payload)
+		coalesced := longPacket(0xe0, version, dcid, scid, []byte{0x01, 0xaa})
+		packet = append(packet, coalesced...)
+
+		h, rest, err := parseLongHeader(packet)
+		require.NoError(t, err, versionName(version))
+		assert.Equal(t, packetInitial, h.typ)
+		assert.Equal(t, version, h.version)
+		assert.Equal(t, dcid, h.dcid)
+		assert.Equal(t, scid, h.scid)
+		assert.Equal(t, coalesced, rest)
+
+		plain, pn, err := client.open(h, 5)
+		require.NoError(t, err, versionName(version))
+		assert.Equal(t, int64(7), pn)
+		assert.Equal(t, payload, plain)
+
+		// Opening doesn't modify the packet.
+		plain, _, err = client.open(h, -1)
+		require.NoError(t, err)
+		assert.Equal(t, payload, plain)
+
+		_, _, err = server.open(h, -1)
+		assert.ErrorIs(t, err, errDecrypt)
+	}
+}
+
+func TestDecodePacketNumber(t *testing.T) {
+	// Example from RFC 9000, appendix A.3.
+	assert.Equal(t, int64(0xa82f9b32), decodePacketNumber(0xa82f30ea, 0x9b32, 16))
+	assert.Equal(t, int64(0), decodePacketNumber(-1, 0, 8))
+	assert.Equal(t, int64(256), decodePacketNumber(255, 0, 8))
+	assert.Equal(t, int64(250), decodePacketNumber(260, 250, 8))
+}
+
+func TestParseLongHeader(t *testing.T) {
+	dcid, scid := []byte{1, 2, 3, 4}, []byte{5, 6}
+
+	t.Run("version negotiation", func(t *testing.T) {
+		h, rest, err := parseLongHeader(longPacket(0x8a, 0, dcid, scid, unhex(t, "000000016b3343cf")))
+		require.NoError(t, err)
+		assert.Nil(t, rest)
+		assert.Equal(t, packetVersionNegotiation, h.typ)
+		assert.Equal(t, []uint32{version1, version2}, h.versions)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		tag := make([]byte, 16)
+		h, _, err := parseLongHeader(longPacket(0xf0, version1, dcid, scid, append([]byte("token"), tag...)))
+		require.NoError(t, err)
+		assert.Equal(t, packetRetry, h.typ)
+		assert.Equal(t, []byte("token"), h.token)
+
+		// Version 2 uses a different packet type value.
+		h, _, err = parseLongHeader(longPacket(0xc0, version2, dcid, scid, append([]byte("token"), tag...)))
+		require.NoError(t, err)
+		assert.Equal(t, packetRetry, h.typ)
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		h, rest, err := parseLongHeader(longPacket(0xe0, version1, dcid, scid, []byte{0x02, 0xaa, 0xbb, 0xcc}))
+		require.NoError(t, err)
+		assert.Equal(t, packetHandshake, h.typ)
+		assert.Equal(t, []byte{0xcc}, rest)
+		assert.Equal(t, len(h.packet)-2, h.pnOffset)
+	})
+
+	for name, packet := range map[string][]byte{
+		"short":            {0xc0, 0, 0, 0, 1},
+		"short header":     {0x40, 0, 0, 0, 1, 0, 0},
+		"fixed bit":        longPacket(0x80, version1, dcid, scid, []byte{0, 0}),
+		"truncated cid":    longPacket(0xc0, version1, []byte{1}, nil, nil)[:7],
+		"long cid":         longPacket(0xc0, version1, make([]byte, 21), scid, []byte{0, 0}),
+		"truncated token":  longPacket(0xc0, version1, dcid, scid, []byte{0x05, 0}),
+		"truncated length": longPacket(0xc0, version1, dcid, scid, []byte{0x00, 0x10, 0}),
+		"truncated retry":  longPacket(0xf0, version1, dcid, scid, make([]byte, 15)),
+	} {
+		_, _, err := parseLongHeader(packet)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseFrames(t *testing.T) {
+	payload := []byte{framePadding, framePing}
+	// ACK with one additional range and ECN counts.
+	payload = append(payload, frameACKECN, 0x10, 0x00, 0x01, 0x00, 0x01, 0x02, 0x00, 0x00, 0x00)
+	payload = append(payload, frameCrypto, 0x40, 0x05, 0x03)
+	payload = append(payload, "abc"...)
+	payload = append(payload, frameACK, 0x01, 0x00, 0x00, 0x00)
+	payload = append(payload, frameConnectionClose, 0x41, 0x28, 0x06, 0x04)
+	payload = append(payload, "oops"...)
+	payload = append(payload, make([]byte, 8)...)
+
+	frames, closeFrame, err := parseFrames(payload)
+	require.NoError(t, err)
+	assert.Equal(t, []cryptoFrame{{offset: 5, data: []byte("abc")}}, frames)
+	assert.Equal(t, &connectionClose{errorCode: 0x128, frameType: 6, reason: "oops"}, closeFrame)
+
+	_, _, err = parseFrames([]byte{0x08, 0x00})
+	assert.Error(t, err)
+	_, _, err = parseFrames([]byte{frameCrypto, 0x00, 0x05, 'a'})
+	assert.ErrorIs(t, err, errTruncated)
+	_, _, err = parseFrames([]byte{frameACK, 0x00, 0x00, 0x05})
+	assert.ErrorIs(t, err, errTruncated)
+}
+
+func TestVersionName(t *testing.T) {
+	assert.Equal(t, "1", versionName(version1))
+	assert.Equal(t, "2", versionName(version2))
+	assert.Equal(t, "draft-29", versionName(versionDraft29))
+	assert.Equal(t, "0x1a2a3a4a", versionName(0x1a2a3a4a))
+}
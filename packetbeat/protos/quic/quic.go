@@ -0,0 +1,506 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/libbeat/ecs"
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tls"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+var (
+	metricPackets           = monitoring.NewUint(nil, "quic.packets")
+	metricParseFailures     = monitoring.NewUint(nil, "quic.parse_failures")
+	metricDecryptFailures   = monitoring.NewUint(nil, "quic.decrypt_failures")
+	metricUnansweredClients = monitoring.NewUint(nil, "quic.unanswered_connections")
+)
+
+const (
+	clientToServer = 0
+	serverToClient = 1
+)
+
+// maxCryptoData limits the handshake data buffered per direction while
+// waiting for a complete ClientHello or ServerHello.
+const maxCryptoData = 64 * 1024
+
+type quicPlugin struct {
+	ports       []int
+	timeout     time.Duration
+	connections *common.Cache
+
+	results protos.Reporter
+	watcher *procs.ProcessesWatcher
+	logger  *logp.Logger
+}
+
+// connection holds the state of a QUIC connection until its handshake has
+// been observed.
+type connection struct {
+	tuple   common.IPPortTuple // oriented from client to server
+	cmdline *common.ProcessTuple
+	start   time.Time
+	end     time.Time
+
+	version      uint32
+	originalDCID []byte
+	// Destination Connection ID the Initial keys are derived from. This is
+	// the one chosen by the client, or the one provided in a Retry packet.
+	initialDCID []byte
+	clientCID   []byte
+	serverCID   []byte
+	retry       bool
+	versions    []uint32 // offered by the server in a Version Negotiation packet
+
+	keys       [2]*initialKeys
+	largestPN  [2]int64
+	crypto     [2]cryptoStream
+	hello      [2]*tls.Hello
+	serverSeen bool
+
+	closeFrame *connectionClose
+	closedBy   int
+
+	published bool
+}
+
+// cryptoStream reassembles the handshake data sent in CRYPTO frames, which
+// can be split across packets and arrive out of order.
+type cryptoStream struct {
+	frames []cryptoFrame
+	size   int
+}
+
+func init() {
+	protos.Register("quic", New)
+}
+
+// New constructs a new QUIC protocol plugin.
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &quicPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config, logger); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *quicPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *quicConfig, logger *logp.Logger) error {
+	p.ports = config.Ports
+	p.timeout = config.TransactionTimeout
+	p.results = results
+	p.watcher = watcher
+	p.logger = logger.Named("quic")
+
+	p.connections = common.NewCacheWithRemovalListener(
+		p.timeout,
+		protos.DefaultTransactionHashSize,
+		func(k common.Key, v common.Value) {
+			conn, ok := v.(*connection)
+			if !ok {
+				p.logger.Error("Expired value is not a *connection.")
+				return
+			}
+			p.expireConnection(conn)
+		})
+	p.connections.StartJanitor(p.timeout)
+	return nil
+}
+
+func (p *quicPlugin) GetPorts() []int {
+	return p.ports
+}
+
+func (p *quicPlugin) Close() {
+	p.connections.StopJanitor()
+}
+
+// Flush publishes all connections whose handshake is incomplete.
+func (p *quicPlugin) Flush() {
+	p.connections.Flush()
+}
+
+func (p *quicPlugin) ParseUDP(pkt *protos.Packet) {
+	metricPackets.Inc()
+
+	// Short header packets are protected with keys that can't be derived
+	// from the traffic.
+	if len(pkt.Payload) == 0 || !isLongHeader(pkt.Payload[0]) {
+		return
+	}
+
+	var dir int
+	var key common.HashableIPPortTuple
+	switch {
+	case slices.Contains(p.ports, int(pkt.Tuple.DstPort)):
+		dir, key = clientToServer, pkt.Tuple.Hashable()
+	case slices.Contains(p.ports, int(pkt.Tuple.SrcPort)):
+		dir, key = serverToClient, pkt.Tuple.RevHashable()
+	default:
+		return
+	}
+
+	var conn *connection
+	if v := p.connections.Get(key); v != nil {
+		conn, _ = v.(*connection)
+	}
+	if conn != nil && conn.published {
+		return
+	}
+
+	// A datagram can hold several coalesced packets.
+	data := pkt.Payload
+	for len(data) > 0 && isLongHeader(data[0]) {
+		h, rest, err := parseLongHeader(data)
+		if err != nil {
+			metricParseFailures.Inc()
+			p.logger.Debugf("Failed to parse QUIC packet from %s: %v", &pkt.Tuple, err)
+			break
+		}
+		data = rest
+
+		if conn == nil {
+			// Connections are only tracked from the first Initial packet
+			// sent by the client.
+			if dir != clientToServer || h.typ != packetInitial || !isSupportedVersion(h.version) {
+				return
+			}
+			if conn, err = p.newConnection(pkt, h); err != nil {
+				p.logger.Debugf("Failed to derive QUIC initial keys for %s: %v", &pkt.Tuple, err)
+				return
+			}
+			p.connections.Put(key, conn)
+		}
+		p.handlePacket(conn, dir, h)
+	}
+	if conn == nil {
+		return
+	}
+
+	conn.end = pkt.Ts
+	if conn.hello[serverToClient] != nil || conn.versions != nil || conn.closeFrame != nil {
+		p.publish(conn)
+	}
+}
+
+func (p *quicPlugin) newConnection(pkt *protos.Packet, h *longHeader) (*connection, error) {
+	conn := &connection{
+		tuple:        pkt.Tuple,
+		start:        pkt.Ts,
+		version:      h.version,
+		originalDCID: slices.Clone(h.dcid),
+		clientCID:    slices.Clone(h.scid),
+		largestPN:    [2]int64{-1, -1},
+	}
+	conn.initialDCID = conn.originalDCID
+	if err := conn.deriveKeys(); err != nil {
+		return nil, err
+	}
+	conn.cmdline = p.watcher.FindProcessesTupleUDP(&conn.tuple)
+	return conn, nil
+}
+
+func (conn *connection) deriveKeys() error {
+	client, server, err := newInitialKeys(conn.version, conn.initialDCID)
+	if err != nil {
+		return err
+	}
+	conn.keys = [2]*initialKeys{client, server}
+	return nil
+}
+
+func (p *quicPlugin) handlePacket(conn *connection, dir int, h *longHeader) {
+	if dir == serverToClient {
+		conn.serverSeen = true
+	}
+
+	switch h.typ {
+	case packetVersionNegotiation:
+		if dir == serverToClient && bytes.Equal(h.dcid, conn.clientCID) {
+			conn.versions = append(make([]uint32, 0, len(h.versions)), h.versions...)
+		}
+		return
+	case packetRetry:
+		if dir != serverToClient || conn.retry || conn.hello[serverToClient] != nil {
+			return
+		}
+		// The client restarts the handshake using the connection ID chosen
+		// by the server, which is also used to derive new Initial keys.
+		conn.retry = true
+		conn.initialDCID = slices.Clone(h.scid)
+		conn.serverCID = conn.initialDCID
+		if err := conn.deriveKeys(); err != nil {
+			p.logger.Debugf("Failed to derive QUIC initial keys after retry: %v", err)
+		}
+		conn.crypto[serverToClient] = cryptoStream{}
+		conn.largestPN[serverToClient] = -1
+		return
+	case packetInitial:
+	default:
+		return
+	}
+
+	if h.version != conn.version && isSupportedVersion(h.version) {
+		// Compatible version negotiation (RFC 9368) switches to the version
+		// picked by the server, keeping the original connection ID.
+		conn.version = h.version
+		if err := conn.deriveKeys(); err != nil {
+			p.logger.Debugf("Failed to derive QUIC initial keys for version %s: %v", versionName(h.version), err)
+			return
+		}
+	}
+
+	payload, pn, err := conn.keys[dir].open(h, conn.largestPN[dir])
+	if err != nil {
+		metricDecryptFailures.Inc()
+		p.logger.Debugf("Failed to decrypt QUIC %v packet from %s: %v", h.typ, &conn.tuple, err)
+		return
+	}
+	conn.largestPN[dir] = max(conn.largestPN[dir], pn)
+	if dir == serverToClient && conn.serverCID == nil {
+		conn.serverCID = slices.Clone(h.scid)
+	}
+
+	frames, closeFrame, err := parseFrames(payload)
+	if err != nil {
+		metricParseFailures.Inc()
+		p.logger.Debugf("Failed to parse QUIC frames from %s: %v", &conn.tuple, err)
+		return
+	}
+	if closeFrame != nil {
+		conn.closeFrame, conn.closedBy = closeFrame, dir
+	}
+	if conn.hello[dir] != nil {
+		return
+	}
+	for _, f := range frames {
+		conn.crypto[dir].add(f)
+	}
+	if msg := conn.crypto[dir].handshakeMessage(); msg != nil {
+		hello, err := tls.ParseHello(msg, p.logger)
+		if err != nil {
+			metricParseFailures.Inc()
+			p.logger.Debugf("Failed to parse TLS hello in QUIC connection %s: %v", &conn.tuple, err)
+		}
+		conn.hello[dir] = hello
+		conn.crypto[dir] = cryptoStream{}
+	}
+}
+
+// add stores the data of a CRYPTO frame. Frames starting past the buffering
+// limit are dropped.
+func (s *cryptoStream) add(f cryptoFrame) {
+	end := f.offset + uint64(len(f.data))
+	if end > maxCryptoData || s.size+len(f.data) > maxCryptoData {
+		return
+	}
+	s.frames = append(s.frames, cryptoFrame{offset: f.offset, data: slices.Clone(f.data)})
+	s.size += len(f.data)
+}
+
+// handshakeMessage returns the first handshake message of the stream, once
+// all its fragments have been received.
+func (s *cryptoStream) handshakeMessage() []byte {
+	slices.SortFunc(s.frames, func(a, b cryptoFrame) int {
+		return cmp.Compare(a.offset, b.offset)
+	})
+	var buf []byte
+	for _, f := range s.frames {
+		if f.offset > uint64(len(buf)) {
+			break
+		}
+		if end := f.offset + uint64(len(f.data)); end > uint64(len(buf)) {
+			buf = append(buf, f.data[uint64(len(buf))-f.offset:]...)
+		}
+	}
+	if len(buf) < 4 {
+		return nil
+	}
+	length := int(binary.BigEndian.Uint32(buf[:4]) & 0xffffff)
+	if len(buf) < 4+length {
+		return nil
+	}
+	return buf[:4+length]
+}
+
+func (p *quicPlugin) expireConnection(conn *connection) {
+	if conn.published {
+		return
+	}
+	if !conn.serverSeen {
+		metricUnansweredClients.Inc()
+	}
+	p.publish(conn)
+}
+
+func (p *quicPlugin) publish(conn *connection) {
+	conn.published = true
+	p.results(p.createEvent(conn))
+}
+
+func (p *quicPlugin) createEvent(conn *connection) beat.Event {
+	evt, pbf := pb.NewBeatEvent(conn.start)
+
+	src, dst := common.MakeEndpointPair(conn.tuple.BaseTuple, conn.cmdline)
+	pbf.SetSource(&src)
+	pbf.SetDestination(&dst)
+	client, server := ecs.Client(*pbf.Source), ecs.Server(*pbf.Destination)
+	pbf.Client = &client
+	pbf.Server = &server
+
+	pbf.Event.Start = conn.start
+	pbf.Event.End = conn.end
+	pbf.Event.Dataset = "quic"
+	pbf.Network.Transport = "udp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	status := common.OK_STATUS
+	if !conn.serverSeen || conn.versions != nil ||
+		(conn.closeFrame != nil && conn.closeFrame.errorCode != 0) {
+		status = common.ERROR_STATUS
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["status"] = status
+
+	quic := mapstr.M{
+		"version":                            versionName(conn.version),
+		"original_destination_connection_id": hex.EncodeToString(conn.originalDCID),
+	}
+	fields["quic"] = quic
+	if conn.clientCID != nil {
+		quic.Put("client.connection_id", hex.EncodeToString(conn.clientCID))
+	}
+	if conn.serverCID != nil {
+		quic.Put("server.connection_id", hex.EncodeToString(conn.serverCID))
+	}
+	if conn.retry {
+		quic["retry"] = true
+	}
+	if conn.versions != nil {
+		versions := make([]string, 0, len(conn.versions))
+		for _, v := range conn.versions {
+			versions = append(versions, versionName(v))
+		}
+		quic["supported_versions"] = versions
+	}
+	if cc := conn.closeFrame; cc != nil {
+		closeFields := mapstr.M{
+			"error_code": cc.errorCode,
+			"error":      transportErrorName(cc.errorCode),
+			"sent_by":    "client",
+		}
+		if conn.closedBy == serverToClient {
+			closeFields["sent_by"] = "server"
+		}
+		if cc.reason != "" {
+			closeFields["reason"] = cc.reason
+		}
+		quic["connection_close"] = closeFields
+	}
+
+	var tlsFields ecs.Tls
+	if hello := conn.hello[clientToServer]; hello != nil {
+		if name := hello.ServerName(); name != "" {
+			pbf.Destination.Domain = name
+			tlsFields.ClientServerName = name
+		}
+		if alpn := hello.ALPN(); len(alpn) > 0 {
+			quic.Put("client.alpn", alpn)
+		}
+		tlsFields.ClientJa3 = hello.JA3()
+	}
+	if hello := conn.hello[serverToClient]; hello != nil {
+		tlsFields.Cipher = hello.Cipher()
+		tlsFields.ServerJa3s = hello.JA3()
+		version := hello.Version()
+		tlsFields.VersionProtocol, tlsFields.Version = version.Protocol, version.Version
+	}
+	pb.MarshalStruct(fields, "tls", tlsFields)
+	if hello := conn.hello[clientToServer]; hello != nil {
+		if ciphers := hello.SupportedCiphers(); len(ciphers) > 0 {
+			fields.Put("tls.client.supported_ciphers", ciphers)
+		}
+		fields.Put("tls.client.ja4", hello.JA4(tls.JA4TransportQUIC))
+	}
+	if hello := conn.hello[serverToClient]; hello != nil {
+		fields.Put("tls.server.ja4s", hello.JA4(tls.JA4TransportQUIC))
+	}
+
+	return evt
+}
+
+// transportErrorName returns the name of a QUIC transport error code
+// (RFC 9000, section 20.1).
+func transportErrorName(code uint64) string {
+	names := []string{
+		"NO_ERROR",
+		"INTERNAL_ERROR",
+		"CONNECTION_REFUSED",
+		"FLOW_CONTROL_ERROR",
+		"STREAM_LIMIT_ERROR",
+		"STREAM_STATE_ERROR",
+		"FINAL_SIZE_ERROR",
+		"FRAME_ENCODING_ERROR",
+		"TRANSPORT_PARAMETER_ERROR",
+		"CONNECTION_ID_LIMIT_ERROR",
+		"PROTOCOL_VIOLATION",
+		"INVALID_TOKEN",
+		"APPLICATION_ERROR",
+		"CRYPTO_BUFFER_EXCEEDED",
+		"KEY_UPDATE_ERROR",
+		"AEAD_LIMIT_REACHED",
+		"NO_VIABLE_PATH",
+	}
+	switch {
+	case code < uint64(len(names)):
+		return names[code]
+	case code >= 0x100 && code <= 0x1ff:
+		return fmt.Sprintf("CRYPTO_ERROR (%d)", code-0x100)
+	}
+	return fmt.Sprintf("unknown (0x%x)", code)
+}
@@ -0,0 +1,362 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package quic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+var (
+	testDCID       = []byte{0x83, 0x94, 0xc8, 0xf0, 0x3e, 0x51, 0x57, 0x08}
+	testClientCID  = []byte{0xc1, 0x01}
+	testServerCID  = []byte{0x5e, 0x00, 0x01, 0x02}
+	testRetryCID   = []byte{0x7e, 0x7e, 0x7e, 0x7e, 0x7e}
+	testClientAddr = net.ParseIP("192.168.0.1")
+	testServerAddr = net.ParseIP("192.168.0.2")
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func testPlugin(t *testing.T) (*eventStore, *quicPlugin) {
+	t.Helper()
+	store := &eventStore{}
+	config := defaultConfig
+	config.Ports = []int{443}
+	p := &quicPlugin{}
+	require.NoError(t, p.init(store.publish, &procs.ProcessesWatcher{}, &config, logptest.NewTestingLogger(t, "")))
+	t.Cleanup(p.Close)
+	return store, p
+}
+
+func clientPacket(payload []byte) *protos.Packet {
+	return &protos.Packet{
+		Ts:      time.Now(),
+		Tuple:   common.NewIPPortTuple(4, testClientAddr, 51000, testServerAddr, 443),
+		Payload: payload,
+	}
+}
+
+func serverPacket(payload []byte) *protos.Packet {
+	return &protos.Packet{
+		Ts:      time.Now(),
+		Tuple:   common.NewIPPortTuple(4, testServerAddr, 443, testClientAddr, 51000),
+		Payload: payload,
+	}
+}
+
+// tlsHellos returns the ClientHello and ServerHello messages of a QUIC
+// handshake performed by crypto/tls.
+func tlsHellos(t *testing.T) (clientHello, serverHello []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.org"},
+		DNSNames:     []string{"example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	client := cryptotls.QUICClient(&cryptotls.QUICConfig{TLSConfig: &cryptotls.Config{
+		ServerName:         "example.org",
+		NextProtos:         []string{"h3"},
+		InsecureSkipVerify: true, //nolint:gosec // Self-signed test certificate.
+		MinVersion:         cryptotls.VersionTLS13,
+	}})
+	defer client.Close()
+	client.SetTransportParameters(nil)
+	require.NoError(t, client.Start(context.Background()))
+	clientHello = initialData(client)
+	require.NotEmpty(t, clientHello)
+
+	server := cryptotls.QUICServer(&cryptotls.QUICConfig{TLSConfig: &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:   []string{"h3"},
+		MinVersion:   cryptotls.VersionTLS13,
+	}})
+	defer server.Close()
+	server.SetTransportParameters(nil)
+	require.NoError(t, server.Start(context.Background()))
+	require.NoError(t, server.HandleData(cryptotls.QUICEncryptionLevelInitial, clientHello))
+	serverHello = initialData(server)
+	require.NotEmpty(t, serverHello)
+	return clientHello, serverHello
+}
+
+// initialData returns the handshake data sent at the Initial encryption
+// level.
+func initialData(conn *cryptotls.QUICConn) []byte {
+	var data []byte
+	for {
+		e := conn.NextEvent()
+		switch e.Kind {
+		case cryptotls.QUICNoEvent:
+			return data
+		case cryptotls.QUICWriteData:
+			if e.Level == cryptotls.QUICEncryptionLevelInitial {
+				data = append(data, e.Data...)
+			}
+		}
+	}
+}
+
+func cryptoFrameData(offset int, data []byte) []byte {
+	frame := []byte{frameCrypto}
+	frame = appendVarint(frame, uint64(offset))
+	frame = appendVarint(frame, uint64(len(data)))
+	return append(frame, data...)
+}
+
+// padded pads the payload of a client Initial packet to fill a 1200 bytes
+// datagram.
+func padded(payload []byte) []byte {
+	return append(payload, make([]byte, max(0, 1150-len(payload)))...)
+}
+
+func initialKeysFor(t *testing.T, version uint32, dcid []byte) (client, server *initialKeys) {
+	t.Helper()
+	client, server, err := newInitialKeys(version, dcid)
+	require.NoError(t, err)
+	return client, server
+}
+
+func expectEvent(t *testing.T, store *eventStore) beat.Event {
+	t.Helper()
+	require.Len(t, store.events, 1)
+	return store.events[0]
+}
+
+func fieldValue(t *testing.T, evt beat.Event, key string) interface{} {
+	t.Helper()
+	v, err := evt.Fields.GetValue(key)
+	require.NoError(t, err, key)
+	return v
+}
+
+func assertMissing(t *testing.T, evt beat.Event, key string) {
+	t.Helper()
+	_, err := evt.Fields.GetValue(key)
+	assert.Error(t, err, key)
+}
+
+func TestHandshake(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, serverHello := tlsHellos(t)
+	clientKeys, serverKeys := initialKeysFor(t, version1, testDCID)
+
+	// The ClientHello is split across two packets, which are received out
+	// of order.
+	half := len(clientHello) / 2
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testDCID, testClientCID, 1,
+		padded(cryptoFrameData(half, clientHello[half:])))))
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello[:half])))))
+	assert.Empty(t, store.events)
+
+	// The server coalesces its Initial and Handshake packets.
+	payload := append([]byte{frameACK, 0x01, 0x00, 0x00, 0x01}, cryptoFrameData(0, serverHello)...)
+	datagram := sealInitial(serverKeys, version1, testClientCID, testServerCID, 0, payload)
+	datagram = append(datagram, longPacket(0xe0, version1, testClientCID, testServerCID, []byte{0x02, 0xaa, 0xbb})...)
+	p.ParseUDP(serverPacket(datagram))
+
+	// Later packets of the connection are ignored.
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testServerCID, testClientCID, 2, padded(nil))))
+	p.Flush()
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "quic", fieldValue(t, evt, "type"))
+	assert.Equal(t, "OK", fieldValue(t, evt, "status"))
+	assert.Equal(t, "udp", fieldValue(t, evt, "network.transport"))
+	assert.Equal(t, "quic", fieldValue(t, evt, "network.protocol"))
+	assert.Equal(t, "192.168.0.1", fieldValue(t, evt, "client.ip"))
+	assert.Equal(t, "192.168.0.2", fieldValue(t, evt, "server.ip"))
+	assert.Equal(t, "example.org", fieldValue(t, evt, "destination.domain"))
+	assert.Equal(t, "1", fieldValue(t, evt, "quic.version"))
+	assert.Equal(t, "8394c8f03e515708", fieldValue(t, evt, "quic.original_destination_connection_id"))
+	assert.Equal(t, "c101", fieldValue(t, evt, "quic.client.connection_id"))
+	assert.Equal(t, "5e000102", fieldValue(t, evt, "quic.server.connection_id"))
+	assert.Equal(t, []string{"h3"}, fieldValue(t, evt, "quic.client.alpn"))
+	assert.Regexp(t, regexp.MustCompile(`^q13d\d{4}h3_[0-9a-f]{12}_[0-9a-f]{12}$`), fieldValue(t, evt, "tls.client.ja4"))
+	assert.Regexp(t, regexp.MustCompile(`^q13\d{2}00_130[1-3]_[0-9a-f]{12}$`), fieldValue(t, evt, "tls.server.ja4s"))
+	assertMissing(t, evt, "quic.retry")
+	assertMissing(t, evt, "quic.connection_close")
+
+	assert.Equal(t, "example.org", fieldValue(t, evt, "tls.client.server_name"))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), fieldValue(t, evt, "tls.client.ja3"))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), fieldValue(t, evt, "tls.server.ja3s"))
+	assert.Contains(t, fieldValue(t, evt, "tls.client.supported_ciphers"), "TLS_AES_128_GCM_SHA256")
+	assert.Regexp(t, regexp.MustCompile(`^TLS_`), fieldValue(t, evt, "tls.cipher"))
+	assert.Equal(t, "1.3", fieldValue(t, evt, "tls.version"))
+	assert.Equal(t, "tls", fieldValue(t, evt, "tls.version_protocol"))
+}
+
+func TestVersion2(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, serverHello := tlsHellos(t)
+	clientKeys, serverKeys := initialKeysFor(t, version2, testDCID)
+
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version2, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello)))))
+	p.ParseUDP(serverPacket(sealInitial(serverKeys, version2, testClientCID, testServerCID, 0,
+		cryptoFrameData(0, serverHello))))
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "OK", fieldValue(t, evt, "status"))
+	assert.Equal(t, "2", fieldValue(t, evt, "quic.version"))
+	assert.Equal(t, "example.org", fieldValue(t, evt, "tls.client.server_name"))
+}
+
+func TestRetry(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, serverHello := tlsHellos(t)
+	clientKeys, _ := initialKeysFor(t, version1, testDCID)
+
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello)))))
+
+	retry := append([]byte("retry token"), make([]byte, 16)...)
+	p.ParseUDP(serverPacket(longPacket(0xf0, version1, testClientCID, testRetryCID, retry)))
+
+	// The handshake restarts with keys derived from the connection ID
+	// chosen by the server.
+	clientKeys, serverKeys := initialKeysFor(t, version1, testRetryCID)
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testRetryCID, testClientCID, 1,
+		padded(cryptoFrameData(0, clientHello)))))
+	assert.Empty(t, store.events)
+	p.ParseUDP(serverPacket(sealInitial(serverKeys, version1, testClientCID, testRetryCID, 0,
+		cryptoFrameData(0, serverHello))))
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "OK", fieldValue(t, evt, "status"))
+	assert.Equal(t, true, fieldValue(t, evt, "quic.retry"))
+	assert.Equal(t, "8394c8f03e515708", fieldValue(t, evt, "quic.original_destination_connection_id"))
+	assert.Equal(t, "7e7e7e7e7e", fieldValue(t, evt, "quic.server.connection_id"))
+	assert.Equal(t, "1.3", fieldValue(t, evt, "tls.version"))
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, _ := tlsHellos(t)
+	clientKeys, _ := initialKeysFor(t, versionDraft29, testDCID)
+
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, versionDraft29, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello)))))
+	p.ParseUDP(serverPacket(longPacket(0x80, 0, testClientCID, testDCID, unhex(t, "000000016b3343cf"))))
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "Error", fieldValue(t, evt, "status"))
+	assert.Equal(t, "draft-29", fieldValue(t, evt, "quic.version"))
+	assert.Equal(t, []string{"1", "2"}, fieldValue(t, evt, "quic.supported_versions"))
+	assert.Equal(t, "example.org", fieldValue(t, evt, "tls.client.server_name"))
+	assertMissing(t, evt, "quic.server.connection_id")
+}
+
+func TestConnectionClose(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, _ := tlsHellos(t)
+	clientKeys, serverKeys := initialKeysFor(t, version1, testDCID)
+
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello)))))
+	// CRYPTO_ERROR carrying a handshake_failure alert.
+	closeFrame := []byte{frameConnectionClose, 0x41, 0x28, frameCrypto, 0x04}
+	closeFrame = append(closeFrame, "nope"...)
+	p.ParseUDP(serverPacket(sealInitial(serverKeys, version1, testClientCID, testServerCID, 0, closeFrame)))
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "Error", fieldValue(t, evt, "status"))
+	assert.Equal(t, uint64(0x128), fieldValue(t, evt, "quic.connection_close.error_code"))
+	assert.Equal(t, "CRYPTO_ERROR (40)", fieldValue(t, evt, "quic.connection_close.error"))
+	assert.Equal(t, "nope", fieldValue(t, evt, "quic.connection_close.reason"))
+	assert.Equal(t, "server", fieldValue(t, evt, "quic.connection_close.sent_by"))
+	assert.Equal(t, "5e000102", fieldValue(t, evt, "quic.server.connection_id"))
+}
+
+func TestUnansweredConnection(t *testing.T) {
+	store, p := testPlugin(t)
+	clientHello, _ := tlsHellos(t)
+	clientKeys, _ := initialKeysFor(t, version1, testDCID)
+
+	p.ParseUDP(clientPacket(sealInitial(clientKeys, version1, testDCID, testClientCID, 0,
+		padded(cryptoFrameData(0, clientHello)))))
+	assert.Empty(t, store.events)
+	p.Flush()
+
+	evt := expectEvent(t, store)
+	assert.Equal(t, "Error", fieldValue(t, evt, "status"))
+	assert.Equal(t, "example.org", fieldValue(t, evt, "tls.client.server_name"))
+	assertMissing(t, evt, "tls.version")
+}
+
+func TestIgnoredPackets(t *testing.T) {
+	store, p := testPlugin(t)
+	clientKeys, serverKeys := initialKeysFor(t, version1, testDCID)
+
+	for name, pkt := range map[string]*protos.Packet{
+		"empty":           clientPacket(nil),
+		"short header":    clientPacket([]byte{0x40, 0x01, 0x02, 0x03}),
+		"not quic":        clientPacket([]byte("\x80\x00GET / HTTP/1.1\r\n\r\n")),
+		"unknown version": clientPacket(longPacket(0xc0, 0x0a0a0a0a, testDCID, testClientCID, []byte{0, 0})),
+		"server first": serverPacket(sealInitial(serverKeys, version1, testClientCID, testServerCID, 0,
+			[]byte{framePing})),
+		"unknown port": {
+			Tuple: common.NewIPPortTuple(4, testClientAddr, 51000, testServerAddr, 8443),
+			Payload: sealInitial(clientKeys, version1, testDCID, testClientCID, 0,
+				padded([]byte{framePing})),
+		},
+	} {
+		p.ParseUDP(pkt)
+		assert.Zero(t, p.connections.Size(), name)
+	}
+	p.Flush()
+	assert.Empty(t, store.events)
+}
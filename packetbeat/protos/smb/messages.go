@@ -0,0 +1,268 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+)
+
+const headerSize = 64
+
+// Protocol identifiers found at the start of an SMB message.
+var (
+	protocolSMB2        = []byte{0xfe, 'S', 'M', 'B'}
+	protocolTransform   = []byte{0xfd, 'S', 'M', 'B'}
+	protocolCompression = []byte{0xfc, 'S', 'M', 'B'}
+	protocolSMB1        = []byte{0xff, 'S', 'M', 'B'}
+)
+
+// Header flags.
+const (
+	flagServerToRedir = 0x00000001
+	flagAsyncCommand  = 0x00000002
+	flagRelated       = 0x00000004
+)
+
+// asyncMessageID is the message ID of the oplock and lease break
+// notifications sent by the server.
+const asyncMessageID = 0xffffffffffffffff
+
+var errTruncated = errors.New("truncated SMB2 message")
+
+// fileID is the handle of an open file, made of its persistent and
+// volatile parts.
+type fileID [16]byte
+
+// relatedFileID is used by the requests of a compound chain to refer to
+// the file opened by a previous request of the chain.
+var relatedFileID = fileID{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+}
+
+// header is the SMB2 packet header (MS-SMB2, section 2.2.1).
+type header struct {
+	command     uint16
+	status      uint32
+	flags       uint32
+	nextCommand uint32
+	messageID   uint64
+	treeID      uint32
+	sessionID   uint64
+}
+
+func (h *header) isResponse() bool {
+	return h.flags&flagServerToRedir != 0
+}
+
+// message is a decoded SMB2 request or response. Only the fields of the
+// commands reported by the analyzer are decoded.
+type message struct {
+	header
+	ts   time.Time
+	size int
+
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+	direction    uint8
+
+	// NEGOTIATE
+	dialects []string
+	dialect  string
+
+	// SESSION_SETUP
+	auth         authentication
+	sessionFlags uint16
+
+	// TREE_CONNECT
+	path      string
+	shareType string
+
+	// CREATE, CLOSE, READ and WRITE
+	fileID      fileID
+	name        string
+	disposition string
+	action      string
+	directory   bool
+	fileSize    uint64
+
+	// READ and WRITE
+	offset uint64
+	length uint32
+}
+
+func decodeHeader(data []byte) (header, error) {
+	var h header
+	if len(data) < headerSize {
+		return h, errTruncated
+	}
+	if !bytes.Equal(data[:4], protocolSMB2) || binary.LittleEndian.Uint16(data[4:]) != headerSize {
+		return h, errors.New("invalid SMB2 header")
+	}
+	h.status = binary.LittleEndian.Uint32(data[8:])
+	h.command = binary.LittleEndian.Uint16(data[12:])
+	h.flags = binary.LittleEndian.Uint32(data[16:])
+	h.nextCommand = binary.LittleEndian.Uint32(data[20:])
+	h.messageID = binary.LittleEndian.Uint64(data[24:])
+	if h.flags&flagAsyncCommand == 0 {
+		h.treeID = binary.LittleEndian.Uint32(data[36:])
+	}
+	h.sessionID = binary.LittleEndian.Uint64(data[40:])
+	return h, nil
+}
+
+// decodeBody decodes the command specific part of a message. data holds
+// the message starting with its header, the offsets found in the body are
+// relative to it. Only the fixed part of large READ responses and WRITE
+// requests is available.
+func decodeBody(data []byte, m *message) error {
+	body := data[headerSize:]
+	if m.isResponse() && isErrorStatus(m.status) && m.status != statusMoreProcessingRequired {
+		// ERROR response.
+		return nil
+	}
+
+	var err error
+	switch m.command {
+	case cmdNegotiate:
+		if m.isResponse() {
+			if len(body) < 6 {
+				return errTruncated
+			}
+			m.dialect = dialectName(binary.LittleEndian.Uint16(body[4:]))
+			return nil
+		}
+		if len(body) < 36 {
+			return errTruncated
+		}
+		count := int(binary.LittleEndian.Uint16(body[2:]))
+		if len(body) < 36+2*count {
+			return errTruncated
+		}
+		for i := 0; i < count; i++ {
+			m.dialects = append(m.dialects, dialectName(binary.LittleEndian.Uint16(body[36+2*i:])))
+		}
+	case cmdSessionSetup:
+		if m.isResponse() {
+			if len(body) < 4 {
+				return errTruncated
+			}
+			m.sessionFlags = binary.LittleEndian.Uint16(body[2:])
+			return nil
+		}
+		if len(body) < 16 {
+			return errTruncated
+		}
+		blob, err := buffer(data, body[12:], body[14:])
+		if err != nil {
+			return err
+		}
+		m.auth = decodeSecurityBlob(blob)
+	case cmdTreeConnect:
+		if m.isResponse() {
+			if len(body) < 3 {
+				return errTruncated
+			}
+			m.shareType = shareTypeNames[body[2]]
+			return nil
+		}
+		if len(body) < 8 {
+			return errTruncated
+		}
+		m.path, err = stringBuffer(data, body[4:], body[6:])
+		return err
+	case cmdCreate:
+		if m.isResponse() {
+			if len(body) < 80 {
+				return errTruncated
+			}
+			m.action = enumName(createActionNames, binary.LittleEndian.Uint32(body[4:]))
+			m.fileSize = binary.LittleEndian.Uint64(body[48:])
+			m.directory = binary.LittleEndian.Uint32(body[56:])&0x10 != 0
+			copy(m.fileID[:], body[64:80])
+			return nil
+		}
+		if len(body) < 48 {
+			return errTruncated
+		}
+		m.disposition = enumName(createDispositionNames, binary.LittleEndian.Uint32(body[36:]))
+		m.name, err = stringBuffer(data, body[44:], body[46:])
+		return err
+	case cmdClose:
+		if !m.isResponse() {
+			if len(body) < 24 {
+				return errTruncated
+			}
+			copy(m.fileID[:], body[8:24])
+		}
+	case cmdRead, cmdWrite:
+		if m.isResponse() {
+			// DataLength of a READ response, Count of a WRITE response.
+			if len(body) < 8 {
+				return errTruncated
+			}
+			m.length = binary.LittleEndian.Uint32(body[4:])
+			return nil
+		}
+		if len(body) < 32 {
+			return errTruncated
+		}
+		m.length = binary.LittleEndian.Uint32(body[4:])
+		m.offset = binary.LittleEndian.Uint64(body[8:])
+		copy(m.fileID[:], body[16:32])
+	}
+	return nil
+}
+
+// buffer returns the variable length buffer of a message, given its 16 bits
+// offset and length fields.
+func buffer(data, offsetField, lengthField []byte) ([]byte, error) {
+	offset := int(binary.LittleEndian.Uint16(offsetField))
+	length := int(binary.LittleEndian.Uint16(lengthField))
+	if length == 0 {
+		return nil, nil
+	}
+	if offset < headerSize || offset+length > len(data) {
+		return nil, fmt.Errorf("buffer at offset %d with length %d out of bounds", offset, length)
+	}
+	return data[offset : offset+length], nil
+}
+
+func stringBuffer(data, offsetField, lengthField []byte) (string, error) {
+	b, err := buffer(data, offsetField, lengthField)
+	if err != nil {
+		return "", err
+	}
+	return decodeUTF16(b), nil
+}
+
+// decodeUTF16 decodes a little-endian UTF-16 string.
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,217 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import "fmt"
+
+// SMB2 commands (MS-SMB2, section 2.2.1).
+const (
+	cmdNegotiate      = 0x00
+	cmdSessionSetup   = 0x01
+	cmdLogoff         = 0x02
+	cmdTreeConnect    = 0x03
+	cmdTreeDisconnect = 0x04
+	cmdCreate         = 0x05
+	cmdClose          = 0x06
+	cmdFlush          = 0x07
+	cmdRead           = 0x08
+	cmdWrite          = 0x09
+	cmdLock           = 0x0a
+	cmdIoctl          = 0x0b
+	cmdCancel         = 0x0c
+	cmdEcho           = 0x0d
+	cmdQueryDirectory = 0x0e
+	cmdChangeNotify   = 0x0f
+	cmdQueryInfo      = 0x10
+	cmdSetInfo        = 0x11
+	cmdOplockBreak    = 0x12
+)
+
+var commandNames = []string{
+	cmdNegotiate:      "NEGOTIATE",
+	cmdSessionSetup:   "SESSION_SETUP",
+	cmdLogoff:         "LOGOFF",
+	cmdTreeConnect:    "TREE_CONNECT",
+	cmdTreeDisconnect: "TREE_DISCONNECT",
+	cmdCreate:         "CREATE",
+	cmdClose:          "CLOSE",
+	cmdFlush:          "FLUSH",
+	cmdRead:           "READ",
+	cmdWrite:          "WRITE",
+	cmdLock:           "LOCK",
+	cmdIoctl:          "IOCTL",
+	cmdCancel:         "CANCEL",
+	cmdEcho:           "ECHO",
+	cmdQueryDirectory: "QUERY_DIRECTORY",
+	cmdChangeNotify:   "CHANGE_NOTIFY",
+	cmdQueryInfo:      "QUERY_INFO",
+	cmdSetInfo:        "SET_INFO",
+	cmdOplockBreak:    "OPLOCK_BREAK",
+}
+
+func commandName(cmd uint16) string {
+	if int(cmd) < len(commandNames) {
+		return commandNames[cmd]
+	}
+	return fmt.Sprintf("unknown (%d)", cmd)
+}
+
+// isTracked returns whether requests for the command are correlated with
+// their response. Other commands, like directory listings and metadata
+// queries, are ignored.
+func isTracked(cmd uint16) bool {
+	switch cmd {
+	case cmdNegotiate, cmdSessionSetup, cmdLogoff, cmdTreeConnect, cmdTreeDisconnect,
+		cmdCreate, cmdClose, cmdRead, cmdWrite:
+		return true
+	}
+	return false
+}
+
+func dialectName(dialect uint16) string {
+	switch dialect {
+	case 0x0202:
+		return "2.0.2"
+	case 0x0210:
+		return "2.1"
+	case 0x02ff:
+		return "2.???"
+	case 0x0300:
+		return "3.0"
+	case 0x0302:
+		return "3.0.2"
+	case 0x0311:
+		return "3.1.1"
+	}
+	return fmt.Sprintf("0x%04x", dialect)
+}
+
+// NT status codes (MS-ERREF, section 2.3.1).
+const (
+	statusSuccess                = 0x00000000
+	statusPending                = 0x00000103
+	statusEndOfFile              = 0xc0000011
+	statusMoreProcessingRequired = 0xc0000016
+)
+
+var ntStatusNames = map[uint32]string{
+	0x00000000: "STATUS_SUCCESS",
+	0x00000103: "STATUS_PENDING",
+	0x0000010b: "STATUS_NOTIFY_CLEANUP",
+	0x0000010c: "STATUS_NOTIFY_ENUM_DIR",
+	0x80000005: "STATUS_BUFFER_OVERFLOW",
+	0x80000006: "STATUS_NO_MORE_FILES",
+	0xc0000001: "STATUS_UNSUCCESSFUL",
+	0xc0000002: "STATUS_NOT_IMPLEMENTED",
+	0xc0000003: "STATUS_INVALID_INFO_CLASS",
+	0xc0000008: "STATUS_INVALID_HANDLE",
+	0xc000000d: "STATUS_INVALID_PARAMETER",
+	0xc000000f: "STATUS_NO_SUCH_FILE",
+	0xc0000010: "STATUS_INVALID_DEVICE_REQUEST",
+	0xc0000011: "STATUS_END_OF_FILE",
+	0xc0000016: "STATUS_MORE_PROCESSING_REQUIRED",
+	0xc0000022: "STATUS_ACCESS_DENIED",
+	0xc0000023: "STATUS_BUFFER_TOO_SMALL",
+	0xc0000033: "STATUS_OBJECT_NAME_INVALID",
+	0xc0000034: "STATUS_OBJECT_NAME_NOT_FOUND",
+	0xc0000035: "STATUS_OBJECT_NAME_COLLISION",
+	0xc000003a: "STATUS_OBJECT_PATH_NOT_FOUND",
+	0xc0000043: "STATUS_SHARING_VIOLATION",
+	0xc0000054: "STATUS_FILE_LOCK_CONFLICT",
+	0xc0000056: "STATUS_DELETE_PENDING",
+	0xc000005e: "STATUS_NO_LOGON_SERVERS",
+	0xc0000061: "STATUS_PRIVILEGE_NOT_HELD",
+	0xc000006a: "STATUS_WRONG_PASSWORD",
+	0xc000006d: "STATUS_LOGON_FAILURE",
+	0xc000006e: "STATUS_ACCOUNT_RESTRICTION",
+	0xc000006f: "STATUS_INVALID_LOGON_HOURS",
+	0xc0000070: "STATUS_INVALID_WORKSTATION",
+	0xc0000071: "STATUS_PASSWORD_EXPIRED",
+	0xc0000072: "STATUS_ACCOUNT_DISABLED",
+	0xc000007f: "STATUS_DISK_FULL",
+	0xc000009a: "STATUS_INSUFFICIENT_RESOURCES",
+	0xc00000b5: "STATUS_IO_TIMEOUT",
+	0xc00000ba: "STATUS_FILE_IS_A_DIRECTORY",
+	0xc00000bb: "STATUS_NOT_SUPPORTED",
+	0xc00000c9: "STATUS_NETWORK_NAME_DELETED",
+	0xc00000cc: "STATUS_BAD_NETWORK_NAME",
+	0xc0000101: "STATUS_DIRECTORY_NOT_EMPTY",
+	0xc0000103: "STATUS_NOT_A_DIRECTORY",
+	0xc0000120: "STATUS_CANCELLED",
+	0xc0000128: "STATUS_FILE_CLOSED",
+	0xc0000184: "STATUS_INVALID_DEVICE_STATE",
+	0xc0000193: "STATUS_ACCOUNT_EXPIRED",
+	0xc0000203: "STATUS_USER_SESSION_DELETED",
+	0xc0000205: "STATUS_INSUFF_SERVER_RESOURCES",
+	0xc0000224: "STATUS_PASSWORD_MUST_CHANGE",
+	0xc0000225: "STATUS_NOT_FOUND",
+	0xc0000234: "STATUS_ACCOUNT_LOCKED_OUT",
+	0xc0000257: "STATUS_PATH_NOT_COVERED",
+	0xc000035c: "STATUS_NETWORK_SESSION_EXPIRED",
+}
+
+func ntStatusName(status uint32) string {
+	if name, found := ntStatusNames[status]; found {
+		return name
+	}
+	return fmt.Sprintf("0x%08x", status)
+}
+
+// isErrorStatus returns whether an NT status has the error severity.
+func isErrorStatus(status uint32) bool {
+	return status>>30 == 3
+}
+
+var shareTypeNames = map[uint8]string{
+	1: "disk",
+	2: "pipe",
+	3: "print",
+}
+
+var createDispositionNames = []string{
+	"supersede",
+	"open",
+	"create",
+	"open_if",
+	"overwrite",
+	"overwrite_if",
+}
+
+var createActionNames = []string{
+	"superseded",
+	"opened",
+	"created",
+	"overwritten",
+}
+
+func enumName(names []string, v uint32) string {
+	if int(v) < len(names) {
+		return names[v]
+	}
+	return fmt.Sprintf("unknown (%d)", v)
+}
+
+// Session flags of a SESSION_SETUP response.
+var sessionFlagNames = []struct {
+	flag uint16
+	name string
+}{
+	{0x0001, "guest"},
+	{0x0002, "anonymous"},
+	{0x0004, "encrypt_data"},
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// authentication describes the authentication used by a SESSION_SETUP
+// request, as found in its security blob.
+type authentication struct {
+	mechanism string
+	// Only known for NTLM authentication.
+	user        string
+	domain      string
+	workstation string
+}
+
+var (
+	ntlmSignature = []byte("NTLMSSP\x00")
+	// DER encodings of the Kerberos OIDs, 1.2.840.113554.1.2.2 and the
+	// one used by Windows, 1.2.840.48018.1.2.2.
+	kerberosOID   = []byte{0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x12, 0x01, 0x02, 0x02}
+	kerberosMSOID = []byte{0x06, 0x09, 0x2a, 0x86, 0x48, 0x82, 0xf7, 0x12, 0x01, 0x02, 0x02}
+)
+
+const (
+	ntlmAuthenticate     = 3
+	ntlmNegotiateUnicode = 0x00000001
+)
+
+// decodeSecurityBlob finds the authentication mechanism used in the SPNEGO
+// token of a SESSION_SETUP request. The user name is taken from NTLM
+// AUTHENTICATE messages (MS-NLMP, section 2.2.1.3). Clients offering both
+// mechanisms send an NTLM token only when NTLM is used.
+func decodeSecurityBlob(blob []byte) authentication {
+	if i := bytes.Index(blob, ntlmSignature); i >= 0 {
+		auth := authentication{mechanism: "ntlm"}
+		msg := blob[i:]
+		if len(msg) < 64 || binary.LittleEndian.Uint32(msg[8:]) != ntlmAuthenticate {
+			return auth
+		}
+		unicode := binary.LittleEndian.Uint32(msg[60:])&ntlmNegotiateUnicode != 0
+		auth.domain = ntlmString(msg, msg[28:], unicode)
+		auth.user = ntlmString(msg, msg[36:], unicode)
+		auth.workstation = ntlmString(msg, msg[44:], unicode)
+		return auth
+	}
+	if bytes.Contains(blob, kerberosOID) || bytes.Contains(blob, kerberosMSOID) {
+		return authentication{mechanism: "kerberos"}
+	}
+	return authentication{}
+}
+
+// ntlmString returns the string referenced by a length, allocated length
+// and offset field of an NTLM message.
+func ntlmString(msg, field []byte, unicode bool) string {
+	length := int(binary.LittleEndian.Uint16(field))
+	offset := int(binary.LittleEndian.Uint32(field[4:]))
+	if length == 0 || offset+length > len(msg) {
+		return ""
+	}
+	b := msg[offset : offset+length]
+	if unicode {
+		return decodeUTF16(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package smb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSecurityBlob(t *testing.T) {
+	// SPNEGO negTokenResp wrapping an NTLM AUTHENTICATE message.
+	ntlm := ntlmAuthenticateMessage("CORP", "jdoe", "WS042")
+	spnego := join([]byte{0xa1, 0x82, 0x01, 0x00, 0x30, 0x81, 0xfc, 0xa2, 0x81, 0xf9, 0x04, 0x81, 0xf6}, ntlm)
+	assert.Equal(t, authentication{mechanism: "ntlm", user: "jdoe", domain: "CORP", workstation: "WS042"},
+		decodeSecurityBlob(spnego))
+
+	// OEM strings.
+	oem := ntlmAuthenticateMessage("", "", "")
+	oem = join(oem[:36], le16(5), le16(5), le32(uint32(len(oem))), oem[44:60], le32(0), oem[64:], []byte("alice"))
+	assert.Equal(t, authentication{mechanism: "ntlm", user: "alice"}, decodeSecurityBlob(oem))
+
+	// Mechanism list offering Kerberos and NTLM, with a Kerberos token.
+	kerberos := join([]byte{0x60, 0x82, 0x05, 0x00, 0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}, kerberosMSOID, kerberosOID)
+	assert.Equal(t, authentication{mechanism: "kerberos"}, decodeSecurityBlob(kerberos))
+
+	assert.Equal(t, authentication{mechanism: "ntlm"}, decodeSecurityBlob(ntlmNegotiateMessage()))
+	assert.Equal(t, authentication{}, decodeSecurityBlob(nil))
+
+	// Out of bounds fields.
+	truncated := ntlmAuthenticateMessage("CORP", "jdoe", "WS042")[:70]
+	assert.Equal(t, authentication{mechanism: "ntlm"}, decodeSecurityBlob(truncated))
+}
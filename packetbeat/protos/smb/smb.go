@@ -0,0 +1,724 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package smb implements a Packetbeat analyzer for version 2 and 3 of the
+// Server Message Block protocol (MS-SMB2). It reports session setups, tree
+// connects and the files opened, with the number of bytes read and written
+// reported when a file is closed. Requests and responses are correlated by
+// message ID. Encrypted and compressed messages, and SMB1, are ignored.
+package smb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// maxMessageSize is the size of the largest frame decoded in full. Only
+	// the beginning of larger frames is decoded, they are usually READ
+	// responses and WRITE requests and their data isn't needed.
+	maxMessageSize = 1 << 16
+	// prefixSize is the number of bytes buffered to decode the beginning
+	// of a large frame.
+	prefixSize = 256
+
+	maxPendingRequests = 1000
+	// maxOpenFiles is the maximum number of open files tracked per
+	// connection.
+	maxOpenFiles = 4096
+	// maxTrees is the maximum number of sessions and tree connects tracked
+	// per connection.
+	maxTrees = 256
+)
+
+// NetBIOS session message types (RFC 1002, section 4.3.1). Only session
+// messages carry SMB messages.
+const (
+	nbSessionMessage   = 0x00
+	nbSessionRequest   = 0x81
+	nbSessionKeepAlive = 0x85
+)
+
+var (
+	unmatchedRequests  = monitoring.NewInt(nil, "smb.unmatched_requests")
+	unmatchedResponses = monitoring.NewInt(nil, "smb.unmatched_responses")
+)
+
+type smbPlugin struct {
+	ports              []int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+	logger  *logp.Logger
+	isDebug bool
+}
+
+type stream struct {
+	applayer.Stream
+	// ts is the time the frame being buffered started.
+	ts time.Time
+	// skip is the number of bytes left of a partially decoded frame.
+	skip int
+}
+
+type connection struct {
+	streams [2]*stream
+	pending map[uint64]*transaction
+	// order holds the message IDs of the pending requests in the order
+	// they were sent, the oldest requests are expired first.
+	order []uint64
+
+	dialect  string
+	sessions map[uint64]*session
+	trees    map[treeKey]*tree
+	files    map[fileID]*openFile
+}
+
+type session struct {
+	auth  authentication
+	flags uint16
+}
+
+type treeKey struct {
+	sessionID uint64
+	treeID    uint32
+}
+
+type tree struct {
+	share     string
+	shareType string
+}
+
+// openFile is a file opened by a CREATE request, with the reads and writes
+// done until it's closed.
+type openFile struct {
+	tree      treeKey
+	name      string
+	directory bool
+	size      uint64
+
+	reads        int
+	writes       int
+	bytesRead    uint64
+	bytesWritten uint64
+}
+
+// transaction is a request and its final response, with the state of the
+// connection it applies to.
+type transaction struct {
+	request  *message
+	response *message
+	// previous is the request preceding a related request in a compound
+	// chain.
+	previous *transaction
+
+	session *session
+	tree    *tree
+	file    *openFile
+}
+
+func init() {
+	protos.Register("smb", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+	logger *logp.Logger,
+) (protos.Plugin, error) {
+	p := &smbPlugin{}
+	p.logger = logger.Named("smb")
+	p.isDebug = p.logger.IsDebug()
+
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	p.init(results, watcher, &config)
+	return p, nil
+}
+
+func (p *smbPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *smbConfig) {
+	p.ports = config.Ports
+	p.transactionTimeout = config.TransactionTimeout
+	p.results = results
+	p.watcher = watcher
+}
+
+//go:inline
+func (p *smbPlugin) debugf(format string, args ...interface{}) {
+	if p.isDebug {
+		p.logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *smbPlugin) GetPorts() []int {
+	return p.ports
+}
+
+func (p *smbPlugin) ConnectionTimeout() time.Duration {
+	return p.transactionTimeout
+}
+
+func (p *smbPlugin) ensureConnection(private protos.ProtocolData) *connection {
+	if conn, ok := private.(*connection); ok && conn != nil {
+		return conn
+	}
+	return &connection{
+		pending:  map[uint64]*transaction{},
+		sessions: map[uint64]*session{},
+		trees:    map[treeKey]*tree{},
+		files:    map[fileID]*openFile{},
+	}
+}
+
+func (p *smbPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := p.ensureConnection(private)
+
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if st.Buf.Len() == 0 {
+		st.ts = pkt.Ts
+	}
+	if err := st.Append(payload); err != nil {
+		p.debugf("%v, dropping TCP stream", err)
+		conn.streams[dir] = nil
+		return conn
+	}
+
+	for st.Buf.Len() >= 4 {
+		buf := st.Buf.Bytes()
+		typ := buf[0]
+		if typ != nbSessionMessage && (typ < nbSessionRequest || typ > nbSessionKeepAlive) {
+			p.debugf("not an SMB stream, dropping TCP stream")
+			conn.streams[dir] = nil
+			return conn
+		}
+		total := 4 + (int(buf[1])<<16 | int(buf[2])<<8 | int(buf[3]))
+		if len(buf) < total {
+			if typ != nbSessionMessage || total <= maxMessageSize || len(buf) < prefixSize {
+				// wait for more data
+				break
+			}
+			st.skip = total - len(buf)
+		} else {
+			buf = buf[:total]
+		}
+		if typ == nbSessionMessage && !p.handleFrame(conn, st, buf[4:], total-4, tcptuple, dir) {
+			p.debugf("invalid SMB message, dropping TCP stream")
+			conn.streams[dir] = nil
+			return conn
+		}
+		_ = st.Buf.Advance(len(buf))
+		st.Buf.Reset()
+		st.ts = pkt.Ts
+	}
+	return conn
+}
+
+// handleFrame decodes the messages of a frame, a single message or a
+// compound chain. data holds the whole frame, or its beginning if
+// truncated. It returns false if the frame isn't an SMB frame.
+func (p *smbPlugin) handleFrame(
+	conn *connection,
+	st *stream,
+	data []byte,
+	size int,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) bool {
+	if len(data) < 4 {
+		return false
+	}
+	switch {
+	case bytes.Equal(data[:4], protocolSMB2):
+	case bytes.Equal(data[:4], protocolTransform), bytes.Equal(data[:4], protocolCompression),
+		bytes.Equal(data[:4], protocolSMB1):
+		return true
+	default:
+		return false
+	}
+
+	var prev *transaction
+	for off := 0; ; {
+		msg := data[off:]
+		h, err := decodeHeader(msg)
+		if err != nil {
+			p.debugf("failed to decode header: %v", err)
+			// Only the first message of a truncated frame is complete.
+			return off > 0
+		}
+		msgSize := size - off
+		if h.nextCommand != 0 {
+			if h.nextCommand < headerSize {
+				return false
+			}
+			msgSize = int(h.nextCommand)
+			if msgSize <= len(msg) {
+				msg = msg[:msgSize]
+			}
+		}
+
+		m := &message{header: h, ts: st.ts, size: msgSize}
+		if err := decodeBody(msg, m); err != nil {
+			p.debugf("failed to decode %s: %v", commandName(h.command), err)
+		} else if m.isResponse() {
+			p.handleResponse(conn, m)
+		} else {
+			prev = p.handleRequest(conn, m, prev, tcptuple, dir)
+		}
+
+		if h.nextCommand == 0 || off+msgSize >= len(data) {
+			break
+		}
+		off += msgSize
+	}
+	return true
+}
+
+// handleRequest records a request and returns the transaction the next
+// related request of a compound chain refers to.
+func (p *smbPlugin) handleRequest(
+	conn *connection,
+	m *message,
+	prev *transaction,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *transaction {
+	if m.flags&flagRelated == 0 {
+		prev = nil
+	}
+	if !isTracked(m.command) {
+		return prev
+	}
+	if prev != nil {
+		// Related requests may use the session and tree of the previous
+		// request.
+		if m.sessionID == ^uint64(0) {
+			m.sessionID = prev.request.sessionID
+		}
+		if m.treeID == ^uint32(0) {
+			m.treeID = prev.request.treeID
+		}
+	}
+
+	m.tcpTuple = *tcptuple
+	m.direction = dir
+	m.cmdlineTuple = p.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	p.debugf("request %s message_id=%d size=%d", commandName(m.command), m.messageID, m.size)
+
+	if _, exists := conn.pending[m.messageID]; exists {
+		p.expire(conn, m.messageID)
+	}
+	if len(conn.order) >= maxPendingRequests {
+		p.expire(conn, conn.order[0])
+	}
+	trans := &transaction{request: m, previous: prev}
+	conn.pending[m.messageID] = trans
+	conn.order = append(conn.order, m.messageID)
+	return trans
+}
+
+func (p *smbPlugin) handleResponse(conn *connection, m *message) {
+	if m.messageID == asyncMessageID {
+		// Oplock or lease break notification.
+		return
+	}
+	trans, ok := conn.pending[m.messageID]
+	if !ok {
+		switch {
+		case m.command == cmdNegotiate:
+			// Answer to an SMB1 NEGOTIATE request offering SMB2 dialects.
+			if m.status == statusSuccess {
+				conn.dialect = m.dialect
+			}
+		case isTracked(m.command):
+			p.debugf("response with unknown message_id=%d", m.messageID)
+			unmatchedResponses.Inc()
+		}
+		return
+	}
+	if m.status == statusPending && m.flags&flagAsyncCommand != 0 {
+		// Interim response, the final response follows.
+		return
+	}
+	p.remove(conn, m.messageID)
+	trans.response = m
+
+	req := trans.request
+	success := !isErrorStatus(m.status)
+	trans.session = conn.sessions[req.sessionID]
+	trans.tree = conn.trees[treeKey{req.sessionID, req.treeID}]
+
+	switch req.command {
+	case cmdNegotiate:
+		if success {
+			conn.dialect = m.dialect
+		}
+	case cmdSessionSetup:
+		if m.status == statusMoreProcessingRequired {
+			// Intermediate leg of the authentication exchange.
+			return
+		}
+		if success && (len(conn.sessions) < maxTrees || trans.session != nil) {
+			conn.sessions[m.sessionID] = &session{auth: req.auth, flags: m.sessionFlags}
+		}
+	case cmdLogoff:
+		p.publish(conn, trans)
+		delete(conn.sessions, req.sessionID)
+		for key := range conn.trees {
+			if key.sessionID == req.sessionID {
+				conn.deleteTree(key)
+			}
+		}
+		return
+	case cmdTreeConnect:
+		if success && len(conn.trees) < maxTrees {
+			trans.tree = &tree{share: req.path, shareType: m.shareType}
+			conn.trees[treeKey{m.sessionID, m.treeID}] = trans.tree
+		}
+	case cmdTreeDisconnect:
+		p.publish(conn, trans)
+		conn.deleteTree(treeKey{req.sessionID, req.treeID})
+		return
+	case cmdCreate:
+		trans.file = &openFile{
+			tree:      treeKey{req.sessionID, req.treeID},
+			name:      req.name,
+			directory: m.directory,
+			size:      m.fileSize,
+		}
+		if success && len(conn.files) < maxOpenFiles {
+			conn.files[m.fileID] = trans.file
+		}
+	case cmdClose:
+		id := trans.fileID()
+		trans.file = conn.files[id]
+		delete(conn.files, id)
+	case cmdRead, cmdWrite:
+		// Reads and writes are only reported when they fail.
+		trans.file = conn.files[trans.fileID()]
+		if m.status == statusEndOfFile {
+			return
+		}
+		if success {
+			if f := trans.file; f != nil {
+				if req.command == cmdRead {
+					f.reads++
+					f.bytesRead += uint64(m.length)
+				} else {
+					f.writes++
+					f.bytesWritten += uint64(m.length)
+				}
+			}
+			return
+		}
+	}
+	p.publish(conn, trans)
+}
+
+// fileID returns the ID of the file a request applies to, resolving the
+// IDs of related requests.
+func (t *transaction) fileID() fileID {
+	switch {
+	case t.request.command == cmdCreate && t.response != nil:
+		return t.response.fileID
+	case t.request.fileID == relatedFileID && t.previous != nil:
+		return t.previous.fileID()
+	}
+	return t.request.fileID
+}
+
+// deleteTree forgets a tree connect and the files opened in it.
+func (conn *connection) deleteTree(key treeKey) {
+	delete(conn.trees, key)
+	for id, f := range conn.files {
+		if f.tree == key {
+			delete(conn.files, id)
+		}
+	}
+}
+
+// remove removes a pending request.
+func (p *smbPlugin) remove(conn *connection, id uint64) {
+	for i, pending := range conn.order {
+		if pending == id {
+			conn.order = append(conn.order[:i], conn.order[i+1:]...)
+			break
+		}
+	}
+	delete(conn.pending, id)
+}
+
+// expire publishes the request with the given message ID as unmatched.
+func (p *smbPlugin) expire(conn *connection, id uint64) {
+	if trans, ok := conn.pending[id]; ok {
+		p.remove(conn, id)
+		unmatchedRequests.Inc()
+		req := trans.request
+		trans.session = conn.sessions[req.sessionID]
+		trans.tree = conn.trees[treeKey{req.sessionID, req.treeID}]
+		switch req.command {
+		case cmdCreate:
+			trans.file = &openFile{name: req.name}
+		case cmdClose, cmdRead, cmdWrite:
+			trans.file = conn.files[trans.fileID()]
+		}
+		p.publish(conn, trans)
+	}
+}
+
+func (p *smbPlugin) publish(conn *connection, trans *transaction) {
+	if p.results != nil {
+		p.results(p.newTransaction(conn, trans))
+	}
+}
+
+func (p *smbPlugin) newTransaction(conn *connection, trans *transaction) beat.Event {
+	req, resp := trans.request, trans.response
+	source, destination := common.MakeEndpointPair(req.tcpTuple.BaseTuple, req.cmdlineTuple)
+	src, dst := &source, &destination
+	if req.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(req.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.Source.Bytes = int64(req.size)
+	pbf.Event.Dataset = "smb"
+	pbf.Event.Start = req.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	method := commandName(req.command)
+	pbf.Event.Action = "smb." + strings.ToLower(method)
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["method"] = method
+
+	smb := mapstr.M{"message_id": req.messageID}
+	if conn.dialect != "" {
+		smb["dialect"] = conn.dialect
+	}
+	sessionID, treeID := req.sessionID, req.treeID
+	if resp != nil {
+		switch req.command {
+		case cmdSessionSetup:
+			sessionID = resp.sessionID
+		case cmdTreeConnect:
+			treeID = resp.treeID
+		}
+	}
+	if sessionID != 0 {
+		smb["session_id"] = fmt.Sprintf("%016x", sessionID)
+	}
+	if treeID != 0 {
+		smb["tree_id"] = treeID
+	}
+
+	auth := authentication{}
+	if trans.session != nil {
+		auth = trans.session.auth
+	}
+	var resource string
+	if trans.tree != nil {
+		resource = trans.tree.share
+		smb["share"] = trans.tree.share
+		if trans.tree.shareType != "" {
+			smb["share_type"] = trans.tree.shareType
+		}
+	}
+
+	switch req.command {
+	case cmdNegotiate:
+		if len(req.dialects) != 0 {
+			smb["negotiate"] = mapstr.M{"dialects": req.dialects}
+		}
+	case cmdSessionSetup:
+		auth = req.auth
+		sess := mapstr.M{}
+		if auth.mechanism != "" {
+			sess["authentication"] = auth.mechanism
+		}
+		if auth.user != "" {
+			sess["user"] = auth.user
+		}
+		if auth.domain != "" {
+			sess["domain"] = auth.domain
+		}
+		if auth.workstation != "" {
+			sess["workstation"] = auth.workstation
+		}
+		if resp != nil && resp.sessionFlags != 0 {
+			var flags []string
+			for _, f := range sessionFlagNames {
+				if resp.sessionFlags&f.flag != 0 {
+					flags = append(flags, f.name)
+				}
+			}
+			sess["flags"] = flags
+		}
+		if len(sess) != 0 {
+			smb["session"] = sess
+		}
+	case cmdTreeConnect:
+		resource = req.path
+		smb["share"] = req.path
+	case cmdCreate:
+		create := mapstr.M{"disposition": req.disposition}
+		if resp != nil && !isErrorStatus(resp.status) {
+			create["action"] = resp.action
+		}
+		smb["create"] = create
+	case cmdRead, cmdWrite:
+		smb["offset"] = req.offset
+		smb["length"] = req.length
+	}
+
+	if f := trans.file; f != nil {
+		if f.name != "" {
+			if resource != "" {
+				resource += `\`
+			}
+			resource += f.name
+		}
+		file := mapstr.M{"name": f.name}
+		if req.command != cmdCreate || (resp != nil && !isErrorStatus(resp.status)) {
+			file["directory"] = f.directory
+			file["size"] = f.size
+		}
+		if req.command == cmdClose {
+			file["reads"] = f.reads
+			file["writes"] = f.writes
+			file["bytes_read"] = f.bytesRead
+			file["bytes_written"] = f.bytesWritten
+		}
+		smb["file"] = file
+	}
+
+	query := method
+	if resource != "" {
+		fields["resource"] = resource
+		query += " " + resource
+	}
+	fields["query"] = query
+
+	if auth.user != "" {
+		fields["user.name"] = auth.user
+		if auth.domain != "" {
+			fields["user.domain"] = auth.domain
+		}
+		pbf.AddUser(auth.user)
+	}
+
+	status := common.OK_STATUS
+	if resp != nil {
+		pbf.Event.End = resp.ts
+		pbf.Destination.Bytes = int64(resp.size)
+		smb["status"] = ntStatusName(resp.status)
+		smb["status_code"] = resp.status
+		if isErrorStatus(resp.status) {
+			status = common.ERROR_STATUS
+		}
+	} else {
+		status = common.ERROR_STATUS
+		pbf.Error.Message = append(pbf.Error.Message, "Unmatched request")
+	}
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+
+	fields["smb"] = smb
+	fields["status"] = status
+	return evt
+}
+
+func (p *smbPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return private, false
+	}
+	st := conn.streams[dir]
+	if st == nil {
+		return conn, false
+	}
+	if st.skip > 0 && nbytes <= st.skip {
+		st.skip -= nbytes
+		return conn, false
+	}
+	// Frame boundaries are lost.
+	conn.streams[dir] = nil
+	return conn, false
+}
+
+func (p *smbPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests left without response.
+func (p *smbPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connection)
+	if !ok || conn == nil {
+		return
+	}
+	for len(conn.order) > 0 {
+		p.expire(conn, conn.order[0])
+	}
+}
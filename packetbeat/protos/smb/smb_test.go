@@ -0,0 +1,569 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package smb
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+)
+
+const serverPort = 445
+
+const (
+	sessionID = 0x0000100000000009
+	treeID    = 5
+	share     = `\\fs01\projects`
+)
+
+var testFileID = fileID{1, 0, 0, 0, 0, 0, 0, 0, 0x21, 0, 0, 0, 0, 0, 0, 0}
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func (e *eventStore) get(t *testing.T, idx int, key string) interface{} {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	v, err := e.events[idx].Fields.GetValue(key)
+	require.NoError(t, err, "missing %s in event %d: %v", key, idx, e.events[idx].Fields)
+	return v
+}
+
+func (e *eventStore) missing(t *testing.T, idx int, key string) {
+	t.Helper()
+	require.Greater(t, len(e.events), idx, "missing event %d", idx)
+	_, err := e.events[idx].Fields.GetValue(key)
+	assert.Error(t, err, "unexpected %s in event %d", key, idx)
+}
+
+func smbModForTests(t *testing.T, store *eventStore) *smbPlugin {
+	p, err := New(true, store.publish, &procs.ProcessesWatcher{}, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	return p.(*smbPlugin)
+}
+
+// Message encoding helpers.
+
+func le16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func le64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func utf16le(s string) []byte {
+	var out []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return out
+}
+
+func join(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+type smb2 struct {
+	command   uint16
+	status    uint32
+	flags     uint32
+	messageID uint64
+	sessionID uint64
+	treeID    uint32
+	body      []byte
+}
+
+func (m smb2) encode(next uint32) []byte {
+	var asyncOrTree []byte
+	if m.flags&flagAsyncCommand != 0 {
+		asyncOrTree = le64(m.messageID + 100)
+	} else {
+		asyncOrTree = join(le32(0xfeff), le32(m.treeID))
+	}
+	return join(protocolSMB2, le16(headerSize), le16(1), le32(m.status), le16(m.command), le16(1),
+		le32(m.flags), le32(next), le64(m.messageID), asyncOrTree, le64(m.sessionID), make([]byte, 16), m.body)
+}
+
+func request(cmd uint16, id uint64, body []byte) smb2 {
+	return smb2{command: cmd, messageID: id, sessionID: sessionID, treeID: treeID, body: body}
+}
+
+func response(cmd uint16, id uint64, status uint32, body []byte) smb2 {
+	if isErrorStatus(status) && status != statusMoreProcessingRequired {
+		body = []byte{9, 0, 0, 0, 0, 0, 0, 0, 0}
+	}
+	return smb2{command: cmd, status: status, flags: flagServerToRedir, messageID: id, sessionID: sessionID, treeID: treeID, body: body}
+}
+
+// unauthenticated removes the session and tree of a message sent before
+// the session is set up.
+func unauthenticated(m smb2) smb2 {
+	m.sessionID, m.treeID = 0, 0
+	return m
+}
+
+func related(m smb2) smb2 {
+	m.flags |= flagRelated
+	return m
+}
+
+// frame encodes a NetBIOS session message holding the given messages as a
+// compound chain.
+func frame(msgs ...smb2) []byte {
+	var payload []byte
+	for i, m := range msgs {
+		b := m.encode(0)
+		if i < len(msgs)-1 {
+			for len(b)%8 != 0 {
+				b = append(b, 0)
+			}
+			b = m.encode(uint32(len(b)))
+			for len(b)%8 != 0 {
+				b = append(b, 0)
+			}
+		}
+		payload = append(payload, b...)
+	}
+	n := len(payload)
+	return append([]byte{0, byte(n >> 16), byte(n >> 8), byte(n)}, payload...)
+}
+
+func negotiateRequest(dialects ...uint16) []byte {
+	body := join(le16(36), le16(uint16(len(dialects))), le16(1), le16(0), le32(0), make([]byte, 16), make([]byte, 8))
+	for _, d := range dialects {
+		body = append(body, le16(d)...)
+	}
+	return body
+}
+
+func negotiateResponse(dialect uint16) []byte {
+	return join(le16(65), le16(1), le16(dialect), le16(0), make([]byte, 56))
+}
+
+func sessionSetupRequest(blob []byte) []byte {
+	return join(le16(25), []byte{0, 1}, le32(1), le32(0), le16(headerSize+24), le16(uint16(len(blob))), le64(0), blob)
+}
+
+func sessionSetupResponse(flags uint16, blob []byte) []byte {
+	return join(le16(9), le16(flags), le16(headerSize+8), le16(uint16(len(blob))), blob)
+}
+
+func ntlmNegotiateMessage() []byte {
+	return join(ntlmSignature, le32(1), le32(0xe2088297), make([]byte, 16))
+}
+
+func ntlmAuthenticateMessage(domain, user, workstation string) []byte {
+	payload := [][]byte{nil, nil, utf16le(domain), utf16le(user), utf16le(workstation), nil}
+	fields := make([]byte, 0, 48)
+	offset := 64
+	for _, p := range payload {
+		fields = append(fields, join(le16(uint16(len(p))), le16(uint16(len(p))), le32(uint32(offset)))...)
+		offset += len(p)
+	}
+	return join(ntlmSignature, le32(ntlmAuthenticate), fields, le32(0xe2888215|ntlmNegotiateUnicode), join(payload...))
+}
+
+func treeConnectRequest(path string) []byte {
+	p := utf16le(path)
+	return join(le16(9), le16(0), le16(headerSize+8), le16(uint16(len(p))), p)
+}
+
+func treeConnectResponse(shareType byte) []byte {
+	return join(le16(16), []byte{shareType, 0}, le32(0), le32(0), le32(0x1f01ff))
+}
+
+func createRequest(name string, disposition uint32) []byte {
+	n := utf16le(name)
+	return join(le16(57), []byte{0, 0}, le32(0), le64(0), le64(0), le32(0x12019f), le32(0x80), le32(7),
+		le32(disposition), le32(0x40), le16(headerSize+56), le16(uint16(len(n))), le32(0), le32(0), n)
+}
+
+func createResponse(action uint32, size uint64, attributes uint32, id fileID) []byte {
+	return join(le16(89), []byte{0, 0}, le32(action), make([]byte, 32), le64(size+4095&^4095), le64(size),
+		le32(attributes), le32(0), id[:], le32(0), le32(0))
+}
+
+func closeRequest(id fileID) []byte {
+	return join(le16(24), le16(0), le32(0), id[:])
+}
+
+func closeResponse() []byte {
+	return join(le16(60), le16(0), make([]byte, 56))
+}
+
+func readRequest(id fileID, offset uint64, length uint32) []byte {
+	return join(le16(49), []byte{0x50, 0}, le32(length), le64(offset), id[:], le32(1), le32(0), le32(0), le16(0), le16(0), []byte{0})
+}
+
+func readResponse(n int) []byte {
+	return join(le16(17), []byte{0x50, 0}, le32(uint32(n)), le32(0), le32(0), make([]byte, n))
+}
+
+func writeRequest(id fileID, offset uint64, n int) []byte {
+	return join(le16(49), le16(0x70), le32(uint32(n)), le64(offset), id[:], le32(0), le32(0), le16(0), le16(0), le32(0), make([]byte, n))
+}
+
+func writeResponse(n int) []byte {
+	return join(le16(17), le16(0), le32(uint32(n)), le32(0), le16(0), le16(0))
+}
+
+func emptyBody() []byte { return join(le16(4), le16(0)) }
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: serverPort,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// login negotiates the dialect and sets up an NTLM session.
+func login(plugin *smbPlugin, tuple *common.TCPTuple, private protos.ProtocolData) protos.ProtocolData {
+	private = plugin.Parse(&protos.Packet{Payload: frame(unauthenticated(request(cmdNegotiate, 0, negotiateRequest(0x0202, 0x0210, 0x0300, 0x0302, 0x0311))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(unauthenticated(response(cmdNegotiate, 0, statusSuccess, negotiateResponse(0x0311))))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(unauthenticated(request(cmdSessionSetup, 1, sessionSetupRequest(ntlmNegotiateMessage()))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdSessionSetup, 1, statusMoreProcessingRequired, sessionSetupResponse(0, []byte("NTLMSSP\x00\x02\x00\x00\x00"))))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdSessionSetup, 2, sessionSetupRequest(ntlmAuthenticateMessage("CORP", "jdoe", "WS042"))))}, tuple, tcp.TCPDirectionOriginal, private)
+	return plugin.Parse(&protos.Packet{Payload: frame(response(cmdSessionSetup, 2, statusSuccess, sessionSetupResponse(0, nil)))}, tuple, tcp.TCPDirectionReverse, private)
+}
+
+// treeConnect connects to the test share.
+func treeConnect(plugin *smbPlugin, tuple *common.TCPTuple, private protos.ProtocolData) protos.ProtocolData {
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdTreeConnect, 3, treeConnectRequest(share)))}, tuple, tcp.TCPDirectionOriginal, private)
+	return plugin.Parse(&protos.Packet{Payload: frame(response(cmdTreeConnect, 3, statusSuccess, treeConnectResponse(1)))}, tuple, tcp.TCPDirectionReverse, private)
+}
+
+func TestSessionSetup(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = login(plugin, tuple, private)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "smb", store.get(t, 0, "type"))
+	store.missing(t, 0, "smb.session_id")
+	store.missing(t, 0, "smb.tree_id")
+	assert.Equal(t, "NEGOTIATE", store.get(t, 0, "method"))
+	assert.Equal(t, "smb.negotiate", store.get(t, 0, "event.action"))
+	assert.Equal(t, "3.1.1", store.get(t, 0, "smb.dialect"))
+	assert.Equal(t, []string{"2.0.2", "2.1", "3.0", "3.0.2", "3.1.1"}, store.get(t, 0, "smb.negotiate.dialects"))
+	assert.Equal(t, "OK", store.get(t, 0, "status"))
+	assert.Equal(t, "tcp", store.get(t, 0, "network.transport"))
+	assert.Equal(t, "smb", store.get(t, 0, "network.protocol"))
+	assert.EqualValues(t, 6512, store.get(t, 0, "source.port"))
+	assert.EqualValues(t, serverPort, store.get(t, 0, "destination.port"))
+
+	assert.Equal(t, "SESSION_SETUP", store.get(t, 1, "method"))
+	assert.Equal(t, "smb.session_setup", store.get(t, 1, "event.action"))
+	assert.Equal(t, "0000100000000009", store.get(t, 1, "smb.session_id"))
+	assert.EqualValues(t, 2, store.get(t, 1, "smb.message_id"))
+	assert.Equal(t, "ntlm", store.get(t, 1, "smb.session.authentication"))
+	assert.Equal(t, "jdoe", store.get(t, 1, "smb.session.user"))
+	assert.Equal(t, "CORP", store.get(t, 1, "smb.session.domain"))
+	assert.Equal(t, "WS042", store.get(t, 1, "smb.session.workstation"))
+	assert.Equal(t, "jdoe", store.get(t, 1, "user.name"))
+	assert.Equal(t, "CORP", store.get(t, 1, "user.domain"))
+	assert.Equal(t, "STATUS_SUCCESS", store.get(t, 1, "smb.status"))
+	assert.EqualValues(t, 0, store.get(t, 1, "smb.status_code"))
+	assert.Equal(t, "OK", store.get(t, 1, "status"))
+	assert.Equal(t, "SESSION_SETUP", store.get(t, 1, "query"))
+}
+
+func TestSessionSetupFailure(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdSessionSetup, 1, sessionSetupRequest(ntlmNegotiateMessage())))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdSessionSetup, 1, statusMoreProcessingRequired, sessionSetupResponse(0, nil)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdSessionSetup, 2, sessionSetupRequest(ntlmAuthenticateMessage("CORP", "jdoe", "WS042"))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdSessionSetup, 2, 0xc000006d, nil))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.get(t, 0, "status"))
+	assert.Equal(t, "failure", store.get(t, 0, "event.outcome"))
+	assert.Equal(t, "STATUS_LOGON_FAILURE", store.get(t, 0, "smb.status"))
+	assert.EqualValues(t, 0xc000006d, store.get(t, 0, "smb.status_code"))
+	assert.Equal(t, "jdoe", store.get(t, 0, "user.name"))
+	assert.Empty(t, private.(*connection).sessions)
+}
+
+func TestGuestSession(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdSessionSetup, 1, sessionSetupRequest(ntlmAuthenticateMessage("", "guest", ""))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdSessionSetup, 1, statusSuccess, sessionSetupResponse(0x1, nil)))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, []string{"guest"}, store.get(t, 0, "smb.session.flags"))
+	store.missing(t, 0, "smb.session.domain")
+	store.missing(t, 0, "user.domain")
+}
+
+func TestFileAccess(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = login(plugin, tuple, private)
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`reports\q3.xlsx`, 1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, statusSuccess, createResponse(1, 10000, 0x20, testFileID)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdRead, 5, readRequest(testFileID, 0, 8192)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdRead, 5, statusSuccess, readResponse(8192)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdRead, 6, readRequest(testFileID, 8192, 8192)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdRead, 6, statusSuccess, readResponse(1808)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdRead, 7, readRequest(testFileID, 10000, 8192)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdRead, 7, statusEndOfFile, nil))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdWrite, 8, writeRequest(testFileID, 10000, 100)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdWrite, 8, statusSuccess, writeResponse(100)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdClose, 9, closeRequest(testFileID)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdClose, 9, statusSuccess, closeResponse()))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 5)
+	assert.Equal(t, "TREE_CONNECT", store.get(t, 2, "method"))
+	assert.Equal(t, "smb.tree_connect", store.get(t, 2, "event.action"))
+	assert.Equal(t, share, store.get(t, 2, "smb.share"))
+	assert.Equal(t, "disk", store.get(t, 2, "smb.share_type"))
+	assert.EqualValues(t, treeID, store.get(t, 2, "smb.tree_id"))
+	assert.Equal(t, share, store.get(t, 2, "resource"))
+	assert.Equal(t, "jdoe", store.get(t, 2, "user.name"))
+
+	assert.Equal(t, "CREATE", store.get(t, 3, "method"))
+	assert.Equal(t, share+`\reports\q3.xlsx`, store.get(t, 3, "resource"))
+	assert.Equal(t, `CREATE `+share+`\reports\q3.xlsx`, store.get(t, 3, "query"))
+	assert.Equal(t, share, store.get(t, 3, "smb.share"))
+	assert.Equal(t, `reports\q3.xlsx`, store.get(t, 3, "smb.file.name"))
+	assert.Equal(t, false, store.get(t, 3, "smb.file.directory"))
+	assert.EqualValues(t, 10000, store.get(t, 3, "smb.file.size"))
+	assert.Equal(t, "open", store.get(t, 3, "smb.create.disposition"))
+	assert.Equal(t, "opened", store.get(t, 3, "smb.create.action"))
+	assert.Equal(t, "jdoe", store.get(t, 3, "user.name"))
+	store.missing(t, 3, "smb.file.reads")
+
+	assert.Equal(t, "CLOSE", store.get(t, 4, "method"))
+	assert.Equal(t, share+`\reports\q3.xlsx`, store.get(t, 4, "resource"))
+	assert.EqualValues(t, 2, store.get(t, 4, "smb.file.reads"))
+	assert.EqualValues(t, 10000, store.get(t, 4, "smb.file.bytes_read"))
+	assert.EqualValues(t, 1, store.get(t, 4, "smb.file.writes"))
+	assert.EqualValues(t, 100, store.get(t, 4, "smb.file.bytes_written"))
+	assert.Equal(t, "OK", store.get(t, 4, "status"))
+	assert.Empty(t, private.(*connection).files)
+}
+
+func TestCreateFailure(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`missing.txt`, 1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, 0xc0000034, nil))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "Error", store.get(t, 1, "status"))
+	assert.Equal(t, "STATUS_OBJECT_NAME_NOT_FOUND", store.get(t, 1, "smb.status"))
+	assert.Equal(t, "missing.txt", store.get(t, 1, "smb.file.name"))
+	assert.Equal(t, "open", store.get(t, 1, "smb.create.disposition"))
+	store.missing(t, 1, "smb.create.action")
+	store.missing(t, 1, "smb.file.size")
+	assert.Empty(t, private.(*connection).files)
+}
+
+func TestReadWriteErrors(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`locked.db`, 3)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, statusSuccess, createResponse(2, 0, 0x20, testFileID)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdWrite, 5, writeRequest(testFileID, 4096, 512)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdWrite, 5, 0xc0000054, nil))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 3)
+	assert.Equal(t, "WRITE", store.get(t, 2, "method"))
+	assert.Equal(t, "STATUS_FILE_LOCK_CONFLICT", store.get(t, 2, "smb.status"))
+	assert.Equal(t, share+`\locked.db`, store.get(t, 2, "resource"))
+	assert.EqualValues(t, 4096, store.get(t, 2, "smb.offset"))
+	assert.EqualValues(t, 512, store.get(t, 2, "smb.length"))
+	assert.Equal(t, "Error", store.get(t, 2, "status"))
+}
+
+func TestCompoundRequests(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`notes.txt`, 1)), related(request(cmdRead, 5, readRequest(relatedFileID, 0, 4096))), related(request(cmdClose, 6, closeRequest(relatedFileID))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, statusSuccess, createResponse(1, 300, 0x20, testFileID)), related(response(cmdRead, 5, statusSuccess, readResponse(300))), related(response(cmdClose, 6, statusSuccess, closeResponse())))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 3)
+	assert.Equal(t, "CREATE", store.get(t, 1, "method"))
+	assert.Equal(t, "CLOSE", store.get(t, 2, "method"))
+	assert.Equal(t, share+`\notes.txt`, store.get(t, 2, "resource"))
+	assert.EqualValues(t, 1, store.get(t, 2, "smb.file.reads"))
+	assert.EqualValues(t, 300, store.get(t, 2, "smb.file.bytes_read"))
+}
+
+func TestAsyncResponse(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`slow.bin`, 2)))}, tuple, tcp.TCPDirectionOriginal, private)
+	interim := response(cmdCreate, 4, statusPending, nil)
+	interim.flags |= flagAsyncCommand
+	interim.body = []byte{9, 0, 0, 0, 0, 0, 0, 0, 0}
+	private = plugin.Parse(&protos.Packet{Payload: frame(interim)}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 1)
+
+	final := response(cmdCreate, 4, statusSuccess, createResponse(2, 0, 0x20, testFileID))
+	final.flags |= flagAsyncCommand
+	private = plugin.Parse(&protos.Packet{Payload: frame(final)}, tuple, tcp.TCPDirectionReverse, private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "created", store.get(t, 1, "smb.create.action"))
+	assert.Equal(t, "create", store.get(t, 1, "smb.create.disposition"))
+}
+
+func TestLargeRead(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`image.iso`, 1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, statusSuccess, createResponse(1, 1<<20, 0x20, testFileID)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdRead, 5, readRequest(testFileID, 0, 1<<20)))}, tuple, tcp.TCPDirectionOriginal, private)
+	for payload := frame(response(cmdRead, 5, statusSuccess, readResponse(1<<20))); len(payload) > 0; {
+		n := min(len(payload), 1400)
+		private = plugin.Parse(&protos.Packet{Payload: payload[:n]}, tuple, tcp.TCPDirectionReverse, private)
+		payload = payload[n:]
+	}
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdClose, 6, closeRequest(testFileID)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdClose, 6, statusSuccess, closeResponse()))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 3)
+	assert.EqualValues(t, 1<<20, store.get(t, 2, "smb.file.bytes_read"))
+	assert.EqualValues(t, 1, store.get(t, 2, "smb.file.reads"))
+}
+
+func TestTreeDisconnect(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = login(plugin, tuple, private)
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(``, 1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdCreate, 4, statusSuccess, createResponse(1, 0, 0x10, testFileID)))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdTreeDisconnect, 5, emptyBody()))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdTreeDisconnect, 5, statusSuccess, emptyBody()))}, tuple, tcp.TCPDirectionReverse, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdLogoff, 6, emptyBody()))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdLogoff, 6, statusSuccess, emptyBody()))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 6)
+	assert.Equal(t, share, store.get(t, 3, "resource"))
+	assert.Equal(t, true, store.get(t, 3, "smb.file.directory"))
+	assert.Equal(t, "TREE_DISCONNECT", store.get(t, 4, "method"))
+	assert.Equal(t, share, store.get(t, 4, "smb.share"))
+	assert.Equal(t, "LOGOFF", store.get(t, 5, "method"))
+	assert.Equal(t, "jdoe", store.get(t, 5, "user.name"))
+	conn := private.(*connection)
+	assert.Empty(t, conn.files)
+	assert.Empty(t, conn.trees)
+	assert.Empty(t, conn.sessions)
+}
+
+func TestUnansweredRequests(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = treeConnect(plugin, tuple, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdCreate, 4, createRequest(`a.txt`, 1)))}, tuple, tcp.TCPDirectionOriginal, private)
+	// Requests that aren't reported are ignored.
+	private = plugin.Parse(&protos.Packet{Payload: frame(request(cmdQueryDirectory, 5, join(le16(33), make([]byte, 31))))}, tuple, tcp.TCPDirectionOriginal, private)
+	private = plugin.Parse(&protos.Packet{Payload: frame(response(cmdQueryDirectory, 5, statusSuccess, join(le16(9), make([]byte, 7))))}, tuple, tcp.TCPDirectionReverse, private)
+
+	require.Len(t, store.events, 1)
+	plugin.Expired(tuple, private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "CREATE", store.get(t, 1, "method"))
+	assert.Equal(t, "Error", store.get(t, 1, "status"))
+	assert.Equal(t, "Unmatched request", store.get(t, 1, "error.message"))
+	assert.Equal(t, share+`\a.txt`, store.get(t, 1, "resource"))
+	store.missing(t, 1, "smb.status")
+}
+
+func TestIgnoredFrames(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	// NetBIOS keep-alive.
+	private = plugin.Parse(&protos.Packet{Payload: []byte{0x85, 0, 0, 0}}, tuple, tcp.TCPDirectionOriginal, private)
+	// Encrypted message.
+	encrypted := join(protocolTransform, make([]byte, 48))
+	private = plugin.Parse(&protos.Packet{Payload: join([]byte{0, 0, 0, byte(len(encrypted))}, encrypted)}, tuple, tcp.TCPDirectionOriginal, private)
+	private = treeConnect(plugin, tuple, private)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "TREE_CONNECT", store.get(t, 0, "method"))
+}
+
+func TestNotSMB(t *testing.T) {
+	store := &eventStore{}
+	plugin := smbModForTests(t, store)
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	private = plugin.Parse(&protos.Packet{Payload: []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")}, tuple, tcp.TCPDirectionOriginal, private)
+	assert.Nil(t, private.(*connection).streams[tcp.TCPDirectionOriginal])
+	assert.Empty(t, store.events)
+}
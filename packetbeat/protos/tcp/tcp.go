@@ -36,6 +36,7 @@ import (
 
 	"github.com/elastic/beats/v7/packetbeat/flows"
 	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/classify"
 )
 
 const TCPMaxDataInStream = 10 * (1 << 20)
@@ -56,6 +57,11 @@ type TCP struct {
 	protocols    protos.Protocols
 	expiredConns expirationQueue
 
+	// classifyPackets is the number of packets with payload inspected to
+	// detect the protocol of connections on ports no analyzer is configured
+	// for, 0 if they are not followed.
+	classifyPackets int
+
 	metrics *inputMetrics
 	logger  *logp.Logger
 	isDebug bool
@@ -86,6 +92,13 @@ func NewTCP(p protos.Protocols, id, device string, idx int, logger *logp.Logger)
 	return tcp, nil
 }
 
+// EnableClassification has connections on ports no analyzer is configured
+// for handed to the analyzer of the protocol detected from the payload of
+// their first maxPackets packets.
+func (tcp *TCP) EnableClassification(maxPackets int) {
+	tcp.classifyPackets = maxPackets
+}
+
 //go:inline
 func (tcp *TCP) debugf(format string, args ...interface{}) {
 	if tcp.isDebug {
@@ -130,6 +143,10 @@ func (tcp *TCP) Process(id *flows.FlowID, tcphdr *layers.TCP, pkt *protos.Packet
 		return
 	}
 
+	if conn.protocol == protos.UnknownProtocol && !tcp.classify(conn, pkt.Payload) {
+		return
+	}
+
 	tcpStartSeq := tcphdr.Seq
 	payloadLen := uint64(len(pkt.Payload))
 	if payloadLen > uint64(^uint32(0)) {
@@ -200,7 +217,7 @@ func (tcp *TCP) getStream(pkt *protos.Packet) (stream TCPStream, created bool) {
 	}
 
 	protocol := tcp.decideProtocol(&pkt.Tuple)
-	if protocol == protos.UnknownProtocol {
+	if protocol == protos.UnknownProtocol && tcp.classifyPackets == 0 {
 		// don't follow
 		return TCPStream{}, false
 	}
@@ -268,6 +285,23 @@ func (tcp *TCP) decideProtocol(tuple *common.IPPortTuple) protos.Protocol {
 	return protos.UnknownProtocol
 }
 
+// classify detects the protocol of a connection on ports no analyzer is
+// configured for, returning whether the connection has been handed to the
+// analyzer of the protocol.
+func (tcp *TCP) classify(conn *TCPConnection, payload []byte) bool {
+	if len(payload) == 0 || conn.inspected >= tcp.classifyPackets {
+		return false
+	}
+	conn.inspected++
+	protocol := protos.Lookup(classify.Dispatchable(classify.TCP, payload))
+	if protocol == protos.UnknownProtocol || tcp.protocols.GetTCP(protocol) == nil {
+		return false
+	}
+	tcp.debugf("Connection %s classified as %s", conn.tuple, protocol)
+	conn.protocol = protocol
+	return true
+}
+
 func (tcp *TCP) findStream(k common.HashableIPPortTuple) *TCPConnection {
 	v := tcp.streams.Get(k)
 	if v != nil {
@@ -286,6 +320,18 @@ func (tcp *TCP) Close() {
 	tcp.metrics.close()
 }
 
+// Flush expires all TCP streams, notifying expiration aware plugins so they
+// can publish incomplete transactions, and flushes all TCP plugins.
+func (tcp *TCP) Flush() {
+	tcp.streams.Flush()
+	tcp.expiredConns.notifyAll()
+	for _, plugin := range tcp.protocols.GetAllTCP() {
+		if flusher, ok := plugin.(protos.PluginFlusher); ok {
+			flusher.Flush()
+		}
+	}
+}
+
 type TCPConnection struct {
 	id       uint32
 	tuple    *common.IPPortTuple
@@ -295,6 +341,10 @@ type TCPConnection struct {
 
 	lastSeq [2]uint32
 
+	// inspected counts the packets inspected to detect the protocol of
+	// the connection.
+	inspected int
+
 	// protocols private data
 	data protos.ProtocolData
 }
@@ -334,6 +384,9 @@ func (stream *TCPStream) addPacket(pkt *protos.Packet, tcphdr *layers.TCP) {
 func (stream *TCPStream) gapInStream(nbytes int) (drop bool) {
 	conn := stream.conn
 	mod := conn.tcp.protocols.GetTCP(conn.protocol)
+	if mod == nil {
+		return false
+	}
 	conn.data, drop = mod.GapInStream(&conn.tcptuple, stream.dir, nbytes, conn.data)
 	return drop
 }
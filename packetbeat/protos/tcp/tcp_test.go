@@ -44,7 +44,7 @@ const (
 	ClientIP   = "10.0.0.1"
 )
 
-var httpProtocol, mysqlProtocol, redisProtocol protos.Protocol
+var httpProtocol, mysqlProtocol, redisProtocol, classifiedProtocol protos.Protocol
 
 func init() {
 	new := func(_ bool, _ protos.Reporter, _ *procs.ProcessesWatcher, _ *conf.C, _ *logp.Logger) (protos.Plugin, error) {
@@ -54,10 +54,14 @@ func init() {
 	protos.Register("httpTest", new)
 	protos.Register("mysqlTest", new)
 	protos.Register("redisTest", new)
+	// Connections classified as HTTP are looked up by the name of the
+	// analyzer.
+	protos.Register("http", new)
 
 	httpProtocol = protos.Lookup("httpTest")
 	redisProtocol = protos.Lookup("redisTest")
 	mysqlProtocol = protos.Lookup("mysqlTest")
+	classifiedProtocol = protos.Lookup("http")
 }
 
 type TestProtocol struct {
@@ -337,6 +341,75 @@ func TestTCSeqPayload(t *testing.T) {
 	}
 }
 
+func TestClassification(t *testing.T) {
+	request := []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+
+	tests := []struct {
+		name     string
+		packets  int
+		payloads [][]byte
+		want     []byte
+	}{
+		{
+			name:     "disabled",
+			payloads: [][]byte{request},
+		},
+		{
+			name:     "first packet",
+			packets:  2,
+			payloads: [][]byte{nil, request, {1, 2, 3}},
+			want:     append(append([]byte{}, request...), 1, 2, 3),
+		},
+		{
+			name:     "second packet",
+			packets:  2,
+			payloads: [][]byte{{1, 2, 3}, request},
+			want:     request,
+		},
+		{
+			name:     "too late",
+			packets:  2,
+			payloads: [][]byte{{1, 2, 3}, {4, 5, 6}, request},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var state []byte
+			tcp, err := NewTCP(protocols{
+				tcp: map[protos.Protocol]protos.TCPPlugin{
+					classifiedProtocol: &TestProtocol{
+						Ports: []int{80},
+						parse: makeCollectPayload(&state, false),
+					},
+				},
+			}, "test", "test", 0, logptest.NewTestingLogger(t, ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tcp.metrics.close()
+			if test.packets > 0 {
+				tcp.EnableClassification(test.packets)
+			}
+
+			addr := common.NewIPPortTuple(4,
+				net.ParseIP(ClientIP), 34567,
+				net.ParseIP(ServerIP), 8080)
+			seq := uint32(1)
+			for _, payload := range test.payloads {
+				tcp.Process(nil, &layers.TCP{Seq: seq}, &protos.Packet{
+					Ts:      time.Now(),
+					Tuple:   addr,
+					Payload: payload,
+				})
+				seq += uint32(len(payload))
+			}
+
+			assert.Equal(t, test.want, state)
+		})
+	}
+}
+
 // Benchmark that runs with parallelism to help find concurrency related
 // issues. To run with parallelism, the 'go test' cpu flag must be set
 // greater than 1, otherwise it just runs concurrently but not in parallel.
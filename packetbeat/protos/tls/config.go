@@ -28,6 +28,7 @@ type tlsConfig struct {
 	IncludeRawCertificates bool     `config:"include_raw_certificates"`
 	IncludeDetailedFields  bool     `config:"include_detailed_fields"`
 	Fingerprints           []string `config:"fingerprints"`
+	KeyLogFile             string   `config:"keylog_file"`
 }
 
 var defaultConfig = tlsConfig{
@@ -0,0 +1,398 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/elastic/beats/v7/libbeat/common/streambuf"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// aeadSuite describes the record protection of a cipher suite that can be
+// decrypted. Only AEAD cipher suites are supported, which covers all of
+// TLS 1.3 and the suites negotiated by default by current TLS 1.2 stacks.
+type aeadSuite struct {
+	keyLen int
+	hash   func() hash.Hash
+	chacha bool
+}
+
+var (
+	aes128GCMSHA256   = aeadSuite{keyLen: 16, hash: sha256.New}
+	aes256GCMSHA384   = aeadSuite{keyLen: 32, hash: sha512.New384}
+	chacha20Poly1305  = aeadSuite{keyLen: chacha20poly1305.KeySize, hash: sha256.New, chacha: true}
+	errDecryptFailed  = errors.New("record decryption failed")
+	errInvalidPadding = errors.New("invalid TLS 1.3 record padding")
+)
+
+var tls12Suites = map[cipherSuite]aeadSuite{
+	0x009C: aes128GCMSHA256,  // TLS_RSA_WITH_AES_128_GCM_SHA256
+	0x009D: aes256GCMSHA384,  // TLS_RSA_WITH_AES_256_GCM_SHA384
+	0x009E: aes128GCMSHA256,  // TLS_DHE_RSA_WITH_AES_128_GCM_SHA256
+	0x009F: aes256GCMSHA384,  // TLS_DHE_RSA_WITH_AES_256_GCM_SHA384
+	0x00A2: aes128GCMSHA256,  // TLS_DHE_DSS_WITH_AES_128_GCM_SHA256
+	0x00A3: aes256GCMSHA384,  // TLS_DHE_DSS_WITH_AES_256_GCM_SHA384
+	0x00A8: aes128GCMSHA256,  // TLS_PSK_WITH_AES_128_GCM_SHA256
+	0x00A9: aes256GCMSHA384,  // TLS_PSK_WITH_AES_256_GCM_SHA384
+	0x00AA: aes128GCMSHA256,  // TLS_DHE_PSK_WITH_AES_128_GCM_SHA256
+	0x00AB: aes256GCMSHA384,  // TLS_DHE_PSK_WITH_AES_256_GCM_SHA384
+	0x00AC: aes128GCMSHA256,  // TLS_RSA_PSK_WITH_AES_128_GCM_SHA256
+	0x00AD: aes256GCMSHA384,  // TLS_RSA_PSK_WITH_AES_256_GCM_SHA384
+	0xC02B: aes128GCMSHA256,  // TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+	0xC02C: aes256GCMSHA384,  // TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	0xC02F: aes128GCMSHA256,  // TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	0xC030: aes256GCMSHA384,  // TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+	0xCCA8: chacha20Poly1305, // TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256
+	0xCCA9: chacha20Poly1305, // TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAA: chacha20Poly1305, // TLS_DHE_RSA_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAB: chacha20Poly1305, // TLS_PSK_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAC: chacha20Poly1305, // TLS_ECDHE_PSK_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAD: chacha20Poly1305, // TLS_DHE_PSK_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAE: chacha20Poly1305, // TLS_RSA_PSK_WITH_CHACHA20_POLY1305_SHA256
+}
+
+var tls13Suites = map[cipherSuite]aeadSuite{
+	0x1301: aes128GCMSHA256,  // TLS_AES_128_GCM_SHA256
+	0x1302: aes256GCMSHA384,  // TLS_AES_256_GCM_SHA384
+	0x1303: chacha20Poly1305, // TLS_CHACHA20_POLY1305_SHA256
+}
+
+func (s aeadSuite) newAEAD(key []byte) (cipher.AEAD, error) {
+	if s.chacha {
+		return chacha20poly1305.New(key)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// fixedIVLen is the length of the implicit part of the nonce. TLS 1.2
+// AES-GCM records carry the remaining 8 bytes explicitly.
+func (s aeadSuite) fixedIVLen(tls13 bool) int {
+	if tls13 || s.chacha {
+		return 12
+	}
+	return 4
+}
+
+// halfConn holds the decryption state for one direction of a connection.
+type halfConn struct {
+	suite aeadSuite
+	tls13 bool
+	aead  cipher.AEAD
+	iv    []byte
+	seq   uint64
+
+	// TLS 1.3 only. The handshake is protected with separate keys, and
+	// applicationSecret is switched to after the Finished message.
+	handshake         bool
+	secret            []byte
+	applicationSecret []byte
+	handshakeBuf      []byte
+
+	// Application protocol, as negotiated in the encrypted extensions.
+	alpn   string
+	failed bool
+	logger *logp.Logger
+}
+
+// decrypter decrypts the records of a TLS session.
+type decrypter struct {
+	// halves is indexed by TCP direction.
+	halves    [2]*halfConn
+	serverDir uint8
+
+	// Application protocol negotiated in the server hello (TLS 1.2).
+	alpn string
+}
+
+// newDecrypter derives the record protection keys for a session. clientDir
+// is the TCP direction of the client to server stream.
+func newDecrypter(
+	clientHello, serverHello *helloMessage,
+	secrets *sessionSecrets,
+	clientDir uint8,
+	logger *logp.Logger,
+) (*decrypter, error) {
+	suiteID := serverHello.selected.cipherSuite
+	var client, server *halfConn
+	if serverHello.isTLS13() {
+		suite, found := tls13Suites[suiteID]
+		if !found {
+			return nil, fmt.Errorf("unsupported TLS 1.3 cipher suite %s", suiteID)
+		}
+		if secrets.clientHandshakeSecret == nil || secrets.serverHandshakeSecret == nil ||
+			secrets.clientApplicationSecret == nil || secrets.serverApplicationSecret == nil {
+			return nil, errors.New("incomplete TLS 1.3 secrets in key log")
+		}
+		client = &halfConn{suite: suite, tls13: true, handshake: true, applicationSecret: secrets.clientApplicationSecret}
+		server = &halfConn{suite: suite, tls13: true, handshake: true, applicationSecret: secrets.serverApplicationSecret}
+		if err := client.setTrafficSecret(secrets.clientHandshakeSecret); err != nil {
+			return nil, err
+		}
+		if err := server.setTrafficSecret(secrets.serverHandshakeSecret); err != nil {
+			return nil, err
+		}
+	} else {
+		suite, found := tls12Suites[suiteID]
+		if !found {
+			return nil, fmt.Errorf("unsupported TLS 1.2 cipher suite %s", suiteID)
+		}
+		if secrets.masterSecret == nil {
+			return nil, errors.New("no master secret in key log")
+		}
+		ivLen := suite.fixedIVLen(false)
+		seed := make([]byte, 0, len(serverHello.random)+len(clientHello.random))
+		seed = append(seed, serverHello.random...)
+		seed = append(seed, clientHello.random...)
+		keys := prf12(suite.hash, secrets.masterSecret, "key expansion", seed, 2*suite.keyLen+2*ivLen)
+
+		var err error
+		client = &halfConn{suite: suite}
+		if client.aead, err = suite.newAEAD(keys[:suite.keyLen]); err != nil {
+			return nil, err
+		}
+		keys = keys[suite.keyLen:]
+		server = &halfConn{suite: suite}
+		if server.aead, err = suite.newAEAD(keys[:suite.keyLen]); err != nil {
+			return nil, err
+		}
+		keys = keys[suite.keyLen:]
+		client.iv, server.iv = keys[:ivLen], keys[ivLen:]
+	}
+
+	client.logger, server.logger = logger, logger
+	d := &decrypter{
+		serverDir: 1 - clientDir,
+		alpn:      serverHello.extensions.selectedProtocol(),
+	}
+	d.halves[clientDir] = client
+	d.halves[d.serverDir] = server
+	return d, nil
+}
+
+// applicationProtocol returns the ALPN protocol selected by the server, if
+// it is known yet. For TLS 1.3 this is part of the encrypted handshake.
+func (d *decrypter) applicationProtocol() string {
+	if alpn := d.halves[d.serverDir].alpn; alpn != "" {
+		return alpn
+	}
+	return d.alpn
+}
+
+// decrypt consumes all complete records in buf and returns the application
+// data they contain.
+func (d *decrypter) decrypt(dir uint8, buf *streambuf.Buffer) ([]byte, error) {
+	hc := d.halves[dir]
+	var data []byte
+	for buf.Avail(recordHeaderSize) {
+		header, err := readRecordHeader(buf)
+		if err != nil || !header.isValid() {
+			hc.failed = true
+			return data, errors.New("invalid TLS record header")
+		}
+		limit := recordHeaderSize + int(header.length)
+		if !buf.Avail(limit) {
+			break
+		}
+		record := buf.Bytes()[:limit]
+		_ = buf.Advance(limit)
+		if hc.failed || header.recordType == recordTypeChangeCipherSpec {
+			continue
+		}
+
+		typ, content, err := hc.decryptRecord(header, record)
+		if err != nil {
+			if hc.tls13 && hc.handshake && errors.Is(err, errDecryptFailed) {
+				// Rejected 0-RTT data is protected with keys not
+				// derived from the handshake secrets.
+				continue
+			}
+			hc.failed = true
+			return data, err
+		}
+		switch typ {
+		case recordTypeApplicationData:
+			data = append(data, content...)
+		case recordTypeHandshake:
+			if err = hc.handleHandshake(content); err != nil {
+				hc.failed = true
+				return data, err
+			}
+		}
+	}
+	return data, nil
+}
+
+func (hc *halfConn) decryptRecord(header *recordHeader, record []byte) (recordType, []byte, error) {
+	payload := record[recordHeaderSize:]
+	nonce := make([]byte, hc.aead.NonceSize())
+	var additionalData []byte
+	if hc.suite.fixedIVLen(hc.tls13) == len(nonce) {
+		copy(nonce, hc.iv)
+		for i := 0; i < 8; i++ {
+			nonce[len(nonce)-1-i] ^= byte(hc.seq >> (8 * i))
+		}
+	} else {
+		explicit := len(nonce) - len(hc.iv)
+		if len(payload) < explicit {
+			return 0, nil, errDecryptFailed
+		}
+		copy(nonce, hc.iv)
+		copy(nonce[len(hc.iv):], payload[:explicit])
+		payload = payload[explicit:]
+	}
+	if len(payload) < hc.aead.Overhead() {
+		return 0, nil, errDecryptFailed
+	}
+	if hc.tls13 {
+		additionalData = record[:recordHeaderSize]
+	} else {
+		additionalData = make([]byte, 13)
+		binary.BigEndian.PutUint64(additionalData, hc.seq)
+		copy(additionalData[8:], record[:3])
+		binary.BigEndian.PutUint16(additionalData[11:], uint16(len(payload)-hc.aead.Overhead()))
+	}
+
+	plaintext, err := hc.aead.Open(nil, nonce, payload, additionalData)
+	if err != nil {
+		return 0, nil, errDecryptFailed
+	}
+	hc.seq++
+
+	if !hc.tls13 {
+		return header.recordType, plaintext, nil
+	}
+	// TLS 1.3 hides the real content type at the end of the plaintext,
+	// followed by optional zero padding.
+	for i := len(plaintext) - 1; i >= 0; i-- {
+		if plaintext[i] != 0 {
+			return recordType(plaintext[i]), plaintext[:i], nil
+		}
+	}
+	return 0, nil, errInvalidPadding
+}
+
+// handleHandshake tracks the TLS 1.3 handshake messages that change the
+// record protection keys.
+func (hc *halfConn) handleHandshake(content []byte) error {
+	if !hc.tls13 {
+		return nil
+	}
+	hc.handshakeBuf = append(hc.handshakeBuf, content...)
+	if len(hc.handshakeBuf) > maxHandshakeSize+handshakeHeaderSize {
+		return errors.New("handshake message too large")
+	}
+	var newSecret []byte
+	for len(hc.handshakeBuf) >= handshakeHeaderSize {
+		length := int(hc.handshakeBuf[1])<<16 | int(binary.BigEndian.Uint16(hc.handshakeBuf[2:]))
+		if len(hc.handshakeBuf) < handshakeHeaderSize+length {
+			break
+		}
+		switch handshakeType(hc.handshakeBuf[0]) {
+		case encryptedExtensions:
+			msg := streambuf.NewFixed(hc.handshakeBuf[:handshakeHeaderSize+length])
+			extensions := ParseExtensions(*newBufferView(msg, handshakeHeaderSize, length), hc.logger)
+			hc.alpn = extensions.selectedProtocol()
+		case finished:
+			if hc.handshake {
+				hc.handshake = false
+				newSecret = hc.applicationSecret
+			}
+		case keyUpdate:
+			if !hc.handshake {
+				newSecret = expandLabel(hc.suite.hash, hc.secret, "traffic upd", hc.suite.hash().Size())
+			}
+		}
+		hc.handshakeBuf = hc.handshakeBuf[handshakeHeaderSize+length:]
+	}
+	if len(hc.handshakeBuf) == 0 {
+		hc.handshakeBuf = nil
+	}
+	// Keys change at the record boundary after the message.
+	if newSecret != nil {
+		return hc.setTrafficSecret(newSecret)
+	}
+	return nil
+}
+
+// setTrafficSecret derives the TLS 1.3 record protection keys for secret.
+func (hc *halfConn) setTrafficSecret(secret []byte) error {
+	aead, err := hc.suite.newAEAD(expandLabel(hc.suite.hash, secret, "key", hc.suite.keyLen))
+	if err != nil {
+		return err
+	}
+	hc.aead = aead
+	hc.iv = expandLabel(hc.suite.hash, secret, "iv", hc.suite.fixedIVLen(true))
+	hc.secret = secret
+	hc.seq = 0
+	return nil
+}
+
+// prf12 is the TLS 1.2 pseudo-random function (RFC 5246, section 5).
+func prf12(h func() hash.Hash, secret []byte, label string, seed []byte, length int) []byte {
+	labelAndSeed := make([]byte, 0, len(label)+len(seed))
+	labelAndSeed = append(labelAndSeed, label...)
+	labelAndSeed = append(labelAndSeed, seed...)
+
+	result := make([]byte, 0, length+h().Size())
+	mac := hmac.New(h, secret)
+	mac.Write(labelAndSeed)
+	a := mac.Sum(nil)
+	for len(result) < length {
+		mac.Reset()
+		mac.Write(a)
+		mac.Write(labelAndSeed)
+		result = mac.Sum(result)
+		mac.Reset()
+		mac.Write(a)
+		a = mac.Sum(nil)
+	}
+	return result[:length]
+}
+
+// expandLabel is HKDF-Expand-Label with an empty context (RFC 8446,
+// section 7.1).
+func expandLabel(h func() hash.Hash, secret []byte, label string, length int) []byte {
+	label = "tls13 " + label
+	info := make([]byte, 0, 4+len(label))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, byte(len(label)))
+	info = append(info, label...)
+	info = append(info, 0)
+	out, err := hkdf.Expand(h, secret, string(info), length)
+	if err != nil {
+		// Only possible if length exceeds 255 hash blocks.
+		panic(err)
+	}
+	return out
+}
@@ -0,0 +1,315 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package tls
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/http"
+	_ "github.com/elastic/beats/v7/packetbeat/protos/http2" // Registers the http2 protocol name.
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+const (
+	testRequest  = "GET /index.html HTTP/1.1\r\nHost: example.org\r\n\r\n"
+	testResponse = "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nContent-Length: 5\r\n\r\nhello"
+)
+
+// segment is a chunk of TCP payload sent in one direction.
+type segment struct {
+	dir  uint8
+	data []byte
+}
+
+type recordingConn struct {
+	net.Conn
+	dir      uint8
+	mu       *sync.Mutex
+	segments *[]segment
+}
+
+func (c recordingConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	*c.segments = append(*c.segments, segment{dir: c.dir, data: bytes.Clone(b)})
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+// recordSession runs a TLS session in memory where the client sends
+// testRequest and the server answers with testResponse. It returns the
+// encrypted segments exchanged and the key log written by the client.
+func recordSession(t *testing.T, version uint16, suite uint16, alpn string) ([]segment, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.org"},
+		DNSNames:     []string{"example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		segments []segment
+		keyLog   bytes.Buffer
+	)
+	clientEnd, serverEnd := net.Pipe()
+	defer clientEnd.Close()
+	defer serverEnd.Close()
+
+	serverConfig := &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MaxVersion:   version,
+	}
+	clientConfig := &cryptotls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // Self-signed test certificate.
+		ServerName:         "example.org",
+		MinVersion:         version,
+		MaxVersion:         version,
+		KeyLogWriter:       &keyLog,
+	}
+	if suite != 0 {
+		clientConfig.CipherSuites = []uint16{suite}
+	}
+	if alpn != "" {
+		serverConfig.NextProtos = []string{alpn}
+		clientConfig.NextProtos = []string{alpn}
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		server := cryptotls.Server(recordingConn{serverEnd, tcp.TCPDirectionReverse, &mu, &segments}, serverConfig)
+		request := make([]byte, len(testRequest))
+		if _, err := io.ReadFull(server, request); err != nil {
+			serverErr <- err
+			return
+		}
+		_, err := server.Write([]byte(testResponse))
+		serverErr <- err
+	}()
+
+	client := cryptotls.Client(recordingConn{clientEnd, tcp.TCPDirectionOriginal, &mu, &segments}, clientConfig)
+	_, err = client.Write([]byte(testRequest))
+	require.NoError(t, err)
+	response := make([]byte, len(testResponse))
+	_, err = io.ReadFull(client, response)
+	require.NoError(t, err)
+	require.NoError(t, <-serverErr)
+	require.Equal(t, testResponse, string(response))
+
+	mu.Lock()
+	defer mu.Unlock()
+	return segments, keyLog.Bytes()
+}
+
+// recordingPlugin is an inner plugin that stores the cleartext it receives.
+type recordingPlugin struct {
+	data [2][]byte
+	fins int
+}
+
+func (p *recordingPlugin) GetPorts() []int { return nil }
+
+func (p *recordingPlugin) Parse(pkt *protos.Packet, _ *common.TCPTuple, dir uint8, _ protos.ProtocolData) protos.ProtocolData {
+	p.data[dir] = append(p.data[dir], pkt.Payload...)
+	return p
+}
+
+func (p *recordingPlugin) ReceivedFin(_ *common.TCPTuple, _ uint8, private protos.ProtocolData) protos.ProtocolData {
+	p.fins++
+	return private
+}
+
+func (p *recordingPlugin) GapInStream(_ *common.TCPTuple, _ uint8, _ int, private protos.ProtocolData) (protos.ProtocolData, bool) {
+	return private, false
+}
+
+func (p *recordingPlugin) ConnectionTimeout() time.Duration { return 0 }
+
+type testProtocols struct {
+	protos.Protocols
+	tcp map[protos.Protocol]protos.TCPPlugin
+}
+
+func (p testProtocols) GetTCP(proto protos.Protocol) protos.TCPPlugin {
+	return p.tcp[proto]
+}
+
+func testDecryptInit(t *testing.T, keyLog []byte, inner map[string]protos.TCPPlugin) (*eventStore, *tlsPlugin) {
+	path := filepath.Join(t.TempDir(), "keys.log")
+	require.NoError(t, os.WriteFile(path, keyLog, 0o600))
+
+	results, plugin := testInit()
+	plugin.keyLog = newKeyLog(path, logptest.NewTestingLogger(t, ""))
+	protocols := testProtocols{tcp: map[protos.Protocol]protos.TCPPlugin{}}
+	for name, p := range inner {
+		protocols.tcp[protos.Lookup(name)] = p
+	}
+	plugin.Link(protocols)
+	return results, plugin
+}
+
+// replay feeds the segments to the plugin, split in chunks of at most
+// chunkSize bytes if chunkSize is positive.
+func replay(plugin *tlsPlugin, segments []segment, chunkSize int) protos.ProtocolData {
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	for _, seg := range segments {
+		data := seg.data
+		for len(data) > 0 {
+			n := len(data)
+			if chunkSize > 0 && n > chunkSize {
+				n = chunkSize
+			}
+			private = plugin.Parse(&protos.Packet{Ts: time.Now(), Payload: data[:n]}, tuple, seg.dir, private)
+			data = data[n:]
+		}
+	}
+	for _, dir := range []uint8{tcp.TCPDirectionOriginal, tcp.TCPDirectionReverse} {
+		private = plugin.ReceivedFin(tuple, dir, private)
+	}
+	return private
+}
+
+func TestDecryptTLS12(t *testing.T) {
+	for name, suite := range map[string]uint16{
+		"aes128-gcm": cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		"aes256-gcm": cryptotls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		"chacha20":   cryptotls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	} {
+		t.Run(name, func(t *testing.T) {
+			segments, keyLog := recordSession(t, cryptotls.VersionTLS12, suite, "")
+			inner := &recordingPlugin{}
+			results, plugin := testDecryptInit(t, keyLog, map[string]protos.TCPPlugin{"http": inner})
+
+			replay(plugin, segments, 0)
+			assert.Equal(t, testRequest, string(inner.data[tcp.TCPDirectionOriginal]))
+			assert.Equal(t, testResponse, string(inner.data[tcp.TCPDirectionReverse]))
+			assert.Equal(t, 2, inner.fins)
+			require.Len(t, results.events, 1)
+			version, _ := results.events[0].Fields.GetValue("tls.version")
+			assert.Equal(t, "1.2", version)
+		})
+	}
+}
+
+func TestDecryptTLS13(t *testing.T) {
+	for _, chunkSize := range []int{0, 1, 100} {
+		segments, keyLog := recordSession(t, cryptotls.VersionTLS13, 0, "")
+		inner := &recordingPlugin{}
+		results, plugin := testDecryptInit(t, keyLog, map[string]protos.TCPPlugin{"http": inner})
+
+		replay(plugin, segments, chunkSize)
+		assert.Equal(t, testRequest, string(inner.data[tcp.TCPDirectionOriginal]), "chunk size %d", chunkSize)
+		assert.Equal(t, testResponse, string(inner.data[tcp.TCPDirectionReverse]), "chunk size %d", chunkSize)
+		require.Len(t, results.events, 1)
+		version, _ := results.events[0].Fields.GetValue("tls.version")
+		assert.Equal(t, "1.3", version)
+	}
+}
+
+func TestDecryptALPN(t *testing.T) {
+	segments, keyLog := recordSession(t, cryptotls.VersionTLS13, 0, "h2")
+	h1, h2 := &recordingPlugin{}, &recordingPlugin{}
+	_, plugin := testDecryptInit(t, keyLog, map[string]protos.TCPPlugin{"http": h1, "http2": h2})
+
+	replay(plugin, segments, 0)
+	assert.Empty(t, h1.data[tcp.TCPDirectionOriginal])
+	assert.Equal(t, testRequest, string(h2.data[tcp.TCPDirectionOriginal]))
+	assert.Equal(t, testResponse, string(h2.data[tcp.TCPDirectionReverse]))
+}
+
+func TestDecryptUnknownSession(t *testing.T) {
+	for _, version := range []uint16{cryptotls.VersionTLS12, cryptotls.VersionTLS13} {
+		segments, _ := recordSession(t, version, 0, "")
+		_, keyLog := recordSession(t, version, 0, "")
+		inner := &recordingPlugin{}
+		results, plugin := testDecryptInit(t, keyLog, map[string]protos.TCPPlugin{"http": inner})
+
+		replay(plugin, segments, 0)
+		assert.Empty(t, inner.data[tcp.TCPDirectionOriginal])
+		assert.Empty(t, inner.data[tcp.TCPDirectionReverse])
+		assert.Zero(t, inner.fins)
+		assert.Len(t, results.events, 1)
+	}
+}
+
+func TestDecryptWithoutInnerPlugin(t *testing.T) {
+	segments, keyLog := recordSession(t, cryptotls.VersionTLS13, 0, "")
+	results, plugin := testDecryptInit(t, keyLog, nil)
+
+	private := replay(plugin, segments, 0)
+	conn := private.(*tlsConnectionData)
+	assert.Nil(t, conn.decrypter)
+	assert.Len(t, results.events, 1)
+}
+
+func TestDecryptHTTPTransaction(t *testing.T) {
+	segments, keyLog := recordSession(t, cryptotls.VersionTLS13, 0, "")
+	httpResults := &eventStore{}
+	httpPlugin, err := http.New(true, httpResults.publish, &procs.ProcessesWatcher{}, nil, logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	_, plugin := testDecryptInit(t, keyLog, map[string]protos.TCPPlugin{"http": httpPlugin.(protos.TCPPlugin)})
+
+	replay(plugin, segments, 0)
+	require.Len(t, httpResults.events, 1)
+	fields := httpResults.events[0].Fields
+	for field, expected := range map[string]interface{}{
+		"type":                      "http",
+		"url.path":                  "/index.html",
+		"http.request.method":       "GET",
+		"http.response.status_code": 200,
+		"http.response.body.bytes":  5,
+		"status":                    "OK",
+		"network.protocol":          "http",
+		"destination.port":          27017,
+	} {
+		actual, err := fields.GetValue(field)
+		if assert.NoError(t, err, field) {
+			assert.EqualValues(t, expected, actual, field)
+		}
+	}
+}
@@ -49,6 +49,10 @@ const (
 	ExtensionSupportedGroups ExtensionID = 10
 	// ExtensionEllipticCurvePointsFormats identifies the points formats extension
 	ExtensionEllipticCurvePointsFormats = 11
+	// ExtensionSignatureAlgorithms identifies the signature algorithms extension
+	ExtensionSignatureAlgorithms = 13
+	// ExtensionSupportedVersions identifies the supported versions extension
+	ExtensionSupportedVersions = 43
 )
 
 var extensionMap = map[uint16]extension{
@@ -65,7 +69,7 @@ var extensionMap = map[uint16]extension{
 	10:     {"supported_groups", parseSupportedGroups, true},
 	11:     {"ec_points_formats", parseEcPoints, true},
 	12:     {"srp", parseSrp, false},
-	13:     {"signature_algorithms", parseSignatureSchemes, false},
+	13:     {"signature_algorithms", parseSignatureSchemes, true},
 	16:     {"application_layer_protocol_negotiation", parseALPN, false},
 	35:     {"session_ticket", parseTicket, false},
 	43:     {"supported_versions", parseSupportedVersions, true},
@@ -119,6 +123,14 @@ func ParseExtensions(buffer bufferView, logger *logp.Logger) Extensions {
 	return result
 }
 
+// selectedProtocol returns the application protocol chosen by the server.
+func (ext Extensions) selectedProtocol() string {
+	if protos, ok := ext.Parsed["application_layer_protocol_negotiation"].([]string); ok && len(protos) == 1 {
+		return protos[0]
+	}
+	return ""
+}
+
 func parseExtension(code uint16, buffer bufferView, logger *logp.Logger) (string, interface{}, bool) {
 	if ext, ok := extensionMap[code]; ok {
 		parsed := ext.parser(buffer, logger)
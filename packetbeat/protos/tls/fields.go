@@ -32,5 +32,5 @@ func init() {
 // AssetTls returns asset data.
 // This is the base64 encoded zlib format compressed contents of protos/tls.
 func AssetTls() string {
-	return "eJzsWc1u20YQvuspBu7BCRDLDYIWqA8FAsUHA0ET1El7JFbkiNxmucvsDiXz7YvZJSn+Sg7sKIETniSRnPnm75sZ7QV8wuoKSLkoQRJSYbIAIEkKr+D8Tf0TfHh7e74ASNDFVhYkjb6CPxcAAN1HLlyBsdzIGHCLmmAjUSVuuYD605V/4wK0yNHr9N8BqCrwClJryqL+pfs8X79AigRWJmA2QJl0sMtQww6hLFIrEgQycL26hZfL39uXGkWxkqip/XlK35TOroi73379o3djTghfCW5EqSiqBcJGKIeDZ6aUdRVu0Tpp9Oh+o/cTVjtjk4n7vRj9E8Sw19gE2BibC1pOvIZ3Ii846K8Ws6CkcyXaZWHNVup4aNIXg3tfywFjwWLKOHeSMqkhNqUmWy3nobhy/R/G9E2wPCgZHdot2p/J+DMZHwdLg6HD3n2dw6Q4lhCHMm86EQ7ZNtExuteHDBuhoZiQ+wgU1pCJjYLSYTJMkDY5zvnRl8tX54tJsBZdmXvNUY6UmSG0B8C+CVAdOo88Ew7WiDqoxOSFv1vqBK2qpE4h6A/WwDuNYDYjmWcyOeOS8A5oJN+84Rw4Ixl/QtrfDt8B7wg1P7ec9kBofFGMlrgrC8LI4ucSHeG0M9bGKBTD+B5xxr8ZUoa29gizm3dIq8nfCFCYGEVJGWrycECSQzX2RenYa6J5q2PAjKUmdkVk0RVGj9jtgenJMVXE2Sk0vFvdvm8sO+z0DJUy92bpEzHw2EK4RxGuK9hlMs66gdxJl6EDGpoYrtjkealDiJPScjB9n6zT+gCVWqETk38dO//2siERJHwpsmEjY8lAihotQ+ebqGNbebGs/FATCLZFcgrdI6D/qOXnEkGX+ZqLzYBMuIw2VY+KmCF8mIwN5ZBInc7ESGuMqWkuneAe7HRFYSxhEsUmL2xtcqA39/WyU0nnK7CjtOZU103KGp2bGikAbhEhIyrc1eXlbrdbSqHF0tj0UjgnU52jJnfJGi5Y9IVMBt+Wdxnlat43LRvPO2Kq9EduYIM9e3Qkgh8tkn3ONpEay5qjEhgNoRF/iaROuFCn2QXuEcORAW/raGXGEatwY591wYiiUDWCSIkKbdQUY6QxNSQfFdx0kvHVwO7gufB4WnLoJRuP/VIpz22G+WSibGCCH0LvPoE5qFPKGkJv+CFofwFy06bUCx4xhAbMC6rAkZ1jDA/QgEi23I8dNvUWSMcLdsec0PJH3XCmauWxHVHHlWm+0QqUCXpQNFtDfE2f0IxrpfjhGFal3SKsuDuZ1Ioiq+DZ9Wr1HGJ/4yAu2BswZJTDZstUCyotRkKlxkrK8hOa3mqHvfYQyVxUsEaOG0gNiUwlCTVveyPnWLpiHBVGanJR2FW/XZifXa+eg8dSr81uCTeBudHT0ryx6OX13h2lfyw0FMIeT3sSVLpmizjojbleN/LFrZfZTNWQ13+g7NeJ6XZ+uNN1QTOe2YfuF7wRaG7S/GLLrz0jlvBa7UTl4IwXk7PAtujm6wv6eyvPbWgjmUQ8+UTKU/lRE1xm7FxMJg1QvRbRDFlkS7+2tTjmqqQP2hseHRyCHgi3Hn15DtwvhN0hqRl+pxe0IeSo1D7lk+h0Vd0B60twh5bDsCFowDAdvxfcS9co5nbMen57Qjum94Z0YS8Lcezui1Mib/wLLCmTacbU0SgY9bb6bwltCPAuRpxdiqgDc/8HhtRdrvRef6IrrMJ4ern7elkx3ulaHIPwbazJW6aalNduSV8WsR9idT/pejrfuO+znp52I/yrFolJVzH0V7/va7f7yKXPq5jWptQxBvoUgy3PbzQ8FjfRmZXXj1ozvAx2wu9wteuErrPdsWfCMpD88PN9TZ1Per6f+d9/DHn6eGMWeHOwIXrzZn/Sb5XDTjhv25OfOrvHSXEm5PRxoLBWVIsDsFf8artvtN2vSdijRz4Th1uPj+beB1BCoaWIlQ055AHHT691AM4Nn4TU4RwlTHteYVhBGSxu0Vb1jxZjlFsmv/8DAAD//6l0gvo="
+	return "eJzsWV9v28gRf+enGLgPuQNiRpQsxfFDgcAXoCmCXlDn2r4RK+6Q3Au5y9tdSta3L2bJpSmJpOQmdnq5C/Ng/pv5zb/fzFCX8Bl3N2ALE3O0TBTIAwArbIE38OKn9hJ8+nD3IgDgaBItKiuUvIG/BgAA/UcuTYWJSEUCuEFpIRVYcBMG0P514964BMlKdDrdOYDdVXgDmVZ11V7pP0/HXyBDC1pwUCnYXBjY5ihhi1BXmWYcwSp4d3sHUbjqXvKKkkKgtN3lIX1DOvsifmVXe9e9jM+42yrND+4NuKl//P3tFaRCZqgrLaRtTEK4dTD/hkWhwoN38J6VFUXERgseLaNVPo+vOWPpKlrOX7+OYlyu5q8xZXO2joIxC+6/qgn/GbIhQW0pAZhFUGmKGjmsd80tZ96oZfNotYpWV7PlIonivZPFjM9nV2wWLZcjtt0vZ28O5A6HmA6OKasLG7fhhpQVBg+eGUqFvsINaiOUPLo/7dMjv/6rEUPOIxMgVbpkRy7ac9MiGAUljKlRh5VWGyET/FJwH1s5oDRozISSsBU2FxISVUurd+E4FFOvf8XEfhMsX0QVBvUGdXAI85FUYYLzbT1daHdDhXbngJ4ki9l8NoujxSyK54srZKvrN9Hy+v+VIRrnj5rDFq8X7E16vUhW63i+ZutoeTV7s7haxtfrVbKaRWy+XF7/yRB/MsQJhvAYegPPdFKcSoihZJhOhCnbThTWpxy9UF9Tnz7cQaWVVYkqoDbIR2voBT0ahYsXwSBYjaYunea4RJsr/vVgv0/bGjcOec4MrBFloxL5S3e3lhx1sRMyg0Z/Yw38LGmcOJJ5IfgFNU2aPTvJ73+ijnVhRfIZ7cPt5hzw3qKk58JhDzSzYtxjqVjjbzUai8POWCtVIJOPc8a/c7Q56h7rOYd0mnoDEw22rLY5SuvggLAGi2Nf1Ia8xvxbPQNGLFWJqWKNplLS4NeLM6UnxbRwjM8k/Hx799FbNu30nLrZ2Sw9VHSnC++UdWdYeE4RrnewzUWS9wO5FSZHA/bQxOZIVFnW0gUMeK0pmG7PadM6DEYN1UxyVT6Nnf90soEzy1wp+la9Z6xVkKFETdDpJspE75z7SPkE8ta2WPCnQf+LFL/VCLIu11RsCgSnMkr9uOG0O4ag80Tpphy4kNmgvERJiYn1zaUX3Ckj66pS2iKPE1VWujW5oTfzNHZTdhbCuArsKW051fSTskVnhkYKgDtEyK2tzM2rV9vtNhRMslDp7BUzRmSyRGnNK9JwSaIvBT84C+9zWxbjvunYeNwRQ6V/5AYy2LFHTyIV40bwh5z1kfISTlPJXhgdTcd0EgvJqVCH2eWcGB4Z8KGNVq6MJRUmmATDqqpoEcQF26GOfTHGEjNlxVcFN5xkdHjYPTyXDk9HDnvJRp9tRFE4blPEJ+G0mZ4fmt79DOagzGzuCd3zQ6P9JYi0S6mXNGIwCVhWdgfG6jHGoP/UwPmG+rFBX28N6TjB5pQTOv5oG455Bke0cSWa91rB5sx+UTQ7Q1xNP6MZ74qCgp/Aba03CLfUnVSmWZXv4Id3t7c/QuJuTOKCBwMOGWXabJFJZmuNMSsypYXNy2c0vdMOD9qbSJZsB2ukKgQhgYtMWFaMyuvknEpXTOJKCWlN3Oyq3y7MP7y7/REclnZtNiG8b5ibxrAcg0FpZCw6eXvvHqV/wiRUTJ9Oe8tsbfwWMaJzutcd+eLOyfRTNZTtV63xjyjndLo+aMIz+tB5wTsCTU2aXuz4dc+IEN4WW7YzcEGLyUXDtmjG66sPt53bUMeCxzT5xIWj8glohOQGTK60fYwBxV6L8EOW1TUtiA84zFmgneHx5BD0hXDb0Vel/YWwPyT54ddHIQimIMe1dCnP4+B/T4xHVnUPrCvBLWqEAlMLHgzR8UdGvXSN7NAED72pi+9px3TeEIYYnLdx7O+LXkz/33vXtSneuchyNLZTcNTb2s8SUlnA+wRxdCmyPZhtFiGnltLjSuf1MBj14+95hS0wGV7unsYcyorjna7DcRC+VKuyY6pBed2W9LiI/SFW9wfqCR7bsh+/no437qmm/W02wn+0IpH3FcP+6hdMAn7u3e4XYklaxaRUNf1Q4OiTdanUfh/eiqKgsdhHZ1TeftT88HKwE4YnfOBJN24J1Dy9H3qh6213RIDNMsD/8PN9S53f9Xw/8t3/3J83RoH7HzbY3rxp9s3xymHLjLPtu586e86Ik5yJQ382BjCt2S6YgH1Lr3b7Rtf9fMI+KJn+neVp0bQDxGk0rEBtY1JmgvPjeSKOb2UDHBIlLRPSfWtspz2n0JWicx1uUO/aixoTFBvkYfDfAQBP6FJF"
 }
@@ -0,0 +1,139 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/streambuf"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// Hello is a TLS ClientHello or ServerHello message. It allows analyzers
+// for protocols that carry the TLS handshake outside of TLS records, like
+// QUIC, to report the same details as the TLS analyzer.
+type Hello struct {
+	msg      *helloMessage
+	isClient bool
+}
+
+// ParseHello parses a ClientHello or ServerHello handshake message,
+// starting with its 4 bytes handshake header.
+func ParseHello(data []byte, logger *logp.Logger) (hello *Hello, err error) {
+	buf := streambuf.NewFixed(data)
+	header, err := readHandshakeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if handshakeHeaderSize+header.length > len(data) {
+		return nil, errors.New("truncated handshake message")
+	}
+
+	// Recover from any bufferView.subview out of bounds errors.
+	defer func() {
+		r := recover()
+		switch r := r.(type) {
+		case nil:
+		case bufferViewError:
+			hello, err = nil, r
+		default:
+			panic(r)
+		}
+	}()
+
+	view := bufferView{buf, handshakeHeaderSize, handshakeHeaderSize + header.length}
+	var msg *helloMessage
+	switch header.handshakeType {
+	case clientHello:
+		msg = parseClientHello(view, logger)
+	case serverHello:
+		msg = parseServerHello(view, logger)
+	default:
+		return nil, fmt.Errorf("not a hello message (type %d)", header.handshakeType)
+	}
+	if msg == nil {
+		return nil, errors.New("invalid hello message")
+	}
+	return &Hello{msg: msg, isClient: header.handshakeType == clientHello}, nil
+}
+
+// IsClient returns whether this is a ClientHello.
+func (h *Hello) IsClient() bool {
+	return h.isClient
+}
+
+// ServerName returns the server name requested by the client.
+func (h *Hello) ServerName() string {
+	if list, ok := h.msg.extensions.Parsed["server_name_indication"].([]string); ok && len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// ALPN returns the application protocols offered by the client, or the one
+// selected by the server.
+func (h *Hello) ALPN() []string {
+	list, _ := h.msg.extensions.Parsed["application_layer_protocol_negotiation"].([]string)
+	return list
+}
+
+// SupportedCiphers returns the cipher suites offered by the client.
+func (h *Hello) SupportedCiphers() []string {
+	return h.msg.supportedCiphers()
+}
+
+// Cipher returns the cipher suite selected by the server.
+func (h *Hello) Cipher() string {
+	if h.isClient {
+		return ""
+	}
+	return h.msg.selected.cipherSuite.String()
+}
+
+// Version returns the version selected by the server. For a ClientHello it
+// is the highest version offered.
+func (h *Hello) Version() ProtocolVersion {
+	if h.isClient {
+		return h.msg.highestVersion().GetProtocolVersion()
+	}
+	return h.msg.negotiatedVersion().GetProtocolVersion()
+}
+
+// JA3 returns the JA3 fingerprint of a ClientHello, or the JA3S
+// fingerprint of a ServerHello.
+func (h *Hello) JA3() string {
+	hash, _ := getJa3Fingerprint(h.msg)
+	return hash
+}
+
+// JA4 returns the JA4 fingerprint of a ClientHello, or the JA4S
+// fingerprint of a ServerHello.
+func (h *Hello) JA4(transport JA4Transport) string {
+	if h.isClient {
+		return getJa4Fingerprint(h.msg, transport)
+	}
+	return getJa4sFingerprint(h.msg, transport)
+}
+
+// Detailed returns the fields reported under tls.detailed.client_hello or
+// tls.detailed.server_hello.
+func (h *Hello) Detailed() mapstr.M {
+	return h.msg.toMap()
+}
@@ -0,0 +1,197 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// JA4Transport is the transport protocol prefix of a JA4 fingerprint.
+type JA4Transport byte
+
+const (
+	JA4TransportTCP  JA4Transport = 't'
+	JA4TransportQUIC JA4Transport = 'q'
+)
+
+const (
+	extensionServerName = 0x0000
+	extensionALPN       = 0x0010
+)
+
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4.md.
+func getJa4Fingerprint(hello *helloMessage, transport JA4Transport) string {
+	var sni byte = 'i'
+	extensions := make([]uint16, 0, len(hello.extensions.InOrder))
+	for _, ext := range hello.extensions.InOrder {
+		switch ext {
+		case extensionServerName:
+			sni = 'd'
+		case extensionALPN:
+		default:
+			extensions = append(extensions, uint16(ext))
+		}
+	}
+	var alpn []string
+	if list, ok := hello.extensions.Parsed["application_layer_protocol_negotiation"].([]string); ok {
+		alpn = list
+	}
+
+	ciphers := make([]uint16, 0, len(hello.supported.cipherSuites))
+	for _, suite := range hello.supported.cipherSuites {
+		if !isGreaseValue(uint16(suite)) {
+			ciphers = append(ciphers, uint16(suite))
+		}
+	}
+	sigAlgs := extractJa3Array(hello.extensions.Raw[ExtensionSignatureAlgorithms], 2)
+
+	a := fmt.Sprintf("%c%s%c%02d%02d%s",
+		transport,
+		ja4Version(hello.highestVersion()),
+		sni,
+		min(len(ciphers), 99),
+		min(len(hello.extensions.InOrder), 99),
+		ja4ALPN(alpn))
+
+	slices.Sort(ciphers)
+	slices.Sort(extensions)
+	c := ja4List(extensions)
+	if len(sigAlgs) > 0 {
+		c += "_" + ja4List(sigAlgs)
+	}
+	return a + "_" + ja4Hash(ja4List(ciphers)) + "_" + ja4Hash(c)
+}
+
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4S.md.
+func getJa4sFingerprint(hello *helloMessage, transport JA4Transport) string {
+	extensions := make([]uint16, len(hello.extensions.InOrder))
+	for idx, ext := range hello.extensions.InOrder {
+		extensions[idx] = uint16(ext)
+	}
+	var alpn []string
+	if list, ok := hello.extensions.Parsed["application_layer_protocol_negotiation"].([]string); ok {
+		alpn = list
+	}
+
+	a := fmt.Sprintf("%c%s%02d%s",
+		transport,
+		ja4Version(hello.negotiatedVersion()),
+		min(len(extensions), 99),
+		ja4ALPN(alpn))
+	return fmt.Sprintf("%s_%04x_%s", a, uint16(hello.selected.cipherSuite), ja4Hash(ja4List(extensions)))
+}
+
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4X.md.
+func getJa4xFingerprint(cert *x509.Certificate) string {
+	oids := func(names []pkix.AttributeTypeAndValue) string {
+		parts := make([]string, len(names))
+		for idx, name := range names {
+			parts[idx] = oidHex(name.Type)
+		}
+		return ja4Hash(strings.Join(parts, ","))
+	}
+	extensions := make([]string, len(cert.Extensions))
+	for idx, ext := range cert.Extensions {
+		extensions[idx] = oidHex(ext.Id)
+	}
+	return oids(cert.Issuer.Names) + "_" + oids(cert.Subject.Names) + "_" + ja4Hash(strings.Join(extensions, ","))
+}
+
+// oidHex returns the hex encoding of the DER contents of an object
+// identifier.
+func oidHex(oid asn1.ObjectIdentifier) string {
+	if len(oid) < 2 {
+		return ""
+	}
+	var der []byte
+	appendArc := func(arc int) {
+		var buf [10]byte
+		n := len(buf) - 1
+		buf[n] = byte(arc & 0x7f)
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			n--
+			buf[n] = byte(arc&0x7f) | 0x80
+		}
+		der = append(der, buf[n:]...)
+	}
+	appendArc(oid[0]*40 + oid[1])
+	for _, arc := range oid[2:] {
+		appendArc(arc)
+	}
+	return hex.EncodeToString(der)
+}
+
+func ja4Version(v tlsVersion) string {
+	switch uint16(v.major)<<8 | uint16(v.minor) {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0002:
+		return "s2"
+	default:
+		return "00"
+	}
+}
+
+// ja4ALPN returns the first and last characters of the first ALPN value.
+func ja4ALPN(alpn []string) string {
+	if len(alpn) == 0 || alpn[0] == "" {
+		return "00"
+	}
+	first, last := alpn[0][0], alpn[0][len(alpn[0])-1]
+	if !isAlphanumeric(first) || !isAlphanumeric(last) {
+		h := hex.EncodeToString([]byte(alpn[0]))
+		return h[:1] + h[len(h)-1:]
+	}
+	return string([]byte{first, last})
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func ja4List(values []uint16) string {
+	parts := make([]string, len(values))
+	for idx, v := range values {
+		parts[idx] = fmt.Sprintf("%04x", v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ja4Hash returns the truncated SHA256 hash used in JA4 fingerprints.
+func ja4Hash(s string) string {
+	if s == "" {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
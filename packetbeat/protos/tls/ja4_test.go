@@ -0,0 +1,191 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// ja4TestHello returns the ClientHello from the example in the JA4
+// technical details.
+func ja4TestHello() *helloMessage {
+	hello := &helloMessage{version: tlsVersion{major: 3, minor: 3}}
+	for _, suite := range []uint16{
+		0x1301, 0x1302, 0x1303, 0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9,
+		0xcca8, 0xc013, 0xc014, 0x009c, 0x009d, 0x002f, 0x0035,
+	} {
+		hello.supported.cipherSuites = append(hello.supported.cipherSuites, cipherSuite(suite))
+	}
+
+	sigAlgs := []byte{0, 16}
+	for _, alg := range []uint16{0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601} {
+		sigAlgs = binary.BigEndian.AppendUint16(sigAlgs, alg)
+	}
+	hello.extensions = Extensions{
+		Parsed: mapstr.M{
+			"application_layer_protocol_negotiation": []string{"h2", "http/1.1"},
+		},
+		Raw: map[ExtensionID][]byte{
+			ExtensionSignatureAlgorithms: sigAlgs,
+			ExtensionSupportedVersions:   {4, 0x03, 0x04, 0x03, 0x03},
+		},
+		InOrder: []ExtensionID{
+			0x001b, 0x0000, 0x0033, 0x0010, 0x4469, 0x0017, 0x002d, 0x000d,
+			0x0005, 0x0023, 0x0012, 0x002b, 0xff01, 0x000b, 0x000a, 0x0015,
+		},
+	}
+	return hello
+}
+
+func TestJa4Fingerprint(t *testing.T) {
+	hello := ja4TestHello()
+	assert.Equal(t, "t13d1516h2_8daaf6152771_e5627efa2ab1", getJa4Fingerprint(hello, JA4TransportTCP))
+	assert.Equal(t, "q13d1516h2_8daaf6152771_e5627efa2ab1", getJa4Fingerprint(hello, JA4TransportQUIC))
+
+	// GREASE cipher suites are ignored.
+	hello.supported.cipherSuites = append([]cipherSuite{0x0a0a}, hello.supported.cipherSuites...)
+	assert.Equal(t, "t13d1516h2_8daaf6152771_e5627efa2ab1", getJa4Fingerprint(hello, JA4TransportTCP))
+
+	// Without SNI, ALPN, signature algorithms and supported_versions.
+	hello.extensions.InOrder = []ExtensionID{0x000a, 0x000b}
+	hello.extensions.Parsed = mapstr.M{}
+	hello.extensions.Raw = map[ExtensionID][]byte{}
+	hello.supported.cipherSuites = nil
+	assert.Equal(t, "t12i000200_000000000000_"+ja4Hash("000a,000b"), getJa4Fingerprint(hello, JA4TransportTCP))
+}
+
+func TestJa4ALPN(t *testing.T) {
+	for alpn, expected := range map[string]string{
+		"":          "00",
+		"h2":        "h2",
+		"http/1.1":  "h1",
+		"h3-29":     "h9",
+		"\xabx\xcd": "ad",
+		"a":         "aa",
+	} {
+		assert.Equal(t, expected, ja4ALPN([]string{alpn}), alpn)
+	}
+	assert.Equal(t, "00", ja4ALPN(nil))
+}
+
+func TestJa4sFingerprint(t *testing.T) {
+	// TLS 1.3 ServerHello from the JA4S technical details.
+	hello := &helloMessage{version: tlsVersion{major: 3, minor: 3}}
+	hello.selected.cipherSuite = 0x1301
+	hello.extensions = Extensions{
+		Parsed:  mapstr.M{},
+		Raw:     map[ExtensionID][]byte{ExtensionSupportedVersions: {0x03, 0x04}},
+		InOrder: []ExtensionID{0x0033, 0x002b},
+	}
+	assert.Equal(t, "t130200_1301_234ea6891581", getJa4sFingerprint(hello, JA4TransportTCP))
+
+	// TLS 1.2 ServerHello, where the extensions are not sorted.
+	hello.selected.cipherSuite = 0xc030
+	hello.extensions = Extensions{
+		Parsed:  mapstr.M{},
+		Raw:     map[ExtensionID][]byte{},
+		InOrder: []ExtensionID{0x0005, 0x0017, 0xff01, 0x0000},
+	}
+	assert.Equal(t, "t120400_c030_4e8089b08790", getJa4sFingerprint(hello, JA4TransportTCP))
+
+	hello.extensions.Parsed["application_layer_protocol_negotiation"] = []string{"h2"}
+	assert.Equal(t, "q1204h2_c030_4e8089b08790", getJa4sFingerprint(hello, JA4TransportQUIC))
+}
+
+func TestJa4xFingerprint(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newCert := func(issuer, subject pkix.Name, parent *x509.Certificate) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Issuer:       issuer,
+			Subject:      subject,
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			SubjectKeyId: []byte{1, 2, 3, 4},
+		}
+		if parent == nil {
+			parent = template
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		return cert
+	}
+
+	// Issuer and subject with the same attributes as the examples in the
+	// JA4X technical details. Only the Subject Key Identifier extension
+	// is present.
+	subject := pkix.Name{
+		Country:            []string{"US"},
+		Province:           []string{"California"},
+		Locality:           []string{"Mountain View"},
+		Organization:       []string{"Example"},
+		OrganizationalUnit: []string{"Engineering"},
+		CommonName:         "example.com",
+	}
+	self := newCert(subject, subject, nil)
+	assert.Equal(t, "2166164053c1_2166164053c1_30d204a01551", getJa4xFingerprint(self))
+
+	ca := newCert(pkix.Name{
+		Country:      []string{"US"},
+		Organization: []string{"Example CA"},
+		CommonName:   "Example Root CA",
+	}, pkix.Name{
+		Country:      []string{"US"},
+		Organization: []string{"Example CA"},
+		CommonName:   "Example Root CA",
+	}, nil)
+	subject.OrganizationalUnit = nil
+	leaf := newCert(pkix.Name{}, subject, ca)
+	// Extensions are Subject Key Identifier and Authority Key Identifier,
+	// in certificate order.
+	assert.Equal(t, "a373a9f83c6b_2bab15409345_"+ja4Hash("551d0e,551d23"), getJa4xFingerprint(leaf))
+}
+
+func TestOIDHex(t *testing.T) {
+	for _, test := range []struct {
+		oid      asn1.ObjectIdentifier
+		expected string
+	}{
+		{asn1.ObjectIdentifier{2, 5, 4, 3}, "550403"},
+		{asn1.ObjectIdentifier{2, 5, 29, 17}, "551d11"},
+		{asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}, "2b06010505070101"},
+		{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}, "2a864886f70d010901"},
+		{asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}, "2b06010401d679020402"},
+	} {
+		assert.Equal(t, test.expected, oidHex(test.oid), test.oid.String())
+	}
+}
@@ -0,0 +1,191 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Labels used in NSS key log files, see
+// https://firefox-source-docs.mozilla.org/security/nss/legacy/key_log_format/
+const (
+	keyLogMasterSecret            = "CLIENT_RANDOM"
+	keyLogClientHandshakeSecret   = "CLIENT_HANDSHAKE_TRAFFIC_SECRET"
+	keyLogServerHandshakeSecret   = "SERVER_HANDSHAKE_TRAFFIC_SECRET"
+	keyLogClientApplicationSecret = "CLIENT_TRAFFIC_SECRET_0"
+	keyLogServerApplicationSecret = "SERVER_TRAFFIC_SECRET_0"
+)
+
+// Key log lines longer than this are ignored. The longest valid line holds
+// a label, a 32 byte client random and a 48 byte secret, all hex-encoded.
+const maxKeyLogLineLength = 1024
+
+// sessionSecrets holds the secrets logged for a single TLS session,
+// identified by its client random.
+type sessionSecrets struct {
+	// TLS 1.2 and earlier.
+	masterSecret []byte
+
+	// TLS 1.3.
+	clientHandshakeSecret, serverHandshakeSecret     []byte
+	clientApplicationSecret, serverApplicationSecret []byte
+}
+
+// keyLog is an NSS key log file (as written by applications honoring the
+// SSLKEYLOGFILE environment variable). The file is read incrementally, as
+// applications append to it while new sessions are established.
+type keyLog struct {
+	path   string
+	logger *logp.Logger
+
+	mu      sync.Mutex
+	offset  int64
+	secrets map[string]*sessionSecrets
+	lastErr string
+}
+
+func newKeyLog(path string, logger *logp.Logger) *keyLog {
+	return &keyLog{
+		path:    path,
+		logger:  logger,
+		secrets: map[string]*sessionSecrets{},
+	}
+}
+
+// lookup returns the secrets for the session with the given client random.
+// The file is re-read if the session is unknown and new lines were appended
+// since the last read.
+func (kl *keyLog) lookup(clientRandom []byte) *sessionSecrets {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	key := string(clientRandom)
+	if s, found := kl.secrets[key]; found {
+		return s
+	}
+	if err := kl.load(); err != nil {
+		// Only log changes, as this is tried for every new session.
+		if msg := err.Error(); msg != kl.lastErr {
+			kl.logger.Warnf("Failed reading TLS key log file: %v", err)
+			kl.lastErr = msg
+		}
+		return nil
+	}
+	kl.lastErr = ""
+	return kl.secrets[key]
+}
+
+// load reads any lines appended to the file since the last call.
+func (kl *keyLog) load() error {
+	f, err := os.Open(kl.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Size() == kl.offset:
+		return nil
+	case info.Size() < kl.offset:
+		// File has been truncated or replaced.
+		kl.offset = 0
+	}
+	if _, err = f.Seek(kl.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// Skip over the rest of an overlong line.
+			for errors.Is(err, bufio.ErrBufferFull) {
+				kl.offset += int64(len(line))
+				line, err = reader.ReadSlice('\n')
+			}
+			kl.offset += int64(len(line))
+			continue
+		}
+		if err != nil {
+			// A last line without a newline might still be being written.
+			// Parse it anyway, but read it again next time.
+			if errors.Is(err, io.EOF) {
+				kl.parseLine(line)
+				return nil
+			}
+			return err
+		}
+		kl.offset += int64(len(line))
+		if len(line) <= maxKeyLogLineLength {
+			kl.parseLine(line)
+		}
+	}
+}
+
+func (kl *keyLog) parseLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return
+	}
+	fields := bytes.Fields(line)
+	if len(fields) != 3 {
+		return
+	}
+	random, err := hex.DecodeString(string(fields[1]))
+	if err != nil || len(random) != randomDataLength+4 {
+		return
+	}
+	secret, err := hex.DecodeString(string(fields[2]))
+	if err != nil || len(secret) == 0 {
+		return
+	}
+
+	key := string(random)
+	s := kl.secrets[key]
+	if s == nil {
+		s = &sessionSecrets{}
+	}
+	switch string(fields[0]) {
+	case keyLogMasterSecret:
+		s.masterSecret = secret
+	case keyLogClientHandshakeSecret:
+		s.clientHandshakeSecret = secret
+	case keyLogServerHandshakeSecret:
+		s.serverHandshakeSecret = secret
+	case keyLogClientApplicationSecret:
+		s.clientApplicationSecret = secret
+	case keyLogServerApplicationSecret:
+		s.serverApplicationSecret = secret
+	default:
+		// Early data and exporter secrets are not needed.
+		return
+	}
+	kl.secrets[key] = s
+}
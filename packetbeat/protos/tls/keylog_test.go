@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package tls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+var (
+	testRandomA = bytes.Repeat([]byte{0xaa}, 32)
+	testRandomB = bytes.Repeat([]byte{0xbb}, 32)
+)
+
+func keyLogLine(label string, random []byte, secret string) string {
+	return label + " " + hex.EncodeToString(random) + " " + secret + "\n"
+}
+
+func TestKeyLogParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.log")
+	content := "# SSL/TLS secrets log file, generated by NSS\n" +
+		"\n" +
+		keyLogLine("CLIENT_RANDOM", testRandomA, "0102") +
+		keyLogLine("CLIENT_HANDSHAKE_TRAFFIC_SECRET", testRandomB, "11") +
+		keyLogLine("SERVER_HANDSHAKE_TRAFFIC_SECRET", testRandomB, "12") +
+		keyLogLine("CLIENT_TRAFFIC_SECRET_0", testRandomB, "13") +
+		keyLogLine("SERVER_TRAFFIC_SECRET_0", testRandomB, "14") +
+		keyLogLine("EXPORTER_SECRET", testRandomB, "15") +
+		"CLIENT_RANDOM abcd 0102\n" +
+		"CLIENT_RANDOM " + hex.EncodeToString(testRandomB) + " zz\n" +
+		"garbage\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	kl := newKeyLog(path, logptest.NewTestingLogger(t, ""))
+	a := kl.lookup(testRandomA)
+	require.NotNil(t, a)
+	assert.Equal(t, []byte{1, 2}, a.masterSecret)
+	assert.Nil(t, a.clientHandshakeSecret)
+
+	b := kl.lookup(testRandomB)
+	require.NotNil(t, b)
+	assert.Nil(t, b.masterSecret)
+	assert.Equal(t, []byte{0x11}, b.clientHandshakeSecret)
+	assert.Equal(t, []byte{0x12}, b.serverHandshakeSecret)
+	assert.Equal(t, []byte{0x13}, b.clientApplicationSecret)
+	assert.Equal(t, []byte{0x14}, b.serverApplicationSecret)
+
+	assert.Len(t, kl.secrets, 2)
+	assert.Nil(t, kl.lookup(bytes.Repeat([]byte{0xcc}, 32)))
+}
+
+func TestKeyLogReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.log")
+	kl := newKeyLog(path, logptest.NewTestingLogger(t, ""))
+
+	// The file does not exist yet.
+	assert.Nil(t, kl.lookup(testRandomA))
+
+	// A line is still being written.
+	line := keyLogLine("CLIENT_RANDOM", testRandomA, "0102")
+	require.NoError(t, os.WriteFile(path, []byte(line[:len(line)-1]), 0o600))
+	assert.Equal(t, []byte{0x01, 0x02}, kl.lookup(testRandomA).masterSecret)
+	assert.Zero(t, kl.offset)
+
+	require.NoError(t, os.WriteFile(path, []byte(line), 0o600))
+	assert.Nil(t, kl.lookup(testRandomB))
+	assert.Equal(t, int64(len(line)), kl.offset)
+
+	// Appended sessions are found.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(keyLogLine("CLIENT_RANDOM", testRandomB, "03"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, []byte{0x03}, kl.lookup(testRandomB).masterSecret)
+
+	// The file is read from the start after being replaced by a shorter one.
+	random := bytes.Repeat([]byte{0xcc}, 32)
+	require.NoError(t, os.WriteFile(path, []byte(keyLogLine("CLIENT_RANDOM", random, "04")), 0o600))
+	assert.Equal(t, []byte{0x04}, kl.lookup(random).masterSecret)
+}
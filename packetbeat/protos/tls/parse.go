@@ -62,14 +62,17 @@ const (
 type handshakeType uint8
 
 const (
-	helloRequest       handshakeType = 0
-	clientHello        handshakeType = 1
-	serverHello        handshakeType = 2
-	certificate        handshakeType = 11
-	serverKeyExchange  handshakeType = 12
-	certificateRequest handshakeType = 13
-	clientKeyExchange  handshakeType = 16
-	certificateStatus  handshakeType = 22
+	helloRequest        handshakeType = 0
+	clientHello         handshakeType = 1
+	serverHello         handshakeType = 2
+	certificate         handshakeType = 11
+	serverKeyExchange   handshakeType = 12
+	certificateRequest  handshakeType = 13
+	clientKeyExchange   handshakeType = 16
+	encryptedExtensions handshakeType = 8
+	finished            handshakeType = 20
+	certificateStatus   handshakeType = 22
+	keyUpdate           handshakeType = 24
 )
 
 type parserResult int8
@@ -106,7 +109,12 @@ type parser struct {
 
 	// If a key-exchange message has been sent. Used to detect session resumption
 	keyExchanged bool
-	logger       *logp.Logger
+
+	// If the encrypted records that follow the handshake are going to be
+	// decrypted. The parser then stops at the first encrypted record
+	// instead of discarding it.
+	decrypting bool
+	logger     *logp.Logger
 }
 
 // https://www.rfc-editor.org/rfc/rfc6960#section-4.2.1
@@ -241,6 +249,37 @@ func (hello *helloMessage) toMap() mapstr.M {
 	return m
 }
 
+// highestVersion returns the highest version offered in a client hello.
+func (hello *helloMessage) highestVersion() tlsVersion {
+	version := hello.version
+	if raw := hello.extensions.Raw[ExtensionSupportedVersions]; len(raw) > 0 {
+		list := raw[1:min(len(raw), 1+int(raw[0]))]
+		for pos := 0; pos+2 <= len(list); pos += 2 {
+			v := tlsVersion{major: list[pos], minor: list[pos+1]}
+			if !isGreaseValue(uint16(v.major)<<8|uint16(v.minor)) &&
+				(v.major > version.major || (v.major == version.major && v.minor > version.minor)) {
+				version = v
+			}
+		}
+	}
+	return version
+}
+
+// negotiatedVersion returns the version selected by a server hello, taken
+// from the supported_versions extension when present.
+func (hello *helloMessage) negotiatedVersion() tlsVersion {
+	if raw := hello.extensions.Raw[ExtensionSupportedVersions]; len(raw) == 2 {
+		return tlsVersion{major: raw[0], minor: raw[1]}
+	}
+	return hello.version
+}
+
+// isTLS13 returns whether a server hello negotiated TLS 1.3.
+func (hello *helloMessage) isTLS13() bool {
+	version, _ := hello.extensions.Parsed["supported_versions"].(string)
+	return version == "TLS 1.3"
+}
+
 func (hello *helloMessage) supportedCiphers() []string {
 	ciphers := make([]string, len(hello.supported.cipherSuites))
 	for idx, code := range hello.supported.cipherSuites {
@@ -275,8 +314,8 @@ func (parser *parser) parse(buf *streambuf.Buffer) parserResult {
 		switch header.recordType {
 		case recordTypeChangeCipherSpec: // single message of size 1 (byte 1)
 			parser.debugf("handshake completed")
-			// discard remaining data for this stream (encrypted)
-			_ = buf.Advance(buf.Len())
+			// remaining data for this stream is encrypted
+			_ = buf.Advance(limit)
 			return resultEncrypted
 
 		case recordTypeHandshake:
@@ -293,7 +332,11 @@ func (parser *parser) parse(buf *streambuf.Buffer) parserResult {
 			}
 
 		case recordTypeApplicationData:
-			// TODO: Request / Response analytics
+			if parser.decrypting {
+				// TLS 1.3 without a middlebox compatibility ChangeCipherSpec
+				parser.debugf("handshake completed")
+				return resultEncrypted
+			}
 			parser.debugf("ignoring application data length %d", header.length)
 
 		default:
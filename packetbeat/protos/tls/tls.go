@@ -49,6 +49,13 @@ type tlsConnectionData struct {
 	handshakeCompleted int8
 	eventSent          bool
 	startTime, endTime time.Time
+
+	// Decryption of application data, only used when a key log file is
+	// configured. The cleartext is passed on to the inner plugin.
+	decrypter        *decrypter
+	decryptAttempted bool
+	inner            protos.TCPPlugin
+	innerData        protos.ProtocolData
 }
 
 // TLS protocol plugin
@@ -60,6 +67,8 @@ type tlsPlugin struct {
 	includeDetailedFields  bool
 	fingerprints           []*FingerprintAlgorithm
 	transactionTimeout     time.Duration
+	keyLog                 *keyLog
+	protocols              protos.Protocols
 	results                protos.Reporter
 	watcher                *procs.ProcessesWatcher
 	logger, tlsLogger      *logp.Logger
@@ -70,6 +79,9 @@ var (
 
 	// ensure that tlsPlugin fulfills the TCPPlugin interface
 	_ protos.TCPPlugin = &tlsPlugin{}
+
+	_ protos.ExpirationAwareTCPPlugin = &tlsPlugin{}
+	_ protos.PluginLinker             = &tlsPlugin{}
 )
 
 func init() {
@@ -132,9 +144,17 @@ func (plugin *tlsPlugin) setFromConfig(config *tlsConfig) error {
 		}
 		plugin.fingerprints = append(plugin.fingerprints, algo)
 	}
+	if config.KeyLogFile != "" {
+		plugin.keyLog = newKeyLog(config.KeyLogFile, plugin.tlsLogger)
+	}
 	return nil
 }
 
+// Link looks up the analyzers that decrypted application data is passed on to.
+func (plugin *tlsPlugin) Link(protocols protos.Protocols) {
+	plugin.protocols = protocols
+}
+
 func (plugin *tlsPlugin) GetPorts() []int {
 	return plugin.ports
 }
@@ -180,9 +200,16 @@ func (plugin *tlsPlugin) doParse(
 	tcptuple *common.TCPTuple,
 	dir uint8,
 ) *tlsConnectionData {
-	// Ignore further traffic after the handshake is completed (encrypted connection)
-	// TODO: request/response analysis
+	// Ignore further traffic after the handshake is completed (encrypted connection),
+	// unless the session keys are known.
 	if conn.handshakeCompleted&(1<<dir) != 0 {
+		if st := conn.streams[dir]; st != nil && conn.decrypter != nil {
+			if err := st.Append(pkt.Payload); err != nil {
+				plugin.debugf("%v, not decrypting TCP stream", err)
+				return conn
+			}
+			plugin.decryptStream(conn, st, pkt, tcptuple, dir)
+		}
 		return conn
 	}
 
@@ -191,6 +218,7 @@ func (plugin *tlsPlugin) doParse(
 		st = newStream(tcptuple)
 		st.cmdlineTuple = plugin.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
 		st.parser.logger = plugin.tlsLogger
+		st.parser.decrypting = plugin.keyLog != nil
 		conn.streams[dir] = st
 	}
 
@@ -220,12 +248,111 @@ func (plugin *tlsPlugin) doParse(
 				conn.endTime = pkt.Ts
 				plugin.sendEvent(conn)
 			}
+			if plugin.startDecryption(conn) {
+				plugin.decryptStream(conn, st, pkt, tcptuple, dir)
+			} else {
+				// discard remaining data for this stream (encrypted)
+				_ = st.Buf.Advance(st.Buf.Len())
+			}
 		}
 	}
 
 	return conn
 }
 
+// startDecryption sets up decryption of the application data once the
+// handshake is done, if the key log file has the secrets for this session.
+func (plugin *tlsPlugin) startDecryption(conn *tlsConnectionData) bool {
+	if conn.decrypter != nil {
+		return true
+	}
+	if plugin.keyLog == nil || conn.decryptAttempted {
+		return false
+	}
+	conn.decryptAttempted = true
+	if plugin.protocols == nil {
+		return false
+	}
+
+	var clientHello, serverHello *helloMessage
+	var clientDir uint8
+	for dir, st := range conn.streams {
+		if st == nil || st.parser.hello == nil {
+			continue
+		}
+		switch st.parser.direction {
+		case dirClient:
+			clientHello, clientDir = st.parser.hello, uint8(dir)
+		case dirServer:
+			serverHello = st.parser.hello
+		}
+	}
+	if clientHello == nil || serverHello == nil {
+		plugin.debugf("handshake not seen, not decrypting")
+		return false
+	}
+
+	secrets := plugin.keyLog.lookup(clientHello.random)
+	if secrets == nil {
+		plugin.debugf("no key log entry for client random %x", clientHello.random)
+		return false
+	}
+	d, err := newDecrypter(clientHello, serverHello, secrets, clientDir, plugin.tlsLogger)
+	if err != nil {
+		plugin.debugf("not decrypting: %v", err)
+		return false
+	}
+	conn.decrypter = d
+	return true
+}
+
+// innerPlugin returns the analyzer for an application protocol selected
+// through ALPN.
+func (plugin *tlsPlugin) innerPlugin(alpn string) protos.TCPPlugin {
+	var name string
+	switch alpn {
+	case "", "http/1.0", "http/1.1":
+		name = "http"
+	case "h2":
+		name = "http2"
+	default:
+		plugin.debugf("no analyzer for application protocol %q, not decrypting", alpn)
+		return nil
+	}
+	inner := plugin.protocols.GetTCP(protos.Lookup(name))
+	if inner == nil {
+		plugin.debugf("%s analyzer not enabled, not decrypting", name)
+	}
+	return inner
+}
+
+// decryptStream decrypts the buffered records of a stream and passes the
+// application data on to the inner plugin.
+func (plugin *tlsPlugin) decryptStream(
+	conn *tlsConnectionData,
+	st *stream,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) {
+	data, err := conn.decrypter.decrypt(dir, &st.Buf)
+	st.Reset()
+	if err != nil {
+		plugin.debugf("stopped decrypting stream: %v", err)
+	}
+	if len(data) == 0 {
+		return
+	}
+	if conn.inner == nil {
+		if conn.inner = plugin.innerPlugin(conn.decrypter.applicationProtocol()); conn.inner == nil {
+			conn.decrypter = nil
+			return
+		}
+	}
+	inner := &protos.Packet{Ts: pkt.Ts, Tuple: pkt.Tuple, Payload: data}
+	conn.innerData = conn.inner.Parse(inner, tcptuple, dir, conn.innerData)
+}
+
 func newStream(tcptuple *common.TCPTuple) *stream {
 	s := &stream{
 		tcptuple: tcptuple,
@@ -239,6 +366,9 @@ func (plugin *tlsPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
 ) protos.ProtocolData {
 	if conn := ensureTLSConnection(private, plugin.logger); conn != nil {
 		plugin.sendEvent(conn)
+		if conn.inner != nil {
+			conn.innerData = conn.inner.ReceivedFin(tcptuple, dir, conn.innerData)
+		}
 	}
 	return private
 }
@@ -247,10 +377,23 @@ func (plugin *tlsPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
 	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool) {
 	if conn := ensureTLSConnection(private, plugin.logger); conn != nil {
 		plugin.sendEvent(conn)
+		if conn.inner != nil {
+			conn.innerData, _ = conn.inner.GapInStream(tcptuple, dir, nbytes, conn.innerData)
+		}
 	}
 	return private, true
 }
 
+func (plugin *tlsPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*tlsConnectionData)
+	if !ok || conn.inner == nil {
+		return
+	}
+	if inner, ok := conn.inner.(protos.ExpirationAwareTCPPlugin); ok {
+		inner.Expired(tuple, conn.innerData)
+	}
+}
+
 func (plugin *tlsPlugin) sendEvent(conn *tlsConnectionData) {
 	if !conn.eventSent {
 		conn.eventSent = true
@@ -283,6 +426,8 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		Established: conn.handshakeCompleted > 1,
 	}
 	detailed := mapstr.M{}
+	// JA4 fingerprints, which have no ECS fields.
+	ja4 := mapstr.M{}
 
 	emptyHello := &helloMessage{logger: plugin.tlsLogger}
 	var clientHello, serverHello *helloMessage
@@ -290,6 +435,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		clientHello = client.parser.hello
 		detailed["client_hello"] = clientHello.toMap()
 		tls.ClientJa3, _ = getJa3Fingerprint(clientHello)
+		ja4["client.ja4"] = getJa4Fingerprint(clientHello, JA4TransportTCP)
 		tls.ClientSupportedCiphers = clientHello.supportedCiphers()
 	} else {
 		clientHello = emptyHello
@@ -298,6 +444,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		serverHello = server.parser.hello
 		detailed["server_hello"] = serverHello.toMap()
 		tls.ServerJa3s, _ = getJa3Fingerprint(serverHello)
+		ja4["server.ja4s"] = getJa4sFingerprint(serverHello, JA4TransportTCP)
 		tls.Cipher = serverHello.selected.cipherSuite.String()
 	} else {
 		serverHello = emptyHello
@@ -334,6 +481,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		tls.ClientIssuer = cert.Issuer.String()
 		tls.ClientNotAfter = cert.NotAfter
 		tls.ClientNotBefore = cert.NotBefore
+		ja4["client.ja4x"] = getJa4xFingerprint(cert)
 	}
 	if list := server.parser.certificates; len(list) > 0 {
 		cert := list[0]
@@ -346,6 +494,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		tls.ServerIssuer = cert.Issuer.String()
 		tls.ServerNotAfter = cert.NotAfter
 		tls.ServerNotBefore = cert.NotBefore
+		ja4["server.ja4x"] = getJa4xFingerprint(cert)
 	}
 	detailed["client_certificate_requested"] = server.parser.certRequested
 
@@ -445,6 +594,9 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 
 	// Serialize ECS TLS fields
 	pb.MarshalStruct(fields, "tls", tls)
+	for key, value := range ja4 {
+		fields.Put("tls."+key, value)
+	}
 	if plugin.includeDetailedFields {
 		if cert, ok := detailed["client_certificate"]; ok {
 			fields.Put("tls.client.x509", cert)
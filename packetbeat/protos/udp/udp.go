@@ -34,6 +34,7 @@ import (
 
 	"github.com/elastic/beats/v7/packetbeat/flows"
 	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/classify"
 )
 
 type Processor interface {
@@ -44,6 +45,10 @@ type UDP struct {
 	protocols protos.Protocols
 	portMap   map[uint16]protos.Protocol
 
+	// classify is set when datagrams on ports no analyzer is configured for
+	// are handed to the analyzer of the protocol detected from their payload.
+	classify bool
+
 	metrics *inputMetrics
 	logger  *logp.Logger
 }
@@ -66,6 +71,13 @@ func NewUDP(p protos.Protocols, id, device string, idx int, logger *logp.Logger)
 	return udp, nil
 }
 
+// EnableClassification has datagrams on ports no analyzer is configured for
+// handed to the analyzer of the protocol detected from their payload. Unlike
+// TCP connections, each datagram is classified on its own.
+func (udp *UDP) EnableClassification() {
+	udp.classify = true
+}
+
 // buildPortsMap creates a mapping of port numbers to protocol identifiers. If
 // any two UdpProtocolPlugins operate on the same port number then an error
 // will be returned.
@@ -95,6 +107,9 @@ func buildPortsMap(plugins map[protos.Protocol]protos.UDPPlugin) (map[uint16]pro
 // or the payload is empty then the method is a noop.
 func (udp *UDP) Process(id *flows.FlowID, pkt *protos.Packet) {
 	protocol := udp.decideProtocol(&pkt.Tuple)
+	if protocol == protos.UnknownProtocol && udp.classify {
+		protocol = protos.Lookup(classify.Dispatchable(classify.UDP, pkt.Payload))
+	}
 	if protocol == protos.UnknownProtocol {
 		udp.logger.Debug("unknown protocol")
 		return
@@ -131,6 +146,15 @@ func (udp *UDP) decideProtocol(tuple *common.IPPortTuple) protos.Protocol {
 	return protos.UnknownProtocol
 }
 
+// Flush flushes pending transactions of all UDP plugins.
+func (udp *UDP) Flush() {
+	for _, plugin := range udp.protocols.GetAllUDP() {
+		if flusher, ok := plugin.(protos.PluginFlusher); ok {
+			flusher.Flush()
+		}
+	}
+}
+
 func (udp *UDP) Close() {
 	if udp.metrics == nil {
 		return
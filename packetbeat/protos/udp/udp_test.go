@@ -30,6 +30,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp/logptest"
 
 	// import plugins for testing
+	_ "github.com/elastic/beats/v7/packetbeat/protos/dns"
 	_ "github.com/elastic/beats/v7/packetbeat/protos/http"
 	_ "github.com/elastic/beats/v7/packetbeat/protos/mysql"
 	_ "github.com/elastic/beats/v7/packetbeat/protos/redis"
@@ -44,6 +45,7 @@ const (
 )
 
 var (
+	dnsProtocol   = protos.Lookup("dns")
 	httpProtocol  = protos.Lookup("http")
 	mysqlProtocol = protos.Lookup("mysql")
 	redisProtocol = protos.Lookup("redis")
@@ -260,3 +262,32 @@ func TestProcess_nonEmptyPayload(t *testing.T) {
 	test.udp.Process(nil, pkt)
 	assert.Equal(t, pkt, test.plugin.pkt)
 }
+
+// Verify that Process hands datagrams on ports no plugin is configured for to
+// the plugin of the protocol detected from their payload, once classification
+// is enabled.
+func TestProcess_classification(t *testing.T) {
+	test := testSetup(t)
+	defer test.udp.metrics.close()
+	dns := &TestProtocol{Ports: []int{53}}
+	test.protocols.udp[dnsProtocol] = dns
+
+	tuple := common.NewIPPortTuple(4,
+		net.ParseIP("10.0.0.1"), 34898,
+		net.ParseIP("192.168.0.1"), 5353)
+	query := []byte{
+		0xab, 0xcd, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		0x00, 0x01, 0x00, 0x01,
+	}
+	pkt := &protos.Packet{Ts: time.Now(), Tuple: tuple, Payload: query}
+
+	test.udp.Process(nil, pkt)
+	assert.Nil(t, dns.pkt)
+
+	test.udp.EnableClassification()
+	test.udp.Process(nil, &protos.Packet{Ts: time.Now(), Tuple: tuple, Payload: []byte("not dns")})
+	assert.Nil(t, dns.pkt)
+	test.udp.Process(nil, pkt)
+	assert.Equal(t, pkt, dns.pkt)
+}
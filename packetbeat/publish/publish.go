@@ -146,16 +146,29 @@ func (p *TransactionPublisher) worker(ch chan beat.Event, client beat.Client) {
 	for {
 		select {
 		case <-p.done:
-			return
-		case event := <-ch:
-			pub, _ := p.processor.Run(&event)
-			if pub != nil {
-				client.Publish(*pub)
+			// publish events still buffered so that transactions flushed
+			// right before stopping are not lost
+			for {
+				select {
+				case event := <-ch:
+					p.publish(client, event)
+				default:
+					return
+				}
 			}
+		case event := <-ch:
+			p.publish(client, event)
 		}
 	}
 }
 
+func (p *TransactionPublisher) publish(client beat.Client, event beat.Event) {
+	pub, _ := p.processor.Run(&event)
+	if pub != nil {
+		client.Publish(*pub)
+	}
+}
+
 func (p *transProcessor) Run(event *beat.Event) (*beat.Event, error) {
 	if err := validateEvent(event); err != nil {
 		p.logger.Warnf("Dropping invalid event: %v", err)
@@ -87,11 +87,17 @@ func DecodersFor(
 		if cfg.Flows != nil {
 			allowMismatchedEth = cfg.Flows.AllowMismatchedEth
 		}
+		classifyPackets := cfg.Flows.ClassificationPackets()
+		if classifyPackets > 0 && cfg.Flows.Classification.Dispatch {
+			tcp.EnableClassification(classifyPackets)
+			udp.EnableClassification()
+		}
 
 		worker, err := decoder.New(flows, dl, icmp4, icmp6, tcp, udp, allowMismatchedEth, logger)
 		if err != nil {
 			return nil, nil, err
 		}
+		worker.ClassifyFlows(classifyPackets)
 
 		cleanup := func() {
 			if icmpCloser != nil {
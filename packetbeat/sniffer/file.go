@@ -36,15 +36,18 @@ type fileHandler struct {
 	loopCount, maxLoopCount int
 
 	topSpeed bool
-	lastTS   time.Time
+	// keepTS keeps packet timestamps when pacing reads, instead of replacing them with the read time
+	keepTS bool
+	lastTS time.Time
 
 	log *logp.Logger
 }
 
-func newFileHandler(file string, topSpeed bool, maxLoopCount int, logger *logp.Logger) (*fileHandler, error) {
+func newFileHandler(file string, topSpeed, keepTS bool, maxLoopCount int, logger *logp.Logger) (*fileHandler, error) {
 	h := &fileHandler{
 		file:         file,
 		topSpeed:     topSpeed,
+		keepTS:       keepTS,
 		maxLoopCount: maxLoopCount,
 		log:          logger,
 	}
@@ -104,7 +107,9 @@ func (h *fileHandler) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
 	}
 
 	h.lastTS = ci.Timestamp
-	ci.Timestamp = time.Now()
+	if !h.keepTS {
+		ci.Timestamp = time.Now()
+	}
 	return data, ci, nil
 }
 
@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package sniffer
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+func TestFileHandlerTimestamps(t *testing.T) {
+	const file = "../tests/system/pcaps/dns_google_com.pcap"
+
+	for _, test := range []struct {
+		name   string
+		keepTS bool
+	}{
+		{name: "read time", keepTS: false},
+		{name: "packet time", keepTS: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := newFileHandler(file, false, test.keepTS, 1, logptest.NewTestingLogger(t, ""))
+			require.NoError(t, err)
+			defer h.Close()
+
+			start := time.Now()
+			var n int
+			for {
+				_, ci, err := h.ReadPacketData()
+				if err == io.EOF { //nolint:errorlint // io.EOF should never be wrapped.
+					break
+				}
+				require.NoError(t, err)
+				n++
+				if test.keepTS {
+					assert.True(t, ci.Timestamp.Before(start), "packet %d has read time %v", n, ci.Timestamp)
+				} else {
+					assert.False(t, ci.Timestamp.Before(start), "packet %d has packet time %v", n, ci.Timestamp)
+				}
+			}
+			assert.NotZero(t, n)
+		})
+	}
+}
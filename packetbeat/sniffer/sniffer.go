@@ -71,6 +71,9 @@ type sniffer struct {
 	id  string
 	idx int
 
+	// packets is the number of packets read by the sniffer.
+	packets *atomic.Uint64
+
 	decoders Decoders
 
 	log *logp.Logger
@@ -118,6 +121,7 @@ func New(
 		}
 		child := sniffer{
 			state:         &atomic.Int32{},
+			packets:       &atomic.Uint64{},
 			followDefault: iface.PollDefaultRoute > 0 && strings.HasPrefix(iface.Device, "default_route"),
 			id:            id,
 			idx:           i,
@@ -456,6 +460,10 @@ func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *de
 		if err != nil {
 			// ignore EOF, if sniffer was driven from file
 			if err == io.EOF && s.config.File != "" { //nolint:errorlint // io.EOF should never be wrapped.
+				if s.config.Replay {
+					s.log.Infof("replayed %d packets from %s", packets, s.config.File)
+					dec.Flush()
+				}
 				return nil
 			}
 
@@ -481,6 +489,7 @@ func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *de
 		}
 
 		packets++
+		s.packets.Add(1)
 
 		if w != nil {
 			err = w.WritePacket(ci, data)
@@ -500,7 +509,7 @@ func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *de
 
 func (s *sniffer) open(device string) (snifferHandle, error) {
 	if s.config.File != "" {
-		return newFileHandler(s.config.File, s.config.TopSpeed, s.config.Loop, s.log)
+		return newFileHandler(s.config.File, s.config.TopSpeed, s.config.Replay, s.config.Loop, s.log)
 	}
 
 	switch s.config.Type {
@@ -520,6 +529,15 @@ func (s *sniffer) UpdateStatus(status status.Status, message string) {
 	}
 }
 
+// Packets returns the number of packets read by all sniffers.
+func (s *Sniffer) Packets() uint64 {
+	var n uint64
+	for _, c := range s.sniffers {
+		n += c.packets.Load()
+	}
+	return n
+}
+
 // Stop marks a sniffer as stopped. The Run method will return once the stop
 // signal has been given.
 func (s *Sniffer) Stop() {
@@ -1,3 +1,5 @@
+import os
+
 from packetbeat import BaseTest
 
 
@@ -34,3 +36,30 @@ class Test(BaseTest):
         assert o["tls.client.ja3"] == "ba7a226ea102737ecfa8959f26b28b95"
         assert o["tls.detailed.version"] == "TLS 1.2"
         assert o["tls.detailed.client_hello.extensions.server_name_indication"] == ["localhost"]
+
+    def test_keylog_decryption(self):
+        """
+        Should decrypt the application data of sessions found in the key
+        log and report the HTTP transactions inside them.
+        """
+        self.render_config_template(
+            tls_keylog_file=os.path.join(self.beat_path, "tests/system/pcaps/tls_keylog.keys"),
+        )
+        self.run_packetbeat(pcap="tls_keylog.pcap")
+        objs = self.read_output()
+
+        tls = [o for o in objs if o["type"] == "tls"]
+        assert [o["tls.version"] for o in tls] == ["1.2", "1.3", "1.3"]
+        assert all(o["status"] == "OK" for o in tls)
+
+        http = [o for o in objs if o["type"] == "http"]
+        assert len(http) == 2
+        assert [o["http.request.method"] for o in http] == ["GET", "POST"]
+        assert [o["url.path"] for o in http] == ["/tls12", "/tls13"]
+        assert all(o["http.response.status_code"] == 200 for o in http)
+        assert http[1]["http.request.body.bytes"] == 15
+
+        http2 = [o for o in objs if o["type"] == "http2"]
+        assert len(http2) == 1
+        assert http2[0]["url.full"] == "https://example.com/h2"
+        assert http2[0]["http.response.status_code"] == 200
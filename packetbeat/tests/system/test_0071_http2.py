@@ -0,0 +1,46 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the HTTP/2 and gRPC protocol analyzer.
+"""
+
+
+class Test(BaseTest):
+
+    def test_grpc_and_h2c_upgrade(self):
+        """
+        Should report one transaction per stream, with gRPC details and
+        h2c upgrades.
+        """
+        self.render_config_template(
+            http2_ports=[50051],
+        )
+        self.run_packetbeat(pcap="http2_grpc.pcap")
+        objs = self.read_output()
+
+        assert len(objs) == 4
+        assert all(o["type"] == "http2" for o in objs)
+
+        calls = [o for o in objs if "grpc.service" in o]
+        assert len(calls) == 3
+        assert [o["http2.stream_id"] for o in calls] == [1, 3, 5]
+
+        ok = calls[0]
+        assert ok["status"] == "OK"
+        assert ok["grpc.service"] == "helloworld.Greeter"
+        assert ok["grpc.method"] == "SayHello"
+        assert ok["grpc.status"] == "OK"
+        assert ok["http.response.status_code"] == 200
+        assert ok["url.full"] == "http://greeter.example.com:50051/helloworld.Greeter/SayHello"
+
+        failed = calls[2]
+        assert failed["status"] == "Error"
+        assert failed["grpc.status_code"] == 5
+        assert failed["grpc.status"] == "NOT_FOUND"
+        assert failed["grpc.message"] == "item not found"
+
+        upgrade = [o for o in objs if o.get("http2.h2c_upgrade")]
+        assert len(upgrade) == 1
+        assert upgrade[0]["status"] == "OK"
+        assert upgrade[0]["url.path"] == "/status"
+        assert upgrade[0]["http.response.body.bytes"] == 3
@@ -0,0 +1,44 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the QUIC protocol analyzer.
+"""
+
+
+class Test(BaseTest):
+
+    def test_initial_handshake(self):
+        """
+        Should report the TLS handshake carried in the Initial packets of a
+        QUIC connection.
+        """
+        self.render_config_template()
+        self.run_packetbeat(pcap="quic_handshake.pcap")
+        objs = self.read_output()
+
+        assert len(objs) == 1
+        o = objs[0]
+
+        assert o["type"] == "quic"
+        assert o["status"] == "OK"
+        assert o["network.transport"] == "udp"
+        assert o["network.protocol"] == "quic"
+        assert o["source.ip"] == "10.0.0.1"
+        assert o["source.port"] == 50001
+        assert o["destination.ip"] == "10.0.0.2"
+        assert o["destination.port"] == 443
+        assert o["destination.domain"] == "example.com"
+
+        assert o["quic.version"] == "1"
+        assert o["quic.original_destination_connection_id"] == "8394c8f03e515708"
+        assert o["quic.client.connection_id"] == "c1010203"
+        assert o["quic.server.connection_id"] == "5e000102"
+        assert o["quic.client.alpn"] == ["h3"]
+
+        assert o["tls.client.server_name"] == "example.com"
+        assert o["tls.client.ja3"] == "dd17add21947bee256bd3f86ff8ef85e"
+        assert o["tls.server.ja3s"] == "52da58051f406ccd386045755808a429"
+        assert o["tls.client.ja4"] == "q13d0313h3_55b375c5d22e_4156cdf64688"
+        assert o["tls.server.ja4s"] == "q130200_1301_a56c5b993250"
+        assert o["tls.version"] == "1.3"
+        assert o["tls.cipher"] == "TLS_AES_128_GCM_SHA256"
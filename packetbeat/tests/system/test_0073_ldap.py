@@ -0,0 +1,49 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the LDAP protocol analyzer.
+"""
+
+
+class Test(BaseTest):
+
+    def test_session(self):
+        """
+        Should report bind, search, modify and unbind operations with their
+        result codes.
+        """
+        self.render_config_template()
+        self.run_packetbeat(pcap="ldap_session.pcap")
+        objs = self.read_output()
+
+        assert len(objs) == 4
+        assert all(o["type"] == "ldap" for o in objs)
+        assert [o["method"] for o in objs] == ["bind", "search", "modify", "unbind"]
+        assert [o["ldap.message_id"] for o in objs] == [1, 2, 3, 4]
+
+        bind = objs[0]
+        assert bind["status"] == "OK"
+        assert bind["network.transport"] == "tcp"
+        assert bind["network.protocol"] == "ldap"
+        assert bind["destination.port"] == 389
+        assert bind["ldap.dn"] == "cn=admin,dc=example,dc=com"
+        assert bind["ldap.bind.authentication"] == "simple"
+        assert bind["ldap.result"] == "success"
+        assert bind["user.name"] == "cn=admin,dc=example,dc=com"
+
+        search = objs[1]
+        assert search["status"] == "OK"
+        assert search["query"] == "search dc=example,dc=com (&(objectClass=person)(uid=j*))"
+        assert search["ldap.search.scope"] == "sub"
+        assert search["ldap.search.filter"] == "(&(objectClass=person)(uid=j*))"
+        assert search["ldap.search.attributes"] == ["cn", "mail"]
+        assert search["ldap.search.entries"] == 2
+
+        modify = objs[2]
+        assert modify["status"] == "Error"
+        assert modify["ldap.modify.changes"] == ["replace: description"]
+        assert modify["ldap.result_code"] == 50
+        assert modify["ldap.result"] == "insufficientAccessRights"
+        assert modify["ldap.diagnostic_message"] == "no write access to parent"
+
+        assert objs[3]["status"] == "OK"
@@ -0,0 +1,48 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the Kerberos protocol analyzer.
+"""
+
+
+class Test(BaseTest):
+
+    def test_as_and_tgs_exchanges(self):
+        """
+        Should report AS exchanges over UDP and TGS exchanges over TCP with
+        their principals, encryption types and errors.
+        """
+        self.render_config_template()
+        self.run_packetbeat(pcap="kerberos_as_tgs.pcap")
+        objs = self.read_output()
+
+        assert len(objs) == 3
+        assert all(o["type"] == "kerberos" for o in objs)
+        assert [o["method"] for o in objs] == ["AS", "AS", "TGS"]
+        assert [o["network.transport"] for o in objs] == ["udp", "udp", "tcp"]
+
+        preauth = objs[0]
+        assert preauth["status"] == "OK"
+        assert preauth["kerberos.error.code"] == 25
+        assert preauth["kerberos.error.name"] == "KDC_ERR_PREAUTH_REQUIRED"
+        assert preauth["kerberos.preauthentication"] == ["PA-PAC-REQUEST"]
+
+        tgt = objs[1]
+        assert tgt["status"] == "OK"
+        assert tgt["resource"] == "krbtgt/EXAMPLE.COM@EXAMPLE.COM"
+        assert tgt["kerberos.client.name"] == "jdoe"
+        assert tgt["kerberos.client.realm"] == "EXAMPLE.COM"
+        assert tgt["user.name"] == "jdoe"
+        assert tgt["kerberos.kdc_options"] == ["forwardable", "renewable", "canonicalize", "renewable-ok"]
+        assert tgt["kerberos.encryption_types"] == [
+            "aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96", "rc4-hmac"]
+        assert tgt["kerberos.preauthentication"] == ["PA-ENC-TIMESTAMP", "PA-PAC-REQUEST"]
+        assert tgt["kerberos.ticket.encryption_type"] == "aes256-cts-hmac-sha1-96"
+        assert tgt["kerberos.reply.encryption_type"] == "aes256-cts-hmac-sha1-96"
+
+        service = objs[2]
+        assert service["status"] == "OK"
+        assert service["query"] == "TGS cifs/fs1.example.com@EXAMPLE.COM"
+        assert service["kerberos.service.name"] == "cifs/fs1.example.com"
+        assert service["kerberos.client.name"] == "jdoe"
+        assert service["kerberos.ticket.encryption_type"] == "rc4-hmac"
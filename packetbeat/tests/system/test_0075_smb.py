@@ -0,0 +1,50 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the SMB protocol analyzer.
+"""
+
+
+class Test(BaseTest):
+
+    def test_file_access(self):
+        """
+        Should report the session setup, tree connect and the files opened,
+        with the bytes read reported when the file is closed.
+        """
+        self.render_config_template()
+        self.run_packetbeat(pcap="smb2_file_access.pcap")
+        objs = self.read_output()
+
+        assert len(objs) == 8
+        assert all(o["type"] == "smb" for o in objs)
+        assert [o["method"] for o in objs] == [
+            "NEGOTIATE", "SESSION_SETUP", "TREE_CONNECT", "CREATE",
+            "CLOSE", "CREATE", "TREE_DISCONNECT", "LOGOFF"]
+        assert all(o["smb.dialect"] == "3.1.1" for o in objs)
+
+        session = objs[1]
+        assert session["status"] == "OK"
+        assert session["network.protocol"] == "smb"
+        assert session["destination.port"] == 445
+        assert session["smb.session.authentication"] == "ntlm"
+        assert session["smb.session.workstation"] == "WS042"
+        assert session["user.name"] == "jdoe"
+        assert session["user.domain"] == "CORP"
+
+        tree = objs[2]
+        assert tree["resource"] == "\\\\fs01\\projects"
+        assert tree["smb.share_type"] == "disk"
+        assert tree["user.name"] == "jdoe"
+
+        close = objs[4]
+        assert close["status"] == "OK"
+        assert close["resource"] == "\\\\fs01\\projects\\reports\\q3.xlsx"
+        assert close["smb.file.reads"] == 2
+        assert close["smb.file.bytes_read"] == 10000
+        assert close["smb.file.bytes_written"] == 0
+
+        denied = objs[5]
+        assert denied["status"] == "Error"
+        assert denied["smb.status"] == "STATUS_ACCESS_DENIED"
+        assert denied["smb.file.name"] == "secret\\payroll.xlsx"
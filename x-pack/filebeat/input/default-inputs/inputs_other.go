@@ -46,7 +46,7 @@ func xpackInputs(info beat.Info, log *logp.Logger, store statestore.States) []v2
 		salesforce.Plugin(log, store),
 		streaming.Plugin(log, store),
 		streaming.PluginWebsocketAlias(log, store),
-		netflow.Plugin(log),
+		netflow.Plugin(log, store),
 		benchmark.Plugin(),
 	}
 }
@@ -47,7 +47,7 @@ func xpackInputs(info beat.Info, log *logp.Logger, store statestore.States) []v2
 		etw.Plugin(),
 		streaming.Plugin(log, store),
 		streaming.PluginWebsocketAlias(log, store),
-		netflow.Plugin(log),
+		netflow.Plugin(log, store),
 		salesforce.Plugin(log, store),
 		benchmark.Plugin(),
 	}
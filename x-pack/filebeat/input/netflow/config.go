@@ -24,6 +24,7 @@ type config struct {
 	DetectSequenceReset       bool          `config:"detect_sequence_reset"`
 	ShareTemplates            bool          `config:"share_templates"`
 	NumberOfWorkers           uint32        `config:"workers"`
+	PersistTemplates          bool          `config:"persist_templates"`
 }
 
 var defaultConfig = config{
@@ -42,4 +43,5 @@ var defaultConfig = config{
 	DetectSequenceReset: true,
 	ShareTemplates:      false,
 	NumberOfWorkers:     1,
+	PersistTemplates:    true,
 }
@@ -7,6 +7,7 @@ package config
 import (
 	"time"
 
+	"github.com/elastic/beats/v7/libbeat/statestore"
 	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/fields"
 	"github.com/elastic/elastic-agent-libs/logp"
 )
@@ -16,6 +17,15 @@ type ActiveSessionsMetric interface {
 	Dec()
 }
 
+// TemplateStore persists the templates received from exporters so that they
+// are available after a restart. It is satisfied by *statestore.Store.
+type TemplateStore interface {
+	Set(key string, from interface{}) error
+	Get(key string, into interface{}) error
+	Remove(key string) error
+	Each(fn func(string, statestore.ValueDecoder) (bool, error)) error
+}
+
 // Config stores the configuration used by the NetFlow Collector.
 type Config struct {
 	protocols            []string
@@ -26,6 +36,8 @@ type Config struct {
 	sharedTemplates      bool
 	withCache            bool
 	activeSessionsMetric ActiveSessionsMetric
+	templateStore        TemplateStore
+	templateStoreID      string
 }
 
 // Defaults returns a configuration object with defaults settings:
@@ -35,6 +47,7 @@ type Config struct {
 // - resets are detected.
 // - templates are not shared.
 // - cache is disabled.
+// - templates are not persisted.
 func Defaults(logger *logp.Logger) Config {
 	return Config{
 		protocols:       []string{},
@@ -110,6 +123,15 @@ func (c *Config) WithActiveSessionsMetric(metric ActiveSessionsMetric) *Config {
 	return c
 }
 
+// WithTemplateStore configures the store where v9 and IPFIX templates are
+// persisted. The id identifies the collector, so that several of them can
+// keep their templates in the same store.
+func (c *Config) WithTemplateStore(store TemplateStore, id string) *Config {
+	c.templateStore = store
+	c.templateStoreID = id
+	return c
+}
+
 // Protocols returns a list of the protocols enabled.
 func (c *Config) Protocols() []string {
 	return c.protocols
@@ -152,3 +174,9 @@ func (c *Config) ActiveSessionsMetric() ActiveSessionsMetric {
 
 	return c.activeSessionsMetric
 }
+
+// TemplateStore returns the store where templates are persisted and the
+// identifier of the collector. The store is nil if persistence is disabled.
+func (c *Config) TemplateStore() (TemplateStore, string) {
+	return c.templateStore, c.templateStoreID
+}
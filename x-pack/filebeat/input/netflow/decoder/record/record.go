@@ -19,6 +19,10 @@ const (
 	// Options enumeration value identifies exported options records, as defined
 	// in NetFlowV9 and IPFIX.
 	Options
+
+	// Counters enumeration value identifies interface and device counters,
+	// as exported in sFlow counter samples.
+	Counters
 )
 
 // Map type is a regular map with string keys and interface{} values. The valid
@@ -96,8 +100,15 @@ type Record struct {
 	// +--------------+-----------+------------------------------------------------------------------+
 	// | sourceId     |   uint64  | Exporter observation domain ID.                                  |
 	// +--------------+-----------+------------------------------------------------------------------+
+	//
+	// sFlow only:
+	// +--------------+-----------+------------------------------------------------------------------+
+	// | agentAddress |   net.IP  | Address of the sFlow agent, which may differ from the sender.    |
+	// +--------------+-----------+------------------------------------------------------------------+
+	// | subAgentId   |   uint64  | ID of the sub-agent that sent the datagram.                      |
+	// +--------------+-----------+------------------------------------------------------------------+
 	Exporter Map
 
-	// Type is the type of this record, either Flow or Options.
+	// Type is the type of this record, either Flow, Options or Counters.
 	Type Type
 }
@@ -0,0 +1,382 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sflow
+
+import (
+	"bytes"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/record"
+)
+
+// Flow record formats of the standard enterprise.
+const (
+	flowRawPacketHeader = 1
+	flowEthernetFrame   = 2
+	flowIPv4            = 3
+	flowIPv6            = 4
+	flowExtendedSwitch  = 1001
+	flowExtendedRouter  = 1002
+	flowExtendedGateway = 1003
+)
+
+// Counter record formats of the standard enterprise.
+const (
+	counterGenericIf = 1
+	counterEthernet  = 2
+	counterProcessor = 1001
+)
+
+// Protocols of sampled packet headers.
+const (
+	headerProtocolEthernet = 1
+	headerProtocolIPv4     = 11
+	headerProtocolIPv6     = 12
+)
+
+const (
+	// interfaceFormatIndex is the format of input and output interfaces
+	// given as an ifIndex.
+	interfaceFormatIndex = 0
+	// interfaceInternal is the interface of packets originating from or
+	// delivered to the agent itself.
+	interfaceInternal = 0x3fffffff
+)
+
+// decodeFlowSample decodes a flow sample into a record with the fields of the
+// equivalent IPFIX information elements. The byte and packet counts are the
+// size of the sampled packet scaled by the sampling rate.
+func decodeFlowSample(r *reader, expanded bool) record.Map {
+	r.uint32() // Sequence number.
+	if expanded {
+		r.uint32() // Source ID type.
+		r.uint32() // Source ID index.
+	} else {
+		r.uint32() // Source ID.
+	}
+	rate := r.uint32()
+	pool := r.uint32()
+	r.uint32() // Drops.
+
+	var inFormat, input, outFormat, output uint32
+	if expanded {
+		inFormat, input = r.uint32(), r.uint32()
+		outFormat, output = r.uint32(), r.uint32()
+	} else {
+		in, out := r.uint32(), r.uint32()
+		inFormat, input = in>>30, in&0x3fffffff
+		outFormat, output = out>>30, out&0x3fffffff
+	}
+
+	fields := record.Map{
+		"samplingPacketInterval": uint64(rate),
+		"samplingPopulation":     uint64(pool),
+	}
+	if inFormat == interfaceFormatIndex && input != interfaceInternal {
+		fields["ingressInterface"] = uint64(input)
+	}
+	if outFormat == interfaceFormatIndex && output != interfaceInternal {
+		fields["egressInterface"] = uint64(output)
+	}
+
+	var length uint32
+	numRecords := r.uint32()
+	for i := uint32(0); i < numRecords && r.err == nil; i++ {
+		format := r.uint32()
+		data := reader{data: r.opaque()}
+		if r.err != nil || format>>12 != 0 {
+			continue
+		}
+		switch format & 0xfff {
+		case flowRawPacketHeader:
+			length = decodeRawPacketHeader(&data, fields)
+		case flowEthernetFrame:
+			length = data.uint32()
+			fields["sourceMacAddress"] = mac(data.next(8))
+			fields["destinationMacAddress"] = mac(data.next(8))
+			fields["ethernetType"] = uint64(data.uint32())
+		case flowIPv4:
+			length = decodeSampledIP(&data, fields, net.IPv4len)
+		case flowIPv6:
+			length = decodeSampledIP(&data, fields, net.IPv6len)
+		case flowExtendedSwitch:
+			fields["vlanId"] = uint64(data.uint32())
+			fields["dot1qPriority"] = uint64(data.uint32())
+			fields["postVlanId"] = uint64(data.uint32())
+			data.uint32() // Destination priority.
+		case flowExtendedRouter:
+			decodeExtendedRouter(&data, fields)
+		case flowExtendedGateway:
+			decodeExtendedGateway(&data, fields)
+		}
+		if data.err != nil {
+			r.err = data.err
+		}
+	}
+
+	if length > 0 {
+		fields["octetDeltaCount"] = uint64(length) * uint64(rate)
+		fields["packetDeltaCount"] = uint64(rate)
+	}
+	return fields
+}
+
+// decodeRawPacketHeader decodes the headers of a sampled packet, returning the
+// length of the packet.
+func decodeRawPacketHeader(r *reader, fields record.Map) uint32 {
+	proto := r.uint32()
+	length := r.uint32()
+	r.uint32() // Stripped.
+	header := r.opaque()
+	if r.err != nil {
+		return 0
+	}
+
+	var first gopacket.LayerType
+	switch proto {
+	case headerProtocolEthernet:
+		first = layers.LayerTypeEthernet
+		fields["dataLinkFrameSize"] = uint64(length)
+	case headerProtocolIPv4:
+		first = layers.LayerTypeIPv4
+	case headerProtocolIPv6:
+		first = layers.LayerTypeIPv6
+	default:
+		return length
+	}
+
+	// The packet holds a copy of the header, so the addresses stay valid.
+	pkt := gopacket.NewPacket(header, first, gopacket.Lazy)
+	for _, layer := range pkt.Layers() {
+		switch l := layer.(type) {
+		case *layers.Ethernet:
+			fields["sourceMacAddress"] = l.SrcMAC
+			fields["destinationMacAddress"] = l.DstMAC
+			fields["ethernetType"] = uint64(l.EthernetType)
+		case *layers.Dot1Q:
+			fields["vlanId"] = uint64(l.VLANIdentifier)
+			fields["dot1qPriority"] = uint64(l.Priority)
+			fields["ethernetType"] = uint64(l.Type)
+		case *layers.IPv4:
+			fields["ipVersion"] = uint64(4)
+			fields["sourceIPv4Address"] = l.SrcIP.To4()
+			fields["destinationIPv4Address"] = l.DstIP.To4()
+			fields["protocolIdentifier"] = uint64(l.Protocol)
+			fields["ipClassOfService"] = uint64(l.TOS)
+			fields["ipTTL"] = uint64(l.TTL)
+		case *layers.IPv6:
+			fields["ipVersion"] = uint64(6)
+			fields["sourceIPv6Address"] = l.SrcIP
+			fields["destinationIPv6Address"] = l.DstIP
+			fields["protocolIdentifier"] = uint64(l.NextHeader)
+			fields["ipClassOfService"] = uint64(l.TrafficClass)
+			fields["ipTTL"] = uint64(l.HopLimit)
+			fields["flowLabelIPv6"] = uint64(l.FlowLabel)
+		case *layers.TCP:
+			fields["sourceTransportPort"] = uint64(l.SrcPort)
+			fields["destinationTransportPort"] = uint64(l.DstPort)
+			if len(l.Contents) > 13 {
+				fields["tcpControlBits"] = uint64(l.Contents[13])
+			}
+		case *layers.UDP:
+			fields["sourceTransportPort"] = uint64(l.SrcPort)
+			fields["destinationTransportPort"] = uint64(l.DstPort)
+		case *layers.ICMPv4:
+			fields["icmpTypeCodeIPv4"] = uint64(l.TypeCode)
+		case *layers.ICMPv6:
+			fields["icmpTypeCodeIPv6"] = uint64(l.TypeCode)
+		}
+	}
+	return length
+}
+
+// decodeSampledIP decodes the IPv4 or IPv6 data of a sampled packet,
+// returning the length of the IP packet.
+func decodeSampledIP(r *reader, fields record.Map, addrLen int) uint32 {
+	length := r.uint32()
+	proto := r.uint32()
+	src := net.IP(bytes.Clone(r.next(addrLen)))
+	dst := net.IP(bytes.Clone(r.next(addrLen)))
+	srcPort := r.uint32()
+	dstPort := r.uint32()
+	flags := r.uint32()
+	tos := r.uint32()
+	if r.err != nil {
+		return 0
+	}
+
+	if addrLen == net.IPv4len {
+		fields["ipVersion"] = uint64(4)
+		fields["sourceIPv4Address"] = src
+		fields["destinationIPv4Address"] = dst
+	} else {
+		fields["ipVersion"] = uint64(6)
+		fields["sourceIPv6Address"] = src
+		fields["destinationIPv6Address"] = dst
+	}
+	fields["protocolIdentifier"] = uint64(proto)
+	fields["sourceTransportPort"] = uint64(srcPort)
+	fields["destinationTransportPort"] = uint64(dstPort)
+	fields["tcpControlBits"] = uint64(flags)
+	fields["ipClassOfService"] = uint64(tos)
+	return length
+}
+
+func decodeExtendedRouter(r *reader, fields record.Map) {
+	nextHop := r.address()
+	srcMask := uint64(r.uint32())
+	dstMask := uint64(r.uint32())
+	if r.err != nil {
+		return
+	}
+	if nextHop.To4() != nil {
+		fields["ipNextHopIPv4Address"] = nextHop
+		fields["sourceIPv4PrefixLength"] = srcMask
+		fields["destinationIPv4PrefixLength"] = dstMask
+	} else if nextHop != nil {
+		fields["ipNextHopIPv6Address"] = nextHop
+		fields["sourceIPv6PrefixLength"] = srcMask
+		fields["destinationIPv6PrefixLength"] = dstMask
+	}
+}
+
+// decodeExtendedGateway decodes the BGP information of a flow. The adjacent
+// and destination AS numbers are the first and last of the AS path.
+func decodeExtendedGateway(r *reader, fields record.Map) {
+	nextHop := r.address()
+	r.uint32() // AS of the router.
+	srcAS := r.uint32()
+	srcPeerAS := r.uint32()
+	var path []uint32
+	for segments := r.uint32(); segments > 0 && r.err == nil; segments-- {
+		r.uint32() // Segment type.
+		for n := r.uint32(); n > 0 && r.err == nil; n-- {
+			path = append(path, r.uint32())
+		}
+	}
+	if r.err != nil {
+		return
+	}
+
+	if nextHop.To4() != nil {
+		fields["bgpNextHopIPv4Address"] = nextHop
+	} else if nextHop != nil {
+		fields["bgpNextHopIPv6Address"] = nextHop
+	}
+	fields["bgpSourceAsNumber"] = uint64(srcAS)
+	fields["bgpPrevAdjacentAsNumber"] = uint64(srcPeerAS)
+	if len(path) > 0 {
+		fields["bgpNextAdjacentAsNumber"] = uint64(path[0])
+		fields["bgpDestinationAsNumber"] = uint64(path[len(path)-1])
+	}
+}
+
+// decodeCounterSample decodes a counter sample into a record with the fields
+// of the interface, Ethernet and processor counters it holds.
+func decodeCounterSample(r *reader, expanded bool) record.Map {
+	r.uint32() // Sequence number.
+	var sourceType, sourceIndex uint32
+	if expanded {
+		sourceType, sourceIndex = r.uint32(), r.uint32()
+	} else {
+		id := r.uint32()
+		sourceType, sourceIndex = id>>24, id&0xffffff
+	}
+	fields := record.Map{
+		"dataSourceType":  uint64(sourceType),
+		"dataSourceIndex": uint64(sourceIndex),
+	}
+
+	numRecords := r.uint32()
+	for i := uint32(0); i < numRecords && r.err == nil; i++ {
+		format := r.uint32()
+		data := reader{data: r.opaque()}
+		if r.err != nil || format>>12 != 0 {
+			continue
+		}
+		switch format & 0xfff {
+		case counterGenericIf:
+			decodeCounters(&data, fields, genericIfCounters)
+		case counterEthernet:
+			decodeCounters(&data, fields, ethernetCounters)
+		case counterProcessor:
+			for _, name := range []string{"cpu5s", "cpu1m", "cpu5m"} {
+				// Percentages are sent multiplied by 100.
+				fields[name] = float64(data.uint32()) / 100
+			}
+			fields["totalMemory"] = data.uint64()
+			fields["freeMemory"] = data.uint64()
+		}
+		if data.err != nil {
+			r.err = data.err
+		}
+	}
+	return fields
+}
+
+type counter struct {
+	name string
+	// size is the size of the counter in bytes.
+	size int
+}
+
+var genericIfCounters = []counter{
+	{"ifIndex", 4},
+	{"ifType", 4},
+	{"ifSpeed", 8},
+	{"ifDirection", 4},
+	{"ifStatus", 4},
+	{"ifInOctets", 8},
+	{"ifInUcastPkts", 4},
+	{"ifInMulticastPkts", 4},
+	{"ifInBroadcastPkts", 4},
+	{"ifInDiscards", 4},
+	{"ifInErrors", 4},
+	{"ifInUnknownProtos", 4},
+	{"ifOutOctets", 8},
+	{"ifOutUcastPkts", 4},
+	{"ifOutMulticastPkts", 4},
+	{"ifOutBroadcastPkts", 4},
+	{"ifOutDiscards", 4},
+	{"ifOutErrors", 4},
+	{"ifPromiscuousMode", 4},
+}
+
+var ethernetCounters = []counter{
+	{"dot3StatsAlignmentErrors", 4},
+	{"dot3StatsFCSErrors", 4},
+	{"dot3StatsSingleCollisionFrames", 4},
+	{"dot3StatsMultipleCollisionFrames", 4},
+	{"dot3StatsSQETestErrors", 4},
+	{"dot3StatsDeferredTransmissions", 4},
+	{"dot3StatsLateCollisions", 4},
+	{"dot3StatsExcessiveCollisions", 4},
+	{"dot3StatsInternalMacTransmitErrors", 4},
+	{"dot3StatsCarrierSenseErrors", 4},
+	{"dot3StatsFrameTooLongs", 4},
+	{"dot3StatsInternalMacReceiveErrors", 4},
+	{"dot3StatsSymbolErrors", 4},
+}
+
+func decodeCounters(r *reader, fields record.Map, counters []counter) {
+	for _, c := range counters {
+		if c.size == 8 {
+			fields[c.name] = r.uint64()
+		} else {
+			fields[c.name] = uint64(r.uint32())
+		}
+	}
+}
+
+// mac returns a copy of the MAC address at the start of b.
+func mac(b []byte) net.HardwareAddr {
+	if len(b) < 6 {
+		return nil
+	}
+	return net.HardwareAddr(bytes.Clone(b[:6]))
+}
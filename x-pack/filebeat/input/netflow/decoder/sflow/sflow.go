@@ -0,0 +1,198 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/config"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/protocol"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/record"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+const (
+	ProtocolName = "sflow"
+	// ProtocolID is the value of the first two bytes of sFlow datagrams. They
+	// start with a 32-bit version number, which sets them apart from NetFlow
+	// packets and their 16-bit version.
+	ProtocolID uint16 = 0
+	LogPrefix         = "[sflow] "
+
+	datagramVersion = 5
+)
+
+// Sample formats of the standard enterprise.
+const (
+	formatFlowSample            = 1
+	formatCounterSample         = 2
+	formatExpandedFlowSample    = 3
+	formatExpandedCounterSample = 4
+)
+
+var errShortRead = errors.New("sflow datagram truncated")
+
+type SFlowProtocol struct {
+	logger *logp.Logger
+	now    func() time.Time
+}
+
+func init() {
+	if err := protocol.Registry.Register(ProtocolName, New); err != nil {
+		panic(err)
+	}
+}
+
+func New(config config.Config) protocol.Protocol {
+	return &SFlowProtocol{
+		logger: config.LogOutput().Named(LogPrefix),
+		now:    time.Now,
+	}
+}
+
+func (p *SFlowProtocol) Version() uint16 {
+	return ProtocolID
+}
+
+func (SFlowProtocol) Start() error {
+	return nil
+}
+
+func (SFlowProtocol) Stop() error {
+	return nil
+}
+
+// OnPacket decodes an sFlow v5 datagram. Flow samples are returned as Flow
+// records and counter samples as Counters records. Samples and records of
+// unknown formats are skipped.
+func (p *SFlowProtocol) OnPacket(buf *bytes.Buffer, source net.Addr) ([]record.Record, error) {
+	r := reader{data: buf.Bytes()}
+	version := r.uint32()
+	if r.err == nil && version != datagramVersion {
+		return nil, fmt.Errorf("unsupported sflow version %d", version)
+	}
+	agent := r.address()
+	subAgentID := r.uint32()
+	r.uint32() // Sequence number.
+	uptime := r.uint32()
+	numSamples := r.uint32()
+	if r.err != nil {
+		p.logger.Debugf("Failed parsing packet: %v", r.err)
+		return nil, fmt.Errorf("error reading sflow header: %w", r.err)
+	}
+
+	timestamp := p.now().UTC()
+	metadata := record.Map{
+		"version":      uint64(version),
+		"uptimeMillis": uint64(uptime),
+		"address":      source.String(),
+		"subAgentId":   uint64(subAgentID),
+	}
+	if agent != nil {
+		metadata["agentAddress"] = agent
+	}
+
+	var records []record.Record
+	for i := uint32(0); i < numSamples; i++ {
+		format := r.uint32()
+		sample := reader{data: r.opaque()}
+		if r.err != nil {
+			return nil, fmt.Errorf("error reading sflow sample: %w", r.err)
+		}
+		if enterprise := format >> 12; enterprise != 0 {
+			continue
+		}
+
+		var rec record.Record
+		switch format & 0xfff {
+		case formatFlowSample:
+			rec = record.Record{Type: record.Flow, Fields: decodeFlowSample(&sample, false)}
+		case formatExpandedFlowSample:
+			rec = record.Record{Type: record.Flow, Fields: decodeFlowSample(&sample, true)}
+		case formatCounterSample:
+			rec = record.Record{Type: record.Counters, Fields: decodeCounterSample(&sample, false)}
+		case formatExpandedCounterSample:
+			rec = record.Record{Type: record.Counters, Fields: decodeCounterSample(&sample, true)}
+		default:
+			continue
+		}
+		if sample.err != nil {
+			p.logger.Debugf("Skipping sample of format %d: %v", format, sample.err)
+			continue
+		}
+		rec.Timestamp = timestamp
+		rec.Exporter = metadata
+		records = append(records, rec)
+	}
+	buf.Next(r.off)
+	return records, nil
+}
+
+// reader decodes the XDR encoded fields of sFlow datagrams. The first error
+// is kept and reads after it return zero values.
+type reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data)-r.off {
+		r.err = errShortRead
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// opaque reads variable-length opaque data, which is padded to a multiple of
+// four bytes.
+func (r *reader) opaque() []byte {
+	n := int(r.uint32())
+	b := r.next((n + 3) &^ 3)
+	if b == nil {
+		return nil
+	}
+	return b[:n]
+}
+
+// address reads an IPv4 or IPv6 address preceded by its type. Addresses of
+// unknown type are returned as nil.
+func (r *reader) address() net.IP {
+	switch r.uint32() {
+	case 1:
+		if b := r.next(net.IPv4len); b != nil {
+			return net.IP(bytes.Clone(b))
+		}
+	case 2:
+		if b := r.next(net.IPv6len); b != nil {
+			return net.IP(bytes.Clone(b))
+		}
+	}
+	return nil
+}
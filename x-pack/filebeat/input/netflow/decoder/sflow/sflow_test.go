@@ -0,0 +1,185 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sflow
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/config"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/record"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/test"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+var receiveTime = time.Date(2026, 5, 12, 9, 30, 0, 0, time.UTC)
+
+func newTestProtocol(t *testing.T) *SFlowProtocol {
+	t.Helper()
+	proto, ok := New(config.Defaults(logp.NewLogger("sflow_test"))).(*SFlowProtocol)
+	require.True(t, ok)
+	proto.now = func() time.Time { return receiveTime }
+	return proto
+}
+
+// readPCAP returns the records of the datagrams in a capture file.
+func readPCAP(t *testing.T, proto *SFlowProtocol, file string) [][]record.Record {
+	t.Helper()
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := pcapgo.NewReader(f)
+	require.NoError(t, err)
+
+	var datagrams [][]record.Record
+	source := test.MakeAddress(t, "192.0.2.10:50000")
+	for pkt := range gopacket.NewPacketSource(r, r.LinkType()).Packets() {
+		buf := bytes.NewBuffer(pkt.TransportLayer().LayerPayload())
+		records, err := proto.OnPacket(buf, source)
+		require.NoError(t, err)
+		assert.Zero(t, buf.Len(), "datagram not fully consumed")
+		datagrams = append(datagrams, records)
+	}
+	return datagrams
+}
+
+func TestSFlowProtocol_New(t *testing.T) {
+	proto := New(config.Defaults(logp.NewLogger("sflow_test")))
+
+	assert.Nil(t, proto.Start())
+	assert.Equal(t, uint16(0), proto.Version())
+	assert.Nil(t, proto.Stop())
+}
+
+func TestSFlowProtocol_OnPacket(t *testing.T) {
+	datagrams := readPCAP(t, newTestProtocol(t), "testdata/sflow_v5.pcap")
+	require.Len(t, datagrams, 2)
+
+	// The first datagram has compact samples from an IPv4 agent. The sample
+	// of a vendor-specific format is skipped.
+	records := datagrams[0]
+	require.Len(t, records, 2)
+
+	flow := records[0]
+	assert.Equal(t, record.Flow, flow.Type)
+	assert.Equal(t, receiveTime, flow.Timestamp)
+	test.AssertMapEqual(t, record.Map{
+		"version":      uint64(5),
+		"uptimeMillis": uint64(3600000),
+		"address":      "192.0.2.10:50000",
+		"agentAddress": net.ParseIP("192.0.2.10").To4(),
+		"subAgentId":   uint64(0),
+	}, flow.Exporter)
+	test.AssertMapEqual(t, record.Map{
+		"samplingPacketInterval":      uint64(2048),
+		"samplingPopulation":          uint64(409600),
+		"ingressInterface":            uint64(7),
+		"egressInterface":             uint64(9),
+		"dataLinkFrameSize":           uint64(1262),
+		"octetDeltaCount":             uint64(1262 * 2048),
+		"packetDeltaCount":            uint64(2048),
+		"sourceMacAddress":            net.HardwareAddr{0x00, 0x1b, 0x21, 0xaa, 0xbb, 0x01},
+		"destinationMacAddress":       net.HardwareAddr{0x00, 0x1b, 0x21, 0xaa, 0xbb, 0x02},
+		"ethernetType":                uint64(0x0800),
+		"vlanId":                      uint64(120),
+		"dot1qPriority":               uint64(3),
+		"postVlanId":                  uint64(130),
+		"ipVersion":                   uint64(4),
+		"sourceIPv4Address":           net.ParseIP("10.1.1.10").To4(),
+		"destinationIPv4Address":      net.ParseIP("93.184.216.34").To4(),
+		"protocolIdentifier":          uint64(6),
+		"ipClassOfService":            uint64(0x10),
+		"ipTTL":                       uint64(63),
+		"sourceTransportPort":         uint64(51000),
+		"destinationTransportPort":    uint64(443),
+		"tcpControlBits":              uint64(0x18),
+		"ipNextHopIPv4Address":        net.ParseIP("10.1.1.1").To4(),
+		"sourceIPv4PrefixLength":      uint64(24),
+		"destinationIPv4PrefixLength": uint64(16),
+		"bgpNextHopIPv4Address":       net.ParseIP("192.0.2.1").To4(),
+		"bgpSourceAsNumber":           uint64(65001),
+		"bgpPrevAdjacentAsNumber":     uint64(65002),
+		"bgpNextAdjacentAsNumber":     uint64(64500),
+		"bgpDestinationAsNumber":      uint64(15169),
+	}, flow.Fields)
+
+	counters := records[1]
+	assert.Equal(t, record.Counters, counters.Type)
+	assert.Equal(t, uint64(0), counters.Fields["dataSourceType"])
+	assert.Equal(t, uint64(7), counters.Fields["dataSourceIndex"])
+	assert.Equal(t, uint64(7), counters.Fields["ifIndex"])
+	assert.Equal(t, uint64(10000000000), counters.Fields["ifSpeed"])
+	assert.Equal(t, uint64(123456789012), counters.Fields["ifInOctets"])
+	assert.Equal(t, uint64(987654321098), counters.Fields["ifOutOctets"])
+	assert.Equal(t, uint64(4), counters.Fields["ifOutErrors"])
+	assert.Equal(t, uint64(1), counters.Fields["dot3StatsFCSErrors"])
+	assert.Equal(t, uint64(12), counters.Fields["dot3StatsSymbolErrors"])
+
+	// The second datagram has expanded samples from an IPv6 agent.
+	records = datagrams[1]
+	require.Len(t, records, 3)
+	assert.Equal(t, net.ParseIP("2001:db8::1"), records[0].Exporter["agentAddress"])
+	assert.Equal(t, uint64(1), records[0].Exporter["subAgentId"])
+
+	flow = records[0]
+	assert.Equal(t, record.Flow, flow.Type)
+	assert.Equal(t, uint64(9), flow.Fields["ingressInterface"])
+	assert.NotContains(t, flow.Fields, "egressInterface", "internal interface")
+	assert.Equal(t, uint64(6), flow.Fields["ipVersion"])
+	assert.Equal(t, net.ParseIP("2001:db8::10"), flow.Fields["sourceIPv6Address"])
+	assert.Equal(t, net.ParseIP("2001:db8:1::53"), flow.Fields["destinationIPv6Address"])
+	assert.Equal(t, uint64(17), flow.Fields["protocolIdentifier"])
+	assert.Equal(t, uint64(0x12345), flow.Fields["flowLabelIPv6"])
+	assert.Equal(t, uint64(40000), flow.Fields["sourceTransportPort"])
+	assert.Equal(t, uint64(53), flow.Fields["destinationTransportPort"])
+	assert.Equal(t, uint64(512), flow.Fields["packetDeltaCount"])
+
+	flow = records[1]
+	assert.NotContains(t, flow.Fields, "egressInterface", "discarded packet")
+	assert.Equal(t, net.ParseIP("10.2.2.2").To4(), flow.Fields["sourceIPv4Address"])
+	assert.Equal(t, net.ParseIP("10.3.3.3").To4(), flow.Fields["destinationIPv4Address"])
+	assert.Equal(t, uint64(1), flow.Fields["protocolIdentifier"])
+	assert.Equal(t, uint64(84*512), flow.Fields["octetDeltaCount"])
+
+	counters = records[2]
+	assert.Equal(t, record.Counters, counters.Type)
+	test.AssertMapEqual(t, record.Map{
+		"dataSourceType":  uint64(2),
+		"dataSourceIndex": uint64(1),
+		"cpu5s":           12.5,
+		"cpu1m":           8.3,
+		"cpu5m":           7.05,
+		"totalMemory":     uint64(8589934592),
+		"freeMemory":      uint64(2147483648),
+	}, counters.Fields)
+}
+
+func TestSFlowProtocol_InvalidDatagrams(t *testing.T) {
+	proto := newTestProtocol(t)
+	source := test.MakeAddress(t, "192.0.2.10:50000")
+
+	_, err := proto.OnPacket(bytes.NewBuffer([]byte{0, 0, 0, 4, 0, 0, 0, 1}), source)
+	assert.ErrorContains(t, err, "unsupported sflow version 4")
+
+	_, err = proto.OnPacket(bytes.NewBuffer([]byte{0, 0, 0, 5, 0, 0, 0, 1, 192, 0}), source)
+	assert.ErrorIs(t, err, errShortRead)
+
+	// A datagram announcing more samples than it holds.
+	header := []byte{
+		0, 0, 0, 5, 0, 0, 0, 1, 192, 0, 2, 10,
+		0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 2,
+		0, 0, 0, 1, 0, 0, 0, 64,
+	}
+	_, err = proto.OnPacket(bytes.NewBuffer(header), source)
+	assert.ErrorIs(t, err, errShortRead)
+}
@@ -35,6 +35,24 @@ type Template struct {
 type FieldTemplate struct {
 	Length uint16
 	Info   *fields.Field
+	// Key is the field identifier from the template definition. It is kept
+	// to allow the template to be persisted.
+	Key fields.Key
+}
+
+// Equal returns whether two templates define the same records.
+func (t *Template) Equal(other *Template) bool {
+	if t.ID != other.ID || t.Length != other.Length || t.VariableLength != other.VariableLength ||
+		t.ScopeFields != other.ScopeFields || t.IsOptions != other.IsOptions ||
+		len(t.Fields) != len(other.Fields) {
+		return false
+	}
+	for i, f := range t.Fields {
+		if f.Key != other.Fields[i].Key || f.Length != other.Fields[i].Length {
+			return false
+		}
+	}
+	return true
 }
 
 func PopulateFieldMap(dest record.Map, fields []FieldTemplate, variableLength bool, buffer *bytes.Buffer) error {
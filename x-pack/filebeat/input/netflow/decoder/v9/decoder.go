@@ -103,36 +103,43 @@ func (d DecoderV9) GetFields() fields.FieldDict {
 }
 
 func ReadFields(d Decoder, buf *bytes.Buffer, count int) (record template.Template, err error) {
-	knownFields := d.GetFields()
-	logger := d.GetLogger()
-	record.Fields = make([]template.FieldTemplate, count)
+	record.Fields = make([]template.FieldTemplate, 0, count)
 	for i := 0; i < count; i++ {
 		key, length, err := d.ReadFieldDefinition(buf)
 		if err != nil {
 			return template.Template{}, io.EOF
 		}
-		field := template.FieldTemplate{
-			Length: length,
-		}
-		if length == template.VariableLength {
-			record.VariableLength = true
-			record.Length += 1
-		} else {
-			record.Length += int(field.Length)
-		}
-		if fieldInfo, found := knownFields[key]; found {
-			min, max := fieldInfo.Decoder.MinLength(), fieldInfo.Decoder.MaxLength()
-			if length == template.VariableLength || min <= field.Length && field.Length <= max {
-				field.Info = fieldInfo
-			} else if logger != nil {
-				logger.Debugf("Size of field %s in template is out of bounds (size=%d, min=%d, max=%d)", fieldInfo.Name, field.Length, min, max)
-			}
+		AddField(d, &record, key, length)
+	}
+	return record, nil
+}
+
+// AddField appends the field with the given key and length to a template.
+// The field is decoded only if it is known to the decoder and its length is
+// within the bounds of the field's type.
+func AddField(d Decoder, record *template.Template, key fields.Key, length uint16) {
+	logger := d.GetLogger()
+	field := template.FieldTemplate{
+		Length: length,
+		Key:    key,
+	}
+	if length == template.VariableLength {
+		record.VariableLength = true
+		record.Length += 1
+	} else {
+		record.Length += int(field.Length)
+	}
+	if fieldInfo, found := d.GetFields()[key]; found {
+		min, max := fieldInfo.Decoder.MinLength(), fieldInfo.Decoder.MaxLength()
+		if length == template.VariableLength || min <= field.Length && field.Length <= max {
+			field.Info = fieldInfo
 		} else if logger != nil {
-			logger.Debugf("Field %v in template not found", key)
+			logger.Debugf("Size of field %s in template is out of bounds (size=%d, min=%d, max=%d)", fieldInfo.Name, field.Length, min, max)
 		}
-		record.Fields[i] = field
+	} else if logger != nil {
+		logger.Debugf("Field %v in template not found", key)
 	}
-	return record, nil
+	record.Fields = append(record.Fields, field)
 }
 
 func ReadTemplateFlowSet(d Decoder, buf *bytes.Buffer) (templates []*template.Template, err error) {
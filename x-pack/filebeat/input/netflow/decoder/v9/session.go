@@ -48,6 +48,16 @@ type SessionState struct {
 	lastSequence uint32
 	logger       *logp.Logger
 	Delete       atomic.Bool
+	// storeKey is the key of the session in the template store.
+	storeKey string
+	// restored is set when the templates were restored from the store and no
+	// packet has been received since. The sequence number of the first packet
+	// does not follow the last one before the restart, so it can't be used to
+	// detect a reset.
+	restored bool
+	// savedAt is the time, in Unix nanoseconds, when the templates were last
+	// persisted in the template store.
+	savedAt atomic.Int64
 }
 
 // NewSession creates a new session.
@@ -58,12 +68,15 @@ func NewSession(logger *logp.Logger) *SessionState {
 	}
 }
 
-// AddTemplate adds the passed template.
-func (s *SessionState) AddTemplate(t *template.Template) {
+// AddTemplate adds the passed template. It returns whether the template is
+// new or different from the one previously received with the same ID.
+func (s *SessionState) AddTemplate(t *template.Template) (changed bool) {
 	s.logger.Debugf("state %p addTemplate %d %p", s, t.ID, t)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	prev, found := s.Templates[TemplateKey(t.ID)]
 	s.Templates[TemplateKey(t.ID)] = &TemplateWrapper{Template: t}
+	return !found || !prev.Template.Equal(t)
 }
 
 // GetTemplate returns a template by ID.
@@ -111,7 +124,9 @@ func (s *SessionState) CheckReset(seqNum uint32) (prev uint32, reset bool) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	prev = s.lastSequence
-	if reset = !isValidSequence(prev, seqNum); reset {
+	if s.restored {
+		s.restored = false
+	} else if reset = !isValidSequence(prev, seqNum); reset {
 		s.Templates = make(map[TemplateKey]*TemplateWrapper)
 	}
 	s.lastSequence = seqNum
@@ -128,6 +143,7 @@ type SessionMap struct {
 	Sessions map[SessionKey]*SessionState
 	logger   *logp.Logger
 	metric   config.ActiveSessionsMetric
+	store    *templateStore
 }
 
 // NewSessionMap returns a new SessionMap.
@@ -158,6 +174,13 @@ func (m *SessionMap) increaseActiveSessions() {
 // GetOrCreate looks up the given session key and returns an existing session
 // or creates a new one.
 func (m *SessionMap) GetOrCreate(key SessionKey) *SessionState {
+	return m.getOrCreate(key, 0)
+}
+
+// getOrCreate is like GetOrCreate, but the templates of a new session are
+// restored from the template store, if any. The version is the protocol
+// version of the packet that caused the lookup.
+func (m *SessionMap) getOrCreate(key SessionKey, version uint16) *SessionState {
 	m.mutex.RLock()
 	session, found := m.Sessions[key]
 	if found {
@@ -168,6 +191,10 @@ func (m *SessionMap) GetOrCreate(key SessionKey) *SessionState {
 		m.mutex.Lock()
 		if session, found = m.Sessions[key]; !found {
 			session = NewSession(m.logger)
+			if m.store != nil && version != 0 {
+				session.storeKey = m.store.key(version, key)
+				m.store.restore(session)
+			}
 			m.Sessions[key] = session
 			m.increaseActiveSessions()
 		}
@@ -184,6 +211,9 @@ func (m *SessionMap) cleanup() (aliveSession int, removedSession int, aliveTempl
 		a, r := session.ExpireTemplates()
 		aliveTemplates += a
 		removedTemplates += r
+		if r > 0 && m.store != nil && session.storeKey != "" {
+			m.store.save(session)
+		}
 		if !session.Delete.CompareAndSwap(false, true) {
 			toDelete = append(toDelete, key)
 		}
@@ -195,6 +225,9 @@ func (m *SessionMap) cleanup() (aliveSession int, removedSession int, aliveTempl
 		for _, key := range toDelete {
 			if session, found := m.Sessions[key]; found && session.Delete.Load() {
 				delete(m.Sessions, key)
+				if m.store != nil && session.storeKey != "" {
+					m.store.remove(session)
+				}
 				removedSession++
 				m.decreaseActiveSessions()
 			}
@@ -215,6 +248,9 @@ func (m *SessionMap) CleanupLoop(interval time.Duration, done <-chan struct{}) {
 			return
 
 		case <-t.C:
+			if m.store != nil {
+				m.store.removeExpired()
+			}
 			aliveS, removedS, aliveT, removedT := m.cleanup()
 			if removedS > 0 || removedT > 0 {
 				m.logger.Debugf("Expired %d sessions (%d remain) / %d templates (%d remain)", removedS, aliveS, removedT, aliveT)
@@ -0,0 +1,173 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v9
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/config"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/fields"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/template"
+)
+
+const templateStorePrefix = "netflow::templates::"
+
+// storedSession is the persisted form of the templates of a session.
+type storedSession struct {
+	Updated   time.Time        `json:"updated" struct:"updated"`
+	Templates []storedTemplate `json:"templates" struct:"templates"`
+}
+
+type storedTemplate struct {
+	ID          uint16        `json:"id" struct:"id"`
+	Fields      []storedField `json:"fields" struct:"fields"`
+	ScopeFields int           `json:"scope_fields" struct:"scope_fields"`
+	IsOptions   bool          `json:"options" struct:"options"`
+}
+
+type storedField struct {
+	EnterpriseID uint32 `json:"pen" struct:"pen"`
+	FieldID      uint16 `json:"id" struct:"id"`
+	Length       uint16 `json:"length" struct:"length"`
+}
+
+// templateStore persists the templates of sessions, so that flows from
+// exporters that resend their templates infrequently can be decoded after a
+// restart. Sessions are saved when they receive new or changed templates, and
+// at least once every half of the expiration timeout while they keep
+// receiving templates. They expire like in-memory sessions, after the
+// expiration timeout.
+type templateStore struct {
+	store   config.TemplateStore
+	prefix  string
+	decoder Decoder
+	timeout time.Duration
+	logger  *logp.Logger
+	now     func() time.Time
+}
+
+func newTemplateStore(store config.TemplateStore, id string, decoder Decoder, timeout time.Duration, logger *logp.Logger) *templateStore {
+	return &templateStore{
+		store:   store,
+		prefix:  templateStorePrefix + id + "::",
+		decoder: decoder,
+		timeout: timeout,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// key returns the store key of a session. The protocol version is part of it
+// as v9 and IPFIX sessions from the same exporter are different sessions.
+func (s *templateStore) key(version uint16, session SessionKey) string {
+	return fmt.Sprintf("%sv%d::%s::%d", s.prefix, version, session.Addr, session.SourceID)
+}
+
+func (s *templateStore) expired(updated time.Time) bool {
+	return s.timeout != 0 && s.now().Sub(updated) > s.timeout
+}
+
+// stale returns whether the stored templates of a session must be saved again
+// so that they don't expire.
+func (s *templateStore) stale(session *SessionState) bool {
+	return s.timeout != 0 && s.now().Sub(time.Unix(0, session.savedAt.Load())) > s.timeout/2
+}
+
+// restore loads the persisted templates of a session.
+func (s *templateStore) restore(session *SessionState) {
+	var stored storedSession
+	if err := s.store.Get(session.storeKey, &stored); err != nil {
+		s.logger.Debugf("No stored templates for %s: %v", session.storeKey, err)
+		return
+	}
+	if s.expired(stored.Updated) {
+		s.remove(session)
+		return
+	}
+	for _, st := range stored.Templates {
+		t := &template.Template{
+			ID:          st.ID,
+			Fields:      make([]template.FieldTemplate, 0, len(st.Fields)),
+			ScopeFields: st.ScopeFields,
+			IsOptions:   st.IsOptions,
+		}
+		for _, f := range st.Fields {
+			AddField(s.decoder, t, fields.Key{EnterpriseID: f.EnterpriseID, FieldID: f.FieldID}, f.Length)
+		}
+		session.Templates[TemplateKey(t.ID)] = &TemplateWrapper{Template: t}
+	}
+	session.restored = len(session.Templates) > 0
+	session.savedAt.Store(stored.Updated.UnixNano())
+	s.logger.Debugf("Restored %d templates for %s", len(stored.Templates), session.storeKey)
+}
+
+// save persists the current templates of a session.
+func (s *templateStore) save(session *SessionState) {
+	stored := storedSession{Updated: s.now().UTC()}
+	session.mutex.RLock()
+	for _, wrapper := range session.Templates {
+		t := wrapper.Template
+		st := storedTemplate{
+			ID:          t.ID,
+			Fields:      make([]storedField, len(t.Fields)),
+			ScopeFields: t.ScopeFields,
+			IsOptions:   t.IsOptions,
+		}
+		for i, f := range t.Fields {
+			st.Fields[i] = storedField{EnterpriseID: f.Key.EnterpriseID, FieldID: f.Key.FieldID, Length: f.Length}
+		}
+		stored.Templates = append(stored.Templates, st)
+	}
+	session.mutex.RUnlock()
+	if len(stored.Templates) == 0 {
+		s.remove(session)
+		return
+	}
+	if err := s.store.Set(session.storeKey, stored); err != nil {
+		s.logger.Warnf("Failed to persist templates for %s: %v", session.storeKey, err)
+		return
+	}
+	session.savedAt.Store(stored.Updated.UnixNano())
+}
+
+// remove deletes the persisted templates of a session.
+func (s *templateStore) remove(session *SessionState) {
+	if err := s.store.Remove(session.storeKey); err != nil {
+		s.logger.Warnf("Failed to remove stored templates for %s: %v", session.storeKey, err)
+	}
+}
+
+// removeExpired deletes the persisted sessions of this collector that have
+// not been updated within the expiration timeout.
+func (s *templateStore) removeExpired() {
+	var expired []string
+	err := s.store.Each(func(key string, dec statestore.ValueDecoder) (bool, error) {
+		if !strings.HasPrefix(key, s.prefix) {
+			return true, nil
+		}
+		var stored storedSession
+		if err := dec.Decode(&stored); err != nil || s.expired(stored.Updated) {
+			expired = append(expired, key)
+		}
+		return true, nil
+	})
+	if err != nil {
+		s.logger.Warnf("Failed to read stored templates: %v", err)
+		return
+	}
+	for _, key := range expired {
+		if err := s.store.Remove(key); err != nil {
+			s.logger.Warnf("Failed to remove stored templates for %s: %v", key, err)
+		}
+	}
+	if len(expired) > 0 {
+		s.logger.Debugf("Removed %d expired sessions from the template store", len(expired))
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v9
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/storetest"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/config"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/test"
+)
+
+func openTestStore(t *testing.T) *statestore.Store {
+	t.Helper()
+	reg := statestore.NewRegistry(storetest.NewMemoryStoreBackend())
+	t.Cleanup(func() { reg.Close() })
+	store, err := reg.Get("netflow")
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func newStoreProtocol(t *testing.T, store config.TemplateStore) *NetflowV9Protocol {
+	t.Helper()
+	cfg := config.Defaults(logp.L())
+	cfg.WithTemplateStore(store, ":2055")
+	proto, ok := New(cfg).(*NetflowV9Protocol)
+	require.True(t, ok)
+	return proto
+}
+
+var (
+	templatePacket = []uint16{
+		// Header
+		// Version, Count, Uptime, Ts, SeqNo, Source
+		9, 2, 11, 11, 22, 22, 0, 1, 0, 1234,
+		// Set #1 (template)
+		0, 16, /*len of set*/
+		999, 2, /*count*/
+		8, 4, // srcIPv4Address
+		11, 2, // dstTransportPort
+		// Set #2 (options template)
+		1, 20, /*len of set*/
+		998, 4 /*scope len*/, 4, /*opts len*/
+		1, 4, // Fields
+		2, 4,
+		0, // Padding
+	}
+	dataPacket = []uint16{
+		// Header
+		// Version, Count, Uptime, Ts, SeqNo, Source
+		9, 1, 11, 11, 22, 22, 0x1234, 0x5678, 0, 1234,
+		// Set #1 (data)
+		999, 10, /*len of set*/
+		0xc0a8, 0x0101, 53,
+	}
+)
+
+func TestTemplateStore_Restore(t *testing.T) {
+	store := openTestStore(t)
+	addr := test.MakeAddress(t, "127.0.0.1:12345")
+
+	proto := newStoreProtocol(t, store)
+	require.NoError(t, proto.Start())
+	flows, err := proto.OnPacket(test.MakePacket(templatePacket), addr)
+	require.NoError(t, err)
+	assert.Empty(t, flows)
+	require.NoError(t, proto.Stop())
+
+	// After a restart, data is decoded with the stored templates, even if the
+	// sequence number of the first packet looks like a reset.
+	proto = newStoreProtocol(t, store)
+	require.NoError(t, proto.Start())
+	defer proto.Stop()
+	flows, err = proto.OnPacket(test.MakePacket(dataPacket), addr)
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, uint64(53), flows[0].Fields["destinationTransportPort"])
+
+	session := proto.Session.GetOrCreate(MakeSessionKey(addr, 1234, false))
+	opts := session.GetTemplate(998)
+	require.NotNil(t, opts)
+	assert.True(t, opts.IsOptions)
+	assert.Equal(t, 1, opts.ScopeFields)
+	assert.Equal(t, 8, opts.Length)
+
+	// Templates are not shared with other exporters.
+	flows, err = proto.OnPacket(test.MakePacket(dataPacket), test.MakeAddress(t, "127.0.0.2:12345"))
+	require.NoError(t, err)
+	assert.Empty(t, flows)
+}
+
+func TestTemplateStore_Expiration(t *testing.T) {
+	store := openTestStore(t)
+	addr := test.MakeAddress(t, "127.0.0.1:12345")
+
+	proto := newStoreProtocol(t, store)
+	_, err := proto.OnPacket(test.MakePacket(templatePacket), addr)
+	require.NoError(t, err)
+	key := proto.Session.store.key(ProtocolID, MakeSessionKey(addr, 1234, false))
+	has, err := store.Has(key)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	// Stored sessions older than the expiration timeout are removed on start.
+	proto = newStoreProtocol(t, store)
+	proto.Session.store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	require.NoError(t, proto.Start())
+	defer proto.Stop()
+	has, err = store.Has(key)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	flows, err := proto.OnPacket(test.MakePacket(dataPacket), addr)
+	require.NoError(t, err)
+	assert.Empty(t, flows)
+}
+
+func TestTemplateStore_Reset(t *testing.T) {
+	store := openTestStore(t)
+	addr := test.MakeAddress(t, "127.0.0.1:12345")
+
+	proto := newStoreProtocol(t, store)
+	_, err := proto.OnPacket(test.MakePacket(templatePacket), addr)
+	require.NoError(t, err)
+
+	// A reset of the exporter invalidates the stored templates.
+	_, err = proto.OnPacket(test.MakePacket(dataPacket), addr)
+	require.NoError(t, err)
+	key := proto.Session.store.key(ProtocolID, MakeSessionKey(addr, 1234, false))
+	has, err := store.Has(key)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+// countingStore counts the writes to the template store.
+type countingStore struct {
+	config.TemplateStore
+	sets int
+}
+
+func (s *countingStore) Set(key string, from interface{}) error {
+	s.sets++
+	return s.TemplateStore.Set(key, from)
+}
+
+func TestTemplateStore_SaveOnlyChanges(t *testing.T) {
+	store := &countingStore{TemplateStore: openTestStore(t)}
+	addr := test.MakeAddress(t, "127.0.0.1:12345")
+
+	proto := newStoreProtocol(t, store)
+	now := time.Now()
+	proto.Session.store.now = func() time.Time { return now }
+	packet := func(seq uint16, set ...uint16) *bytes.Buffer {
+		header := []uint16{9, 1, 11, 11, 22, 22, 0, seq, 0, 1234}
+		return test.MakePacket(append(header, set...))
+	}
+	template := func(port uint16) []uint16 {
+		return []uint16{0, 16, 999, 2, 8, 4, port, 2}
+	}
+
+	_, err := proto.OnPacket(packet(1, template(11)...), addr)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.sets)
+
+	// An unchanged template refresh does not write to the store.
+	_, err = proto.OnPacket(packet(2, template(11)...), addr)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.sets)
+
+	// A changed template is saved.
+	_, err = proto.OnPacket(packet(3, template(7)...), addr)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.sets)
+
+	// Unchanged templates are saved again before the stored session expires.
+	now = now.Add(45 * time.Minute)
+	_, err = proto.OnPacket(packet(4, template(7)...), addr)
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.sets)
+}
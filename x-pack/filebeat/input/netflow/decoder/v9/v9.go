@@ -67,6 +67,10 @@ func NewProtocolWithDecoder(decoder Decoder, config config.Config, logger *logp.
 		pd.cache = newPendingTemplatesCache()
 	}
 
+	if store, id := config.TemplateStore(); store != nil {
+		pd.Session.store = newTemplateStore(store, id, decoder, pd.timeout, logger)
+	}
+
 	return pd
 }
 
@@ -75,6 +79,10 @@ func (*NetflowV9Protocol) Version() uint16 {
 }
 
 func (p *NetflowV9Protocol) Start() error {
+	if p.Session.store != nil {
+		p.Session.store.removeExpired()
+	}
+
 	if p.timeout != time.Duration(0) {
 		go p.Session.CleanupLoop(p.timeout, p.ctx.Done())
 	}
@@ -104,13 +112,16 @@ func (p *NetflowV9Protocol) OnPacket(buf *bytes.Buffer, source net.Addr) (flows
 
 	sessionKey := MakeSessionKey(source, header.SourceID, p.shareTemplates)
 
-	session := p.Session.GetOrCreate(sessionKey)
+	session := p.Session.getOrCreate(sessionKey, header.Version)
 	remote := source.String()
 
 	p.logger.Debugf("Packet from:%s src:%d seq:%d", remote, header.SourceID, header.SequenceNo)
 	if p.detectReset {
 		if prev, reset := session.CheckReset(header.SequenceNo); reset {
 			p.logger.Debugf("Session %s reset (sequence=%d last=%d)", remote, header.SequenceNo, prev)
+			if p.Session.store != nil {
+				p.Session.store.remove(session)
+			}
 		}
 	}
 
@@ -168,8 +179,18 @@ func (p *NetflowV9Protocol) parseSet(
 	if err != nil {
 		return nil, err
 	}
+	changed := false
+	defer func() {
+		// Exporters resend their templates often, the store is only written
+		// when they change, or to refresh the expiration of the session.
+		if p.Session.store != nil && len(templates) > 0 && (changed || p.Session.store.stale(session)) {
+			p.Session.store.save(session)
+		}
+	}()
 	for _, template := range templates {
-		session.AddTemplate(template)
+		if session.AddTemplate(template) {
+			changed = true
+		}
 
 		if p.cache == nil {
 			continue
@@ -19,5 +19,5 @@ func init() {
 // AssetNetflow returns asset data.
 // This is the base64 encoded zlib format compressed contents of input/netflow.
 func AssetNetflow() string {
-	return "eJysXc2S2ziSvvspFDOHvWx32K6y2+7DnmYndg67O4c57A0BkSkKXSTAAkCp1E+/AfBHpAhQyAR96Imx9X34YSIBZCYy/5r459NfD/86C3M4iRoOwhwqkKC5hfLXw9/UQSp7aFQpTrdfPyUz/nJ4g9vvBwn2VKvrp8PBClvD74e//A/Yv9fq+pdPh0MJptCitULJ3w//8elwOBz+LqAuzeGkVXMYfnngsjz8459//8f/HRyV+fXT4XDyP/vdQ345SN7AvCn3x95a+P1QadW1w98EWnva4q/Dz+btzdt0rUx/OTb6Brer0uXs7yNNuz//OoOHHdRpal5DoXQ5oI5QHo63g3XfBy4g7a+fVt2Aj1ZpC3rVlfn4n3Tkv8Hyklt+0FC7T3+w6mDPMHEfSriIAg72zO1dQPp+9R3+dcb3OGHz3vKy1GDM4t/ic/ek2+7Pfw5d/DfjhOCq9NvYxkHIwz/++bv758NJ6YbPZ2/eJ6M6XQATjy33vaqVrHBd+t+jAX3h7p8PpWq468ff3JRez6I4z2ftcARHbyIds6IBY3nTBjtWcgu4jv1LNODl20Gd0PXfN9J617r2WSPqWoQ/GH5q/ktdPWopXa1WhftgZ24ORwB50J2UQlb/7j5h3z4USpaxebqANkLJYB+FtFAtVkdCN8fFOBAfOgNlYOnxo1F1Z4GB1mq9AEvVHWsIwHr5ZK1SNTuL6szsWYM5q7pccfgp3mao1TWDQFvW8LYVssrtyoxpty61oFlnQBP7dipYq5VVhapXkFEwNlHM/VVUya+hUslbI/70a5+dal4ZRLsLsIXiLMV7t249TtC2tSh6+LEzQoIxv2io4cJlseaJzNmMpOAWKqVv2FmYUcwWGo3A72IZHThb27JOC2Yst8JYUaw/iTkrbVNoDOhfeAXSUijEWmwTUPSRm+64xxe0mp9OovilqLlZT11EiLRlRS1AWjZsyqzfS/iHaLoml0XIHVgMgaHlxRtY1CyoTlrmDlVMg2mVNICHS7iyQkkJhfsieDy95QnJzsJYVWnesGPnJuHLjlxfd+R62ZHrdUeubztyfd+R6zcCl9VcmkYYQ5JGj+ZoUc5UJJkaJEN1jHNP7fkDHt31JR7Xd3eRAZ0790EW9DhCLKTR5H6QMA11PPmfB78rWWV5nT0PQRb0NIRYcLPQM8zUSt6A1kTEMa2IEMMyBppjDSU7aV417vDilWcqvCvFgAgYhiKYC2heAXPXC821Fhc3BBE4Nobxx6p1p30rZH9u5IbJrjkmt+/wEj4s4+UfvHAjJjOcVctEe3lla2vTcIVqn6O/o9Gthkte7wdLFAV64bUohb35e876m0VuGkdxcnf1Uuj+bJmOkyVhih3Krwp/yXf/Sb/d+vtH6BIVnpP+57hrj8eI03jzAVkJCYh7W6HqGhaK4266WnF4812MQmlWgLZ9X5K/5h2M/jQLKE7071Dyp1VNoyRrtWrdoAHzmZU8iRJkAayGC6zNPDEjnLtPUaZJyoxhjlc4txOwstPDRT0iIdERjyzzDQYzY9JqXrylQ0puOTveLKA2IY+qhXxze5jb/XAqZgUXfyJsYY/ooLNmG92b6A3TUAt+FLWwtxXDUakauAwwQG0588oVNWezDRQtnCtwq+EkPnKwrAZZ2XPyN1uy4PTICkzv/vf87je8iPY+uoHMCahaohQVGMvO3Jzdph4wAEdER7i2i/GWrU5sOSnR0dDoNmSTSBiXllTC0ZGXP9aJaYdh3rnQI5SG8c6elRaWW3FJPgg4YIE7+zhIYEOIi6k0rPlg8FGcuayQDTUffoGChpBjYrNNaUrkqc6jPljvicXMYGs1pa13AkRjNygH6kDfposzZmBaozdjaZhRnMFHK3SyRhpAuPvziNJw0mDWuvsZyur1Nr2NMaAFX58cN0ENQTCM4poC0xfsFjKitHCqYz0fT5GW6woILV5BVGckzlrM3NsPy9zxDDGHyn55Z0VnrGpAsxIE4hj3iM09ICz5oh8otgyX8GFn2aMnl5oH7xDxL+fxhMmkjdmAvgi3i0pjuXyM1tkUmjB+p25YXuEY8BOtVdtCyWp+A/2VqcKCZf31AnWzCNH0dkoKTW43ctvvbd4ZHRgICD0wdvSJMyFLWF9QwjiofHjLUSteFtxYeg8GJm+oPfHkyI5HWPjIsYltzzcjCl5T2+6k2GXoF31KXv8gC31rLZR9SI2qVbVe9FE1ibYBDoDg3MYg9gxagmVn4CVo5KV1Qrf8VitexuBRBTMR9J+DDkedYPuTJNG/0UcO4qWwh03BXeu4we2Z7uGmsC0zVgNvUIp8gM/sArHQtM323ZzV6jqYpyhraKRpwBheQQ4FVY2PoZ8UK/eERRvI5kicbWpCUq06PYEPaeyDXdMPMPBhQUtejx1mGni9vk1FZuskNFx5XTMfP45AGcv8YZ5JJRk0rb1Nm+7oLUqOpOvpVkQ4k25PMkCPXMqAfyyqx30sJuOyZIY3be1kIHX+/WrjhRWX3jutOsR378HWanHsbCAAaxuI9sv1qNGx0IhCK5x34ZFgw4G1QQCyHI5m1C44hk1s2H02w6JdbxNWckltVgM3yK/lYMTWzM2wrsU46T0UJ/mirKly/wY3d0R22j0QHr/RZs2PUHstjUH5le1UrHe++sPBJdmuE2AwLS+EXN/vtgighmLaovG3kyUJ9ZK1ZCFflWY0SjNeV+7CfF7vPNtCYCzXNlMb9BxUfTCiaRqhRxN1Qg+mA2kL3PJjDexUd+bcb/v4z95TtMDfcFilr1yXbgkZy22XfkIYrwHhRxPPUKIEaafIjdTOjmh1OhmMtfN0ZceaF2+q8982WZR9FPdJVJ27+GB8ew5pDTO6YKZK/hbX4eOHAyNSQIit4srgw0YOmVsTOaEYrzEjm2AlmAJxDryyM69PTLUgcZI9B55FleqYWOB06JoTxTX8gxlARVmfrkx3NcLBcLoy0zUN1zfWviH1xJX9qSSwlgvMaXqOCjpDwriqVsfZHSjrzVqlwZ1NEn/tPfGDV151tg0cg6Imb4/tzwJh92N0aXikkMIK7rYtjdIXPbidTEIRBZcGDt7SNqD9HDHNZeWDcOnYwHxtYKcDD6nlR3R629a2Tq9bp4yCNrDo4vPQ0QoTM0Zto/sLB2vPmgd8wM+w750PNVFmLRtp0AbsWa2XXRo44mTcBvfHClaoEmE6mF7vsfDrvWiTomha35Y3NqUeSxao9RVmC+V+sdFgdIRr8LrdBDB6lCMqfZTVgEoHSOXOS7OYPILBcWQZ3F9DUGAuDdX8OdLk4sl+FCH7jAg+zLkGfwr2E4z6MiESynvfEBHGsh7EB11zKIrg6QQ1iH4vOUKVvJvEWUCmHm9CHAYaLjGPn0MknRSBZ0Ybc7qby3Okwnp8VjiM03MEN11tM/2WIxXZgToSZHtQRyKMC3U4hjJbtLj7eQ+0SveaLvUWc4fhnrbdvzJRDY1w7Mq3oBsoBbeAd+94NNm9I1rWv3RRpzE4BQEtxamH9aeGVol0F5Foaf7qO270AuFcHCLnkZfIeeM1uxNFhhz5wnccecithgLKYLhsHGSgYKZdR2hFu7kZARBHBQL44p1COt39JxZnRAuXV6ZazOtyD9Gqs6BZwJYUFoXLdwYfFqTx2UG8QCe3N9tSkof1IbjbSK8m7LpMgSG1moP2eb+wDQ4oSnvSMC7NFePanYBeEQZ8rM+RPpSagBuvIx+YK6UHm/6BKGGGjGWFsNiMLnesOyvoW/gCnQ4ntl5zK2wXaPlUKx4VJwdUsqIhNVQ+lxFtvANa4tFD3BkrRHtGi/MIfoMbOiBrQYAzSHmoN5aclUFfgCZwp9d7TgqMYqBxaNEYwUx37FMmYue6/o3xtkUc2GYgwgzV3IIsUo3PHqLhQvYFTwRkN7BnMLogKiyHpCosj6UrrDmc2DpJYXkgSWE5JF1hzdCE4dqzBm4jdsfQGWgJCtgbn4BwuqnmuoJRijHyuzD54cU/02KYGbYfgJuu8be9947r5JRemQbLADynGwaqmJFvC7e8PYWfAUXOpUECrN8/SIILYqyFBFZwXSLGriqm2uSBqitoVghWi0asxxZLh9Bw/ZbYn4Z/sKM4MpBWi+Qv71ADYsrqiYEOsdeoSBoHzIkmfMCj44cWeHwE0QJOgA7hLW6+pQ/J0TDkFUo9fTgaUqzuLHSBICf3oxwCJZquYTQbxogetFMGA8IU0gCXQyR/elSIBw1fBAXrfcxN+Y0VZyjeQpmoov3ssaZQ6a6hBixoUrR7A1YrBpciBIkeDu4ojAOrEUdW8NZ2esw7hvWSeAYlrQ9Hwj4VmoNx5zWH9A4udHitQzaq7GqsecYB1fEPKIg+vhl+DNYLbAAbMzWAyb02N2kDISEpUB96w44h59fzDvdgXC62FbziXSDHQiJ4nQL9mfZeMcQzaoQO+St8f240VofCqVOnUOFW1gLrV7cVxVvyhvLI0EkjKgnJakwcfQLmDVmPASXxsCOG7YQcLL1iwB94lgyEI8+SAA3OOQQM6IxDwMCAOQSoo6jBG7AQmqkHNUaYEqOBlRRW+V15fBtxP9hipzrANZMaLFtbm+HNh7G8eGMltOmz/gjGfbNHNNI1F8N/+ZzLsE6KjSRYZ8JGEiRHgsUI1jmvkQTJUWUxgnV2ayTBj1yCnygCkn95gaR5mD2FVe0wAvhItjc8INEe+TUe55R/wJPyvj1w0BXBnQOzBzwgMRcYB720svdesz73WNUJE/I4RTlsMR3vMLeXFh0O1EPGaCIfb2jgvfPZO1EJb3ui8dI9WhORJgrPoaEAcQHNrHqD1CC9e0CYhnuhjBMv0u9hkscey0TmzgGENKIEZi4FJjpyQCLT5jiU0qLy6Y5kRYtO8iSdpfbalzdC9dgjcFdEB3vvlOUMPgqAEsrIh4m36g76kcvNZrPTuxV0ixglIcGagrtTZsHHSijoxwYjiWacc2+8Rc7yHY92m66xOIdtAC9kwfWQyAuleZZcFprW13bJGVAnhc0d1b3ojzuEd+30hH8XMsT70ztRwYszMA2l0KPUzVIXF0pjlFEiKykh8oy8J9Q2eSneobOhnbmQPsIQ42HboErfk4MklInotPaPQEUB0oBjM12TbPy4E5VHVtSWEk2y4HBHhSM39HVWHlmtKhGryZYwjO2KcE+/R3lkGt7xu8QjAUXxD2DT4q4Va3jkSTcOnjcHHT6Q8Y73liWaBPSv/Hr90FfIKIYaAWVYPSZ0J8bZG1L2YNUgS9C7dXKg26N/pPeSDyT9k1qyNIxvEccUy3+GN0wMU6HUm8jrzEnpKzsdaQI6EdQZBLjnoAEC7KPQAIUuLlmT4PA5c9Anp0ZEEgY4Or02AaDwhHDIAM1FIHLlBvAfQ04PKJ1oZVCZXNk2ubJtWK0iGUIww8gTTpMpnIYZHwCXqWnmNGtTczJPf6ggjiV+pIibBSYKd5t1F6bBm93ywMEmYRgjDTpQYUVBLNka57Ggm6maBOUKtkmIyta55CzOXEqfHSa1CmgU78vK4I9jS5bU+qFR/C69SK08GsXv0ovUmqVR/C69SK12GsXn9KI/rGbdOWc8Yu0KCfkhgtiad7II2eJTF1pP4+2n2qqIQYFAdhWyVFekxTxIl6VHPIXvUAk1T41KjJL8IWx6jE2UZXhzfK9zmznjw9fDG4IeKT6yF0VviN+hLzarL3fbFL5CxZJqeGnYV/BgUpH6k3mPdBQ0o4JDejVFl/76t7uuwbwrWrEM1WizWLy2Yw04PS4MIlHkkmes8Nu1JbcQt16k9OmBa8N0kcJGXTlj6eIMCRso9tkK5mQ7bAU9XaYen5Fk6PEZy356fEaaJwF5+vceUJ+xIEaSqqO4FeYMGgZFnuz/D/MYIPuY5jTDCvfJdpiB9Tt+Atnw8IJEleMjHf0uNGu/381aXgHZy3ZnIIE/Zo4jigesMeLu3AvaOZ7OgVadrzUsiJI1aEhrCYaeCUsw8pibqVU1JgWkyM7AgK1vtCJw02Ysb9bXn2djyPTCdvJNqqv8+ts6hDIZirdLTFC8MWGC4i0AExR/bZ+g+Lv2BE1Nrx6AriMrk6HrmMpk6DqaMhX6gy5NP+jS9IMuTT/o0vSDLk0/6NL0gy5NP+jS9IMuTT/o0vSTLk0/6dL0ky5NP+nS9JMuTT/p0vSTLk0/6dL0ky5NP8nS9PKZLE0vn8nS9PKZLE0vn8nS9PKZLE0vn8nS9PKZLE0vn8nS9PKZLE0vn+nSFHh6kgylS9MXujR9oUvTF7o0faFL05e1NCUczCc0XaC+0AXqy1qgEH3+upapBNvJhKaL1Ve6WH1dixWmz4jk2wH0WrgwaETu7gCaLl9f1/KFaXgtYgj0S5aIvaxFDIOmS9nLWsoQ6+plLWUYNF2FvdA3xBe6fL3Q9dcLfUN8pW+Ir3TN9UqXqVf6hvhK3xBf6dL0SpemV7o0vWZpq9esDfHbWqYw6LVYYdBryUKM+xtduL7RhesbXbi+0YXrG124vic/n15jA6abZCzdPPBCv7y+0m9lr/Rb2Sv9pPIaOGukTvFrYLdPx9I/7St95b1+T5/k6zy+Ap9wsE+P3idox9R1WRX3RTWqLDN99T91JaQnnPCZaQ4nnmwCcn0GdTSgL32M5hCYkuxDCWBxHsg5gS8GQGu7h2Kcl3O0z9VDSF8T4EAnsFlx4FPYrCgo8LGCLEF+iIlGszOMUpdMdk5R1fL3McdUcijC8LS9ZvRq8kEKXPKKicJ9bMMK1bQ12OR3lg/woWwKFd5qMOmv0CdwlsIy7CRkBZq1OlR6JK6osKmolcG/eW+/tsxCcZaqVhWiPAA50zZ502h56RN84tbAkDIGM7A+xwxIq1WbHFqwTGqDrE3XqloUN/auhvoOU5FfdhaguS7OqeFmM6b3DnxevFCJsDRwqVWbqqAesbh2NWIT9r+OlwvbmOQJJ7uGuf+LeJMzQ/v4SiISWmQQYtuHzho7vmlpeBFVv3Gh9izKfnlnRWesakCzS82DWuxJVzwJDZtRx2nE5xRzGjnwZYY8MjPJ+ZqDoAIdR+OT8uzRmwBTVp926MwOvaDvTCsOaj/Iicba/j1Pu1zyPtbMqxH3H+Si82xGdbqAXKJlr9BnyRgL7jg5sQxjonfjTkDoQZasZ0l5nnznSfYwZfQ9CL9xaHHpo5Qt6FYLg8yi5p+8as54izkUDyCfjiW2Uz1Fj/kfCm6hUsH08E854rXJtocLH0PBxVAc/sZk92h/LT/CmV8E5jX8CK8qQ5jsjJwZC4qTqAGXBGYBr7msOlRY8hKOfny/gKNzMjygaRXLgyT4q+SSxbRKGkA/fl+woNJLLJGExBIjQTBT8bboCFmoZuN2mojGlYJdwc2Zt+5/URe3GEnsldzTLzcVeyXpHreAhhSrwSQtwR35Efsdj41kmt6eNNXZSpE/+4SmffYJnvPZVyTkz95q1YIOPMZ4ut7eFdzFb9y5VjSRkmJBklEEKSTTjOT0ZCKh9kRzguLS0CgLxMVzBxNWj5aBmrLPJGZ4Q+Qawxk65wSko5khnVDuJXu2Sh48pSEm1BzhfmcbjxlsVsIeRdXn+cNXKWkNdKViV6F9xkSrVc18I4/4iNqZwfMukzOi9NPxDBQ8YUZFVgM3yYlG+x8zC5iSuRpqbsUFGGgdOHTHapr156wSkNXn7zDc3qPBHQuB8aNRdWfxvR3gUslbM2SjiyQW2PgWIRLvxBDvHeqjDkSz9EHDZQ1bSzNERSohFCKqtOqw+YRDPJhiUyF8fg9Md8ye4Qto/2jXJ5PUWlx4jXn+OvIcq3ahgbjBGRbmPD6ehJd/8MLdXbOZSFX/Yyw429acpdVw2WdUg9koh+LCa1EKe/P3yWTD/cjQux3SkwEscTRBffDkoSunTTyqrsfUz08jPrYncqBSmhWgbd83ysiUPAnvQGE1XGB9tn2m/mfBW6Zrpjze0SiSZ6PKy4098vhaDcebxb3oX6BrId+GKrqx0hpPZ3dFhPIXxliwx50Zi4ZC6dIwDbXgR1EjHtxPPN5Y7c2KpLnNOSlGSfoyKntwIEuxRNhomnpFkj+s7/sNi+QpCBFRXVcTl6jAWHbm5twXu8OKoC87Uwwhn0ydHiYtOso82g1ZzySOSxuWePKj7c+44/BTvH1POJfxEyWsjcTPVsYjw14LZcmLTYsSoclx9kUo0e6/JQ95yvNmZDAzEQoMPeHZuVuWIyoOLLnoH0artoUyNx5lkw7vrH6k26tbe/WH7MOPEGX0yFi3t55OokDFCo54qHzFrKNWvMwLnXlg9Pf7Ew9Yd3Hw8Mk3iaM934zwHqW8vnRS7Do1F31CayCQhb61FkpStO+dhXiJHYDBb/EMas+gJUwPdmiH0onlSfXJpwpvItosnougIV3MxurJhW2ZsRp4Q9LeU1R/lj2gZyFVwh8pTkLDldc1rl7gDG0sW73Oot2ce7KB4silxPh4JpKaV4ZxWTLDm9anS0RPibst88L7BKxoQHWEa09PYq0Wxy6UyjiNoN+nMgxPPYvQuHKjS/RoJNp8NZXSjTtR3nhAltPM5HWpr1C9B0f+iLYegyVSRJxlKV95SEaa07q5GYbLtbqgEGWdu97uVX4pHfAKwylTb+0dK4/nM5mWF6GaZClEUENhx/d69FPrkiz3UL5kyz5Sz+iUZryu3NXsjEjwvSTySbj3UQ9j0fl9WPJURM+SqSR6knwC+krvyz35uCdMvb8Jr3lfjhlVGnqFHsMvIiGjiX1Qp5MBgq6qNLA3SH1ZNqK8BXWwpqrOtgEt+WT4nqHXJP4pJb7nnmEosS0MaJJFtydppwtBZBpxJMEjZwJFP5PDM7GGY2/hAY7AvCZwTOo0qyePLOi+iKJp+xdeor2s03Y8EbAFOjm5wwLtfrnRgacSuiZBPAddk5BnYUTjZ6Ea0HigVBrKuesww+Ixsg32wMGXuRddrjlvFnzHoAavjf24SRMXIssJIQoRUowEQZ6gqZBERYuuCDH12sYXR9jhQ8afGOO5DDRcWlGgjwohsk4KzMvlO9XuptqRkmofXeEpxtqRpOlqu5OddaTMNgCPRLtZgEdCigl4ODAxW7S0c+uQe0OhY00f4bSY07uUZKrFkYaqeSzoBkrBLdANnhlv4WcUOc/hZzRZlvU7fjS7UiOfxB7xj2KP8MfZiR71kjCA32FS8AkLZmADBTNt6nu2GXDTv/Ecbdcmq+edvUTeHD5FXl6ZOBNavLwy5fUIWhk5qFadBc1M4O3LpnBdvvvHRdKXYeoXD7r92YaHHXbNdQWUFHZ3gtlpnG5x2+lQv1PoQYCGlKoryLfT6DIyiD3wGahiF4IU/FKr+UsPXrUFqajWrCAZzQNYCwms4LokzI66gmaFYLUI1eJ7Fhjd8I/JtUuyjjqCPVxKDzxkm/GCh241XtDQKbL8wg3/EE3XZG6NI8uwDHdgIuy0Q+U11pTfWHGG4s10az/H0+U7sphC4a0dDVjQWSEDDVitGFwK0rumO5piq2mEzFynQu7k21kx0dfqkiljtS6JyCR7LLeBZYflNjBRlpuSwio9PQe/8PquV6nTE+CcSQCVta3N4L42lhdvrIQWP1OPJLT5fmQh36BiTIEaQmSudbZtMhWi1sYzKkThjWdU69zcZCpEYaFnVOvc6mSqdV55MhWi6MKCKsvksGDINTrQM9hFGMjmnDUPzaLzwJP1wOmBaw/1dGej7C8PDJQDjaO4tLI3aQzPZ6pOmDMl+vFuitdwf/x94oUN5ArYFmvJLS0m1AGH/M0+NHUQGqaB1+vDbgJZq3zCDEL/PZJmbHZwyvfMLpFwJ6KVSpjwOSUT7iT7lD4Y+bJrCISIkLUE4hSU742rLZDeo9wX56haA0gqIk2mmXA3+2C2EO9lEdyuLfB8DeRXGdikou33mVUHIjTY6gMRGlwVghVJTjWCiYxalWBGQNSZhCoFI5ZarWDC5wYJZzvw0fUIHoDYugSPcFJ9gokElah/gSIk7A/gCYn7AyzIBP5BBnQi/zvLHgn9l2w7PbLOSfC/4NghuGGPhP+PXHQ/+h4FAOJcGdpkt4IAzxh36eOOnduxV/mbwg6FA5Zc2eaXfQsJrFkzCwo8EGYWFnjGRjtBZhca2CDK6NEua2iX1bPPutlnxeyRq4RWoGBCZxYquPOQc7NOFHk5WkM0+6xPSs7WEDhoIXn6hcgZVkc4MdPqI5wW/apPxcu3b5/ZH8K6i3GGfWfFRLbuPDDRbTva3gMmKeYvhzfw3vmchJFl91Q+Nl7qP2l+RDaqRHd9xNIu0yNac1mqhvpaeHooHH/1mjIKx9C/oc7sRh88QHqvPHHk9mHYl3aicYPBPh64c6i2qylPRO8MWh1jqRuf6b2JhPC2cZe37Otn7OhueKWUm9NjDCocjJNlbB94rmgyQx3755V9wtRB56G30z0euk8c9NbF+DiwN4yOhYF3ohvfoWbTtW92x855tty+EfcLMD7onBSzZxpe1zl+vNyb1BxPSHO6hhMDAHIvcnN81jgyM7XucWvayRJgdJGVh85YniFXlmdOguWy5Lr09drGwhx5+ekDlLmpy8zNWGi8EyvbsWuLlvHiLePePjBIda2hrIa3wJQbsyMar7lH0tNYxzC/4dJE2L+rFDJzSrLe4jkC2nsmh2zNObPz2uRYchzDs1tcAkOvkOjf0Nxyv2Gnq3wGtx68u4Nyl3UcVyFLd48qeE2wlswZSI6ueaw1MYvGfVPJtXrOtqdcq6XtpIQ6yw3dlXvoG8cyvsGgbgmOI2vBdNI7FqhPyru2zXkv5Wth0U7CPmWQD3T0uRG9JzZcNvPJEC5C266Pvr9v/P59OeVdSpyNOMwHvn1Yuo40Nqpt/aLZidbxq2uyOHO/ZPGGPQ83hjLYq22JZ8p8Q2++gTfbsEs16OYYcvEGXIrhlmawzTPU0g20WYbZHIMs1RCbaYDNMbzSDa5ZhlaKgTXLsEo3qOYZUrMNqFTDaY7BNMdQijeQ7mQY3ckguo8hdB8DKNHwORbJwKIIZlIj6jfG2yFqByElVLMq1ZxKNKNmm0+pZlOiuTTbTJpjHs00iwbhJnJLirMQjarG1L4iADOgBa9jB7H44Ce8qCS3ncYsvRE7VXyUyjJ+CplBkCRHOClaV3BpiUbcUFDB50RFtipa1MM7j6kFSFpfVdPqQePh7sAOrY5/QBF5tbTZ4QEYzt27iWy7Yy0K9ga3jV05lSGiEzbhQ+XyyGeKL6oBh9yMKK4NsktjR1cG3oUREzOU46LkNnDKpjsssh0VdAdFnmOC7pAgOyLwDgi644HucKA7GsgOBrpjge5QyHEk0B0IZMeBhaat3X6JMRHak3VCXgNOp3pY0OoTh4gGjOXNOhY/Mvvj7+/F8u9JW35BXtkznCnjARBjhst3vOzgcMlwtOQ5WHIcK2SHCtGRQnSgEBwnDoJDZLtYLqK1UHOfmCLZhILyx0Qb3scLk+d9SfK6xEaA9bVcmivXgfKJ3FrEtjGwrBN7k2gsSN5nwbcRpRc4+YaxgdeuaVjcKl4SjG85kj/aAj2ztxA6P7N+0NGkwV8+nOwN6fG0CpwdYsP3yxz76gXvHLxybtwhtRF/8sEq7JOhprZIdCoSnIlkJ+KHP0jNt8Ax/41oWaI8rTlw4tDjB0miNp8kiP8fAAD//4qByOE="
+	return "eJy8fU2v6zbS5v7+CuHtxWyS4HznniwGSOcDE6Az6ZncxsyOoKWyzByJ1CEp+zi/flAUZcu2ZKuKupMO0siNn4fFYrFEsorFf8z869M/si8b5bK1qiBTLitBg5Ueiu+yn02mjc9qU6j1/rtPPeLWX5++zd5g/0Omwa8rs/uUZV75Cn7I/ut/gv+1Mrv/+pRlBbjcqsYro3/I/vunLMuyXxVUhcvW1tRZ/GUmdZH99u9ff/u/GVK57z5l2Tr87IcA+TbTsoZhU/g/v2/gh6y0pm3in4y0drPF7+LPhu0N28RWDn/YN/oG+52xxeDPJ5rGv79sIMAysz40byE3tojqWUGRrfaZx/GBLWj/3acLMeCjMdaDHTBf9v+GIL+Dl4X0MrNQ4dBn3mR+AwfurICtyiHzG+mPBtLJ1QncK2tMYUNpZVFYcO7kv03r7obY+PcvUcT/5tAIdsa+9W1kSme//fsH/M/Z2thaDrV3IlMJ2otrkqmGJtSPUQKzDmp0YWRDM50KnaybKigQDvobFc2Z1uYg1LlSOrEqo0uaYH+sHNitxP+cFaaWqKKfcbR3G5VvhgOarQDp3YRgXtXgvKzPFdMJVkgPNMG+qBrCZEcozofO9CZabxtsX9SqqpRbSDX/w+wC6tTwG2tycC7bSJetAHRmW62VLr9B6+rah9zoYkpPrl2JMPDLjeFvP5/alWtX33JsawvWKaPHDV57KMHSBOtdWCTOWgfDtvt20dGIaNijPvRMJVda/RKdJyoDabOOFvUjdVRPblqNI9nNuW+yO3QGoYN2LXNwNyRUuoCPBBF/QzxVRtfmm0y6MIZqfaCQ+ij3iNhqnSztoLHQ9vXWEkdPrYfjd6NnrgEoEhr7E/EXbYVpvFLeZQ2aSJjK4+0XykLuT+cLVYaf26aCD1xPXfb6h+wua/WbNjv9TXafrduq+rYIv/8me8g2slof/vUxU/qb7CkzrR+X1XnpW5cg6J+B4ELG77J/Kp/d4SpRrX8saqXjD9Fxr5TP7jO1/qMB2/3xuHBKC5N78Cny/REI0L2B2uKw6jNBp1puc+m8aN6SWv+PVkiTNTJ/44lRt5VXS4jye0+UIMzKGlksIcw/e6IEYQrlcmmLFDF+0yvT6uIgRKTsl9JzpABrjV1Shp3ym6xjnWw0zn7RWOPNV1IA5LJ1/bcnNpiFBnNTjUtmWr/YhHW4SplnC9jsV5mtRBm+4lQlSvIV5ylRkmhQKTL80fq0WYr2kTxNL6QIK+jctFURTj5W0KlmMG+uTeLGmlq5vDWtE/iRT5Ds/2zAb8CeaiJ8eHU2aCbDZsJ6wdjM27bbQz2EJe5aVm5MeYXxj2GJ4ISsVKlr0AuoElnxs+9+7Dl/CZo6rG3jYuIX7JYGf3V8ByKuc7egcL/+9OcyYjmlywpEbnAHqIwWaytrWETGPwP1Tz3zr4E4Vd7gxpqvJPHvkXxhmd07CA9uSeP883/98gXcQqZZwBqshUJ4K7WrlUO1LiLmz5H5y5A4VVw82TsO/yJy/kv646AnCwgfeNSitktL+UvPu5yooUdaVqKWeT/8S9rpb5H/d5lHG1jIZnNprQIrHGgHCwr8U8f7J9IuI2lwqMIbI1CcRYQMrvSLMf9CwkVNIG50vo4F/O+OfBm1un29MtWCgv4ZCBOEy5v2+VKSdWWknyfKj1uwsoTsp3//J2u9qtTf3QG32cYFVIW79ed4vOPCmU8DNh+Pp+RNe19/fXFqpVsPt2V5/v8gy3OU5pZqvPFo71Abu08wnS9Ik3U0vaV0AabQ/mrvR09l1xYgvfFfLQC9bblypmp99JifLuIdpl1VMALr4kCiMaYSG1VuhN9YcBtTTRxlXmeozC6BwHpRy6ZRukwVZcC0mEgNWNE6sEzZ1rnojzE+TQUzrqIEEn2aCkleQrXR+zpOJ7GuZOkI7Z6APeQbrd5bIBA0TaXyru1V65QG5761UMFW6hzm6mxAkksPpbF7qhYGFIM5xyMI8eoEATbeN6K1Kqx0lPMqvxwStzHWz6FxYLugGodCFRwUv+cYZFxgBL2V67XKv80r6dxcI7Je5JXCI4QYfhddaFZ+qLqtU1mUXoDFMRjigRAFh0ufbpNlwTVGO6DDNexEbrTuQk10PL/lA1JslPOmtLIWqxaVcL8g18OCXI8Lcj0tyPW8INfLglzfM7hmnGxcwQe0JJtyoiNJ9CAJrqPXPVfyM7zSSXia7JgXBDZV96MsSi/AwupN6oCM0yi9BA2nQ/SvUrdlS9XDKIvSC7DQtNDJMXAraR26JFJ6GSJCt5yDelVBgefxJcZNOuc5F94WKiLAzsV0hwICtxcWz+222AVVw0z8qmxEAc4r3S0/pRO6rVez20e8hg8vZPGXxJMPPsPGNEI126eR7M24hWpuo1/I6MbCNk36mF3GgW5lpQrl92GfA3N3GiuFCdRX0pgmcbpgqFjpGBYJm3z8xyVuansc9h9jm6hxnXQ/p217Akat+50P6FLpszTbq1rBAAWcOI5jJugFB6a0TlIYK3KwvpMFiO0bSx+aE+gLE8oeWlPXRuOhS4OdBsowG71WBegcRAVbqGYfwuF+iqMmrRO62W/hcAUpitbGjfqEhUz2uGcZfmAoGtPeyvxtPgQTVAUegpI+QgFVKf0WgzaO5mIu4OpvmK/rc/RoUup1dJfx7oSFSsmVqpS/PGFeGVOB1CMMUHkpgnMl6WzwASUb5/DrG8CNhbX6SMGKCnTpN7PH7JTlJUX8lyhCCjZBfAzhTkk/+QEZEnC9RKFKzG7YSLcRW1m1MNd08HBT53HZJ8z6ZDWmmsne8Oi2T0sTviQT9sn5zXJM26cFucg91E7I1m+MVXh4vYXZhqydyGlrn0KPfnWnzVQ7UX8I+Mg3UpfEhuqPMLnBwlhg4mqb2hXEVR32TH+I7mITRYONt5y23hkQS/1AaSfeW7D7w8aZ0jFryR9j7YQzUsBHoywQzBdBtP1zj7KwxmgfGeXtnohxYJWsaKCaYRjOSMuB2S31E9KjrDJ2bNlyE+mlLYHR4g5UuSHivKfo3n94gYs7gg6Nv38Xeeu8qcGKAhRhGXeOTV0gnPJNDtDUNDyFxy/LEpJsKzm6h5geuYBnKJPXZzzpVPhF1s5jNHtM1gmjGccvJIaXJY2BrmhrmgYKUck92Ifu0oXothekncUYTXfgyaFJFSO1/e7MO0GASMCQwPk+Jn7tYuUFDkpcRQ7va3AliEyH7DkmbHzJcRXbbPZO5bI6ktDwrVaLdH1r17PnP+jc7huPCdmYUmMqU+7nu0nyGWAEjOp2ChLzIcUGZAGWuGk9oBu5r4wspuCTDuZA0A0HHz7W5WlYWEky4xtdGQC6FXawPiVMXN51v67pDu5y3wjnLcia5MgjfHAuEOWgtY8xITxW6o6nOHOop6nBOVlCCgXXjUcC1in3AXt1d66a68gXHpJ7qtMRYJZlrB0xZjpTuoop5VFgYUFW9VxtrZWFnawqESrFEFB4wwYX80IbLaBu/L5324dokaPRXRDRjnQ7mSJ0JbUGO9+Ph1xMIXUhumoKdr7+wyEuhnG3XXTatIRx78DeW7XCVGoikByX61B9YKFWuTVT8acrnR0QXAlgXSEAXcSlGVcEZLiKHQ+fDbDk0NsBq6XmNmtBOuJoIYzZmts70TaUIH2A0ixfFRXX7t9gj0tk9O7GUtqs5Aqq4KUpqDCz0cWitN3iYCsrPoNrZK50SSIADMD2n2j67uSUhLvJOmWJHjOJxlghqxIPkzY10Qicx9SaNG/QcXD9QY/meYQOzfQJHZgP5E1wL1cViHXVuk332acPe0fRgHyjYY3dSVvgJJwoNzPlCvttwPiliVsoTAGI68ixr+WEsD3arNeOctq53olVJfM3rDbgIHdz2wtZ3GtVtng9mBLbW+9EjlefbS5cOXssdnHwxxMj5oAIi6SdwMyr8UXmNUUeUEJWntEYRhFzwjpwJ7BqkjANaJplD4F4G4mDs2PbnElcLT+EwzvK8xOl1zth2wpI+nBtXUu7xzo/VI38bTSIRirKanqIGg2GjOPKyqwGe6CkO2ulBfEG+5m/DpH4GJU3rW9aP//IO2CDQ58IP05OjYBUWnkl8bNlSf6iAzeHI6EJBzcPPLpLuwLtdCQshoUxUZ+PVZqCPSx4WC2fo+e3jRfMcqM96IkzsMnJFy6V9acwU4dR19HdhkM0GysdkLHvbUg1Mc4zoTX4jSmY4Ikg43Vwt6wQ+VghnenJ1N/eE+O39yabVHmNg1uE9I2nucuSE9QLCYW/uNLgZA8vwS8cMLmXPWp+L8uImg/QBtdLg5w8xoFjzxLDX30ZiUQa7vFnT5OKj1s7BoHuah/jKRFUEA7gg4JJIzNGwrnvO0akCoL9juFHQ3MkClqqxhhD9yVaQTn7azLNArpI4HBQS025/DxG0mrlHUWni4U8eypqxOcCN+56roMHxf5S5WcHUJVeKIKqND2EGpehwucNbX/eAfFqwLVakVdgtKttx1FmuqEeTp35HmwNhcJb3uTwjtIp4R3ViHBrJSStdjkuBGih1h2sW3I0RmlPgLPi1UdctGFiKqJKueR1BqYFAQd7ookuT4zwEcfucoO1pIrRdNlpkINcuEbNF/NqBsA0ylcEoYhBd1yRCrUhtLB9Eqah3C4PTVjTeqyAls80he0LnkGBxtOZaNCz2xt8UmZ360NJzB3aufHQ5RwY0ashtCvARG0wojjtYca7djuwDGBwhOAYyJBKzcD125EPTwW77oIoQ0POixzveXKxuFaw+/EN9Hw4s/VKeuXbkZa76mVXgEaXPKSFEqcos78RrenomHcmctVswDLBGEGdcMfTy+4hwehy93rb4WxoYxx5A3QAt1axYJwDGkSr2inh2hWu+cZuTV9HV98L2TRjPm7CfQ9ADA1hLSCdzz18DhC8V86NBR8I2GHgwOBsznRYiOQ6rIDlO6whnNk6y2FhuzyHhUi+wxqgGd3F6nrST5w7NjdBL2QQzTdVeFrcL6Ap9nty5Ec3/xM4fb8d4dzpOwLHu+O423tvpQXH4UnsRQdPEcNBH7Yn4U53T+PXgCbWpaME1Lj/KAktibHCihL4lgmh76YUppndUbMDK3IlKlWry75NlUOopX2bKQ+GpVdqJUB7q2aPPKIi4lDVkwKNudekTBps85Asx8geOsOT84dO8PQMohM4AxrTW5xoQIeUHAwWhrpCc1cfSNObO8nMB6kLDDs5LuUIKCzRJHhnGD06eqcEBsJRSA1Sx0z++VkhARRHhJBM0seY6+JZ5BvI38YqUU3K2WFdbhqYD/JgWdnuNXhrBGzzMcjk4uCI8nuClAorpDa+tX3dMWqUJDBg7P/D08sFDcG09Roiw90zcnotImtTtBX1eAaBZvUX5MwY3wDfJ+uBpWgqgtlSu7328oMFDak3YjUW/LotcAem1WK7gJeyLYEL7r30OXzae18wTFfUUM0MfLfsdN6OpVPPVaFRBRsbZrdX+ZvjKrHVTpUaCgIeCzBfsfUpoGYudpQWJ+nO9OXOOQN9wXPKwFjynBKQwSmLAKVTFwFKkxcBZqUqCAdYBM/UgWqnXEHxwEYrb3AKHu5GHBe2VFWPcA2shsrWVC7e+XAeK5MV0MzX+jmYNmbn6Litmz2AE/j7u1SGh1SCx1SCp1SC51SCl1SC71MJPqcSvJIIWPHlEyQvwhwovGliD+CjYSLJEflL/EsKnlX37YzjZPyYHJRvwBmSsoFB6LbRXfRadHXMyla5sYjTJAem9USVU3YvDTkdqIP02UR4fCccvLeY1UAreNsR9Zvu/jSReEQROOITXVZ48wZ6bvOHhDALx4cy1jKfvw/TMt5fmas7BCjtVAHCbXNVzO9oRBLL5iDKWFWGcke65GUnBZLWc6UODySRJA4I2hYRG3pvjcfiZjlAAcXEwEy3igv9ic3N1WYP91bILVJOOTR4l0tcZeayfwmFfNmgJ7FCShkitUQtH/HksOkllhawHcErnUsbC3mRPM8pl4e6wXhuUocwqza1VwcnFBbhbXOoAbAIGeH+6ZEol/kGhIVC2d7qBqWLc2MpzmgmK6sg8oC8I7Te06GDrm2k0iE9kRJhu0KlCupUPyHhKKK1FjVRqRyf7kQ219ZAJypWIq88J5vkhAOXCivp+POsWInKlEpPrFdmdONQNGhUhJvjUazwuhX9K3FOwHH8Eewa2rbiEj5xpZsGT9NBS09kPOLD8RjPAkKCUnQ44cQjehnQxfj1/BniTHEGH74IqwWNidZLCRnplpCPdV/yjKS7Usu2hv4uYl9i+e/xDyaFKTfmTaUJszZ2J9YrnoEeCKoEAtp10BEC6qXQEQqbb5OUgPgUHXTFqW1SH1pbpeEZ6ZAjNFslk/AfsaYHFrUxNoHKpdq2S7VtJyqTp85yl2icLtE4nXDg0z3NkOaBz9MtKph9mV5STB8LHChULnGz0kezG+k3nG70NOREhQuKw7aJ+0E658FbZX216dGvLVWwE8KxL+9MtWPJf42HtcU9S6YBPjwr4xJZHlLxi0jxmIpfRIqnVPwiUjyn4lOk6BarSXvOAY9qZsUhRrGVbHU+dhY/d6J1XQnnp9abiZRdBtlO6cLsiCfmo3SjK3gSRRCogErOzUqcJPlL+fk5NpMs8c7x8Z3bRI3H0fOpctmP5EkReNwCsvgkWY5nU5NV+GdqON407F7wENqw5EncR2KXeIcKiAxuim/91fdxWIj3ii5Y4mu0SSzB24ka0I8rV3MHtX/ht20wz2f69GKOTGdcV44u5rBxZ05UboqFRYplPgVDsgU+BR1doh8fkCT48QHLcn58QJpmAWn+95hQnzAhepKyVQX9IHnIgJefgyN3aTwO2DGmIU2c4aHYDqa/LEEWL16wqFJipH3cZXRxdVO54WvWYM4+N8p2ZGCBPwaBozEvdZPBqf6rNnHOcVMH1rT42bCKaVnRQ3q/XvGxjEMet3eVKfunGTi2Exmo7xtdEOCEdF7WDbkPiVHYVr9ps9MP39/xofd86AMf+siHPvGhz3zoCx/6PR/6mQ99ZUM/863pM9+aPvOt6TPfmj7zrekz35o+863pM9+aPvOt6TPfml751vTKt6ZXvjW98q3plW9Nr3xreuVb0yvfml751vTKtqbHO7Y1Pd6xrenxjm1Nj3dsa3q8Y1vT4x3bmh7v2Nb0eMe2psc7tjU93vGt6f6OD+Vb0z3fmu751nTPt6Z7vjXdv3AW5gc036Du+QZ1/5oi88Md5+zkgOab1QPfrB4ek2R+SkI/J6FfktB8+3r4nNTwawr6McnEHu+T0Hwre3xMmVePT0lovgt7fOFD+fb1yPdfj69s6NMdH8r3XE98m3p65EOf+FC+NT3xremJb01PSd7q6TVl6j3fJaHvk9APKf1+5hvXM9+4nvnG9cw3rme+cb080k9Oe+znBCz/eOCRv3l94u/Knvi7sif+SuXp4ZWt4qfHhwQsf2if+DPv6WW+knfD/Ap6wcGutnqoZ0161+XicV9SowZrfYTX/8wult5g4UfqFLJ4kgmiEugMZoWhpy5HMyamqIKPpUUghwThMQBe2x2UErwcojHwxClfM8JBLmBzwUEvYXNBwYH3L8gy7IdZaDS5wih3yiTXFDWNfO9rTLnZBtddba8E/zX5UYoXHgX6PSdyg0+T+9n3LM/g8dkULryx4ObfQj+AR5zOfKfhxFrpEqxo7NjTI9OOilqK2jj6nffmAW945xttKlPuCThupW32R6ORRSjwSZsDsWQMpWMBECp6mmZPbAcvipR+M1EremoD05hK5XvxbuL7DodHfsVGgZU23+znKunI9N5CCxNPhM0DF9Y0jomltWsJZZLDr6efC5vcJQ5wuq0F/qtjoUN+JRMJDTEJselSZ7HmTWcRtcwn3e+0UQcW4+/fRd46b2qwYlvJUS92Q5RAwsMmvOPU41Mec+o56M8MBeTJspvh9y44GC4QOepcnjMxpRlhSpJpAWEWkIL/Zbrg4MrBLjQW4Fo2p1M+5JoFN4L/IE66wOZMa3NIJTqViryWnGJ54bHEPvHFOBIwJEiy9SQrT7PvNMuOKuN/g+gfDqu2XZayB9tY5YhV1MKVV4tXRCmL4ggK5VimvlQ30X39h1x6KM1oefibHPHujyqI3cVn28KDi9ZT5nlE415JrGAjt4pyG76Hl6VjKDuhZsYJxRrrxpKKwJzAK6nLVpbc1umX70/g5JoMZ2jei+WjJPSt5CmLawym91Mvv5+wkMpLnCIZhSV6gtFKxddNR+nc1Fd2pzPR8ToPF+42ssH/J23cpkimbsndHDmlU3wPTqBYYnW0SItqZmBf6NiJStPXlWZaXxr2sB/QvGE/wFOG/YKEPeyNNQ1Yv6fPt3cDR/Prv1xznxQbJVGaT3LQCHwsQMKVxEpPV6SF2nhgTp4jmDF7rM7pFhPvEGFjtIPOIQFraeZYK5Tjkz3Xnjy4ScMsqNnD8c7dYZkhBk/Yk6i6On9XujG1Q3XQFkbslMWbd3hGWonQyDl+wu0M4GmbyQGR4rQ+usKcNFl8Vcromc10Pxb4YM78MbGAzzBuQYC1I4vuqTfNunUWBrJJn6AjjPbtsYDLQhBy5UzVerq0Ea6N3texGt1EYYErYzFGEoIY6r0FBtGgfFDcrFHf0hyjYj0hNEZUWtM2CwikijR8ugRYRDpVw1uw4dIuflilxeOBinL9tedZlc3JQZR0tIOFIU/IJ5HFXzLH/X4yE+vV/ymWFzZLg0/yLtKreGyUQrGVlSrwhV3cT8Lcr1XPEKJqY/Y/C8cz1LNIHvnltAOPqaqY6XQ74+O6IiOVsSIHi9/9XHpOz4xe48ohx3OWLVRk9z9I3sJsiL6Ot+D2Kq02ds+DBZhDxR/SuegJulL6Lb6iO/W0xk3tXhCR4oVTLNTlzoAFa2DYAmuMVEquVEW4cH/gCYfV4ViRpduUleIkSfeMyhIcU8eNN7Vywsbz1Bck6d16Wa5brEjBGBE3dHXgUiWejG6k23SP3VFNMDw7k8eUT4xgD6W78nZgGu326WsRvyxGHL/uqlmecfv0FTjZPT/NnyhAkWfGOcNSE+WUl1oWZYImamxJycjhv1MetsrTNBKPmRgPDN3gWVgsLwkvDpxy8QfGmqaBIjUf5SodPVh9TreUWEvJw47hTxAlSOQ8rprXa5WTcgV7PJToncXKGlmkpc6cMaLl2bXMIRE+vvKdxdFs9k6FiFKaLK1Wi6pma9dkDwQ6t/vGQ8HK9j2yMDexETg6FregfgNWw+HCDm9RemA5TdSlO7wD0dXHcwk0Yyq5DY+vJ+cey8tbkDXLex+y+pPOAzoW1kv4PcVaWdjJqpp4JO7G4K6VxaSw89tZtEzbU7JIscJi3ZauknCkLSSWVpR438EyVIK7ZTzL2HYP95vWMzoTSLy3atWOlTKeR9B9OBMOnsLOv1CW9tzoKbo/JLp6a2qOGEeitP5g4cxeM2kiIdMyHOk9unYZbCbFRLBszijHYqQprbu9E7RaqycUqqhS59vxlV+OAMFhoDNFIQ4vj6czuUbmY2+SzSECPDbu7+vxV62nZKmL8lO26LAXoTNWyApTNv2GUOD7lAgviSzkHvpH55dhSXMRHUuik+hI0gn4M7177gln2UR+4g28ld1zzOOh61uerkf36RcTKaMzZTDrtQNPt9PSgniDPbHZcIIaT1NN65vWU7sfGMIwdlcp6ZIHhu4mKBqzPT3RpXRENYdH5SfUSCMZXXLOoOg0Ga+J1fIjnUNpDsfBnSZJcs5ClkXlddPd8MIzYKqBnaBfWGj85RUBblroJclLCglbCz2aroUyoulAbSwUw9BhwolHzxbPA2Mscym61OO8QfKdgAqCNw79ZilujCwlhWhMOlUwzHCMZ/SokEXFy64YY+p8VngcYYGBnL5iTOdyUEvtVU5eKoyR4VvqjqP7xY9qlT47bE3Fj3uceSR1W/mFzlmVXugAWOmFT4CV5h8BxwWT8HnDW7d2BN6Qc03P4byc06OVJLrFnobrefCJSSiwCgn/wDPhLvyAIuU6/IAm6WT9iI/Wzc58UkvkP56RvHBJ+hX9hFJujm6PX0Ap9IIFA7CDXLhG0TtwNb5xG+0rhrBTdw5vIrdPQm0YLW6fhAl+xJG7uH0S1rRYEsnlROPavoTLRRpL9cTJQ25/8MGjdruStuyfDmN9hU5W4/wTtxMa/jcx0qQeJ47QsEp1jfIt1LuECmJnfA76IygW/tSrhc0e3bWNUnFPs0bJeBHACmPcubQFQztmh9FUJSo19hbfrcToWn4cQrus01EkOERCEk6Mz3jYZ8YnPPxT4xMaPkVvHSyrqOWHqts68dPYs8RpuAAT40sbX14TdfEs8g3kb66t6dO3Z3G5oZ921ODBJqUM1OCtEbDNx6AzhO/RnLOaWunEear0QrGdWumF4jtnTAmz9ZSITbLEdFN6qemmNHu6Ga28sYfr4Hhj7eBXueoZ4RxYAJcVK1d14WvnZf4mCmj8JpWEp+9zlvhNZ0z1Cab7u+W4HpajelyO6mk5quflqF6Wo/p+OarPy1G9MqmSjhxOGE6XwUxxWBXsJhjYxzmXPC9L8CRdcDrjOhn7ZF1zvi9nDJwFDVJsG90dacTrM2Wr3IaT/Xg8irdwvPy9lnj1lGjWWKiPlROKwFi/GbvSG42wIKuaQ9aYUDCDIX9A8g6bsRecBWryEwlHIt5TCQd8ypMJR5L44sDIYQiHb6ScN294Et4SmKbgjPeQJd4SZ+8oRrjYe4oLLv6u4oKKSZN4TLjY+WCyES91Inj9bYHbc2DsiQDex36U6iWNivfqwAQN9fWBCRraKwQXJCPui+52uK8SDAiYPpPxSkGP5b5WcMCnJgnHjxh/1sYVOkd01rsE53DW+wQHElKh/hMUo2D/CJ5RuH+EhVjAf5SBXMj/yLJEQf9TtoUuWacU+D/hWCC5YYmC/+dc/Dj6Eg8ATHMleJNjyfolpVvkYYARxgWFW1Cq6NMXESv9+5D2gMAJzWIPCVyyxtoQCxGeSslePk6xvaSxxb6mi3UkSpBokTm0yOxZZt4sM2OWqFXCe6DggE58qODIw67NeqBIq9E6RrPM/BwQqhRpRk9Ibo4Qu8JqD2dWWj2H87Jf7Tp/fH6+E38pjxvjhPOdCyb26c4ZE/9sx/pjwuTo4N6weMQ7fLdA55PT7qZ9XLmpf6P5HlmbArhY3ma6R1upC1MfQsFE9R8uCk/fep3TCzxmx+OWZDECCe++8oEjVYb4XVqIBjsDbA7TtBXniuiRwZrVVOnGW37vQDK6350pQMpd9lMSliaDd0ut6REjfP3hZLzA7hiOJjHVsbteiaIcfB75c7rERfcDB7911V8OxCnv4hk/LEXX30NNpmve/ILCBbZU2ZjfC3Ah6ZyVs+dqrNATZxFnqZ66kxriGWVOL+HMBIDUjdwQn9SPxEqtS+yaFjoJcDZPqkPnvEywKy8TleClLjBhHN9ri7ukxPr0I5SnZ/gMHe+dB1wkKp8c2MVLhJj2wt+3RwZtdhUUZXe1lbVjRqJ+m7tiXY1FhuEOl2fCyLJWOlElMdeCN6FRBN59JkQ2bpMovHUpJzk+v72Lm8HQOST+GLp96hi2tkxnwPkQEkLA8vqxU7rAfVQuK0hjYAW6hrnWzCoax49K6qnn4POUemrpW62hSgpDt8US/gZZ+jsY3E8CcsQvOFMIjR1gXylvmyblvlR4C4u3Eg4lg0KiY6iNiL2YeDbzRhe2yvoWc3D84MO/luObsttiTbIxu3nGtwxL27L6xj1b31qx5gm+wybzDVbxHE3evDG2Ae6cKhgt+4a5pkw/6E0/4E0+2OUe6KYc5PaHofPHmXNw22NoBpl2UMs/oE06mE05kD1guW3Gc4ZEOOW48IilHrgmHbRyDliTDlb5B6ppB6nJB6jcg9OUA9OUg9IDlt5a2sHoQgeiyxyELnMAemAh+t34SAYVxTgmdap6w7cku6wdgpVwj1W5x6nMY9RLGPHYkXtsyjwuvYTx5GWt4iJ2sO8kba9G4W5ilzTNwjxUda4KLwRi4iVWDJtYiE13/oBXpZa+tcDAHl58xNs/cu3BppKsYG14otDKEvW4+KBCqKxKbFU1pIt3AVMp0DxZTY03Ehx9D4ztmtVfkE/cWroqcASO1+69imzaVaVyLF585as8l2HCJ1yFx5fLJ4ZpelJFHPFjxAltYDyE57iWC2XQQxhTZkYKXBT4iMkFBz9gkRyo4Aco0gIT/IAEOxBBD0DwAw/8gAM/0MAOMPADC/yAQkoggR9AYAcOPNRNJf3o3mwatPZo5BXQfGqAjZ76TENUDc7Lupmr/f73x8fyj0VbviVu2ROCKf0CkHIMlx54WSDgkhBoSQuwpARW2AEVZiCFGUBhBE4QQkMkh1i2qvFQyVCYYvYRynQERREanuIgauCMJw3dtoQeUGMt23on7eGJwWOHpfeWzKL0IjQetOyq4PsJp9fMxb5wsbRZfErQ3+WYPWgn6OhGmB0/ol/4aFbntx+VPJTHs6aC2d1vNOPWCz04uJPS4SK1Vn/LeCociqHObZEZVGQEE9lBxI+wkBp+AiMF3oOdaU+XHDRz6PDRkrjNzzLE/zcAI2vN3w=="
 }
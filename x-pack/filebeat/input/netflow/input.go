@@ -19,6 +19,7 @@ import (
 	"github.com/elastic/beats/v7/libbeat/beat"
 	"github.com/elastic/beats/v7/libbeat/feature"
 	"github.com/elastic/beats/v7/libbeat/management/status"
+	"github.com/elastic/beats/v7/libbeat/statestore"
 	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder"
 	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/fields"
 
@@ -31,20 +32,22 @@ const (
 	inputName = "netflow"
 )
 
-func Plugin(log *logp.Logger) v2.Plugin {
+func Plugin(log *logp.Logger, store statestore.States) v2.Plugin {
 	return v2.Plugin{
 		Name:       inputName,
 		Stability:  feature.Stable,
 		Deprecated: false,
 		Info:       "collect and decode packets of netflow protocol",
 		Manager: &netflowInputManager{
-			log: log.Named(inputName),
+			log:   log.Named(inputName),
+			store: store,
 		},
 	}
 }
 
 type netflowInputManager struct {
-	log *logp.Logger
+	log   *logp.Logger
+	store statestore.States
 }
 
 func (im *netflowInputManager) Init(_ unison.Group) error {
@@ -72,6 +75,7 @@ func (im *netflowInputManager) Create(cfg *conf.C) (v2.Input, error) {
 		internalNetworks: inputCfg.InternalNetworks,
 		logger:           im.log,
 		queueSize:        inputCfg.PacketQueueSize,
+		store:            im.store,
 	}
 
 	return input, nil
@@ -98,6 +102,7 @@ type netflowInput struct {
 	cancelFunc       context.CancelFunc
 	queueSize        int
 	started          bool
+	store            statestore.States
 }
 
 func (n *netflowInput) Name() string {
@@ -130,15 +135,27 @@ func (n *netflowInput) Run(env v2.Context, connector beat.PipelineConnector) err
 	}
 
 	n.metrics = newInputMetrics(n.udpMetrics.Registry())
-	var err error
-	n.decoder, err = decoder.NewDecoder(decoder.NewConfig(n.logger).
+	decoderCfg := decoder.NewConfig(n.logger).
 		WithProtocols(n.cfg.Protocols...).
 		WithExpiration(n.cfg.ExpirationTimeout).
 		WithCustomFields(n.customFields...).
 		WithSequenceResetEnabled(n.cfg.DetectSequenceReset).
 		WithSharedTemplates(n.cfg.ShareTemplates).
 		WithActiveSessionsMetric(n.metrics.ActiveSessions()).
-		WithCache(n.cfg.NumberOfWorkers > 1))
+		WithCache(n.cfg.NumberOfWorkers > 1)
+	if n.cfg.PersistTemplates && n.store != nil {
+		store, err := n.store.StoreFor("")
+		if err != nil {
+			env.UpdateStatus(status.Failed, fmt.Sprintf("Failed to access persistent store: %v", err))
+			return fmt.Errorf("can't access persistent store: %w", err)
+		}
+		defer store.Close()
+		// Templates are kept per listening address, which identifies the
+		// input across restarts.
+		decoderCfg.WithTemplateStore(store, n.cfg.Host)
+	}
+	var err error
+	n.decoder, err = decoder.NewDecoder(decoderCfg)
 	if err != nil {
 		env.UpdateStatus(status.Failed, fmt.Sprintf("Failed to initialize netflow decoder: %v", err))
 		return fmt.Errorf("error initializing netflow decoder: %w", err)
@@ -25,6 +25,8 @@ import (
 
 	v2 "github.com/elastic/beats/v7/filebeat/input/v2"
 	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/storetest"
 	"github.com/elastic/beats/v7/x-pack/dockerlogbeat/pipelinemock"
 	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder"
 	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/protocol"
@@ -82,6 +84,30 @@ func newV2Context(id string) (v2.Context, func()) {
 	}, cancel
 }
 
+type testInputStore struct {
+	registry *statestore.Registry
+}
+
+func openTestStatestore(t *testing.T) statestore.States {
+	store := &testInputStore{
+		registry: statestore.NewRegistry(storetest.NewMemoryStoreBackend()),
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func (s *testInputStore) Close() {
+	_ = s.registry.Close()
+}
+
+func (s *testInputStore) StoreFor(string) (*statestore.Store, error) {
+	return s.registry.Get("filebeat")
+}
+
+func (s *testInputStore) CleanupInterval() time.Duration {
+	return 24 * time.Hour
+}
+
 func TestNetFlow(t *testing.T) {
 	pcaps, err := filepath.Glob(filepath.Join(pcapDir, "*.pcap"))
 	if err != nil {
@@ -106,7 +132,7 @@ func TestNetFlow(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			netflowPlugin, err := Plugin(logp.NewLogger("netflow_test")).Manager.Create(pluginCfg)
+			netflowPlugin, err := Plugin(logp.NewLogger("netflow_test"), openTestStatestore(t)).Manager.Create(pluginCfg)
 			require.NoError(t, err)
 
 			mockPipeline := &pipelinemock.MockPipelineConnector{}
@@ -505,3 +531,62 @@ func TestReverseFlows(t *testing.T) {
 		assert.Equal(t, keys[0], keys[1], key)
 	}
 }
+
+// This test converts the records of sFlow datagrams to Beat events to check
+// that flow samples populate the ECS fields and counter samples are reported
+// as their own type.
+func TestSFlowEvents(t *testing.T) {
+	f, err := os.Open("decoder/sflow/testdata/sflow_v5.pcap")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := pcapgo.NewReader(f)
+	require.NoError(t, err)
+
+	config := decoder.NewConfig(logp.NewLogger("netflow_test")).WithProtocols("sflow")
+	dec, err := decoder.NewDecoder(config)
+	require.NoError(t, err)
+
+	var events []beat.Event
+	source := test.MakeAddress(t, "192.0.2.10:50000")
+	for pkt := range gopacket.NewPacketSource(r, r.LinkType()).Packets() {
+		flows, err := dec.Read(bytes.NewBuffer(pkt.TransportLayer().LayerPayload()), source)
+		require.NoError(t, err)
+		for _, flow := range flows {
+			events = append(events, toBeatEvent(flow, []string{"private"}))
+		}
+	}
+	require.Len(t, events, 5)
+
+	flow := events[0].Fields
+	for key, want := range map[string]interface{}{
+		"netflow.type":                     "netflow_flow",
+		"netflow.exporter.agent_address":   "192.0.2.10",
+		"source.ip":                        "10.1.1.10",
+		"source.mac":                       "00-1B-21-AA-BB-01",
+		"destination.ip":                   "93.184.216.34",
+		"destination.port":                 uint64(443),
+		"network.transport":                "tcp",
+		"network.bytes":                    uint64(1262 * 2048),
+		"network.packets":                  uint64(2048),
+		"netflow.sampling_packet_interval": uint64(2048),
+	} {
+		got, err := flow.GetValue(key)
+		if assert.NoError(t, err, key) {
+			assert.Equal(t, want, got, key)
+		}
+	}
+
+	counters := events[1].Fields
+	for key, want := range map[string]interface{}{
+		"netflow.type":                  "netflow_counters",
+		"event.action":                  "netflow_counters",
+		"netflow.if_in_octets":          uint64(123456789012),
+		"netflow.dot3_stats_fcs_errors": uint64(1),
+	} {
+		got, err := counters.GetValue(key)
+		if assert.NoError(t, err, key) {
+			assert.Equal(t, want, got, key)
+		}
+	}
+	assert.NotContains(t, counters, "source")
+}
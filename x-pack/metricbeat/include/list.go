@@ -64,6 +64,8 @@ import (
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql/performance"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql/transaction_log"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/opentelemetry"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/opentelemetry/otlp"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/oracle"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/oracle/performance"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/oracle/sysmetric"
@@ -77,6 +79,9 @@ import (
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/remote_write"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/redisenterprise"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/get"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/trap"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/sql"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/sql/query"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/stan"
@@ -0,0 +1,7 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package opentelemetry is a Metricbeat module that receives metrics from
+// applications instrumented with OpenTelemetry.
+package opentelemetry
@@ -0,0 +1,61 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package otlp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// defaultMaxMessageSize is the default maximum size of a decompressed export
+// request (4MB), the default limit of gRPC servers.
+const defaultMaxMessageSize = 4 * 1024 * 1024
+
+type config struct {
+	GRPC           endpointConfig          `config:"grpc"`
+	HTTP           endpointConfig          `config:"http"`
+	TLS            *tlscommon.ServerConfig `config:"ssl"`
+	MaxMessageSize int                     `config:"max_message_size" validate:"positive"`
+	RateCounters   bool                    `config:"rate_counters"`
+	// Period is the expected interval between exports. Cumulative counters
+	// and histograms not updated in five periods are forgotten.
+	Period time.Duration `config:"period" validate:"positive"`
+}
+
+type endpointConfig struct {
+	Enabled  bool   `config:"enabled"`
+	Endpoint string `config:"endpoint"`
+}
+
+func defaultConfig() config {
+	return config{
+		GRPC: endpointConfig{
+			Enabled:  true,
+			Endpoint: "localhost:4317",
+		},
+		HTTP: endpointConfig{
+			Enabled:  true,
+			Endpoint: "localhost:4318",
+		},
+		MaxMessageSize: defaultMaxMessageSize,
+		// Default export interval of the OpenTelemetry SDKs.
+		Period: 60 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if !c.GRPC.Enabled && !c.HTTP.Enabled {
+		return errors.New("at least one of grpc or http must be enabled")
+	}
+	if c.GRPC.Enabled && c.GRPC.Endpoint == "" {
+		return errors.New("grpc.endpoint is required")
+	}
+	if c.HTTP.Enabled && c.HTTP.Endpoint == "" {
+		return errors.New("http.endpoint is required")
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package otlp
+
+import (
+	"math"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	p "github.com/elastic/beats/v7/metricbeat/helper/prometheus"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// eventsGenerator converts OTLP metrics to events. Data points of the same
+// resource with the same attributes and timestamp are grouped in an event.
+type eventsGenerator struct {
+	// mu serializes the conversions, as the counter cache is not thread-safe.
+	mu           sync.Mutex
+	counterCache collector.CounterCache
+	rateCounters bool
+}
+
+// series identifies the data points stored in the same event.
+type series struct {
+	resource   mapstr.M
+	attributes mapstr.M
+	timestamp  pcommon.Timestamp
+}
+
+// GenerateEvents converts the metrics of an export request. Data points are
+// converted as follows:
+//   - gauges and non-monotonic sums are stored as values
+//   - monotonic sums with cumulative temporality are stored as counters, and
+//     also as rates if rate_counters is enabled
+//   - monotonic sums with delta temporality are stored as rates, as they
+//     already contain the increase since the previous export
+//   - histograms and exponential histograms are converted to ES histograms,
+//     with the counts of the current period
+//
+// Summaries are not supported and ignored.
+func (g *eventsGenerator) GenerateEvents(md pmetric.Metrics) []mb.Event {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var keys []string
+	events := map[string]mb.Event{}
+	add := func(s series, name string, data mapstr.M) {
+		key := s.resource.String() + s.attributes.String() + strconv.FormatUint(uint64(s.timestamp), 10)
+		e, ok := events[key]
+		if !ok {
+			e = newEvent(s)
+			events[key] = e
+			keys = append(keys, key)
+		}
+		e.ModuleFields["metrics"].(mapstr.M)[name] = data
+	}
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := mapstr.M(rm.Resource().Attributes().AsRaw())
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				g.convertMetric(resource, metrics.At(k), add)
+			}
+		}
+	}
+
+	// Events are returned in the order of their first data point in the
+	// request.
+	result := make([]mb.Event, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, events[key])
+	}
+	return result
+}
+
+func newEvent(s series) mb.Event {
+	e := mb.Event{
+		ModuleFields: mapstr.M{
+			"metrics": mapstr.M{},
+		},
+	}
+	if s.timestamp != 0 {
+		e.Timestamp = s.timestamp.AsTime()
+	}
+	if len(s.attributes) > 0 {
+		e.ModuleFields["attributes"] = s.attributes
+	}
+	if len(s.resource) > 0 {
+		e.ModuleFields["resource"] = mapstr.M{"attributes": s.resource}
+		if name, ok := s.resource["service.name"].(string); ok && name != "" {
+			e.RootFields = mapstr.M{"service": mapstr.M{"name": name}}
+		}
+	}
+	return e
+}
+
+func (g *eventsGenerator) convertMetric(resource mapstr.M, metric pmetric.Metric, add func(series, string, mapstr.M)) {
+	name := metric.Name()
+	// Cached values are identified by the metric, the resource and the
+	// attributes of the data point.
+	cacheKey := func(attributes mapstr.M) string {
+		return name + resource.String() + attributes.String()
+	}
+
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		forEachNumber(metric.Gauge().DataPoints(), func(attributes mapstr.M, ts pcommon.Timestamp, value float64) {
+			add(series{resource, attributes, ts}, name, mapstr.M{"value": value})
+		})
+
+	case pmetric.MetricTypeSum:
+		sum := metric.Sum()
+		forEachNumber(sum.DataPoints(), func(attributes mapstr.M, ts pcommon.Timestamp, value float64) {
+			var data mapstr.M
+			switch {
+			case !sum.IsMonotonic():
+				data = mapstr.M{"value": value}
+			case sum.AggregationTemporality() == pmetric.AggregationTemporalityDelta:
+				data = mapstr.M{"rate": value}
+			default:
+				data = mapstr.M{"counter": value}
+				if g.rateCounters {
+					data["rate"], _ = g.counterCache.RateFloat64(cacheKey(attributes), value)
+				}
+			}
+			add(series{resource, attributes, ts}, name, data)
+		})
+
+	case pmetric.MetricTypeHistogram:
+		histogram := metric.Histogram()
+		dps := histogram.DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if dp.Flags().NoRecordedValue() {
+				continue
+			}
+			attributes := mapstr.M(dp.Attributes().AsRaw())
+			promHistogram := toPromHistogram(dp)
+			var data mapstr.M
+			if histogram.AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+				data = collector.DeltaHistogramToES(promHistogram)
+			} else {
+				data = collector.PromHistogramToES(g.counterCache, name+resource.String(), attributes, promHistogram)
+			}
+			add(series{resource, attributes, dp.Timestamp()}, name, mapstr.M{"histogram": data})
+		}
+
+	case pmetric.MetricTypeExponentialHistogram:
+		histogram := metric.ExponentialHistogram()
+		cumulative := histogram.AggregationTemporality() != pmetric.AggregationTemporalityDelta
+		dps := histogram.DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if dp.Flags().NoRecordedValue() {
+				continue
+			}
+			attributes := mapstr.M(dp.Attributes().AsRaw())
+			var cc collector.CounterCache
+			if cumulative {
+				cc = g.counterCache
+			}
+			data := exponentialHistogramToES(cc, cacheKey(attributes), dp)
+			add(series{resource, attributes, dp.Timestamp()}, name, mapstr.M{"histogram": data})
+		}
+	}
+}
+
+// forEachNumber calls fn with the value of the data points that have one.
+func forEachNumber(dps pmetric.NumberDataPointSlice, fn func(mapstr.M, pcommon.Timestamp, float64)) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.Flags().NoRecordedValue() {
+			continue
+		}
+		var value float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			value = float64(dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			value = dp.DoubleValue()
+		default:
+			continue
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		fn(mapstr.M(dp.Attributes().AsRaw()), dp.Timestamp(), value)
+	}
+}
+
+// toPromHistogram converts an OTLP histogram data point to a Prometheus
+// histogram, whose buckets have an upper bound and cumulative counts.
+func toPromHistogram(dp pmetric.HistogramDataPoint) *p.Histogram {
+	bounds := dp.ExplicitBounds()
+	counts := dp.BucketCounts()
+	histogram := &p.Histogram{}
+	var cumulative float64
+	for i := 0; i < counts.Len(); i++ {
+		upperBound := math.Inf(1)
+		if i < bounds.Len() {
+			upperBound = bounds.At(i)
+		}
+		cumulative += float64(counts.At(i))
+		count := cumulative
+		histogram.Bucket = append(histogram.Bucket, &p.Bucket{
+			UpperBound:      &upperBound,
+			CumulativeCount: &count,
+		})
+	}
+	return histogram
+}
+
+// exponentialHistogramToES converts an exponential histogram data point to an
+// ES histogram. Every bucket is represented by the midpoint of its boundaries,
+// and the zero bucket by 0. If a counter cache is given, counts are cumulative
+// and the increase since the previous call is reported, as done for
+// Prometheus histograms.
+func exponentialHistogramToES(cc collector.CounterCache, key string, dp pmetric.ExponentialHistogramDataPoint) mapstr.M {
+	scale := dp.Scale()
+	values := []float64{}
+	counts := []uint64{}
+	add := func(bucket string, value float64, count uint64) {
+		if cc != nil {
+			// The scale is part of the key, so a change of scale is handled
+			// as new buckets.
+			count, _ = cc.RateUint64(key+bucket, count)
+		}
+		values = append(values, value)
+		counts = append(counts, count)
+	}
+
+	negative := dp.Negative()
+	for i := negative.BucketCounts().Len() - 1; i >= 0; i-- {
+		index := int64(negative.Offset()) + int64(i)
+		add("-"+bucketKey(scale, index), -bucketMidpoint(scale, index), negative.BucketCounts().At(i))
+	}
+	add("zero", 0, dp.ZeroCount())
+	positive := dp.Positive()
+	for i := 0; i < positive.BucketCounts().Len(); i++ {
+		index := int64(positive.Offset()) + int64(i)
+		add("+"+bucketKey(scale, index), bucketMidpoint(scale, index), positive.BucketCounts().At(i))
+	}
+
+	return mapstr.M{
+		"values": values,
+		"counts": counts,
+	}
+}
+
+func bucketKey(scale int32, index int64) string {
+	return strconv.Itoa(int(scale)) + ":" + strconv.FormatInt(index, 10)
+}
+
+// bucketMidpoint returns the midpoint of the bucket with the given index of
+// an exponential histogram, whose boundaries are (base^index, base^(index+1)]
+// with base = 2^(2^-scale).
+func bucketMidpoint(scale int32, index int64) float64 {
+	width := math.Exp2(-float64(scale))
+	lower := math.Exp2(float64(index) * width)
+	upper := math.Exp2(float64(index+1) * width)
+	return lower + (upper-lower)/2
+}
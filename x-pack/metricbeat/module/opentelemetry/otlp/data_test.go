@@ -0,0 +1,179 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var testTimestamp = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestGenerator(rateCounters bool) *eventsGenerator {
+	cache := collector.NewCounterCache(time.Hour)
+	return &eventsGenerator{counterCache: cache, rateCounters: rateCounters}
+}
+
+// newTestMetrics returns metrics of a resource with a single scope.
+func newTestMetrics() (pmetric.Metrics, pmetric.MetricSlice) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("host.name", "node-1")
+	return md, rm.ScopeMetrics().AppendEmpty().Metrics()
+}
+
+func appendNumber(dps pmetric.NumberDataPointSlice, queue string, value int64) {
+	dp := dps.AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTimestamp))
+	dp.Attributes().PutStr("queue", queue)
+	dp.SetIntValue(value)
+}
+
+func appendSum(metrics pmetric.MetricSlice, name string, monotonic bool, temporality pmetric.AggregationTemporality) pmetric.Sum {
+	m := metrics.AppendEmpty()
+	m.SetName(name)
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(monotonic)
+	sum.SetAggregationTemporality(temporality)
+	return sum
+}
+
+func TestGenerateEvents(t *testing.T) {
+	md, metrics := newTestMetrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("queue.size")
+	appendNumber(gauge.SetEmptyGauge().DataPoints(), "a", 3)
+	appendNumber(gauge.Gauge().DataPoints(), "b", 7)
+	requests := appendSum(metrics, "queue.requests", true, pmetric.AggregationTemporalityCumulative)
+	appendNumber(requests.DataPoints(), "a", 10)
+	active := appendSum(metrics, "queue.active", false, pmetric.AggregationTemporalityCumulative)
+	appendNumber(active.DataPoints(), "b", 2)
+
+	// Data points without value are ignored.
+	missing := gauge.Gauge().DataPoints().AppendEmpty()
+	missing.Attributes().PutStr("queue", "c")
+	missing.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+
+	events := newTestGenerator(false).GenerateEvents(md)
+	require.Len(t, events, 2)
+
+	resource := mapstr.M{"attributes": mapstr.M{"service.name": "checkout", "host.name": "node-1"}}
+	for _, e := range events {
+		assert.Equal(t, testTimestamp, e.Timestamp.UTC())
+		assert.Equal(t, mapstr.M{"service": mapstr.M{"name": "checkout"}}, e.RootFields)
+		assert.Equal(t, resource, e.ModuleFields["resource"])
+	}
+
+	assert.Equal(t, mapstr.M{"queue": "a"}, events[0].ModuleFields["attributes"])
+	assert.Equal(t, mapstr.M{
+		"queue.size":     mapstr.M{"value": float64(3)},
+		"queue.requests": mapstr.M{"counter": float64(10)},
+	}, events[0].ModuleFields["metrics"])
+
+	assert.Equal(t, mapstr.M{"queue": "b"}, events[1].ModuleFields["attributes"])
+	assert.Equal(t, mapstr.M{
+		"queue.size":   mapstr.M{"value": float64(7)},
+		"queue.active": mapstr.M{"value": float64(2)},
+	}, events[1].ModuleFields["metrics"])
+}
+
+func TestGenerateEventsSums(t *testing.T) {
+	g := newTestGenerator(true)
+	export := func(cumulative, delta int64) mapstr.M {
+		md, metrics := newTestMetrics()
+		appendNumber(appendSum(metrics, "cumulative", true, pmetric.AggregationTemporalityCumulative).DataPoints(), "a", cumulative)
+		appendNumber(appendSum(metrics, "delta", true, pmetric.AggregationTemporalityDelta).DataPoints(), "a", delta)
+		events := g.GenerateEvents(md)
+		require.Len(t, events, 1)
+		return events[0].ModuleFields["metrics"].(mapstr.M)
+	}
+
+	assert.Equal(t, mapstr.M{
+		"cumulative": mapstr.M{"counter": float64(10), "rate": float64(0)},
+		"delta":      mapstr.M{"rate": float64(4)},
+	}, export(10, 4))
+	assert.Equal(t, mapstr.M{
+		"cumulative": mapstr.M{"counter": float64(25), "rate": float64(15)},
+		"delta":      mapstr.M{"rate": float64(6)},
+	}, export(25, 6))
+}
+
+func TestGenerateEventsHistograms(t *testing.T) {
+	g := newTestGenerator(false)
+	export := func(temporality pmetric.AggregationTemporality, counts ...uint64) mapstr.M {
+		md, metrics := newTestMetrics()
+		m := metrics.AppendEmpty()
+		m.SetName("request.duration")
+		histogram := m.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(temporality)
+		dp := histogram.DataPoints().AppendEmpty()
+		dp.ExplicitBounds().FromRaw([]float64{1, 5})
+		dp.BucketCounts().FromRaw(counts)
+		events := g.GenerateEvents(md)
+		require.Len(t, events, 1)
+		return events[0].ModuleFields["metrics"].(mapstr.M)["request.duration"].(mapstr.M)
+	}
+
+	values := []float64{0.5, 3, 5}
+	cases := []struct {
+		temporality pmetric.AggregationTemporality
+		counts      []uint64
+		expected    []uint64
+	}{
+		// The first cumulative histogram is the reference for the next ones.
+		{pmetric.AggregationTemporalityCumulative, []uint64{2, 3, 1}, []uint64{0, 0, 0}},
+		{pmetric.AggregationTemporalityCumulative, []uint64{4, 3, 2}, []uint64{2, 0, 1}},
+		{pmetric.AggregationTemporalityDelta, []uint64{2, 3, 1}, []uint64{2, 3, 1}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, mapstr.M{
+			"histogram": mapstr.M{"values": values, "counts": c.expected},
+		}, export(c.temporality, c.counts...))
+	}
+}
+
+func TestGenerateEventsExponentialHistograms(t *testing.T) {
+	g := newTestGenerator(false)
+	export := func(temporality pmetric.AggregationTemporality, zero uint64, negative, positive []uint64) mapstr.M {
+		md, metrics := newTestMetrics()
+		m := metrics.AppendEmpty()
+		m.SetName("request.size")
+		histogram := m.SetEmptyExponentialHistogram()
+		histogram.SetAggregationTemporality(temporality)
+		dp := histogram.DataPoints().AppendEmpty()
+		// With scale 0, bucket i is (2^i, 2^(i+1)].
+		dp.SetScale(0)
+		dp.SetZeroCount(zero)
+		dp.Negative().SetOffset(1)
+		dp.Negative().BucketCounts().FromRaw(negative)
+		dp.Positive().SetOffset(0)
+		dp.Positive().BucketCounts().FromRaw(positive)
+		events := g.GenerateEvents(md)
+		require.Len(t, events, 1)
+		return events[0].ModuleFields["metrics"].(mapstr.M)["request.size"].(mapstr.M)
+	}
+
+	values := []float64{-3, 0, 1.5, 3}
+	assert.Equal(t, mapstr.M{
+		"histogram": mapstr.M{"values": values, "counts": []uint64{4, 1, 1, 2}},
+	}, export(pmetric.AggregationTemporalityDelta, 1, []uint64{4}, []uint64{1, 2}))
+
+	assert.Equal(t, mapstr.M{
+		"histogram": mapstr.M{"values": values, "counts": []uint64{0, 0, 0, 0}},
+	}, export(pmetric.AggregationTemporalityCumulative, 1, []uint64{4}, []uint64{1, 2}))
+	assert.Equal(t, mapstr.M{
+		"histogram": mapstr.M{"values": values, "counts": []uint64{1, 2, 0, 3}},
+	}, export(pmetric.AggregationTemporalityCumulative, 3, []uint64{5}, []uint64{1, 5}))
+}
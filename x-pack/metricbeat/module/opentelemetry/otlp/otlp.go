@@ -0,0 +1,304 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // Register the gzip compressor for OTLP/gRPC requests.
+	"google.golang.org/grpc/status"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/mb/parse"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// metricsPath is the path of OTLP/HTTP metrics export requests.
+const metricsPath = "/v1/metrics"
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet("opentelemetry", "otlp", New,
+		mb.WithHostParser(parse.EmptyHostParser),
+	)
+}
+
+// MetricSet receives metrics exported with the OpenTelemetry Protocol over
+// gRPC and HTTP.
+type MetricSet struct {
+	mb.BaseMetricSet
+	config    config
+	tlsConfig *tlscommon.TLSConfig
+	generator *eventsGenerator
+
+	events chan []mb.Event
+	// done is closed when the metricset stops reporting events.
+	done chan struct{}
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	base.Logger().Warn(cfgwarn.Beta("The opentelemetry otlp metricset is beta."))
+
+	config := defaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlscommon.LoadTLSServerConfig(config.TLS, base.Logger())
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		config:        config,
+		tlsConfig:     tlsConfig,
+		generator: &eventsGenerator{
+			// use a counter cache with a timeout of 5x the period, as a safe
+			// value to make sure that all counters are available between
+			// exports
+			counterCache: collector.NewCounterCache(config.Period * 5),
+			rateCounters: config.RateCounters,
+		},
+		events: make(chan []mb.Event),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Run receives metrics until the reporter is done.
+func (m *MetricSet) Run(reporter mb.PushReporterV2) {
+	m.generator.counterCache.Start()
+	defer m.generator.counterCache.Stop()
+
+	var grpcServer *grpc.Server
+	var httpServer *http.Server
+	defer func() {
+		// Handlers waiting to send events return once done is closed, so
+		// servers can be stopped.
+		close(m.done)
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		if httpServer != nil {
+			_ = httpServer.Close()
+		}
+	}()
+
+	var err error
+	if m.config.GRPC.Enabled {
+		if grpcServer, err = m.startGRPC(); err != nil {
+			reporter.Error(err)
+			return
+		}
+	}
+	if m.config.HTTP.Enabled {
+		if httpServer, err = m.startHTTP(); err != nil {
+			reporter.Error(err)
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-reporter.Done():
+			return
+		case events := <-m.events:
+			for _, e := range events {
+				reporter.Event(e)
+			}
+		}
+	}
+}
+
+func (m *MetricSet) listen(endpoint string) (net.Listener, error) {
+	l, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", endpoint, err)
+	}
+	return l, nil
+}
+
+func (m *MetricSet) startGRPC() (*grpc.Server, error) {
+	l, err := m.listen(m.config.GRPC.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(m.config.MaxMessageSize)}
+	if m.tlsConfig != nil {
+		host, _, _ := net.SplitHostPort(m.config.GRPC.Endpoint)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(m.tlsConfig.BuildServerConfig(host))))
+	}
+	server := grpc.NewServer(opts...)
+	pmetricotlp.RegisterGRPCServer(server, &metricsServer{m: m})
+
+	m.Logger().Infof("Starting OTLP/gRPC server on %s", l.Addr())
+	go func() {
+		if err := server.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			m.Logger().Errorf("OTLP/gRPC server failed: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+func (m *MetricSet) startHTTP() (*http.Server, error) {
+	l, err := m.listen(m.config.HTTP.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, m.handleHTTP)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if m.tlsConfig != nil {
+		host, _, _ := net.SplitHostPort(m.config.HTTP.Endpoint)
+		server.TLSConfig = m.tlsConfig.BuildServerConfig(host)
+	}
+
+	m.Logger().Infof("Starting OTLP/HTTP server on %s", l.Addr())
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			//certificate is already loaded. That's why the parameters are empty
+			err = server.ServeTLS(l, "", "")
+		} else {
+			err = server.Serve(l)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.Logger().Errorf("OTLP/HTTP server failed: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+// publish converts the metrics of a request to events and waits until they
+// are reported.
+func (m *MetricSet) publish(ctx context.Context, req pmetricotlp.ExportRequest) error {
+	events := m.generator.GenerateEvents(req.Metrics())
+	if len(events) == 0 {
+		return nil
+	}
+	select {
+	case m.events <- events:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.done:
+		return errors.New("metricset is stopped")
+	}
+}
+
+type metricsServer struct {
+	pmetricotlp.UnimplementedGRPCServer
+	m *MetricSet
+}
+
+func (s *metricsServer) Export(ctx context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	if err := s.m.publish(ctx, req); err != nil {
+		return pmetricotlp.NewExportResponse(), status.Error(codes.Unavailable, err.Error())
+	}
+	return pmetricotlp.NewExportResponse(), nil
+}
+
+func (m *MetricSet) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := m.readBody(w, r)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, fmt.Sprintf("request body too large: exceeds %d bytes limit", m.config.MaxMessageSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := pmetricotlp.NewExportRequest()
+	if contentType == contentTypeJSON {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := m.publish(r.Context(), req); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	resp := pmetricotlp.NewExportResponse()
+	var data []byte
+	if contentType == contentTypeJSON {
+		data, err = resp.MarshalJSON()
+	} else {
+		data, err = resp.MarshalProto()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// readBody reads the body of a request, decompressing it if needed. The size
+// of the body is limited to the maximum message size before and after
+// decompression.
+func (m *MetricSet) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	limit := int64(m.config.MaxMessageSize)
+	var body io.Reader = http.MaxBytesReader(w, r.Body, limit)
+	switch encoding := r.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress request: %w", err)
+		}
+		defer gz.Close()
+		body = http.MaxBytesReader(w, io.NopCloser(gz), limit)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,211 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+func freeEndpoint(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	endpoint := l.Addr().String()
+	require.NoError(t, l.Close())
+	return endpoint
+}
+
+func testRequest() pmetricotlp.ExportRequest {
+	md, metrics := newTestMetrics()
+	m := metrics.AppendEmpty()
+	m.SetName("queue.size")
+	appendNumber(m.SetEmptyGauge().DataPoints(), "a", 3)
+	return pmetricotlp.NewExportRequestFromMetrics(md)
+}
+
+func TestRun(t *testing.T) {
+	cases := map[string]struct {
+		protocol string
+		send     func(ctx context.Context, endpoint string) error
+	}{
+		"grpc": {
+			protocol: "grpc",
+			send: func(ctx context.Context, endpoint string) error {
+				conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+				_, err = pmetricotlp.NewGRPCClient(conn).Export(ctx, testRequest())
+				return err
+			},
+		},
+		"http protobuf": {
+			protocol: "http",
+			send: func(ctx context.Context, endpoint string) error {
+				body, err := testRequest().MarshalProto()
+				if err != nil {
+					return err
+				}
+				return post(ctx, endpoint, contentTypeProtobuf, "", body)
+			},
+		},
+		"http json gzip": {
+			protocol: "http",
+			send: func(ctx context.Context, endpoint string) error {
+				data, err := testRequest().MarshalJSON()
+				if err != nil {
+					return err
+				}
+				var body bytes.Buffer
+				gz := gzip.NewWriter(&body)
+				_, _ = gz.Write(data)
+				_ = gz.Close()
+				return post(ctx, endpoint, contentTypeJSON, "gzip", body.Bytes())
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			endpoint := freeEndpoint(t)
+			ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+				"module":     "opentelemetry",
+				"metricsets": []string{"otlp"},
+				"grpc": map[string]interface{}{
+					"enabled":  c.protocol == "grpc",
+					"endpoint": endpoint,
+				},
+				"http": map[string]interface{}{
+					"enabled":  c.protocol == "http",
+					"endpoint": endpoint,
+				},
+			})
+
+			// Requests are sent until one is received, as the server may not
+			// be listening yet.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for ctx.Err() == nil {
+					if c.send(ctx, endpoint) == nil {
+						return
+					}
+					time.Sleep(50 * time.Millisecond)
+				}
+			}()
+
+			events := mbtest.RunPushMetricSetV2(10*time.Second, 1, ms)
+			require.NotEmpty(t, events)
+			assert.Equal(t, mapstr.M{"queue.size": mapstr.M{"value": float64(3)}}, events[0].ModuleFields["metrics"])
+			assert.Equal(t, mapstr.M{"queue": "a"}, events[0].ModuleFields["attributes"])
+			assert.Equal(t, mapstr.M{"service": mapstr.M{"name": "checkout"}}, events[0].RootFields)
+		})
+	}
+}
+
+func post(ctx context.Context, endpoint, contentType, encoding string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+endpoint+metricsPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &httpStatusError{resp.StatusCode}
+	}
+	return nil
+}
+
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func TestHandleHTTPErrors(t *testing.T) {
+	ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+		"module":           "opentelemetry",
+		"metricsets":       []string{"otlp"},
+		"max_message_size": 64,
+	}).(*MetricSet)
+
+	empty, err := pmetricotlp.NewExportRequestFromMetrics(pmetric.NewMetrics()).MarshalProto()
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		method      string
+		contentType string
+		encoding    string
+		body        string
+		status      int
+	}{
+		"get":                  {http.MethodGet, contentTypeProtobuf, "", "", http.StatusMethodNotAllowed},
+		"unsupported type":     {http.MethodPost, "text/plain", "", "", http.StatusUnsupportedMediaType},
+		"unsupported encoding": {http.MethodPost, contentTypeProtobuf, "br", "", http.StatusBadRequest},
+		"invalid json":         {http.MethodPost, contentTypeJSON, "", "{", http.StatusBadRequest},
+		"too large":            {http.MethodPost, contentTypeProtobuf, "", strings.Repeat("x", 65), http.StatusRequestEntityTooLarge},
+		// Requests without metrics are accepted without waiting for the
+		// metricset to report events.
+		"empty":             {http.MethodPost, contentTypeProtobuf, "", string(empty), http.StatusOK},
+		"json with charset": {http.MethodPost, contentTypeJSON + "; charset=utf-8", "", "{}", http.StatusOK},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, metricsPath, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			if c.encoding != "" {
+				req.Header.Set("Content-Encoding", c.encoding)
+			}
+			w := httptest.NewRecorder()
+			ms.handleHTTP(w, req)
+			assert.Equal(t, c.status, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg, err := conf.NewConfigFrom(map[string]interface{}{
+		"module":       "opentelemetry",
+		"metricsets":   []string{"otlp"},
+		"grpc.enabled": false,
+		"http.enabled": false,
+	})
+	require.NoError(t, err)
+
+	_, _, err = mb.NewModule(cfg, mb.Registry, beat.Info{Paths: paths.New(), Logger: logptest.NewTestingLogger(t, "")})
+	assert.ErrorContains(t, err, "at least one of grpc or http must be enabled")
+}
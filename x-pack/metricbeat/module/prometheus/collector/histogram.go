@@ -49,17 +49,8 @@ func PromHistogramToES(cc CounterCache, name string, labels mapstr.M, histogram
 		}
 
 		bucketUpperBound := bucket.GetUpperBound()
-		if bucketUpperBound == math.Inf(0) {
-			// Report +Inf bucket as a point, use the preceding bucket's value
-			values = append(values, lastUpper)
-		} else {
-			// for the first bucket only: if it has a negative "le", use the value as-is
-			if bucketUpperBound < 0 && len(values) == 0 {
-				values = append(values, bucketUpperBound)
-			} else {
-				// calculate bucket centroid
-				values = append(values, lastUpper+(bucketUpperBound-lastUpper)/2.0)
-			}
+		values = append(values, bucketCentroid(len(values) == 0, lastUpper, bucketUpperBound))
+		if bucketUpperBound != math.Inf(0) {
 			lastUpper = bucketUpperBound
 		}
 
@@ -91,3 +82,57 @@ func PromHistogramToES(cc CounterCache, name string, labels mapstr.M, histogram
 
 	return res
 }
+
+// DeltaHistogramToES converts a histogram whose cumulative bucket counts only
+// cover the current period, as the histograms with delta temporality of
+// OpenTelemetry, to an ES histogram. Values are calculated as in
+// PromHistogramToES, and counts are deaccumulated without keeping the state of
+// previous calls.
+func DeltaHistogramToES(histogram *p.Histogram) mapstr.M {
+	var values []float64
+	var counts []uint64
+
+	var lastUpper, prevCount float64
+	for _, bucket := range histogram.GetBucket() {
+		// Ignore non-numbers
+		if math.IsNaN(bucket.GetCumulativeCount()) || math.IsInf(bucket.GetCumulativeCount(), 0) {
+			continue
+		}
+
+		bucketUpperBound := bucket.GetUpperBound()
+		values = append(values, bucketCentroid(len(values) == 0, lastUpper, bucketUpperBound))
+		if bucketUpperBound != math.Inf(0) {
+			lastUpper = bucketUpperBound
+		}
+
+		count := bucket.GetCumulativeCount() - prevCount
+		if count < 0 {
+			// Cumulative counts must not decrease, ignore the bucket count
+			// if they do to avoid overflowing.
+			count = 0
+		}
+		counts = append(counts, uint64(count))
+		prevCount = max(prevCount, bucket.GetCumulativeCount())
+	}
+
+	return mapstr.M{
+		"values": values,
+		"counts": counts,
+	}
+}
+
+// bucketCentroid returns the value that represents a bucket in an ES
+// histogram, given the upper bound of the preceding bucket.
+func bucketCentroid(first bool, lastUpper, upperBound float64) float64 {
+	switch {
+	case upperBound == math.Inf(0):
+		// Report +Inf bucket as a point, use the preceding bucket's value
+		return lastUpper
+	case first && upperBound < 0:
+		// for the first bucket only: if it has a negative "le", use the value as-is
+		return upperBound
+	default:
+		// calculate bucket centroid
+		return lastUpper + (upperBound-lastUpper)/2.0
+	}
+}
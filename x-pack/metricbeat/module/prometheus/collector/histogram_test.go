@@ -8,6 +8,7 @@
 package collector
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -464,3 +465,36 @@ func TestPromHistogramToES(t *testing.T) {
 		})
 	}
 }
+
+func TestDeltaHistogramToES(t *testing.T) {
+	histogram := p.Histogram{
+		SampleCount: proto.Float64(30),
+		Bucket: []*p.Bucket{
+			{
+				UpperBound:      proto.Float64(1),
+				CumulativeCount: proto.Float64(5),
+			},
+			{
+				UpperBound:      proto.Float64(5),
+				CumulativeCount: proto.Float64(12),
+			},
+			{
+				UpperBound:      proto.Float64(10),
+				CumulativeCount: proto.Float64(math.NaN()),
+			},
+			{
+				UpperBound:      proto.Float64(math.Inf(1)),
+				CumulativeCount: proto.Float64(30),
+			},
+		},
+	}
+	expected := mapstr.M{
+		"counts": []uint64{5, 7, 18},
+		"values": []float64{0.5, 3, 5},
+	}
+
+	// Results don't depend on previous calls.
+	for range 2 {
+		assert.Equal(t, expected, DeltaHistogramToES(&histogram))
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// BER tags of the ASN.1 universal types used by SNMP.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagOID         = 0x06
+	tagSequence    = 0x30
+)
+
+var errTruncated = errors.New("truncated BER data")
+
+// appendLength appends a BER definite length.
+func appendLength(b []byte, n int) []byte {
+	switch {
+	case n < 0x80:
+		return append(b, byte(n))
+	case n <= 0xff:
+		return append(b, 0x81, byte(n))
+	case n <= 0xffff:
+		return append(b, 0x82, byte(n>>8), byte(n))
+	case n <= 0xffffff:
+		return append(b, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(b, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// headerLen returns the length of the tag and length octets of a value of
+// n bytes.
+func headerLen(n int) int {
+	return len(appendLength(nil, n)) + 1
+}
+
+// appendTLV appends a value with its tag and length.
+func appendTLV(b []byte, tag byte, value []byte) []byte {
+	b = append(b, tag)
+	b = appendLength(b, len(value))
+	return append(b, value...)
+}
+
+// appendInt appends a signed integer in its shortest two's complement form.
+func appendInt(b []byte, tag byte, v int64) []byte {
+	n := 1
+	for i := v; i > 127 || i < -128; i >>= 8 {
+		n++
+	}
+	b = append(b, tag, byte(n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
+
+// appendUint appends an unsigned integer. A leading zero octet is added if
+// the most significant bit is set, so it is not read as a negative number.
+func appendUint(b []byte, tag byte, v uint64) []byte {
+	n := 1
+	for i := v; i > 0xff; i >>= 8 {
+		n++
+	}
+	pad := v>>(8*n-1)&1 == 1
+	if pad {
+		b = append(b, tag, byte(n+1), 0)
+	} else {
+		b = append(b, tag, byte(n))
+	}
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
+
+// appendOID appends an object identifier.
+func appendOID(b []byte, oid OID) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, fmt.Errorf("invalid object identifier %v", oid)
+	}
+	var value []byte
+	value = appendBase128(value, oid[0]*40+oid[1])
+	for _, sub := range oid[2:] {
+		value = appendBase128(value, sub)
+	}
+	return appendTLV(b, tagOID, value), nil
+}
+
+func appendBase128(b []byte, v uint32) []byte {
+	n := 1
+	for i := v; i >= 0x80; i >>= 7 {
+		n++
+	}
+	for i := n - 1; i > 0; i-- {
+		b = append(b, byte(v>>(7*i))|0x80)
+	}
+	return append(b, byte(v&0x7f))
+}
+
+// readTLV reads a value with its tag and length. It returns the value and the
+// data that follows it. The value is a subslice of b.
+func readTLV(b []byte) (tag byte, value, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errTruncated
+	}
+	tag = b[0]
+	n := int(b[1])
+	off := 2
+	if n&0x80 != 0 {
+		octets := n & 0x7f
+		if octets == 0 || octets > 4 {
+			return 0, nil, nil, fmt.Errorf("unsupported BER length of %d octets", octets)
+		}
+		if len(b) < off+octets {
+			return 0, nil, nil, errTruncated
+		}
+		n = 0
+		for _, c := range b[off : off+octets] {
+			n = n<<8 | int(c)
+		}
+		off += octets
+	}
+	if n < 0 || n > len(b)-off {
+		return 0, nil, nil, errTruncated
+	}
+	return tag, b[off : off+n], b[off+n:], nil
+}
+
+// readExpected reads a value that must have the given tag.
+func readExpected(b []byte, want byte) (value, rest []byte, err error) {
+	tag, value, rest, err := readTLV(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != want {
+		return nil, nil, fmt.Errorf("unexpected BER tag 0x%02x, expected 0x%02x", tag, want)
+	}
+	return value, rest, nil
+}
+
+// readInt reads an integer that must have the given tag.
+func readInt(b []byte, want byte) (v int64, rest []byte, err error) {
+	value, rest, err := readExpected(b, want)
+	if err != nil {
+		return 0, nil, err
+	}
+	v, err = parseInt(value)
+	return v, rest, err
+}
+
+// readInt32 reads an integer that must have the given tag and fit in 32 bits,
+// as most integers of SNMP messages.
+func readInt32(b []byte, want byte) (int32, []byte, error) {
+	v, rest, err := readInt(b, want)
+	if err != nil {
+		return 0, nil, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, nil, fmt.Errorf("integer %d overflows 32 bits", v)
+	}
+	return int32(v), rest, nil
+}
+
+func parseInt(value []byte) (int64, error) {
+	if len(value) == 0 || len(value) > 8 {
+		return 0, fmt.Errorf("invalid integer length %d", len(value))
+	}
+	v := int64(int8(value[0]))
+	for _, c := range value[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+func parseUint(value []byte) (uint64, error) {
+	if len(value) > 1 && value[0] == 0 {
+		value = value[1:]
+	}
+	if len(value) == 0 || len(value) > 8 {
+		return 0, fmt.Errorf("invalid unsigned integer length %d", len(value))
+	}
+	var v uint64
+	for _, c := range value {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func parseOID(value []byte) (OID, error) {
+	if len(value) == 0 {
+		return nil, errors.New("empty object identifier")
+	}
+	var oid OID
+	var sub uint64
+	for i, c := range value {
+		sub = sub<<7 | uint64(c&0x7f)
+		if sub > 0xffffffff {
+			return nil, errors.New("object identifier component overflows 32 bits")
+		}
+		if c&0x80 != 0 {
+			if i == len(value)-1 {
+				return nil, errTruncated
+			}
+			continue
+		}
+		if oid == nil {
+			switch {
+			case sub < 40:
+				oid = OID{0, uint32(sub)}
+			case sub < 80:
+				oid = OID{1, uint32(sub - 40)}
+			default:
+				oid = OID{2, uint32(sub - 80)}
+			}
+		} else {
+			oid = append(oid, uint32(sub))
+		}
+		sub = 0
+	}
+	return oid, nil
+}
@@ -0,0 +1,315 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxMessageSize is the largest SNMP message accepted over UDP.
+const maxMessageSize = 65507
+
+// usmStats is the subtree of the USM statistics returned in reports.
+var usmStats = OID{1, 3, 6, 1, 6, 3, 15, 1, 1}
+
+// Report OIDs of the USM statistics, from SNMP-USER-BASED-SM-MIB.
+var (
+	usmStatsUnsupportedSecLevels = usmStats.Append(1, 0)
+	usmStatsNotInTimeWindows     = usmStats.Append(2, 0)
+	usmStatsUnknownUserNames     = usmStats.Append(3, 0)
+	usmStatsUnknownEngineIDs     = usmStats.Append(4, 0)
+	usmStatsWrongDigests         = usmStats.Append(5, 0)
+	usmStatsDecryptionErrors     = usmStats.Append(6, 0)
+)
+
+var errorStatusNames = []string{
+	"noError", "tooBig", "noSuchName", "badValue", "readOnly", "genErr",
+	"noAccess", "wrongType", "wrongLength", "wrongEncoding", "wrongValue",
+	"noCreation", "inconsistentValue", "resourceUnavailable", "commitFailed",
+	"undoFailed", "authorizationError", "notWritable", "inconsistentName",
+}
+
+// ErrTimeout is returned when an agent doesn't respond.
+var ErrTimeout = errors.New("request timed out")
+
+// Client sends requests to an SNMP agent. Requests are serialized.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+
+	version        Version
+	community      string
+	user           *User
+	contextName    string
+	timeout        time.Duration
+	retries        int
+	maxRepetitions int
+
+	requestID int32
+	engine    engine
+}
+
+// engine is the state of the authoritative SNMPv3 engine of the agent.
+type engine struct {
+	id     []byte
+	boots  int32
+	time   int32
+	synced time.Time
+}
+
+// NewClient returns a client for the agent at the given address.
+func NewClient(host string, config Config, timeout time.Duration) (*Client, error) {
+	version, err := ParseVersion(config.Version)
+	if err != nil {
+		return nil, err
+	}
+	user, err := config.User()
+	if err != nil {
+		return nil, err
+	}
+	if version == Version3 && user == nil {
+		return nil, errors.New("username is required for SNMPv3")
+	}
+	conn, err := net.Dial("udp", host)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", host, err)
+	}
+	return &Client{
+		conn:           conn,
+		version:        version,
+		community:      config.Community,
+		user:           user,
+		contextName:    config.ContextName,
+		timeout:        timeout,
+		retries:        config.Retries,
+		maxRepetitions: config.MaxRepetitions,
+		requestID:      int32(time.Now().UnixNano() & 0x7fffffff),
+	}, nil
+}
+
+// Close closes the connection of the client.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Get returns the variables of the given instances.
+func (c *Client) Get(oids []OID) ([]Variable, error) {
+	pdu := PDU{Type: GetRequest}
+	for _, oid := range oids {
+		pdu.Variables = append(pdu.Variables, Variable{OID: oid, Type: Null})
+	}
+	resp, err := c.request(pdu)
+	if err != nil {
+		return nil, err
+	}
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+	return resp.Variables, nil
+}
+
+// Walk returns all the variables in the subtree of root. GetBulk requests are
+// used for SNMPv2c and SNMPv3, and GetNext requests for SNMPv1.
+func (c *Client) Walk(root OID) ([]Variable, error) {
+	var result []Variable
+	next := root
+	for {
+		pdu := PDU{
+			Type:      GetNextRequest,
+			Variables: []Variable{{OID: next, Type: Null}},
+		}
+		if c.version != Version1 {
+			pdu.Type = GetBulkRequest
+			pdu.ErrorIndex = c.maxRepetitions
+		}
+		resp, err := c.request(pdu)
+		if err != nil {
+			return nil, err
+		}
+		if c.version == Version1 && resp.ErrorStatus == NoSuchName {
+			// End of the MIB view of the agent.
+			return result, nil
+		}
+		if err := responseError(resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Variables) == 0 {
+			return result, nil
+		}
+		for _, v := range resp.Variables {
+			if v.Type == EndOfMibView || !v.OID.HasPrefix(root) {
+				return result, nil
+			}
+			if v.OID.Compare(next) <= 0 {
+				return nil, fmt.Errorf("agent returned %v after %v, OIDs are not increasing", v.OID, next)
+			}
+			result = append(result, v)
+			next = v.OID
+		}
+	}
+}
+
+func responseError(pdu PDU) error {
+	if pdu.ErrorStatus == NoError {
+		return nil
+	}
+	status := fmt.Sprintf("error status %d", pdu.ErrorStatus)
+	if pdu.ErrorStatus > 0 && pdu.ErrorStatus < len(errorStatusNames) {
+		status = errorStatusNames[pdu.ErrorStatus]
+	}
+	if i := pdu.ErrorIndex - 1; i >= 0 && i < len(pdu.Variables) {
+		return fmt.Errorf("agent returned %s for %v", status, pdu.Variables[i].OID)
+	}
+	return fmt.Errorf("agent returned %s", status)
+}
+
+func (c *Client) request(pdu PDU) (PDU, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version == Version3 && c.engine.id == nil {
+		if err := c.discover(); err != nil {
+			return PDU{}, err
+		}
+	}
+
+	// A report about the time window or the engine ID updates the state of
+	// the engine, and the request is sent again once.
+	resynced := false
+	for {
+		resp, err := c.exchange(func(id int32) *Message {
+			pdu.RequestID = id
+			return c.message(pdu, id)
+		})
+		if err != nil {
+			return PDU{}, err
+		}
+		if resp.PDU.Type != Report {
+			return resp.PDU, nil
+		}
+		var report OID
+		if len(resp.PDU.Variables) > 0 {
+			report = resp.PDU.Variables[0].OID
+		}
+		switch {
+		case !resynced && (report.Compare(usmStatsNotInTimeWindows) == 0 || report.Compare(usmStatsUnknownEngineIDs) == 0):
+			c.sync(resp)
+			resynced = true
+		case report.Compare(usmStatsUnknownUserNames) == 0:
+			return PDU{}, ErrUnknownUser
+		case report.Compare(usmStatsWrongDigests) == 0:
+			return PDU{}, ErrAuthentication
+		case report.Compare(usmStatsUnsupportedSecLevels) == 0:
+			return PDU{}, errors.New("agent doesn't support the security level of the user")
+		case report.Compare(usmStatsDecryptionErrors) == 0:
+			return PDU{}, errors.New("agent failed to decrypt the request")
+		default:
+			return PDU{}, fmt.Errorf("agent returned report %v", report)
+		}
+	}
+}
+
+// discover learns the engine ID, boots and time of the agent, as described in
+// RFC 3414 section 4.
+func (c *Client) discover() error {
+	resp, err := c.exchange(func(id int32) *Message {
+		return &Message{
+			Version:   Version3,
+			MessageID: id,
+			MaxSize:   maxMessageSize,
+			Flags:     FlagReportable,
+			PDU:       PDU{Type: GetRequest, RequestID: id},
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error discovering SNMPv3 engine: %w", err)
+	}
+	if len(resp.EngineID) == 0 {
+		return errors.New("agent didn't report its SNMPv3 engine ID")
+	}
+	c.sync(resp)
+	return nil
+}
+
+func (c *Client) sync(resp *Message) {
+	c.engine = engine{
+		id:     resp.EngineID,
+		boots:  resp.EngineBoots,
+		time:   resp.EngineTime,
+		synced: time.Now(),
+	}
+}
+
+// message wraps a PDU in a message of the version of the client.
+func (c *Client) message(pdu PDU, id int32) *Message {
+	if c.version != Version3 {
+		return &Message{Version: c.version, Community: c.community, PDU: pdu}
+	}
+	return &Message{
+		Version:         Version3,
+		MessageID:       id,
+		MaxSize:         maxMessageSize,
+		Flags:           c.user.Flags() | FlagReportable,
+		EngineID:        c.engine.id,
+		EngineBoots:     c.engine.boots,
+		EngineTime:      c.engine.time + int32(time.Since(c.engine.synced)/time.Second),
+		UserName:        c.user.Name,
+		ContextEngineID: c.engine.id,
+		ContextName:     c.contextName,
+		PDU:             pdu,
+	}
+}
+
+// exchange sends a message and waits for its response, retrying on timeouts.
+// Every attempt uses a new request ID.
+func (c *Client) exchange(build func(id int32) *Message) (*Message, error) {
+	buf := make([]byte, maxMessageSize)
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		c.requestID = (c.requestID + 1) & 0x7fffffff
+		req := build(c.requestID)
+		packet, err := req.Marshal(c.user)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := c.conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := c.conn.Read(buf)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading response: %w", err)
+			}
+			resp, err := Unmarshal(buf[:n], c.user)
+			if err != nil || !matches(req, resp) {
+				// Responses to previous attempts and invalid messages are
+				// ignored.
+				continue
+			}
+			return resp, nil
+		}
+	}
+	return nil, ErrTimeout
+}
+
+func matches(req, resp *Message) bool {
+	if req.Version != resp.Version {
+		return false
+	}
+	if req.Version == Version3 {
+		return req.MessageID == resp.MessageID
+	}
+	return req.PDU.RequestID == resp.PDU.RequestID
+}
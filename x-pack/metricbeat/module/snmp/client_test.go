@@ -0,0 +1,145 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/snmptest"
+)
+
+var (
+	sysDescr  = snmp.OID{1, 3, 6, 1, 2, 1, 1, 1, 0}
+	sysUpTime = snmp.OID{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	ifDescr   = snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 2}
+)
+
+func testVariables() []snmp.Variable {
+	vars := []snmp.Variable{
+		{OID: sysDescr, Type: snmp.OctetString, Value: []byte("test router")},
+		{OID: sysUpTime, Type: snmp.TimeTicks, Value: uint64(4200)},
+		{OID: snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 1}, Type: snmp.Counter32, Value: uint64(100)},
+	}
+	for i := uint32(1); i <= 25; i++ {
+		vars = append(vars, snmp.Variable{OID: ifDescr.Append(i), Type: snmp.OctetString, Value: []byte{'e', 't', 'h', byte('0' + i%10)}})
+	}
+	return vars
+}
+
+func startAgent(t *testing.T, user *snmp.User) *snmptest.Agent {
+	t.Helper()
+	agent, err := snmptest.NewAgent("public", user, testVariables())
+	require.NoError(t, err)
+	t.Cleanup(func() { agent.Close() })
+	return agent
+}
+
+func newClient(t *testing.T, addr string, config snmp.Config) *snmp.Client {
+	t.Helper()
+	require.NoError(t, config.Validate())
+	client, err := snmp.NewClient(addr, config, 200*time.Millisecond)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient(t *testing.T) {
+	user, err := snmp.NewUser("beats", snmp.SHA256, "authpassword", snmp.AES, "privpassword")
+	require.NoError(t, err)
+	agent := startAgent(t, user)
+
+	configs := map[string]snmp.Config{
+		"v1":  {Version: "1", Community: "public", MaxRepetitions: 10},
+		"v2c": {Version: "2c", Community: "public", MaxRepetitions: 10},
+		"v3": {
+			Version: "3", MaxRepetitions: 7, Username: "beats",
+			AuthProtocol: "sha256", AuthPassword: "authpassword",
+			PrivProtocol: "aes", PrivPassword: "privpassword",
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t, agent.Addr(), config)
+
+			vars, err := client.Get([]snmp.OID{sysDescr, sysUpTime})
+			require.NoError(t, err)
+			require.Len(t, vars, 2)
+			assert.Equal(t, []byte("test router"), vars[0].Value)
+			assert.Equal(t, snmp.TimeTicks, vars[1].Type)
+			assert.Equal(t, uint64(4200), vars[1].Value)
+
+			vars, err = client.Walk(ifDescr)
+			require.NoError(t, err)
+			require.Len(t, vars, 25)
+			assert.Equal(t, ifDescr.Append(25), vars[24].OID)
+
+			// Walking past the last object of the agent.
+			vars, err = client.Walk(snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 10})
+			require.NoError(t, err)
+			assert.Len(t, vars, 1)
+		})
+	}
+}
+
+func TestClientGetMissing(t *testing.T) {
+	agent := startAgent(t, nil)
+	missing := snmp.OID{1, 3, 6, 1, 2, 1, 1, 9, 0}
+
+	client := newClient(t, agent.Addr(), snmp.Config{Version: "2c", Community: "public", MaxRepetitions: 10})
+	vars, err := client.Get([]snmp.OID{sysDescr, missing})
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	assert.Equal(t, snmp.NoSuchObject, vars[1].Type)
+
+	client = newClient(t, agent.Addr(), snmp.Config{Version: "1", Community: "public", MaxRepetitions: 10})
+	_, err = client.Get([]snmp.OID{sysDescr, missing})
+	require.ErrorContains(t, err, "noSuchName for 1.3.6.1.2.1.1.9.0")
+}
+
+func TestClientRetries(t *testing.T) {
+	agent := startAgent(t, nil)
+
+	client := newClient(t, agent.Addr(), snmp.Config{Version: "2c", Community: "public", Retries: 2, MaxRepetitions: 10})
+	agent.Drop(2)
+	_, err := client.Get([]snmp.OID{sysDescr})
+	require.NoError(t, err)
+	assert.Equal(t, 3, agent.Requests())
+
+	client = newClient(t, agent.Addr(), snmp.Config{Version: "2c", Community: "private", Retries: 1, MaxRepetitions: 10})
+	_, err = client.Get([]snmp.OID{sysDescr})
+	require.ErrorIs(t, err, snmp.ErrTimeout)
+}
+
+func TestClientV3(t *testing.T) {
+	user, err := snmp.NewUser("beats", snmp.MD5, "authpassword", snmp.DES, "privpassword")
+	require.NoError(t, err)
+	agent := startAgent(t, user)
+
+	config := snmp.Config{
+		Version: "3", MaxRepetitions: 10, Username: "beats",
+		AuthProtocol: "MD5", AuthPassword: "authpassword",
+		PrivProtocol: "DES", PrivPassword: "privpassword",
+	}
+	client := newClient(t, agent.Addr(), config)
+	_, err = client.Get([]snmp.OID{sysDescr})
+	require.NoError(t, err)
+
+	// The agent reboots, the client resynchronizes with it after receiving
+	// a report.
+	agent.Reboot()
+	vars, err := client.Get([]snmp.OID{sysDescr})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test router"), vars[0].Value)
+
+	config.AuthPassword = "wrongpassword"
+	client = newClient(t, agent.Addr(), config)
+	_, err = client.Get([]snmp.OID{sysDescr})
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+)
+
+// Config contains the SNMP settings shared by the metricsets of the module.
+type Config struct {
+	Version        string   `config:"version"`
+	Community      string   `config:"community"`
+	Retries        int      `config:"retries" validate:"min=0"`
+	MaxRepetitions int      `config:"max_repetitions" validate:"min=1"`
+	MIBPaths       []string `config:"mib_paths"`
+
+	// SNMPv3 settings.
+	Username     string `config:"username"`
+	AuthProtocol string `config:"auth_protocol"`
+	AuthPassword string `config:"auth_password"`
+	PrivProtocol string `config:"priv_protocol"`
+	PrivPassword string `config:"priv_password"`
+	ContextName  string `config:"context_name"`
+}
+
+// DefaultConfig returns the default settings of the module.
+func DefaultConfig() Config {
+	return Config{
+		Version:        "2c",
+		Community:      "public",
+		Retries:        1,
+		MaxRepetitions: 10,
+	}
+}
+
+// Validate checks the version and the SNMPv3 credentials.
+func (c *Config) Validate() error {
+	version, err := ParseVersion(c.Version)
+	if err != nil {
+		return err
+	}
+	if version == Version3 {
+		if c.Username == "" {
+			return errors.New("username is required for SNMPv3")
+		}
+		if _, err := c.User(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// User returns the SNMPv3 user of the configuration, or nil if no username is
+// set.
+func (c *Config) User() (*User, error) {
+	if c.Username == "" {
+		return nil, nil
+	}
+	return NewUser(c.Username, AuthProtocol(c.AuthProtocol), c.AuthPassword, PrivProtocol(c.PrivProtocol), c.PrivPassword)
+}
@@ -0,0 +1,9 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package snmp is a Metricbeat module that polls SNMP agents and receives SNMP
+// traps. It contains the implementation of the protocol used by its
+// metricsets: message encoding, the User-based Security Model of SNMPv3, a
+// client and the resolution of object names from MIB files.
+package snmp
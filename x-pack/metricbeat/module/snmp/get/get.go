@@ -0,0 +1,217 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package get
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/mb/parse"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var hostParser = parse.URLHostParserBuilder{
+	DefaultScheme: "udp",
+	DefaultPort:   "161",
+}.Build()
+
+func init() {
+	mb.Registry.MustAddMetricSet("snmp", "get", New,
+		mb.WithHostParser(hostParser),
+		mb.DefaultMetricSet(),
+	)
+}
+
+type oidConfig struct {
+	OID   string `config:"oid" validate:"required"`
+	Field string `config:"field"`
+}
+
+type tableConfig struct {
+	Name    string      `config:"name" validate:"required"`
+	Columns []oidConfig `config:"columns" validate:"required"`
+}
+
+type config struct {
+	snmp.Config `config:",inline"`
+
+	OIDs   []oidConfig   `config:"oids"`
+	Tables []tableConfig `config:"tables"`
+}
+
+func (c *config) Validate() error {
+	if err := c.Config.Validate(); err != nil {
+		return err
+	}
+	if len(c.OIDs) == 0 && len(c.Tables) == 0 {
+		return errors.New("at least one OID or table must be configured")
+	}
+	return nil
+}
+
+// field is an object mapped to a field of the events.
+type field struct {
+	oid  snmp.OID
+	name string
+}
+
+type table struct {
+	name    string
+	columns []field
+}
+
+// MetricSet polls SNMP agents for the configured objects and tables.
+type MetricSet struct {
+	mb.BaseMetricSet
+	client  *snmp.Client
+	scalars []field
+	tables  []table
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	config := config{Config: snmp.DefaultConfig()}
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	mib, err := snmp.LoadMIB(config.MIBPaths)
+	if err != nil {
+		return nil, err
+	}
+	m := &MetricSet{BaseMetricSet: base}
+	if m.scalars, err = resolveFields(mib, config.OIDs); err != nil {
+		return nil, err
+	}
+	for _, t := range config.Tables {
+		columns, err := resolveFields(mib, t.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid columns of table %s: %w", t.Name, err)
+		}
+		m.tables = append(m.tables, table{name: t.Name, columns: columns})
+	}
+
+	m.client, err = snmp.NewClient(base.HostData().Host, config.Config, base.Module().Config().Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// resolveFields resolves the objects of the configuration. Objects without a
+// field name are stored under their name in the MIB, without the ".0" suffix
+// of scalar instances.
+func resolveFields(mib *snmp.MIB, configs []oidConfig) ([]field, error) {
+	fields := make([]field, 0, len(configs))
+	for _, c := range configs {
+		oid, err := mib.Resolve(c.OID)
+		if err != nil {
+			return nil, err
+		}
+		name := c.Field
+		if name == "" {
+			name = strings.TrimSuffix(mib.Name(oid), ".0")
+		}
+		fields = append(fields, field{oid: oid, name: name})
+	}
+	return fields, nil
+}
+
+// Fetch reports an event with the configured objects, and an event for each
+// row of the configured tables.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	if len(m.scalars) > 0 {
+		if err := m.fetchScalars(reporter); err != nil {
+			return err
+		}
+	}
+	for _, t := range m.tables {
+		if err := m.fetchTable(reporter, t); err != nil {
+			return fmt.Errorf("error fetching table %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+func (m *MetricSet) fetchScalars(reporter mb.ReporterV2) error {
+	oids := make([]snmp.OID, len(m.scalars))
+	for i, f := range m.scalars {
+		oids[i] = f.oid
+	}
+	vars, err := m.client.Get(oids)
+	if err != nil {
+		return err
+	}
+	values := mapstr.M{}
+	for i, v := range vars {
+		if i >= len(m.scalars) || v.Type.Exception() {
+			continue
+		}
+		_, _ = values.Put(m.scalars[i].name, v.FieldValue())
+	}
+	if len(values) == 0 {
+		m.Logger().Debugf("none of the configured objects exist in agent %s", m.Host())
+		return nil
+	}
+	reporter.Event(mb.Event{MetricSetFields: mapstr.M{"values": values}})
+	return nil
+}
+
+// fetchTable walks the columns of a table. Variables with the same index in
+// different columns belong to the same row.
+func (m *MetricSet) fetchTable(reporter mb.ReporterV2, t table) error {
+	type row struct {
+		index  snmp.OID
+		values mapstr.M
+	}
+	rows := map[string]*row{}
+	for _, column := range t.columns {
+		vars, err := m.client.Walk(column.oid)
+		if err != nil {
+			return err
+		}
+		for _, v := range vars {
+			if v.Type.Exception() {
+				continue
+			}
+			index := v.OID[len(column.oid):]
+			r, found := rows[index.String()]
+			if !found {
+				r = &row{index: index, values: mapstr.M{}}
+				rows[index.String()] = r
+			}
+			_, _ = r.values.Put(column.name, v.FieldValue())
+		}
+	}
+
+	sorted := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].index.Compare(sorted[j].index) < 0
+	})
+	for _, r := range sorted {
+		if !reporter.Event(mb.Event{
+			MetricSetFields: mapstr.M{
+				"table":  t.name,
+				"index":  r.index.String(),
+				"values": r.values,
+			},
+		}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close closes the connection with the agent.
+func (m *MetricSet) Close() error {
+	return m.client.Close()
+}
@@ -0,0 +1,128 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package get
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/snmptest"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+var (
+	ifDescr       = snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 2}
+	ifPhysAddress = snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 6}
+	ifHCInOctets  = snmp.OID{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6}
+)
+
+func startAgent(t *testing.T) *snmptest.Agent {
+	t.Helper()
+	vars := []snmp.Variable{
+		{OID: snmp.OID{1, 3, 6, 1, 2, 1, 1, 1, 0}, Type: snmp.OctetString, Value: []byte("test router")},
+		{OID: snmp.OID{1, 3, 6, 1, 2, 1, 1, 3, 0}, Type: snmp.TimeTicks, Value: uint64(4200)},
+		{OID: snmp.OID{1, 3, 6, 1, 2, 1, 1, 5, 0}, Type: snmp.OctetString, Value: []byte("router1")},
+		{OID: ifDescr.Append(1), Type: snmp.OctetString, Value: []byte("lo")},
+		{OID: ifDescr.Append(2), Type: snmp.OctetString, Value: []byte("eth0")},
+		{OID: ifPhysAddress.Append(2), Type: snmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+		{OID: ifHCInOctets.Append(1), Type: snmp.Counter64, Value: uint64(1000)},
+		{OID: ifHCInOctets.Append(2), Type: snmp.Counter64, Value: uint64(2000)},
+	}
+	user, err := snmp.NewUser("beats", snmp.SHA, "authpassword", snmp.AES, "privpassword")
+	require.NoError(t, err)
+	agent, err := snmptest.NewAgent("public", user, vars)
+	require.NoError(t, err)
+	t.Cleanup(func() { agent.Close() })
+	return agent
+}
+
+func TestFetch(t *testing.T) {
+	agent := startAgent(t)
+
+	for name, settings := range map[string]map[string]interface{}{
+		"v2c": {"version": "2c", "community": "public"},
+		"v3": {
+			"version":       "3",
+			"username":      "beats",
+			"auth_protocol": "SHA",
+			"auth_password": "authpassword",
+			"priv_protocol": "AES",
+			"priv_password": "privpassword",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"module":     "snmp",
+				"metricsets": []string{"get"},
+				"hosts":      []string{agent.Addr()},
+				"oids": []map[string]interface{}{
+					{"oid": "sysDescr.0"},
+					{"oid": "1.3.6.1.2.1.1.3.0", "field": "uptime"},
+					{"oid": "sysContact.0"},
+				},
+				"tables": []map[string]interface{}{{
+					"name": "interfaces",
+					"columns": []map[string]interface{}{
+						{"oid": "ifDescr", "field": "name"},
+						{"oid": "IF-MIB::ifPhysAddress", "field": "mac"},
+						{"oid": "ifHCInOctets", "field": "in.bytes"},
+					},
+				}},
+			}
+			for k, v := range settings {
+				config[k] = v
+			}
+
+			ms := mbtest.NewReportingMetricSetV2Error(t, config)
+			events, errs := mbtest.ReportingFetchV2Error(ms)
+			require.Empty(t, errs)
+			require.Len(t, events, 3)
+
+			// sysContact.0 doesn't exist in the agent.
+			assert.Equal(t, mapstr.M{
+				"values": mapstr.M{"sysDescr": "test router", "uptime": uint64(4200)},
+			}, events[0].MetricSetFields)
+			assert.Equal(t, mapstr.M{
+				"table": "interfaces",
+				"index": "1",
+				"values": mapstr.M{
+					"name": "lo",
+					"in":   mapstr.M{"bytes": uint64(1000)},
+				},
+			}, events[1].MetricSetFields)
+			assert.Equal(t, mapstr.M{
+				"table": "interfaces",
+				"index": "2",
+				"values": mapstr.M{
+					"name": "eth0",
+					"mac":  "00:1a:2b:3c:4d:5e",
+					"in":   mapstr.M{"bytes": uint64(2000)},
+				},
+			}, events[2].MetricSetFields)
+		})
+	}
+}
+
+func TestFetchUnknownObject(t *testing.T) {
+	config := map[string]interface{}{
+		"module":     "snmp",
+		"metricsets": []string{"get"},
+		"hosts":      []string{"127.0.0.1"},
+		"oids":       []map[string]interface{}{{"oid": "noSuchObject.0"}},
+	}
+	c, err := conf.NewConfigFrom(config)
+	require.NoError(t, err)
+	_, _, err = mb.NewModule(c, mb.Registry, beat.Info{Paths: paths.New(), Logger: logptest.NewTestingLogger(t, "")})
+	require.ErrorContains(t, err, "unknown object name")
+}
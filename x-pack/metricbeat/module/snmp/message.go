@@ -0,0 +1,274 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+)
+
+// Version is the version of the SNMP protocol.
+type Version int
+
+// Versions of SNMP, as encoded in messages.
+const (
+	Version1  Version = 0
+	Version2c Version = 1
+	Version3  Version = 3
+)
+
+// ParseVersion parses a version as written in configurations: 1, 2c or 3.
+func ParseVersion(s string) (Version, error) {
+	switch s {
+	case "1":
+		return Version1, nil
+	case "2c", "2":
+		return Version2c, nil
+	case "3":
+		return Version3, nil
+	}
+	return 0, fmt.Errorf("unsupported SNMP version %q", s)
+}
+
+func (v Version) String() string {
+	switch v {
+	case Version1:
+		return "1"
+	case Version2c:
+		return "2c"
+	case Version3:
+		return "3"
+	}
+	return fmt.Sprintf("unknown(%d)", int(v))
+}
+
+// Flags of SNMPv3 messages.
+const (
+	FlagAuth       byte = 0x01
+	FlagPriv       byte = 0x02
+	FlagReportable byte = 0x04
+)
+
+// securityModelUSM is the identifier of the User-based Security Model.
+const securityModelUSM = 3
+
+// Message is an SNMP message. Community is used by SNMPv1 and SNMPv2c, and
+// the other fields by SNMPv3.
+type Message struct {
+	Version   Version
+	Community string
+
+	MessageID       int32
+	MaxSize         int32
+	Flags           byte
+	EngineID        []byte
+	EngineBoots     int32
+	EngineTime      int32
+	UserName        string
+	ContextEngineID []byte
+	ContextName     string
+
+	PDU PDU
+}
+
+// Marshal encodes a message. SNMPv3 messages with authentication or privacy
+// flags are signed and encrypted with the keys of the user.
+func (m *Message) Marshal(user *User) ([]byte, error) {
+	pdu, err := m.PDU.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if m.Version != Version3 {
+		body := appendInt(nil, tagInteger, int64(m.Version))
+		body = appendTLV(body, tagOctetString, []byte(m.Community))
+		body = append(body, pdu...)
+		return appendTLV(nil, tagSequence, body), nil
+	}
+
+	if m.Flags&(FlagAuth|FlagPriv) != 0 && (user == nil || user.Flags()&m.Flags != m.Flags&(FlagAuth|FlagPriv)) {
+		return nil, errors.New("the security level of the message is not supported by the user")
+	}
+
+	scoped := appendTLV(nil, tagOctetString, m.ContextEngineID)
+	scoped = appendTLV(scoped, tagOctetString, []byte(m.ContextName))
+	scoped = appendTLV(nil, tagSequence, append(scoped, pdu...))
+
+	var authParams, privParams []byte
+	if m.Flags&FlagAuth != 0 {
+		authParams = make([]byte, user.digestLen())
+	}
+	if m.Flags&FlagPriv != 0 {
+		encrypted, params, err := user.encrypt(m.EngineID, m.EngineBoots, m.EngineTime, scoped)
+		if err != nil {
+			return nil, fmt.Errorf("error encrypting scoped PDU: %w", err)
+		}
+		scoped = appendTLV(nil, tagOctetString, encrypted)
+		privParams = params
+	}
+
+	// The offset of the authentication parameters is tracked while the
+	// message is encoded, so that the digest can be set in place.
+	sp := appendTLV(nil, tagOctetString, m.EngineID)
+	sp = appendInt(sp, tagInteger, int64(m.EngineBoots))
+	sp = appendInt(sp, tagInteger, int64(m.EngineTime))
+	sp = appendTLV(sp, tagOctetString, []byte(m.UserName))
+	authOffset := len(sp) + headerLen(len(authParams))
+	sp = appendTLV(sp, tagOctetString, authParams)
+	sp = appendTLV(sp, tagOctetString, privParams)
+	authOffset += headerLen(len(sp))
+	sp = appendTLV(nil, tagSequence, sp)
+
+	header := appendInt(nil, tagInteger, int64(m.MessageID))
+	header = appendInt(header, tagInteger, int64(m.MaxSize))
+	header = appendTLV(header, tagOctetString, []byte{m.Flags})
+	header = appendInt(header, tagInteger, securityModelUSM)
+
+	body := appendInt(nil, tagInteger, int64(Version3))
+	body = appendTLV(body, tagSequence, header)
+	authOffset += len(body) + headerLen(len(sp))
+	body = appendTLV(body, tagOctetString, sp)
+	body = append(body, scoped...)
+	authOffset += headerLen(len(body))
+	packet := appendTLV(nil, tagSequence, body)
+
+	if m.Flags&FlagAuth != 0 {
+		copy(packet[authOffset:], user.digest(m.EngineID, packet))
+	}
+	return packet, nil
+}
+
+// Unmarshal decodes a message. The digest of authenticated SNMPv3 messages is
+// verified and encrypted ones are decrypted with the keys of the user.
+func Unmarshal(b []byte, user *User) (*Message, error) {
+	body, _, err := readExpected(b, tagSequence)
+	if err != nil {
+		return nil, fmt.Errorf("error reading message: %w", err)
+	}
+	version, body, err := readInt(body, tagInteger)
+	if err != nil {
+		return nil, fmt.Errorf("error reading version: %w", err)
+	}
+	m := &Message{Version: Version(version)}
+	switch m.Version {
+	case Version1, Version2c:
+		community, body, err := readExpected(body, tagOctetString)
+		if err != nil {
+			return nil, fmt.Errorf("error reading community: %w", err)
+		}
+		m.Community = string(community)
+		if m.PDU, err = unmarshalPDU(body); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case Version3:
+		if err := m.unmarshalV3(b, body, user); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported SNMP version %d", version)
+}
+
+func (m *Message) unmarshalV3(packet, body []byte, user *User) error {
+	header, body, err := readExpected(body, tagSequence)
+	if err != nil {
+		return fmt.Errorf("error reading header: %w", err)
+	}
+	if m.MessageID, header, err = readInt32(header, tagInteger); err != nil {
+		return fmt.Errorf("error reading message ID: %w", err)
+	}
+	if m.MaxSize, header, err = readInt32(header, tagInteger); err != nil {
+		return fmt.Errorf("error reading maximum size: %w", err)
+	}
+	flags, header, err := readExpected(header, tagOctetString)
+	if err != nil || len(flags) != 1 {
+		return errors.New("error reading message flags")
+	}
+	m.Flags = flags[0]
+	n, _, err := readInt(header, tagInteger)
+	if err != nil {
+		return fmt.Errorf("error reading security model: %w", err)
+	}
+	if n != securityModelUSM {
+		return fmt.Errorf("unsupported security model %d", n)
+	}
+
+	params, body, err := readExpected(body, tagOctetString)
+	if err != nil {
+		return fmt.Errorf("error reading security parameters: %w", err)
+	}
+	sp, _, err := readExpected(params, tagSequence)
+	if err != nil {
+		return fmt.Errorf("error reading security parameters: %w", err)
+	}
+	var value, authParams, privParams []byte
+	if value, sp, err = readExpected(sp, tagOctetString); err != nil {
+		return fmt.Errorf("error reading engine ID: %w", err)
+	}
+	m.EngineID = append([]byte(nil), value...)
+	if m.EngineBoots, sp, err = readInt32(sp, tagInteger); err != nil {
+		return fmt.Errorf("error reading engine boots: %w", err)
+	}
+	if m.EngineTime, sp, err = readInt32(sp, tagInteger); err != nil {
+		return fmt.Errorf("error reading engine time: %w", err)
+	}
+	if value, sp, err = readExpected(sp, tagOctetString); err != nil {
+		return fmt.Errorf("error reading user name: %w", err)
+	}
+	m.UserName = string(value)
+	if authParams, sp, err = readExpected(sp, tagOctetString); err != nil {
+		return fmt.Errorf("error reading authentication parameters: %w", err)
+	}
+	if privParams, _, err = readExpected(sp, tagOctetString); err != nil {
+		return fmt.Errorf("error reading privacy parameters: %w", err)
+	}
+
+	if m.Flags&(FlagAuth|FlagPriv) != 0 {
+		if user == nil || user.Name != m.UserName {
+			return ErrUnknownUser
+		}
+		if user.Flags()&m.Flags != m.Flags&(FlagAuth|FlagPriv) {
+			return errors.New("unsupported security level")
+		}
+	}
+	if m.Flags&FlagAuth != 0 {
+		if len(authParams) != user.digestLen() {
+			return ErrAuthentication
+		}
+		// authParams is a subslice of packet, so the difference of their
+		// capacities is its offset in the packet.
+		offset := cap(packet) - cap(authParams)
+		zeroed := append([]byte(nil), packet...)
+		clear(zeroed[offset : offset+len(authParams)])
+		if !hmac.Equal(authParams, user.digest(m.EngineID, zeroed)) {
+			return ErrAuthentication
+		}
+	}
+	if m.Flags&FlagPriv != 0 {
+		encrypted, _, err := readExpected(body, tagOctetString)
+		if err != nil {
+			return fmt.Errorf("error reading encrypted PDU: %w", err)
+		}
+		if body, err = user.decrypt(m.EngineID, m.EngineBoots, m.EngineTime, privParams, encrypted); err != nil {
+			return fmt.Errorf("error decrypting scoped PDU: %w", err)
+		}
+	}
+
+	scoped, _, err := readExpected(body, tagSequence)
+	if err != nil {
+		return fmt.Errorf("error reading scoped PDU: %w", err)
+	}
+	if value, scoped, err = readExpected(scoped, tagOctetString); err != nil {
+		return fmt.Errorf("error reading context engine ID: %w", err)
+	}
+	m.ContextEngineID = append([]byte(nil), value...)
+	if value, scoped, err = readExpected(scoped, tagOctetString); err != nil {
+		return fmt.Errorf("error reading context name: %w", err)
+	}
+	m.ContextName = string(value)
+	m.PDU, err = unmarshalPDU(scoped)
+	return err
+}
@@ -0,0 +1,446 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MIB resolves names of objects to object identifiers and back. It knows the
+// objects of the system and interfaces groups, and the objects defined in the
+// MIB files loaded into it.
+//
+// MIB files are not fully parsed. Only the assignments of object identifiers
+// are collected, which is enough to resolve names.
+type MIB struct {
+	// definitions by qualified name (MODULE::name).
+	definitions map[string]definition
+	// modules defining each name, in load order.
+	modules map[string][]string
+
+	names map[string]OID
+	oids  map[string]string
+}
+
+// definition is an object identifier assignment: the value of the object is
+// its parent followed by some sub-identifiers. Assignments with absolute
+// values have no parent.
+type definition struct {
+	module string
+	parent string
+	subs   []uint32
+}
+
+// builtinDefinitions are the objects known without loading any MIB file, in
+// the format of an assignment: module, name, parent and sub-identifiers.
+var builtinDefinitions = []struct {
+	module, name, parent string
+	subs                 []uint32
+}{
+	{"SNMPv2-SMI", "iso", "", []uint32{1}},
+	{"SNMPv2-SMI", "org", "iso", []uint32{3}},
+	{"SNMPv2-SMI", "dod", "org", []uint32{6}},
+	{"SNMPv2-SMI", "internet", "dod", []uint32{1}},
+	{"SNMPv2-SMI", "directory", "internet", []uint32{1}},
+	{"SNMPv2-SMI", "mgmt", "internet", []uint32{2}},
+	{"SNMPv2-SMI", "mib-2", "mgmt", []uint32{1}},
+	{"SNMPv2-SMI", "transmission", "mib-2", []uint32{10}},
+	{"SNMPv2-SMI", "experimental", "internet", []uint32{3}},
+	{"SNMPv2-SMI", "private", "internet", []uint32{4}},
+	{"SNMPv2-SMI", "enterprises", "private", []uint32{1}},
+	{"SNMPv2-SMI", "security", "internet", []uint32{5}},
+	{"SNMPv2-SMI", "snmpV2", "internet", []uint32{6}},
+	{"SNMPv2-SMI", "snmpDomains", "snmpV2", []uint32{1}},
+	{"SNMPv2-SMI", "snmpProxys", "snmpV2", []uint32{2}},
+	{"SNMPv2-SMI", "snmpModules", "snmpV2", []uint32{3}},
+
+	{"SNMPv2-MIB", "system", "mib-2", []uint32{1}},
+	{"SNMPv2-MIB", "sysDescr", "system", []uint32{1}},
+	{"SNMPv2-MIB", "sysObjectID", "system", []uint32{2}},
+	{"SNMPv2-MIB", "sysUpTime", "system", []uint32{3}},
+	{"SNMPv2-MIB", "sysContact", "system", []uint32{4}},
+	{"SNMPv2-MIB", "sysName", "system", []uint32{5}},
+	{"SNMPv2-MIB", "sysLocation", "system", []uint32{6}},
+	{"SNMPv2-MIB", "sysServices", "system", []uint32{7}},
+	{"SNMPv2-MIB", "snmpMIB", "snmpModules", []uint32{1}},
+	{"SNMPv2-MIB", "snmpMIBObjects", "snmpMIB", []uint32{1}},
+	{"SNMPv2-MIB", "snmpTrap", "snmpMIBObjects", []uint32{4}},
+	{"SNMPv2-MIB", "snmpTrapOID", "snmpTrap", []uint32{1}},
+	{"SNMPv2-MIB", "snmpTrapEnterprise", "snmpTrap", []uint32{3}},
+	{"SNMPv2-MIB", "snmpTraps", "snmpMIBObjects", []uint32{5}},
+	{"SNMPv2-MIB", "coldStart", "snmpTraps", []uint32{1}},
+	{"SNMPv2-MIB", "warmStart", "snmpTraps", []uint32{2}},
+	{"SNMPv2-MIB", "authenticationFailure", "snmpTraps", []uint32{5}},
+
+	{"IF-MIB", "interfaces", "mib-2", []uint32{2}},
+	{"IF-MIB", "ifNumber", "interfaces", []uint32{1}},
+	{"IF-MIB", "ifTable", "interfaces", []uint32{2}},
+	{"IF-MIB", "ifEntry", "ifTable", []uint32{1}},
+	{"IF-MIB", "ifIndex", "ifEntry", []uint32{1}},
+	{"IF-MIB", "ifDescr", "ifEntry", []uint32{2}},
+	{"IF-MIB", "ifType", "ifEntry", []uint32{3}},
+	{"IF-MIB", "ifMtu", "ifEntry", []uint32{4}},
+	{"IF-MIB", "ifSpeed", "ifEntry", []uint32{5}},
+	{"IF-MIB", "ifPhysAddress", "ifEntry", []uint32{6}},
+	{"IF-MIB", "ifAdminStatus", "ifEntry", []uint32{7}},
+	{"IF-MIB", "ifOperStatus", "ifEntry", []uint32{8}},
+	{"IF-MIB", "ifLastChange", "ifEntry", []uint32{9}},
+	{"IF-MIB", "ifInOctets", "ifEntry", []uint32{10}},
+	{"IF-MIB", "ifInUcastPkts", "ifEntry", []uint32{11}},
+	{"IF-MIB", "ifInDiscards", "ifEntry", []uint32{13}},
+	{"IF-MIB", "ifInErrors", "ifEntry", []uint32{14}},
+	{"IF-MIB", "ifOutOctets", "ifEntry", []uint32{16}},
+	{"IF-MIB", "ifOutUcastPkts", "ifEntry", []uint32{17}},
+	{"IF-MIB", "ifOutDiscards", "ifEntry", []uint32{19}},
+	{"IF-MIB", "ifOutErrors", "ifEntry", []uint32{20}},
+	{"IF-MIB", "ifMIB", "mib-2", []uint32{31}},
+	{"IF-MIB", "ifMIBObjects", "ifMIB", []uint32{1}},
+	{"IF-MIB", "ifXTable", "ifMIBObjects", []uint32{1}},
+	{"IF-MIB", "ifXEntry", "ifXTable", []uint32{1}},
+	{"IF-MIB", "ifName", "ifXEntry", []uint32{1}},
+	{"IF-MIB", "ifHCInOctets", "ifXEntry", []uint32{6}},
+	{"IF-MIB", "ifHCInUcastPkts", "ifXEntry", []uint32{7}},
+	{"IF-MIB", "ifHCOutOctets", "ifXEntry", []uint32{10}},
+	{"IF-MIB", "ifHCOutUcastPkts", "ifXEntry", []uint32{11}},
+	{"IF-MIB", "ifHighSpeed", "ifXEntry", []uint32{15}},
+	{"IF-MIB", "ifAlias", "ifXEntry", []uint32{18}},
+	{"IF-MIB", "linkDown", "snmpTraps", []uint32{3}},
+	{"IF-MIB", "linkUp", "snmpTraps", []uint32{4}},
+}
+
+// oidMacros are the macros whose values are object identifiers.
+var oidMacros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+}
+
+// NewMIB returns a MIB with the builtin objects.
+func NewMIB() *MIB {
+	m := &MIB{
+		definitions: map[string]definition{},
+		modules:     map[string][]string{},
+	}
+	for _, d := range builtinDefinitions {
+		m.define(d.module, d.name, definition{module: d.module, parent: d.parent, subs: d.subs})
+	}
+	m.build()
+	return m
+}
+
+// LoadMIB returns a MIB with the builtin objects and the objects defined in
+// the given files. Directories are loaded with all the files they contain.
+func LoadMIB(paths []string) (*MIB, error) {
+	m := NewMIB()
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error loading MIB: %w", err)
+		}
+		if info.IsDir() {
+			err = m.LoadDir(path)
+		} else {
+			err = m.LoadFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// LoadDir loads all the files in a directory. Hidden files and
+// subdirectories are ignored.
+func (m *MIB) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading MIB directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if err := m.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads the object identifier assignments of a MIB file. Objects
+// whose parents are not defined in any of the loaded files are ignored.
+func (m *MIB) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading MIB file: %w", err)
+	}
+	if err := m.parse(string(data)); err != nil {
+		return fmt.Errorf("error parsing MIB file %s: %w", path, err)
+	}
+	m.build()
+	return nil
+}
+
+// Resolve returns the object identifier of a name. Names can be numeric
+// object identifiers, object names optionally qualified with their module
+// (IF-MIB::ifDescr), and object names followed by numeric sub-identifiers
+// (sysDescr.0).
+func (m *MIB) Resolve(name string) (OID, error) {
+	if name == "" {
+		return nil, errors.New("empty object name")
+	}
+	if c := name[0]; c == '.' || (c >= '0' && c <= '9') {
+		return ParseOID(name)
+	}
+	// The suffix starts at the first dot after the module qualifier.
+	key, suffix := name, OID(nil)
+	start := strings.LastIndex(name, "::") + 1
+	if dot := strings.IndexByte(name[start:], '.'); dot >= 0 {
+		key = name[:start+dot]
+		var err error
+		if suffix, err = ParseOID(name[start+dot+1:]); err != nil {
+			return nil, fmt.Errorf("invalid suffix in object name %q", name)
+		}
+	}
+	oid, found := m.names[key]
+	if !found {
+		return nil, fmt.Errorf("unknown object name %q", key)
+	}
+	return oid.Append(suffix...), nil
+}
+
+// Name returns the name of the closest known ancestor of an object followed
+// by the remaining sub-identifiers, as in ifDescr.3. Objects without known
+// ancestors are returned in numeric form.
+func (m *MIB) Name(oid OID) string {
+	for n := len(oid); n > 0; n-- {
+		name, found := m.oids[oid[:n].String()]
+		if !found {
+			continue
+		}
+		if n == len(oid) {
+			return name
+		}
+		return name + "." + oid[n:].String()
+	}
+	return oid.String()
+}
+
+func (m *MIB) define(module, name string, d definition) {
+	key := module + "::" + name
+	if _, found := m.definitions[key]; !found {
+		m.modules[name] = append(m.modules[name], module)
+	}
+	m.definitions[key] = d
+}
+
+// build resolves all the definitions. Names are resolved within their module
+// first, and then in the order modules were loaded.
+func (m *MIB) build() {
+	m.names = map[string]OID{}
+	m.oids = map[string]string{}
+	resolving := map[string]bool{}
+	var resolve func(module, name string) OID
+	resolve = func(module, name string) OID {
+		key := module + "::" + name
+		if oid, found := m.names[key]; found {
+			return oid
+		}
+		d, found := m.definitions[key]
+		if !found {
+			modules := m.modules[name]
+			if len(modules) == 0 {
+				return nil
+			}
+			key = modules[0] + "::" + name
+			if d, found = m.definitions[key]; !found {
+				return nil
+			}
+		}
+		if resolving[key] {
+			// Circular definition.
+			return nil
+		}
+		resolving[key] = true
+		defer delete(resolving, key)
+
+		var oid OID
+		if d.parent == "" {
+			oid = OID(d.subs).Append()
+		} else {
+			parent := resolve(d.module, d.parent)
+			if parent == nil {
+				return nil
+			}
+			oid = parent.Append(d.subs...)
+		}
+		m.names[key] = oid
+		return oid
+	}
+
+	for key, d := range m.definitions {
+		_, name, _ := strings.Cut(key, "::")
+		resolve(d.module, name)
+	}
+	for name, modules := range m.modules {
+		for _, module := range modules {
+			oid, found := m.names[module+"::"+name]
+			if !found {
+				continue
+			}
+			if _, found := m.names[name]; !found {
+				m.names[name] = oid
+			}
+			if _, found := m.oids[oid.String()]; !found {
+				m.oids[oid.String()] = name
+			}
+		}
+	}
+}
+
+// parse collects the object identifier assignments of the modules in a MIB
+// file. Assignments look like "name MACRO ... ::= { parent 1 }" or
+// "name OBJECT IDENTIFIER ::= { parent 1 2 }".
+func (m *MIB) parse(text string) error {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return err
+	}
+	module := ""
+	current := ""
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		next := func(n int) string {
+			if i+n < len(tokens) {
+				return tokens[i+n]
+			}
+			return ""
+		}
+		switch {
+		case next(1) == "DEFINITIONS":
+			module = tok
+			current = ""
+		case isValueName(tok) && next(1) == "OBJECT" && next(2) == "IDENTIFIER" && next(3) == "::=":
+			current = tok
+		case isValueName(tok) && oidMacros[next(1)]:
+			current = tok
+		case tok == "::=" && next(1) == "{":
+			if current == "" {
+				continue
+			}
+			end := i + 2
+			for end < len(tokens) && tokens[end] != "}" {
+				end++
+			}
+			if end == len(tokens) {
+				return fmt.Errorf("unterminated value of %s", current)
+			}
+			d, err := parseValue(tokens[i+2 : end])
+			if err != nil {
+				return fmt.Errorf("invalid value of %s: %w", current, err)
+			}
+			d.module = module
+			m.define(module, current, d)
+			current = ""
+			i = end
+		case tok == "::=":
+			current = ""
+		}
+	}
+	return nil
+}
+
+// parseValue parses the components of an object identifier value. Named
+// numbers, as in iso(1), are taken by their number.
+func parseValue(components []string) (definition, error) {
+	var d definition
+	for i := 0; i < len(components); i++ {
+		c := components[i]
+		if isValueName(c) && i+3 < len(components) && components[i+1] == "(" && components[i+3] == ")" {
+			c = components[i+2]
+			i += 3
+		} else if isValueName(c) {
+			if i > 0 {
+				return d, fmt.Errorf("unexpected name %s", c)
+			}
+			d.parent = c
+			continue
+		}
+		n, err := strconv.ParseUint(c, 10, 32)
+		if err != nil {
+			return d, fmt.Errorf("invalid sub-identifier %q", c)
+		}
+		d.subs = append(d.subs, uint32(n))
+	}
+	if d.parent == "" && len(d.subs) == 0 {
+		return d, errors.New("empty value")
+	}
+	return d, nil
+}
+
+// isValueName returns whether a token is a value reference, which in ASN.1
+// starts with a lowercase letter.
+func isValueName(tok string) bool {
+	return tok != "" && unicode.IsLower(rune(tok[0]))
+}
+
+// tokenize splits a MIB file into tokens, dropping comments and the contents
+// of quoted strings.
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(text[i:], "--"):
+			// Comments end at the end of the line or at the next "--".
+			end := i + 2
+			for end < len(text) && text[end] != '\n' && !strings.HasPrefix(text[end:], "--") {
+				end++
+			}
+			i = min(end+2, len(text))
+			if end < len(text) && text[end] == '\n' {
+				i = end + 1
+			}
+		case c == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, `""`)
+			i += end + 2
+		case strings.HasPrefix(text[i:], "::="):
+			tokens = append(tokens, "::=")
+			i += 3
+		case isIdentChar(c):
+			end := i + 1
+			for end < len(text) && isIdentChar(text[end]) && !strings.HasPrefix(text[end:], "--") {
+				end++
+			}
+			tokens = append(tokens, text[i:end])
+			i = end
+		default:
+			tokens = append(tokens, text[i:i+1])
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
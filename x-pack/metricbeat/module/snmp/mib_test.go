@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMIBBuiltin(t *testing.T) {
+	mib := NewMIB()
+
+	for name, want := range map[string]string{
+		"sysDescr.0":              "1.3.6.1.2.1.1.1.0",
+		"SNMPv2-MIB::sysUpTime.0": "1.3.6.1.2.1.1.3.0",
+		"IF-MIB::ifHCInOctets":    "1.3.6.1.2.1.31.1.1.1.6",
+		".1.3.6.1.2.1.2.2.1.2.3":  "1.3.6.1.2.1.2.2.1.2.3",
+		"linkUp":                  "1.3.6.1.6.3.1.1.5.4",
+	} {
+		oid, err := mib.Resolve(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, want, oid.String(), name)
+		}
+	}
+
+	_, err := mib.Resolve("noSuchObject.0")
+	require.Error(t, err)
+	_, err = mib.Resolve("sysDescr.x")
+	require.Error(t, err)
+
+	assert.Equal(t, "ifDescr.3", mib.Name(OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 3}))
+	assert.Equal(t, "sysUpTime", mib.Name(OID{1, 3, 6, 1, 2, 1, 1, 3}))
+	assert.Equal(t, "2.5.4", mib.Name(OID{2, 5, 4}))
+}
+
+func TestMIBLoad(t *testing.T) {
+	mib, err := LoadMIB([]string{"testdata"})
+	require.NoError(t, err)
+
+	for name, want := range map[string]string{
+		"beatsTestMIB":                       "1.3.6.1.4.1.99999",
+		"beatsVersion.0":                     "1.3.6.1.4.1.99999.1.1.0",
+		"BEATS-TEST-MIB::beatsQueueEvents.2": "1.3.6.1.4.1.99999.1.2.1.2.2",
+		"beatsQueueFull":                     "1.3.6.1.4.1.99999.0.1",
+		"beatsAbsolute":                      "1.3.4242",
+	} {
+		oid, err := mib.Resolve(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, want, oid.String(), name)
+		}
+	}
+
+	// The SEQUENCE type and the enumeration don't define objects.
+	_, err = mib.Resolve("BeatsQueueEntry")
+	require.Error(t, err)
+	_, err = mib.Resolve("primary")
+	require.Error(t, err)
+
+	assert.Equal(t, "beatsQueueEvents.1", mib.Name(OID{1, 3, 6, 1, 4, 1, 99999, 1, 2, 1, 2, 1}))
+}
+
+func TestMIBLoadMissing(t *testing.T) {
+	_, err := LoadMIB([]string{"testdata/missing"})
+	require.Error(t, err)
+}
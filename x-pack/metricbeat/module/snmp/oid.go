@@ -0,0 +1,88 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OID is a numeric object identifier.
+type OID []uint32
+
+// ParseOID parses an object identifier in dotted notation. A leading dot is
+// accepted.
+func ParseOID(s string) (OID, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil, errors.New("empty object identifier")
+	}
+	parts := strings.Split(s, ".")
+	oid := make(OID, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object identifier %q", s)
+		}
+		oid[i] = uint32(n)
+	}
+	return oid, nil
+}
+
+// String returns the object identifier in dotted notation.
+func (o OID) String() string {
+	var sb strings.Builder
+	for i, sub := range o {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(sub), 10))
+	}
+	return sb.String()
+}
+
+// HasPrefix returns whether the object identifier is in the subtree of
+// prefix.
+func (o OID) HasPrefix(prefix OID) bool {
+	if len(o) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if o[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare returns -1, 0 or +1 depending on whether o precedes, is equal to or
+// follows other in lexicographical order.
+func (o OID) Compare(other OID) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		switch {
+		case o[i] < other[i]:
+			return -1
+		case o[i] > other[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(o) < len(other):
+		return -1
+	case len(o) > len(other):
+		return 1
+	}
+	return 0
+}
+
+// Append returns a new object identifier with the given sub-identifiers
+// appended.
+func (o OID) Append(subs ...uint32) OID {
+	oid := make(OID, 0, len(o)+len(subs))
+	oid = append(oid, o...)
+	return append(oid, subs...)
+}
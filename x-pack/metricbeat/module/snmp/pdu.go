@@ -0,0 +1,342 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Type is the type of the value of a variable binding.
+type Type byte
+
+// Types of SNMP values.
+const (
+	Integer          Type = tagInteger
+	OctetString      Type = tagOctetString
+	Null             Type = tagNull
+	ObjectIdentifier Type = tagOID
+	IPAddress        Type = 0x40
+	Counter32        Type = 0x41
+	Gauge32          Type = 0x42
+	TimeTicks        Type = 0x43
+	Opaque           Type = 0x44
+	Counter64        Type = 0x46
+	NoSuchObject     Type = 0x80
+	NoSuchInstance   Type = 0x81
+	EndOfMibView     Type = 0x82
+)
+
+var typeNames = map[Type]string{
+	Integer:          "integer",
+	OctetString:      "octet_string",
+	Null:             "null",
+	ObjectIdentifier: "object_identifier",
+	IPAddress:        "ip_address",
+	Counter32:        "counter32",
+	Gauge32:          "gauge32",
+	TimeTicks:        "timeticks",
+	Opaque:           "opaque",
+	Counter64:        "counter64",
+	NoSuchObject:     "no_such_object",
+	NoSuchInstance:   "no_such_instance",
+	EndOfMibView:     "end_of_mib_view",
+}
+
+func (t Type) String() string {
+	if name, found := typeNames[t]; found {
+		return name
+	}
+	return fmt.Sprintf("unknown(0x%02x)", byte(t))
+}
+
+// Exception returns whether the type signals that there is no value for a
+// requested object.
+func (t Type) Exception() bool {
+	return t == NoSuchObject || t == NoSuchInstance || t == EndOfMibView
+}
+
+// Variable is a variable binding. The Go type of the value depends on the
+// SNMP type:
+//   - Integer: int64.
+//   - Counter32, Gauge32, TimeTicks and Counter64: uint64.
+//   - OctetString and Opaque: []byte.
+//   - ObjectIdentifier: OID.
+//   - IPAddress: net.IP.
+//   - Null and exceptions: nil.
+type Variable struct {
+	OID   OID
+	Type  Type
+	Value interface{}
+}
+
+// FieldValue returns the value of the variable as stored in events. Octet
+// strings are returned as text when they are printable and as colon separated
+// hexadecimal bytes otherwise, as is usual for MAC addresses. Object
+// identifiers and IP addresses are returned in their textual form.
+func (v Variable) FieldValue() interface{} {
+	switch value := v.Value.(type) {
+	case []byte:
+		if printable(value) {
+			return string(value)
+		}
+		return hexString(value)
+	case OID:
+		return value.String()
+	case net.IP:
+		return value.String()
+	}
+	return v.Value
+}
+
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hexString(b []byte) string {
+	parts := make([]string, len(b))
+	for i := range b {
+		parts[i] = hex.EncodeToString(b[i : i+1])
+	}
+	return strings.Join(parts, ":")
+}
+
+// PDUType is the type of a protocol data unit.
+type PDUType byte
+
+// Types of protocol data units.
+const (
+	GetRequest     PDUType = 0xa0
+	GetNextRequest PDUType = 0xa1
+	GetResponse    PDUType = 0xa2
+	SetRequest     PDUType = 0xa3
+	TrapV1         PDUType = 0xa4
+	GetBulkRequest PDUType = 0xa5
+	InformRequest  PDUType = 0xa6
+	TrapV2         PDUType = 0xa7
+	Report         PDUType = 0xa8
+)
+
+// Error statuses of responses.
+const (
+	NoError    = 0
+	TooBig     = 1
+	NoSuchName = 2
+)
+
+// PDU is a protocol data unit.
+type PDU struct {
+	Type      PDUType
+	RequestID int32
+	// ErrorStatus is the number of non-repeaters in GetBulk requests.
+	ErrorStatus int
+	// ErrorIndex is the maximum number of repetitions in GetBulk requests.
+	ErrorIndex int
+	Variables  []Variable
+
+	// Fields of SNMPv1 traps.
+	Enterprise   OID
+	AgentAddress net.IP
+	GenericTrap  int
+	SpecificTrap int
+	Timestamp    uint32
+}
+
+func (p *PDU) marshal() ([]byte, error) {
+	var body []byte
+	if p.Type == TrapV1 {
+		var err error
+		if body, err = appendOID(body, p.Enterprise); err != nil {
+			return nil, err
+		}
+		body = appendTLV(body, byte(IPAddress), p.AgentAddress.To4())
+		body = appendInt(body, tagInteger, int64(p.GenericTrap))
+		body = appendInt(body, tagInteger, int64(p.SpecificTrap))
+		body = appendUint(body, byte(TimeTicks), uint64(p.Timestamp))
+	} else {
+		body = appendInt(body, tagInteger, int64(p.RequestID))
+		body = appendInt(body, tagInteger, int64(p.ErrorStatus))
+		body = appendInt(body, tagInteger, int64(p.ErrorIndex))
+	}
+	var vars []byte
+	for _, v := range p.Variables {
+		var err error
+		if vars, err = appendVariable(vars, v); err != nil {
+			return nil, err
+		}
+	}
+	body = appendTLV(body, tagSequence, vars)
+	return appendTLV(nil, byte(p.Type), body), nil
+}
+
+func appendVariable(b []byte, v Variable) ([]byte, error) {
+	bind, err := appendOID(nil, v.OID)
+	if err != nil {
+		return nil, err
+	}
+	switch v.Type {
+	case Integer:
+		n, ok := v.Value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("value of %v is not an int64", v.OID)
+		}
+		bind = appendInt(bind, byte(v.Type), n)
+	case Counter32, Gauge32, TimeTicks, Counter64:
+		n, ok := v.Value.(uint64)
+		if !ok {
+			return nil, fmt.Errorf("value of %v is not an uint64", v.OID)
+		}
+		bind = appendUint(bind, byte(v.Type), n)
+	case OctetString, Opaque:
+		s, ok := v.Value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("value of %v is not a byte slice", v.OID)
+		}
+		bind = appendTLV(bind, byte(v.Type), s)
+	case ObjectIdentifier:
+		oid, ok := v.Value.(OID)
+		if !ok {
+			return nil, fmt.Errorf("value of %v is not an OID", v.OID)
+		}
+		if bind, err = appendOID(bind, oid); err != nil {
+			return nil, err
+		}
+	case IPAddress:
+		ip, ok := v.Value.(net.IP)
+		if !ok || ip.To4() == nil {
+			return nil, fmt.Errorf("value of %v is not an IPv4 address", v.OID)
+		}
+		bind = appendTLV(bind, byte(v.Type), ip.To4())
+	case Null, NoSuchObject, NoSuchInstance, EndOfMibView:
+		bind = appendTLV(bind, byte(v.Type), nil)
+	default:
+		return nil, fmt.Errorf("unsupported type %v of %v", v.Type, v.OID)
+	}
+	return appendTLV(b, tagSequence, bind), nil
+}
+
+func unmarshalPDU(b []byte) (PDU, error) {
+	tag, body, _, err := readTLV(b)
+	if err != nil {
+		return PDU{}, fmt.Errorf("error reading PDU: %w", err)
+	}
+	p := PDU{Type: PDUType(tag)}
+	switch p.Type {
+	case GetRequest, GetNextRequest, GetResponse, SetRequest, GetBulkRequest, InformRequest, TrapV2, Report:
+		if p.RequestID, body, err = readInt32(body, tagInteger); err != nil {
+			return p, fmt.Errorf("error reading request ID: %w", err)
+		}
+		var n int32
+		if n, body, err = readInt32(body, tagInteger); err != nil {
+			return p, fmt.Errorf("error reading error status: %w", err)
+		}
+		p.ErrorStatus = int(n)
+		if n, body, err = readInt32(body, tagInteger); err != nil {
+			return p, fmt.Errorf("error reading error index: %w", err)
+		}
+		p.ErrorIndex = int(n)
+	case TrapV1:
+		var value []byte
+		if value, body, err = readExpected(body, tagOID); err != nil {
+			return p, fmt.Errorf("error reading enterprise: %w", err)
+		}
+		if p.Enterprise, err = parseOID(value); err != nil {
+			return p, err
+		}
+		if value, body, err = readExpected(body, byte(IPAddress)); err != nil {
+			return p, fmt.Errorf("error reading agent address: %w", err)
+		}
+		p.AgentAddress = net.IP(append([]byte(nil), value...))
+		var n int32
+		if n, body, err = readInt32(body, tagInteger); err != nil {
+			return p, fmt.Errorf("error reading generic trap: %w", err)
+		}
+		p.GenericTrap = int(n)
+		if n, body, err = readInt32(body, tagInteger); err != nil {
+			return p, fmt.Errorf("error reading specific trap: %w", err)
+		}
+		p.SpecificTrap = int(n)
+		if value, body, err = readExpected(body, byte(TimeTicks)); err != nil {
+			return p, fmt.Errorf("error reading timestamp: %w", err)
+		}
+		ticks, err := parseUint(value)
+		if err != nil {
+			return p, err
+		}
+		if ticks > math.MaxUint32 {
+			return p, fmt.Errorf("timestamp %d overflows 32 bits", ticks)
+		}
+		p.Timestamp = uint32(ticks)
+	default:
+		return p, fmt.Errorf("unsupported PDU type 0x%02x", tag)
+	}
+
+	vars, _, err := readExpected(body, tagSequence)
+	if err != nil {
+		return p, fmt.Errorf("error reading variable bindings: %w", err)
+	}
+	for len(vars) > 0 {
+		var bind []byte
+		if bind, vars, err = readExpected(vars, tagSequence); err != nil {
+			return p, fmt.Errorf("error reading variable binding: %w", err)
+		}
+		v, err := unmarshalVariable(bind)
+		if err != nil {
+			return p, err
+		}
+		p.Variables = append(p.Variables, v)
+	}
+	return p, nil
+}
+
+func unmarshalVariable(b []byte) (v Variable, err error) {
+	name, b, err := readExpected(b, tagOID)
+	if err != nil {
+		return v, fmt.Errorf("error reading variable name: %w", err)
+	}
+	if v.OID, err = parseOID(name); err != nil {
+		return v, err
+	}
+	tag, value, _, err := readTLV(b)
+	if err != nil {
+		return v, fmt.Errorf("error reading value of %v: %w", v.OID, err)
+	}
+	v.Type = Type(tag)
+	switch v.Type {
+	case Integer:
+		v.Value, err = parseInt(value)
+	case Counter32, Gauge32, TimeTicks, Counter64:
+		v.Value, err = parseUint(value)
+	case OctetString, Opaque:
+		v.Value = append([]byte(nil), value...)
+	case ObjectIdentifier:
+		v.Value, err = parseOID(value)
+	case IPAddress:
+		if len(value) != net.IPv4len {
+			return v, fmt.Errorf("invalid IP address length %d for %v", len(value), v.OID)
+		}
+		v.Value = net.IP(append([]byte(nil), value...))
+	case Null, NoSuchObject, NoSuchInstance, EndOfMibView:
+	default:
+		return v, fmt.Errorf("unsupported type 0x%02x of %v", tag, v.OID)
+	}
+	if err != nil {
+		return v, fmt.Errorf("error decoding value of %v: %w", v.OID, err)
+	}
+	return v, nil
+}
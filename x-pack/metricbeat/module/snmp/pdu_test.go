@@ -0,0 +1,103 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageV2c(t *testing.T) {
+	m := &Message{
+		Version:   Version2c,
+		Community: "public",
+		PDU: PDU{
+			Type:      GetResponse,
+			RequestID: -5,
+			Variables: []Variable{
+				{OID: OID{1, 3, 6, 1, 2, 1, 1, 1, 0}, Type: OctetString, Value: []byte("router")},
+				{OID: OID{1, 3, 6, 1, 2, 1, 1, 2, 0}, Type: ObjectIdentifier, Value: OID{1, 3, 6, 1, 4, 1, 9, 1, 516}},
+				{OID: OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 7, 1}, Type: Integer, Value: int64(-129)},
+				{OID: OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 1}, Type: Counter32, Value: uint64(math.MaxUint32)},
+				{OID: OID{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6, 1}, Type: Counter64, Value: uint64(math.MaxUint64)},
+				{OID: OID{1, 3, 6, 1, 2, 1, 4, 20, 1, 1, 10, 0, 0, 1}, Type: IPAddress, Value: net.IP{10, 0, 0, 1}},
+				{OID: OID{1, 3, 6, 1, 2, 1, 1, 9, 0}, Type: NoSuchObject},
+			},
+		},
+	}
+	packet, err := m.Marshal(nil)
+	require.NoError(t, err)
+
+	decoded, err := Unmarshal(packet, nil)
+	require.NoError(t, err)
+	assert.Equal(t, m, decoded)
+
+	for i := range packet {
+		// Truncated messages must fail without panicking.
+		_, err := Unmarshal(packet[:i], nil)
+		require.Error(t, err)
+	}
+}
+
+func TestMessageTrapV1(t *testing.T) {
+	m := &Message{
+		Version:   Version1,
+		Community: "public",
+		PDU: PDU{
+			Type:         TrapV1,
+			Enterprise:   OID{1, 3, 6, 1, 4, 1, 8072},
+			AgentAddress: net.IP{192, 168, 1, 1},
+			GenericTrap:  6,
+			SpecificTrap: 17,
+			Timestamp:    300,
+			Variables: []Variable{
+				{OID: OID{1, 3, 6, 1, 4, 1, 8072, 1}, Type: Gauge32, Value: uint64(80)},
+			},
+		},
+	}
+	packet, err := m.Marshal(nil)
+	require.NoError(t, err)
+
+	decoded, err := Unmarshal(packet, nil)
+	require.NoError(t, err)
+	assert.Equal(t, m, decoded)
+}
+
+func TestParseOID(t *testing.T) {
+	oid, err := ParseOID(".1.3.6.1")
+	require.NoError(t, err)
+	assert.Equal(t, OID{1, 3, 6, 1}, oid)
+	assert.Equal(t, "1.3.6.1", oid.String())
+	assert.True(t, OID{1, 3, 6, 1, 2}.HasPrefix(oid))
+	assert.Equal(t, -1, oid.Compare(OID{1, 3, 6, 1, 0}))
+	assert.Equal(t, 1, oid.Compare(OID{1, 3, 5, 9}))
+
+	for _, s := range []string{"", ".", "1..3", "1.x", "1.4294967296"} {
+		_, err := ParseOID(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	for _, tc := range []struct {
+		v    Variable
+		want interface{}
+	}{
+		{Variable{Type: OctetString, Value: []byte("eth0")}, "eth0"},
+		{Variable{Type: OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0xff}}, "00:1a:2b:ff"},
+		{Variable{Type: OctetString, Value: []byte{}}, ""},
+		{Variable{Type: ObjectIdentifier, Value: OID{1, 3, 6}}, "1.3.6"},
+		{Variable{Type: IPAddress, Value: net.IP{10, 0, 0, 1}}, "10.0.0.1"},
+		{Variable{Type: Counter64, Value: uint64(42)}, uint64(42)},
+		{Variable{Type: Integer, Value: int64(-1)}, int64(-1)},
+		{Variable{Type: Null}, nil},
+	} {
+		assert.Equal(t, tc.want, tc.v.FieldValue())
+	}
+}
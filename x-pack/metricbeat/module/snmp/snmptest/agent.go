@@ -0,0 +1,270 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package snmptest provides an in-process SNMP agent for tests.
+package snmptest
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+)
+
+// EngineID is the SNMPv3 engine ID of the agents.
+var EngineID = []byte{0x80, 0x00, 0x1f, 0x88, 0x04, 'b', 'e', 'a', 't', 's'}
+
+var (
+	usmStatsNotInTimeWindows = snmp.OID{1, 3, 6, 1, 6, 3, 15, 1, 1, 2, 0}
+	usmStatsUnknownEngineIDs = snmp.OID{1, 3, 6, 1, 6, 3, 15, 1, 1, 4, 0}
+)
+
+// Agent is an SNMP agent serving a fixed set of variables over UDP. It answers
+// Get, GetNext and GetBulk requests of all versions. Requests with a wrong
+// community or invalid credentials are ignored.
+type Agent struct {
+	// Community accepted in SNMPv1 and SNMPv2c requests.
+	Community string
+	// User accepted in SNMPv3 requests.
+	User *snmp.User
+
+	conn    *net.UDPConn
+	started time.Time
+	vars    []snmp.Variable
+
+	boots    atomic.Int32
+	drop     atomic.Int32
+	requests atomic.Int32
+
+	wg sync.WaitGroup
+}
+
+// NewAgent returns an agent listening on a random local port.
+func NewAgent(community string, user *snmp.User, vars []snmp.Variable) (*Agent, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]snmp.Variable(nil), vars...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OID.Compare(sorted[j].OID) < 0
+	})
+	a := &Agent{
+		Community: community,
+		User:      user,
+		conn:      conn,
+		started:   time.Now(),
+		vars:      sorted,
+	}
+	a.boots.Store(1)
+	a.wg.Add(1)
+	go a.serve()
+	return a, nil
+}
+
+// Addr returns the address the agent listens on.
+func (a *Agent) Addr() string {
+	return a.conn.LocalAddr().String()
+}
+
+// Close stops the agent.
+func (a *Agent) Close() error {
+	err := a.conn.Close()
+	a.wg.Wait()
+	return err
+}
+
+// Reboot increments the number of times the SNMPv3 engine of the agent has
+// been restarted.
+func (a *Agent) Reboot() {
+	a.boots.Add(1)
+}
+
+// Drop makes the agent ignore the next n requests.
+func (a *Agent) Drop(n int) {
+	a.drop.Store(int32(n))
+}
+
+// Requests returns the number of requests received, including the dropped
+// ones.
+func (a *Agent) Requests() int {
+	return int(a.requests.Load())
+}
+
+func (a *Agent) serve() {
+	defer a.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		a.requests.Add(1)
+		if a.drop.Add(-1) >= 0 {
+			continue
+		}
+		resp := a.handle(buf[:n])
+		if resp == nil {
+			continue
+		}
+		packet, err := resp.Marshal(a.User)
+		if err != nil {
+			continue
+		}
+		_, _ = a.conn.WriteToUDP(packet, addr)
+	}
+}
+
+func (a *Agent) engineTime() int32 {
+	return int32(time.Since(a.started) / time.Second)
+}
+
+func (a *Agent) handle(packet []byte) *snmp.Message {
+	req, err := snmp.Unmarshal(packet, a.User)
+	if err != nil {
+		return nil
+	}
+	switch req.Version {
+	case snmp.Version1, snmp.Version2c:
+		if req.Community != a.Community {
+			return nil
+		}
+		return &snmp.Message{
+			Version:   req.Version,
+			Community: req.Community,
+			PDU:       a.respond(req.Version, req.PDU),
+		}
+	}
+
+	resp := &snmp.Message{
+		Version:         snmp.Version3,
+		MessageID:       req.MessageID,
+		MaxSize:         req.MaxSize,
+		EngineID:        EngineID,
+		EngineBoots:     a.boots.Load(),
+		EngineTime:      a.engineTime(),
+		UserName:        req.UserName,
+		ContextEngineID: EngineID,
+		ContextName:     req.ContextName,
+	}
+	// Reports are sent without authentication, as is done for discovery.
+	report := func(oid snmp.OID) *snmp.Message {
+		resp.UserName = ""
+		resp.PDU = snmp.PDU{
+			Type:      snmp.Report,
+			RequestID: req.PDU.RequestID,
+			Variables: []snmp.Variable{{OID: oid, Type: snmp.Counter32, Value: uint64(1)}},
+		}
+		return resp
+	}
+	if string(req.EngineID) != string(EngineID) {
+		return report(usmStatsUnknownEngineIDs)
+	}
+	if req.Flags&snmp.FlagAuth != 0 {
+		diff := req.EngineTime - resp.EngineTime
+		if req.EngineBoots != resp.EngineBoots || diff > 150 || diff < -150 {
+			return report(usmStatsNotInTimeWindows)
+		}
+	}
+	if a.User == nil || req.UserName != a.User.Name || req.Flags&(snmp.FlagAuth|snmp.FlagPriv) != a.User.Flags() {
+		return nil
+	}
+	resp.Flags = req.Flags &^ snmp.FlagReportable
+	resp.PDU = a.respond(req.Version, req.PDU)
+	return resp
+}
+
+func (a *Agent) respond(version snmp.Version, req snmp.PDU) snmp.PDU {
+	resp := snmp.PDU{Type: snmp.GetResponse, RequestID: req.RequestID}
+	switch req.Type {
+	case snmp.GetRequest:
+		for i, v := range req.Variables {
+			found, ok := a.get(v.OID)
+			if !ok {
+				if version == snmp.Version1 {
+					return noSuchName(req, i)
+				}
+				found = snmp.Variable{OID: v.OID, Type: snmp.NoSuchObject}
+			}
+			resp.Variables = append(resp.Variables, found)
+		}
+	case snmp.GetNextRequest:
+		for i, v := range req.Variables {
+			next, ok := a.next(v.OID)
+			if !ok {
+				if version == snmp.Version1 {
+					return noSuchName(req, i)
+				}
+				next = snmp.Variable{OID: v.OID, Type: snmp.EndOfMibView}
+			}
+			resp.Variables = append(resp.Variables, next)
+		}
+	case snmp.GetBulkRequest:
+		nonRepeaters := min(max(req.ErrorStatus, 0), len(req.Variables))
+		for _, v := range req.Variables[:nonRepeaters] {
+			next, ok := a.next(v.OID)
+			if !ok {
+				next = snmp.Variable{OID: v.OID, Type: snmp.EndOfMibView}
+			}
+			resp.Variables = append(resp.Variables, next)
+		}
+		repeaters := append([]snmp.Variable(nil), req.Variables[nonRepeaters:]...)
+		for r := 0; r < req.ErrorIndex && len(repeaters) > 0; r++ {
+			done := true
+			for i, v := range repeaters {
+				next, ok := a.next(v.OID)
+				if !ok {
+					next = snmp.Variable{OID: v.OID, Type: snmp.EndOfMibView}
+				} else {
+					done = false
+				}
+				repeaters[i] = next
+				resp.Variables = append(resp.Variables, next)
+			}
+			if done {
+				break
+			}
+		}
+	default:
+		resp.ErrorStatus = 5 // genErr
+	}
+	return resp
+}
+
+func noSuchName(req snmp.PDU, i int) snmp.PDU {
+	return snmp.PDU{
+		Type:        snmp.GetResponse,
+		RequestID:   req.RequestID,
+		ErrorStatus: snmp.NoSuchName,
+		ErrorIndex:  i + 1,
+		Variables:   req.Variables,
+	}
+}
+
+func (a *Agent) get(oid snmp.OID) (snmp.Variable, bool) {
+	i := sort.Search(len(a.vars), func(i int) bool {
+		return a.vars[i].OID.Compare(oid) >= 0
+	})
+	if i < len(a.vars) && a.vars[i].OID.Compare(oid) == 0 {
+		return a.vars[i], true
+	}
+	return snmp.Variable{}, false
+}
+
+func (a *Agent) next(oid snmp.OID) (snmp.Variable, bool) {
+	i := sort.Search(len(a.vars), func(i int) bool {
+		return a.vars[i].OID.Compare(oid) > 0
+	})
+	if i < len(a.vars) {
+		return a.vars[i], true
+	}
+	return snmp.Variable{}, false
+}
@@ -0,0 +1,156 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package trap
+
+import (
+	"errors"
+	"fmt"
+
+	serverhelper "github.com/elastic/beats/v7/metricbeat/helper/server"
+	"github.com/elastic/beats/v7/metricbeat/helper/server/udp"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet("snmp", "trap", New)
+}
+
+var (
+	sysUpTimeInstance   = snmp.OID{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	snmpTrapOIDInstance = snmp.OID{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}
+	snmpTraps           = snmp.OID{1, 3, 6, 1, 6, 3, 1, 1, 5}
+)
+
+// enterpriseSpecific is the generic trap number of SNMPv1 traps defined by
+// enterprises.
+const enterpriseSpecific = 6
+
+// MetricSet receives SNMP traps and informs.
+type MetricSet struct {
+	mb.BaseMetricSet
+	server serverhelper.Server
+	user   *snmp.User
+	mib    *snmp.MIB
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	config := snmp.DefaultConfig()
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+	user, err := config.User()
+	if err != nil {
+		return nil, err
+	}
+	mib, err := snmp.LoadMIB(config.MIBPaths)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := udp.NewUdpServer(base)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricSet{
+		BaseMetricSet: base,
+		server:        svc,
+		user:          user,
+		mib:           mib,
+	}, nil
+}
+
+// Host returns the address the metricset listens on.
+func (m *MetricSet) Host() string {
+	return m.server.(*udp.UdpServer).GetHost()
+}
+
+// Run receives traps until the reporter is done.
+func (m *MetricSet) Run(reporter mb.PushReporterV2) {
+	if err := m.server.Start(); err != nil {
+		reporter.Error(fmt.Errorf("could not start SNMP trap listener: %w", err))
+		return
+	}
+	defer m.server.Stop()
+
+	for {
+		select {
+		case <-reporter.Done():
+			return
+		case msg := <-m.server.GetEvents():
+			data, _ := msg.GetEvent()[serverhelper.EventDataKey].([]byte)
+			source, _ := msg.GetMeta()["client_ip"].(string)
+			event, err := m.decode(data)
+			if err != nil {
+				reporter.Error(fmt.Errorf("error decoding SNMP message from %s: %w", source, err))
+				continue
+			}
+			if source != "" {
+				event.RootFields = mapstr.M{"source": mapstr.M{"ip": source}}
+			}
+			reporter.Event(event)
+		}
+	}
+}
+
+// decode converts a trap or an inform into an event. SNMPv1 traps are
+// translated to the SNMPv2 trap OID as described in RFC 3584 section 3.1.
+func (m *MetricSet) decode(data []byte) (mb.Event, error) {
+	msg, err := snmp.Unmarshal(data, m.user)
+	if err != nil {
+		return mb.Event{}, err
+	}
+
+	fields := mapstr.M{"version": msg.Version.String()}
+	if msg.Version == snmp.Version3 {
+		fields["user"] = msg.UserName
+	}
+	var trapOID snmp.OID
+	vars := msg.PDU.Variables
+	switch msg.PDU.Type {
+	case snmp.TrapV1:
+		fields["type"] = "trap"
+		fields["uptime"] = uint64(msg.PDU.Timestamp)
+		fields["enterprise"] = msg.PDU.Enterprise.String()
+		fields["generic_trap"] = msg.PDU.GenericTrap
+		fields["specific_trap"] = msg.PDU.SpecificTrap
+		fields["agent_address"] = msg.PDU.AgentAddress.String()
+		if msg.PDU.GenericTrap == enterpriseSpecific {
+			trapOID = msg.PDU.Enterprise.Append(0, uint32(msg.PDU.SpecificTrap))
+		} else {
+			trapOID = snmpTraps.Append(uint32(msg.PDU.GenericTrap) + 1)
+		}
+	case snmp.TrapV2, snmp.InformRequest:
+		fields["type"] = "trap"
+		if msg.PDU.Type == snmp.InformRequest {
+			fields["type"] = "inform"
+		}
+		// The first variables are sysUpTime.0 and snmpTrapOID.0.
+		if len(vars) < 2 || vars[0].OID.Compare(sysUpTimeInstance) != 0 || vars[1].OID.Compare(snmpTrapOIDInstance) != 0 {
+			return mb.Event{}, errors.New("notification doesn't start with sysUpTime.0 and snmpTrapOID.0")
+		}
+		fields["uptime"] = vars[0].FieldValue()
+		oid, ok := vars[1].Value.(snmp.OID)
+		if !ok {
+			return mb.Event{}, errors.New("snmpTrapOID.0 is not an object identifier")
+		}
+		trapOID = oid
+		vars = vars[2:]
+	default:
+		return mb.Event{}, fmt.Errorf("unexpected PDU type 0x%02x", byte(msg.PDU.Type))
+	}
+	fields["oid"] = trapOID.String()
+	fields["name"] = m.mib.Name(trapOID)
+
+	if len(vars) > 0 {
+		variables := make(mapstr.M, len(vars))
+		for _, v := range vars {
+			variables[m.mib.Name(v.OID)] = v.FieldValue()
+		}
+		fields["variables"] = variables
+	}
+	return mb.Event{MetricSetFields: fields}, nil
+}
@@ -0,0 +1,185 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package trap
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var (
+	linkDown     = snmp.OID{1, 3, 6, 1, 6, 3, 1, 1, 5, 3}
+	ifIndex2     = snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 1, 2}
+	ifDescr2     = snmp.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 2}
+	netSnmpAgent = snmp.OID{1, 3, 6, 1, 4, 1, 8072, 3, 2, 10}
+)
+
+func v2Trap(pduType snmp.PDUType) snmp.PDU {
+	return snmp.PDU{
+		Type:      pduType,
+		RequestID: 1,
+		Variables: []snmp.Variable{
+			{OID: sysUpTimeInstance, Type: snmp.TimeTicks, Value: uint64(12345)},
+			{OID: snmpTrapOIDInstance, Type: snmp.ObjectIdentifier, Value: linkDown},
+			{OID: ifIndex2, Type: snmp.Integer, Value: int64(2)},
+			{OID: ifDescr2, Type: snmp.OctetString, Value: []byte("eth0")},
+		},
+	}
+}
+
+func TestDecode(t *testing.T) {
+	user, err := snmp.NewUser("beats", snmp.SHA256, "authpassword", snmp.AES, "privpassword")
+	require.NoError(t, err)
+	m := &MetricSet{user: user, mib: snmp.NewMIB()}
+
+	linkDownFields := mapstr.M{
+		"type":   "trap",
+		"oid":    "1.3.6.1.6.3.1.1.5.3",
+		"name":   "linkDown",
+		"uptime": uint64(12345),
+		"variables": mapstr.M{
+			"ifIndex.2": int64(2),
+			"ifDescr.2": "eth0",
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		msg  snmp.Message
+		want mapstr.M
+	}{
+		"v1 generic": {
+			msg: snmp.Message{Version: snmp.Version1, Community: "public", PDU: snmp.PDU{
+				Type:         snmp.TrapV1,
+				Enterprise:   netSnmpAgent,
+				AgentAddress: net.IP{192, 168, 1, 1},
+				GenericTrap:  2,
+				Timestamp:    12345,
+				Variables:    v2Trap(snmp.TrapV1).Variables[2:],
+			}},
+			want: mapstr.M{
+				"version":       "1",
+				"enterprise":    "1.3.6.1.4.1.8072.3.2.10",
+				"generic_trap":  2,
+				"specific_trap": 0,
+				"agent_address": "192.168.1.1",
+				"uptime":        uint64(12345),
+			},
+		},
+		"v1 enterprise specific": {
+			msg: snmp.Message{Version: snmp.Version1, Community: "public", PDU: snmp.PDU{
+				Type:         snmp.TrapV1,
+				Enterprise:   netSnmpAgent,
+				AgentAddress: net.IP{192, 168, 1, 1},
+				GenericTrap:  6,
+				SpecificTrap: 42,
+				Timestamp:    10,
+			}},
+			want: mapstr.M{
+				"version":       "1",
+				"type":          "trap",
+				"oid":           "1.3.6.1.4.1.8072.3.2.10.0.42",
+				"name":          "enterprises.8072.3.2.10.0.42",
+				"uptime":        uint64(10),
+				"enterprise":    "1.3.6.1.4.1.8072.3.2.10",
+				"generic_trap":  6,
+				"specific_trap": 42,
+				"agent_address": "192.168.1.1",
+			},
+		},
+		"v2c trap": {
+			msg:  snmp.Message{Version: snmp.Version2c, Community: "public", PDU: v2Trap(snmp.TrapV2)},
+			want: mapstr.M{"version": "2c"},
+		},
+		"v2c inform": {
+			msg:  snmp.Message{Version: snmp.Version2c, Community: "public", PDU: v2Trap(snmp.InformRequest)},
+			want: mapstr.M{"version": "2c", "type": "inform"},
+		},
+		"v3 trap": {
+			msg: snmp.Message{
+				Version:     snmp.Version3,
+				MessageID:   1,
+				MaxSize:     65507,
+				Flags:       snmp.FlagAuth | snmp.FlagPriv,
+				EngineID:    []byte{0x80, 0, 0, 0, 1},
+				EngineBoots: 1,
+				EngineTime:  100,
+				UserName:    "beats",
+				PDU:         v2Trap(snmp.TrapV2),
+			},
+			want: mapstr.M{"version": "3", "user": "beats"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			packet, err := tc.msg.Marshal(user)
+			require.NoError(t, err)
+			event, err := m.decode(packet)
+			require.NoError(t, err)
+
+			want := linkDownFields.Clone()
+			want.DeepUpdate(tc.want)
+			if len(tc.msg.PDU.Variables) == 0 {
+				delete(want, "variables")
+			}
+			assert.Equal(t, want, event.MetricSetFields)
+		})
+	}
+
+	// Notifications must start with sysUpTime.0 and snmpTrapOID.0.
+	pdu := v2Trap(snmp.TrapV2)
+	pdu.Variables = pdu.Variables[1:]
+	packet, err := (&snmp.Message{Version: snmp.Version2c, Community: "public", PDU: pdu}).Marshal(nil)
+	require.NoError(t, err)
+	_, err = m.decode(packet)
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	// Find a free port for the listener.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	require.NoError(t, conn.Close())
+
+	ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+		"module":     "snmp",
+		"metricsets": []string{"trap"},
+		"host":       "127.0.0.1",
+		"port":       port,
+	})
+
+	packet, err := (&snmp.Message{Version: snmp.Version2c, Community: "public", PDU: v2Trap(snmp.TrapV2)}).Marshal(nil)
+	require.NoError(t, err)
+
+	// Traps are sent until one is received, as the listener may not be ready
+	// for the first ones.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		sender, err := net.Dial("udp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			return
+		}
+		defer sender.Close()
+		for ctx.Err() == nil {
+			_, _ = sender.Write(packet)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	events := mbtest.RunPushMetricSetV2(10*time.Second, 1, ms)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "linkDown", events[0].MetricSetFields["name"])
+	assert.Equal(t, mapstr.M{"source": mapstr.M{"ip": "127.0.0.1"}}, events[0].RootFields)
+}
@@ -0,0 +1,303 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec // DES is one of the privacy protocols of the SNMPv3 User-based Security Model.
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// AuthProtocol is an authentication protocol of the User-based Security
+// Model (RFC 3414 and RFC 7860).
+type AuthProtocol string
+
+// Authentication protocols.
+const (
+	NoAuth AuthProtocol = ""
+	MD5    AuthProtocol = "MD5"
+	SHA    AuthProtocol = "SHA"
+	SHA224 AuthProtocol = "SHA224"
+	SHA256 AuthProtocol = "SHA256"
+	SHA384 AuthProtocol = "SHA384"
+	SHA512 AuthProtocol = "SHA512"
+)
+
+// PrivProtocol is a privacy protocol of the User-based Security Model
+// (RFC 3414 and RFC 3826).
+type PrivProtocol string
+
+// Privacy protocols.
+const (
+	NoPriv PrivProtocol = ""
+	DES    PrivProtocol = "DES"
+	AES    PrivProtocol = "AES"
+)
+
+var (
+	// ErrUnknownUser is returned when a message is received for a user that
+	// is not configured.
+	ErrUnknownUser = errors.New("unknown SNMPv3 user")
+	// ErrAuthentication is returned when the digest of a message doesn't
+	// match.
+	ErrAuthentication = errors.New("SNMPv3 authentication failure")
+)
+
+// hash returns the hash function of the protocol and the length of the
+// truncated message authentication codes.
+func (p AuthProtocol) hash() (func() hash.Hash, int) {
+	switch p {
+	case MD5:
+		return md5.New, 12
+	case SHA:
+		return sha1.New, 12
+	case SHA224:
+		return sha256.New224, 16
+	case SHA256:
+		return sha256.New, 24
+	case SHA384:
+		return sha512.New384, 32
+	case SHA512:
+		return sha512.New, 48
+	}
+	return nil, 0
+}
+
+// User holds the credentials of an SNMPv3 user. The keys derived from the
+// passwords are localized and cached for every engine the user talks to.
+type User struct {
+	Name         string
+	AuthProtocol AuthProtocol
+	PrivProtocol PrivProtocol
+
+	authKey []byte
+	privKey []byte
+
+	mu        sync.Mutex
+	localized map[string]userKeys
+
+	salt atomic.Uint64
+}
+
+type userKeys struct {
+	auth, priv []byte
+}
+
+// NewUser returns a user with the given credentials. Protocols are case
+// insensitive. Privacy requires authentication.
+func NewUser(name string, auth AuthProtocol, authPassword string, priv PrivProtocol, privPassword string) (*User, error) {
+	u := &User{
+		Name:         name,
+		AuthProtocol: AuthProtocol(strings.ToUpper(string(auth))),
+		PrivProtocol: PrivProtocol(strings.ToUpper(string(priv))),
+		localized:    map[string]userKeys{},
+	}
+	if u.AuthProtocol != NoAuth {
+		h, _ := u.AuthProtocol.hash()
+		if h == nil {
+			return nil, fmt.Errorf("unsupported authentication protocol %q", auth)
+		}
+		if len(authPassword) < 8 {
+			return nil, errors.New("the authentication password must have at least 8 characters")
+		}
+		u.authKey = passwordToKey(h, authPassword)
+	}
+	switch u.PrivProtocol {
+	case NoPriv:
+	case DES, AES:
+		if u.AuthProtocol == NoAuth {
+			return nil, errors.New("privacy requires an authentication protocol")
+		}
+		if len(privPassword) < 8 {
+			return nil, errors.New("the privacy password must have at least 8 characters")
+		}
+		h, _ := u.AuthProtocol.hash()
+		u.privKey = passwordToKey(h, privPassword)
+	default:
+		return nil, fmt.Errorf("unsupported privacy protocol %q", priv)
+	}
+
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("error initializing privacy salt: %w", err)
+	}
+	u.salt.Store(binary.BigEndian.Uint64(seed[:]))
+	return u, nil
+}
+
+// Flags returns the message flags of the security level of the user.
+func (u *User) Flags() byte {
+	var flags byte
+	if u.AuthProtocol != NoAuth {
+		flags |= FlagAuth
+	}
+	if u.PrivProtocol != NoPriv {
+		flags |= FlagPriv
+	}
+	return flags
+}
+
+// passwordToKey converts a password into a key as described in RFC 3414
+// appendix A.2.
+func passwordToKey(h func() hash.Hash, password string) []byte {
+	const expandedLength = 1 << 20
+	hh := h()
+	buf := make([]byte, 64)
+	for count := 0; count < expandedLength; count += len(buf) {
+		for i := range buf {
+			buf[i] = password[(count+i)%len(password)]
+		}
+		hh.Write(buf)
+	}
+	return hh.Sum(nil)
+}
+
+// keys returns the keys of the user localized for an engine.
+func (u *User) keys(engineID []byte) userKeys {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if k, found := u.localized[string(engineID)]; found {
+		return k
+	}
+	var k userKeys
+	if h, _ := u.AuthProtocol.hash(); h != nil {
+		k.auth = localizeKey(h, u.authKey, engineID)
+		if u.privKey != nil {
+			k.priv = localizeKey(h, u.privKey, engineID)
+		}
+	}
+	u.localized[string(engineID)] = k
+	return k
+}
+
+func localizeKey(h func() hash.Hash, key, engineID []byte) []byte {
+	hh := h()
+	hh.Write(key)
+	hh.Write(engineID)
+	hh.Write(key)
+	return hh.Sum(nil)
+}
+
+// digest returns the truncated message authentication code of a message.
+func (u *User) digest(engineID, msg []byte) []byte {
+	h, n := u.AuthProtocol.hash()
+	mac := hmac.New(h, u.keys(engineID).auth)
+	mac.Write(msg)
+	return mac.Sum(nil)[:n]
+}
+
+func (u *User) digestLen() int {
+	_, n := u.AuthProtocol.hash()
+	return n
+}
+
+// encrypt encrypts a scoped PDU. It returns the encrypted data and the
+// privacy parameters of the message.
+func (u *User) encrypt(engineID []byte, boots, engineTime int32, plaintext []byte) (ciphertext, params []byte, err error) {
+	key := u.keys(engineID).priv
+	salt := u.salt.Add(1)
+	params = make([]byte, 8)
+	switch u.PrivProtocol {
+	case DES:
+		// The salt is made of the engine boots and a local counter.
+		binary.BigEndian.PutUint32(params, uint32(boots))
+		binary.BigEndian.PutUint32(params[4:], uint32(salt))
+		block, err := des.NewCipher(key[:8]) //nolint:gosec // See import.
+		if err != nil {
+			return nil, nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ params[i]
+		}
+		padded := make([]byte, (len(plaintext)+des.BlockSize-1)/des.BlockSize*des.BlockSize)
+		copy(padded, plaintext)
+		ciphertext = make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+	case AES:
+		binary.BigEndian.PutUint64(params, salt)
+		ciphertext, err = aesCFB(key, aesIV(boots, engineTime, params), plaintext, true)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, errors.New("no privacy protocol")
+	}
+	return ciphertext, params, nil
+}
+
+// decrypt decrypts a scoped PDU.
+func (u *User) decrypt(engineID []byte, boots, engineTime int32, params, ciphertext []byte) ([]byte, error) {
+	if len(params) != 8 {
+		return nil, fmt.Errorf("invalid privacy parameters length %d", len(params))
+	}
+	key := u.keys(engineID).priv
+	switch u.PrivProtocol {
+	case DES:
+		if len(ciphertext)%des.BlockSize != 0 {
+			return nil, errors.New("encrypted data is not a multiple of the DES block size")
+		}
+		block, err := des.NewCipher(key[:8]) //nolint:gosec // See import.
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ params[i]
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		return plaintext, nil
+	case AES:
+		return aesCFB(key, aesIV(boots, engineTime, params), ciphertext, false)
+	}
+	return nil, errors.New("no privacy protocol")
+}
+
+func aesIV(boots, engineTime int32, salt []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv, uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:], uint32(engineTime))
+	copy(iv[8:], salt)
+	return iv
+}
+
+// aesCFB encrypts or decrypts data with AES-128 in 128-bit cipher feedback
+// mode, as required by RFC 3826.
+func aesCFB(key, iv, in []byte, encrypt bool) ([]byte, error) {
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	feedback := append([]byte(nil), iv...)
+	stream := make([]byte, aes.BlockSize)
+	for off := 0; off < len(in); off += aes.BlockSize {
+		block.Encrypt(stream, feedback)
+		end := min(off+aes.BlockSize, len(in))
+		for i := off; i < end; i++ {
+			out[i] = in[i] ^ stream[i-off]
+		}
+		if encrypt {
+			copy(feedback, out[off:end])
+		} else {
+			copy(feedback, in[off:end])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of RFC 3414 appendix A.3.
+func TestLocalizeKey(t *testing.T) {
+	engineID, _ := hex.DecodeString("000000000000000000000002")
+
+	for protocol, want := range map[AuthProtocol]string{
+		MD5: "526f5eed9fcce26f8964c2930787d82b",
+		SHA: "6695febc9288e36282235fc7151f128497b38f3f",
+	} {
+		user, err := NewUser("user", protocol, "maplesyrup", NoPriv, "")
+		require.NoError(t, err)
+		assert.Equal(t, want, hex.EncodeToString(user.keys(engineID).auth), protocol)
+	}
+}
+
+func TestNewUser(t *testing.T) {
+	_, err := NewUser("user", "sha256", "password", "aes", "password")
+	require.NoError(t, err)
+
+	_, err = NewUser("user", "sha3", "password", NoPriv, "")
+	require.Error(t, err)
+	_, err = NewUser("user", SHA, "short", NoPriv, "")
+	require.Error(t, err)
+	_, err = NewUser("user", NoAuth, "", AES, "password")
+	require.Error(t, err)
+	_, err = NewUser("user", SHA, "password", "3DES", "password")
+	require.Error(t, err)
+}
+
+func TestMessageV3(t *testing.T) {
+	engineID := []byte{0x80, 0, 0, 0, 1}
+	pdu := PDU{
+		Type:      GetResponse,
+		RequestID: 42,
+		Variables: []Variable{
+			{OID: OID{1, 3, 6, 1, 2, 1, 1, 1, 0}, Type: OctetString, Value: []byte("router")},
+			{OID: OID{1, 3, 6, 1, 2, 1, 1, 3, 0}, Type: TimeTicks, Value: uint64(123456)},
+		},
+	}
+
+	for _, tc := range []struct {
+		auth AuthProtocol
+		priv PrivProtocol
+	}{
+		{MD5, NoPriv},
+		{SHA, DES},
+		{SHA256, AES},
+		{SHA512, AES},
+	} {
+		t.Run(string(tc.auth)+string(tc.priv), func(t *testing.T) {
+			user, err := NewUser("user", tc.auth, "authpassword", tc.priv, "privpassword")
+			require.NoError(t, err)
+
+			m := &Message{
+				Version:         Version3,
+				MessageID:       7,
+				MaxSize:         maxMessageSize,
+				Flags:           user.Flags(),
+				EngineID:        engineID,
+				EngineBoots:     3,
+				EngineTime:      1000,
+				UserName:        "user",
+				ContextEngineID: engineID,
+				PDU:             pdu,
+			}
+			packet, err := m.Marshal(user)
+			require.NoError(t, err)
+
+			decoded, err := Unmarshal(packet, user)
+			require.NoError(t, err)
+			assert.Equal(t, m.PDU, decoded.PDU)
+			assert.Equal(t, m.EngineID, decoded.EngineID)
+			assert.Equal(t, m.ContextEngineID, decoded.ContextEngineID)
+			assert.Equal(t, int32(3), decoded.EngineBoots)
+
+			other, err := NewUser("user", tc.auth, "otherpassword", tc.priv, "privpassword")
+			require.NoError(t, err)
+			_, err = Unmarshal(packet, other)
+			require.ErrorIs(t, err, ErrAuthentication)
+
+			packet[len(packet)-1] ^= 0xff
+			_, err = Unmarshal(packet, user)
+			require.ErrorIs(t, err, ErrAuthentication)
+		})
+	}
+}
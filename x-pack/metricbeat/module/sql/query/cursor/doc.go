@@ -59,7 +59,7 @@
 // The placeholder translation layer supports:
 //
 //   - PostgreSQL / CockroachDB: $1
-//   - MySQL: ?
+//   - MySQL, SQLite: ?
 //   - Oracle (godror): :cursor_val
 //   - MSSQL (sqlserver): @p1
 //
@@ -206,6 +206,11 @@
 //     Preflight: the host running Metricbeat must have Oracle Instant Client
 //     installed and libclntsh discoverable (macOS: DYLD_LIBRARY_PATH,
 //     Linux: LD_LIBRARY_PATH), otherwise startup/fetch will fail with DPI-1047.
+//   - SQLite: use driver "sqlite" with the path of the database file as host.
+//     Timestamp cursors are bound as text in the format used by the driver to
+//     store time.Time values (2006-01-02 15:04:05.999999999-07:00); columns
+//     written in another format should be compared with julianday() on both
+//     sides to compare instants instead of strings.
 //   - Decimal: ToDriverArg returns string. Most drivers implicitly cast to
 //     DECIMAL, but if not, the user can add CAST(:cursor AS DECIMAL) in SQL.
 //
@@ -228,6 +233,9 @@
 // cases like overflow, NULL, timezone conversion), placeholder translation,
 // state persistence, and the full manager lifecycle.
 //
+// The query MetricSet is also tested end-to-end against an embedded SQLite
+// database, including incremental fetches across restarts.
+//
 // Integration tests verify end-to-end cursor operation against PostgreSQL,
 // MySQL, Oracle, and MSSQL with all five cursor types (integer, timestamp,
 // date, float, decimal), both scan directions, compound WHERE clauses,
@@ -34,7 +34,7 @@ func ValidateQueryHasCursor(query string) error {
 //
 // Driver placeholder mapping:
 //   - PostgreSQL, CockroachDB: $1
-//   - MySQL: ?
+//   - MySQL, SQLite: ?
 //   - Oracle: :cursor_val
 //   - MSSQL: @p1
 func TranslateQuery(query, driver string) string {
@@ -65,7 +65,7 @@ func getDriverPlaceholder(driver string) string {
 	switch strings.ToLower(driver) {
 	case "postgres", "postgresql", "cockroachdb", "cockroach":
 		return "$1"
-	case "mysql":
+	case "mysql", "sqlite", "sqlite3":
 		return "?"
 	case "oracle", "godror":
 		return ":cursor_val"
@@ -141,6 +141,16 @@ func TestTranslateQuery(t *testing.T) {
 			driver: "mysql",
 			want:   "SELECT * FROM logs WHERE id > ? ORDER BY id ASC LIMIT 1000",
 		},
+		{
+			name:   "sqlite",
+			driver: "sqlite",
+			want:   "SELECT * FROM logs WHERE id > ? ORDER BY id ASC LIMIT 1000",
+		},
+		{
+			name:   "sqlite3",
+			driver: "sqlite3",
+			want:   "SELECT * FROM logs WHERE id > ? ORDER BY id ASC LIMIT 1000",
+		},
 		{
 			name:   "oracle",
 			driver: "oracle",
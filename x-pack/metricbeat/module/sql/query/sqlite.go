@@ -0,0 +1,13 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !requirefips
+
+package query
+
+import (
+	// Registers the sqlite3 driver, used with driver "sqlite" to query
+	// embedded databases from their file path.
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,144 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build cgo && !requirefips
+
+package query
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+// newSQLiteDB creates an embedded SQLite database with an events table.
+func newSQLiteDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "events.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE events (
+		id INTEGER PRIMARY KEY,
+		message TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	require.NoError(t, err)
+
+	return db, dbPath
+}
+
+func insertSQLiteEvents(t *testing.T, db *sql.DB, start time.Time, ids ...int) {
+	t.Helper()
+
+	for _, id := range ids {
+		_, err := db.Exec("INSERT INTO events (id, message, created_at) VALUES (?, ?, ?)",
+			id, "login", start.Add(time.Duration(id)*time.Minute))
+		require.NoError(t, err)
+	}
+}
+
+// newSQLiteCursorMetricSet creates a query MetricSet with a cursor on the
+// column, storing the cursor state under dataPath.
+func newSQLiteCursorMetricSet(t *testing.T, dataPath, dbPath, query, column, cursorType, cursorDefault string) *MetricSet {
+	t.Helper()
+
+	origData := paths.Paths.Data
+	paths.Paths.Data = dataPath
+	t.Cleanup(func() { paths.Paths.Data = origData })
+
+	c, err := conf.NewConfigFrom(map[string]interface{}{
+		"module":              "sql",
+		"metricsets":          []string{"query"},
+		"hosts":               []string{dbPath},
+		"driver":              "sqlite",
+		"sql_query":           query,
+		"sql_response_format": "table",
+		"cursor": map[string]interface{}{
+			"enabled": true,
+			"column":  column,
+			"type":    cursorType,
+			"default": cursorDefault,
+		},
+	})
+	require.NoError(t, err)
+
+	_, metricsets, err := mb.NewModule(c, mb.Registry, beat.Info{Paths: paths.New(), Logger: logptest.NewTestingLogger(t, "")})
+	require.NoError(t, err)
+	require.Len(t, metricsets, 1)
+
+	ms, ok := metricsets[0].(*MetricSet)
+	require.Truef(t, ok, "expected *MetricSet, got %T", metricsets[0])
+	return ms
+}
+
+// fetchSQLiteIDs fetches once and returns the ids of the reported rows.
+func fetchSQLiteIDs(t *testing.T, ms *MetricSet) []int64 {
+	t.Helper()
+
+	reporter := &mbtest.CapturingReporterV2{}
+	require.NoError(t, ms.Fetch(context.Background(), reporter))
+	require.Empty(t, reporter.GetErrors())
+
+	var ids []int64
+	for _, event := range reporter.GetEvents() {
+		id, err := event.ModuleFields.GetValue("metrics.numeric.id")
+		require.NoError(t, err)
+		ids = append(ids, id.(int64))
+	}
+	return ids
+}
+
+func TestSQLiteCursorIncrementalFetch(t *testing.T) {
+	db, dbPath := newSQLiteDB(t)
+	dataPath := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := "SELECT id, message FROM events WHERE id > :cursor ORDER BY id ASC LIMIT 2"
+
+	insertSQLiteEvents(t, db, start, 1, 2, 3)
+
+	ms := newSQLiteCursorMetricSet(t, dataPath, dbPath, query, "id", "integer", "0")
+	assert.Equal(t, []int64{1, 2}, fetchSQLiteIDs(t, ms))
+	assert.Equal(t, []int64{3}, fetchSQLiteIDs(t, ms))
+	assert.Empty(t, fetchSQLiteIDs(t, ms), "no rows should be sent again")
+
+	insertSQLiteEvents(t, db, start, 4, 5)
+	require.NoError(t, ms.Close())
+
+	// The cursor is persisted, so a restarted MetricSet only fetches new rows.
+	ms = newSQLiteCursorMetricSet(t, dataPath, dbPath, query, "id", "integer", "0")
+	t.Cleanup(func() { _ = ms.Close() })
+	assert.Equal(t, []int64{4, 5}, fetchSQLiteIDs(t, ms))
+	assert.Equal(t, "5", ms.cursorManager.CursorValueString())
+}
+
+func TestSQLiteCursorTimestamp(t *testing.T) {
+	db, dbPath := newSQLiteDB(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := "SELECT id, created_at FROM events WHERE created_at > :cursor ORDER BY created_at ASC"
+
+	insertSQLiteEvents(t, db, start, 1, 2)
+
+	ms := newSQLiteCursorMetricSet(t, t.TempDir(), dbPath, query, "created_at", "timestamp", "2024-01-01T00:00:00Z")
+	t.Cleanup(func() { _ = ms.Close() })
+	assert.Equal(t, []int64{1, 2}, fetchSQLiteIDs(t, ms))
+
+	insertSQLiteEvents(t, db, start, 3)
+	assert.Equal(t, []int64{3}, fetchSQLiteIDs(t, ms))
+	assert.Empty(t, fetchSQLiteIDs(t, ms))
+}
@@ -42,12 +42,21 @@ func splitTags(rawTags, kvSep []byte, log *logp.Logger) map[string]string {
 	}
 
 	for _, kv := range tagSplit {
+		if len(kv) == 0 {
+			continue
+		}
 		kvSplit := bytes.SplitN(kv, kvSep, 2)
-		if len(kvSplit) != 2 {
+		if len(kvSplit[0]) == 0 {
 			log.Named("statd").Warn("could not parse tags")
 			continue
 		}
-		tags[string(kvSplit[0])] = string(kvSplit[1])
+		// Tags without value, like DogStatsD `#production`, are kept with
+		// an empty value.
+		var value string
+		if len(kvSplit) == 2 {
+			value = string(kvSplit[1])
+		}
+		tags[string(kvSplit[0])] = value
 	}
 	return tags
 }
@@ -56,25 +65,27 @@ func parseSingle(b []byte, log *logp.Logger) (statsdMetric, error) {
 	// format: <metric name>:<value>|<type>[|@samplerate][|#<k>:<v>,<k>:<v>]
 	// alternative: <metric name>[,<k>=<v>,<k>=<v>]:<value>|<type>[|@samplerate]
 	// alternative: <metric name>[;<k>=<v>;<k>=<v>]:<value>|<type>[|@samplerate]
+	//
+	// Sections after the type can be in any order. Other DogStatsD sections,
+	// like container IDs (|c:<id>) and timestamps (|T<timestamp>), are ignored.
 	s := statsdMetric{}
 
-	parts := bytes.SplitN(b, []byte("|"), 4)
+	parts := bytes.Split(b, []byte("|"))
 	if len(parts) < 2 {
 		return s, errInvalidPacket
 	}
 
-	if len(parts) > 2 && len(parts[2]) > 0 && parts[2][0] == '@' {
-		s.sampleRate = string(parts[2][1:])
-
-		if len(parts) > 3 {
-			parts = [][]byte{parts[0], parts[1], parts[3]}
-		} else {
-			parts = [][]byte{parts[0], parts[1]}
+	var dogStatsDTags map[string]string
+	for _, part := range parts[2:] {
+		if len(part) == 0 {
+			continue
+		}
+		switch part[0] {
+		case '@':
+			s.sampleRate = string(part[1:])
+		case '#':
+			dogStatsDTags = splitTags(part[1:], []byte(":"), log)
 		}
-	}
-
-	if len(parts) > 2 && len(parts[2]) > 0 && parts[2][0] == '#' {
-		s.tags = splitTags(parts[2][1:], []byte(":"), log)
 	}
 
 	nameSplit := bytes.SplitN(parts[0], []byte{':'}, 2)
@@ -95,6 +106,13 @@ func parseSingle(b []byte, log *logp.Logger) (statsdMetric, error) {
 	if len(nameTagsSplit) > 1 {
 		s.tags = splitTags(nameTagsSplit[1], []byte("="), log)
 	}
+	if s.tags == nil {
+		s.tags = dogStatsDTags
+	} else {
+		for k, v := range dogStatsDTags {
+			s.tags[k] = v
+		}
+	}
 
 	s.value = string(nameSplit[1])
 	s.metricType = string(parts[1])
@@ -107,6 +125,10 @@ func parse(b []byte, log *logp.Logger) ([]statsdMetric, error) {
 	rawMetrics := bytes.Split(b, []byte("\n"))
 	metrics := make([]statsdMetric, 0, len(rawMetrics))
 	for i := range rawMetrics {
+		if isDogStatsDEventOrServiceCheck(rawMetrics[i]) {
+			log.Named("statd").Debug("DogStatsD events and service checks are not supported, skipped")
+			continue
+		}
 		if len(rawMetrics[i]) > 0 {
 			metric, err := parseSingle(rawMetrics[i], log)
 			if err != nil {
@@ -119,6 +141,13 @@ func parse(b []byte, log *logp.Logger) ([]statsdMetric, error) {
 	return metrics, nil
 }
 
+// isDogStatsDEventOrServiceCheck returns true for DogStatsD events, with the
+// format `_e{<title length>,<text length>}:<title>|<text>|...`, and service
+// checks, with the format `_sc|<name>|<status>|...`.
+func isDogStatsDEventOrServiceCheck(b []byte) bool {
+	return bytes.HasPrefix(b, []byte("_e{")) || bytes.HasPrefix(b, []byte("_sc|"))
+}
+
 func eventMapping(metricName string, metricValue interface{}, mappings map[string]StatsdMapping, log *logp.Logger) mapstr.M {
 	m := mapstr.M{}
 	if len(mappings) == 0 {
@@ -161,9 +190,9 @@ func eventMapping(metricName string, metricValue interface{}, mappings map[strin
 	return m
 }
 
-func newMetricProcessor(ttl time.Duration, log *logp.Logger) *metricProcessor {
+func newMetricProcessor(ttl time.Duration, percentiles []float64, log *logp.Logger) *metricProcessor {
 	return &metricProcessor{
-		registry: &registry{metrics: map[string]map[string]*metric{}, ttl: ttl, logger: log.Named("statd")},
+		registry: &registry{metrics: map[string]map[string]*metric{}, ttl: ttl, logger: log.Named("statd"), percentiles: percentiles},
 	}
 }
 
@@ -172,7 +201,8 @@ func (p *metricProcessor) processSingle(m statsdMetric) error {
 		return nil
 	}
 
-	// parse sample rate. Only applicable for timers and counters
+	// parse sample rate. Only applicable for counters, timers, histograms and
+	// distributions, it is validated but ignored for gauges and sets.
 	var sampleRate float64
 	if m.sampleRate == "" {
 		sampleRate = 1.0
@@ -219,14 +249,21 @@ func (p *metricProcessor) processSingle(m statsdMetric) error {
 		if err != nil {
 			return fmt.Errorf("failed to process timer `%s` with value `%s`: %w", m.name, m.value, err)
 		}
-		c.SampledUpdate(time.Duration(v), sampleRate)
-	case "h": // TODO: can these be floats?
+		c.SampledUpdate(v, sampleRate)
+	case "h":
 		c := p.registry.GetOrNewHistogram(m.name, m.tags)
-		v, err := strconv.ParseInt(m.value, 10, 64)
+		v, err := strconv.ParseFloat(m.value, 64)
 		if err != nil {
 			return fmt.Errorf("failed to process histogram `%s` with value `%s`: %w", m.name, m.value, err)
 		}
-		c.Update(v)
+		c.SampledUpdate(v, sampleRate)
+	case "d":
+		c := p.registry.GetOrNewDistribution(m.name, m.tags)
+		v, err := strconv.ParseFloat(m.value, 64)
+		if err != nil {
+			return fmt.Errorf("failed to process distribution `%s` with value `%s`: %w", m.name, m.value, err)
+		}
+		c.SampledUpdate(v, sampleRate)
 	case "s":
 		c := p.registry.GetOrNewSet(m.name, m.tags)
 		c.Add(m.value)
@@ -5,7 +5,10 @@
 package server
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -14,12 +17,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/elastic/beats/v7/libbeat/beat"
 	"github.com/elastic/beats/v7/metricbeat/helper/server"
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/logp/logptest"
 	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/paths"
 )
 
 func init() {
@@ -1053,7 +1059,7 @@ func TestParseMetrics(t *testing.T) {
 		assert.Equal(t, test.err, err, test.input)
 		assert.Equal(t, test.expected, actual, test.input)
 
-		processor := newMetricProcessor(time.Second, logger)
+		processor := newMetricProcessor(time.Second, nil, logger)
 		for _, e := range actual {
 			err := processor.processSingle(e)
 
@@ -1404,6 +1410,232 @@ func TestChangeType(t *testing.T) {
 	}, events[0].MetricSetFields)
 }
 
+func TestParseDogStatsD(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected []statsdMetric
+	}{
+		{
+			// Sections after the type can be in any order.
+			input: "metric1:1|c|#k1:v1,k2:v2|@0.5",
+			expected: []statsdMetric{{
+				name:       "metric1",
+				metricType: "c",
+				value:      "1",
+				sampleRate: "0.5",
+				tags:       map[string]string{"k1": "v1", "k2": "v2"},
+			}},
+		},
+		{
+			// Container IDs and timestamps are ignored.
+			input: "metric2:2.5|d|@0.1|#env:prod,url:http://example.com|c:83c0a99c0a54c0c187f461c7980e9b57f3f6a8b0c918c8d93df19a9de6f3fe1d|T1656581400",
+			expected: []statsdMetric{{
+				name:       "metric2",
+				metricType: "d",
+				value:      "2.5",
+				sampleRate: "0.1",
+				tags:       map[string]string{"env": "prod", "url": "http://example.com"},
+			}},
+		},
+		{
+			// Tags without value are kept.
+			input: "metric3:3|g|#production,region:eu",
+			expected: []statsdMetric{{
+				name:       "metric3",
+				metricType: "g",
+				value:      "3",
+				tags:       map[string]string{"production": "", "region": "eu"},
+			}},
+		},
+		{
+			// DogStatsD tags are merged with tags in the name.
+			input: "metric4,k1=v1:4|h|#k2:v2",
+			expected: []statsdMetric{{
+				name:       "metric4",
+				metricType: "h",
+				value:      "4",
+				tags:       map[string]string{"k1": "v1", "k2": "v2"},
+			}},
+		},
+		{
+			// Events and service checks are skipped.
+			input: "_e{5,4}:title|text|#k1:v1\n_sc|check|0|#k1:v1\nmetric5:5|c",
+			expected: []statsdMetric{{
+				name:       "metric5",
+				metricType: "c",
+				value:      "5",
+			}},
+		},
+	} {
+		actual, err := parse([]byte(test.input), logptest.NewTestingLogger(t, ""))
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, actual, test.input)
+	}
+}
+
+func TestHistogramSampled(t *testing.T) {
+	ms := mbtest.NewMetricSet(t, map[string]interface{}{"module": "statsd"}).(*MetricSet)
+	testData := []string{
+		"metric01:2.5|h|@0.1",
+		"metric01:3|h",
+	}
+	err := process(testData, ms)
+	require.NoError(t, err)
+
+	events := ms.getEvents()
+	require.Len(t, events, 1)
+
+	actualMetric01 := events[0].MetricSetFields["metric01"].(map[string]interface{})
+	assert.Equal(t, int64(11), actualMetric01["count"])
+	assert.Equal(t, int64(3), actualMetric01["max"])
+}
+
+func TestDistribution(t *testing.T) {
+	ms := mbtest.NewMetricSet(t, map[string]interface{}{
+		"module":      "statsd",
+		"percentiles": []float64{50, 99.9},
+	}).(*MetricSet)
+	testData := []string{
+		"metric01:1|d|#k1:v1",
+		"metric01:2|d|#k1:v1",
+		"metric01:3.5|d|@0.5|#k1:v1",
+	}
+	err := process(testData, ms)
+	require.NoError(t, err)
+
+	events := ms.getEvents()
+	require.Len(t, events, 1)
+
+	actualMetric01 := events[0].MetricSetFields["metric01"].(map[string]interface{})
+	assert.Equal(t, int64(4), actualMetric01["count"])
+	assert.Equal(t, 1.0, actualMetric01["min"])
+	assert.Equal(t, 3.5, actualMetric01["max"])
+	assert.Equal(t, 10.0, actualMetric01["sum"])
+	assert.Equal(t, 2.5, actualMetric01["mean"])
+	assert.InEpsilon(t, 2, actualMetric01["p50"], sketchRelativeAccuracy)
+	assert.InEpsilon(t, 3.5, actualMetric01["p99_9"], sketchRelativeAccuracy)
+
+	// Distributions are reset on every report.
+	events = ms.getEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, mapstr.M{
+		"metric01": map[string]interface{}{"count": int64(0)},
+	}, events[0].MetricSetFields)
+}
+
+func TestPercentiles(t *testing.T) {
+	ms := mbtest.NewMetricSet(t, map[string]interface{}{
+		"module":      "statsd",
+		"percentiles": []float64{50, 90},
+	}).(*MetricSet)
+	var testData []string
+	for i := 1; i <= 100; i++ {
+		testData = append(testData, fmt.Sprintf("timer:%d|ms", i), fmt.Sprintf("histogram:%d|h", i*10))
+	}
+	err := process(testData, ms)
+	require.NoError(t, err)
+
+	metrics := func() mapstr.M {
+		fields := mapstr.M{}
+		for _, e := range ms.getEvents() {
+			for k, v := range e.MetricSetFields {
+				fields[k] = v
+			}
+		}
+		return fields
+	}
+
+	fields := metrics()
+	timer := fields["timer"].(map[string]interface{})
+	assert.InEpsilon(t, 50.5, timer["p50"], 2*sketchRelativeAccuracy)
+	assert.InEpsilon(t, 90.1, timer["p90"], 2*sketchRelativeAccuracy)
+	histogram := fields["histogram"].(map[string]interface{})
+	assert.InEpsilon(t, 505, histogram["p50"], 2*sketchRelativeAccuracy)
+	assert.InEpsilon(t, 901, histogram["p90"], 2*sketchRelativeAccuracy)
+
+	// Percentiles are calculated from the values of the flush interval.
+	err = process([]string{"timer:1000|ms"}, ms)
+	require.NoError(t, err)
+
+	fields = metrics()
+	timer = fields["timer"].(map[string]interface{})
+	assert.Equal(t, 1000.0, timer["p50"])
+	histogram = fields["histogram"].(map[string]interface{})
+	assert.NotContains(t, histogram, "p50")
+}
+
+func TestConfigValidate(t *testing.T) {
+	for name, test := range map[string]struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		"protocol":             {map[string]interface{}{"protocol": "http"}, "`protocol` can only be udp or tcp"},
+		"percentile too large": {map[string]interface{}{"percentiles": []float64{50, 100}}, "invalid percentile 100"},
+		"negative percentile":  {map[string]interface{}{"percentiles": []float64{-1}}, "invalid percentile -1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]interface{}{"module": "statsd"}
+			for k, v := range test.config {
+				config[k] = v
+			}
+			cfg, err := conf.NewConfigFrom(config)
+			require.NoError(t, err)
+
+			_, _, err = mb.NewModule(cfg, mb.Registry, beat.Info{Paths: paths.New(), Logger: logptest.NewTestingLogger(t, "")})
+			assert.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func TestRunTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	ms := mbtest.NewPushMetricSetV2(t, map[string]interface{}{
+		"module":   "statsd",
+		"host":     "127.0.0.1",
+		"port":     port,
+		"protocol": "tcp",
+		"period":   "100ms",
+	})
+
+	// Metrics are sent until the server accepts the connection, as it may not
+	// be listening yet.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				_, err = conn.Write([]byte("metric01:1|c|#k1:v1\nmetric02:2|g|#k1:v1\n"))
+				conn.Close()
+				if err == nil {
+					return
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	events := mbtest.RunPushMetricSetV2(10*time.Second, 2, ms)
+	require.Len(t, events, 2)
+	fields := mapstr.M{}
+	for _, e := range events {
+		assert.Equal(t, mapstr.M{"labels": mapstr.M{"k1": "v1"}}, e.RootFields)
+		for k, v := range e.MetricSetFields {
+			fields[k] = v
+		}
+	}
+	assert.Equal(t, mapstr.M{
+		"metric01": map[string]interface{}{"count": int64(1)},
+		"metric02": map[string]interface{}{"value": 2.0},
+	}, fields)
+}
+
 func BenchmarkIngest(b *testing.B) {
 	tests := []string{
 		"metric01:1.0|g|#k1:v1,k2:v2",
@@ -5,6 +5,9 @@
 package server
 
 import (
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rcrowley/go-metrics"
@@ -26,6 +29,9 @@ type registry struct {
 	ttl        time.Duration
 	lastReport time.Time
 	logger     *logp.Logger
+	// percentiles reported for timers, histograms and distributions, computed
+	// from the values received since the previous report.
+	percentiles []float64
 }
 
 type setMetric struct {
@@ -71,10 +77,13 @@ type samplingTimer struct {
 	metrics.Timer
 	meter     metrics.Meter
 	histogram metrics.Histogram
+	// sketch of the values since the previous report, nil if no percentiles
+	// are configured.
+	sketch *ddSketch
 }
 
 // NewSamplingTimer returns a new SamplingTimer
-func newSamplingTimer() *samplingTimer {
+func newSamplingTimer(sketch *ddSketch) *samplingTimer {
 	m := metrics.NewMeter()
 	h := metrics.NewHistogram(metrics.NewExpDecaySample(1028, 0.015))
 
@@ -82,13 +91,54 @@ func newSamplingTimer() *samplingTimer {
 		Timer:     metrics.NewCustomTimer(h, m),
 		meter:     m,
 		histogram: h,
+		sketch:    sketch,
 	}
 }
 
 // SampledUpdate will update the timer a sampled measurement
-func (s *samplingTimer) SampledUpdate(d time.Duration, sampleRate float64) {
-	s.histogram.Update(int64(d))
+func (s *samplingTimer) SampledUpdate(v float64, sampleRate float64) {
+	s.histogram.Update(int64(v))
 	s.meter.Mark(int64(1 / sampleRate))
+	if s.sketch != nil {
+		s.sketch.Add(v, 1/sampleRate)
+	}
+}
+
+// samplingHistogram is a histogram that supports sampling.
+type samplingHistogram struct {
+	metrics.Histogram
+	// count is the extrapolated number of values.
+	count int64
+	// sketch of the values since the previous report, nil if no percentiles
+	// are configured.
+	sketch *ddSketch
+}
+
+func newSamplingHistogram(sketch *ddSketch) *samplingHistogram {
+	return &samplingHistogram{
+		Histogram: metrics.NewHistogram(metrics.NewExpDecaySample(1028, 0.015)),
+		sketch:    sketch,
+	}
+}
+
+// SampledUpdate updates the histogram with a sampled value.
+func (s *samplingHistogram) SampledUpdate(v float64, sampleRate float64) {
+	s.Histogram.Update(int64(v))
+	s.count += int64(1 / sampleRate)
+	if s.sketch != nil {
+		s.sketch.Add(v, 1/sampleRate)
+	}
+}
+
+// distributionMetric is a DogStatsD distribution. Its statistics are
+// calculated from the values received since the previous report.
+type distributionMetric struct {
+	sketch *ddSketch
+}
+
+// SampledUpdate updates the distribution with a sampled value.
+func (d *distributionMetric) SampledUpdate(v float64, sampleRate float64) {
+	d.sketch.Add(v, 1/sampleRate)
 }
 
 // Snapshot gets a snapshot of the SamplingTimer
@@ -191,10 +241,10 @@ func (r *registry) getMetric(metric interface{}) map[string]interface{} {
 		m.Clear()
 	case *deltaGaugeMetric:
 		values["value"] = m.Value()
-	case metrics.Histogram:
+	case *samplingHistogram:
 		h := m.Snapshot()
 		ps := h.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
-		values["count"] = h.Count()
+		values["count"] = m.count
 		values["min"] = h.Min()
 		values["max"] = h.Max()
 		values["mean"] = h.Mean()
@@ -204,6 +254,7 @@ func (r *registry) getMetric(metric interface{}) map[string]interface{} {
 		values["p95"] = ps[2]
 		values["p99"] = ps[3]
 		values["p99_9"] = ps[4]
+		r.addPercentiles(values, m.sketch)
 	case *samplingTimer:
 		t := m.Snapshot()
 		ps := t.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
@@ -221,6 +272,16 @@ func (r *registry) getMetric(metric interface{}) map[string]interface{} {
 		values["5m_rate"] = t.Rate5()
 		values["15m_rate"] = t.Rate15()
 		values["mean_rate"] = t.RateMean()
+		r.addPercentiles(values, m.sketch)
+	case *distributionMetric:
+		values["count"] = int64(math.Round(m.sketch.Count()))
+		if m.sketch.Count() > 0 {
+			values["min"] = m.sketch.Min()
+			values["max"] = m.sketch.Max()
+			values["sum"] = m.sketch.Sum()
+			values["mean"] = m.sketch.Sum() / m.sketch.Count()
+		}
+		r.addPercentiles(values, m.sketch)
 	case *setMetric:
 		values["count"] = m.Count()
 		m.Reset()
@@ -228,6 +289,34 @@ func (r *registry) getMetric(metric interface{}) map[string]interface{} {
 	return values
 }
 
+// addPercentiles adds the configured percentiles of the values of a sketch,
+// and resets it for the next report. Percentiles are named after their
+// value, like p95 or p99_9.
+func (r *registry) addPercentiles(values map[string]interface{}, sketch *ddSketch) {
+	if sketch == nil {
+		return
+	}
+	if sketch.Count() > 0 {
+		for _, p := range r.percentiles {
+			values[percentileName(p)] = sketch.Quantile(p / 100)
+		}
+	}
+	sketch.Reset()
+}
+
+func percentileName(p float64) string {
+	return "p" + strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "_")
+}
+
+// newSketch returns a sketch for the percentiles of timers and histograms, or
+// nil if no percentiles are configured.
+func (r *registry) newSketch() *ddSketch {
+	if len(r.percentiles) == 0 {
+		return nil
+	}
+	return newDDSketch()
+}
+
 func (r *registry) GetAll() []metricsGroup {
 	var tags map[string]string
 	now := time.Now()
@@ -331,7 +420,7 @@ func (r *registry) GetOrNewCounter(name string, tags map[string]string) metrics.
 }
 
 func (r *registry) GetOrNewTimer(name string, tags map[string]string) *samplingTimer {
-	timer, ok := r.getOrNew(name, tags, func() interface{} { return newSamplingTimer() }).(*samplingTimer)
+	timer, ok := r.getOrNew(name, tags, func() interface{} { return newSamplingTimer(r.newSketch()) }).(*samplingTimer)
 	if ok {
 		return timer
 	}
@@ -350,8 +439,8 @@ func (r *registry) GetOrNewGauge64(name string, tags map[string]string) *deltaGa
 	return r.GetOrNewGauge64(name, tags)
 }
 
-func (r *registry) GetOrNewHistogram(name string, tags map[string]string) metrics.Histogram {
-	histogram, ok := r.getOrNew(name, tags, func() interface{} { return metrics.NewHistogram(metrics.NewExpDecaySample(1028, 0.015)) }).(metrics.Histogram)
+func (r *registry) GetOrNewHistogram(name string, tags map[string]string) *samplingHistogram {
+	histogram, ok := r.getOrNew(name, tags, func() interface{} { return newSamplingHistogram(r.newSketch()) }).(*samplingHistogram)
 	if ok {
 		return histogram
 	}
@@ -360,6 +449,16 @@ func (r *registry) GetOrNewHistogram(name string, tags map[string]string) metric
 	return r.GetOrNewHistogram(name, tags)
 }
 
+func (r *registry) GetOrNewDistribution(name string, tags map[string]string) *distributionMetric {
+	distribution, ok := r.getOrNew(name, tags, func() interface{} { return &distributionMetric{sketch: newDDSketch()} }).(*distributionMetric)
+	if ok {
+		return distribution
+	}
+
+	r.clearTypeChanged(name, tags)
+	return r.GetOrNewDistribution(name, tags)
+}
+
 func (r *registry) GetOrNewSet(name string, tags map[string]string) *setMetric {
 	setmetric, ok := r.getOrNew(name, tags, func() interface{} { return newSetMetric() }).(*setMetric)
 	if ok {
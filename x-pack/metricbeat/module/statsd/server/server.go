@@ -5,12 +5,14 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"time"
 
 	serverhelper "github.com/elastic/beats/v7/metricbeat/helper/server"
+	"github.com/elastic/beats/v7/metricbeat/helper/server/tcp"
 	"github.com/elastic/beats/v7/metricbeat/helper/server/udp"
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	"github.com/elastic/elastic-agent-libs/mapstr"
@@ -56,15 +58,34 @@ func newErrInvalidMapping(metricLabels []string, attrLabels []Label) error {
 type Config struct {
 	TTL      time.Duration   `config:"ttl"`
 	Mappings []StatsdMapping `config:"statsd.mappings"`
+	// Protocol to listen on, udp or tcp. Packets received over tcp are
+	// delimited by newlines.
+	Protocol string `config:"protocol"`
+	// Percentiles reported for timers, histograms and distributions.
+	Percentiles []float64 `config:"percentiles"`
 }
 
 func defaultConfig() Config {
 	return Config{
 		TTL:      time.Second * 30,
 		Mappings: nil,
+		Protocol: "udp",
 	}
 }
 
+// Validate validates the protocol and the percentiles.
+func (c Config) Validate() error {
+	if c.Protocol != "udp" && c.Protocol != "tcp" {
+		return errors.New("`protocol` can only be udp or tcp")
+	}
+	for _, p := range c.Percentiles {
+		if p <= 0 || p >= 100 {
+			return fmt.Errorf("invalid percentile %v in `percentiles`, percentiles must be between 0 and 100", p)
+		}
+	}
+	return nil
+}
+
 // MetricSet type defines all fields of the MetricSet
 // As a minimum it must inherit the mb.BaseMetricSet fields, but can be extended with
 // additional entries. These variables can be used to persist data or configuration between
@@ -86,12 +107,18 @@ func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
 		return nil, err
 	}
 
-	svc, err := udp.NewUdpServer(base)
+	var svc serverhelper.Server
+	var err error
+	if config.Protocol == "tcp" {
+		svc, err = tcp.NewTcpServer(base)
+	} else {
+		svc, err = udp.NewUdpServer(base)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	processor := newMetricProcessor(config.TTL, base.Logger())
+	processor := newMetricProcessor(config.TTL, config.Percentiles, base.Logger())
 
 	mappings, err := buildMappings(config.Mappings)
 	if err != nil {
@@ -108,7 +135,7 @@ func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
 // Host returns the hostname or other module specific value that identifies a
 // specific host or service instance from which to collect metrics.
 func (m *MetricSet) Host() string {
-	return m.server.(*udp.UdpServer).GetHost()
+	return m.server.(interface{ GetHost() string }).GetHost()
 }
 
 func buildMappings(config []StatsdMapping) (map[string]StatsdMapping, error) {
@@ -0,0 +1,213 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package server
+
+import (
+	"math"
+)
+
+const (
+	// sketchRelativeAccuracy is the maximum relative error of the quantiles
+	// estimated by sketches.
+	sketchRelativeAccuracy = 0.01
+	// sketchMaxBins is the maximum number of buckets of each sign kept by a
+	// sketch. With the relative accuracy above, this covers values over more
+	// than 17 orders of magnitude before buckets are collapsed.
+	sketchMaxBins = 2048
+	// sketchMinValue is the smallest absolute value counted in its own
+	// bucket, smaller values are counted as zeros.
+	sketchMinValue = 1e-9
+)
+
+// ddSketch is a DDSketch (https://arxiv.org/abs/1908.10693), that estimates
+// quantiles of the values it receives with a bounded relative error. Values
+// are counted in buckets whose boundaries grow exponentially. When a sketch
+// has too many buckets, the ones with the smallest absolute values are
+// collapsed, so its memory is bounded and high quantiles stay accurate.
+type ddSketch struct {
+	gamma    float64
+	logGamma float64
+
+	positive  sketchStore
+	negative  sketchStore
+	zeroCount float64
+
+	count float64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func newDDSketch() *ddSketch {
+	gamma := (1 + sketchRelativeAccuracy) / (1 - sketchRelativeAccuracy)
+	s := &ddSketch{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+	}
+	s.Reset()
+	return s
+}
+
+// Add counts a value weight times. Weights are used to extrapolate sampled
+// values.
+func (s *ddSketch) Add(value, weight float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) || weight <= 0 {
+		return
+	}
+
+	switch {
+	case value > sketchMinValue:
+		s.positive.add(s.index(value), weight)
+	case value < -sketchMinValue:
+		s.negative.add(s.index(-value), weight)
+	default:
+		s.zeroCount += weight
+	}
+
+	if s.count == 0 || value < s.min {
+		s.min = value
+	}
+	if s.count == 0 || value > s.max {
+		s.max = value
+	}
+	s.count += weight
+	s.sum += value * weight
+}
+
+// Quantile returns an estimation of the q-quantile of the values, with q
+// between 0 and 1, or NaN if the sketch is empty.
+func (s *ddSketch) Quantile(q float64) float64 {
+	switch {
+	case s.count == 0 || q < 0 || q > 1:
+		return math.NaN()
+	case q == 0:
+		return s.min
+	case q == 1:
+		return s.max
+	}
+
+	rank := q * (s.count - 1)
+	value := s.max
+	var cumulative float64
+	found := false
+
+	// Negative values, from the largest absolute value to the smallest.
+	for i := len(s.negative.bins) - 1; i >= 0 && !found; i-- {
+		cumulative += s.negative.bins[i]
+		if cumulative > rank {
+			value = -s.value(s.negative.offset + i)
+			found = true
+		}
+	}
+	if !found {
+		cumulative += s.zeroCount
+		if cumulative > rank {
+			value = 0
+			found = true
+		}
+	}
+	for i := 0; i < len(s.positive.bins) && !found; i++ {
+		cumulative += s.positive.bins[i]
+		if cumulative > rank {
+			value = s.value(s.positive.offset + i)
+			found = true
+		}
+	}
+
+	// Estimations are never out of the range of the received values.
+	return math.Max(s.min, math.Min(s.max, value))
+}
+
+// Count returns the sum of the weights of the values.
+func (s *ddSketch) Count() float64 { return s.count }
+
+// Sum returns the weighted sum of the values.
+func (s *ddSketch) Sum() float64 { return s.sum }
+
+// Min returns the smallest value, or zero if the sketch is empty.
+func (s *ddSketch) Min() float64 { return s.min }
+
+// Max returns the largest value, or zero if the sketch is empty.
+func (s *ddSketch) Max() float64 { return s.max }
+
+// Reset removes all the values.
+func (s *ddSketch) Reset() {
+	s.positive = sketchStore{}
+	s.negative = sketchStore{}
+	s.zeroCount = 0
+	s.count = 0
+	s.sum = 0
+	s.min = 0
+	s.max = 0
+}
+
+// index returns the index of the bucket of a positive value. Bucket i
+// contains the values in (gamma^(i-1), gamma^i].
+func (s *ddSketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value returns the value that represents a bucket, with the lowest relative
+// error for all the values of the bucket.
+func (s *ddSketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+// sketchStore counts values in contiguous buckets, bins[i] being the count
+// of the bucket with index offset+i.
+type sketchStore struct {
+	bins   []float64
+	offset int
+}
+
+// add adds a weight to a bucket. If the range of indexes gets larger than the
+// maximum number of buckets, the buckets with the lowest indexes are
+// collapsed.
+func (s *sketchStore) add(index int, weight float64) {
+	if len(s.bins) == 0 {
+		s.bins = []float64{weight}
+		s.offset = index
+		return
+	}
+
+	highest := s.offset + len(s.bins) - 1
+	switch {
+	case index > highest:
+		if lowest := index - sketchMaxBins + 1; lowest > s.offset {
+			s.collapse(lowest)
+		}
+		s.bins = append(s.bins, make([]float64, index-(s.offset+len(s.bins)-1))...)
+	case index < s.offset:
+		// Values below the lowest bucket that can be kept are counted in it.
+		if lowest := highest - sketchMaxBins + 1; index < lowest {
+			index = lowest
+		}
+		if index < s.offset {
+			s.bins = append(make([]float64, s.offset-index), s.bins...)
+			s.offset = index
+		}
+	}
+	s.bins[index-s.offset] += weight
+}
+
+// collapse adds the counts of the buckets below lowest to it.
+func (s *sketchStore) collapse(lowest int) {
+	var collapsed float64
+	n := lowest - s.offset
+	if n >= len(s.bins) {
+		for _, c := range s.bins {
+			collapsed += c
+		}
+		s.bins = []float64{collapsed}
+		s.offset = lowest
+		return
+	}
+	for _, c := range s.bins[:n] {
+		collapsed += c
+	}
+	s.bins = append(s.bins[:0], s.bins[n:]...)
+	s.bins[0] += collapsed
+	s.offset = lowest
+}
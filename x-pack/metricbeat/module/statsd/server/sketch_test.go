@@ -0,0 +1,81 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package server
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDDSketchQuantiles(t *testing.T) {
+	s := newDDSketch()
+	for i := 1; i <= 10000; i++ {
+		s.Add(float64(i), 1)
+	}
+
+	assert.Equal(t, float64(10000), s.Count())
+	assert.Equal(t, float64(1), s.Min())
+	assert.Equal(t, float64(10000), s.Max())
+	assert.Equal(t, float64(50005000), s.Sum())
+
+	for _, q := range []float64{0.01, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999} {
+		expected := 1 + q*9999
+		assert.InEpsilon(t, expected, s.Quantile(q), sketchRelativeAccuracy*1.01, "quantile %v", q)
+	}
+	assert.Equal(t, float64(1), s.Quantile(0))
+	assert.Equal(t, float64(10000), s.Quantile(1))
+}
+
+func TestDDSketchNegativeAndZero(t *testing.T) {
+	s := newDDSketch()
+	for _, v := range []float64{-100, -10, 0, 0, 10, 100} {
+		s.Add(v, 1)
+	}
+
+	assert.InEpsilon(t, -100, s.Quantile(0), sketchRelativeAccuracy)
+	assert.InEpsilon(t, -10, s.Quantile(0.2), sketchRelativeAccuracy)
+	assert.Equal(t, float64(0), s.Quantile(0.5))
+	assert.InEpsilon(t, 10, s.Quantile(0.8), sketchRelativeAccuracy)
+	assert.InEpsilon(t, 100, s.Quantile(1), sketchRelativeAccuracy)
+}
+
+func TestDDSketchWeights(t *testing.T) {
+	s := newDDSketch()
+	s.Add(1, 9)
+	s.Add(1000, 1)
+
+	assert.Equal(t, float64(10), s.Count())
+	assert.Equal(t, float64(1009), s.Sum())
+	assert.InEpsilon(t, 1, s.Quantile(0.8), sketchRelativeAccuracy)
+	assert.InEpsilon(t, 1000, s.Quantile(1), sketchRelativeAccuracy)
+}
+
+func TestDDSketchBoundedBins(t *testing.T) {
+	s := newDDSketch()
+	// Values over a range too large to fit in the maximum number of bins.
+	for i := -30000; i <= 30000; i++ {
+		s.Add(math.Pow(10, float64(i)/100), 1)
+	}
+
+	assert.LessOrEqual(t, len(s.positive.bins), sketchMaxBins)
+	// Collapsed buckets only affect the lowest values.
+	assert.InEpsilon(t, 1e294, s.Quantile(0.99), 2*sketchRelativeAccuracy)
+	assert.InEpsilon(t, 1e290, s.Quantile(0.9833333), 2*sketchRelativeAccuracy)
+	assert.Less(t, s.Quantile(0.01), 1e283)
+}
+
+func TestDDSketchReset(t *testing.T) {
+	s := newDDSketch()
+	s.Add(5, 1)
+	s.Add(math.NaN(), 1)
+	s.Add(math.Inf(1), 1)
+	assert.Equal(t, float64(1), s.Count())
+
+	s.Reset()
+	assert.Equal(t, float64(0), s.Count())
+	assert.True(t, math.IsNaN(s.Quantile(0.5)))
+}
@@ -24,6 +24,9 @@ import (
 	// This registers the Npcap installer on Windows.
 	_ "github.com/elastic/beats/v7/x-pack/packetbeat/npcap"
 
+	// This registers the IPFIX and NetFlow v9 flow exporter.
+	_ "github.com/elastic/beats/v7/x-pack/packetbeat/flows/netflow"
+
 	// Enable pipelines.
 	_ "github.com/elastic/beats/v7/x-pack/packetbeat/module"
 )
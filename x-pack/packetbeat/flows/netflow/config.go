@@ -0,0 +1,54 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package netflow
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	protocolIPFIX = "ipfix"
+	protocolV9    = "v9"
+)
+
+type config struct {
+	Hosts                   []string      `config:"hosts" validate:"required"`
+	Protocol                string        `config:"protocol"`
+	ObservationDomainID     uint32        `config:"observation_domain_id"`
+	TemplateRefreshInterval time.Duration `config:"template_refresh_interval" validate:"positive,nonzero"`
+	MaxMessageSize          int           `config:"max_message_size"`
+}
+
+var defaultConfig = config{
+	Protocol:                protocolIPFIX,
+	TemplateRefreshInterval: time.Minute,
+	MaxMessageSize:          1400,
+}
+
+// minMessageSize leaves room for the header, all templates and at least one
+// data record.
+const minMessageSize = 512
+
+func (c *config) Validate() error {
+	switch c.Protocol {
+	case protocolIPFIX, protocolV9:
+	default:
+		return fmt.Errorf("unsupported protocol %q, expected %q or %q", c.Protocol, protocolIPFIX, protocolV9)
+	}
+	if c.MaxMessageSize < minMessageSize || c.MaxMessageSize > 65507 {
+		return fmt.Errorf("max_message_size must be between %d and 65507, got %d", minMessageSize, c.MaxMessageSize)
+	}
+	return nil
+}
+
+// defaultPort returns the port collectors listen on by default for the
+// configured protocol.
+func (c *config) defaultPort() string {
+	if c.Protocol == protocolV9 {
+		return "2055"
+	}
+	return "4739"
+}
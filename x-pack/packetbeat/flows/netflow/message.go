@@ -0,0 +1,235 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package netflow
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/elastic/beats/v7/packetbeat/flows"
+)
+
+const (
+	ipfixVersion     = 10
+	ipfixHeaderLen   = 16
+	ipfixTemplateSet = 2
+
+	v9Version         = 9
+	v9HeaderLen       = 20
+	v9TemplateFlowSet = 0
+
+	setHeaderLen = 4
+)
+
+// encoder splits flow records into IPFIX or NetFlow v9 messages.
+type encoder struct {
+	protocol string
+	domainID uint32
+	maxSize  int
+
+	templates map[templateKey]*template
+	ordered   []*template
+
+	refresh       time.Duration
+	lastTemplates time.Time
+
+	// boot is the system start time of NetFlow v9 headers.
+	boot time.Time
+	// sequence counts the data records sent for IPFIX and the messages sent
+	// for NetFlow v9.
+	sequence uint32
+}
+
+func newEncoder(cfg config, now time.Time) (*encoder, error) {
+	byKey, ordered, err := newTemplates(cfg.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	return &encoder{
+		protocol:  cfg.Protocol,
+		domainID:  cfg.ObservationDomainID,
+		maxSize:   cfg.MaxMessageSize,
+		templates: byKey,
+		ordered:   ordered,
+		refresh:   cfg.TemplateRefreshInterval,
+		boot:      now,
+	}, nil
+}
+
+// message is an IPFIX or NetFlow v9 message being built.
+type message struct {
+	buf []byte
+	// set is the offset of the open set, or 0.
+	set   int
+	setID uint16
+	// records counts the template and data records of the message.
+	records int
+	// dataRecords counts the data records of the message.
+	dataRecords int
+}
+
+// encode returns the messages holding the records of the flows reported at
+// ts. Templates are sent ahead of the data on the first call and whenever the
+// refresh interval elapsed since they were last sent.
+func (e *encoder) encode(ts time.Time, records []flows.Record) [][]byte {
+	var msgs [][]byte
+	msg := e.newMessage()
+	if e.lastTemplates.IsZero() || ts.Sub(e.lastTemplates) >= e.refresh {
+		e.appendTemplates(msg)
+		e.lastTemplates = ts
+	}
+
+	groups := map[*template][]record{}
+	for i := range records {
+		fr := &records[i]
+		if fr.SourceIP == nil {
+			continue
+		}
+		t := e.templates[templateKey{ipv6: fr.SourceIP.To4() == nil, delta: fr.Delta}]
+		groups[t] = append(groups[t], e.directions(fr)...)
+	}
+
+	for _, t := range e.ordered {
+		for i := range groups[t] {
+			size := len(msg.buf) + t.length
+			if msg.setID != t.id {
+				size += setHeaderLen
+			}
+			if e.protocol == protocolV9 {
+				// Leave room for the padding of the flowset.
+				size += 3
+			}
+			if size > e.maxSize && msg.records > 0 {
+				msgs = append(msgs, e.finish(ts, msg))
+				msg = e.newMessage()
+			}
+			if msg.setID != t.id {
+				e.openSet(msg, t.id)
+			}
+			off := len(msg.buf)
+			msg.buf = append(msg.buf, make([]byte, t.length)...)
+			for _, el := range t.elements {
+				el.encode(msg.buf[off:off+int(el.length)], &groups[t][i])
+				off += int(el.length)
+			}
+			msg.records++
+			msg.dataRecords++
+		}
+	}
+	if msg.records > 0 {
+		msgs = append(msgs, e.finish(ts, msg))
+	}
+	return msgs
+}
+
+func (e *encoder) newMessage() *message {
+	headerLen := ipfixHeaderLen
+	if e.protocol == protocolV9 {
+		headerLen = v9HeaderLen
+	}
+	return &message{buf: make([]byte, headerLen, e.maxSize)}
+}
+
+func (e *encoder) appendTemplates(msg *message) {
+	setID := uint16(ipfixTemplateSet)
+	if e.protocol == protocolV9 {
+		setID = v9TemplateFlowSet
+	}
+	e.openSet(msg, setID)
+	for _, t := range e.ordered {
+		msg.buf = binary.BigEndian.AppendUint16(msg.buf, t.id)
+		msg.buf = binary.BigEndian.AppendUint16(msg.buf, uint16(len(t.elements)))
+		for _, el := range t.elements {
+			msg.buf = binary.BigEndian.AppendUint16(msg.buf, el.key.FieldID)
+			msg.buf = binary.BigEndian.AppendUint16(msg.buf, el.length)
+		}
+		msg.records++
+	}
+	e.closeSet(msg)
+}
+
+// openSet starts a set, closing the previous one.
+func (e *encoder) openSet(msg *message, id uint16) {
+	e.closeSet(msg)
+	msg.set, msg.setID = len(msg.buf), id
+	msg.buf = binary.BigEndian.AppendUint16(msg.buf, id)
+	msg.buf = append(msg.buf, 0, 0)
+}
+
+// closeSet writes the length of the open set. NetFlow v9 flowsets are padded
+// to a 4 bytes boundary.
+func (e *encoder) closeSet(msg *message) {
+	if msg.set == 0 {
+		return
+	}
+	if e.protocol == protocolV9 {
+		for (len(msg.buf)-msg.set)%4 != 0 {
+			msg.buf = append(msg.buf, 0)
+		}
+	}
+	binary.BigEndian.PutUint16(msg.buf[msg.set+2:], uint16(len(msg.buf)-msg.set))
+	msg.set, msg.setID = 0, 0
+}
+
+// finish writes the header of a message.
+func (e *encoder) finish(ts time.Time, msg *message) []byte {
+	e.closeSet(msg)
+	hdr := msg.buf
+	if e.protocol == protocolV9 {
+		binary.BigEndian.PutUint16(hdr[0:], v9Version)
+		binary.BigEndian.PutUint16(hdr[2:], uint16(msg.records))
+		binary.BigEndian.PutUint32(hdr[4:], uint32(uptime(e.boot, ts)))
+		binary.BigEndian.PutUint32(hdr[8:], uint32(ts.Unix()))
+		binary.BigEndian.PutUint32(hdr[12:], e.sequence)
+		binary.BigEndian.PutUint32(hdr[16:], e.domainID)
+		e.sequence++
+		return msg.buf
+	}
+	binary.BigEndian.PutUint16(hdr[0:], ipfixVersion)
+	binary.BigEndian.PutUint16(hdr[2:], uint16(len(msg.buf)))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(ts.Unix()))
+	binary.BigEndian.PutUint32(hdr[8:], e.sequence)
+	binary.BigEndian.PutUint32(hdr[12:], e.domainID)
+	e.sequence += uint32(msg.dataRecords)
+	return msg.buf
+}
+
+// directions splits a bidirectional flow record into the records of the
+// directions that saw traffic.
+func (e *encoder) directions(fr *flows.Record) []record {
+	var vlan uint16
+	if len(fr.VLANs) > 0 {
+		vlan = fr.VLANs[0]
+	}
+	var icmpTypeCode uint16
+	if fr.Protocol == 1 || fr.Protocol == 58 {
+		icmpTypeCode = uint16(fr.ICMPType)<<8 | uint16(fr.ICMPCode)
+	}
+	out := make([]record, 0, 2)
+	if fr.Source.Packets > 0 {
+		out = append(out, record{
+			start: fr.Start, end: fr.End, final: fr.Final,
+			srcMAC: fr.SourceMAC, dstMAC: fr.DestinationMAC, vlan: vlan,
+			srcIP: fr.SourceIP, dstIP: fr.DestinationIP,
+			srcPort: fr.SourcePort, dstPort: fr.DestinationPort,
+			protocol: fr.Protocol, icmpTypeCode: icmpTypeCode,
+			bytes: fr.Source.Bytes, packets: fr.Source.Packets,
+			boot: e.boot,
+		})
+	}
+	// The ICMP type of the replies is not tracked, so it's left unset.
+	if fr.Destination.Packets > 0 {
+		out = append(out, record{
+			start: fr.Start, end: fr.End, final: fr.Final,
+			srcMAC: fr.DestinationMAC, dstMAC: fr.SourceMAC, vlan: vlan,
+			srcIP: fr.DestinationIP, dstIP: fr.SourceIP,
+			srcPort: fr.DestinationPort, dstPort: fr.SourcePort,
+			protocol: fr.Protocol,
+			bytes:    fr.Destination.Bytes, packets: fr.Destination.Packets,
+			boot: e.boot,
+		})
+	}
+	return out
+}
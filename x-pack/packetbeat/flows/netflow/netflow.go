@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package netflow exports Packetbeat flows as IPFIX or NetFlow v9 messages
+// sent over UDP to flow collectors.
+package netflow
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/elastic/beats/v7/packetbeat/flows"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+func init() {
+	flows.RegisterExporter("netflow", New)
+}
+
+type exporter struct {
+	log   *logp.Logger
+	enc   *encoder
+	conns []net.Conn
+}
+
+// New creates a flow exporter sending IPFIX or NetFlow v9 messages to the
+// configured collectors.
+func New(cfg *conf.C, logger *logp.Logger) (flows.Exporter, error) {
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	enc, err := newEncoder(config, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	e := &exporter{log: logger, enc: enc}
+	for _, host := range config.Hosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, config.defaultPort())
+		}
+		conn, err := net.Dial("udp", host)
+		if err != nil {
+			e.Close()
+			return nil, fmt.Errorf("failed to dial flow collector %s: %w", host, err)
+		}
+		e.conns = append(e.conns, conn)
+	}
+	logger.Infof("Exporting flows as %s to %v", config.Protocol, config.Hosts)
+	return e, nil
+}
+
+func (e *exporter) Export(ts time.Time, records []flows.Record) error {
+	var errs []error
+	for _, msg := range e.enc.encode(ts, records) {
+		for _, conn := range e.conns {
+			if _, err := conn.Write(msg); err != nil {
+				errs = append(errs, fmt.Errorf("failed to send flows to %s: %w", conn.RemoteAddr(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *exporter) Close() error {
+	var errs []error
+	for _, conn := range e.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,237 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !integration
+
+package netflow
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/packetbeat/flows"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder"
+	nfrecord "github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/record"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+)
+
+var (
+	exportTime = time.Date(2026, 3, 4, 10, 20, 30, 0, time.UTC)
+
+	tcpFlow = flows.Record{
+		Start:           exportTime.Add(-10 * time.Second),
+		End:             exportTime.Add(-time.Second),
+		Delta:           true,
+		SourceMAC:       net.HardwareAddr{0x08, 0x00, 0x27, 0x01, 0x02, 0x03},
+		DestinationMAC:  net.HardwareAddr{0x08, 0x00, 0x27, 0x04, 0x05, 0x06},
+		VLANs:           []uint16{171},
+		SourceIP:        net.IPv4(10, 0, 0, 1),
+		DestinationIP:   net.IPv4(10, 0, 0, 2),
+		SourcePort:      38805,
+		DestinationPort: 80,
+		Protocol:        6,
+		Source:          flows.Counters{Bytes: 1200, Packets: 10},
+		Destination:     flows.Counters{Bytes: 64000, Packets: 45},
+	}
+
+	icmpv6Flow = flows.Record{
+		Start:         exportTime.Add(-5 * time.Second),
+		End:           exportTime.Add(-2 * time.Second),
+		Final:         true,
+		SourceIP:      net.ParseIP("fd00::1"),
+		DestinationIP: net.ParseIP("fd00::2"),
+		Protocol:      58,
+		ICMPType:      128,
+		Source:        flows.Counters{Bytes: 104, Packets: 1},
+	}
+)
+
+func newTestEncoder(t *testing.T, protocol string, maxSize int) *encoder {
+	t.Helper()
+	cfg := defaultConfig
+	cfg.Protocol = protocol
+	cfg.ObservationDomainID = 42
+	cfg.MaxMessageSize = maxSize
+	enc, err := newEncoder(cfg, exportTime.Add(-time.Hour))
+	require.NoError(t, err)
+	return enc
+}
+
+// decode reads messages with the decoder of the netflow input.
+func decode(t *testing.T, protocol string, msgs [][]byte) []nfrecord.Record {
+	t.Helper()
+	cfg := decoder.NewConfig(logptest.NewTestingLogger(t, "")).
+		WithProtocols(protocol).
+		WithSequenceResetEnabled(false).
+		WithExpiration(0)
+	dec, err := decoder.NewDecoder(cfg)
+	require.NoError(t, err)
+
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4739}
+	var records []nfrecord.Record
+	for _, msg := range msgs {
+		r, err := dec.Read(bytes.NewBuffer(msg), addr)
+		require.NoError(t, err)
+		records = append(records, r...)
+	}
+	return records
+}
+
+func TestIPFIXRoundTrip(t *testing.T) {
+	enc := newTestEncoder(t, protocolIPFIX, 1400)
+	msgs := enc.encode(exportTime, []flows.Record{tcpFlow, icmpv6Flow})
+	require.Len(t, msgs, 1)
+
+	records := decode(t, protocolIPFIX, msgs)
+	require.Len(t, records, 3)
+
+	fwd := records[0].Fields
+	assert.Equal(t, tcpFlow.Start, fwd["flowStartMilliseconds"])
+	assert.Equal(t, tcpFlow.End, fwd["flowEndMilliseconds"])
+	assert.Equal(t, tcpFlow.SourceMAC, fwd["sourceMacAddress"])
+	assert.Equal(t, tcpFlow.DestinationMAC, fwd["destinationMacAddress"])
+	assert.Equal(t, uint64(171), fwd["vlanId"])
+	assert.Equal(t, "10.0.0.1", fmt.Sprint(fwd["sourceIPv4Address"]))
+	assert.Equal(t, "10.0.0.2", fmt.Sprint(fwd["destinationIPv4Address"]))
+	assert.Equal(t, uint64(38805), fwd["sourceTransportPort"])
+	assert.Equal(t, uint64(80), fwd["destinationTransportPort"])
+	assert.Equal(t, uint64(6), fwd["protocolIdentifier"])
+	assert.Equal(t, uint64(1200), fwd["octetDeltaCount"])
+	assert.Equal(t, uint64(10), fwd["packetDeltaCount"])
+	assert.Equal(t, uint64(endReasonActiveTimeout), fwd["flowEndReason"])
+
+	rev := records[1].Fields
+	assert.Equal(t, tcpFlow.DestinationMAC, rev["sourceMacAddress"])
+	assert.Equal(t, "10.0.0.2", fmt.Sprint(rev["sourceIPv4Address"]))
+	assert.Equal(t, uint64(80), rev["sourceTransportPort"])
+	assert.Equal(t, uint64(38805), rev["destinationTransportPort"])
+	assert.Equal(t, uint64(64000), rev["octetDeltaCount"])
+	assert.Equal(t, uint64(45), rev["packetDeltaCount"])
+
+	// The ICMPv6 flow only has traffic in one direction and reports totals.
+	icmp := records[2].Fields
+	assert.Equal(t, "fd00::1", fmt.Sprint(icmp["sourceIPv6Address"]))
+	assert.Equal(t, "fd00::2", fmt.Sprint(icmp["destinationIPv6Address"]))
+	assert.Equal(t, uint64(58), icmp["protocolIdentifier"])
+	assert.Equal(t, uint64(128<<8), icmp["icmpTypeCodeIPv6"])
+	assert.Equal(t, uint64(104), icmp["octetTotalCount"])
+	assert.Equal(t, uint64(1), icmp["packetTotalCount"])
+	assert.Equal(t, uint64(endReasonIdleTimeout), icmp["flowEndReason"])
+	assert.NotContains(t, icmp, "octetDeltaCount")
+}
+
+func TestNetflowV9RoundTrip(t *testing.T) {
+	enc := newTestEncoder(t, protocolV9, 1400)
+	msgs := enc.encode(exportTime, []flows.Record{tcpFlow})
+	require.Len(t, msgs, 1)
+
+	records := decode(t, protocolV9, msgs)
+	require.Len(t, records, 2)
+
+	fwd := records[0].Fields
+	// Uptimes are relative to the creation of the encoder, an hour earlier.
+	assert.Equal(t, uint64((time.Hour - 10*time.Second).Milliseconds()), fwd["flowStartSysUpTime"])
+	assert.Equal(t, uint64((time.Hour - time.Second).Milliseconds()), fwd["flowEndSysUpTime"])
+	assert.Equal(t, "10.0.0.1", fmt.Sprint(fwd["sourceIPv4Address"]))
+	assert.Equal(t, uint64(38805), fwd["sourceTransportPort"])
+	assert.Equal(t, uint64(1200), fwd["octetDeltaCount"])
+	assert.Equal(t, "10.0.0.2", fmt.Sprint(records[1].Fields["sourceIPv4Address"]))
+	assert.Equal(t, uint64(45), records[1].Fields["packetDeltaCount"])
+}
+
+func TestTemplateRefresh(t *testing.T) {
+	enc := newTestEncoder(t, protocolIPFIX, 1400)
+	withTemplates := enc.encode(exportTime, []flows.Record{tcpFlow})
+	withoutTemplates := enc.encode(exportTime.Add(10*time.Second), []flows.Record{tcpFlow})
+	refreshed := enc.encode(exportTime.Add(time.Minute), []flows.Record{tcpFlow})
+
+	require.Len(t, withTemplates, 1)
+	require.Len(t, withoutTemplates, 1)
+	require.Len(t, refreshed, 1)
+	assert.Less(t, len(withoutTemplates[0]), len(withTemplates[0]))
+	assert.Equal(t, len(withTemplates[0]), len(refreshed[0]))
+
+	// Data arriving after the templates is decoded.
+	records := decode(t, protocolIPFIX, append(withTemplates, withoutTemplates...))
+	assert.Len(t, records, 4)
+
+	// Nothing is sent when there are no flows and the templates are current.
+	assert.Empty(t, enc.encode(exportTime.Add(70*time.Second), nil))
+}
+
+func TestMessageSplit(t *testing.T) {
+	for _, protocol := range []string{protocolIPFIX, protocolV9} {
+		t.Run(protocol, func(t *testing.T) {
+			enc := newTestEncoder(t, protocol, minMessageSize)
+			records := make([]flows.Record, 20)
+			for i := range records {
+				records[i] = tcpFlow
+				records[i].SourcePort = uint16(40000 + i)
+			}
+			msgs := enc.encode(exportTime, records)
+			require.Greater(t, len(msgs), 1)
+			for _, msg := range msgs {
+				assert.LessOrEqual(t, len(msg), minMessageSize)
+			}
+
+			decoded := decode(t, protocol, msgs)
+			require.Len(t, decoded, 40)
+			for i, r := range decoded {
+				port := "sourceTransportPort"
+				if i%2 == 1 {
+					port = "destinationTransportPort"
+				}
+				assert.Equal(t, uint64(40000+i/2), r.Fields[port])
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	logger := logptest.NewTestingLogger(t, "")
+
+	_, err := New(conf.MustNewConfigFrom(map[string]interface{}{}), logger)
+	assert.Error(t, err)
+
+	_, err = New(conf.MustNewConfigFrom(map[string]interface{}{
+		"hosts":    []string{"127.0.0.1"},
+		"protocol": "v5",
+	}), logger)
+	assert.ErrorContains(t, err, "unsupported protocol")
+
+	_, err = New(conf.MustNewConfigFrom(map[string]interface{}{
+		"hosts":            []string{"127.0.0.1"},
+		"max_message_size": 100,
+	}), logger)
+	assert.ErrorContains(t, err, "max_message_size")
+}
+
+func TestExport(t *testing.T) {
+	collector, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer collector.Close()
+
+	exporter, err := New(conf.MustNewConfigFrom(map[string]interface{}{
+		"hosts": []string{collector.LocalAddr().String()},
+	}), logptest.NewTestingLogger(t, ""))
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(exportTime, []flows.Record{tcpFlow}))
+	require.NoError(t, exporter.Close())
+
+	buf := make([]byte, 65536)
+	require.NoError(t, collector.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := collector.ReadFrom(buf)
+	require.NoError(t, err)
+
+	records := decode(t, protocolIPFIX, [][]byte{buf[:n]})
+	require.Len(t, records, 2)
+	assert.Equal(t, uint64(38805), records[0].Fields["sourceTransportPort"])
+}
@@ -0,0 +1,199 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package netflow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/fields"
+)
+
+// record is one direction of a flow, as NetFlow and IPFIX only know
+// unidirectional flows.
+type record struct {
+	start, end     time.Time
+	final          bool
+	srcMAC, dstMAC net.HardwareAddr
+	vlan           uint16
+	srcIP, dstIP   net.IP
+	srcPort        uint16
+	dstPort        uint16
+	protocol       uint8
+	icmpTypeCode   uint16
+	bytes, packets uint64
+
+	// boot is the time NetFlow v9 system uptimes are relative to.
+	boot time.Time
+}
+
+// Values of the flowEndReason information element (RFC 5102).
+const (
+	endReasonIdleTimeout   = 1
+	endReasonActiveTimeout = 2
+)
+
+// recordValues maps the information elements used in templates to the
+// record value they hold.
+var recordValues = map[string]func(r *record) interface{}{
+	"flowStartMilliseconds":    func(r *record) interface{} { return r.start },
+	"flowEndMilliseconds":      func(r *record) interface{} { return r.end },
+	"flowStartSysUpTime":       func(r *record) interface{} { return uptime(r.boot, r.start) },
+	"flowEndSysUpTime":         func(r *record) interface{} { return uptime(r.boot, r.end) },
+	"sourceMacAddress":         func(r *record) interface{} { return r.srcMAC },
+	"destinationMacAddress":    func(r *record) interface{} { return r.dstMAC },
+	"vlanId":                   func(r *record) interface{} { return uint64(r.vlan) },
+	"sourceIPv4Address":        func(r *record) interface{} { return r.srcIP },
+	"destinationIPv4Address":   func(r *record) interface{} { return r.dstIP },
+	"sourceIPv6Address":        func(r *record) interface{} { return r.srcIP },
+	"destinationIPv6Address":   func(r *record) interface{} { return r.dstIP },
+	"sourceTransportPort":      func(r *record) interface{} { return uint64(r.srcPort) },
+	"destinationTransportPort": func(r *record) interface{} { return uint64(r.dstPort) },
+	"protocolIdentifier":       func(r *record) interface{} { return uint64(r.protocol) },
+	"icmpTypeCodeIPv4":         func(r *record) interface{} { return uint64(r.icmpTypeCode) },
+	"icmpTypeCodeIPv6":         func(r *record) interface{} { return uint64(r.icmpTypeCode) },
+	"octetDeltaCount":          func(r *record) interface{} { return r.bytes },
+	"packetDeltaCount":         func(r *record) interface{} { return r.packets },
+	"octetTotalCount":          func(r *record) interface{} { return r.bytes },
+	"packetTotalCount":         func(r *record) interface{} { return r.packets },
+	"flowEndReason": func(r *record) interface{} {
+		if r.final {
+			return uint64(endReasonIdleTimeout)
+		}
+		return uint64(endReasonActiveTimeout)
+	},
+}
+
+func uptime(boot, ts time.Time) uint64 {
+	if ts.Before(boot) {
+		return 0
+	}
+	return uint64(ts.Sub(boot).Milliseconds())
+}
+
+type element struct {
+	key     fields.Key
+	length  uint16
+	decoder fields.Decoder
+	value   func(r *record) interface{}
+}
+
+// newElement looks up an information element by name in the field
+// definitions of the netflow input.
+func newElement(name string) (element, error) {
+	value, found := recordValues[name]
+	if !found {
+		return element{}, fmt.Errorf("no flow value for field %s", name)
+	}
+	for key, field := range fields.GlobalFields {
+		if key.EnterpriseID != 0 || field.Name != name {
+			continue
+		}
+		if field.Decoder.MinLength() != field.Decoder.MaxLength() && !isUnsigned(field.Decoder) {
+			return element{}, fmt.Errorf("field %s has a variable length", name)
+		}
+		return element{
+			key:     key,
+			length:  field.Decoder.MaxLength(),
+			decoder: field.Decoder,
+			value:   value,
+		}, nil
+	}
+	return element{}, fmt.Errorf("unknown field %s", name)
+}
+
+func isUnsigned(decoder fields.Decoder) bool {
+	_, ok := decoder.(fields.UnsignedDecoder)
+	return ok
+}
+
+// encode writes the value of the element for a record into dst, which is
+// the element's length.
+func (e *element) encode(dst []byte, r *record) {
+	switch v := e.value(r).(type) {
+	case uint64:
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], v)
+		copy(dst, buf[8-len(dst):])
+	case time.Time:
+		binary.BigEndian.PutUint64(dst, uint64(v.UnixMilli()))
+	case net.IP:
+		if len(dst) == net.IPv4len {
+			v = v.To4()
+		} else {
+			v = v.To16()
+		}
+		copy(dst, v)
+	case net.HardwareAddr:
+		copy(dst, v)
+	}
+}
+
+type template struct {
+	id       uint16
+	elements []element
+	// length is the length of the data records of the template.
+	length int
+}
+
+// templateKey selects the template of a record.
+type templateKey struct {
+	ipv6  bool
+	delta bool
+}
+
+// newTemplates builds the templates of the records exported with the
+// given protocol.
+func newTemplates(protocol string) (map[templateKey]*template, []*template, error) {
+	byKey := map[templateKey]*template{}
+	var ordered []*template
+	id := uint16(256)
+	for _, ipv6 := range []bool{false, true} {
+		for _, delta := range []bool{true, false} {
+			t := &template{id: id}
+			for _, name := range templateFields(protocol, ipv6, delta) {
+				e, err := newElement(name)
+				if err != nil {
+					return nil, nil, err
+				}
+				t.elements = append(t.elements, e)
+				t.length += int(e.length)
+			}
+			byKey[templateKey{ipv6: ipv6, delta: delta}] = t
+			ordered = append(ordered, t)
+			id++
+		}
+	}
+	return byKey, ordered, nil
+}
+
+func templateFields(protocol string, ipv6, delta bool) []string {
+	var names []string
+	if protocol == protocolV9 {
+		names = append(names, "flowStartSysUpTime", "flowEndSysUpTime")
+	} else {
+		names = append(names, "flowStartMilliseconds", "flowEndMilliseconds")
+	}
+	names = append(names, "sourceMacAddress", "destinationMacAddress", "vlanId")
+	if ipv6 {
+		names = append(names, "sourceIPv6Address", "destinationIPv6Address")
+	} else {
+		names = append(names, "sourceIPv4Address", "destinationIPv4Address")
+	}
+	names = append(names, "sourceTransportPort", "destinationTransportPort", "protocolIdentifier")
+	if ipv6 {
+		names = append(names, "icmpTypeCodeIPv6")
+	} else {
+		names = append(names, "icmpTypeCodeIPv4")
+	}
+	if delta {
+		names = append(names, "octetDeltaCount", "packetDeltaCount")
+	} else {
+		names = append(names, "octetTotalCount", "packetTotalCount")
+	}
+	return append(names, "flowEndReason")
+}